@@ -0,0 +1,240 @@
+package achievements
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"smooth/platform"
+)
+
+// Achievement represents a milestone the user can unlock
+type Achievement struct {
+	ID          string
+	Title       string
+	Description string
+	Icon        string
+}
+
+// All lists every achievement in display order
+var All = []Achievement{
+	{ID: "first-save", Title: "First Steps", Description: "Make your first save", Icon: "🐣"},
+	{ID: "ten-saves", Title: "Getting the Hang of It", Description: "Make 10 saves", Icon: "🌱"},
+	{ID: "hundred-saves", Title: "Centurion", Description: "Make your 100th save", Icon: "💯"},
+	{ID: "first-sync", Title: "To the Cloud", Description: "Sync to GitHub for the first time", Icon: "☁️"},
+	{ID: "first-experiment-kept", Title: "Mad Scientist", Description: "Keep your first experiment", Icon: "🧪"},
+	{ID: "seven-day-streak", Title: "On a Roll", Description: "Save on 7 days in a row", Icon: "🔥"},
+}
+
+// State holds progress toward achievements and which have been unlocked
+type State struct {
+	Unlocked        map[string]time.Time `json:"unlocked"`
+	Saves           int                  `json:"saves"`
+	Syncs           int                  `json:"syncs"`
+	ExperimentsKept int                  `json:"experimentsKept"`
+	SaveDays        []string             `json:"saveDays"` // YYYY-MM-DD, oldest first
+	Days            map[string]DayStats  `json:"days"`     // by YYYY-MM-DD, for the last year
+	LongestStreak   int                  `json:"longestStreak"`
+}
+
+// DayStats counts the saves made on one day and the lines they changed
+type DayStats struct {
+	Saves   int `json:"saves"`
+	Added   int `json:"added"`
+	Deleted int `json:"deleted"`
+}
+
+// statsDays is how many days of daily stats are kept
+const statsDays = 366
+
+// dayFormat is how save days are recorded
+const dayFormat = "2006-01-02"
+
+// statePath returns the path to the achievements file
+func statePath() (string, error) {
+	return platform.SmoothPath("achievements.json")
+}
+
+// Load reads achievement progress from disk, returning empty progress if
+// none has been recorded yet
+func Load() (State, error) {
+	state := State{Unlocked: make(map[string]time.Time), Days: make(map[string]DayStats)}
+
+	path, err := statePath()
+	if err != nil {
+		return state, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{Unlocked: make(map[string]time.Time), Days: make(map[string]DayStats)}, err
+	}
+	if state.Unlocked == nil {
+		state.Unlocked = make(map[string]time.Time)
+	}
+	if state.Days == nil {
+		state.Days = make(map[string]DayStats)
+	}
+	return state, nil
+}
+
+// Save writes achievement progress to disk
+func Save(state State) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// IsUnlocked checks if the achievement has been unlocked
+func (s State) IsUnlocked(id string) bool {
+	_, ok := s.Unlocked[id]
+	return ok
+}
+
+// Streak returns how many consecutive days ending today (or yesterday, if
+// there's been no save yet today) have at least one save
+func (s State) Streak(now time.Time) int {
+	days := make(map[string]bool)
+	for _, d := range s.SaveDays {
+		days[d] = true
+	}
+	for d, stats := range s.Days {
+		if stats.Saves > 0 {
+			days[d] = true
+		}
+	}
+
+	day := now
+	if !days[day.Format(dayFormat)] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for days[day.Format(dayFormat)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// Day returns the stats for the day t falls on
+func (s State) Day(t time.Time) DayStats {
+	return s.Days[t.Format(dayFormat)]
+}
+
+// Totals adds up the stats for every recorded day
+func (s State) Totals() DayStats {
+	var total DayStats
+	for _, d := range s.Days {
+		total.Saves += d.Saves
+		total.Added += d.Added
+		total.Deleted += d.Deleted
+	}
+	return total
+}
+
+// unlock marks achievements whose condition now holds, returning the new ones
+func (s *State) unlock(now time.Time) []Achievement {
+	conditions := map[string]bool{
+		"first-save":            s.Saves >= 1,
+		"ten-saves":             s.Saves >= 10,
+		"hundred-saves":         s.Saves >= 100,
+		"first-sync":            s.Syncs >= 1,
+		"first-experiment-kept": s.ExperimentsKept >= 1,
+		"seven-day-streak":      s.Streak(now) >= 7,
+	}
+
+	var unlocked []Achievement
+	for _, a := range All {
+		if conditions[a.ID] && !s.IsUnlocked(a.ID) {
+			s.Unlocked[a.ID] = now
+			unlocked = append(unlocked, a)
+		}
+	}
+	return unlocked
+}
+
+// record loads progress, applies the update, unlocks anything earned and
+// saves. Failing to read or write progress never gets in the way of the
+// action that triggered it, so errors just mean nothing is unlocked.
+func record(update func(s *State, now time.Time)) []Achievement {
+	state, err := Load()
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	update(&state, now)
+	unlocked := state.unlock(now)
+
+	if err := Save(state); err != nil {
+		return nil
+	}
+	return unlocked
+}
+
+// RecordSave counts a save that added and deleted the given number of
+// lines, and returns any achievements it unlocked
+func RecordSave(added, deleted int) []Achievement {
+	return record(func(s *State, now time.Time) {
+		s.Saves++
+		today := now.Format(dayFormat)
+		if n := len(s.SaveDays); n == 0 || s.SaveDays[n-1] != today {
+			s.SaveDays = append(s.SaveDays, today)
+		}
+		// Only the last week matters for the streak
+		if n := len(s.SaveDays); n > 7 {
+			s.SaveDays = s.SaveDays[n-7:]
+		}
+
+		day := s.Days[today]
+		day.Saves++
+		day.Added += added
+		day.Deleted += deleted
+		s.Days[today] = day
+		// Keep a year of daily stats
+		cutoff := now.AddDate(0, 0, -statsDays).Format(dayFormat)
+		for d := range s.Days {
+			if d < cutoff {
+				delete(s.Days, d)
+			}
+		}
+
+		s.LongestStreak = max(s.LongestStreak, s.Streak(now))
+	})
+}
+
+// RecordSync counts a sync and returns any achievements it unlocked
+func RecordSync() []Achievement {
+	return record(func(s *State, now time.Time) {
+		s.Syncs++
+	})
+}
+
+// RecordExperimentKept counts a kept experiment and returns any
+// achievements it unlocked
+func RecordExperimentKept() []Achievement {
+	return record(func(s *State, now time.Time) {
+		s.ExperimentsKept++
+	})
+}
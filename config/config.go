@@ -2,26 +2,32 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"smooth/platform"
 )
 
 // Theme represents a color theme
 type Theme struct {
-	Name       string
-	Primary    string // Main accent color
-	Secondary  string // Secondary accent
-	Accent     string // Highlight/selection color
-	Success    string // Success messages
-	Danger     string // Error/danger messages
-	Muted      string // Muted/subtle text
-	Background string // Background elements
-	Text       string // Main text color
-	Highlight  string // Highlighted text
-}
-
-// Available themes
-var Themes = map[string]Theme{
+	Name       string `json:"name"`
+	Primary    string `json:"primary"`    // Main accent color
+	Secondary  string `json:"secondary"`  // Secondary accent
+	Accent     string `json:"accent"`     // Highlight/selection color
+	Success    string `json:"success"`    // Success messages
+	Danger     string `json:"danger"`     // Error/danger messages
+	Muted      string `json:"muted"`      // Muted/subtle text
+	Background string `json:"background"` // Background elements
+	Text       string `json:"text"`       // Main text color
+	Highlight  string `json:"highlight"`  // Highlighted text
+}
+
+// themes are the available themes by ID, custom ones included once
+// they're loaded. Use LookupTheme and ThemeIDs, which hold themesMu.
+var themes = map[string]Theme{
 	"coral": {
 		Name:       "Coral Sunset",
 		Primary:    "#FF6B6B",
@@ -142,38 +148,339 @@ var Themes = map[string]Theme{
 		Text:       "#E0DEF4",
 		Highlight:  "#C4A7E7",
 	},
+	"daylight": {
+		Name:       "Daylight",
+		Primary:    "#D9480F",
+		Secondary:  "#0B7285",
+		Accent:     "#5F3DC4",
+		Success:    "#2B8A3E",
+		Danger:     "#C92A2A",
+		Muted:      "#6C757D",
+		Background: "#E9ECEF",
+		Text:       "#212529",
+		Highlight:  "#1864AB",
+	},
+	"solarized-light": {
+		Name:       "Solarized Light",
+		Primary:    "#268BD2",
+		Secondary:  "#2AA198",
+		Accent:     "#D33682",
+		Success:    "#859900",
+		Danger:     "#DC322F",
+		Muted:      "#839496",
+		Background: "#EEE8D5",
+		Text:       "#586E75",
+		Highlight:  "#6C71C4",
+	},
 }
 
-// ThemeNames returns the list of available theme IDs in display order
-var ThemeNames = []string{
+// themeNames lists the available theme IDs in display order
+var themeNames = []string{
 	"coral", "ocean", "forest", "dracula", "nord",
 	"solarized", "monokai", "cyberpunk", "gruvbox", "rosepine",
+	"daylight", "solarized-light",
 }
 
+// ThemeAuto picks a dark or light theme to match the terminal's background
+const ThemeAuto = "auto"
+
+// The themes auto picks between
+const (
+	AutoDarkTheme  = "coral"
+	AutoLightTheme = "daylight"
+)
+
+// Experience levels, from least to most git exposed
+const (
+	LevelBeginner     = "beginner"
+	LevelIntermediate = "intermediate"
+	LevelAdvanced     = "advanced"
+)
+
+// ExperienceLevels lists the experience levels in order
+var ExperienceLevels = []string{LevelBeginner, LevelIntermediate, LevelAdvanced}
+
+// LevelRank returns the position of a level in ExperienceLevels, or -1
+func LevelRank(level string) int {
+	for i, l := range ExperienceLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// Safety levels, from the most confirmations to the fewest
+const (
+	SafetyBeginner = "beginner"
+	SafetyNormal   = "normal"
+	SafetyExpert   = "expert"
+)
+
+// SafetyLevels lists the safety levels in order
+var SafetyLevels = []string{SafetyBeginner, SafetyNormal, SafetyExpert}
+
+// Export archive formats
+const (
+	ExportZip   = "zip"
+	ExportTarGz = "tar.gz"
+)
+
+// ExportFormats lists the export archive formats in order
+var ExportFormats = []string{ExportZip, ExportTarGz}
+
+// Large repo modes. In large repo mode the menu refreshes less often and
+// only counts lines for the files it shows; auto turns it on while git
+// status is slow.
+const (
+	LargeRepoAuto = "auto"
+	LargeRepoOn   = "on"
+	LargeRepoOff  = "off"
+)
+
+// LargeRepoModes lists the large repo modes in order
+var LargeRepoModes = []string{LargeRepoAuto, LargeRepoOn, LargeRepoOff}
+
+// Save on quit modes. Quitting with unsaved changes can quick save them
+// first, and upload the save too with sync.
+const (
+	SaveOnQuitOff  = "off"
+	SaveOnQuitSave = "save"
+	SaveOnQuitSync = "sync"
+)
+
+// SaveOnQuitModes lists the save on quit modes in order
+var SaveOnQuitModes = []string{SaveOnQuitOff, SaveOnQuitSave, SaveOnQuitSync}
+
+// Mascots cheer on the save screen and when a milestone like the tenth
+// save or the first sync is reached. Off leaves them out.
+const (
+	MascotCat   = "cat"
+	MascotDog   = "dog"
+	MascotRobot = "robot"
+	MascotOff   = "off"
+)
+
+// Mascots lists the mascots in order, with off last
+var Mascots = []string{MascotCat, MascotDog, MascotRobot, MascotOff}
+
 // Config holds application configuration
 type Config struct {
-	AutoSyncEnabled    bool   `json:"autoSyncEnabled"`
-	MaxBackups         int    `json:"maxBackups"`
-	ExperimentsEnabled bool   `json:"experimentsEnabled"`
-	Theme              string `json:"theme"`
+	AutoSyncEnabled        bool      `json:"autoSyncEnabled"`
+	MaxBackups             int       `json:"maxBackups"`
+	ExperimentsEnabled     bool      `json:"experimentsEnabled"`
+	Theme                  string    `json:"theme"`
+	ExperienceLevel        string    `json:"experienceLevel"`
+	DisableAnimations      bool      `json:"disableAnimations"`
+	AlwaysPreviewSave      bool      `json:"alwaysPreviewSave"`
+	LargeFileMB            int       `json:"largeFileMB"` // files this big get a warning before saving
+	NotificationsEnabled   bool      `json:"notificationsEnabled"`
+	MessageTemplate        string    `json:"messageTemplate"` // save message template, e.g. "[{ticket}] {message}"
+	SafetyLevel            string    `json:"safetyLevel"`
+	ExportDir              string    `json:"exportDir"` // where exported backups go, ~/smooth-exports if empty
+	ExportFormat           string    `json:"exportFormat"`
+	DisableSyntaxHighlight bool      `json:"disableSyntaxHighlight"` // plain +/- colors for terminals with few colors
+	EndOfDay               string    `json:"endOfDay"`               // "17:30" to wrap up the day at that time, empty for off
+	EndOfDayWebhook        string    `json:"endOfDayWebhook"`        // URL the day's summary is posted to as JSON
+	Webhooks               []Webhook `json:"webhooks"`               // called after saves, failed syncs and restores
+	RemoteDefault          string    `json:"remoteDefault"`          // start of new projects' GitHub address, like git@github.com:my-org/
+	AccessibleMode         bool      `json:"accessibleMode"`         // plain text for screen readers: no color, borders or emoji
+	SignCommits            bool      `json:"signCommits"`            // sign saves with the GPG or SSH key set up in git
+	NetworkTimeoutSeconds  int       `json:"networkTimeoutSeconds"`  // how long syncing waits for GitHub before giving up
+	DebugLogging           bool      `json:"debugLogging"`           // log every git command to ~/.smooth/logs, for bug reports
+	LargeRepoMode          string    `json:"largeRepoMode"`          // auto, on or off
+	DisableQuitConfirm     bool      `json:"disableQuitConfirm"`     // quit straight away, even with unsaved changes
+	SaveOnQuit             string    `json:"saveOnQuit"`             // off, save or sync
+	SyncEverything         bool      `json:"syncEverything"`         // Sync uploads every branch, experiment, backup and tag, not just the current branch
+	ReducedMotion          bool      `json:"reducedMotion"`          // still indicators instead of spinners, and nothing animated
+	NoEmoji                bool      `json:"noEmoji"`                // leave emoji out of everything smooth shows
+	Mascot                 string    `json:"mascot"`                 // cat, dog, robot or off
+	FancyAnimations        bool      `json:"fancyAnimations"`        // typewriter screen transitions and checkmarks that pop in
+}
+
+// Webhook kinds, which decide the shape of what's posted
+const (
+	WebhookSlack   = "slack"   // {"text": ...}
+	WebhookDiscord = "discord" // {"content": ...}
+	WebhookGeneric = "generic" // the event as JSON, or the template as is
+)
+
+// Webhook events
+const (
+	EventSave       = "save"
+	EventSyncFailed = "sync-failed"
+	EventRestore    = "restore"
+)
+
+// Webhook is a URL told about things that happen in a project. Template
+// is the message, with placeholders like {event}, {project}, {branch},
+// {message}, {hash} and {error}; for generic webhooks it's the whole body,
+// with values escaped for JSON.
+type Webhook struct {
+	URL      string   `json:"url"`
+	Kind     string   `json:"kind"`               // slack, discord or generic; guessed from the URL if empty
+	Events   []string `json:"events,omitempty"`   // events to send, or all of them if empty
+	Template string   `json:"template,omitempty"` // a default message is used if empty
+}
+
+// Wants checks if the webhook should be called for event
+func (w Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GuessKind returns the webhook's kind, guessing from the URL for Slack
+// and Discord if it isn't set
+func (w Webhook) GuessKind() string {
+	switch {
+	case w.Kind != "":
+		return w.Kind
+	case strings.Contains(w.URL, "hooks.slack.com"):
+		return WebhookSlack
+	case strings.Contains(w.URL, "discord.com/api/webhooks"), strings.Contains(w.URL, "discordapp.com/api/webhooks"):
+		return WebhookDiscord
+	}
+	return WebhookGeneric
+}
+
+// AtLeast checks if the configured experience level is at or above level
+func (c Config) AtLeast(level string) bool {
+	return LevelRank(c.ExperienceLevel) >= LevelRank(level)
+}
+
+// Confirmations returns how many times a destructive operation like
+// reverting or abandoning an experiment asks before going ahead. Experts
+// aren't asked at all, relying on the backup that's always made first.
+func (c Config) Confirmations() int {
+	switch c.SafetyLevel {
+	case SafetyBeginner:
+		return 2
+	case SafetyExpert:
+		return 0
+	}
+	return 1
+}
+
+// ShowWarnings checks if destructive operations should explain what could
+// be lost
+func (c Config) ShowWarnings() bool {
+	return c.SafetyLevel != SafetyExpert
+}
+
+// ShowForce checks if force operations, like overwriting the saves on
+// GitHub, are offered at all
+func (c Config) ShowForce() bool {
+	return c.SafetyLevel == SafetyExpert
+}
+
+// EndOfDayAt returns when the end of day falls on the same date as day, or
+// false if it's turned off or not a valid time
+func (c Config) EndOfDayAt(day time.Time) (time.Time, bool) {
+	t, err := ParseClock(c.EndOfDay)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), true
+}
+
+// ParseClock parses a 24-hour time of day like "17:30"
+func ParseClock(s string) (time.Time, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return t, fmt.Errorf("%q isn't a time like 17:30", s)
+	}
+	return t, nil
+}
+
+// ExportDirectory returns the folder exported backups are written to, with
+// a leading ~ expanded to the home folder
+func (c Config) ExportDirectory() (string, error) {
+	home, err := platform.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := c.ExportDir
+	switch {
+	case dir == "":
+		return filepath.Join(home, "smooth-exports"), nil
+	case dir == "~":
+		return home, nil
+	case strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`):
+		return filepath.Join(home, dir[2:]), nil
+	}
+	return dir, nil
+}
+
+// RemoteFor suggests a GitHub address for a new project from the remote
+// default: "git@github.com:my-org/" and "my-project" give
+// git@github.com:my-org/my-project.git. It's "" without a remote default.
+func (c Config) RemoteFor(project string) string {
+	if strings.HasSuffix(c.RemoteDefault, "/") || strings.HasSuffix(c.RemoteDefault, ":") {
+		return c.RemoteDefault + project + ".git"
+	}
+	return c.RemoteDefault
+}
+
+// NetworkTimeout returns how long syncing waits for GitHub before giving up
+func (c Config) NetworkTimeout() time.Duration {
+	return time.Duration(c.NetworkTimeoutSeconds) * time.Second
 }
 
 // DefaultConfig returns a config with default values
 func DefaultConfig() Config {
 	return Config{
-		AutoSyncEnabled:    false,
-		MaxBackups:         10,
-		ExperimentsEnabled: false,
-		Theme:              "coral",
+		AutoSyncEnabled:       false,
+		MaxBackups:            10,
+		ExperimentsEnabled:    false,
+		Theme:                 ThemeAuto,
+		ExperienceLevel:       LevelIntermediate,
+		LargeFileMB:           DefaultLargeFileMB,
+		SafetyLevel:           SafetyNormal,
+		ExportFormat:          ExportZip,
+		NetworkTimeoutSeconds: DefaultNetworkTimeoutSeconds,
+		LargeRepoMode:         LargeRepoAuto,
+		SaveOnQuit:            SaveOnQuitOff,
+		Mascot:                MascotCat,
 	}
 }
 
+// DefaultLargeFileMB is the default large file threshold. GitHub warns
+// about files over 50 MB and rejects files over 100 MB.
+const DefaultLargeFileMB = 50
+
+// DefaultNetworkTimeoutSeconds is how long syncing waits for GitHub by
+// default. Big first uploads on slow connections can take a while.
+const DefaultNetworkTimeoutSeconds = 300
+
+// WebPort is where smooth web serves the web interface
+const WebPort = 3000
+
 // GetTheme returns the theme for the given name, or default if not found
 func GetTheme(name string) Theme {
-	if theme, ok := Themes[name]; ok {
+	if theme, ok := LookupTheme(name); ok {
 		return theme
 	}
-	return Themes["coral"]
+	theme, _ := LookupTheme("coral")
+	return theme
+}
+
+// ResolveTheme returns the ID of the theme to show for name, with auto
+// picking the dark or light theme for the terminal's background
+func ResolveTheme(name string, darkBackground bool) string {
+	if name != ThemeAuto {
+		return name
+	}
+	if darkBackground {
+		return AutoDarkTheme
+	}
+	return AutoLightTheme
 }
 
 // CurrentTheme returns the theme from the current config
@@ -182,13 +489,13 @@ func CurrentTheme() Theme {
 	return GetTheme(cfg.Theme)
 }
 
-// configPath returns the path to the config file
+// configPath returns the path to the config file, which is the active
+// profile's if there is one
 func configPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if name := ActiveProfile(); name != "" {
+		return profilePath(name)
 	}
-	return filepath.Join(home, ".smooth", "config.json"), nil
+	return platform.SmoothPath("config.json")
 }
 
 // Load reads the config from disk, returning defaults if not found
@@ -199,6 +506,12 @@ func Load() (Config, error) {
 	}
 
 	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && ActiveProfile() != "" {
+		// A profile that hasn't been saved yet starts from the default settings
+		if path, err = platform.SmoothPath("config.json"); err == nil {
+			data, err = os.ReadFile(path)
+		}
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return defaults if config doesn't exist
@@ -220,10 +533,51 @@ func Load() (Config, error) {
 	// Ensure Theme has a valid value
 	if cfg.Theme == "" {
 		cfg.Theme = "coral"
-	} else if _, ok := Themes[cfg.Theme]; !ok {
+	} else if _, ok := LookupTheme(cfg.Theme); !ok && cfg.Theme != ThemeAuto {
 		cfg.Theme = "coral"
 	}
 
+	// Configs from before experience levels keep showing everything they did
+	if LevelRank(cfg.ExperienceLevel) < 0 {
+		cfg.ExperienceLevel = LevelIntermediate
+	}
+
+	if cfg.LargeFileMB < 1 {
+		cfg.LargeFileMB = DefaultLargeFileMB
+	}
+
+	if cfg.NetworkTimeoutSeconds < 1 {
+		cfg.NetworkTimeoutSeconds = DefaultNetworkTimeoutSeconds
+	}
+
+	switch cfg.SafetyLevel {
+	case SafetyBeginner, SafetyNormal, SafetyExpert:
+	default:
+		cfg.SafetyLevel = SafetyNormal
+	}
+
+	if cfg.ExportFormat != ExportTarGz {
+		cfg.ExportFormat = ExportZip
+	}
+
+	switch cfg.LargeRepoMode {
+	case LargeRepoAuto, LargeRepoOn, LargeRepoOff:
+	default:
+		cfg.LargeRepoMode = LargeRepoAuto
+	}
+
+	switch cfg.SaveOnQuit {
+	case SaveOnQuitOff, SaveOnQuitSave, SaveOnQuitSync:
+	default:
+		cfg.SaveOnQuit = SaveOnQuitOff
+	}
+
+	switch cfg.Mascot {
+	case MascotCat, MascotDog, MascotRobot, MascotOff:
+	default:
+		cfg.Mascot = MascotCat
+	}
+
 	return cfg, nil
 }
 
@@ -233,7 +587,11 @@ func Save(cfg Config) error {
 	if err != nil {
 		return err
 	}
+	return writeConfig(path, cfg)
+}
 
+// writeConfig writes cfg as indented JSON to path
+func writeConfig(path string, cfg Config) error {
 	// Create .smooth directory if it doesn't exist
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
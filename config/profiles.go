@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"smooth/platform"
+)
+
+// A profile is a named set of settings, like "work" or "personal", kept in
+// ~/.smooth/profiles/<name>.json. The profile picked in Settings is
+// remembered in ~/.smooth/profile; smooth --profile picks one for a single
+// run. Without a profile, ~/.smooth/config.json is used, and a profile
+// that hasn't been saved yet starts from those settings.
+
+// profileOverride is the profile given with --profile, if any
+var profileOverride *string
+
+// profileNamePattern is what profile names may contain, so they make
+// safe file names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ValidateProfileName checks that name can be used for a profile
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("profile names use letters, numbers, - and _, up to 32 characters")
+	}
+	return nil
+}
+
+// profilePath returns where a profile's settings are kept
+func profilePath(name string) (string, error) {
+	return platform.SmoothPath("profiles", name+".json")
+}
+
+// activeProfilePath returns the file remembering the profile picked in
+// Settings
+func activeProfilePath() (string, error) {
+	return platform.SmoothPath("profile")
+}
+
+// UseProfile picks a profile for this run only, as smooth --profile does.
+// An empty name uses the default settings.
+func UseProfile(name string) error {
+	if name != "" {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+	}
+	profileOverride = &name
+	return nil
+}
+
+// ActiveProfile returns the name of the profile in use, or "" for the
+// default settings
+func ActiveProfile() string {
+	if profileOverride != nil {
+		return *profileOverride
+	}
+	path, err := activeProfilePath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// SwitchProfile makes name the profile in use from now on, or the default
+// settings if name is ""
+func SwitchProfile(name string) error {
+	if err := UseProfile(name); err != nil {
+		return err
+	}
+	path, err := activeProfilePath()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0644)
+}
+
+// Profiles returns the names of the saved profiles, sorted
+func Profiles() []string {
+	dir, err := platform.SmoothPath("profiles")
+	if err != nil {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if ValidateProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateProfile saves cfg as a new profile and switches to it
+func CreateProfile(name string, cfg Config) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("there's already a profile called %s", name)
+	}
+	if err := writeConfig(path, cfg); err != nil {
+		return err
+	}
+	return SwitchProfile(name)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"smooth/platform"
+)
+
+// builtinThemes records which theme IDs ship with smooth, so custom themes
+// can't overwrite them
+var builtinThemes = func() map[string]bool {
+	ids := make(map[string]bool)
+	for _, id := range themeNames {
+		ids[id] = true
+	}
+	return ids
+}()
+
+var customThemesOnce sync.Once
+
+// themesMu guards themes and themeNames, which custom themes are added to
+// while the web server and the TUI read them
+var themesMu sync.RWMutex
+
+// hexColor matches #RGB and #RRGGBB colors
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// loadCustomThemesOnce adds the user's custom themes to themes the first time
+// a theme is looked up
+func loadCustomThemesOnce() {
+	customThemesOnce.Do(func() {
+		LoadCustomThemes()
+	})
+}
+
+// themesDir returns the directory custom themes are stored in
+func themesDir() (string, error) {
+	return platform.SmoothPath("themes")
+}
+
+// IsBuiltinTheme checks if the theme ID is one of smooth's own themes
+func IsBuiltinTheme(id string) bool {
+	return builtinThemes[id]
+}
+
+// ThemeID turns a theme name into the ID used for its file and in config
+func ThemeID(name string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			b.WriteRune('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// ValidateTheme checks that a theme has a name and every color is a hex color
+func ValidateTheme(t Theme) error {
+	if ThemeID(t.Name) == "" {
+		return fmt.Errorf("theme needs a name")
+	}
+	for _, c := range ThemeColors(&t) {
+		if !hexColor.MatchString(*c.Value) {
+			return fmt.Errorf("%s color %q is not a hex color like #FF6B6B", c.Label, *c.Value)
+		}
+	}
+	return nil
+}
+
+// ThemeColor is one editable color of a theme
+type ThemeColor struct {
+	Label string
+	Value *string
+}
+
+// ThemeColors returns pointers to each color of the theme, in display order
+func ThemeColors(t *Theme) []ThemeColor {
+	return []ThemeColor{
+		{"Primary", &t.Primary},
+		{"Secondary", &t.Secondary},
+		{"Accent", &t.Accent},
+		{"Success", &t.Success},
+		{"Danger", &t.Danger},
+		{"Muted", &t.Muted},
+		{"Background", &t.Background},
+		{"Text", &t.Text},
+		{"Highlight", &t.Highlight},
+	}
+}
+
+// LookupTheme returns the theme with the given ID, and whether there is one
+func LookupTheme(id string) (Theme, bool) {
+	loadCustomThemesOnce()
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	t, ok := themes[id]
+	return t, ok
+}
+
+// ThemeIDs returns the IDs of the available themes in display order
+func ThemeIDs() []string {
+	loadCustomThemesOnce()
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	return slices.Clone(themeNames)
+}
+
+// registerTheme adds a custom theme to themes and themeNames
+func registerTheme(id string, t Theme) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	if _, exists := themes[id]; !exists {
+		themeNames = append(themeNames, id)
+	}
+	themes[id] = t
+}
+
+// LoadCustomThemes reads ~/.smooth/themes/*.json into themes. Invalid files
+// and files that would replace a built-in theme are skipped.
+func LoadCustomThemes() error {
+	dir, err := themesDir()
+	if err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		if IsBuiltinTheme(id) {
+			continue
+		}
+		t, err := readThemeFile(path)
+		if err != nil {
+			continue
+		}
+		registerTheme(id, t)
+	}
+	return nil
+}
+
+// readThemeFile reads and validates a theme JSON file
+func readThemeFile(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Theme{}, fmt.Errorf("%s is not a theme file: %w", filepath.Base(path), err)
+	}
+	if err := ValidateTheme(t); err != nil {
+		return Theme{}, err
+	}
+	return t, nil
+}
+
+// writeThemeFile writes a theme as indented JSON
+func writeThemeFile(path string, t Theme) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SaveCustomTheme validates the theme, writes it to ~/.smooth/themes and
+// makes it available immediately. It returns the theme's ID.
+func SaveCustomTheme(t Theme) (string, error) {
+	loadCustomThemesOnce()
+
+	if err := ValidateTheme(t); err != nil {
+		return "", err
+	}
+	id := ThemeID(t.Name)
+	if IsBuiltinTheme(id) {
+		return "", fmt.Errorf("%q is a built-in theme, pick another name", t.Name)
+	}
+
+	dir, err := themesDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := writeThemeFile(filepath.Join(dir, id+".json"), t); err != nil {
+		return "", err
+	}
+
+	registerTheme(id, t)
+	return id, nil
+}
+
+// ExportTheme writes the theme to a JSON file others can import
+func ExportTheme(id, path string) error {
+	t, ok := LookupTheme(id)
+	if !ok {
+		return fmt.Errorf("unknown theme %q", id)
+	}
+	return writeThemeFile(path, t)
+}
+
+// ImportTheme reads a theme JSON file and saves it as a custom theme,
+// returning its ID
+func ImportTheme(path string) (string, error) {
+	t, err := readThemeFile(path)
+	if err != nil {
+		return "", err
+	}
+	return SaveCustomTheme(t)
+}
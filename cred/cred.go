@@ -0,0 +1,193 @@
+// Package cred keeps the access tokens GitHub wants for HTTPS in the
+// operating system's keychain, and points git's credential helper at it so
+// pushes stop asking for a password that GitHub no longer accepts.
+package cred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"smooth/platform"
+)
+
+// Helper is a git credential helper that can hold a token
+type Helper struct {
+	Name   string // what credential.helper is set to, like "osxkeychain"
+	Where  string // where it keeps tokens, for telling the user
+	Secure bool   // false if tokens end up in a plain text file
+}
+
+// KeychainHelper returns the credential helper that keeps tokens in this
+// OS's keychain, or false if there isn't one
+func KeychainHelper() (Helper, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		return Helper{Name: "osxkeychain", Where: "the macOS Keychain", Secure: true}, true
+	case "windows":
+		// Git Credential Manager comes with Git for Windows
+		return Helper{Name: "manager", Where: "the Windows Credential Manager", Secure: true}, true
+	}
+	if hasGitHelper("libsecret") {
+		return Helper{Name: "libsecret", Where: "your desktop keyring", Secure: true}, true
+	}
+	return Helper{}, false
+}
+
+// PlainTextHelper keeps tokens in ~/.git-credentials as plain text, for
+// when there's no keychain and the user agrees to it
+var PlainTextHelper = Helper{Name: "store", Where: "~/.git-credentials"}
+
+// hasGitHelper checks if git-credential-<name> is installed, either with
+// git or on the PATH
+func hasGitHelper(name string) bool {
+	program := "git-credential-" + name
+	if _, err := exec.LookPath(program); err == nil {
+		return true
+	}
+	dir, err := git("--exec-path").Output()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(strings.TrimSpace(string(dir)), program))
+	return err == nil
+}
+
+// helpersFor returns the credential helpers git's global config sets up
+// for remote's host alone
+func helpersFor(remote Remote) []string {
+	output, _ := git("config", "--global", "--get-all", helperKey(remote)).Output()
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+// helperKey is the config key for the credential helpers of remote's host,
+// like credential.https://github.com.helper
+func helperKey(remote Remote) string {
+	return "credential." + remote.Protocol + "://" + remote.Host + ".helper"
+}
+
+// Remote is where a project's saves are synced over HTTPS
+type Remote struct {
+	Protocol string // "https", or "http" for servers without TLS
+	Host     string // like "github.com"
+	Username string // from the address, if it has one
+}
+
+// ParseRemote reads an HTTPS remote address. It reports false for SSH
+// addresses, which use keys rather than tokens.
+func ParseRemote(address string) (Remote, bool) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return Remote{}, false
+	}
+	return Remote{Protocol: u.Scheme, Host: u.Host, Username: u.User.Username()}, true
+}
+
+// GitHubLogin checks a token with GitHub and returns the account it
+// belongs to
+func GitHubLogin(token string) (string, error) {
+	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("couldn't reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", errors.New("GitHub doesn't recognize that token. Check it was copied whole and hasn't expired")
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("GitHub said %s", resp.Status)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+// StoreToken saves token for remote with helper, replacing whatever git
+// had before, and makes helper a credential helper for remote's host only,
+// leaving the ones set up for everything else alone. For github.com the
+// token is checked first.
+func StoreToken(remote Remote, token string, helper Helper) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("the token is empty")
+	}
+	username := remote.Username
+	if remote.Host == "github.com" {
+		login, err := GitHubLogin(token)
+		if err != nil {
+			return err
+		}
+		if username == "" {
+			username = login
+		}
+	}
+	if username == "" {
+		// Hosts that take tokens accept any username with them
+		username = "x-access-token"
+	}
+
+	// Forget the login that was turned down, wherever git kept it
+	forget := credential(remote, username, "")
+	_ = runCredential(git("credential", "reject"), forget)
+
+	if !slices.Contains(helpersFor(remote), helper.Name) {
+		if output, err := git("config", "--global", "--add", helperKey(remote), helper.Name).CombinedOutput(); err != nil {
+			return fmt.Errorf("couldn't set up git's credential helper: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	// Only the chosen helper gets the token, not any others set up too
+	approve := git("-c", "credential.helper=", "-c", "credential.helper="+helper.Name, "credential", "approve")
+	if err := runCredential(approve, credential(remote, username, token)); err != nil {
+		return fmt.Errorf("couldn't store the token in %s: %w", helper.Where, err)
+	}
+	return nil
+}
+
+// credential is a login in the format git credential reads
+func credential(remote Remote, username, password string) string {
+	s := "protocol=" + remote.Protocol + "\nhost=" + remote.Host + "\nusername=" + username + "\n"
+	if password != "" {
+		s += "password=" + password + "\n"
+	}
+	return s + "\n"
+}
+
+// runCredential runs a git credential command with input on stdin
+func runCredential(cmd *exec.Cmd, input string) error {
+	cmd.Stdin = strings.NewReader(input)
+	// Nothing should ask, but a prompt would be hidden behind the TUI
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// git builds a git command with any config this OS needs
+func git(args ...string) *exec.Cmd {
+	return exec.Command("git", append(platform.GitArgs(), args...)...)
+}
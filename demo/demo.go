@@ -0,0 +1,257 @@
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"smooth/config"
+	"smooth/platform"
+)
+
+// ProjectPath is what the demo project's location is shown as
+const ProjectPath = "~/projects/demo-app"
+
+// Session is a running demo: a synthetic project in a temporary directory
+// with its own home, so nothing from the user's real projects or settings
+// shows up on screen
+type Session struct {
+	root    string // temporary directory holding everything below
+	project string // the demo project smooth runs in
+	home    string // stand-in home directory for ~/.smooth
+	origWd  string
+}
+
+// commit is one save in the demo project's history
+type commit struct {
+	message string
+	daysAgo int
+	files   map[string]string
+}
+
+// history is the demo project's history, oldest first
+var history = []commit{
+	{"Start my portfolio site", 12, map[string]string{
+		"README.md":  "# Demo App\n\nMy personal portfolio site.\n",
+		"index.html": "<!doctype html>\n<html>\n<head>\n  <title>Demo App</title>\n</head>\n<body>\n  <h1>Hello!</h1>\n</body>\n</html>\n",
+	}},
+	{"Add some styles", 11, map[string]string{
+		"style.css": "body {\n  font-family: sans-serif;\n  margin: 0 auto;\n  max-width: 40rem;\n}\n",
+	}},
+	{"Link the stylesheet", 11, map[string]string{
+		"index.html": "<!doctype html>\n<html>\n<head>\n  <title>Demo App</title>\n  <link rel=\"stylesheet\" href=\"style.css\">\n</head>\n<body>\n  <h1>Hello!</h1>\n</body>\n</html>\n",
+	}},
+	{"Add projects section", 9, map[string]string{
+		"projects.html": "<section>\n  <h2>Projects</h2>\n  <ul>\n    <li>Weather widget</li>\n    <li>Recipe finder</li>\n  </ul>\n</section>\n",
+	}},
+	{"Add a little interactivity", 7, map[string]string{
+		"app.js": "document.querySelector('h1').addEventListener('click', () => {\n  alert('Thanks for visiting!');\n});\n",
+	}},
+	{"Make the header pop", 5, map[string]string{
+		"style.css": "body {\n  font-family: sans-serif;\n  margin: 0 auto;\n  max-width: 40rem;\n}\n\nh1 {\n  color: #ff6b6b;\n}\n",
+	}},
+	{"Write a better README", 3, map[string]string{
+		"README.md": "# Demo App\n\nMy personal portfolio site.\n\n## Running it\n\nOpen index.html in a browser.\n",
+	}},
+	{"Add contact form", 1, map[string]string{
+		"contact.html": "<form>\n  <input name=\"email\" placeholder=\"you@example.com\">\n  <button>Say hi</button>\n</form>\n",
+	}},
+}
+
+// Start builds the demo project and switches into it. Call Stop to go back
+// and delete it.
+func Start() (*Session, error) {
+	root, err := os.MkdirTemp("", "smooth-demo-")
+	if err != nil {
+		return nil, err
+	}
+	origWd, _ := os.Getwd()
+	s := &Session{
+		root:    root,
+		project: filepath.Join(root, "demo-app"),
+		home:    filepath.Join(root, "home"),
+		origWd:  origWd,
+	}
+
+	if err := s.setup(); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("failed to set up demo project: %w", err)
+	}
+	return s, nil
+}
+
+// setup isolates the environment and creates the demo project
+func (s *Session) setup() error {
+	for _, dir := range []string{s.project, filepath.Join(s.home, ".smooth")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// Keep the user's look and feel, but nothing else from their home
+	if realHome, err := platform.HomeDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(realHome, ".smooth", "config.json")); err == nil {
+			if data, err := lookAndFeel(data); err == nil {
+				os.WriteFile(filepath.Join(s.home, ".smooth", "config.json"), data, 0644)
+			}
+		}
+		if themes, err := filepath.Glob(filepath.Join(realHome, ".smooth", "themes", "*.json")); err == nil && len(themes) > 0 {
+			os.MkdirAll(filepath.Join(s.home, ".smooth", "themes"), 0755)
+			for _, t := range themes {
+				if data, err := os.ReadFile(t); err == nil {
+					os.WriteFile(filepath.Join(s.home, ".smooth", "themes", filepath.Base(t)), data, 0644)
+				}
+			}
+		}
+	}
+
+	env := map[string]string{
+		"HOME":                s.home,
+		"USERPROFILE":         s.home,
+		"GIT_CONFIG_GLOBAL":   filepath.Join(s.home, ".gitconfig"),
+		"GIT_CONFIG_NOSYSTEM": "1",
+		"GIT_AUTHOR_NAME":     "Demo User",
+		"GIT_AUTHOR_EMAIL":    "demo@example.com",
+		"GIT_COMMITTER_NAME":  "Demo User",
+		"GIT_COMMITTER_EMAIL": "demo@example.com",
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Chdir(s.project); err != nil {
+		return err
+	}
+
+	if err := run("init", "-b", "main"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range history {
+		if err := writeFiles(c.files); err != nil {
+			return err
+		}
+		date := now.AddDate(0, 0, -c.daysAgo).Format(time.RFC3339)
+		os.Setenv("GIT_AUTHOR_DATE", date)
+		os.Setenv("GIT_COMMITTER_DATE", date)
+		if err := run("add", "-A"); err != nil {
+			return err
+		}
+		if err := run("commit", "-m", c.message); err != nil {
+			return err
+		}
+	}
+	os.Unsetenv("GIT_AUTHOR_DATE")
+	os.Unsetenv("GIT_COMMITTER_DATE")
+
+	// An experiment in progress and a backup from an earlier revert
+	experiment := fmt.Sprintf("experiment-dark-mode-%s", now.AddDate(0, 0, -2).Format("20060102-150405"))
+	if err := run("checkout", "-q", "-b", experiment); err != nil {
+		return err
+	}
+	if err := writeFiles(map[string]string{
+		"dark.css": "body {\n  background: #1e1e2e;\n  color: #cdd6f4;\n}\n",
+	}); err != nil {
+		return err
+	}
+	if err := run("add", "-A"); err != nil {
+		return err
+	}
+	if err := run("commit", "-m", "Try a dark theme"); err != nil {
+		return err
+	}
+	if err := run("checkout", "-q", "main"); err != nil {
+		return err
+	}
+	if err := run("branch", fmt.Sprintf("backup/main/%s", now.AddDate(0, 0, -4).Format("20060102-150405")), "HEAD~2"); err != nil {
+		return err
+	}
+
+	// A "GitHub" remote that's really a local bare repo, so Sync works offline
+	remote := filepath.Join(s.root, "remote.git")
+	if err := run("init", "--bare", "-q", remote); err != nil {
+		return err
+	}
+	if err := run("remote", "add", "origin", "git@github.com:demo-user/demo-app.git"); err != nil {
+		return err
+	}
+	if err := run("config", "url."+remote+".insteadOf", "git@github.com:demo-user/demo-app.git"); err != nil {
+		return err
+	}
+
+	// Unsaved work to show off the Save screen
+	return writeFiles(map[string]string{
+		"style.css":  "body {\n  font-family: sans-serif;\n  margin: 0 auto;\n  max-width: 40rem;\n}\n\nh1 {\n  color: #ff6b6b;\n  font-size: 3rem;\n}\n",
+		"about.html": "<section>\n  <h2>About me</h2>\n  <p>I build things for the web.</p>\n</section>\n",
+	})
+}
+
+// Mask replaces the demo's temporary paths with ProjectPath, for anything
+// that ends up on screen
+func (s *Session) Mask(text string) string {
+	if s == nil {
+		return text
+	}
+	text = strings.ReplaceAll(text, s.project, ProjectPath)
+	text = strings.ReplaceAll(text, s.home, "~")
+	return strings.ReplaceAll(text, s.root, ProjectPath)
+}
+
+// Stop returns to the original directory and deletes the demo project
+func (s *Session) Stop() {
+	if s.origWd != "" {
+		os.Chdir(s.origWd)
+	}
+	os.RemoveAll(s.root)
+}
+
+// lookAndFeel turns the user's config into one for the demo that only
+// keeps how smooth looks. Everything else is left at the defaults, so
+// webhooks, auto sync and the like never fire from the demo.
+func lookAndFeel(data []byte) ([]byte, error) {
+	user := config.DefaultConfig()
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	cfg := config.DefaultConfig()
+	cfg.Theme = user.Theme
+	cfg.DisableAnimations = user.DisableAnimations
+	cfg.ReducedMotion = user.ReducedMotion
+	cfg.FancyAnimations = user.FancyAnimations
+	cfg.DisableSyntaxHighlight = user.DisableSyntaxHighlight
+	cfg.AccessibleMode = user.AccessibleMode
+	cfg.NoEmoji = user.NoEmoji
+	cfg.Mascot = user.Mascot
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+// run runs a git command in the current directory
+func run(args ...string) error {
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeFiles writes each file, creating directories as needed
+func writeFiles(files map[string]string) error {
+	for path, content := range files {
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
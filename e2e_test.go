@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"smooth/config"
+	"smooth/git/gittest"
+)
+
+// These tests drive the whole app like a terminal would, the way
+// charmbracelet's teatest does: keys go in as messages, and the frames the
+// program renders are collected and waited on.
+
+// waitTimeout is how long to wait for a frame before failing
+const waitTimeout = 10 * time.Second
+
+// frames collects everything the program renders
+type frames struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (f *frames) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buf.Write(p)
+}
+
+func (f *frames) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buf.String()
+}
+
+// tui is a running program under test
+type tui struct {
+	t    *testing.T
+	p    *tea.Program
+	out  *frames
+	seen int // how much of the output earlier waits have read past
+}
+
+// startTUI runs the app in a 120x40 terminal until the test ends
+func startTUI(t *testing.T) *tui {
+	t.Helper()
+	out := &frames{}
+	p := tea.NewProgram(NewModel(), tea.WithInput(nil), tea.WithOutput(out), tea.WithoutSignalHandler())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run()
+	}()
+	t.Cleanup(func() {
+		p.Kill()
+		<-done
+	})
+	p.Send(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return &tui{t: t, p: p, out: out}
+}
+
+// waitFor waits until text is rendered after whatever earlier waits saw
+func (u *tui) waitFor(text string) {
+	u.t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		rendered := ansi.Strip(u.out.String())
+		if i := strings.Index(rendered[min(u.seen, len(rendered)):], text); i >= 0 {
+			u.seen += i + len(text)
+			return
+		}
+		if time.Now().After(deadline) {
+			u.t.Fatalf("%q never showed up; last output:\n%s", text, rendered[max(0, len(rendered)-2000):])
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// press sends keys by name, like "enter" or "down", or as typed text
+func (u *tui) press(keys ...string) {
+	for _, k := range keys {
+		switch k {
+		case "enter":
+			u.p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+		case "down":
+			u.p.Send(tea.KeyMsg{Type: tea.KeyDown})
+		case "up":
+			u.p.Send(tea.KeyMsg{Type: tea.KeyUp})
+		case "esc":
+			u.p.Send(tea.KeyMsg{Type: tea.KeyEsc})
+		default:
+			u.p.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+}
+
+func TestSaveFromMenu(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "hello\n")
+
+	u := startTUI(t)
+	u.waitFor("app.txt")
+
+	u.press("enter")
+	u.waitFor("What did you work on?")
+	u.press("Add the app", "enter")
+	u.waitFor("Complete!")
+	u.waitFor("Saved 1 file(s)")
+
+	if got := r.Git("log", "-1", "--format=%s"); got != "Add the app" {
+		t.Errorf("last save is %q, want Add the app", got)
+	}
+	if got := r.Git("status", "--porcelain"); got != "" {
+		t.Errorf("status %q, want everything saved", got)
+	}
+
+	// Back on the menu there's nothing left to save
+	u.press("enter")
+	u.waitFor("No uncommitted changes")
+}
+
+func TestRevertFromMenu(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("app.txt", "broken\n")
+	r.Commit("Break the app")
+
+	u := startTUI(t)
+	u.waitFor("Branch: main")
+
+	u.press("down", "down")
+	u.waitFor("Restore your project to an earlier save point")
+	u.press("enter")
+	u.waitFor("Select a save point to revert back to:")
+	u.waitFor("Break the app")
+
+	// Pick the first save and confirm
+	u.press("down")
+	u.waitFor("File changes:")
+	u.press("enter")
+	u.waitFor("Are you sure? (y/n)")
+	u.press("y")
+	u.waitFor("Restored!")
+
+	if r.Head() != first {
+		t.Errorf("HEAD is %s, want the first save %s", r.Head(), first)
+	}
+	if got := r.Read("app.txt"); got != "" {
+		t.Errorf("app.txt = %q, want it gone", got)
+	}
+	if got := r.Git("branch", "--list", "backup/main/*"); got == "" {
+		t.Error("reverting should leave a backup")
+	}
+}
+
+func TestSaveDetailsFromRestore(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "hello\n")
+	r.Commit("Add the app")
+
+	u := startTUI(t)
+	u.waitFor("Branch: main")
+
+	u.press("down", "down", "enter")
+	u.waitFor("Add the app")
+	u.press("i")
+	u.waitFor("Save details")
+	u.waitFor("Files changed: 1")
+	u.waitFor("app.txt")
+
+	u.press("b")
+	u.waitFor("Name for the new branch:")
+	u.press("keep-this", "enter")
+	u.waitFor("Created keep-this at this save")
+	if got := r.Git("rev-parse", "keep-this"); got != r.Head() {
+		t.Errorf("keep-this is at %s, want %s", got, r.Head())
+	}
+
+	// Back to the list it was opened from
+	u.press("esc")
+	u.waitFor("Select a save point to revert back to:")
+}
+
+func TestKeysOverlay(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "hello\n")
+
+	u := startTUI(t)
+	u.waitFor("Branch: main")
+
+	u.press("?")
+	u.waitFor("On this screen:")
+	u.waitFor("learn more")
+	u.waitFor("Anywhere:")
+
+	// Any key closes it without doing anything else
+	u.press("enter")
+	u.waitFor("Branch: main")
+
+	// While typing a message, ? is just typed
+	u.press("enter")
+	u.waitFor("What did you work on?")
+	u.press("Why?", "enter")
+	u.waitFor("Complete!")
+	if got := r.Git("log", "-1", "--format=%s"); got != "Why?" {
+		t.Errorf("last save is %q, want Why?", got)
+	}
+}
+
+func TestQuitSavesFirst(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "hello\n")
+
+	u := startTUI(t)
+	u.waitFor("1 unsaved file")
+
+	u.press("q")
+	u.waitFor("save before quitting?")
+	u.press("esc")
+	u.waitFor("Branch: main")
+
+	u.press("q")
+	u.waitFor("save before quitting?")
+	u.press("s")
+	deadline := time.Now().Add(waitTimeout)
+	for r.Git("status", "--porcelain") != "" {
+		if time.Now().After(deadline) {
+			t.Fatal("quitting never saved app.txt")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := r.Git("log", "-1", "--format=%s"); got == "First save" {
+		t.Error("want a quick save made on the way out")
+	}
+}
+
+func TestSaveOnQuit(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "hello\n")
+	cfg := config.DefaultConfig()
+	cfg.SaveOnQuit = config.SaveOnQuitSave
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	u := startTUI(t)
+	u.waitFor("1 unsaved file")
+
+	u.press("q")
+	deadline := time.Now().Add(waitTimeout)
+	for r.Git("status", "--porcelain") != "" {
+		if time.Now().After(deadline) {
+			t.Fatal("quitting never saved app.txt")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := r.Git("log", "-1", "--format=%s"); !strings.HasPrefix(got, "WIP: quit at ") {
+		t.Errorf("want a timestamped save on the way out, got %q", got)
+	}
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// smoothDir holds smooth's per-project data; it is kept out of saves via
+// .git/info/exclude
+const smoothDir = ".smooth"
+
+// activityLogPath is the append-only log of git operations smooth ran
+var activityLogPath = filepath.Join(smoothDir, "activity.log")
+
+// ActivityEntry represents one git operation smooth ran
+type ActivityEntry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// isMutating checks if a git command changes the repository, its refs or
+// its configuration. Read-only commands aren't worth auditing.
+func isMutating(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	hasAny := func(flags ...string) bool {
+		for _, a := range args[1:] {
+			for _, f := range flags {
+				if a == f {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch args[0] {
+	case "commit", "reset", "merge", "rebase", "push", "checkout", "switch", "revert", "read-tree", "rm", "mv":
+		return true
+	case "stash":
+		return !hasAny("list", "show")
+	case "branch":
+		// Listing uses only flags; creating, deleting or renaming names a branch
+		if hasAny("-d", "-D", "-m", "-M", "--delete") {
+			return true
+		}
+		for _, a := range args[1:] {
+			if !strings.HasPrefix(a, "-") {
+				return true
+			}
+		}
+		return false
+	case "remote":
+		return hasAny("add", "remove", "rename", "set-url")
+	case "config":
+		return !hasAny("--get", "--get-all", "--get-regexp", "--list", "-l")
+	case "maintenance":
+		return hasAny("start", "register", "unregister", "stop")
+	}
+	return false
+}
+
+// recordActivity appends a mutating git command and its outcome to the
+// activity log. Logging is best-effort and never fails the command.
+func recordActivity(args []string, output string, err error) {
+	if !isMutating(args) {
+		return
+	}
+
+	entry := ActivityEntry{
+		Time:    time.Now(),
+		Command: "git " + strings.Join(args, " "),
+	}
+	if err != nil {
+		msg, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+		if msg == "" {
+			msg = err.Error()
+		}
+		entry.Error = msg
+	}
+
+	data, jsonErr := json.Marshal(entry)
+	if jsonErr != nil {
+		return
+	}
+
+	if EnsureSmoothDir() != nil {
+		return
+	}
+	f, openErr := os.OpenFile(activityLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// EnsureSmoothDir creates the .smooth directory in the project and keeps it
+// out of saves by listing it in .git/info/exclude
+func EnsureSmoothDir() error {
+	excludePath, err := Run("rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(smoothDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == smoothDir+"/" {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	prefix := ""
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		prefix = "\n"
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(prefix + smoothDir + "/\n")
+	return err
+}
+
+// ReadActivity returns up to limit entries from the activity log, newest
+// first. A limit of 0 returns everything.
+func ReadActivity(limit int) ([]ActivityEntry, error) {
+	f, err := os.Open(activityLogPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ActivityEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ActivityEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry ActivityEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []ActivityEntry{}
+	}
+	return entries, nil
+}
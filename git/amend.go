@@ -0,0 +1,62 @@
+package git
+
+import "fmt"
+
+// LastSave returns the latest save on the current branch, and whether it's
+// already been uploaded to origin
+func LastSave() (CommitInfo, bool, error) {
+	commits, err := Log(0, 1)
+	if err != nil {
+		return CommitInfo{}, false, err
+	}
+	if len(commits) == 0 {
+		return CommitInfo{}, false, fmt.Errorf("no saves yet")
+	}
+	last := commits[0]
+	branch, err := CurrentBranch()
+	if err != nil {
+		return last, false, nil
+	}
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err != nil {
+		return last, false, nil
+	}
+	_, err = Run("merge-base", "--is-ancestor", last.FullHash, "origin/"+branch)
+	return last, err == nil, nil
+}
+
+// AmendLastSave replaces the last save with one that has message and also
+// whatever is staged. An empty message keeps the old one. If the save was
+// already uploaded the branch is backed up first, since syncing afterwards
+// has to replace the save on GitHub, and the backup's name is returned.
+func AmendLastSave(message string, sign bool) (backup string, err error) {
+	_, uploaded, err := LastSave()
+	if err != nil {
+		return "", err
+	}
+	if uploaded {
+		branch, err := CurrentBranch()
+		if err != nil {
+			return "", err
+		}
+		if backup, err = CreateBackup(branch, BackupBeforeAmend); err != nil {
+			return "", fmt.Errorf("couldn't back up first, so nothing was changed: %w", err)
+		}
+	}
+
+	args := []string{"commit", "--amend"}
+	if sign {
+		args = append(args, "-S")
+	}
+	if message == "" {
+		args = append(args, "--no-edit")
+	} else {
+		args = append(args, "-m", message)
+	}
+	if output, err := Run(args...); err != nil {
+		if isSigningFailure(output) {
+			return backup, &SigningError{Key: DetectSigningKey(), Output: output, Err: err}
+		}
+		return backup, &CommitError{Output: output, Err: err}
+	}
+	return backup, nil
+}
@@ -0,0 +1,204 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupsPath holds why each backup was made, keyed by backup branch name.
+// Backups from before it existed simply have no entry.
+var backupsPath = filepath.Join(smoothDir, "backups.json")
+
+// BackupReason is what smooth was about to do when it made a backup
+type BackupReason string
+
+const (
+	BackupBeforeRestore BackupReason = "pre-restore"
+	BackupBeforeMerge   BackupReason = "pre-merge"
+	BackupBeforeTidy    BackupReason = "pre-tidy"
+	BackupBeforePurge   BackupReason = "pre-purge"
+	BackupBeforeAmend   BackupReason = "pre-amend"
+	BackupManual        BackupReason = "manual"
+)
+
+// Describe returns the reason the way the Backups screen says it
+func (r BackupReason) Describe() string {
+	switch r {
+	case BackupBeforeRestore:
+		return "Before a restore"
+	case BackupBeforeMerge:
+		return "Before getting teammates' saves"
+	case BackupBeforeTidy:
+		return "Before tidying saves"
+	case BackupBeforePurge:
+		return "Before removing a file from history"
+	case BackupBeforeAmend:
+		return "Before editing an uploaded save"
+	case BackupManual:
+		return "Made by you"
+	}
+	return ""
+}
+
+// BackupMeta is what's remembered about a backup besides its branch
+type BackupMeta struct {
+	Reason  BackupReason `json:"reason"`
+	Note    string       `json:"note,omitempty"`
+	From    string       `json:"from"`              // full hash of the save the branch was on
+	Unsaved bool         `json:"unsaved,omitempty"` // the backup's last commit holds unsaved changes, not a save
+	Created time.Time    `json:"created"`
+}
+
+// Time returns when the backup was made
+func (b BackupInfo) Time() time.Time {
+	t, err := time.ParseInLocation("20060102-150405", b.Timestamp, time.Local)
+	if err != nil {
+		return b.Created
+	}
+	return t
+}
+
+// BackupsBeyond returns the backups after the newest keep, given backups
+// newest first as ListBackups returns them
+func BackupsBeyond(backups []BackupInfo, keep int) []BackupInfo {
+	if keep < 0 {
+		keep = 0
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+	return backups[keep:]
+}
+
+// BackupsOlderThan returns the backups made more than age ago
+func BackupsOlderThan(backups []BackupInfo, age time.Duration) []BackupInfo {
+	cutoff := time.Now().Add(-age)
+	var old []BackupInfo
+	for _, b := range backups {
+		if b.Time().Before(cutoff) {
+			old = append(old, b)
+		}
+	}
+	return old
+}
+
+// DeleteBackups deletes several backups at once
+func DeleteBackups(backups []BackupInfo) error {
+	names := make([]string, len(backups))
+	for i, b := range backups {
+		names[i] = b.Name
+	}
+	return DeleteBranches(names)
+}
+
+// BackupsSize estimates the space deleting backups would free: the size on
+// disk of everything only they keep. Git frees it when it next tidies up.
+func BackupsSize(backups []BackupInfo) (int64, error) {
+	if len(backups) == 0 {
+		return 0, nil
+	}
+	args := []string{"rev-list", "--objects"}
+	for _, b := range backups {
+		args = append(args, "refs/heads/"+b.Name)
+	}
+	args = append(args, "--not")
+	for _, b := range backups {
+		args = append(args, "--exclude=refs/heads/"+b.Name)
+	}
+	args = append(args, "--all")
+	output, err := Run(args...)
+	if err != nil {
+		return 0, err
+	}
+	if output == "" {
+		return 0, nil
+	}
+
+	var objects strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		hash, _, _ := strings.Cut(line, " ")
+		objects.WriteString(hash + "\n")
+	}
+	sizes, err := runGit([]string{"cat-file", "--batch-check=%(objectsize:disk)"}, func(cmd *exec.Cmd) {
+		cmd.Stdin = strings.NewReader(objects.String())
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, line := range strings.Fields(string(sizes)) {
+		n, _ := strconv.ParseInt(line, 10, 64)
+		total += n
+	}
+	return total, nil
+}
+
+// CountBackups counts the backups of a branch without looking into each
+// one, for when only the number is shown
+func CountBackups(forBranch string) int {
+	output, err := Run("for-each-ref", "--format=%(refname)", "refs/heads/backup/"+forBranch+"/")
+	if err != nil || output == "" {
+		return 0
+	}
+	return len(strings.Split(output, "\n"))
+}
+
+// SavesAfter counts the saves on the current branch that ref doesn't have,
+// which restoring ref would undo
+func SavesAfter(ref string) int {
+	output, err := Run("rev-list", "--count", ref+"..HEAD")
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(output)
+	return n
+}
+
+// loadBackupMeta returns the metadata of every backup that has some
+func loadBackupMeta() (map[string]BackupMeta, error) {
+	meta := make(map[string]BackupMeta)
+	data, err := os.ReadFile(backupsPath)
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", backupsPath, err)
+	}
+	return meta, nil
+}
+
+// saveBackupMeta writes the backup metadata to disk
+func saveBackupMeta(meta map[string]BackupMeta) error {
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(backupsPath, data, 0644)
+}
+
+// updateBackupMeta loads the backup metadata, lets change edit it and
+// writes it back
+func updateBackupMeta(change func(map[string]BackupMeta)) error {
+	meta, err := loadBackupMeta()
+	if err != nil {
+		return err
+	}
+	before := len(meta)
+	change(meta)
+	if before == 0 && len(meta) == 0 {
+		return nil
+	}
+	return saveBackupMeta(meta)
+}
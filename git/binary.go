@@ -0,0 +1,61 @@
+package git
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif" // register formats for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strconv"
+)
+
+// binarySniffLen is how much of a file is checked for binary content,
+// the same amount git looks at
+const binarySniffLen = 8000
+
+// IsBinaryFile checks if the file at path is binary rather than text. Like
+// git, a NUL byte near the start means binary.
+func IsBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, binarySniffLen)
+	n, _ := io.ReadFull(f, buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// BinaryInfo describes a changed binary file, shown in place of a diff
+type BinaryInfo struct {
+	Size    int64  // bytes now, or -1 if it was deleted
+	OldSize int64  // bytes at the last save, or -1 if it's new
+	Format  string // image format like "png", empty if not an image Go can read
+	Width   int
+	Height  int
+}
+
+// GetBinaryInfo returns the size of a changed binary file before and
+// after, and its dimensions if it's an image
+func GetBinaryInfo(path string) BinaryInfo {
+	info := BinaryInfo{Size: -1, OldSize: -1}
+	if stat, err := os.Stat(path); err == nil {
+		info.Size = stat.Size()
+	}
+	if output, err := Run("cat-file", "-s", "HEAD:"+path); err == nil {
+		info.OldSize, _ = strconv.ParseInt(output, 10, 64)
+	}
+
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		if cfg, format, err := image.DecodeConfig(f); err == nil {
+			info.Format = format
+			info.Width = cfg.Width
+			info.Height = cfg.Height
+		}
+	}
+	return info
+}
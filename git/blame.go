@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is one line of a file with the save that last changed it
+type BlameLine struct {
+	Number  int
+	Text    string
+	Hash    string // full hash, or all zeros for unsaved lines
+	Author  string
+	Time    time.Time
+	Summary string // first line of the save message
+}
+
+// Unsaved checks if the line has changed since the last save
+func (l BlameLine) Unsaved() bool {
+	return strings.Trim(l.Hash, "0") == ""
+}
+
+// ShortHash returns the abbreviated hash, as shown elsewhere in smooth
+func (l BlameLine) ShortHash() string {
+	if len(l.Hash) > 7 {
+		return l.Hash[:7]
+	}
+	return l.Hash
+}
+
+// Blame returns every line of a file in the working tree with the save
+// that last changed it. Lines changed since the last save are included,
+// marked as unsaved.
+func Blame(path string) ([]BlameLine, error) {
+	output, err := RunRaw("blame", "--porcelain", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	return parseBlame(output), nil
+}
+
+// parseBlame reads `git blame --porcelain` output. Each line starts with a
+// "<hash> <orig line> <final line>" header; details about a commit follow
+// only the first time it appears, so they're remembered by hash.
+func parseBlame(output string) []BlameLine {
+	type commitInfo struct {
+		author  string
+		time    time.Time
+		summary string
+	}
+	commits := make(map[string]*commitInfo)
+
+	var lines []BlameLine
+	var current BlameLine
+	var info *commitInfo
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			if info == nil {
+				continue
+			}
+			current.Text = line[1:]
+			current.Author = info.author
+			current.Time = info.time
+			current.Summary = info.summary
+			lines = append(lines, current)
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			info.author = value
+		case "author-time":
+			secs, _ := strconv.ParseInt(value, 10, 64)
+			info.time = time.Unix(secs, 0)
+		case "summary":
+			info.summary = value
+		default:
+			// A header: 40 hex characters, then line numbers
+			fields := strings.Fields(line)
+			if len(key) != 40 || len(fields) < 3 {
+				continue
+			}
+			number, _ := strconv.Atoi(fields[2])
+			current = BlameLine{Hash: key, Number: number}
+			if commits[key] == nil {
+				commits[key] = &commitInfo{}
+			}
+			info = commits[key]
+		}
+	}
+	return lines
+}
+
+// RestoreFileFrom puts one file back the way it was in a save, leaving the
+// rest of the project alone. The change shows up as unsaved, and the
+// current files are snapshotted first.
+func RestoreFileFrom(hash, path string) error {
+	snapshotBefore("restoring " + path)
+	_, err := Run("restore", "--source="+hash, "--worktree", "--", path)
+	return err
+}
+
+// ListFiles returns every file smooth is tracking
+func ListFiles() ([]string, error) {
+	output, err := Run("-c", "core.quotePath=false", "ls-files")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return []string{}, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"strings"
+
+	"smooth/platform"
+)
+
+// checkCommandKey is the git config key holding the project's check
+// command. It's kept in .git/config, so it's set per project on this
+// computer and isn't synced: a command from a cloned project would run on
+// every save without anyone having looked at it.
+const checkCommandKey = "smooth.checkCommand"
+
+// CheckCommand returns the command to run before each save in this
+// project, like a formatter or the tests, or "" if there isn't one
+func CheckCommand() string {
+	output, _ := Run("config", "--get", checkCommandKey)
+	return output
+}
+
+// SetCheckCommand sets the command to run before each save in this
+// project. An empty command removes it.
+func SetCheckCommand(command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		if CheckCommand() == "" {
+			return nil
+		}
+		_, err := Run("config", "--unset", checkCommandKey)
+		return err
+	}
+	_, err := Run("config", checkCommandKey, command)
+	return err
+}
+
+// RunCheckCommand runs a check command from the project root and returns
+// everything it printed
+func RunCheckCommand(command string) (string, error) {
+	cmd, err := platform.ShellCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if root, err := Run("rev-parse", "--show-toplevel"); err == nil {
+		cmd.Dir = root
+	}
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// HasPreCommitHook checks if git will run a pre-commit hook when saving.
+// core.hooksPath is respected; git only runs hooks that are executable,
+// which Windows doesn't track.
+func HasPreCommitHook() bool {
+	path, err := Run("rev-parse", "--git-path", "hooks/pre-commit")
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return platform.IsWindows() || info.Mode()&0111 != 0
+}
+
+// CommitError is returned when git refuses to make a save, with what git
+// (and any hooks it ran) printed
+type CommitError struct {
+	Output string
+	Err    error
+}
+
+func (e *CommitError) Error() string {
+	if e.Output != "" {
+		return e.Output
+	}
+	return e.Err.Error()
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// CommitSkippingHooks creates a commit without running the project's
+// pre-commit and commit-msg hooks
+func CommitSkippingHooks(message string) error {
+	if output, err := Run("commit", "--no-verify", "-m", message); err != nil {
+		if isSigningFailure(output) {
+			return &SigningError{Key: DetectSigningKey(), Output: output, Err: err}
+		}
+		return &CommitError{Output: output, Err: err}
+	}
+	return nil
+}
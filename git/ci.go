@@ -0,0 +1,82 @@
+package git
+
+import (
+	"fmt"
+)
+
+// CIState sums up the checks, like GitHub Actions, that GitHub ran on a save
+type CIState string
+
+const (
+	CINone    CIState = "none" // no checks have started, maybe none are set up
+	CIPending CIState = "pending"
+	CIPassed  CIState = "passed"
+	CIFailed  CIState = "failed"
+)
+
+// CIStatus is how the checks GitHub ran on a save went
+type CIStatus struct {
+	Commit string
+	State  CIState
+	Total  int      // checks started so far
+	Failed []string // names of the checks that failed
+	URL    string   // the save's checks on GitHub
+}
+
+// failedConclusions are the ways a finished check can fail
+var failedConclusions = map[string]bool{
+	"failure":         true,
+	"timed_out":       true,
+	"cancelled":       true,
+	"action_required": true,
+	"startup_failure": true,
+}
+
+// CheckCI asks GitHub's Checks API how the checks on commit are going.
+// Public repositories answer without a token; private ones need one, found
+// like pull requests find theirs.
+func CheckCI(commit string) (CIStatus, error) {
+	owner, repo, err := GitHubRepo()
+	if err != nil {
+		return CIStatus{}, err
+	}
+	token, _ := githubToken()
+
+	var result struct {
+		TotalCount int `json:"total_count"`
+		CheckRuns  []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs?per_page=100", owner, repo, commit)
+	if _, err := githubRequest("GET", path, token, nil, &result); err != nil {
+		return CIStatus{}, err
+	}
+
+	status := CIStatus{
+		Commit: commit,
+		State:  CINone,
+		Total:  result.TotalCount,
+		URL:    fmt.Sprintf("https://github.com/%s/%s/commit/%s/checks", owner, repo, commit),
+	}
+	pending := false
+	for _, run := range result.CheckRuns {
+		if run.Status != "completed" {
+			pending = true
+		} else if failedConclusions[run.Conclusion] {
+			status.Failed = append(status.Failed, run.Name)
+		}
+	}
+	// A failure is worth knowing about before the rest finish
+	switch {
+	case len(status.Failed) > 0:
+		status.State = CIFailed
+	case pending:
+		status.State = CIPending
+	case status.Total > 0:
+		status.State = CIPassed
+	}
+	return status, nil
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CleanupCandidate is an experiment or backup branch that's safe to consider
+// deleting, because it's old or everything on it is already in main
+type CleanupCandidate struct {
+	Name       string
+	Kind       string // "experiment" or "backup"
+	LastCommit time.Time
+	Merged     bool // every save on it is already in main
+	Unique     int  // saves only this branch has, lost if it's deleted
+}
+
+// IsMerged checks if every commit on branch is already part of into
+func IsMerged(branch, into string) bool {
+	_, err := Run("merge-base", "--is-ancestor", branch, into)
+	return err == nil
+}
+
+// UniqueCommits counts the commits on any of branches that aren't on into.
+// Commits shared by several branches are counted once.
+func UniqueCommits(into string, branches ...string) int {
+	if len(branches) == 0 {
+		return 0
+	}
+	args := append([]string{"rev-list", "--count"}, branches...)
+	output, err := Run(append(args, "^"+into)...)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(output)
+	return n
+}
+
+// FindCleanupCandidates returns experiment and backup branches whose last
+// commit is older than olderThan, or that are already merged into main,
+// oldest first. The current branch is never included.
+func FindCleanupCandidates(olderThan time.Duration) ([]CleanupCandidate, error) {
+	output, err := Run("for-each-ref", "--sort=committerdate",
+		"--format=%(refname:short)|%(committerdate:unix)",
+		"refs/heads/experiment-*", "refs/heads/backup/")
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := CurrentBranch()
+	mainBranch := GetMainBranch()
+	cutoff := time.Now().Add(-olderThan)
+
+	candidates := []CleanupCandidate{}
+	for _, line := range strings.Split(output, "\n") {
+		name, unix, ok := strings.Cut(line, "|")
+		if !ok || name == current {
+			continue
+		}
+		secs, _ := strconv.ParseInt(unix, 10, 64)
+		c := CleanupCandidate{
+			Name:       name,
+			Kind:       "experiment",
+			LastCommit: time.Unix(secs, 0),
+			Merged:     IsMerged(name, mainBranch),
+		}
+		if strings.HasPrefix(name, "backup/") {
+			c.Kind = "backup"
+		}
+		if !c.Merged && !c.LastCommit.Before(cutoff) {
+			continue
+		}
+		if !c.Merged {
+			c.Unique = UniqueCommits(mainBranch, name)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// DeleteBranches deletes several branches at once
+func DeleteBranches(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	args := append([]string{"branch", "-D"}, names...)
+	if _, err := Run(args...); err != nil {
+		return err
+	}
+	updateBackupMeta(func(meta map[string]BackupMeta) {
+		for _, name := range names {
+			delete(meta, name)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// githubShorthand matches "owner/repo", which is cloned from GitHub
+var githubShorthand = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// NormalizeCloneURL tidies up a project address typed by the user. GitHub's
+// "owner/repo" shorthand and web addresses both work.
+func NormalizeCloneURL(url string) string {
+	url = strings.TrimSpace(url)
+	if githubShorthand.MatchString(url) {
+		return "https://github.com/" + url + ".git"
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// CloneDir returns the folder a clone of url goes in, named after the
+// project like git does. A name that isn't a new folder, like "..", or
+// that git would take for an option is refused.
+func CloneDir(url string) (string, error) {
+	dir := strings.TrimSuffix(NormalizeCloneURL(url), ".git")
+	if i := strings.LastIndexAny(dir, "/:\\"); i >= 0 {
+		dir = dir[i+1:]
+	}
+	if dir == "" || dir == "." || dir == ".." || strings.HasPrefix(dir, "-") {
+		return "", fmt.Errorf("%s doesn't end in a project name to clone into", url)
+	}
+	return dir, nil
+}
+
+// Clone downloads the project at url into dir, calling progress whenever
+// git reports how far it's got
+func Clone(url, dir string, progress func(Progress)) error {
+	// -- keeps an address starting with - from being taken for an option
+	cmd := command("clone", "--progress", "--", NormalizeCloneURL(url), dir)
+	// A password prompt would be hidden behind the TUI, so fail instead
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := runWithProgress(context.Background(), cmd, progress)
+	if err != nil {
+		var lines []string
+		for _, line := range output {
+			if !strings.HasPrefix(line, "Cloning into") {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			return fmt.Errorf("%s", strings.Join(lines, "\n"))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comparison is everything that differs between two save points
+type Comparison struct {
+	From    CommitInfo
+	To      CommitInfo // zero when comparing with the files as they are now
+	Summary CommitDiffSummary
+	Diff    string
+}
+
+// WorkingTree reports if the comparison is with the files as they are now,
+// unsaved changes included, rather than a save
+func (c Comparison) WorkingTree() bool {
+	return c.To.FullHash == ""
+}
+
+// LookupCommit returns the save that ref names, like a hash or a branch
+func LookupCommit(ref string) (CommitInfo, error) {
+	// Anything starting with - would be read as an option
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return CommitInfo{}, fmt.Errorf("%q isn't a save", ref)
+	}
+	output, err := Run("log", "-1", "--format=%h|%G?|%cr|%H|%s", ref+"^{commit}", "--")
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("%q isn't a save", ref)
+	}
+	parts := strings.SplitN(output, "|", 5)
+	if len(parts) != 5 {
+		return CommitInfo{}, fmt.Errorf("%q isn't a save", ref)
+	}
+	return CommitInfo{
+		Hash:      parts[0],
+		Signature: parts[1],
+		Timestamp: parts[2],
+		FullHash:  parts[3],
+		Message:   parts[4],
+	}, nil
+}
+
+// ResolveCommit returns the full hash of the save ref names. Refs that
+// start with - are turned away, since git would read them as options.
+func ResolveCommit(ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("%q isn't a save", ref)
+	}
+	hash, err := Run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || hash == "" {
+		return "", fmt.Errorf("%q isn't a save", ref)
+	}
+	return hash, nil
+}
+
+// Compare diffs two saves. If to is empty, from is compared with the files
+// as they are now. New files that were never saved aren't included.
+func Compare(from, to string) (Comparison, error) {
+	var c Comparison
+	var err error
+	if c.From, err = LookupCommit(from); err != nil {
+		return c, err
+	}
+	if to != "" {
+		if c.To, err = LookupCommit(to); err != nil {
+			return c, err
+		}
+	}
+	if c.Summary, err = GetDiffStatBetweenCommits(c.From.FullHash, c.To.FullHash); err != nil {
+		return c, err
+	}
+	c.Diff, err = GetDiffBetweenCommits(c.From.FullHash, c.To.FullHash)
+	return c, err
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IsDetached checks if HEAD points at a commit rather than a branch, as it
+// does after checking out a save, tag or remote branch outside smooth.
+// CurrentBranch returns "HEAD" then.
+func IsDetached() bool {
+	if _, err := Run("symbolic-ref", "-q", "HEAD"); err == nil {
+		return false
+	}
+	_, err := Run("rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// DetachedInfo describes where a detached HEAD is
+type DetachedInfo struct {
+	Commit CommitInfo
+	Loose  int // saves made here that no branch has, lost when switching away
+}
+
+// GetDetachedInfo returns the commit HEAD is detached at and how many saves
+// would be left behind by switching to a branch
+func GetDetachedInfo() (DetachedInfo, error) {
+	commits, err := Log(0, 1)
+	if err != nil {
+		return DetachedInfo{}, err
+	}
+	if len(commits) == 0 {
+		return DetachedInfo{}, fmt.Errorf("no saves yet")
+	}
+	info := DetachedInfo{Commit: commits[0]}
+	if output, err := Run("rev-list", "--count", "HEAD", "--not", "--branches"); err == nil {
+		info.Loose, _ = strconv.Atoi(output)
+	}
+	return info, nil
+}
+
+// CreateBranchHere makes a branch at the detached HEAD and switches to it,
+// keeping any unsaved changes
+func CreateBranchHere(name string) error {
+	if err := checkNewBranchName(name); err != nil {
+		return err
+	}
+	if output, err := Run("switch", "-c", name); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// checkNewBranchName makes sure name can be used for a new branch
+func checkNewBranchName(name string) error {
+	if _, err := Run("check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("%q isn't a valid name", name)
+	}
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		return fmt.Errorf("there's already a branch called %s", name)
+	}
+	return nil
+}
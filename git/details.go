@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitDetails is everything about one save: who made it and when, its
+// whole message, the saves it came from and the files it changed
+type CommitDetails struct {
+	CommitInfo
+	Author      string
+	AuthorEmail string
+	Date        time.Time
+	Body        string   // the whole message, not just the first line
+	Parents     []string // short hashes, none for the first save and two for a merge
+	Summary     CommitDiffSummary
+}
+
+// GetCommitDetails looks up everything about the save ref names. The files
+// changed are compared with the first parent, or with nothing for the first
+// save.
+func GetCommitDetails(ref string) (CommitDetails, error) {
+	info, err := LookupCommit(ref)
+	if err != nil {
+		return CommitDetails{}, err
+	}
+	d := CommitDetails{CommitInfo: info}
+
+	output, err := Run("log", "-1", "--format=%an%x00%ae%x00%at%x00%p%x00%B", info.FullHash, "--")
+	if err != nil {
+		return d, err
+	}
+	parts := strings.SplitN(output, "\x00", 5)
+	if len(parts) != 5 {
+		return d, fmt.Errorf("couldn't read save %s", info.Hash)
+	}
+	d.Author = parts[0]
+	d.AuthorEmail = parts[1]
+	if secs, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+		d.Date = time.Unix(secs, 0)
+	}
+	d.Parents = strings.Fields(parts[3])
+	d.Body = strings.TrimSpace(parts[4])
+
+	base := emptyTree
+	if len(d.Parents) > 0 {
+		base = info.FullHash + "^"
+	}
+	d.Summary, err = GetDiffStatBetweenCommits(base, info.FullHash)
+	return d, err
+}
+
+// CreateBranchAt makes a branch at a save without switching to it
+func CreateBranchAt(name, commitHash string) error {
+	if err := checkNewBranchName(name); err != nil {
+		return err
+	}
+	if output, err := Run("branch", name, commitHash); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dailyDir holds a summary of each day wrapped up at the end of day
+var dailyDir = filepath.Join(smoothDir, "daily")
+
+// dateLayout names each day's summary file
+const dateLayout = "2006-01-02"
+
+// DaySave is one save made during a day
+type DaySave struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// DaySummary describes the work done in a project on one day
+type DaySummary struct {
+	Date    string    `json:"date"` // YYYY-MM-DD
+	Branch  string    `json:"branch"`
+	Saves   []DaySave `json:"saves"` // newest first, on every branch
+	Files   []string  `json:"files"` // touched by the day's saves
+	Added   int       `json:"added"`
+	Deleted int       `json:"deleted"`
+
+	WIPHash   string    `json:"wipHash,omitempty"`   // the save made of unsaved work, if any
+	WIPError  string    `json:"wipError,omitempty"`  // why unsaved work couldn't be saved
+	Synced    bool      `json:"synced"`              // whether the day was uploaded
+	SyncError string    `json:"syncError,omitempty"` // why the upload failed
+	Created   time.Time `json:"created"`
+}
+
+// SummarizeDay collects the saves made on day's date, on every branch, and
+// the files and lines they changed
+func SummarizeDay(day time.Time) (DaySummary, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	summary := DaySummary{Date: start.Format(dateLayout), Saves: []DaySave{}, Files: []string{}, Created: time.Now()}
+	summary.Branch, _ = CurrentBranch()
+
+	window := []string{"--branches", "--since=" + start.Format(time.RFC3339), "--until=" + end.Format(time.RFC3339)}
+
+	output, err := Run(append([]string{"log", "--format=%h%x00%cI%x00%s"}, window...)...)
+	if err != nil {
+		return summary, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		when, _ := time.Parse(time.RFC3339, parts[1])
+		summary.Saves = append(summary.Saves, DaySave{Hash: parts[0], Time: when, Message: parts[2]})
+	}
+
+	output, err = Run(append([]string{"log", "--numstat", "--format="}, window...)...)
+	if err != nil {
+		return summary, err
+	}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Binary files show - instead of line counts
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		summary.Added += added
+		summary.Deleted += deleted
+		if !seen[fields[2]] {
+			seen[fields[2]] = true
+			summary.Files = append(summary.Files, fields[2])
+		}
+	}
+	sort.Strings(summary.Files)
+	return summary, nil
+}
+
+// SaveDaySummary writes a day's summary to .smooth/daily, replacing any
+// earlier one for the same day
+func SaveDaySummary(summary DaySummary) error {
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dailyDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dailyDir, summary.Date+".json"), data, 0644)
+}
+
+// HasDaySummary checks if day has already been wrapped up
+func HasDaySummary(day time.Time) bool {
+	_, err := os.Stat(filepath.Join(dailyDir, day.Format(dateLayout)+".json"))
+	return err == nil
+}
+
+// RecentDaySummaries returns up to n summaries, newest first
+func RecentDaySummaries(n int) []DaySummary {
+	entries, err := os.ReadDir(dailyDir)
+	if err != nil {
+		return nil
+	}
+	var summaries []DaySummary
+	for i := len(entries) - 1; i >= 0 && len(summaries) < n; i-- {
+		data, err := os.ReadFile(filepath.Join(dailyDir, entries[i].Name()))
+		if err != nil {
+			continue
+		}
+		var summary DaySummary
+		if json.Unmarshal(data, &summary) == nil && summary.Date != "" {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// eolAttribute is the .gitattributes rule that lets git normalize line endings
+const eolAttribute = "* text=auto"
+
+// LineEndingChurn returns the changed files whose only differences from the
+// last save are line endings (CRLF vs LF)
+func LineEndingChurn() ([]string, error) {
+	all, err := Run("diff", "HEAD", "--numstat")
+	if err != nil {
+		return nil, err
+	}
+	if all == "" {
+		return nil, nil
+	}
+
+	// With CRs at line ends ignored, line-ending-only files show no changes
+	ignoringCR, err := Run("diff", "HEAD", "--numstat", "--ignore-cr-at-eol")
+	if err != nil {
+		return nil, err
+	}
+	realChanges := make(map[string]bool)
+	for _, line := range strings.Split(ignoringCR, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) == 3 && (parts[0] != "0" || parts[1] != "0") {
+			realChanges[parts[2]] = true
+		}
+	}
+
+	var churn []string
+	for _, line := range strings.Split(all, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 3 || parts[0] == "-" || (parts[0] == "0" && parts[1] == "0") {
+			continue
+		}
+		if !realChanges[parts[2]] {
+			churn = append(churn, parts[2])
+		}
+	}
+	return churn, nil
+}
+
+// HasEOLAttributes checks if .gitattributes already normalizes line endings
+func HasEOLAttributes() bool {
+	data, err := os.ReadFile(".gitattributes")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == eolAttribute {
+			return true
+		}
+	}
+	return false
+}
+
+// AddEOLAttributes adds a "* text=auto" rule to .gitattributes so git
+// stores text files with LF endings regardless of platform
+func AddEOLAttributes() error {
+	if HasEOLAttributes() {
+		return nil
+	}
+
+	prefix := ""
+	if data, err := os.ReadFile(".gitattributes"); err == nil && len(data) > 0 && data[len(data)-1] != '\n' {
+		prefix = "\n"
+	}
+
+	f, err := os.OpenFile(".gitattributes", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(prefix + eolAttribute + "\n")
+	return err
+}
+
+// RecommendedAutoCRLF returns the core.autocrlf value suited to this OS
+func RecommendedAutoCRLF() string {
+	if runtime.GOOS == "windows" {
+		return "true"
+	}
+	return "input"
+}
+
+// SetAutoCRLF sets core.autocrlf for this repository
+func SetAutoCRLF(value string) error {
+	_, err := Run("config", "core.autocrlf", value)
+	return err
+}
+
+// NormalizeLineEndings adds the .gitattributes rule, re-applies it to the
+// given files, and commits the result. Other changes are left untouched.
+func NormalizeLineEndings(files []string) error {
+	if err := AddEOLAttributes(); err != nil {
+		return err
+	}
+	if _, err := Run("add", "--", ".gitattributes"); err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		args := append([]string{"add", "--renormalize", "--"}, files...)
+		if _, err := Run(args...); err != nil {
+			return err
+		}
+	}
+
+	// Commit only these paths so unrelated staged work stays put
+	paths := append([]string{".gitattributes"}, files...)
+	args := append([]string{"commit", "-m", "Normalize line endings", "--"}, paths...)
+	output, err := Run(args...)
+	if err != nil && strings.Contains(output, "nothing to commit") {
+		// Normalizing made the files identical to the last save
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The errors here say what went wrong in a way the UI can explain and
+// suggest a next step for, instead of passing on what git printed.
+
+// AuthError is returned when GitHub turned down the login: a wrong or
+// expired token over HTTPS, a password GitHub no longer accepts, or a
+// missing SSH key
+type AuthError struct {
+	Output string
+	Err    error // the *PushError, for pushes
+}
+
+func (e *AuthError) Error() string {
+	return "GitHub didn't accept your login: " + strings.TrimSpace(e.Output)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// authFailed checks git's output for GitHub turning down the login
+func authFailed(output string) bool {
+	for _, s := range []string{
+		"Authentication failed",
+		"could not read Username",
+		"could not read Password",
+		"Invalid username or password",
+		"The requested URL returned error: 403",
+		"The requested URL returned error: 401",
+		"Permission denied (publickey)",
+	} {
+		if strings.Contains(output, s) {
+			return true
+		}
+	}
+	return strings.Contains(output, "Permission to ") && strings.Contains(output, " denied to ")
+}
+
+// newPushError describes a failed push, as an *AuthError if the login was
+// the problem
+func newPushError(output string, err error) error {
+	push := &PushError{Output: output, Err: err}
+	if authFailed(output) {
+		return &AuthError{Output: output, Err: push}
+	}
+	return push
+}
+
+// MergeConflictError is returned when combining a branch stopped because
+// the same lines were changed on both sides. The merge is undone, so
+// nothing was changed.
+type MergeConflictError struct {
+	Branch string
+	Files  []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("%s changes the same lines as this branch in %d file(s): %s",
+		e.Branch, len(e.Files), strings.Join(e.Files, ", "))
+}
+
+// DirtyTreeError is returned when git refused to do something because it
+// would overwrite unsaved changes
+type DirtyTreeError struct {
+	Files []string
+}
+
+func (e *DirtyTreeError) Error() string {
+	return fmt.Sprintf("unsaved changes in %d file(s) would be overwritten: %s",
+		len(e.Files), strings.Join(e.Files, ", "))
+}
+
+// dirtyTreeError reads the files git listed when it refused to overwrite
+// unsaved changes or new files, or returns nil if that isn't what happened
+func dirtyTreeError(output string) *DirtyTreeError {
+	if !strings.Contains(output, "would be overwritten by") {
+		return nil
+	}
+	var e DirtyTreeError
+	for _, line := range strings.Split(output, "\n") {
+		// git lists the files indented by a tab under its message
+		if strings.HasPrefix(line, "\t") {
+			e.Files = append(e.Files, strings.TrimSpace(line))
+		}
+	}
+	return &e
+}
+
+// commandError turns what git printed when a command failed into one of
+// the errors above if it's one of them, or an error with git's message
+func commandError(output string, err error) error {
+	if dirty := dirtyTreeError(output); dirty != nil {
+		return dirty
+	}
+	if _, ok := err.(*LockError); ok {
+		return err
+	}
+	if output == "" {
+		return err
+	}
+	return fmt.Errorf("%s", output)
+}
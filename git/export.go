@@ -0,0 +1,83 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportArchive writes the project as it was at ref to a timestamped zip or
+// tar.gz in dir and returns the archive's path. An empty ref exports the
+// files as they are now, including unsaved changes and new files that
+// aren't ignored. Everything is inside a folder named after the project.
+func ExportArchive(ref, dir, format string) (string, error) {
+	ext := "zip"
+	if format == "tar.gz" {
+		ext = "tar.gz"
+	}
+
+	root, err := Run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	project := filepath.Base(root)
+
+	name := project
+	tree := ref
+	if ref == "" {
+		tree, err = workingTree()
+		if err != nil {
+			return "", fmt.Errorf("couldn't read your files: %w", err)
+		}
+	} else {
+		hash, err := Run("rev-parse", "--short", ref)
+		if err != nil {
+			return "", fmt.Errorf("couldn't find save %s", ref)
+		}
+		name += "-" + hash
+	}
+	name += "-" + time.Now().Format("20060102-150405") + "." + ext
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+
+	// From a subfolder, git would only archive that folder
+	args := []string{"archive", "--format=" + ext, "--prefix=" + project + "/", "-o", path, tree}
+	if output, err := runGit(args, func(cmd *exec.Cmd) { cmd.Dir = root }); err != nil {
+		return "", fmt.Errorf("couldn't write the archive: %s", strings.TrimSpace(string(output)))
+	}
+	return path, nil
+}
+
+// workingTree records the files as they are now, saved or not, as a git
+// tree. A throwaway copy of the index is used so nothing gets staged.
+func workingTree() (string, error) {
+	dir, err := os.MkdirTemp("", "smooth-export-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	// Starting from the real index means unchanged files aren't read again
+	index := filepath.Join(dir, "index")
+	if path, err := Run("rev-parse", "--git-path", "index"); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if err := os.WriteFile(index, data, 0644); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if _, err := runWithIndex(index, "add", "--all"); err != nil {
+		return "", err
+	}
+	return runWithIndex(index, "write-tree")
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Git file modes as they appear in `git status --porcelain=v2`
+const (
+	modeRegular    = "100644"
+	modeExecutable = "100755"
+	modeSymlink    = "120000"
+)
+
+// statusEntry is a changed file as `git status --porcelain=v2 -z` lists it
+type statusEntry struct {
+	Code    string // like "M." or "??"; v2 has . where v1 has a space
+	Path    string
+	OldPath string // where a renamed or copied file was
+	OldMode string // the mode at the last save; "" for untracked files
+	NewMode string // the mode on disk
+}
+
+// parseStatus reads `git status --porcelain=v2 -z` output
+func parseStatus(output string) []statusEntry {
+	var entries []statusEntry
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		line := fields[i]
+		// 1 XY sub mH mI mW hH hI path
+		// 2 XY sub mH mI mW hH hI score path, then the old path
+		// u XY sub m1 m2 m3 mW h1 h2 h3 path
+		// ? path
+		var parts []string
+		switch {
+		case strings.HasPrefix(line, "? "):
+			entries = append(entries, statusEntry{Code: "??", Path: line[2:]})
+			continue
+		case strings.HasPrefix(line, "1 "):
+			parts = strings.SplitN(line, " ", 9)
+		case strings.HasPrefix(line, "2 "):
+			parts = strings.SplitN(line, " ", 10)
+		case strings.HasPrefix(line, "u "):
+			if parts = strings.SplitN(line, " ", 11); len(parts) == 11 {
+				entries = append(entries, statusEntry{Code: parts[1], Path: parts[10]})
+			}
+			continue
+		default:
+			continue
+		}
+		if len(parts) < 9 {
+			continue
+		}
+		entry := statusEntry{Code: parts[1], Path: parts[len(parts)-1], OldMode: parts[3], NewMode: parts[5]}
+		if parts[0] == "2" {
+			if i+1 < len(fields) {
+				entry.OldPath = fields[i+1]
+			}
+			i++
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// DescribeModeChanges returns a plain-language label for files whose change
+// is about permissions or symlinks rather than content, keyed by path.
+// Files with ordinary content changes are not included. With no paths,
+// every changed file is checked.
+func DescribeModeChanges(paths ...string) map[string]string {
+	output, err := RunRaw(append([]string{"status", "--porcelain=v2", "-z", "--"}, paths...)...)
+	if err != nil {
+		return map[string]string{}
+	}
+	return modeLabels(parseStatus(output))
+}
+
+// modeLabels labels the entries whose change is about permissions or
+// symlinks, from the modes status already gave. Only files whose
+// permissions changed need a diff, to see if their content did too.
+func modeLabels(entries []statusEntry) map[string]string {
+	labels := make(map[string]string)
+	var permissions []statusEntry
+	for _, e := range entries {
+		if e.OldMode == "" || (e.OldMode == e.NewMode && e.NewMode != modeSymlink) {
+			continue
+		}
+		if isPermissionChange(e.OldMode, e.NewMode) {
+			permissions = append(permissions, e)
+			continue
+		}
+		// A link that's still a link only shows up because it points
+		// somewhere else
+		if label := describeModeChange(e.Path, e.OldMode, e.NewMode, true); label != "" {
+			labels[e.Path] = label
+		}
+	}
+	if len(permissions) == 0 {
+		return labels
+	}
+
+	// Files whose content also changed are described by their diff instead
+	args := []string{"diff", "HEAD", "--numstat", "-z", "--"}
+	for _, e := range permissions {
+		args = append(args, e.Path)
+	}
+	output, _ := RunRaw(args...)
+	contentChanged := make(map[string]bool)
+	for _, field := range strings.Split(output, "\x00") {
+		parts := strings.SplitN(field, "\t", 3)
+		if len(parts) == 3 && (parts[0] != "0" || parts[1] != "0") {
+			contentChanged[parts[2]] = true
+		}
+	}
+	for _, e := range permissions {
+		if label := describeModeChange(e.Path, e.OldMode, e.NewMode, contentChanged[e.Path]); label != "" {
+			labels[e.Path] = label
+		}
+	}
+	return labels
+}
+
+// isPermissionChange checks if a file went between modes other than a
+// symlink's, while staying in the project
+func isPermissionChange(oldMode, newMode string) bool {
+	return oldMode != newMode && oldMode != modeSymlink && newMode != modeSymlink &&
+		oldMode != "000000" && newMode != "000000"
+}
+
+// describeModeChange returns the label for a single file's mode change
+func describeModeChange(path, oldMode, newMode string, contentChanged bool) string {
+	switch {
+	case newMode == modeSymlink && oldMode == modeSymlink:
+		if !contentChanged {
+			return ""
+		}
+		return "link now points to " + linkTarget(path)
+	case newMode == modeSymlink:
+		return "replaced by a link to " + linkTarget(path)
+	case oldMode == modeSymlink && newMode != "000000":
+		return "was a link, now a regular file"
+	case contentChanged || oldMode == newMode:
+		return ""
+	case newMode == modeExecutable && oldMode == modeRegular:
+		return "permissions changed (now executable)"
+	case newMode == modeRegular && oldMode == modeExecutable:
+		return "permissions changed (no longer executable)"
+	case oldMode != "000000" && newMode != "000000":
+		return fmt.Sprintf("permissions changed (%s → %s)", oldMode, newMode)
+	}
+	return ""
+}
+
+// UntrackedLinkLabel returns a label for a new, untracked symlink, or ""
+// if the path isn't a symlink
+func UntrackedLinkLabel(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	return "new link to " + linkTarget(path)
+}
+
+// linkTarget returns where a symlink points, or a placeholder if unreadable
+func linkTarget(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "(unknown)"
+	}
+	return target
+}
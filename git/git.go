@@ -1,11 +1,15 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"smooth/platform"
 )
 
 // CommitInfo represents a simplified commit entry
@@ -14,25 +18,56 @@ type CommitInfo struct {
 	Message   string
 	Timestamp string
 	FullHash  string
+	Signature string // git's signature check: "G" good, "U" good but untrusted key, "N" unsigned, and so on
+}
+
+// Signed checks if the commit has a good signature
+func (c CommitInfo) Signed() bool {
+	return c.Signature == "G" || c.Signature == "U"
 }
 
 // BranchInfo represents a branch
 type BranchInfo struct {
 	Name      string
 	IsCurrent bool
+
+	// Set by ListExperiments, comparing the experiment with the main branch
+	LastActivity string // when its latest save was made, like "2 hours ago"
+	Ahead        int    // saves not on the main branch yet
+	FilesChanged int    // files it changes, since it split from main
+	LinesAdded   int
+	LinesDeleted int
+}
+
+// DiffSummary describes what an experiment changes in one line, like
+// "3 files +40 -12"
+func (b BranchInfo) DiffSummary() string {
+	if b.FilesChanged == 0 {
+		return "no changes"
+	}
+	files := fmt.Sprintf("%d files", b.FilesChanged)
+	if b.FilesChanged == 1 {
+		files = "1 file"
+	}
+	return fmt.Sprintf("%s +%d -%d", files, b.LinesAdded, b.LinesDeleted)
+}
+
+// command builds a git command with any config this OS needs
+func command(args ...string) *exec.Cmd {
+	return exec.Command("git", append(platform.GitArgs(), args...)...)
 }
 
 // Run executes a git command and returns the output (trimmed)
 func Run(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := runGit(args, nil)
+	recordActivity(args, string(output), err)
 	return strings.TrimSpace(string(output)), err
 }
 
 // RunRaw executes a git command and returns the raw output (preserves whitespace)
 func RunRaw(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := runGit(args, nil)
+	recordActivity(args, string(output), err)
 	return string(output), err
 }
 
@@ -69,27 +104,77 @@ func AddFiles(paths []string) error {
 	return err
 }
 
-// AddToGitignore adds a pattern to .gitignore
+// StageRemovals stages files that are gone, such as where renamed files
+// used to be. Ones that are already unstaged are left alone.
+func StageRemovals(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"rm", "--cached", "--quiet", "--ignore-unmatch", "--"}, paths...)
+	if output, err := Run(args...); err != nil {
+		return commandError(output, err)
+	}
+	return nil
+}
+
+// StagedFiles returns the paths currently staged for the next commit
+func StagedFiles() ([]string, error) {
+	// Both sides of a rename are listed, as they are both part of the save
+	output, err := RunRaw("diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// AddToGitignore adds a pattern to .gitignore, skipping patterns that are
+// already listed
 func AddToGitignore(pattern string) error {
-	// Read existing gitignore
+	pattern = platform.GitPath(pattern)
+	if HasGitignorePattern(pattern) {
+		return nil
+	}
+
+	// Match the file's line endings, and start on a fresh line if it
+	// doesn't end with one
+	prefix, newline := "", "\n"
+	if data, err := os.ReadFile(".gitignore"); err == nil && len(data) > 0 {
+		newline = platform.LineEnding(data)
+		if data[len(data)-1] != '\n' {
+			prefix = newline
+		}
+	}
+
 	f, err := os.OpenFile(".gitignore", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	// Add newline + pattern
-	if _, err := f.WriteString("\n" + pattern); err != nil {
+	if _, err := f.WriteString(prefix + pattern + newline); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Commit creates a commit with the given message
+// Commit creates a commit with the given message. If git refuses, the
+// error is a *CommitError with what it printed.
 func Commit(message string) error {
-	_, err := Run("commit", "-m", message)
-	return err
+	if output, err := Run("commit", "-m", message); err != nil {
+		if isSigningFailure(output) {
+			return &SigningError{Key: DetectSigningKey(), Output: output, Err: err}
+		}
+		return &CommitError{Output: output, Err: err}
+	}
+	return nil
 }
 
 // Push pushes the current branch to origin
@@ -126,24 +211,147 @@ func (e NoRemoteError) Error() string {
 		"3. Try syncing again"
 }
 
-func Push() error {
-	// Check if remote exists first
+// Push pushes the current branch to origin. Cancelling ctx stops the push,
+// and git fails instead of asking for a password nobody would see.
+func Push(ctx context.Context) error {
+	return PushWithProgress(ctx, nil)
+}
+
+// PushWithProgress pushes like Push, calling progress whenever git reports
+// how far the upload has got. Cancelling ctx stops the push.
+func PushWithProgress(ctx context.Context, progress func(Progress)) error {
+	if !HasRemote() {
+		return NoRemoteError{}
+	}
+	branch, err := CurrentBranch()
+	if err != nil {
+		return err
+	}
+	return pushBranchWithProgress(ctx, "origin", branch, progress)
+}
+
+// pushBranch pushes a branch to origin. A successful push uploads any saves
+// that were waiting from a failed auto-sync, so the queue is cleared.
+func pushBranch(branch string, flags ...string) error {
+	args := append(append([]string{"push"}, flags...), "-u", "origin", branch)
+	if output, err := Run(args...); err != nil {
+		return newPushError(output, err)
+	}
+	pushed(branch)
+	return nil
+}
+
+// pushBranchWithProgress pushes a branch to remote like pushBranch, with
+// git's progress passed to progress as it comes. If ctx stops the push, the
+// error is context.Canceled or a *TimeoutError. Only origin becomes the
+// branch's upstream, and only pushing there clears the auto-sync queue.
+func pushBranchWithProgress(ctx context.Context, remote, branch string, progress func(Progress), flags ...string) error {
+	args := append([]string{"push", "--progress"}, flags...)
+	if remote == "origin" {
+		args = append(args, "-u")
+	}
+	args = append(args, remote, branch)
+	if err := runPush(ctx, args, progress); err != nil {
+		return err
+	}
+	if remote == "origin" {
+		pushed(branch)
+	}
+	return nil
+}
+
+// runPush runs a git push with --progress, passing git's progress to
+// progress as it comes
+func runPush(ctx context.Context, args []string, progress func(Progress)) error {
+	start := time.Now()
+	done := markBusy("git push")
+	cmd := commandContext(ctx, args...)
+	// A password prompt would be hidden behind the TUI, so fail instead
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	lines, err := runWithProgress(ctx, cmd, progress)
+	done()
+	output := strings.Join(lines, "\n")
+	logCommand(args, time.Since(start), []byte(output), err)
+	recordActivity(args, output, err)
+	if err != nil {
+		if ctxErr := contextError(ctx, start); ctxErr != nil {
+			return ctxErr
+		}
+		return newPushError(output, err)
+	}
+	return nil
+}
+
+// pushed clears the auto-sync queue once branch has been pushed
+func pushed(branch string) {
+	if pending, ok := LoadPendingSync(); ok && pending.Branch == branch {
+		ClearPendingSync()
+	}
+}
+
+// ForcePush pushes the current branch to origin, replacing whatever is
+// there. It still refuses if origin has changed since it was last fetched,
+// so saves nobody has seen aren't lost.
+func ForcePush() error {
 	if !HasRemote() {
 		return NoRemoteError{}
 	}
+	branch, err := CurrentBranch()
+	if err != nil {
+		return err
+	}
+	return pushBranch(branch, "--force-with-lease")
+}
 
+// ForcePushWithProgress force pushes like ForcePush, calling progress
+// whenever git reports how far the upload has got. Cancelling ctx stops
+// the push.
+func ForcePushWithProgress(ctx context.Context, progress func(Progress)) error {
+	if !HasRemote() {
+		return NoRemoteError{}
+	}
 	branch, err := CurrentBranch()
 	if err != nil {
 		return err
 	}
-	_, err = Run("push", "-u", "origin", branch)
-	return err
+	return pushBranchWithProgress(ctx, "origin", branch, progress, "--force-with-lease")
+}
+
+// PushError is returned when git couldn't push, with what it printed
+type PushError struct {
+	Output string
+	Err    error
 }
 
-// Log returns a list of recent commits
-func Log(count int) ([]CommitInfo, error) {
-	format := "%h|%s|%cr|%H"
-	output, err := Run("log", fmt.Sprintf("-%d", count), fmt.Sprintf("--format=%s", format))
+func (e *PushError) Error() string {
+	if e.Output != "" {
+		return e.Output
+	}
+	return e.Err.Error()
+}
+
+func (e *PushError) Unwrap() error {
+	return e.Err
+}
+
+// AuthFailed checks if origin turned down the login; such pushes fail
+// with an *AuthError wrapping the PushError
+func (e *PushError) AuthFailed() bool {
+	return authFailed(e.Output)
+}
+
+// Rejected checks if origin refused the push because it has saves that
+// aren't here, rather than because of a network or login problem
+func (e *PushError) Rejected() bool {
+	return strings.Contains(e.Output, "[rejected]") || strings.Contains(e.Output, "non-fast-forward")
+}
+
+// Log returns up to limit commits on the current branch, newest first,
+// after skipping the newest offset, so long histories can be loaded a page
+// at a time
+func Log(offset, limit int) ([]CommitInfo, error) {
+	format := "%h|%G?|%cr|%H|%s"
+	output, err := Run("log", fmt.Sprintf("--skip=%d", offset), fmt.Sprintf("-%d", limit), fmt.Sprintf("--format=%s", format))
 	if err != nil {
 		return nil, err
 	}
@@ -155,25 +363,56 @@ func Log(count int) ([]CommitInfo, error) {
 	var commits []CommitInfo
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) == 4 {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) == 5 {
 			commits = append(commits, CommitInfo{
 				Hash:      parts[0],
-				Message:   parts[1],
+				Signature: parts[1],
 				Timestamp: parts[2],
 				FullHash:  parts[3],
+				Message:   parts[4],
 			})
 		}
 	}
 	return commits, nil
 }
 
+// CommitBefore returns the last commit on the current branch made at or
+// before t, which is how the project looked at that time, along with when
+// it was made
+func CommitBefore(t time.Time) (CommitInfo, time.Time, error) {
+	output, err := Run("log", "-1", "--before="+t.Format("2006-01-02 15:04:05 -0700"), "--format=%h|%cr|%H|%ct|%s")
+	if err != nil {
+		return CommitInfo{}, time.Time{}, err
+	}
+	parts := strings.SplitN(output, "|", 5)
+	if len(parts) != 5 {
+		return CommitInfo{}, time.Time{}, fmt.Errorf("no saves from before %s", t.Format("Jan 2 15:04"))
+	}
+	secs, _ := strconv.ParseInt(parts[3], 10, 64)
+	commit := CommitInfo{
+		Hash:      parts[0],
+		Timestamp: parts[1],
+		FullHash:  parts[2],
+		Message:   parts[4],
+	}
+	return commit, time.Unix(secs, 0), nil
+}
+
 // ResetHard resets to the specified commit
 func ResetHard(commitHash string) error {
+	snapshotBefore("reverting")
 	_, err := Run("reset", "--hard", commitHash)
 	return err
 }
 
+// ResetKeepFiles moves the branch back to the specified commit but leaves
+// the files alone, so what the later commits changed becomes uncommitted
+func ResetKeepFiles(commitHash string) error {
+	_, err := Run("reset", "--mixed", "--quiet", commitHash)
+	return err
+}
+
 // HasChanges checks if there are uncommitted changes
 func HasChanges() bool {
 	output, err := Run("status", "--porcelain")
@@ -324,34 +563,41 @@ func GetDiffFull() string {
 
 // FileChange represents a changed file
 type FileChange struct {
-	Status string // "added", "modified", "deleted", "renamed"
-	Path   string
+	Status     string // "added", "modified", "deleted", "renamed"
+	Path       string
+	OldPath    string // Where a renamed file was before
+	Similarity int    // How much of a renamed file is unchanged, in percent
+	Detail     string // Set when the change is only to permissions or a symlink
 }
 
-// GetChangeSummary returns a summary of all changed files
+// DisplayPath returns the path to show for the change, with where it came
+// from if it was renamed
+func (c FileChange) DisplayPath() string {
+	if c.OldPath != "" {
+		return renameLabel(c.OldPath, c.Path, c.Similarity)
+	}
+	return c.Path
+}
+
+// GetChangeSummary returns a summary of all changed files. New folders are
+// listed file by file, so each file can be saved or skipped on its own.
 func GetChangeSummary() ([]FileChange, error) {
-	output, err := Run("status", "--porcelain")
+	// -z leaves paths unquoted, and puts a rename's old path in its own
+	// field. Version 2 also has each file's mode, to spot permission and
+	// symlink changes without another pass.
+	output, err := RunRaw("status", "--porcelain=v2", "-z", "--untracked-files=all")
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
 	}
+	entries := parseStatus(output)
 
-	if output == "" {
-		return []FileChange{}, nil
-	}
-
-	var changes []FileChange
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if len(line) < 3 {
-			continue
-		}
-
-		statusCode := line[:2]
-		path := strings.TrimSpace(line[2:])
+	changes := []FileChange{}
+	for _, entry := range entries {
+		statusCode, path, oldPath := entry.Code, entry.Path, entry.OldPath
 
 		var status string
 		switch {
-		case statusCode[0] == 'A' || statusCode[1] == 'A' || statusCode == "??":
+		case statusCode[0] == 'A' || statusCode[1] == 'A' || statusCode[0] == 'C' || statusCode == "??":
 			status = "added"
 		case statusCode[0] == 'D' || statusCode[1] == 'D':
 			status = "deleted"
@@ -361,10 +607,28 @@ func GetChangeSummary() ([]FileChange, error) {
 			status = "modified"
 		}
 
-		changes = append(changes, FileChange{
+		change := FileChange{
 			Status: status,
 			Path:   path,
-		})
+		}
+		if status == "renamed" {
+			change.OldPath = oldPath
+		}
+		changes = append(changes, change)
+	}
+
+	// A file moved without git mv shows up deleted in one place and new
+	// in another
+	changes = pairRenames(changes)
+
+	// Label permission-only and symlink changes, which otherwise look empty
+	labels := modeLabels(entries)
+	for i, c := range changes {
+		if label, ok := labels[c.Path]; ok {
+			changes[i].Detail = label
+		} else if c.Status == "added" {
+			changes[i].Detail = UntrackedLinkLabel(c.Path)
+		}
 	}
 
 	return changes, nil
@@ -389,16 +653,57 @@ func CreateExperiment(name string) (string, error) {
 	return branchName, err
 }
 
+// CreateExperimentAt creates an experiment branch starting at an earlier
+// save and switches to it. The branch it was on keeps its saves; unsaved
+// changes come along if they don't clash with the save.
+func CreateExperimentAt(name, commitHash string) (string, error) {
+	timestamp := time.Now().Format("20060102-150405")
+	branchName := fmt.Sprintf("experiment-%s-%s", name, timestamp)
+	if output, err := Run("checkout", "-b", branchName, commitHash); err != nil {
+		return "", commandError(output, err)
+	}
+	return branchName, nil
+}
+
+// OpenAsExperiment opens an earlier save as a new experiment named after
+// it, instead of reverting to it, so the branch it was on keeps its saves.
+// Unsaved changes come along; if they clash with the save they stay in the
+// stash and a *StashConflictError is returned, with the experiment made.
+func OpenAsExperiment(commitHash string) (string, error) {
+	short, err := Run("rev-parse", "--short", commitHash+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%s is not a save", commitHash)
+	}
+	var branchName string
+	err = WithAutoStash("opening "+short+" as an experiment", func() error {
+		var err error
+		branchName, err = CreateExperimentAt("from-"+short, commitHash)
+		return err
+	})
+	return branchName, err
+}
+
 // SwitchBranch switches to the specified branch
 func SwitchBranch(name string) error {
-	_, err := Run("checkout", name)
-	return err
+	if output, err := Run("checkout", name); err != nil {
+		return commandError(output, err)
+	}
+	return nil
 }
 
-// MergeBranch merges the specified branch into the current branch
+// MergeBranch merges the specified branch into the current branch. If the
+// two changed the same lines the merge is undone and a
+// *MergeConflictError lists the files.
 func MergeBranch(name string) error {
-	_, err := Run("merge", name)
-	return err
+	output, err := Run("merge", name)
+	if err == nil {
+		return nil
+	}
+	if files, _ := ConflictedFiles(); len(files) > 0 {
+		Run("merge", "--abort")
+		return &MergeConflictError{Branch: name, Files: files}
+	}
+	return commandError(output, err)
 }
 
 // DeleteBranch deletes the specified branch
@@ -407,6 +712,83 @@ func DeleteBranch(name string) error {
 	return err
 }
 
+// RenameRemoteError is returned when a branch was renamed here but its copy
+// on GitHub couldn't be renamed to match
+type RenameRemoteError struct {
+	Err error
+}
+
+func (e *RenameRemoteError) Error() string {
+	return "renamed here, but GitHub still has the old name: " + e.Err.Error()
+}
+
+func (e *RenameRemoteError) Unwrap() error {
+	return e.Err
+}
+
+// RenameBranch renames a local branch, along with its backups. If the
+// branch was pushed, the copy on GitHub is renamed too by pushing the new
+// name and deleting the old one; a failure there is a *RenameRemoteError.
+func RenameBranch(oldName, newName string) error {
+	if _, err := Run("check-ref-format", "--branch", newName); err != nil {
+		return fmt.Errorf("%q isn't a valid name", newName)
+	}
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/heads/"+newName); err == nil {
+		return fmt.Errorf("there's already a branch called %s", newName)
+	}
+	if _, err := Run("branch", "-m", oldName, newName); err != nil {
+		return err
+	}
+
+	// Backups follow the branch so Restore backup still finds them
+	if backups, err := ListBackups(oldName); err == nil {
+		renamed := make(map[string]string)
+		for _, b := range backups {
+			name := fmt.Sprintf("backup/%s/%s", newName, b.Timestamp)
+			if _, err := Run("branch", "-m", b.Name, name); err == nil {
+				renamed[b.Name] = name
+			}
+		}
+		updateBackupMeta(func(meta map[string]BackupMeta) {
+			for old, name := range renamed {
+				if m, ok := meta[old]; ok {
+					meta[name] = m
+					delete(meta, old)
+				}
+			}
+		})
+	}
+	if pending, ok := LoadPendingSync(); ok && pending.Branch == oldName {
+		pending.Branch = newName
+		savePendingSync(pending)
+	}
+
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+oldName); err != nil {
+		return nil
+	}
+	if err := pushBranch(newName); err != nil {
+		return &RenameRemoteError{Err: err}
+	}
+	if output, err := Run("push", "origin", "--delete", oldName); err != nil {
+		return &RenameRemoteError{Err: fmt.Errorf("%s", output)}
+	}
+	return nil
+}
+
+// RenameExperiment gives an experiment a new name, dropping the timestamp
+// it was created with, and returns the new branch name
+func RenameExperiment(oldName, name string) (string, error) {
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" {
+		return "", fmt.Errorf("the new name can't be empty")
+	}
+	branchName := "experiment-" + strings.TrimPrefix(name, "experiment-")
+	if branchName == oldName {
+		return branchName, nil
+	}
+	return branchName, RenameBranch(oldName, branchName)
+}
+
 // ListBranches returns all local branches
 func ListBranches() ([]BranchInfo, error) {
 	output, err := Run("branch", "--format=%(refname:short)|%(HEAD)")
@@ -432,32 +814,53 @@ func ListBranches() ([]BranchInfo, error) {
 	return branches, nil
 }
 
-// ListExperiments returns only experiment branches
+// ListExperiments returns only experiment branches, with when each was
+// last saved to and how it differs from the main branch
 func ListExperiments() ([]BranchInfo, error) {
 	branches, err := ListBranches()
 	if err != nil {
 		return nil, err
 	}
 
+	mainBranch := GetMainBranch()
 	var experiments []BranchInfo
 	for _, b := range branches {
 		if strings.HasPrefix(b.Name, "experiment-") {
+			describeExperiment(&b, mainBranch)
 			experiments = append(experiments, b)
 		}
 	}
 	return experiments, nil
 }
 
-// Stash stashes current changes
-func Stash() error {
-	_, err := Run("stash")
-	return err
-}
-
-// StashPop pops the stashed changes
-func StashPop() error {
-	_, err := Run("stash", "pop")
-	return err
+// describeExperiment fills in an experiment's last activity and how far it
+// is from main. Anything git can't tell is left empty.
+func describeExperiment(b *BranchInfo, mainBranch string) {
+	b.LastActivity, _ = Run("log", "-1", "--format=%cr", "refs/heads/"+b.Name, "--")
+	if output, err := Run("rev-list", "--count", "refs/heads/"+mainBranch+"..refs/heads/"+b.Name); err == nil {
+		b.Ahead, _ = strconv.Atoi(output)
+	}
+	// Since where it split from main, so changes made on main since don't count
+	output, err := Run("diff", "--shortstat", "refs/heads/"+mainBranch+"...refs/heads/"+b.Name)
+	if err != nil {
+		return
+	}
+	// " 3 files changed, 40 insertions(+), 12 deletions(-)"
+	for _, part := range strings.Split(output, ",") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			continue
+		}
+		n, _ := strconv.Atoi(fields[0])
+		switch {
+		case strings.HasPrefix(fields[1], "file"):
+			b.FilesChanged = n
+		case strings.HasPrefix(fields[1], "insertion"):
+			b.LinesAdded = n
+		case strings.HasPrefix(fields[1], "deletion"):
+			b.LinesDeleted = n
+		}
+	}
 }
 
 // IsOnMain checks if we're on the main or master branch
@@ -494,6 +897,7 @@ func KeepExperiment() error {
 	}
 
 	mainBranch := GetMainBranch()
+	snapshotBefore("keeping an experiment")
 
 	// Switch to main
 	if err := SwitchBranch(mainBranch); err != nil {
@@ -518,6 +922,7 @@ func AbandonExperiment() error {
 	}
 
 	mainBranch := GetMainBranch()
+	snapshotBefore("abandoning an experiment")
 
 	// Switch to main first
 	if err := SwitchBranch(mainBranch); err != nil {
@@ -528,6 +933,74 @@ func AbandonExperiment() error {
 	return DeleteBranch(currentBranch)
 }
 
+// archivePrefix is where archived experiments are kept, out of the way of
+// the experiments list but still branches
+const archivePrefix = "archived/"
+
+// ArchiveExperiment puts the current experiment away under archived/ and
+// switches to main. Unlike abandoning, its saves stay and it can be brought
+// back with UnarchiveExperiment.
+func ArchiveExperiment() (string, error) {
+	currentBranch, err := CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(currentBranch, "experiment-") {
+		return "", fmt.Errorf("%s isn't an experiment", currentBranch)
+	}
+	archived := archivePrefix + currentBranch
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/heads/"+archived); err == nil {
+		return "", fmt.Errorf("there's already an archived experiment called %s", currentBranch)
+	}
+
+	snapshotBefore("archiving an experiment")
+	if err := SwitchBranch(GetMainBranch()); err != nil {
+		return "", err
+	}
+	if output, err := Run("branch", "-m", currentBranch, archived); err != nil {
+		return "", fmt.Errorf("%s", output)
+	}
+	return archived, nil
+}
+
+// ListArchivedExperiments returns the archived experiments, most recently
+// saved to first, named as they were before being archived
+func ListArchivedExperiments() ([]BranchInfo, error) {
+	output, err := Run("for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads/"+archivePrefix)
+	if err != nil {
+		return nil, err
+	}
+	mainBranch := GetMainBranch()
+	var archived []BranchInfo
+	for _, name := range strings.Split(output, "\n") {
+		if name == "" {
+			continue
+		}
+		b := BranchInfo{Name: name}
+		describeExperiment(&b, mainBranch)
+		b.Name = strings.TrimPrefix(name, archivePrefix)
+		archived = append(archived, b)
+	}
+	return archived, nil
+}
+
+// UnarchiveExperiment moves an archived experiment back to the experiments
+// list, without switching to it
+func UnarchiveExperiment(name string) error {
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		return fmt.Errorf("there's already an experiment called %s", name)
+	}
+	if output, err := Run("branch", "-m", archivePrefix+name, name); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// DeleteArchivedExperiment deletes an archived experiment for good
+func DeleteArchivedExperiment(name string) error {
+	return DeleteBranch(archivePrefix + name)
+}
+
 // BackupInfo represents a backup branch
 type BackupInfo struct {
 	Name       string
@@ -535,20 +1008,53 @@ type BackupInfo struct {
 	Timestamp  string
 	CommitHash string
 	Message    string
+	BackupMeta // empty for backups made before smooth kept metadata
 }
 
-// CreateBackup creates a backup branch for the current state
+// CreateBackup creates a backup branch for the current state, remembering
+// why it was made
 // Format: backup/<branch-name>/<timestamp>
-func CreateBackup(forBranch string) (string, error) {
+func CreateBackup(forBranch string, reason BackupReason) (string, error) {
+	return createBackup(forBranch, "HEAD", BackupMeta{Reason: reason})
+}
+
+// BackUpNow is a backup the user asks for, with an optional label. Unsaved
+// changes are backed up too, in a commit on top of the latest save that
+// RestoreBackup turns back into unsaved changes.
+func BackUpNow(forBranch, label string) (string, error) {
+	meta := BackupMeta{Reason: BackupManual, Note: strings.TrimSpace(label)}
+	target := "HEAD"
+	if HasChanges() {
+		commit, err := snapshotCommit("Unsaved changes, backed up")
+		if err != nil {
+			return "", err
+		}
+		target = commit
+		meta.Unsaved = true
+	}
+	return createBackup(forBranch, target, meta)
+}
+
+// createBackup makes a backup branch at target and records meta for it
+func createBackup(forBranch, target string, meta BackupMeta) (string, error) {
 	timestamp := time.Now().Format("20060102-150405")
 	backupName := fmt.Sprintf("backup/%s/%s", forBranch, timestamp)
 
-	// Create the backup branch at current HEAD without switching to it
-	_, err := Run("branch", backupName)
+	// Create the backup branch without switching to it
+	_, err := Run("branch", backupName, target)
 	if err != nil {
 		return "", err
 	}
 
+	// The branch is the backup; losing its metadata only loses the label
+	if meta.From == "" {
+		meta.From, _ = Run("rev-parse", "HEAD")
+	}
+	meta.Created = time.Now()
+	updateBackupMeta(func(all map[string]BackupMeta) {
+		all[backupName] = meta
+	})
+
 	return backupName, nil
 }
 
@@ -566,6 +1072,8 @@ func ListBackups(forBranch string) ([]BackupInfo, error) {
 		return []BackupInfo{}, nil
 	}
 
+	meta, _ := loadBackupMeta()
+
 	var backups []BackupInfo
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
@@ -573,8 +1081,13 @@ func ListBackups(forBranch string) ([]BackupInfo, error) {
 			// Extract timestamp from branch name
 			timestamp := strings.TrimPrefix(line, prefix)
 
-			// Get the commit info for this backup
-			commitInfo, err := Run("log", "-1", "--format=%h|%s", line)
+			// Get the commit info for this backup: the save it was taken
+			// at, under any unsaved changes backed up with it
+			ref := line
+			if meta[line].Unsaved {
+				ref += "^"
+			}
+			commitInfo, err := Run("log", "-1", "--format=%h|%s", ref)
 			if err != nil {
 				continue
 			}
@@ -595,6 +1108,7 @@ func ListBackups(forBranch string) ([]BackupInfo, error) {
 				Timestamp:  timestamp,
 				CommitHash: hash,
 				Message:    message,
+				BackupMeta: meta[line],
 			})
 		}
 	}
@@ -607,14 +1121,29 @@ func ListBackups(forBranch string) ([]BackupInfo, error) {
 	return backups, nil
 }
 
-// RestoreBackup restores from a backup branch
+// RestoreBackup restores from a backup branch. Unsaved changes backed up
+// with it come back as unsaved changes.
 func RestoreBackup(backupBranch string) error {
-	return ResetHard(backupBranch)
+	meta, _ := loadBackupMeta()
+	if !meta[backupBranch].Unsaved {
+		return ResetHard(backupBranch)
+	}
+	if err := ResetHard(backupBranch + "^"); err != nil {
+		return err
+	}
+	_, err := Run("restore", "--source="+backupBranch, "--worktree", "--", ":/")
+	return err
 }
 
-// DeleteBackup deletes a backup branch
+// DeleteBackup deletes a backup branch and what's remembered about it
 func DeleteBackup(backupBranch string) error {
-	return DeleteBranch(backupBranch)
+	if err := DeleteBranch(backupBranch); err != nil {
+		return err
+	}
+	updateBackupMeta(func(meta map[string]BackupMeta) {
+		delete(meta, backupBranch)
+	})
+	return nil
 }
 
 // GetFileDiff returns the diff for a specific file
@@ -632,9 +1161,21 @@ func GetFileDiff(path string) string {
 		output, _ = Run("diff", "--", path)
 	}
 
+	// Spell out permission and symlink changes, whose diffs are otherwise
+	// just header lines
+	if label := DescribeModeChanges(path)[path]; label != "" {
+		return label + "\n\n" + output
+	}
+
 	// For untracked files, show the file content as "added"
 	if output == "" {
 		status, _ := Run("status", "--porcelain", "--", path)
+		if label := UntrackedLinkLabel(path); strings.HasPrefix(status, "??") && label != "" {
+			return fmt.Sprintf("new file: %s\n%s\n", path, label)
+		}
+		if strings.HasPrefix(status, "??") && IsBinaryFile(path) {
+			return fmt.Sprintf("new file: %s\nBinary file, not shown as text\n", path)
+		}
 		if strings.HasPrefix(status, "??") {
 			// Untracked file - show content as new file
 			content, err := os.ReadFile(path)
@@ -663,26 +1204,40 @@ func GetFileDiff(path string) string {
 
 // RevertFile discards changes for a specific file, restoring it to HEAD
 func RevertFile(path string) error {
+	snapshotBefore("reverting " + path)
 	_, err := Run("checkout", "HEAD", "--", path)
 	return err
 }
 
-// RevertFiles discards changes for multiple files
+// RevertFiles discards changes for multiple files. Paths not in the last
+// save are removed, so reverting both sides of a rename undoes it.
 func RevertFiles(paths []string) error {
 	if len(paths) == 0 {
 		return nil
 	}
-	args := append([]string{"checkout", "HEAD", "--"}, paths...)
+	snapshotBefore(fmt.Sprintf("reverting %d file(s)", len(paths)))
+	args := append([]string{"restore", "--source=HEAD", "--staged", "--worktree", "--"}, paths...)
 	_, err := Run(args...)
 	return err
 }
 
 // DiffStat represents the diff statistics for a file
 type DiffStat struct {
-	Path      string
-	Additions int
-	Deletions int
-	IsBinary  bool
+	Path       string
+	OldPath    string // Where a renamed file was before
+	Similarity int    // How much of a renamed file is unchanged, in percent
+	Additions  int
+	Deletions  int
+	IsBinary   bool
+}
+
+// DisplayPath returns the path to show for the file, with where it came
+// from if it was renamed
+func (d DiffStat) DisplayPath() string {
+	if d.OldPath != "" {
+		return renameLabel(d.OldPath, d.Path, d.Similarity)
+	}
+	return d.Path
 }
 
 // CommitDiffSummary represents the summary of changes between commits
@@ -697,30 +1252,46 @@ type CommitDiffSummary struct {
 func GetDiffStatBetweenCommits(fromHash, toHash string) (CommitDiffSummary, error) {
 	var summary CommitDiffSummary
 
-	// Build the diff command
-	args := []string{"diff", "--numstat"}
+	// Build the diff command. --raw comes first and has how similar each
+	// rename is, which --numstat leaves out.
+	args := []string{"diff", "--raw", "--numstat", "-z", renamesFlag, "--end-of-options"}
 	if toHash == "" {
 		args = append(args, fromHash)
 	} else {
 		args = append(args, fromHash, toHash)
 	}
 
-	output, err := Run(args...)
+	output, err := RunRaw(args...)
 	if err != nil {
-		return summary, err
+		return summary, fmt.Errorf("%s", strings.TrimSpace(output))
 	}
 
-	if output == "" {
-		return summary, nil
-	}
+	similarity := make(map[string]int)
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if field == "" {
+			continue
+		}
 
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if line == "" {
+		// A --raw record: ":mode mode hash hash status", then its paths
+		if strings.HasPrefix(field, ":") {
+			parts := strings.Fields(field)
+			status := parts[len(parts)-1]
+			if status[0] == 'R' || status[0] == 'C' {
+				if i+2 < len(fields) {
+					similarity[fields[i+2]], _ = strconv.Atoi(status[1:])
+				}
+				i += 2
+			} else {
+				i++
+			}
 			continue
 		}
 
-		parts := strings.Fields(line)
+		// A --numstat record: "added\tdeleted\tpath", with the path left
+		// empty and both paths following for a rename
+		parts := strings.SplitN(field, "\t", 3)
 		if len(parts) < 3 {
 			continue
 		}
@@ -728,6 +1299,12 @@ func GetDiffStatBetweenCommits(fromHash, toHash string) (CommitDiffSummary, erro
 		stat := DiffStat{
 			Path: parts[2],
 		}
+		if stat.Path == "" && i+2 < len(fields) {
+			stat.OldPath = fields[i+1]
+			stat.Path = fields[i+2]
+			stat.Similarity = similarity[stat.Path]
+			i += 2
+		}
 
 		// Binary files show "-" for additions/deletions
 		if parts[0] == "-" {
@@ -747,13 +1324,32 @@ func GetDiffStatBetweenCommits(fromHash, toHash string) (CommitDiffSummary, erro
 
 // GetUncommittedDiffStat returns the diff stats for uncommitted changes
 func GetUncommittedDiffStat() (CommitDiffSummary, error) {
+	return uncommittedDiffStat(nil)
+}
+
+// GetUncommittedDiffStatFor is GetUncommittedDiffStat for only the given
+// files, which is much quicker in huge projects
+func GetUncommittedDiffStatFor(paths []string) (CommitDiffSummary, error) {
+	if len(paths) == 0 {
+		return CommitDiffSummary{}, nil
+	}
+	return uncommittedDiffStat(paths)
+}
+
+// uncommittedDiffStat counts the lines changed in paths, or everywhere if
+// paths is nil
+func uncommittedDiffStat(paths []string) (CommitDiffSummary, error) {
 	var summary CommitDiffSummary
+	var pathspec []string
+	if paths != nil {
+		pathspec = append([]string{"--"}, paths...)
+	}
 
 	// Get diff stats for tracked files
-	output, err := Run("diff", "--numstat", "HEAD")
+	output, err := Run(append([]string{"diff", "--numstat", "HEAD"}, pathspec...)...)
 	if err != nil {
 		// Try without HEAD for new repos
-		output, _ = Run("diff", "--numstat")
+		output, _ = Run(append([]string{"diff", "--numstat"}, pathspec...)...)
 	}
 
 	if output != "" {
@@ -785,20 +1381,23 @@ func GetUncommittedDiffStat() (CommitDiffSummary, error) {
 		}
 	}
 
-	// Also get untracked files
-	status, _ := Run("status", "--porcelain")
-	if status != "" {
-		for _, line := range strings.Split(status, "\n") {
-			if strings.HasPrefix(line, "?? ") {
-				path := strings.TrimPrefix(line, "?? ")
-				lineCount := countFileLines(path)
-				summary.Files = append(summary.Files, DiffStat{
-					Path:      path + " (new)",
-					Additions: lineCount,
-				})
-				summary.TotalAdded += lineCount
-			}
+	// Also get untracked files, one by one like the change summary
+	status, _ := RunRaw(append([]string{"status", "--porcelain", "-z", "--untracked-files=all"}, pathspec...)...)
+	for _, entry := range strings.Split(status, "\x00") {
+		if !strings.HasPrefix(entry, "?? ") {
+			continue
+		}
+		path := strings.TrimPrefix(entry, "?? ")
+		if IsBinaryFile(path) {
+			summary.Files = append(summary.Files, DiffStat{Path: path + " (new)", IsBinary: true})
+			continue
 		}
+		lineCount := countFileLines(path)
+		summary.Files = append(summary.Files, DiffStat{
+			Path:      path + " (new)",
+			Additions: lineCount,
+		})
+		summary.TotalAdded += lineCount
 	}
 
 	return summary, nil
@@ -816,14 +1415,9 @@ func TrimBackups(forBranch string, maxCount int) error {
 		return err
 	}
 
-	// ListBackups returns newest first, so we keep the first maxCount
-	if len(backups) <= maxCount {
-		return nil
-	}
-
 	// Delete backups beyond the limit (oldest ones)
-	for i := maxCount; i < len(backups); i++ {
-		if err := DeleteBackup(backups[i].Name); err != nil {
+	for _, b := range BackupsBeyond(backups, maxCount) {
+		if err := DeleteBackup(b.Name); err != nil {
 			// Continue trying to delete others even if one fails
 			continue
 		}
@@ -831,3 +1425,118 @@ func TrimBackups(forBranch string, maxCount int) error {
 
 	return nil
 }
+
+// GraphLine is one row of `git log --graph` output. Rows that only draw
+// connecting lines between commits have a nil Commit.
+type GraphLine struct {
+	Graph  string
+	Commit *CommitInfo
+	Refs   []string
+}
+
+// Graph returns the commit graph for all branches (including experiments
+// and backups), newest first, limited to count commits
+func Graph(count int) ([]GraphLine, error) {
+	const sep = "\x1f"
+	format := sep + "%h" + sep + "%s" + sep + "%cr" + sep + "%H" + sep + "%D"
+	output, err := RunRaw("log", "--graph", "--exclude=refs/smooth/*", "--all", fmt.Sprintf("-%d", count), "--format="+format)
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+
+	var lines []GraphLine
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, sep)
+		if len(parts) < 6 {
+			lines = append(lines, GraphLine{Graph: line})
+			continue
+		}
+
+		var refs []string
+		if parts[5] != "" {
+			for _, ref := range strings.Split(parts[5], ", ") {
+				refs = append(refs, strings.TrimPrefix(ref, "HEAD -> "))
+			}
+		}
+
+		lines = append(lines, GraphLine{
+			Graph: parts[0],
+			Commit: &CommitInfo{
+				Hash:      parts[1],
+				Message:   parts[2],
+				Timestamp: parts[3],
+				FullHash:  parts[4],
+			},
+			Refs: refs,
+		})
+	}
+	return lines, nil
+}
+
+// GraphCommit is a commit with its parents and the refs pointing at it,
+// used to draw the commit graph outside the terminal
+type GraphCommit struct {
+	CommitInfo
+	Parents []string
+	Refs    []string
+}
+
+// CommitGraph returns commits from all branches in topological order,
+// newest first, limited to count commits
+func CommitGraph(count int) ([]GraphCommit, error) {
+	const sep = "\x1f"
+	format := "%h" + sep + "%s" + sep + "%cr" + sep + "%H" + sep + "%P" + sep + "%D" + sep + "%G?"
+	output, err := Run("log", "--exclude=refs/smooth/*", "--all", "--topo-order", fmt.Sprintf("-%d", count), "--format="+format)
+	if err != nil {
+		return nil, fmt.Errorf("%s", output)
+	}
+
+	commits := []GraphCommit{}
+	if output == "" {
+		return commits, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, sep)
+		if len(parts) < 7 {
+			continue
+		}
+
+		var refs []string
+		if parts[5] != "" {
+			for _, ref := range strings.Split(parts[5], ", ") {
+				refs = append(refs, strings.TrimPrefix(ref, "HEAD -> "))
+			}
+		}
+
+		commits = append(commits, GraphCommit{
+			CommitInfo: CommitInfo{
+				Hash:      parts[0],
+				Message:   parts[1],
+				Timestamp: parts[2],
+				FullHash:  parts[3],
+				Signature: parts[6],
+			},
+			Parents: strings.Fields(parts[4]),
+			Refs:    refs,
+		})
+	}
+	return commits, nil
+}
+
+// GetDiffBetweenCommits returns the full diff between two commits.
+// If toHash is empty, compares fromHash to the working tree.
+func GetDiffBetweenCommits(fromHash, toHash string) (string, error) {
+	args := []string{"diff", "--color=never", "--end-of-options", fromHash}
+	if toHash != "" {
+		args = append(args, toHash)
+	}
+	output, err := RunRaw(args...)
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	return output, nil
+}
@@ -0,0 +1,109 @@
+// Package gittest helps test code that runs git: a scripted fake
+// git.GitRunner for unit tests, and throwaway repositories for integration
+// tests against the real git.
+package gittest
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"smooth/git"
+)
+
+// Fake is a git.GitRunner that answers from a script instead of running
+// git, and remembers what it was asked to run
+type Fake struct {
+	t     testing.TB
+	mu    sync.Mutex
+	rules []*Rule
+	calls [][]string
+}
+
+// Rule is a scripted answer to git commands starting with some arguments
+type Rule struct {
+	prefix []string
+	output string
+	err    error
+	once   bool
+	used   bool
+}
+
+// Returns makes the command succeed, printing output
+func (r *Rule) Returns(output string) *Rule {
+	r.output, r.err = output, nil
+	return r
+}
+
+// Fails makes the command fail, printing output like git would on stderr
+func (r *Rule) Fails(output string) *Rule {
+	r.output, r.err = output, errors.New("exit status 128")
+	return r
+}
+
+// Once makes the rule answer only the first matching command, so a later
+// rule can answer the next one
+func (r *Rule) Once() *Rule {
+	r.once = true
+	return r
+}
+
+// NewFake makes git commands go through a new Fake until the test ends.
+// The test runs in an empty temporary folder, so what smooth writes next
+// to the project, like its activity log, doesn't end up in the source tree.
+func NewFake(t testing.TB) *Fake {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	f := &Fake{t: t}
+	t.Cleanup(git.SetRunner(f))
+	return f
+}
+
+// On scripts the answer to git commands starting with args. Rules are tried
+// in the order they were added; by default a command succeeds printing
+// nothing.
+func (f *Fake) On(args ...string) *Rule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := &Rule{prefix: args}
+	f.rules = append(f.rules, r)
+	return r
+}
+
+// Run answers a git command from the script. Commands nothing was scripted
+// for fail the test.
+func (f *Fake) Run(ctx context.Context, args []string, setup func(*exec.Cmd)) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, slices.Clone(args))
+	for _, r := range f.rules {
+		if r.used || len(args) < len(r.prefix) || !slices.Equal(args[:len(r.prefix)], r.prefix) {
+			continue
+		}
+		r.used = r.once
+		return []byte(r.output), r.err
+	}
+	f.t.Errorf("unscripted git command: git %s", strings.Join(args, " "))
+	return nil, errors.New("unscripted git command")
+}
+
+// Calls returns every command run so far, oldest first
+func (f *Fake) Calls() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// Ran checks if a command starting with args was run
+func (f *Fake) Ran(args ...string) bool {
+	for _, call := range f.Calls() {
+		if len(call) >= len(args) && slices.Equal(call[:len(args)], args) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Repo is a throwaway git repository the test runs in
+type Repo struct {
+	t   testing.TB
+	Dir string
+}
+
+// NewRepo creates a repository on main with one save, README.md, and runs
+// the test in it. Home is a temporary folder too, so the user's settings
+// and git config are left alone. Tests are skipped if git isn't installed.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Smooth Test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+
+	r := &Repo{t: t, Dir: t.TempDir()}
+	t.Chdir(r.Dir)
+	r.Git("init", "--quiet", "--initial-branch=main")
+	r.Git("config", "commit.gpgsign", "false")
+	r.Write("README.md", "# test\n")
+	r.Commit("First save")
+	return r
+}
+
+// Git runs a git command in the repository, failing the test if it fails
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Write writes a file in the repository, making folders as needed
+func (r *Repo) Write(path, content string) {
+	r.t.Helper()
+	full := filepath.Join(r.Dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Read returns a file's content, or "" if it doesn't exist
+func (r *Repo) Read(path string) string {
+	r.t.Helper()
+	data, err := os.ReadFile(filepath.Join(r.Dir, path))
+	if err != nil && !os.IsNotExist(err) {
+		r.t.Fatal(err)
+	}
+	return string(data)
+}
+
+// Commit saves everything, returning the new save's full hash
+func (r *Repo) Commit(message string) string {
+	r.t.Helper()
+	r.Git("add", "-A")
+	r.Git("commit", "--quiet", "-m", message)
+	return r.Head()
+}
+
+// Head returns the full hash of the current save
+func (r *Repo) Head() string {
+	r.t.Helper()
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Branch returns the current branch
+func (r *Repo) Branch() string {
+	r.t.Helper()
+	return r.Git("symbolic-ref", "--short", "HEAD")
+}
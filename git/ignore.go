@@ -0,0 +1,130 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitignorePattern represents a single pattern line in .gitignore
+type GitignorePattern struct {
+	Line        int // 1-based line number in .gitignore
+	Pattern     string
+	DuplicateOf int // line of the earlier identical pattern, or 0
+}
+
+// ReadGitignore returns the patterns in the root .gitignore, skipping
+// blank lines and comments. A missing file returns no patterns.
+func ReadGitignore() ([]GitignorePattern, error) {
+	data, err := os.ReadFile(".gitignore")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []GitignorePattern{}, nil
+		}
+		return nil, err
+	}
+
+	patterns := []GitignorePattern{}
+	seen := make(map[string]int)
+	for i, line := range strings.Split(string(data), "\n") {
+		pattern := strings.TrimSpace(line)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		p := GitignorePattern{Line: i + 1, Pattern: pattern}
+		if first, ok := seen[pattern]; ok {
+			p.DuplicateOf = first
+		} else {
+			seen[pattern] = p.Line
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// HasGitignorePattern checks if .gitignore already contains the pattern
+func HasGitignorePattern(pattern string) bool {
+	patterns, err := ReadGitignore()
+	if err != nil {
+		return false
+	}
+	pattern = strings.TrimSpace(pattern)
+	for _, p := range patterns {
+		if p.Pattern == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveGitignoreLines deletes the given 1-based line numbers from .gitignore
+func RemoveGitignoreLines(lines []int) error {
+	data, err := os.ReadFile(".gitignore")
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[int]bool)
+	for _, l := range lines {
+		remove[l] = true
+	}
+
+	var kept []string
+	for i, line := range strings.Split(string(data), "\n") {
+		if !remove[i+1] {
+			kept = append(kept, line)
+		}
+	}
+
+	return os.WriteFile(".gitignore", []byte(strings.Join(kept, "\n")), 0644)
+}
+
+// GitignoreMatches returns, for each .gitignore line number, the paths
+// from the given list that the pattern on that line matches. Tracked files
+// are included, since a pattern matching them is often a surprise.
+func GitignoreMatches(paths []string) map[int][]string {
+	matches := make(map[int][]string)
+	if len(paths) == 0 {
+		return matches
+	}
+
+	// The paths go in on stdin, since ignored folders can hold more files
+	// than fit on a command line. check-ignore exits with 1 when nothing
+	// matches, so ignore the error.
+	output, _ := runGit([]string{"check-ignore", "--no-index", "-v", "-z", "--stdin"}, func(cmd *exec.Cmd) {
+		cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00"))
+	})
+
+	// Format: <source>\0<line>\0<pattern>\0<path>\0
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+3 < len(fields); i += 4 {
+		if fields[i] != ".gitignore" {
+			continue
+		}
+		lineNum, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			continue
+		}
+		matches[lineNum] = append(matches[lineNum], fields[i+3])
+	}
+	return matches
+}
+
+// IgnoredFiles returns the untracked files .gitignore keeps out of saves.
+// A folder that's ignored as a whole is listed once, like "build/".
+func IgnoredFiles() ([]string, error) {
+	output, err := RunRaw("ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
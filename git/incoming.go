@@ -0,0 +1,70 @@
+package git
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// FetchIncoming downloads the current branch from origin and returns how
+// many new saves arrived that this copy of the project doesn't have yet.
+// Saves seen by an earlier fetch aren't counted again. Cancelling ctx stops
+// the download.
+func FetchIncoming(ctx context.Context) (int, error) {
+	if !HasRemote() {
+		return 0, NoRemoteError{}
+	}
+	branch, err := CurrentBranch()
+	if err != nil {
+		return 0, err
+	}
+
+	remoteRef := "refs/remotes/origin/" + branch
+	before, _ := Run("rev-parse", "--verify", "--quiet", remoteRef)
+	if _, err := RunContext(ctx, "fetch", "--quiet", "origin", branch); err != nil {
+		return 0, err
+	}
+	after, err := Run("rev-parse", "--verify", "--quiet", remoteRef)
+	if err != nil || after == before {
+		return 0, nil
+	}
+
+	args := []string{"rev-list", "--count", after, "^HEAD"}
+	if before != "" {
+		args = append(args, "^"+before)
+	}
+	output, err := Run(args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(output)
+	return n, nil
+}
+
+// AheadBehind returns how many saves on the current branch haven't been
+// uploaded to origin, and how many on origin haven't been downloaded, as of
+// the last fetch. A branch origin doesn't have yet counts all its saves as
+// not uploaded. Without a remote both are 0.
+func AheadBehind() (ahead, behind int) {
+	if !HasRemote() {
+		return 0, 0
+	}
+	branch, err := CurrentBranch()
+	if err != nil {
+		return 0, 0
+	}
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err != nil {
+		output, _ := Run("rev-list", "--count", "HEAD")
+		ahead, _ = strconv.Atoi(output)
+		return ahead, 0
+	}
+	output, err := Run("rev-list", "--left-right", "--count", "HEAD...origin/"+branch)
+	if err != nil {
+		return 0, 0
+	}
+	if fields := strings.Fields(output); len(fields) == 2 {
+		ahead, _ = strconv.Atoi(fields[0])
+		behind, _ = strconv.Atoi(fields[1])
+	}
+	return ahead, behind
+}
@@ -0,0 +1,684 @@
+package git_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"smooth/git"
+	"smooth/git/gittest"
+	_ "smooth/scan" // masks secrets in the debug log
+)
+
+func TestResetHard(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("notes.txt", "v2\n")
+	r.Commit("Second save")
+	r.Write("notes.txt", "unsaved\n")
+
+	if err := git.ResetHard(first); err != nil {
+		t.Fatalf("ResetHard: %v", err)
+	}
+	if r.Head() != first {
+		t.Errorf("HEAD is %s, want %s", r.Head(), first)
+	}
+	if got := r.Read("notes.txt"); got != "" {
+		t.Errorf("notes.txt = %q, want it gone", got)
+	}
+}
+
+func TestResetKeepFiles(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("notes.txt", "v2\n")
+	r.Commit("Second save")
+
+	if err := git.ResetKeepFiles(first); err != nil {
+		t.Fatalf("ResetKeepFiles: %v", err)
+	}
+	if r.Head() != first {
+		t.Errorf("HEAD is %s, want %s", r.Head(), first)
+	}
+	if got := r.Read("notes.txt"); got != "v2\n" {
+		t.Errorf("notes.txt = %q, want the later save's version kept", got)
+	}
+	if !git.HasChanges() {
+		t.Error("the later save's changes should be unsaved")
+	}
+}
+
+func TestLog(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("a.txt", "a\n")
+	r.Commit("Second save")
+	r.Write("a.txt", "b\n")
+	r.Commit("Third save")
+
+	commits, err := git.Log(0, 2)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Message != "Third save" || commits[1].Message != "Second save" {
+		t.Errorf("got %+v, want the two newest saves", commits)
+	}
+	older, err := git.Log(2, 2)
+	if err != nil || len(older) != 1 || older[0].Message != "First save" {
+		t.Errorf("got %+v, %v, want the first save on the next page", older, err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("app.txt", "good\n")
+	good := r.Commit("Good save")
+
+	backup, err := git.CreateBackup("main", git.BackupBeforeRestore)
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	r.Write("app.txt", "broken\n")
+	r.Commit("Broken save")
+
+	backups, err := git.ListBackups("main")
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("ListBackups: got %+v, %v, want one backup", backups, err)
+	}
+	if b := backups[0]; b.Name != backup || b.Message != "Good save" || b.Reason != git.BackupBeforeRestore {
+		t.Errorf("got %+v, want the good save backed up before a restore", b)
+	}
+	if n := git.SavesAfter(backup); n != 1 {
+		t.Errorf("SavesAfter = %d, want 1", n)
+	}
+	if n := git.CountBackups("main"); n != 1 {
+		t.Errorf("CountBackups = %d, want 1", n)
+	}
+
+	if err := git.RestoreBackup(backup); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if r.Head() != good || r.Read("app.txt") != "good\n" {
+		t.Errorf("restored to %s with %q, want the good save", r.Head(), r.Read("app.txt"))
+	}
+}
+
+func TestBackUpNowKeepsUnsavedChanges(t *testing.T) {
+	r := gittest.NewRepo(t)
+	head := r.Head()
+	r.Write("draft.txt", "unsaved work\n")
+
+	backup, err := git.BackUpNow("main", "before trying something")
+	if err != nil {
+		t.Fatalf("BackUpNow: %v", err)
+	}
+	backups, _ := git.ListBackups("main")
+	if len(backups) != 1 || !backups[0].Unsaved || backups[0].Note != "before trying something" {
+		t.Fatalf("got %+v, want one labeled backup with unsaved changes", backups)
+	}
+	if r.Head() != head {
+		t.Error("backing up shouldn't make a save")
+	}
+
+	r.Git("clean", "-fd", "--", "draft.txt")
+	if err := git.RestoreBackup(backup); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if r.Head() != head {
+		t.Errorf("HEAD is %s, want %s", r.Head(), head)
+	}
+	if got := r.Read("draft.txt"); got != "unsaved work\n" {
+		t.Errorf("draft.txt = %q, want the unsaved work back", got)
+	}
+}
+
+func TestDeleteBackups(t *testing.T) {
+	r := gittest.NewRepo(t)
+	backup, err := git.CreateBackup("main", git.BackupBeforeRestore)
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	backups, _ := git.ListBackups("main")
+	if err := git.DeleteBackups(backups); err != nil {
+		t.Fatalf("DeleteBackups: %v", err)
+	}
+	if left, _ := git.ListBackups("main"); len(left) != 0 {
+		t.Errorf("got %+v, want no backups left", left)
+	}
+	if n := git.CountBackups("main"); n != 0 {
+		t.Errorf("CountBackups = %d, want 0", n)
+	}
+	if out := r.Git("branch", "--list", backup); out != "" {
+		t.Errorf("backup branch %s still exists", backup)
+	}
+}
+
+func TestKeepExperiment(t *testing.T) {
+	r := gittest.NewRepo(t)
+	name, err := git.CreateExperiment("idea")
+	if err != nil {
+		t.Fatalf("CreateExperiment: %v", err)
+	}
+	if !strings.HasPrefix(name, "experiment-idea-") || r.Branch() != name {
+		t.Fatalf("on %s after creating %s", r.Branch(), name)
+	}
+	if git.IsOnMain() {
+		t.Error("IsOnMain on an experiment")
+	}
+	r.Write("idea.txt", "it works\n")
+	r.Commit("Try the idea")
+
+	experiments, err := git.ListExperiments()
+	if err != nil || len(experiments) != 1 || experiments[0].Name != name || experiments[0].Ahead != 1 {
+		t.Errorf("ListExperiments: got %+v, %v, want %s one save ahead", experiments, err, name)
+	}
+
+	if err := git.KeepExperiment(); err != nil {
+		t.Fatalf("KeepExperiment: %v", err)
+	}
+	if r.Branch() != "main" || r.Read("idea.txt") != "it works\n" {
+		t.Errorf("on %s with idea.txt %q, want main with the experiment merged", r.Branch(), r.Read("idea.txt"))
+	}
+}
+
+func TestAbandonExperiment(t *testing.T) {
+	r := gittest.NewRepo(t)
+	main := r.Head()
+	name, err := git.CreateExperiment("dead-end")
+	if err != nil {
+		t.Fatalf("CreateExperiment: %v", err)
+	}
+	r.Write("dead-end.txt", "nope\n")
+	r.Commit("Try a dead end")
+
+	if err := git.AbandonExperiment(); err != nil {
+		t.Fatalf("AbandonExperiment: %v", err)
+	}
+	if r.Branch() != "main" || r.Head() != main {
+		t.Errorf("on %s at %s, want main unchanged", r.Branch(), r.Head())
+	}
+	if out := r.Git("branch", "--list", name); out != "" {
+		t.Errorf("experiment %s still exists", name)
+	}
+}
+
+func TestOpenAsExperiment(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("app.txt", "later\n")
+	later := r.Commit("Later save")
+	r.Write("notes.txt", "unsaved\n")
+
+	name, err := git.OpenAsExperiment(first)
+	if err != nil {
+		t.Fatalf("OpenAsExperiment: %v", err)
+	}
+	if !strings.HasPrefix(name, "experiment-from-") || r.Branch() != name {
+		t.Fatalf("on %s, want the new experiment %s", r.Branch(), name)
+	}
+	if r.Head() != first || r.Read("app.txt") != "" {
+		t.Errorf("at %s with app.txt %q, want the first save", r.Head(), r.Read("app.txt"))
+	}
+	if got := r.Read("notes.txt"); got != "unsaved\n" {
+		t.Errorf("notes.txt = %q, want the unsaved changes brought along", got)
+	}
+	if got := r.Git("rev-parse", "main"); got != later {
+		t.Errorf("main is at %s, want it left at %s", got, later)
+	}
+}
+
+func TestGetCommitDetails(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("app.txt", "one\ntwo\n")
+	r.Git("add", "-A")
+	r.Git("commit", "-q", "-m", "Add the app", "-m", "It says one and two.")
+
+	d, err := git.GetCommitDetails("HEAD")
+	if err != nil {
+		t.Fatalf("GetCommitDetails: %v", err)
+	}
+	if d.Message != "Add the app" || d.Body != "Add the app\n\nIt says one and two." {
+		t.Errorf("message %q, body %q", d.Message, d.Body)
+	}
+	if d.Author == "" || d.AuthorEmail == "" || d.Date.IsZero() {
+		t.Errorf("got %+v, want the author and date", d)
+	}
+	if len(d.Parents) != 1 || !strings.HasPrefix(first, d.Parents[0]) {
+		t.Errorf("parents %v, want the first save", d.Parents)
+	}
+	if len(d.Summary.Files) != 1 || d.Summary.Files[0].Path != "app.txt" || d.Summary.TotalAdded != 2 {
+		t.Errorf("got %+v, want app.txt with 2 lines added", d.Summary)
+	}
+
+	root, err := git.GetCommitDetails(first)
+	if err != nil || len(root.Parents) != 0 || len(root.Summary.Files) != 1 || root.Summary.Files[0].Path != "README.md" {
+		t.Errorf("got %+v, %v, want the first save with README.md and no parents", root, err)
+	}
+}
+
+func TestCreateBranchAt(t *testing.T) {
+	r := gittest.NewRepo(t)
+	first := r.Head()
+	r.Write("app.txt", "later\n")
+	r.Commit("Later save")
+
+	if err := git.CreateBranchAt("from-first", first); err != nil {
+		t.Fatalf("CreateBranchAt: %v", err)
+	}
+	if r.Branch() != "main" {
+		t.Errorf("on %s, want to stay on main", r.Branch())
+	}
+	if got := r.Git("rev-parse", "from-first"); got != first {
+		t.Errorf("from-first is at %s, want %s", got, first)
+	}
+	if err := git.CreateBranchAt("from-first", first); err == nil {
+		t.Error("want an error making a branch that already exists")
+	}
+	if err := git.CreateBranchAt("bad..name", first); err == nil {
+		t.Error("want an error for an invalid name")
+	}
+}
+
+func TestPushEverything(t *testing.T) {
+	r := gittest.NewRepo(t)
+	remote := t.TempDir()
+	r.Git("init", "--quiet", "--bare", remote)
+	r.Git("remote", "add", "origin", remote)
+	r.Git("branch", "experiment-login")
+	r.Git("branch", "backup/main/20240101-120000")
+	r.Git("tag", "v1.0")
+
+	refs, err := git.ListSyncRefs()
+	if err != nil {
+		t.Fatalf("ListSyncRefs: %v", err)
+	}
+	var names []string
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	if got, want := strings.Join(names, " "), "main backup/main/20240101-120000 experiment-login v1.0"; got != want {
+		t.Fatalf("got refs %q, want %q", got, want)
+	}
+	if !refs[3].Tag || refs[0].Tag {
+		t.Errorf("got %+v, want only v1.0 as a tag", refs)
+	}
+
+	for _, ref := range refs {
+		if err := git.PushRefWithProgress(context.Background(), "origin", ref, nil); err != nil {
+			t.Fatalf("pushing %s: %v", ref.Name, err)
+		}
+	}
+	got := r.Git("--git-dir", remote, "for-each-ref", "--format=%(refname)")
+	for _, want := range []string{"refs/heads/main", "refs/heads/experiment-login", "refs/heads/backup/main/20240101-120000", "refs/tags/v1.0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("origin has %q, want %s", got, want)
+		}
+	}
+}
+
+func TestSyncRemotes(t *testing.T) {
+	r := gittest.NewRepo(t)
+	origin, backup := t.TempDir(), t.TempDir()
+	r.Git("init", "--quiet", "--bare", origin)
+	r.Git("init", "--quiet", "--bare", backup)
+	r.Git("remote", "add", "upstream", origin)
+	if err := git.AddOrigin(origin); err != nil {
+		t.Fatalf("AddOrigin: %v", err)
+	}
+	if err := git.AddSyncRemote("backup", backup); err != nil {
+		t.Fatalf("AddSyncRemote: %v", err)
+	}
+
+	remotes, err := git.ListRemotes()
+	if err != nil || len(remotes) != 3 || remotes[0].Name != "origin" || !remotes[0].Sync {
+		t.Fatalf("got %+v, %v, want origin first and synced", remotes, err)
+	}
+	if synced := git.SyncRemotes(); len(synced) != 1 || synced[0].Name != "backup" {
+		t.Errorf("got %+v, want only backup synced besides origin", synced)
+	}
+
+	if err := git.PushToWithProgress(context.Background(), "backup", nil); err != nil {
+		t.Fatalf("PushToWithProgress: %v", err)
+	}
+	if got := r.Git("--git-dir", backup, "rev-parse", "main"); got != r.Head() {
+		t.Errorf("backup has main at %s, want %s", got, r.Head())
+	}
+	if upstream, _ := git.Run("config", "--get", "branch.main.remote"); upstream == "backup" {
+		t.Error("pushing to backup shouldn't make it main's upstream")
+	}
+
+	if err := git.SetRemoteSync("backup", false); err != nil {
+		t.Fatalf("SetRemoteSync: %v", err)
+	}
+	if synced := git.SyncRemotes(); len(synced) != 0 {
+		t.Errorf("got %+v, want nothing synced besides origin", synced)
+	}
+	if err := git.RemoveRemote("backup"); err != nil {
+		t.Fatalf("RemoveRemote: %v", err)
+	}
+	if remotes, _ := git.ListRemotes(); len(remotes) != 2 {
+		t.Errorf("got %+v, want backup gone", remotes)
+	}
+}
+
+func TestInitRepo(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Git("config", "--global", "init.defaultBranch", "master")
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("notes.txt", []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if name, email := git.Identity(); name != "" || email != "" {
+		t.Fatalf("got identity %q <%q>, want none yet", name, email)
+	}
+
+	err := git.InitRepo(git.NewRepoOptions{FirstSave: true, Gitignore: true, Name: "Ada", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("InitRepo: %v", err)
+	}
+	if branch, _ := git.CurrentBranch(); branch != "main" {
+		t.Errorf("on %q, want main even though init.defaultBranch is master", branch)
+	}
+	if name, email := git.Identity(); name != "Ada" || email != "ada@example.com" {
+		t.Errorf("got identity %q <%q>, want Ada <ada@example.com>", name, email)
+	}
+	files, _ := git.Run("ls-tree", "--name-only", "HEAD")
+	if files != ".gitignore\nnotes.txt" {
+		t.Errorf("first save has %q, want .gitignore and notes.txt", files)
+	}
+	if git.HasChanges() {
+		t.Error("nothing should be left unsaved after the first save")
+	}
+}
+
+func TestInitRepoKeepsGitignore(t *testing.T) {
+	gittest.NewRepo(t)
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".gitignore", []byte("secret.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := git.InitRepo(git.NewRepoOptions{Gitignore: true}); err != nil {
+		t.Fatalf("InitRepo: %v", err)
+	}
+	if data, _ := os.ReadFile(".gitignore"); string(data) != "secret.txt\n" {
+		t.Errorf(".gitignore = %q, want the existing one kept", data)
+	}
+	if _, err := git.Run("rev-parse", "--verify", "HEAD"); err == nil {
+		t.Error("no first save was asked for")
+	}
+}
+
+func TestRenamesInChangeSummary(t *testing.T) {
+	r := gittest.NewRepo(t)
+	lines := strings.Repeat("a line that stays the same\n", 40)
+	r.Write("notes.txt", lines)
+	r.Write("other.txt", "something else entirely\n")
+	r.Commit("Add notes")
+
+	// Moved without git mv, and changed a little on the way
+	if err := os.Rename("notes.txt", "docs.txt"); err != nil {
+		t.Fatal(err)
+	}
+	r.Write("docs.txt", lines+"one more\n")
+	if err := os.Remove("other.txt"); err != nil {
+		t.Fatal(err)
+	}
+	r.Write("fresh.txt", "nothing like the others\n")
+
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		t.Fatalf("GetChangeSummary: %v", err)
+	}
+	got := make(map[string]git.FileChange)
+	for _, c := range changes {
+		got[c.Path] = c
+	}
+	if len(changes) != 3 {
+		t.Fatalf("got %+v, want the rename, other.txt deleted and fresh.txt added", changes)
+	}
+	moved := got["docs.txt"]
+	if moved.Status != "renamed" || moved.OldPath != "notes.txt" || moved.Similarity < 90 || moved.Similarity == 100 {
+		t.Errorf("got %+v, want docs.txt renamed from notes.txt and mostly similar", moved)
+	}
+	if want := fmt.Sprintf("notes.txt → docs.txt (%d%% similar)", moved.Similarity); moved.DisplayPath() != want {
+		t.Errorf("DisplayPath() = %q, want %q", moved.DisplayPath(), want)
+	}
+	if got["other.txt"].Status != "deleted" || got["fresh.txt"].Status != "added" {
+		t.Errorf("got %+v, want unrelated files left alone", changes)
+	}
+	if staged, _ := git.StagedFiles(); len(staged) != 0 {
+		t.Errorf("finding renames staged %v", staged)
+	}
+}
+
+func TestRenamesInDiffStat(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Git("mv", "README.md", "GUIDE.md")
+	r.Write("notes.txt", "hello\n")
+	r.Commit("Move the readme")
+
+	summary, err := git.GetDiffStatBetweenCommits("HEAD^", "HEAD")
+	if err != nil {
+		t.Fatalf("GetDiffStatBetweenCommits: %v", err)
+	}
+	if len(summary.Files) != 2 {
+		t.Fatalf("got %+v, want the rename and notes.txt", summary.Files)
+	}
+	moved, added := summary.Files[0], summary.Files[1]
+	if moved.Path != "GUIDE.md" || moved.OldPath != "README.md" || moved.Similarity != 100 {
+		t.Errorf("got %+v, want README.md renamed to GUIDE.md unchanged", moved)
+	}
+	if moved.DisplayPath() != "README.md → GUIDE.md (100% similar)" {
+		t.Errorf("DisplayPath() = %q", moved.DisplayPath())
+	}
+	if added.Path != "notes.txt" || added.OldPath != "" || added.Additions != 1 {
+		t.Errorf("got %+v, want notes.txt with one line added", added)
+	}
+	if summary.TotalAdded != 1 || summary.TotalDeleted != 0 {
+		t.Errorf("got +%d -%d, want +1 -0", summary.TotalAdded, summary.TotalDeleted)
+	}
+}
+
+func TestPurgeFile(t *testing.T) {
+	r := gittest.NewRepo(t)
+	remote := t.TempDir()
+	r.Git("init", "--quiet", "--bare", remote)
+	r.Git("remote", "add", "origin", remote)
+	r.Write(".env", "SECRET=1\n")
+	r.Commit("Add settings")
+	r.Git("tag", "-a", "v1.0", "-m", "First release")
+	r.Git("push", "--quiet", "origin", "main", "v1.0")
+	r.Git("fetch", "--quiet", "origin")
+
+	r.Write("README.md", "changed\n")
+	if err := git.TakeSnapshot("Before an experiment"); err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+	r.Git("checkout", "--", "README.md")
+
+	result, err := git.PurgeFile(".env")
+	if err != nil {
+		t.Fatalf("PurgeFile: %v", err)
+	}
+	if result.Remaining != 0 || result.Snapshots != 1 || len(result.Backups) != 1 {
+		t.Fatalf("got %+v, want nothing remaining, one snapshot and one backup", result)
+	}
+
+	snapshots, err := git.ListSnapshots()
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("ListSnapshots: %v, %v", snapshots, err)
+	}
+	if files := r.Git("ls-tree", "-r", "--name-only", snapshots[0].Hash); strings.Contains(files, ".env") {
+		t.Errorf("snapshot still has .env: %q", files)
+	}
+
+	refs, err := git.ListSyncRefs()
+	if err != nil {
+		t.Fatalf("ListSyncRefs: %v", err)
+	}
+	for _, ref := range refs {
+		if ref.Name == result.Backups[0] {
+			t.Errorf("syncing everything would upload %s, which still has .env", ref.Name)
+		}
+	}
+
+	if err := git.PushRewritten(result); err != nil {
+		t.Fatalf("PushRewritten: %v", err)
+	}
+	for _, ref := range []string{"main", "v1.0"} {
+		if files := r.Git("--git-dir", remote, "log", "--format=", "--name-only", ref); strings.Contains(files, ".env") {
+			t.Errorf("origin's %s still has .env", ref)
+		}
+	}
+}
+
+func TestDraftPullRequestTitle(t *testing.T) {
+	r := gittest.NewRepo(t)
+	for branch, want := range map[string]string{
+		"experiment-élan-login":                 "Élan login",
+		"experiment-":                           "Experiment-",
+		"experiment-login-page-20240308-174500": "Login page",
+	} {
+		r.Git("checkout", "--quiet", "-b", branch, "main")
+		r.Write("a.txt", branch+" one\n")
+		r.Commit("First")
+		r.Write("a.txt", branch+" two\n")
+		r.Commit("Second")
+
+		draft, err := git.DraftPullRequest()
+		if err != nil {
+			t.Fatalf("DraftPullRequest on %s: %v", branch, err)
+		}
+		if draft.Title != want {
+			t.Errorf("on %s got title %q, want %q", branch, draft.Title, want)
+		}
+	}
+}
+
+func TestDebugLogKeepsSecretsOut(t *testing.T) {
+	r := gittest.NewRepo(t)
+	git.SetDebugLogging(true)
+	t.Cleanup(func() { git.SetDebugLogging(false) })
+
+	token := "ghp_" + strings.Repeat("a1B2", 9)
+	r.Write("config.js", "const token = '"+token+"'\n")
+	r.Git("add", "config.js")
+	if _, err := git.Run("diff", "--cached"); err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	git.DebugLogf("checked %s", token)
+
+	logs, err := git.DebugLogs()
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("DebugLogs: %v, %v", logs, err)
+	}
+	data, err := os.ReadFile(logs[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Errorf("the log has the token:\n%s", data)
+	}
+	if !strings.Contains(string(data), "git diff --cached") {
+		t.Errorf("the log is missing the diff:\n%s", data)
+	}
+	if info, err := os.Stat(logs[0].Path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("the log's mode is %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestCloneDir(t *testing.T) {
+	for url, want := range map[string]string{
+		"owner/repo":                         "repo",
+		"https://github.com/owner/repo.git/": "repo",
+		"git@github.com:owner/repo.git":      "repo",
+		"https://example.com/..":             "",
+		"https://example.com/-oops":          "",
+	} {
+		dir, err := git.CloneDir(url)
+		if want == "" {
+			if err == nil {
+				t.Errorf("CloneDir(%q) = %q, want an error", url, dir)
+			}
+			continue
+		}
+		if err != nil || dir != want {
+			t.Errorf("CloneDir(%q) = %q, %v, want %q", url, dir, err, want)
+		}
+	}
+}
+
+func TestChangeSummaryModeLabels(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write("run me.sh", "echo hi\n")
+	r.Write("edited.sh", "echo one\n")
+	r.Write("target.txt", "hello\n")
+	if err := os.Symlink("target.txt", "link"); err != nil {
+		t.Fatal(err)
+	}
+	r.Git("add", ".")
+	r.Commit("Scripts")
+
+	// Only permissions changed, on a path status -z leaves unquoted
+	if err := os.Chmod("run me.sh", 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Permissions and content both changed, so the diff says it all
+	r.Write("edited.sh", "echo two\n")
+	if err := os.Chmod("edited.sh", 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove("link")
+	if err := os.Symlink("run me.sh", "link"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		t.Fatalf("GetChangeSummary: %v", err)
+	}
+	got := make(map[string]string)
+	for _, c := range changes {
+		got[c.Path] = c.Detail
+	}
+	want := map[string]string{
+		"run me.sh": "permissions changed (now executable)",
+		"edited.sh": "",
+		"link":      "link now points to run me.sh",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGitignoreMatchesIgnoredFiles(t *testing.T) {
+	r := gittest.NewRepo(t)
+	r.Write(".gitignore", "*.log\nbuild/\n")
+	r.Commit("Ignore logs and builds")
+	r.Write("debug.log", "noise\n")
+	r.Write("build/app", "binary\n")
+	r.Write("build/lib/util", "binary\n")
+	r.Write("notes.txt", "kept\n")
+
+	ignored, err := git.IgnoredFiles()
+	if err != nil {
+		t.Fatalf("IgnoredFiles: %v", err)
+	}
+	if want := []string{"build/", "debug.log"}; !reflect.DeepEqual(ignored, want) {
+		t.Errorf("got %q, want %q", ignored, want)
+	}
+
+	matches := git.GitignoreMatches(append(ignored, "notes.txt"))
+	want := map[int][]string{1: {"debug.log"}, 2: {"build/"}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got %q, want %q", matches, want)
+	}
+}
@@ -0,0 +1,148 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// journalPath records the operation in progress, so one cut short by a
+// crash can be finished or undone the next time smooth starts
+var journalPath = filepath.Join(smoothDir, "journal.json")
+
+// Journal is the intent record written before a multi-step operation
+// changes anything: where the branch and staging area were, and the files
+// it could modify, kept as git objects so they survive the crash
+type Journal struct {
+	Operation string          `json:"operation"` // like "save"
+	Started   time.Time       `json:"started"`
+	Head      string          `json:"head"`  // full hash of the save the branch was on
+	Index     string          `json:"index"` // tree of the staging area
+	Files     []JournalFile   `json:"files"`
+	Plan      json.RawMessage `json:"plan,omitempty"` // what the operation needs to run again
+}
+
+// JournalFile is a file as it was before the operation
+type JournalFile struct {
+	Path string      `json:"path"`
+	Blob string      `json:"blob,omitempty"` // empty if the file didn't exist
+	Mode os.FileMode `json:"mode,omitempty"`
+}
+
+// BeginJournal records that operation is about to modify paths, along with
+// plan, before it starts. EndJournal clears it once the operation has
+// finished or been rolled back.
+func BeginJournal(operation string, paths []string, plan any) error {
+	j := Journal{Operation: operation, Started: time.Now()}
+	j.Head, _ = Run("rev-parse", "--verify", "--quiet", "HEAD")
+	// write-tree fails while there are unresolved conflicts; the staging
+	// area then can't be put back, only the files
+	j.Index, _ = CaptureIndex()
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			j.Files = append(j.Files, JournalFile{Path: path})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		blob, err := Run("hash-object", "-w", "--", path)
+		if err != nil {
+			return fmt.Errorf("couldn't keep a copy of %s: %s", path, blob)
+		}
+		j.Files = append(j.Files, JournalFile{Path: path, Blob: blob, Mode: info.Mode().Perm()})
+	}
+
+	if plan != nil {
+		data, err := json.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		j.Plan = data
+	}
+
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash never leaves half a journal
+	tmp := journalPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, journalPath)
+}
+
+// EndJournal clears the journal once the operation is over
+func EndJournal() {
+	os.Remove(journalPath)
+}
+
+// LoadJournal returns the operation that was cut short, if there is one.
+// If the branch moved on since it started, the operation got as far as
+// saving, so it's treated as finished and the journal is cleared.
+func LoadJournal() (Journal, bool) {
+	var j Journal
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		return j, false
+	}
+	if err := json.Unmarshal(data, &j); err != nil || j.Operation == "" {
+		EndJournal()
+		return j, false
+	}
+	if head, _ := Run("rev-parse", "--verify", "--quiet", "HEAD"); head != j.Head {
+		EndJournal()
+		return j, false
+	}
+	return j, true
+}
+
+// Rollback puts the files and staging area back the way they were before
+// the operation started, and clears the journal
+func (j Journal) Rollback() error {
+	var errs []error
+	for _, f := range j.Files {
+		if f.Blob == "" {
+			if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		data, err := runGit([]string{"cat-file", "blob", f.Blob}, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("couldn't read the copy of %s: %w", f.Path, err))
+			continue
+		}
+		if dir := filepath.Dir(f.Path); dir != "." {
+			os.MkdirAll(dir, 0755)
+		}
+		if err := os.WriteFile(f.Path, data, f.Mode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := RestoreIndex(j.Index); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	EndJournal()
+	return nil
+}
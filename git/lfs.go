@@ -0,0 +1,83 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LargeFile is a changed file over the large file threshold
+type LargeFile struct {
+	Path string
+	Size int64
+}
+
+// FindLargeFiles returns the files among paths (or inside them, for
+// untracked folders) that are at least threshold bytes and not already
+// tracked with Git LFS
+func FindLargeFiles(paths []string, threshold int64) []LargeFile {
+	var large []LargeFile
+	check := func(path string, info fs.FileInfo) {
+		if info.Mode().IsRegular() && info.Size() >= threshold && !IsLFSTracked(path) {
+			large = append(large, LargeFile{Path: path, Size: info.Size()})
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			// Deleted files have nothing to upload
+			continue
+		}
+		if !info.IsDir() {
+			check(path, info)
+			continue
+		}
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				check(filepath.ToSlash(p), info)
+			}
+			return nil
+		})
+	}
+	return large
+}
+
+// LFSInstalled checks if the git-lfs extension is available
+func LFSInstalled() bool {
+	_, err := Run("lfs", "version")
+	return err == nil
+}
+
+// IsLFSTracked checks if .gitattributes routes path through Git LFS
+func IsLFSTracked(path string) bool {
+	output, err := Run("check-attr", "filter", "--", path)
+	return err == nil && strings.HasSuffix(output, ": filter: lfs")
+}
+
+// TrackWithLFS tracks the given files with Git LFS and commits the updated
+// .gitattributes, so the files themselves are uploaded to LFS when saved
+func TrackWithLFS(paths []string) error {
+	// Sets up the LFS filters for this repository in case they aren't global
+	if _, err := Run("lfs", "install", "--local"); err != nil {
+		return err
+	}
+	args := append([]string{"lfs", "track", "--filename", "--"}, paths...)
+	if _, err := Run(args...); err != nil {
+		return err
+	}
+	if _, err := Run("add", "--", ".gitattributes"); err != nil {
+		return err
+	}
+
+	// Commit only .gitattributes so the rest of the save stays as reviewed
+	output, err := Run("commit", "-m", "Track large files with Git LFS", "--", ".gitattributes")
+	if err != nil && strings.Contains(output, "nothing to commit") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// BigProjectCommits is the commit count above which smooth suggests optimizing
+const BigProjectCommits = 5000
+
+// OptimizeResult represents the outcome of optimizing a repository
+type OptimizeResult struct {
+	Before    time.Duration // History walk time before optimizing
+	After     time.Duration // History walk time after optimizing
+	Scheduled bool          // Whether background maintenance was scheduled
+	// ScheduleErr explains why background maintenance couldn't be
+	// scheduled (e.g. no cron or launchd available); the one-time
+	// optimization still applies
+	ScheduleErr error
+}
+
+// CommitCount returns the number of commits reachable from any ref
+func CommitCount() int {
+	output, err := Run("rev-list", "--count", "--all")
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(output)
+	return count
+}
+
+// IsOptimized checks if this repository has already been set up for
+// background maintenance
+func IsOptimized() bool {
+	strategy, _ := Run("config", "--get", "maintenance.strategy")
+	return strategy != ""
+}
+
+// NeedsOptimizing checks if the repository is big enough to benefit from
+// maintenance and hasn't been optimized yet
+func NeedsOptimizing() bool {
+	return !IsOptimized() && CommitCount() >= BigProjectCommits
+}
+
+// timeHistoryWalk measures counting every commit, which walks the whole
+// history like Log and Graph do but without reading any files, so it
+// stays quick enough to run before and after optimizing even in huge
+// projects. The commit-graph is what speeds it up.
+func timeHistoryWalk() time.Duration {
+	start := time.Now()
+	Run("rev-list", "--count", "--all")
+	return time.Since(start)
+}
+
+// OptimizeRepo writes a commit-graph with changed-path filters, turns on the
+// settings that keep it up to date, and schedules git's background
+// maintenance (prefetch, commit-graph, loose objects, incremental repack)
+func OptimizeRepo() (OptimizeResult, error) {
+	result := OptimizeResult{Before: timeHistoryWalk()}
+
+	settings := [][]string{
+		{"core.commitGraph", "true"},
+		{"fetch.writeCommitGraph", "true"},
+		{"gc.writeCommitGraph", "true"},
+		{"commitGraph.readChangedPaths", "true"},
+	}
+	for _, s := range settings {
+		if _, err := Run("config", s[0], s[1]); err != nil {
+			return result, fmt.Errorf("failed to set %s: %w", s[0], err)
+		}
+	}
+
+	if output, err := Run("commit-graph", "write", "--reachable", "--changed-paths"); err != nil {
+		return result, fmt.Errorf("failed to write commit-graph: %s", output)
+	}
+
+	// start registers the repo and installs a cron/launchd/schtasks
+	// schedule. Without a scheduler, fall back to register so git still
+	// runs maintenance opportunistically after commits and fetches.
+	if output, err := Run("maintenance", "start"); err != nil {
+		result.ScheduleErr = fmt.Errorf("%s", output)
+		if output, err := Run("maintenance", "register"); err != nil {
+			return result, fmt.Errorf("failed to register for maintenance: %s", output)
+		}
+	} else {
+		result.Scheduled = true
+	}
+
+	result.After = timeHistoryWalk()
+	return result, nil
+}
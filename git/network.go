@@ -0,0 +1,86 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"smooth/platform"
+)
+
+// Talking to GitHub can hang on a bad connection or a server that never
+// answers. Network commands take a context so the user can stop them, and
+// so they give up after the network timeout in Settings.
+
+// TimeoutError is returned when GitHub didn't answer within the network
+// timeout
+type TimeoutError struct {
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("GitHub didn't answer within %s. Check your internet connection, or give it longer with Network timeout in Settings.", durationWords(e.After))
+}
+
+// commandContext builds a git command like command, killed if ctx is done
+// before it finishes
+func commandContext(ctx context.Context, args ...string) *exec.Cmd {
+	if ctx.Done() == nil {
+		// ctx can't be cancelled
+		return command(args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(platform.GitArgs(), args...)...)
+	// ssh can outlive a killed git and hold its output open, so don't wait
+	// long for it
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// RunContext runs a git command that talks to GitHub like Run, stopping it
+// if ctx is cancelled or times out. It then returns context.Canceled or a
+// *TimeoutError.
+func RunContext(ctx context.Context, args ...string) (string, error) {
+	start := time.Now()
+	output, err := runGitContext(ctx, args, func(cmd *exec.Cmd) {
+		// A password prompt would be hidden behind the TUI, so fail instead
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	})
+	recordActivity(args, string(output), err)
+	if err != nil {
+		if ctxErr := contextError(ctx, start); ctxErr != nil {
+			return "", ctxErr
+		}
+	}
+	return strings.TrimSpace(string(output)), err
+}
+
+// contextError returns why ctx stopped a command that started at start, or
+// nil if it didn't
+func contextError(ctx context.Context, start time.Time) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return context.Canceled
+	case context.DeadlineExceeded:
+		after := time.Since(start)
+		if deadline, ok := ctx.Deadline(); ok {
+			after = deadline.Sub(start)
+		}
+		return &TimeoutError{After: after.Round(time.Second)}
+	}
+	return nil
+}
+
+// durationWords says d the way people do, like "2 minutes" or "30 seconds"
+func durationWords(d time.Duration) string {
+	n, unit := int(d.Seconds()), "second"
+	if d >= time.Minute && d%time.Minute == 0 {
+		n, unit = int(d.Minutes()), "minute"
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
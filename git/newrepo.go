@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultBranch is the branch a new project starts on
+const DefaultBranch = "main"
+
+// NewRepoOptions is how a folder is set up when git is initialized in it
+type NewRepoOptions struct {
+	FirstSave bool   // save everything that's already in the folder
+	Gitignore bool   // write a starter .gitignore, unless there is one
+	Name      string // set as user.name in git's global settings, if given
+	Email     string // set as user.email in git's global settings, if given
+}
+
+// StarterGitignore is written into a new project, keeping dependencies,
+// secrets and editor clutter out of its saves
+const StarterGitignore = `# Dependencies and build output
+node_modules/
+dist/
+build/
+__pycache__/
+*.pyc
+.venv/
+venv/
+
+# Secrets
+.env
+.env.local
+
+# Editors and operating systems
+.DS_Store
+Thumbs.db
+.idea/
+.vscode/
+*.swp
+
+# Logs
+*.log
+`
+
+// HasGitignore checks if the folder already has a .gitignore
+func HasGitignore() bool {
+	_, err := os.Stat(".gitignore")
+	return err == nil
+}
+
+// Identity returns the name and email saves are made under, from the
+// environment or git's settings. Either is empty if git doesn't know it,
+// and saving fails until it does.
+func Identity() (name, email string) {
+	name = os.Getenv("GIT_AUTHOR_NAME")
+	if name == "" {
+		name, _ = Run("config", "--get", "user.name")
+	}
+	email = os.Getenv("GIT_AUTHOR_EMAIL")
+	if email == "" {
+		email, _ = Run("config", "--get", "user.email")
+	}
+	return name, email
+}
+
+// InitRepo initializes git in the current folder on the main branch, then
+// sets it up as opts says. The first save is made last, so it includes
+// the starter .gitignore.
+func InitRepo(opts NewRepoOptions) error {
+	if _, err := Run("init", "--quiet"); err != nil {
+		return err
+	}
+	// init.defaultBranch may name something else, and --initial-branch
+	// needs git 2.28, but an unborn HEAD can simply be pointed at main
+	if _, err := Run("symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch); err != nil {
+		return err
+	}
+
+	if name := strings.TrimSpace(opts.Name); name != "" {
+		if _, err := Run("config", "--global", "user.name", name); err != nil {
+			return err
+		}
+	}
+	if email := strings.TrimSpace(opts.Email); email != "" {
+		if _, err := Run("config", "--global", "user.email", email); err != nil {
+			return err
+		}
+	}
+
+	if opts.Gitignore && !HasGitignore() {
+		if err := os.WriteFile(".gitignore", []byte(StarterGitignore), 0644); err != nil {
+			return err
+		}
+	}
+
+	if opts.FirstSave {
+		if err := AddAll(); err != nil {
+			return err
+		}
+		// An empty folder still gets a first save, so there's history to
+		// branch and sync from
+		if output, err := Run("commit", "--allow-empty", "-m", "First save"); err != nil {
+			if isSigningFailure(output) {
+				return &SigningError{Key: DetectSigningKey(), Output: output, Err: err}
+			}
+			return &CommitError{Output: output, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// pendingSyncPath records an auto-sync that failed and should be retried
+var pendingSyncPath = filepath.Join(smoothDir, "pending-sync")
+
+// Retry delays grow from the first to the last after each failed attempt
+const (
+	firstSyncRetry = 30 * time.Second
+	maxSyncRetry   = 10 * time.Minute
+)
+
+// PendingSync is an upload that failed and is waiting to be retried
+type PendingSync struct {
+	Branch      string    `json:"branch"`
+	Since       time.Time `json:"since"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+// QueueSync records that pushing the current branch failed, so it's retried
+// later. Queueing again after another failure pushes the next retry back.
+func QueueSync(pushErr error) error {
+	pending, ok := LoadPendingSync()
+	if !ok {
+		branch, err := CurrentBranch()
+		if err != nil {
+			return err
+		}
+		pending = PendingSync{Branch: branch, Since: time.Now()}
+	}
+	pending.Attempts++
+	pending.LastError = pushErr.Error()
+	pending.NextAttempt = time.Now().Add(syncRetryDelay(pending.Attempts))
+	return savePendingSync(pending)
+}
+
+// syncRetryDelay doubles the wait after each attempt, up to maxSyncRetry
+func syncRetryDelay(attempts int) time.Duration {
+	delay := firstSyncRetry
+	for i := 1; i < attempts && delay < maxSyncRetry; i++ {
+		delay *= 2
+	}
+	return min(delay, maxSyncRetry)
+}
+
+// savePendingSync writes the queued sync to disk
+func savePendingSync(pending PendingSync) error {
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pendingSyncPath, data, 0644)
+}
+
+// LoadPendingSync returns the queued sync, if there is one
+func LoadPendingSync() (PendingSync, bool) {
+	var pending PendingSync
+	data, err := os.ReadFile(pendingSyncPath)
+	if err != nil {
+		return pending, false
+	}
+	if err := json.Unmarshal(data, &pending); err != nil || pending.Branch == "" {
+		return pending, false
+	}
+	return pending, true
+}
+
+// ClearPendingSync removes the queued sync
+func ClearPendingSync() {
+	os.Remove(pendingSyncPath)
+}
+
+// SyncRetryDue checks if there's a queued sync whose retry time has come
+func SyncRetryDue() bool {
+	pending, ok := LoadPendingSync()
+	return ok && !time.Now().Before(pending.NextAttempt)
+}
+
+// RetryPendingSync pushes the queued branch. On success the queue is
+// cleared; on failure the next retry is scheduled.
+func RetryPendingSync() error {
+	pending, ok := LoadPendingSync()
+	if !ok {
+		return nil
+	}
+	if err := pushBranch(pending.Branch); err != nil {
+		QueueSync(err)
+		return err
+	}
+	ClearPendingSync()
+	return nil
+}
+
+// SavesWaiting returns how many saves on the queued branch haven't been
+// uploaded, or 0 if nothing is queued
+func SavesWaiting() int {
+	pending, ok := LoadPendingSync()
+	if !ok {
+		return 0
+	}
+
+	// Without a remote copy of the branch yet, every save is waiting
+	rangeSpec := pending.Branch
+	if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+pending.Branch); err == nil {
+		rangeSpec = "origin/" + pending.Branch + ".." + pending.Branch
+	}
+	output, err := Run("rev-list", "--count", rangeSpec)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(output)
+	return n
+}
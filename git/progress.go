@@ -0,0 +1,111 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Progress is how far a clone or push has got, as git reports it
+type Progress struct {
+	Phase       string // like "Receiving objects"
+	Percent     int
+	Count       int    // objects done so far
+	Total       int    // objects in this phase
+	Transferred string // like "1.2 MiB", when git says
+	Speed       string // like "2.3 MiB/s", when git says
+}
+
+// Details describes the counts and speed, like
+// "450/1000 objects · 1.2 MiB · 2.3 MiB/s"
+func (p Progress) Details() string {
+	var parts []string
+	if p.Total > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d objects", p.Count, p.Total))
+	}
+	if p.Transferred != "" {
+		parts = append(parts, p.Transferred)
+	}
+	if p.Speed != "" {
+		parts = append(parts, p.Speed)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// progressLine matches git's progress output, like
+// "Receiving objects:  45% (450/1000), 1.2 MiB | 2.3 MiB/s"
+var progressLine = regexp.MustCompile(`^(?:remote: )?([A-Za-z ]+):\s+(\d+)%(?:\s+\((\d+)/(\d+)\))?(?:,\s+([\d.]+ (?:bytes|[KMGT]iB))(?:\s+\|\s+([\d.]+ (?:bytes|[KMGT]iB)/s))?)?`)
+
+// parseProgress reads a line of git's progress output
+func parseProgress(line string) (Progress, bool) {
+	m := progressLine.FindStringSubmatch(line)
+	if m == nil {
+		return Progress{}, false
+	}
+	p := Progress{Phase: m[1], Transferred: m[5], Speed: m[6]}
+	p.Percent, _ = strconv.Atoi(m[2])
+	p.Count, _ = strconv.Atoi(m[3])
+	p.Total, _ = strconv.Atoi(m[4])
+	return p, true
+}
+
+// runWithProgress runs a git command that takes --progress, calling
+// progress whenever git reports how far it's got. It returns the rest of
+// what git printed, a line at a time. cmd should be built with ctx, which
+// also stops the reading if it's done first.
+func runWithProgress(ctx context.Context, cmd *exec.Cmd, progress func(Progress)) ([]string, error) {
+	// git prints progress to stderr and some messages to stdout, so both
+	// go down one pipe to keep them in order
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	w.Close()
+	defer r.Close()
+	// Anything git started, like ssh, may still hold the pipe open
+	stop := context.AfterFunc(ctx, func() { r.Close() })
+	defer stop()
+
+	// Progress updates end in \r so they overwrite each other in a terminal
+	var output []string
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if p, ok := parseProgress(line); ok {
+			if progress != nil {
+				progress(p)
+			}
+			continue
+		}
+		output = append(output, line)
+	}
+	return output, cmd.Wait()
+}
+
+// scanProgressLines splits git's output at \n and \r
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
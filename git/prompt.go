@@ -0,0 +1,64 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromptStatus is a quick summary of the project for shell prompts and
+// status bars, which run it every time they redraw
+type PromptStatus struct {
+	Branch      string
+	Unsaved     int  // changed and new files
+	HasUpstream bool // whether the branch has been synced to GitHub
+	Ahead       int  // saves not uploaded yet
+	Behind      int  // saves on GitHub not downloaded yet
+	LastSave    time.Time
+}
+
+// GetPromptStatus reads the prompt summary with as few git commands as
+// possible. Ahead and behind are as of the last fetch; nothing here
+// touches the network.
+func GetPromptStatus() (PromptStatus, error) {
+	var status PromptStatus
+	output, err := Run("status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return status, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// "# branch.ab +1 -2"
+			status.HasUpstream = true
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			status.Unsaved++
+		}
+	}
+
+	status.LastSave = LastSaveTime()
+	return status, nil
+}
+
+// LastSaveTime returns when the newest save on this branch was made, or the
+// zero time if there are none
+func LastSaveTime() time.Time {
+	output, err := Run("log", "-1", "--format=%ct")
+	if err != nil {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
@@ -0,0 +1,224 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// githubAPI is where pull requests are created and checks looked up
+const githubAPI = "https://api.github.com"
+
+// experimentStamp is the time an experiment was started at the end of its
+// branch name, like the -20240308-174500 of experiment-login-20240308-174500
+var experimentStamp = regexp.MustCompile(`-\d{8}-\d{6}$`)
+
+// PullRequestDraft is a pull request ready to be opened, prefilled from an
+// experiment's saves
+type PullRequestDraft struct {
+	Branch string
+	Base   string
+	Title  string
+	Body   string
+}
+
+// GitHubRepo returns the owner and name of the GitHub repository origin
+// points at, from URLs like git@github.com:owner/repo.git or
+// https://github.com/owner/repo
+func GitHubRepo() (owner, repo string, err error) {
+	url := GetRemoteURL()
+	if url == "" {
+		return "", "", NoRemoteError{}
+	}
+	var path string
+	for _, prefix := range []string{"git@github.com:", "ssh://git@github.com/", "https://github.com/", "http://github.com/"} {
+		if strings.HasPrefix(url, prefix) {
+			path = strings.TrimPrefix(url, prefix)
+			break
+		}
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%s isn't a GitHub repository, so pull requests can't be opened from here", url)
+	}
+	return parts[0], parts[1], nil
+}
+
+// DraftPullRequest prefills a pull request for the current experiment:
+// with one save its message is the title, otherwise the title comes from
+// the experiment's name without the time it was started, and the body lists
+// the saves
+func DraftPullRequest() (PullRequestDraft, error) {
+	branch, err := CurrentBranch()
+	if err != nil {
+		return PullRequestDraft{}, err
+	}
+	draft := PullRequestDraft{Branch: branch, Base: GetMainBranch()}
+
+	output, err := Run("log", "--reverse", "--format=%s", draft.Base+".."+branch)
+	if err != nil {
+		return draft, err
+	}
+	var subjects []string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	if len(subjects) == 0 {
+		return draft, fmt.Errorf("%s has no saves that aren't in %s yet", branch, draft.Base)
+	}
+
+	if len(subjects) == 1 {
+		draft.Title = subjects[0]
+	} else {
+		name := experimentStamp.ReplaceAllString(strings.TrimPrefix(branch, "experiment-"), "")
+		name = strings.TrimSpace(strings.ReplaceAll(name, "-", " "))
+		if name == "" {
+			name = branch
+		}
+		first, size := utf8.DecodeRuneInString(name)
+		draft.Title = string(unicode.ToUpper(first)) + name[size:]
+	}
+	var body strings.Builder
+	for _, subject := range subjects {
+		body.WriteString("- " + subject + "\n")
+	}
+	draft.Body = body.String()
+	return draft, nil
+}
+
+// githubToken finds a token for the GitHub API: GITHUB_TOKEN or GH_TOKEN,
+// the token git has stored for github.com, like one set up from Sync, or
+// the GitHub CLI's login if it's installed
+func githubToken() (string, error) {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token, nil
+		}
+	}
+	if token := storedToken(); token != "" {
+		return token, nil
+	}
+	if output, err := exec.Command("gh", "auth", "token").Output(); err == nil {
+		if token := strings.TrimSpace(string(output)); token != "" {
+			return token, nil
+		}
+	}
+	return "", errors.New("no GitHub token found: set GITHUB_TOKEN, set up a token from Sync to GitHub, or log in with the GitHub CLI (gh auth login)")
+}
+
+// storedToken asks git's credential helpers for the password stored for
+// github.com, or returns "" if there isn't one. It's run directly rather
+// than through Run, so the token stays out of the debug log.
+func storedToken() string {
+	cmd := command("credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+	// Only a stored login will do, so git mustn't ask for one
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if password, ok := strings.CutPrefix(line, "password="); ok {
+			return password
+		}
+	}
+	return ""
+}
+
+// CreatePullRequest uploads the draft's branch and opens a pull request for
+// it on GitHub, returning its URL. If one is already open for the branch,
+// that one's URL is returned.
+func CreatePullRequest(draft PullRequestDraft) (string, error) {
+	owner, repo, err := GitHubRepo()
+	if err != nil {
+		return "", err
+	}
+	token, err := githubToken()
+	if err != nil {
+		return "", err
+	}
+	if err := pushBranch(draft.Branch); err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"title": draft.Title,
+		"body":  draft.Body,
+		"head":  draft.Branch,
+		"base":  draft.Base,
+	})
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	status, err := githubRequest("POST", fmt.Sprintf("/repos/%s/%s/pulls", owner, repo), token, payload, &created)
+	if err == nil {
+		return created.HTMLURL, nil
+	}
+	if status != http.StatusUnprocessableEntity {
+		return "", err
+	}
+
+	// GitHub refuses a second pull request for the same branch
+	var existing []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/pulls?head=%s", owner, repo, url.QueryEscape(owner+":"+draft.Branch))
+	if _, listErr := githubRequest("GET", path, token, nil, &existing); listErr == nil && len(existing) > 0 {
+		return existing[0].HTMLURL, nil
+	}
+	return "", err
+}
+
+// githubRequest calls the GitHub API and decodes the JSON response into
+// out, returning the HTTP status. Without a token, only what's public can
+// be read.
+func githubRequest(method, path, token string, body []byte, out interface{}) (int, error) {
+	req, err := http.NewRequest(method, githubAPI+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		msg := apiErr.Message
+		if len(apiErr.Errors) > 0 && apiErr.Errors[0].Message != "" {
+			msg += ": " + apiErr.Errors[0].Message
+		}
+		return resp.StatusCode, fmt.Errorf("GitHub said %s (%s)", resp.Status, msg)
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Removing a file from history means rewriting every save since it was
+// first added, on every branch that has it. git filter-branch does the
+// rewriting: it comes with git, so there's nothing else to install.
+
+// PurgeResult is what removing a file from history did
+type PurgeResult struct {
+	Branches  []string          // branches whose saves were rewritten
+	Tags      []string          // tags moved to the rewritten saves
+	TagsWere  map[string]string // what each of Tags pointed at before, for pushing them safely
+	Snapshots int               // snapshots rewritten without the file
+	Backups   []string          // backups of the branches as they were, which still have the file
+	KeptCopy  bool              // the file is still on disk, now in .gitignore
+	Remaining int               // saves outside backups that still touch the file; 0 if it worked
+}
+
+// SavesWithFile counts the saves on branches and tags that added, changed
+// or deleted path, leaving out backups
+func SavesWithFile(path string) (int, error) {
+	output, err := Run("rev-list", "--count", "--exclude=backup/*", "--branches", "--tags", "--", path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(output)
+}
+
+// refsWithFile returns the branches, tags and snapshot refs with path
+// somewhere in their history, leaving out backups
+func refsWithFile(path string) (branches, tags, snapshots []string, err error) {
+	output, err := Run("for-each-ref", "--format=%(refname)", "refs/heads/", "refs/tags/", snapshotRefPrefix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, ref := range strings.Split(output, "\n") {
+		if ref == "" || strings.HasPrefix(ref, "refs/heads/backup/") {
+			continue
+		}
+		if count, _ := Run("rev-list", "--count", ref, "--", path); count == "0" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branches = append(branches, name)
+		} else if name, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+			tags = append(tags, name)
+		} else {
+			snapshots = append(snapshots, ref)
+		}
+	}
+	return branches, tags, snapshots, nil
+}
+
+// PurgeFile removes path from every save on every branch and tag, and from
+// snapshots, after backing up each branch it rewrites. The copy on disk is kept and added
+// to .gitignore so it isn't saved again.
+func PurgeFile(path string) (PurgeResult, error) {
+	var result PurgeResult
+	if output, _ := Run("status", "--porcelain", "--untracked-files=no"); output != "" {
+		return result, errors.New("save or undo your changes first: history can only be rewritten from a clean slate")
+	}
+	branches, tags, snapshots, err := refsWithFile(path)
+	if err != nil {
+		return result, err
+	}
+	if len(branches) == 0 && len(tags) == 0 {
+		return result, fmt.Errorf("no saves have %s", path)
+	}
+	result.Branches, result.Tags, result.Snapshots = branches, tags, len(snapshots)
+	result.TagsWere = make(map[string]string)
+	for _, tag := range tags {
+		result.TagsWere[tag], _ = Run("rev-parse", "refs/tags/"+tag)
+	}
+
+	for _, branch := range branches {
+		from, _ := Run("rev-parse", "refs/heads/"+branch)
+		backup, err := createBackup(branch, from, BackupMeta{Reason: BackupBeforePurge, Note: "still has " + path, From: from})
+		if err != nil {
+			return result, fmt.Errorf("couldn't back up %s, so nothing was changed: %w", branch, err)
+		}
+		result.Backups = append(result.Backups, backup)
+	}
+
+	// Rewriting switches to the new saves, which don't have the file
+	kept, keepErr := os.ReadFile(path)
+
+	filter := "git rm --cached --ignore-unmatch --quiet -- " + shellQuote(path)
+	args := []string{"filter-branch", "--force", "--index-filter", filter, "--prune-empty", "--tag-name-filter", "cat", "--"}
+	for _, branch := range branches {
+		args = append(args, "refs/heads/"+branch)
+	}
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag)
+	}
+	args = append(args, snapshots...)
+	output, err := runGit(args, func(cmd *exec.Cmd) {
+		cmd.Env = append(os.Environ(), "FILTER_BRANCH_SQUELCH_WARNING=1")
+	})
+	recordActivity(args, string(output), err)
+	if err != nil {
+		return result, fmt.Errorf("rewriting history failed, restore a backup to undo any of it: %s", strings.TrimSpace(string(output)))
+	}
+
+	// filter-branch keeps the old saves under refs/original, with the file
+	if originals, _ := Run("for-each-ref", "--format=%(refname)", "refs/original/"); originals != "" {
+		for _, ref := range strings.Split(originals, "\n") {
+			Run("update-ref", "-d", ref)
+		}
+	}
+
+	if keepErr == nil {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			os.WriteFile(path, kept, 0600)
+		}
+		if err := AddToGitignore(path); err == nil {
+			Run("add", "--", ".gitignore")
+			Run("commit", "-m", "Ignore "+path, "--", ".gitignore")
+		}
+		result.KeptCopy = true
+	}
+
+	result.Remaining, err = SavesWithFile(path)
+	return result, err
+}
+
+// PushRewritten uploads the rewritten branches and tags that are on
+// origin, replacing the saves there that still have the purged file. Like
+// ForcePush, it refuses if origin changed since it was last fetched, and
+// only replaces a tag if origin still has it where it was before.
+func PushRewritten(result PurgeResult) error {
+	if !HasRemote() {
+		return NoRemoteError{}
+	}
+	var leases, refspecs []string
+	for _, branch := range result.Branches {
+		if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err == nil {
+			refspecs = append(refspecs, branch)
+		}
+	}
+	if len(result.Tags) > 0 {
+		remoteTags, err := Run("ls-remote", "--tags", "origin")
+		if err != nil {
+			return err
+		}
+		for _, tag := range result.Tags {
+			ref := "refs/tags/" + tag
+			if result.TagsWere[tag] == "" || !strings.Contains(remoteTags+"\n", "\t"+ref+"\n") {
+				continue
+			}
+			leases = append(leases, "--force-with-lease="+ref+":"+result.TagsWere[tag])
+			refspecs = append(refspecs, ref+":"+ref)
+		}
+	}
+	if len(refspecs) == 0 {
+		return nil
+	}
+	args := append(append([]string{"push", "--force-with-lease"}, leases...), "origin")
+	args = append(args, refspecs...)
+	if output, err := Run(args...); err != nil {
+		return newPushError(output, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// smooth runs git from several places at once: the menu's refresh, auto-sync
+// in the background and whatever the user just asked for. Git lets one
+// process at a time write the index or a branch, and the others fail with
+// "index.lock: File exists". Commands that write are run one at a time
+// here, and retried for a while if another program holds git's lock.
+
+// writeMu lets one writing command run at a time
+var writeMu sync.Mutex
+
+// Lock retries back off from firstLockWait, doubling each time
+const (
+	lockRetries   = 6
+	firstLockWait = 50 * time.Millisecond
+)
+
+var (
+	busyMu sync.Mutex
+	busy   []string // writing commands running now, oldest first
+)
+
+// LockError is returned when git stayed locked by another operation
+// after all the retries
+type LockError struct {
+	Err error
+}
+
+func (e *LockError) Error() string {
+	return "another git operation is still running, try again in a moment " +
+		"(if nothing else is using git, a crashed one may have left .git/index.lock behind; deleting it fixes this)"
+}
+
+func (e *LockError) Unwrap() error {
+	return e.Err
+}
+
+// takesLock checks if a git command writes the index or refs, and so has
+// to wait its turn. Pushing only updates refs on GitHub and can take a
+// while, so it doesn't hold up everything else.
+func takesLock(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "push", "fetch":
+		return false
+	case "add", "apply", "update-index", "update-ref", "restore", "clean", "cherry-pick", "write-tree":
+		return true
+	}
+	return isMutating(args)
+}
+
+// isLockError checks if git failed because something else held its lock
+func isLockError(output string) bool {
+	return strings.Contains(output, ".lock") &&
+		(strings.Contains(output, "File exists") || strings.Contains(output, "Unable to create"))
+}
+
+// runGit runs a git command and returns everything it printed. Writing
+// commands wait for each other, and are retried while another program
+// holds git's lock. setup, if given, adjusts each attempt's command.
+func runGit(args []string, setup func(*exec.Cmd)) ([]byte, error) {
+	return runGitContext(context.Background(), args, setup)
+}
+
+// runGitContext runs a git command like runGit, killing it if ctx is done
+// before it finishes
+func runGitContext(ctx context.Context, args []string, setup func(*exec.Cmd)) (output []byte, err error) {
+	start := time.Now()
+	defer func() { logCommand(args, time.Since(start), output, err) }()
+
+	if takesLock(args) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+	}
+	if isMutating(args) {
+		done := markBusy("git " + args[0])
+		defer done()
+	}
+
+	wait := firstLockWait
+	for attempt := 0; ; attempt++ {
+		output, err = runner.Run(ctx, args, setup)
+		if err == nil || ctx.Err() != nil || !isLockError(string(output)) {
+			return output, err
+		}
+		if attempt == lockRetries {
+			return output, &LockError{Err: err}
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// markBusy records that an operation started, returning a func to call
+// when it's over
+func markBusy(operation string) func() {
+	busyMu.Lock()
+	busy = append(busy, operation)
+	busyMu.Unlock()
+
+	return func() {
+		busyMu.Lock()
+		defer busyMu.Unlock()
+		for i, op := range busy {
+			if op == operation {
+				busy = append(busy[:i], busy[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// BusyWith returns the git operation smooth is running now, like
+// "git push", or "" if it isn't changing anything
+func BusyWith() string {
+	busyMu.Lock()
+	defer busyMu.Unlock()
+	if len(busy) == 0 {
+		return ""
+	}
+	return busy[0]
+}
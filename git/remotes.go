@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// remoteSyncKey is the git config key, under remote.<name>, that turns
+// syncing to a remote other than origin on or off. It lives in the
+// project's git config, so it goes away with the remote.
+const remoteSyncKey = "smoothsync"
+
+// Remote is a place saves can be uploaded to
+type Remote struct {
+	Name string
+	URL  string
+	Sync bool // whether Sync uploads to it; origin is always synced
+}
+
+// ListRemotes returns the project's remotes, origin first. Remotes other
+// than origin are only synced once that's turned on, so a fork's upstream
+// isn't pushed to by surprise.
+func ListRemotes() ([]Remote, error) {
+	output, err := Run("remote")
+	if err != nil {
+		return nil, err
+	}
+	var remotes []Remote
+	for _, name := range strings.Split(output, "\n") {
+		if name == "" {
+			continue
+		}
+		url, _ := Run("remote", "get-url", name)
+		remote := Remote{Name: name, URL: url, Sync: name == "origin"}
+		if name == "origin" {
+			remotes = append([]Remote{remote}, remotes...)
+			continue
+		}
+		on, _ := Run("config", "--type=bool", "--get", "remote."+name+"."+remoteSyncKey)
+		remote.Sync = on == "true"
+		remotes = append(remotes, remote)
+	}
+	return remotes, nil
+}
+
+// SyncRemotes returns the remotes other than origin that Sync uploads to
+func SyncRemotes() []Remote {
+	remotes, _ := ListRemotes()
+	var synced []Remote
+	for _, r := range remotes {
+		if r.Sync && r.Name != "origin" {
+			synced = append(synced, r)
+		}
+	}
+	return synced
+}
+
+// SetRemoteSync turns syncing to a remote other than origin on or off
+func SetRemoteSync(name string, on bool) error {
+	value := "false"
+	if on {
+		value = "true"
+	}
+	_, err := Run("config", "remote."+name+"."+remoteSyncKey, value)
+	return err
+}
+
+// AddSyncRemote adds a remote that Sync uploads to, like a private backup
+func AddSyncRemote(name, url string) error {
+	if err := AddRemote(name, url); err != nil {
+		return err
+	}
+	return SetRemoteSync(name, true)
+}
+
+// RemoveRemote removes a remote. Nothing on it is deleted; smooth just
+// stops knowing about it.
+func RemoveRemote(name string) error {
+	_, err := Run("remote", "remove", name)
+	return err
+}
+
+// PushToWithProgress pushes the current branch to remote, calling progress
+// whenever git reports how far the upload has got. Cancelling ctx stops
+// the push.
+func PushToWithProgress(ctx context.Context, remote string, progress func(Progress)) error {
+	branch, err := CurrentBranch()
+	if err != nil {
+		return err
+	}
+	return pushBranchWithProgress(ctx, remote, branch, progress)
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// RenameThreshold is how similar, in percent, a deleted and an added file
+// must be to count as one file moved. It's git's own default.
+const RenameThreshold = 50
+
+// renamesFlag turns on rename detection at RenameThreshold
+var renamesFlag = fmt.Sprintf("-M%d%%", RenameThreshold)
+
+// rename is where a file moved from, and how much of it is unchanged
+type rename struct {
+	OldPath    string
+	Similarity int
+}
+
+// renameLabel describes a move as "old → new (98% similar)"
+func renameLabel(oldPath, path string, similarity int) string {
+	label := oldPath + " → " + path
+	if similarity > 0 {
+		label += fmt.Sprintf(" (%d%% similar)", similarity)
+	}
+	return label
+}
+
+// parseRenames reads the renames out of "diff --name-status -z" output,
+// keyed by where each file is now
+func parseRenames(output string) map[string]rename {
+	renames := make(map[string]rename)
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		if status[0] != 'R' && status[0] != 'C' {
+			i++ // one path
+			continue
+		}
+		if i+2 >= len(fields) {
+			break
+		}
+		similarity, _ := strconv.Atoi(status[1:])
+		if status[0] == 'R' {
+			renames[fields[i+2]] = rename{OldPath: fields[i+1], Similarity: similarity}
+		}
+		i += 2
+	}
+	return renames
+}
+
+// unsavedRenames finds files that were moved since the last save. Git only
+// pairs a deleted file with a new one once both are staged, so the new
+// files are marked as intended in a copy of the staging area, leaving the
+// real one alone.
+func unsavedRenames(added []string) (map[string]rename, error) {
+	indexPath, err := Run("rev-parse", "--git-path", "index")
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp("", "smooth-renames-*")
+	if err != nil {
+		return nil, err
+	}
+	tmpIndex := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpIndex)
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if err := os.WriteFile(tmpIndex, data, 0644); err != nil {
+			return nil, err
+		}
+	} else {
+		os.Remove(tmpIndex)
+	}
+
+	if len(added) > 0 {
+		args := append([]string{"add", "--intent-to-add", "--"}, added...)
+		if _, err := runWithIndex(tmpIndex, args...); err != nil {
+			return nil, err
+		}
+	}
+	output, err := runWithIndex(tmpIndex, "diff", "HEAD", "--name-status", "-z", renamesFlag)
+	if err != nil {
+		return nil, err
+	}
+	return parseRenames(output), nil
+}
+
+// pairRenames turns a deleted file and a new one that are mostly the same
+// into a single rename, and fills in how similar staged renames are
+func pairRenames(changes []FileChange) []FileChange {
+	var added []string
+	deleted, renamed := false, false
+	for _, c := range changes {
+		switch c.Status {
+		case "added":
+			added = append(added, c.Path)
+		case "deleted":
+			deleted = true
+		case "renamed":
+			renamed = true
+		}
+	}
+	if !renamed && (!deleted || len(added) == 0) {
+		return changes
+	}
+
+	renames, err := unsavedRenames(added)
+	if err != nil || len(renames) == 0 {
+		return changes
+	}
+
+	moved := make(map[string]bool)
+	for i, c := range changes {
+		r, ok := renames[c.Path]
+		if !ok || (c.Status != "added" && c.Status != "renamed") {
+			continue
+		}
+		if c.Status == "renamed" && c.OldPath != r.OldPath {
+			continue
+		}
+		changes[i].Status = "renamed"
+		changes[i].OldPath = r.OldPath
+		changes[i].Similarity = r.Similarity
+		moved[r.OldPath] = true
+	}
+
+	paired := changes[:0]
+	for _, c := range changes {
+		if c.Status == "deleted" && moved[c.Path] {
+			continue
+		}
+		paired = append(paired, c)
+	}
+	return paired
+}
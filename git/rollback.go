@@ -0,0 +1,89 @@
+package git
+
+import (
+	"errors"
+	"os"
+)
+
+// FileState captures the on-disk contents of a set of files so they can be
+// put back if a multi-step operation fails partway through
+type FileState struct {
+	files map[string]fileContents
+}
+
+// fileContents is the saved state of a single file
+type fileContents struct {
+	data   []byte
+	mode   os.FileMode
+	exists bool
+}
+
+// CaptureFileState records the current contents of the given paths.
+// Paths that don't exist are remembered as missing so Restore can remove them.
+func CaptureFileState(paths []string) (FileState, error) {
+	state := FileState{files: make(map[string]fileContents)}
+	for _, path := range paths {
+		if _, ok := state.files[path]; ok {
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				state.files[path] = fileContents{exists: false}
+				continue
+			}
+			return state, err
+		}
+
+		// Directories can't be snapshotted byte-for-byte, skip them
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return state, err
+		}
+		state.files[path] = fileContents{
+			data:   data,
+			mode:   info.Mode().Perm(),
+			exists: true,
+		}
+	}
+	return state, nil
+}
+
+// Restore writes every captured file back to disk, removing files that
+// didn't exist when the state was captured
+func (s FileState) Restore() error {
+	var errs []error
+	for path, f := range s.files {
+		if !f.exists {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, f.data, f.mode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CaptureIndex records the current staging area as a tree object.
+// Returns the tree hash, which can be passed to RestoreIndex.
+func CaptureIndex() (string, error) {
+	return Run("write-tree")
+}
+
+// RestoreIndex resets the staging area to a tree captured by CaptureIndex
+// without touching files in the working tree
+func RestoreIndex(tree string) error {
+	if tree == "" {
+		return nil
+	}
+	_, err := Run("read-tree", tree)
+	return err
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+)
+
+// GitRunner runs one git command and returns everything it printed. Every
+// command smooth runs through Run, RunRaw and the rest of this package goes
+// through it, except clones, which stream their progress. setup, if given,
+// adjusts the command before it starts, like feeding it input.
+type GitRunner interface {
+	Run(ctx context.Context, args []string, setup func(*exec.Cmd)) ([]byte, error)
+}
+
+// execRunner runs the git binary
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, args []string, setup func(*exec.Cmd)) ([]byte, error) {
+	cmd := commandContext(ctx, args...)
+	if setup != nil {
+		setup(cmd)
+	}
+	return cmd.CombinedOutput()
+}
+
+// runner runs git commands; tests swap in a fake with SetRunner
+var runner GitRunner = execRunner{}
+
+// SetRunner makes git commands go through r, returning a func that puts
+// back the runner that was there before. It's meant for tests, which
+// should set it before anything runs git in the background.
+func SetRunner(r GitRunner) (restore func()) {
+	previous := runner
+	runner = r
+	return func() { runner = previous }
+}
@@ -0,0 +1,95 @@
+package git_test
+
+import (
+	"errors"
+	"testing"
+
+	"smooth/git"
+	"smooth/git/gittest"
+)
+
+func TestCurrentBranch(t *testing.T) {
+	f := gittest.NewFake(t)
+	f.On("symbolic-ref", "--short", "HEAD").Returns("feature\n")
+
+	got, err := git.CurrentBranch()
+	if err != nil || got != "feature" {
+		t.Errorf("got %q, %v, want feature", got, err)
+	}
+}
+
+func TestCurrentBranchDetached(t *testing.T) {
+	f := gittest.NewFake(t)
+	f.On("symbolic-ref").Fails("fatal: ref HEAD is not a symbolic ref")
+	f.On("rev-parse", "--abbrev-ref", "HEAD").Returns("HEAD")
+
+	got, err := git.CurrentBranch()
+	if err != nil || got != "HEAD" {
+		t.Errorf("got %q, %v, want HEAD", got, err)
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		fails  bool
+		want   bool
+	}{
+		{"clean", "", false, false},
+		{"changed", " M main.go\n", false, true},
+		{"not a repo", "fatal: not a git repository", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := gittest.NewFake(t)
+			rule := f.On("status", "--porcelain")
+			if tt.fails {
+				rule.Fails(tt.output)
+			} else {
+				rule.Returns(tt.output)
+			}
+			if got := git.HasChanges(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitErrors(t *testing.T) {
+	f := gittest.NewFake(t)
+	f.On("commit").Fails("error: gpg failed to sign the data").Once()
+	f.On("commit").Fails("nothing to commit, working tree clean").Once()
+	f.On() // looking up the signing key
+
+	var signing *git.SigningError
+	if err := git.Commit("first"); !errors.As(err, &signing) {
+		t.Errorf("got %v, want a *SigningError", err)
+	}
+	var commit *git.CommitError
+	if err := git.Commit("second"); !errors.As(err, &commit) || commit.Output != "nothing to commit, working tree clean" {
+		t.Errorf("got %v, want a *CommitError with git's output", err)
+	}
+}
+
+func TestRunRetriesWhileLocked(t *testing.T) {
+	f := gittest.NewFake(t)
+	locked := "fatal: Unable to create '.git/index.lock': File exists."
+	f.On("add").Fails(locked).Once()
+	f.On("add").Fails(locked).Once()
+	f.On("add").Returns("")
+	f.On("rev-parse", "--git-path").Returns(".git/info/exclude") // the activity log
+
+	if err := git.AddAll(); err != nil {
+		t.Fatalf("AddAll: %v", err)
+	}
+	adds := 0
+	for _, call := range f.Calls() {
+		if call[0] == "add" {
+			adds++
+		}
+	}
+	if adds != 3 {
+		t.Errorf("ran git add %d times, want 3", adds)
+	}
+}
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SigningKey describes the key git signs commits with, as set up in the
+// user's git config
+type SigningKey struct {
+	Format     string // "openpgp", "ssh" or "x509"
+	Key        string // user.signingkey, or the email gpg finds a key for
+	AlwaysSign bool   // commit.gpgsign is on, so git signs every commit anyway
+}
+
+// Found checks if there's a key to sign with
+func (k SigningKey) Found() bool {
+	return k.Key != ""
+}
+
+// String describes the key, like "SSH key id_ed25519.pub" or
+// "GPG key 3AA5C34371567BD2"
+func (k SigningKey) String() string {
+	if !k.Found() {
+		return "no signing key"
+	}
+	switch k.Format {
+	case "ssh":
+		if strings.HasPrefix(k.Key, "ssh-") || strings.HasPrefix(k.Key, "key::") {
+			return "SSH key"
+		}
+		return "SSH key " + filepath.Base(k.Key)
+	case "x509":
+		return "X.509 certificate " + k.Key
+	}
+	return "GPG key " + k.Key
+}
+
+// DetectSigningKey finds the key git would sign commits with. Without
+// user.signingkey, GPG signs with a key for the user's email if it has one.
+func DetectSigningKey() SigningKey {
+	key := SigningKey{Format: "openpgp"}
+	if format, _ := Run("config", "--get", "gpg.format"); format != "" {
+		key.Format = format
+	}
+	key.Key, _ = Run("config", "--get", "user.signingkey")
+	gpgsign, _ := Run("config", "--type=bool", "--get", "commit.gpgsign")
+	key.AlwaysSign = gpgsign == "true"
+
+	if key.Key == "" && key.Format == "openpgp" {
+		email, _ := Run("config", "--get", "user.email")
+		if email != "" && hasGPGKey(email) {
+			key.Key = email
+		}
+	}
+	return key
+}
+
+// hasGPGKey checks if GPG has a secret key for id, a key ID or email
+func hasGPGKey(id string) bool {
+	return exec.Command(gpgProgram(), "--list-secret-keys", id).Run() == nil
+}
+
+// gpgProgram returns the program git signs OpenPGP commits with
+func gpgProgram() string {
+	if program, _ := Run("config", "--get", "gpg.program"); program != "" {
+		return program
+	}
+	return "gpg"
+}
+
+// CommitSigned creates a commit signed with the user's GPG or SSH key.
+// skipHooks leaves out the project's hooks, as CommitSkippingHooks does.
+// If signing is what failed, the error is a *SigningError saying how to
+// fix it.
+func CommitSigned(message string, skipHooks bool) error {
+	args := []string{"commit", "-S"}
+	if skipHooks {
+		args = append(args, "--no-verify")
+	}
+	output, err := Run(append(args, "-m", message)...)
+	if err == nil {
+		return nil
+	}
+	if isSigningFailure(output) {
+		return &SigningError{Key: DetectSigningKey(), Output: output, Err: err}
+	}
+	return &CommitError{Output: output, Err: err}
+}
+
+// isSigningFailure checks if git's output says it couldn't sign a commit
+func isSigningFailure(output string) bool {
+	for _, s := range []string{
+		"failed to sign the data",
+		"gpg failed",
+		"cannot run gpg",
+		"cannot run ssh-keygen",
+		"user.signingkey or gpg.ssh.defaultKeyCommand",
+		"Couldn't load public key",
+		"No secret key",
+		"no default secret key",
+	} {
+		if strings.Contains(output, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningError is returned when git couldn't sign a save
+type SigningError struct {
+	Key    SigningKey
+	Output string // what git and the signing program printed
+	Err    error
+}
+
+func (e *SigningError) Error() string {
+	return "couldn't sign the save. " + e.Guidance()
+}
+
+func (e *SigningError) Unwrap() error {
+	return e.Err
+}
+
+// Guidance says what's likely wrong with signing and how to fix it
+func (e *SigningError) Guidance() string {
+	switch {
+	case strings.Contains(e.Output, "cannot run"):
+		program := "GnuPG"
+		if e.Key.Format == "ssh" {
+			program = "OpenSSH (ssh-keygen)"
+		}
+		return fmt.Sprintf("%s isn't installed or git can't find it. Install it, or point git at it with git config --global gpg.program.", program)
+	case !e.Key.Found():
+		if e.Key.Format == "ssh" {
+			return "No SSH key is set for signing. Run git config --global user.signingkey ~/.ssh/id_ed25519.pub, or turn off Sign saves in Settings."
+		}
+		return "No GPG key was found for your email. Run git config --global user.signingkey <key id>, or turn off Sign saves in Settings."
+	case strings.Contains(e.Output, "Couldn't load public key"), e.Key.Format == "openpgp" && !hasGPGKey(e.Key.Key):
+		return fmt.Sprintf("git can't use the %s. Check user.signingkey in your git config points at a key you have.", e.Key)
+	case e.Key.Format == "openpgp":
+		return "GPG couldn't ask for your passphrase. Run export GPG_TTY=$(tty) in your shell, or unlock the key first with echo test | gpg --clearsign, then save again."
+	}
+	return fmt.Sprintf("git couldn't sign with your %s: %s", e.Key, firstLine(e.Output))
+}
+
+// firstLine returns the first non-empty line of s
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// skipListPath records files the user chose to leave out of saves for now
+var skipListPath = filepath.Join(smoothDir, "skip.json")
+
+// SkipTTL is how long a file stays skipped before it's offered for saving
+// again, so a forgotten skip doesn't hide work forever
+const SkipTTL = 7 * 24 * time.Hour
+
+// SkippedFiles returns the files being skipped and when each skip runs
+// out. Expired skips are left out.
+func SkippedFiles() map[string]time.Time {
+	skipped := make(map[string]time.Time)
+	data, err := os.ReadFile(skipListPath)
+	if err != nil {
+		return skipped
+	}
+	if err := json.Unmarshal(data, &skipped); err != nil {
+		return make(map[string]time.Time)
+	}
+	now := time.Now()
+	for path, until := range skipped {
+		if !now.Before(until) {
+			delete(skipped, path)
+		}
+	}
+	return skipped
+}
+
+// SkipFiles keeps paths out of saves until they're unskipped or SkipTTL
+// passes. Files already skipped keep their original expiry.
+func SkipFiles(paths []string) error {
+	skipped := SkippedFiles()
+	changed := false
+	for _, path := range paths {
+		if _, ok := skipped[path]; !ok {
+			skipped[path] = time.Now().Add(SkipTTL)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveSkipList(skipped)
+}
+
+// UnskipFiles lets paths be saved again
+func UnskipFiles(paths []string) error {
+	skipped := SkippedFiles()
+	changed := false
+	for _, path := range paths {
+		if _, ok := skipped[path]; ok {
+			delete(skipped, path)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveSkipList(skipped)
+}
+
+// saveSkipList writes the skip list to disk, removing it once it's empty
+func saveSkipList(skipped map[string]time.Time) error {
+	if len(skipped) == 0 {
+		err := os.Remove(skipListPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(skipped, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(skipListPath, data, 0644)
+}
@@ -0,0 +1,190 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotRefPrefix is where snapshots are kept. They're ordinary commits
+// under a hidden ref, so they never show up as branches or in the history.
+const snapshotRefPrefix = "refs/smooth/snapshots/"
+
+// MaxSnapshots is how many snapshots are kept before the oldest are dropped
+const MaxSnapshots = 50
+
+// Snapshot is a copy of the working tree, including unsaved and untracked
+// files, taken without making a save
+type Snapshot struct {
+	Ref    string
+	Hash   string
+	Reason string
+	Time   time.Time
+}
+
+// TakeSnapshot copies the working tree into a new snapshot. It does nothing
+// when there are no unsaved changes. The staging area and the working tree
+// are left untouched.
+func TakeSnapshot(reason string) error {
+	if !HasChanges() {
+		return nil
+	}
+
+	commit, err := snapshotCommit(reason)
+	if err != nil {
+		return err
+	}
+
+	ref := snapshotRefPrefix + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := Run("update-ref", ref, commit); err != nil {
+		return err
+	}
+	return TrimSnapshots(MaxSnapshots)
+}
+
+// snapshotCommit commits the working tree, including unsaved and untracked
+// files, on top of HEAD without touching the branch, the staging area or
+// the files, and returns the commit's hash
+func snapshotCommit(message string) (string, error) {
+	// Stage everything into a copy of the index, so the real one is untouched
+	// and unchanged files don't need hashing again
+	indexPath, err := Run("rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(indexPath), "smooth-snapshot-index-")
+	if err != nil {
+		return "", err
+	}
+	tmpIndex := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpIndex)
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if err := os.WriteFile(tmpIndex, data, 0644); err != nil {
+			return "", err
+		}
+	} else {
+		os.Remove(tmpIndex)
+	}
+
+	if _, err := runWithIndex(tmpIndex, "add", "-A", "--", ":/"); err != nil {
+		return "", fmt.Errorf("failed to snapshot files: %w", err)
+	}
+	tree, err := runWithIndex(tmpIndex, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot files: %w", err)
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if head, err := Run("rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		args = append(args, "-p", head)
+	}
+	commit, err := Run(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot files: %w", err)
+	}
+	return commit, nil
+}
+
+// runWithIndex runs a git command against another index file
+func runWithIndex(indexPath string, args ...string) (string, error) {
+	output, err := runGit(args, func(cmd *exec.Cmd) {
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+indexPath)
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// snapshotBefore takes a snapshot ahead of an operation that could lose
+// unsaved work. It's best-effort: a failed snapshot doesn't stop the
+// operation.
+func snapshotBefore(operation string) {
+	TakeSnapshot("Before " + operation)
+}
+
+// ListSnapshots returns all snapshots, newest first
+func ListSnapshots() ([]Snapshot, error) {
+	output, err := Run("for-each-ref", "--sort=-refname",
+		"--format=%(refname)|%(objectname)|%(creatordate:unix)|%(subject)", snapshotRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+	if output == "" {
+		return snapshots, nil
+	}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[2], 10, 64)
+		snapshots = append(snapshots, Snapshot{
+			Ref:    parts[0],
+			Hash:   parts[1],
+			Time:   time.Unix(secs, 0),
+			Reason: parts[3],
+		})
+	}
+	return snapshots, nil
+}
+
+// SnapshotFiles returns the files that had unsaved changes when the
+// snapshot was taken
+func SnapshotFiles(s Snapshot) ([]string, error) {
+	// Snapshots of a project without saves have no parent to compare with
+	base := s.Hash + "^"
+	if _, err := Run("rev-parse", "--verify", "--quiet", base); err != nil {
+		base = emptyTree
+	}
+	output, err := Run("diff", "--name-only", base, s.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return []string{}, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// emptyTree is git's well-known hash for a tree with no files
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// RestoreSnapshot puts the files in the working tree back the way they were
+// in the snapshot. The current files are snapshotted first, so restoring
+// can be undone. Files created since the snapshot that it doesn't know
+// about are left alone, and saves are not affected.
+func RestoreSnapshot(s Snapshot) error {
+	if err := TakeSnapshot("Before restoring a snapshot"); err != nil {
+		return fmt.Errorf("failed to snapshot current files first: %w", err)
+	}
+	_, err := Run("restore", "--source="+s.Hash, "--worktree", "--overlay", "--", ":/")
+	return err
+}
+
+// DeleteSnapshot removes a snapshot
+func DeleteSnapshot(s Snapshot) error {
+	_, err := Run("update-ref", "-d", s.Ref)
+	return err
+}
+
+// TrimSnapshots deletes the oldest snapshots beyond max
+func TrimSnapshots(max int) error {
+	snapshots, err := ListSnapshots()
+	if err != nil {
+		return err
+	}
+	for i := max; i < len(snapshots); i++ {
+		if err := DeleteSnapshot(snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Stashes smooth makes are labeled so they can be told apart from any the
+// user made themselves, and so a stash left behind explains where it came
+// from
+const stashLabelPrefix = "smooth: "
+
+// StashEntry is a set of unsaved changes put aside in git's stash
+type StashEntry struct {
+	Ref     string // like "stash@{0}"; changes as stashes are added and dropped
+	Hash    string
+	Message string
+	Age     string // like "2 hours ago"
+}
+
+// Smooth reports if smooth stashed these changes itself
+func (s StashEntry) Smooth() bool {
+	return strings.Contains(s.Message, stashLabelPrefix)
+}
+
+// StashConflictError is returned when putting stashed changes back clashed
+// with the files as they are now. The changes stay in the stash, and any
+// files changed on both sides have conflict markers in them.
+type StashConflictError struct {
+	Stash  StashEntry
+	Files  []string // files changed on both sides
+	Output string   // what git said, when it wasn't a conflict
+}
+
+func (e *StashConflictError) Error() string {
+	if len(e.Files) == 0 {
+		return fmt.Sprintf("couldn't put your unsaved changes back, they're kept in the stash: %s", e.Output)
+	}
+	return fmt.Sprintf("%d file(s) clashed when putting your unsaved changes back", len(e.Files))
+}
+
+// WithAutoStash runs op with unsaved changes, new files included, stashed
+// under label, and puts them back afterwards, even if op fails. If they
+// can't go back cleanly they stay in the stash and a *StashConflictError is
+// returned, for the Stashed changes screen to sort out.
+func WithAutoStash(label string, op func() error) error {
+	if !HasChanges() {
+		return op()
+	}
+	snapshotBefore("stashing changes")
+	if output, err := Run("stash", "push", "--include-untracked", "-m", stashLabelPrefix+label); err != nil {
+		return fmt.Errorf("couldn't put your unsaved changes aside: %s", output)
+	}
+	hash, err := Run("rev-parse", "stash@{0}")
+	if err != nil {
+		return err
+	}
+
+	opErr := op()
+	return errors.Join(opErr, ApplyStash(hash))
+}
+
+// ListStashes returns the stashed changes, newest first
+func ListStashes() ([]StashEntry, error) {
+	output, err := Run("stash", "list", "--format=%gd|%H|%cr|%gs")
+	if err != nil || output == "" {
+		return nil, err
+	}
+	var stashes []StashEntry
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) == 4 {
+			stashes = append(stashes, StashEntry{Ref: parts[0], Hash: parts[1], Age: parts[2], Message: parts[3]})
+		}
+	}
+	return stashes, nil
+}
+
+// StashCount returns how many sets of changes are stashed
+func StashCount() int {
+	stashes, _ := ListStashes()
+	return len(stashes)
+}
+
+// findStash returns the stash with the given hash
+func findStash(hash string) (StashEntry, bool) {
+	stashes, _ := ListStashes()
+	for _, s := range stashes {
+		if s.Hash == hash {
+			return s, true
+		}
+	}
+	return StashEntry{}, false
+}
+
+// ApplyStash puts stashed changes back and drops them from the stash. If
+// they clash with the files now, they stay in the stash and a
+// *StashConflictError says which files need a decision.
+func ApplyStash(hash string) error {
+	stash, ok := findStash(hash)
+	if !ok {
+		return fmt.Errorf("those stashed changes are gone")
+	}
+	if output, err := Run("stash", "apply", hash); err != nil {
+		files, _ := ConflictedFiles()
+		return &StashConflictError{Stash: stash, Files: files, Output: output}
+	}
+	return DropStash(hash)
+}
+
+// DropStash deletes stashed changes
+func DropStash(hash string) error {
+	stash, ok := findStash(hash)
+	if !ok {
+		return nil
+	}
+	if output, err := Run("stash", "drop", "--quiet", stash.Ref); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// ResolveStashConflict settles a file that clashed when putting stashed
+// changes back by keeping one side whole: the stashed changes, or the file
+// as it was. The file is left unsaved either way.
+func ResolveStashConflict(path string, keepStashed bool) error {
+	// Applying a stash merges it into the files now, so the stash is
+	// "theirs"
+	side := "--ours"
+	if keepStashed {
+		side = "--theirs"
+	}
+	if _, err := Run("checkout", side, "--", path); err != nil {
+		return err
+	}
+	return markStashResolved(path)
+}
+
+// MarkStashResolved records that a clashing file was fixed by hand. It
+// refuses while the file still has conflict markers in it.
+func MarkStashResolved(path string) error {
+	if err := MarkResolved(path); err != nil {
+		return err
+	}
+	_, err := Run("reset", "--quiet", "--", path)
+	return err
+}
+
+// markStashResolved clears a file's conflict without staging it, so it's
+// an unsaved change like the rest
+func markStashResolved(path string) error {
+	if _, err := Run("add", "--", path); err != nil {
+		return err
+	}
+	_, err := Run("reset", "--quiet", "--", path)
+	return err
+}
+
+// FinishStashConflict drops the stash once every clashing file is
+// resolved, since its changes are back in the files. Applying staged the
+// changes that went back cleanly, so they're unstaged again too.
+func FinishStashConflict(hash string) error {
+	if files, _ := ConflictedFiles(); len(files) > 0 {
+		return fmt.Errorf("%d file(s) still need a decision", len(files))
+	}
+	if output, err := Run("reset", "--quiet"); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return DropStash(hash)
+}
+
+// UndoStashApply takes back stashed changes that clashed, leaving the files
+// as they were and the changes in the stash for later
+func UndoStashApply() error {
+	if output, err := Run("reset", "--merge"); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RepoSize is how much space the project's history takes in .git
+type RepoSize struct {
+	Packed  int64 // compressed history in pack files
+	Loose   int64 // objects git hasn't packed yet
+	Garbage int64 // leftovers git can delete
+}
+
+// Total returns the space .git's objects take altogether
+func (s RepoSize) Total() int64 {
+	return s.Packed + s.Loose + s.Garbage
+}
+
+// GetRepoSize measures the history with git count-objects
+func GetRepoSize() (RepoSize, error) {
+	output, err := Run("count-objects", "-v")
+	if err != nil {
+		return RepoSize{}, err
+	}
+	var size RepoSize
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		// count-objects reports sizes in KiB
+		kib, _ := strconv.ParseInt(value, 10, 64)
+		switch name {
+		case "size":
+			size.Loose = kib << 10
+		case "size-pack":
+			size.Packed = kib << 10
+		case "size-garbage":
+			size.Garbage = kib << 10
+		}
+	}
+	return size, nil
+}
+
+// TrackedFile is a file in the latest save, with its size
+type TrackedFile struct {
+	Path string
+	Size int64
+}
+
+// LargestTrackedFiles returns the n biggest files in the latest save,
+// biggest first
+func LargestTrackedFiles(n int) ([]TrackedFile, error) {
+	output, err := RunRaw("ls-tree", "-r", "-l", "-z", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var files []TrackedFile
+	for _, entry := range strings.Split(output, "\x00") {
+		// "<mode> blob <hash> <size>\t<path>"
+		info, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		files = append(files, TrackedFile{Path: path, Size: size})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files, nil
+}
+
+// HistoryObject is a version of a file kept somewhere in history
+type HistoryObject struct {
+	Hash    string
+	Path    string // where it was first seen
+	Size    int64  // uncompressed
+	OnDisk  int64  // compressed, as stored in .git
+	Current bool   // the latest save still has this version
+}
+
+// LargestObjects returns the n biggest file versions in the history of
+// every branch, biggest on disk first. Old versions of files that were
+// changed or deleted still take space until they're purged.
+func LargestObjects(n int) ([]HistoryObject, error) {
+	output, err := Run("rev-list", "--objects", "--all")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	paths := make(map[string]string)
+	var hashes strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		hash, path, ok := strings.Cut(line, " ")
+		if !ok || path == "" {
+			// Commits, and the project's top folder
+			continue
+		}
+		if _, seen := paths[hash]; !seen {
+			paths[hash] = path
+			hashes.WriteString(hash + "\n")
+		}
+	}
+
+	sizes, err := runGit([]string{"cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize) %(objectsize:disk)"}, func(cmd *exec.Cmd) {
+		cmd.Stdin = strings.NewReader(hashes.String())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't measure history: %s", strings.TrimSpace(string(sizes)))
+	}
+	var objects []HistoryObject
+	for _, line := range strings.Split(strings.TrimSpace(string(sizes)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		onDisk, _ := strconv.ParseInt(fields[3], 10, 64)
+		objects = append(objects, HistoryObject{Hash: fields[0], Path: paths[fields[0]], Size: size, OnDisk: onDisk})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].OnDisk > objects[j].OnDisk })
+	if len(objects) > n {
+		objects = objects[:n]
+	}
+
+	current := make(map[string]bool)
+	if output, err := Run("ls-tree", "-r", "HEAD"); err == nil {
+		// "<mode> blob <hash>\t<path>"
+		for _, line := range strings.Split(output, "\n") {
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				current[fields[2]] = true
+			}
+		}
+	}
+	for i := range objects {
+		objects[i].Current = current[objects[i].Hash]
+	}
+	return objects, nil
+}
+
+// StopTracking takes path out of the project but leaves it on disk, adds it
+// to .gitignore so it isn't saved again, and saves that. Earlier saves
+// still have it.
+func StopTracking(path string) error {
+	// Committing the path by name would save the copy on disk again, so the
+	// whole staging area is committed, which mustn't sweep anything else in
+	if _, err := Run("diff", "--cached", "--quiet"); err != nil {
+		return fmt.Errorf("some other changes are staged for the next save. Save them first")
+	}
+	if output, err := Run("rm", "--cached", "--quiet", "--", path); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	if err := AddToGitignore(path); err != nil {
+		return err
+	}
+	if _, err := Run("add", "--", ".gitignore"); err != nil {
+		return err
+	}
+	if output, err := Run("commit", "-m", "Stop tracking "+path); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// RemoveFile deletes path from the project and the disk, and saves that.
+// Earlier saves still have it.
+func RemoveFile(path string) error {
+	if output, err := Run("rm", "--quiet", "--", path); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	if output, err := Run("commit", "-m", "Remove "+path, "--", path); err != nil {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// BackupStorage returns how many backup branches there are, across every
+// branch, and the space only they keep
+func BackupStorage() (int, int64, error) {
+	output, err := Run("for-each-ref", "--format=%(refname:short)", "refs/heads/backup/")
+	if err != nil || output == "" {
+		return 0, 0, err
+	}
+	var backups []BackupInfo
+	for _, name := range strings.Split(output, "\n") {
+		backups = append(backups, BackupInfo{Name: name})
+	}
+	size, err := BackupsSize(backups)
+	return len(backups), size, err
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// SyncRef is a branch or tag that syncing everything uploads
+type SyncRef struct {
+	Name string // like "main", "experiment-login" or "v1.0"
+	Tag  bool
+}
+
+// ListSyncRefs returns what syncing everything uploads: the main branch,
+// then every other branch, experiments and backups included, then tags.
+// Backups made before removing a file from history are left out, since
+// they still have the file.
+func ListSyncRefs() ([]SyncRef, error) {
+	output, err := Run("for-each-ref", "--format=%(refname)", "refs/heads/", "refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+	mainBranch := GetMainBranch()
+	meta, _ := loadBackupMeta()
+	var refs []SyncRef
+	for _, ref := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			if meta[name].Reason == BackupBeforePurge {
+				continue
+			}
+			if name == mainBranch {
+				refs = append([]SyncRef{{Name: name}}, refs...)
+			} else {
+				refs = append(refs, SyncRef{Name: name})
+			}
+		}
+	}
+	for _, ref := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+			refs = append(refs, SyncRef{Name: name, Tag: true})
+		}
+	}
+	return refs, nil
+}
+
+// PushRefWithProgress pushes one branch or tag to remote, calling progress
+// whenever git reports how far the upload has got. Cancelling ctx stops
+// the push.
+func PushRefWithProgress(ctx context.Context, remote string, ref SyncRef, progress func(Progress)) error {
+	if remote == "origin" && !HasRemote() {
+		return NoRemoteError{}
+	}
+	if ref.Tag {
+		return runPush(ctx, []string{"push", "--progress", remote, "refs/tags/" + ref.Name}, progress)
+	}
+	return pushBranchWithProgress(ctx, remote, ref.Name, progress)
+}
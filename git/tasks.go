@@ -0,0 +1,136 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tasksPath holds the project's to-do list
+var tasksPath = filepath.Join(smoothDir, "tasks.json")
+
+// Task is something to do in the project, optionally linked to the saves
+// that worked on it
+type Task struct {
+	ID      int        `json:"id"`
+	Title   string     `json:"title"`
+	Done    bool       `json:"done"`
+	Created time.Time  `json:"created"`
+	DoneAt  *time.Time `json:"doneAt,omitempty"`
+	Saves   []string   `json:"saves,omitempty"` // hashes of linked saves, oldest first
+}
+
+// Reference is how a save message mentions the task, like "(task #3)"
+func (t Task) Reference() string {
+	return fmt.Sprintf("(task #%d)", t.ID)
+}
+
+// LoadTasks returns every task, in the order they were added
+func LoadTasks() ([]Task, error) {
+	data, err := os.ReadFile(tasksPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", tasksPath, err)
+	}
+	return tasks, nil
+}
+
+// OpenTasks returns the tasks that aren't done yet
+func OpenTasks() []Task {
+	tasks, _ := LoadTasks()
+	var open []Task
+	for _, t := range tasks {
+		if !t.Done {
+			open = append(open, t)
+		}
+	}
+	return open
+}
+
+// saveTasks writes the task list to disk
+func saveTasks(tasks []Task) error {
+	if err := EnsureSmoothDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tasksPath, data, 0644)
+}
+
+// AddTask adds a task to the end of the list
+func AddTask(title string) (Task, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Task{}, fmt.Errorf("a task needs a title")
+	}
+	tasks, err := LoadTasks()
+	if err != nil {
+		return Task{}, err
+	}
+	task := Task{ID: 1, Title: title, Created: time.Now()}
+	for _, t := range tasks {
+		if t.ID >= task.ID {
+			task.ID = t.ID + 1
+		}
+	}
+	return task, saveTasks(append(tasks, task))
+}
+
+// SetTaskDone marks a task done, or not done again
+func SetTaskDone(id int, done bool) error {
+	return updateTask(id, func(t *Task) {
+		t.Done = done
+		t.DoneAt = nil
+		if done {
+			now := time.Now()
+			t.DoneAt = &now
+		}
+	})
+}
+
+// LinkTaskSave records that a save worked on a task
+func LinkTaskSave(id int, hash string) error {
+	return updateTask(id, func(t *Task) {
+		t.Saves = append(t.Saves, hash)
+	})
+}
+
+// DeleteTask removes a task from the list
+func DeleteTask(id int) error {
+	tasks, err := LoadTasks()
+	if err != nil {
+		return err
+	}
+	for i, t := range tasks {
+		if t.ID == id {
+			return saveTasks(append(tasks[:i], tasks[i+1:]...))
+		}
+	}
+	return fmt.Errorf("there's no task #%d", id)
+}
+
+// updateTask changes one task and writes the list back
+func updateTask(id int, change func(*Task)) error {
+	tasks, err := LoadTasks()
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		if tasks[i].ID == id {
+			change(&tasks[i])
+			return saveTasks(tasks)
+		}
+	}
+	return fmt.Errorf("there's no task #%d", id)
+}
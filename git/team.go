@@ -0,0 +1,221 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// TeamCommit is a save a teammate uploaded that isn't here yet
+type TeamCommit struct {
+	Hash    string
+	Author  string
+	When    string // relative, like "2 hours ago"
+	Message string
+}
+
+// TeamStatus compares this branch with the copy on GitHub after a fetch
+type TeamStatus struct {
+	Branch   string
+	Incoming []TeamCommit // their saves, newest first
+	Ahead    int          // saves here that aren't on GitHub
+}
+
+// IntegrateMethod is how teammates' saves are combined with yours
+type IntegrateMethod int
+
+const (
+	// IntegrateRebase replays your saves on top of theirs, keeping history
+	// in a straight line
+	IntegrateRebase IntegrateMethod = iota
+	// IntegrateMerge combines both with a merge save
+	IntegrateMerge
+)
+
+// ConflictError is returned when combining saves stopped because the same
+// lines were changed on both sides
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d file(s) were changed both here and by a teammate", len(e.Files))
+}
+
+// FetchTeam downloads the current branch from GitHub and returns the saves
+// teammates uploaded since this copy last synced, with who made them.
+// Cancelling ctx stops the download.
+func FetchTeam(ctx context.Context) (TeamStatus, error) {
+	var status TeamStatus
+	if !HasRemote() {
+		return status, NoRemoteError{}
+	}
+	branch, err := CurrentBranch()
+	if err != nil {
+		return status, err
+	}
+	status.Branch = branch
+
+	if output, err := RunContext(ctx, "fetch", "--quiet", "origin", branch); err != nil {
+		if ctx.Err() != nil {
+			return status, err
+		}
+		return status, fmt.Errorf("couldn't reach GitHub: %s", output)
+	}
+	remoteRef := "refs/remotes/origin/" + branch
+
+	output, err := Run("log", "--format=%h%x00%an%x00%ar%x00%s", "HEAD.."+remoteRef)
+	if err != nil {
+		return status, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) == 4 {
+			status.Incoming = append(status.Incoming, TeamCommit{
+				Hash:    parts[0],
+				Author:  parts[1],
+				When:    parts[2],
+				Message: parts[3],
+			})
+		}
+	}
+
+	if output, err := Run("rev-list", "--count", remoteRef+"..HEAD"); err == nil {
+		status.Ahead, _ = strconv.Atoi(output)
+	}
+	return status, nil
+}
+
+// IntegrateTeam brings teammates' fetched saves into the current branch.
+// A backup of the branch is made first. Unsaved changes are set aside and
+// put back afterwards. If both sides changed the same lines, it stops with
+// a *ConflictError, to be finished with ContinueTeamSync or undone with
+// AbortTeamSync.
+func IntegrateTeam(method IntegrateMethod) error {
+	branch, err := CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if _, err := CreateBackup(branch, BackupBeforeMerge); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	remoteRef := "origin/" + branch
+	args := []string{"rebase", "--autostash", remoteRef}
+	if method == IntegrateMerge {
+		args = []string{"merge", "--autostash", "--no-edit", remoteRef}
+	}
+	return integrateStep(args)
+}
+
+// ContinueTeamSync carries on combining saves once every conflicted file
+// has been resolved. A rebase can stop again at a later save.
+func ContinueTeamSync() error {
+	if rebasing, _ := TeamSyncInProgress(); rebasing {
+		return integrateStep([]string{"rebase", "--continue"})
+	}
+	return integrateStep([]string{"commit", "--no-edit"})
+}
+
+// AbortTeamSync puts the branch back the way it was before IntegrateTeam
+func AbortTeamSync() error {
+	rebasing, merging := TeamSyncInProgress()
+	switch {
+	case rebasing:
+		_, err := Run("rebase", "--abort")
+		return err
+	case merging:
+		_, err := Run("merge", "--abort")
+		return err
+	}
+	return nil
+}
+
+// TeamSyncInProgress checks if a rebase or merge stopped partway through
+func TeamSyncInProgress() (rebasing, merging bool) {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if path, err := Run("rev-parse", "--git-path", dir); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				rebasing = true
+			}
+		}
+	}
+	if _, err := Run("rev-parse", "--verify", "--quiet", "MERGE_HEAD"); err == nil {
+		merging = true
+	}
+	return rebasing, merging
+}
+
+// ConflictedFiles returns the files still waiting for a conflict to be
+// resolved
+func ConflictedFiles() ([]string, error) {
+	output, err := RunRaw("diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+	var files []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// ResolveConflict settles a conflicted file by keeping one side whole:
+// your version, or your teammate's
+func ResolveConflict(path string, keepMine bool) error {
+	// While rebasing, git calls the teammate's side "ours", since your
+	// saves are being replayed on top of theirs
+	rebasing, _ := TeamSyncInProgress()
+	side := "--ours"
+	if keepMine == rebasing {
+		side = "--theirs"
+	}
+	if _, err := Run("checkout", side, "--", path); err != nil {
+		return err
+	}
+	_, err := Run("add", "--", path)
+	return err
+}
+
+// MarkResolved records that a conflicted file was fixed by hand. It
+// refuses while the file still has conflict markers in it.
+func MarkResolved(path string) error {
+	data, err := os.ReadFile(path)
+	if err == nil && hasConflictMarkers(data) {
+		return fmt.Errorf("%s still has conflict markers (<<<<<<<) in it", path)
+	}
+	_, err = Run("add", "--", path)
+	return err
+}
+
+// hasConflictMarkers checks for the lines git writes around a conflict
+func hasConflictMarkers(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("<<<<<<< ")) || bytes.HasPrefix(line, []byte(">>>>>>> ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// integrateStep runs a rebase or merge step without opening an editor for
+// messages, turning a stop for conflicts into a *ConflictError
+func integrateStep(args []string) error {
+	output, err := runGit(args, func(cmd *exec.Cmd) {
+		cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	})
+	recordActivity(args, string(output), err)
+	if err == nil {
+		return nil
+	}
+	if files, _ := ConflictedFiles(); len(files) > 0 {
+		return &ConflictError{Files: files}
+	}
+	return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+}
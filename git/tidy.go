@@ -0,0 +1,145 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TidyGroup is a run of consecutive saves to be combined into one save
+type TidyGroup struct {
+	Hashes  []string // full hashes, oldest first
+	Message string   // message for the combined save
+}
+
+// TidyableSaves returns the recent saves on the current branch that can be
+// tidied, newest first. Saves already uploaded to GitHub are left alone,
+// since rewriting them would make the next sync fail, and so is anything
+// from a merge or older.
+func TidyableSaves(count int) ([]CommitInfo, error) {
+	output, err := Run("log", fmt.Sprintf("-%d", count), "--format=%h|%s|%cr|%H|%P")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return []CommitInfo{}, nil
+	}
+
+	// Only the saves that haven't been uploaded yet
+	uploadable := count
+	if branch, err := CurrentBranch(); err == nil {
+		if _, err := Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err == nil {
+			n, err := Run("rev-list", "--count", "origin/"+branch+"..HEAD")
+			if err != nil {
+				return nil, err
+			}
+			uploadable, _ = strconv.Atoi(n)
+		}
+	}
+
+	var saves []CommitInfo
+	for i, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) != 5 || i >= uploadable || len(strings.Fields(parts[4])) > 1 {
+			break
+		}
+		saves = append(saves, CommitInfo{
+			Hash:      parts[0],
+			Message:   parts[1],
+			Timestamp: parts[2],
+			FullHash:  parts[3],
+		})
+	}
+	return saves, nil
+}
+
+// TidyHistory rewrites recent saves so each group becomes a single save
+// with the group's message. Groups are oldest first and must cover a run of
+// saves from TidyableSaves up to the latest one. A backup of the branch is
+// made first, and unsaved changes are put back afterwards.
+//
+// It works by handing git rebase a prepared todo list through
+// GIT_SEQUENCE_EDITOR: each group's first save is picked, the rest are
+// folded in with fixup, and the message is set with commit --amend.
+func TidyHistory(groups []TidyGroup) (backup string, err error) {
+	if len(groups) == 0 {
+		return "", fmt.Errorf("nothing to tidy")
+	}
+
+	// Saves after the last group would be dropped from the todo list
+	last := groups[len(groups)-1].Hashes
+	if head, _ := Run("rev-parse", "HEAD"); len(last) == 0 || last[len(last)-1] != head {
+		return "", fmt.Errorf("tidying has to include the latest save")
+	}
+
+	branch, err := CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	backup, err = CreateBackup(branch, BackupBeforeTidy)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "smooth-tidy-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	var todo strings.Builder
+	for i, g := range groups {
+		if len(g.Hashes) == 0 {
+			return "", fmt.Errorf("save group %d is empty", i+1)
+		}
+		message := strings.TrimSpace(g.Message)
+		if message == "" {
+			return "", fmt.Errorf("save group %d needs a message", i+1)
+		}
+		msgPath := filepath.Join(dir, fmt.Sprintf("message-%d", i))
+		if err := os.WriteFile(msgPath, []byte(message+"\n"), 0644); err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&todo, "pick %s\n", g.Hashes[0])
+		for _, h := range g.Hashes[1:] {
+			fmt.Fprintf(&todo, "fixup %s\n", h)
+		}
+		fmt.Fprintf(&todo, "exec git commit --amend --quiet --allow-empty -F %s\n", shellQuote(msgPath))
+	}
+	todoPath := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todoPath, []byte(todo.String()), 0644); err != nil {
+		return "", err
+	}
+
+	// Rebase onto the save before the oldest one, or rewrite from the very
+	// first save if that's where the groups start
+	args := []string{"rebase", "--interactive", "--autostash"}
+	oldest := groups[0].Hashes[0]
+	if _, err := Run("rev-parse", "--verify", "--quiet", oldest+"^"); err == nil {
+		args = append(args, oldest+"^")
+	} else {
+		args = append(args, "--root")
+	}
+
+	output, err := runGit(args, func(cmd *exec.Cmd) {
+		cmd.Env = append(os.Environ(),
+			"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoPath),
+			"GIT_EDITOR=true",
+		)
+	})
+	recordActivity(args, string(output), err)
+	if err != nil {
+		Run("rebase", "--abort")
+		return "", fmt.Errorf("couldn't tidy saves: %s", strings.TrimSpace(string(output)))
+	}
+	return backup, nil
+}
+
+// shellQuote quotes a path for git's shell, which is sh even on Windows
+func shellQuote(path string) string {
+	return "'" + strings.ReplaceAll(filepath.ToSlash(path), "'", `'\''`) + "'"
+}
@@ -1,17 +1,30 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
+	"smooth/config"
+	"smooth/demo"
 	"smooth/git"
+	"smooth/mcp"
+	"smooth/notify"
+	"smooth/platform"
 	"smooth/ui"
 	"smooth/web"
 )
@@ -27,6 +40,33 @@ const (
 	StateBackups
 	StateExperiments
 	StateSettings
+	StateTimeline
+	StateIgnore
+	StateEOL
+	StateOptimize
+	StateTrophies
+	StateActivity
+	StateCleanup
+	StateRewind
+	StateSnapshots
+	StateBlame
+	StateStats
+	StateTidy
+	StateExport
+	StateTeam
+	StateTasks
+	StateRemotes
+	StatePlugin
+	StateDetached
+	StateStorage
+	StatePurge
+	StateCompare
+	StateAmend
+	StateStash
+	StateRecover
+	StateLogs
+	StateCommitDetail
+	StateQuit
 )
 
 // Model is the main application model
@@ -39,16 +79,62 @@ type Model struct {
 	backups     ui.BackupsModel
 	experiments ui.ExperimentsModel
 	settings    ui.SettingsModel
+	timeline    ui.TimelineModel
+	ignore      ui.IgnoreModel
+	eol         ui.EOLModel
+	optimize    ui.OptimizeModel
+	trophies    ui.TrophiesModel
+	activity    ui.ActivityModel
+	cleanup     ui.CleanupModel
+	rewind      ui.RewindModel
+	snapshots   ui.SnapshotsModel
+	blame       ui.BlameModel
+	stats       ui.StatsModel
+	tidy        ui.TidyModel
+	export      ui.ExportModel
+	team        ui.TeamModel
+	tasks       ui.TasksModel
+	remotes     ui.RemotesModel
+	plugin      ui.PluginModel
+	detached    ui.DetachedModel
+	storage     ui.StorageModel
+	purge       ui.PurgeModel
+	compare     ui.CompareModel
+	amend       ui.AmendModel
+	stash       ui.StashModel
+	recover     ui.RecoverModel
+	logs        ui.LogsModel
+	toast       ui.Toast
+	detail      ui.CommitDetailModel
+	quit        ui.QuitModel
+	detailFrom  AppState // the list the details were opened from
+	showKeys    bool     // whether ? has the current screen's keys open
+	transition  ui.Transition
+	checkmark   ui.Checkmark // pops in the checkmark of a new success message
+	announced   string       // the last line announced in accessible mode
 	width       int
 	height      int
 }
 
 // NewModel creates a new application model
 func NewModel() Model {
-	return Model{
+	m := Model{
 		state: StateMenu,
 		menu:  ui.NewMenuModel(),
 	}
+	// Something outside smooth left HEAD on a save; help get back on a
+	// branch before anything else
+	if git.IsDetached() {
+		m.state = StateDetached
+		m.detached = ui.NewDetachedModel()
+	}
+	// An operation cut short by a crash left the project half-changed;
+	// finish or undo it first
+	if journal, ok := git.LoadJournal(); ok {
+		m.state = StateRecover
+		m.recover = ui.NewRecoverModel(journal)
+	}
+	return m
 }
 
 // Init initializes the application
@@ -57,8 +143,45 @@ func (m Model) Init() tea.Cmd {
 	return m.menu.Init()
 }
 
-// Update handles messages
+// Update handles messages, sliding in the new screen whenever the state
+// changes and, with fancy animations, popping in new success checkmarks
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(ui.TransitionFrameMsg); ok {
+		var cmd tea.Cmd
+		m.transition, cmd = m.transition.Update(msg)
+		return m, cmd
+	}
+	if _, ok := msg.(ui.CheckmarkFrameMsg); ok {
+		var cmd tea.Cmd
+		m.checkmark, cmd = m.checkmark.Update(msg)
+		return m, cmd
+	}
+
+	prev := m.state
+	updated, cmd := m.update(msg)
+	next := updated.(Model)
+	if next.state != prev {
+		var transitionCmd tea.Cmd
+		next.transition, transitionCmd = ui.StartTransition()
+		cmd = tea.Batch(cmd, transitionCmd)
+	}
+	if ui.FancyAnimations() {
+		var checkmarkCmd tea.Cmd
+		next.checkmark, checkmarkCmd = next.checkmark.Watch(next.screenView())
+		cmd = tea.Batch(cmd, checkmarkCmd)
+	}
+	// Screen readers hear each new screen or status as one line
+	if ui.AccessibleMode() {
+		if line := ui.Announcement(next.screenView() + "\n" + next.toast.View()); line != "" && line != next.announced {
+			next.announced = line
+			cmd = tea.Batch(cmd, tea.Println(line))
+		}
+	}
+	return next, cmd
+}
+
+// update routes messages to the current screen
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -67,20 +190,227 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.menu.SetSize(msg.Width, msg.Height)
 		// Continue processing to let sub-models handle it too
 
+	case ui.SyncRetryMsg, ui.IncomingMsg, ui.EndOfDayMsg, ui.QuicksaveMsg, ui.MenuStatusMsg, ui.CIStatusMsg:
+		// Retries, checks for new saves, the end of day, quick saves,
+		// status refreshes and GitHub's checks run in the background, so
+		// the menu hears about them whichever screen is open
+		var cmd tea.Cmd
+		m.menu, cmd = m.menu.Update(msg)
+		// The checks on an upload show on the sync screen too
+		if _, ok := msg.(ui.CIStatusMsg); ok && m.state == StateSync {
+			m.sync, _ = m.sync.Update(msg)
+		}
+		// A quick save on the way out quits once it's done
+		if _, ok := msg.(ui.QuicksaveMsg); ok && m.state == StateQuit {
+			m.quit, _ = m.quit.Update(msg)
+			if m.quit.WantsQuit() {
+				return m, tea.Quit
+			}
+		}
+		return m, cmd
+
+	case ui.CopiedMsg:
+		// Copying happens on any screen, so the toast is shown over all of them
+		var cmd tea.Cmd
+		m.toast, cmd = m.toast.Show("Copied " + msg.What + "!")
+		return m, cmd
+
+	case ui.ToastExpiredMsg:
+		m.toast = m.toast.Update(msg)
+		return m, nil
+
 	case tea.KeyMsg:
-		// Global quit
+		// Any key closes the list of keys, without doing anything else
+		if m.showKeys {
+			m.showKeys = false
+			return m, nil
+		}
+		if msg.String() == "?" && !m.screenKeys().Typing {
+			m.showKeys = true
+			return m, nil
+		}
+
+		// Global quit, saving first or asking first if q would leave changes
+		// unsaved
 		if key.Matches(msg, quitKey) && m.state == StateMenu {
+			cfg, _ := config.Load()
+			if status, ok := m.menu.StatusBar(); ok && status.Unsaved > 0 && msg.String() == "q" {
+				switch {
+				case cfg.SaveOnQuit != config.SaveOnQuitOff:
+					m.state = StateQuit
+					m.quit = ui.NewSaveOnQuitModel(status.Unsaved, cfg.SaveOnQuit == config.SaveOnQuitSync)
+					return m, m.quit.Init()
+				case !cfg.DisableQuitConfirm:
+					m.state = StateQuit
+					m.quit = ui.NewQuitModel(status.Unsaved)
+					return m, m.quit.Init()
+				}
+			}
 			return m, tea.Quit
 		}
 
+		// Quick save without leaving the menu
+		if key.Matches(msg, quicksaveKey) && m.state == StateMenu {
+			return m, m.menu.StartQuicksave()
+		}
+
 		// Handle escape to go back
 		if msg.String() == "esc" {
 			switch m.state {
-			case StateSave, StateSync, StateRestore, StateBackups:
+			case StateSave:
+				if m.save.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateSync:
+				if m.sync.HandlesEsc() {
+					break
+				}
 				m.state = StateMenu
 				cmd := m.menu.RefreshStatus()
 				return m, cmd
+			case StateQuit:
+				if m.quit.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateRestore, StateTimeline, StateIgnore, StateEOL, StateOptimize, StateTrophies, StateActivity, StateStats, StateExport, StatePlugin:
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateCleanup:
+				if m.cleanup.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateRewind:
+				if m.rewind.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateSnapshots:
+				if m.snapshots.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateBlame:
+				if m.blame.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateTidy:
+				if m.tidy.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateTeam:
+				if m.team.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateTasks:
+				if m.tasks.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateRemotes:
+				if m.remotes.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateDetached:
+				if m.detached.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateBackups:
+				if m.backups.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateStorage:
+				if m.storage.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StatePurge:
+				if m.purge.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateCompare:
+				if m.compare.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateAmend:
+				if m.amend.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateStash:
+				if m.stash.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateRecover:
+				if m.recover.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateLogs:
+				if m.logs.HandlesEsc() {
+					break
+				}
+				m.state = StateMenu
+				cmd := m.menu.RefreshStatus()
+				return m, cmd
+			case StateCommitDetail:
+				if m.detail.HandlesEsc() {
+					break
+				}
+				m.state = m.detailFrom
+				return m, nil
 			case StateSettings:
+				if m.settings.HandlesEsc() {
+					break
+				}
 				if m.settings.HasUnsavedChanges() {
 					m.settings.PromptExit()
 					return m, nil
@@ -113,14 +443,118 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = StateSync
 				m.sync = ui.NewSyncModel()
 				return m, m.sync.Init()
+			case ui.ActionSyncAll:
+				m.state = StateSync
+				m.sync = ui.NewSyncAllModel()
+				return m, m.sync.Init()
 			case ui.ActionRestore:
 				m.state = StateRestore
 				m.restore = ui.NewRestoreModel()
 				return m, m.restore.Init()
+			case ui.ActionTimeline:
+				m.state = StateTimeline
+				m.timeline = ui.NewTimelineModel()
+				return m, m.timeline.Init()
 			case ui.ActionBackups:
 				m.state = StateBackups
 				m.backups = ui.NewBackupsModel()
 				return m, m.backups.Init()
+			case ui.ActionBackUpNow:
+				m.state = StateBackups
+				m.backups = ui.NewBackUpNowModel()
+				return m, m.backups.Init()
+			case ui.ActionIgnore:
+				m.state = StateIgnore
+				m.ignore = ui.NewIgnoreModel()
+				return m, m.ignore.Init()
+			case ui.ActionFixLineEndings:
+				m.state = StateEOL
+				m.eol = ui.NewEOLModel()
+				return m, m.eol.Init()
+			case ui.ActionOptimize:
+				m.state = StateOptimize
+				m.optimize = ui.NewOptimizeModel()
+				return m, m.optimize.Init()
+			case ui.ActionTrophies:
+				m.state = StateTrophies
+				m.trophies = ui.NewTrophiesModel()
+				return m, m.trophies.Init()
+			case ui.ActionActivity:
+				m.state = StateActivity
+				m.activity = ui.NewActivityModel()
+				return m, m.activity.Init()
+			case ui.ActionCleanup:
+				m.state = StateCleanup
+				m.cleanup = ui.NewCleanupModel()
+				return m, m.cleanup.Init()
+			case ui.ActionRewind:
+				m.state = StateRewind
+				m.rewind = ui.NewRewindModel()
+				return m, m.rewind.Init()
+			case ui.ActionSnapshots:
+				m.state = StateSnapshots
+				m.snapshots = ui.NewSnapshotsModel()
+				return m, m.snapshots.Init()
+			case ui.ActionBlame:
+				m.state = StateBlame
+				m.blame = ui.NewBlameModel()
+				return m, m.blame.Init()
+			case ui.ActionStats:
+				m.state = StateStats
+				m.stats = ui.NewStatsModel()
+				return m, m.stats.Init()
+			case ui.ActionTidy:
+				m.state = StateTidy
+				m.tidy = ui.NewTidyModel()
+				return m, m.tidy.Init()
+			case ui.ActionExport:
+				m.state = StateExport
+				m.export = ui.NewExportModel()
+				return m, m.export.Init()
+			case ui.ActionTeamSync:
+				m.state = StateTeam
+				m.team = ui.NewTeamModel()
+				return m, m.team.Init()
+			case ui.ActionTasks:
+				m.state = StateTasks
+				m.tasks = ui.NewTasksModel()
+				return m, m.tasks.Init()
+			case ui.ActionRemotes:
+				m.state = StateRemotes
+				m.remotes = ui.NewRemotesModel()
+				return m, m.remotes.Init()
+			case ui.ActionPlugin:
+				m.state = StatePlugin
+				m.plugin = ui.NewPluginModel(m.menu.SelectedPlugin())
+				return m, m.plugin.Init()
+			case ui.ActionFixDetached:
+				m.state = StateDetached
+				m.detached = ui.NewDetachedModel()
+				return m, m.detached.Init()
+			case ui.ActionStorage:
+				m.state = StateStorage
+				m.storage = ui.NewStorageModel()
+				return m, m.storage.Init()
+			case ui.ActionPurgeFile:
+				m.state = StatePurge
+				m.purge = ui.NewPurgeModel()
+				return m, m.purge.Init()
+			case ui.ActionCompare:
+				m.state = StateCompare
+				m.compare = ui.NewCompareModel()
+				return m, m.compare.Init()
+			case ui.ActionAmend:
+				m.state = StateAmend
+				m.amend = ui.NewAmendModel()
+				return m, m.amend.Init()
+			case ui.ActionStash:
+				m.state = StateStash
+				m.stash = ui.NewStashModel()
+				return m, m.stash.Init()
+			case ui.ActionLogs:
+				m.state = StateLogs
+				m.logs = ui.NewLogsModel()
+				return m, m.logs.Init()
 			case ui.ActionExperiments:
 				m.state = StateExperiments
 				m.experiments = ui.NewExperimentsModel()
@@ -160,12 +594,144 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmd := m.menu.RefreshStatus()
 			return m, cmd
 		}
+		if m.state == StateTimeline && m.timeline.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateIgnore && m.ignore.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateEOL && m.eol.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateOptimize && m.optimize.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateTrophies && m.trophies.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateActivity && m.activity.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateCleanup && m.cleanup.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateRewind && m.rewind.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateSnapshots && m.snapshots.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateBlame && m.blame.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateStats && m.stats.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateTidy && m.tidy.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateExport && m.export.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateTeam && m.team.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateTasks && m.tasks.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateRemotes && m.remotes.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StatePlugin && m.plugin.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateDetached && m.detached.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateStorage && m.storage.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StatePurge && m.purge.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateCompare && m.compare.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateAmend && m.amend.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateStash && m.stash.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateRecover && m.recover.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
+		if m.state == StateLogs && m.logs.IsDone() {
+			m.state = StateMenu
+			cmd := m.menu.RefreshStatus()
+			return m, cmd
+		}
 		if m.state == StateBackups && m.backups.IsDone() {
 			m.state = StateMenu
 			cmd := m.menu.RefreshStatus()
 			return m, cmd
 		}
 		if m.state == StateExperiments && m.experiments.IsDone() {
+			// Unsaved changes that clashed after switching get sorted out
+			// right away
+			if conflict := m.experiments.StashConflict(); conflict != nil {
+				m.state = StateStash
+				m.stash = ui.NewStashConflictModel(conflict)
+				return m, nil
+			}
 			// After keep/abandon, go back to main menu
 			if m.experiments.ShouldReturnToMainMenu() {
 				m.state = StateMenu
@@ -179,6 +745,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Settings doesn't auto-close, handled by esc key above
 	}
 
+	// The status bar takes the bottom line under every screen but the menu,
+	// which shows the status at the top
+	if size, ok := msg.(tea.WindowSizeMsg); ok && m.state != StateMenu {
+		size.Height--
+		msg = size
+	}
+
 	// Delegate to sub-models
 	var cmd tea.Cmd
 	switch m.state {
@@ -188,10 +761,112 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.save, cmd = m.save.Update(msg)
 	case StateSync:
 		m.sync, cmd = m.sync.Update(msg)
+		if m.sync.WantsBack() {
+			m.state = StateMenu
+			return m, m.menu.RefreshStatus()
+		}
 	case StateRestore:
 		m.restore, cmd = m.restore.Update(msg)
+		if m.restore.WantsDetail() {
+			return m.openCommitDetail(m.restore.SelectedCommit())
+		}
+	case StateCommitDetail:
+		m.detail, cmd = m.detail.Update(msg)
+		if m.detail.WantsRestore() {
+			m.state = StateRestore
+			m.restore = ui.NewRestoreModelForCommit(m.detail.Commit())
+			return m, m.restore.Init()
+		}
 	case StateBackups:
 		m.backups, cmd = m.backups.Update(msg)
+	case StateIgnore:
+		m.ignore, cmd = m.ignore.Update(msg)
+	case StateEOL:
+		m.eol, cmd = m.eol.Update(msg)
+		if m.eol.WantsBack() {
+			m.state = StateMenu
+			return m, m.menu.RefreshStatus()
+		}
+	case StateTrophies:
+		m.trophies, cmd = m.trophies.Update(msg)
+	case StateActivity:
+		m.activity, cmd = m.activity.Update(msg)
+	case StateLogs:
+		m.logs, cmd = m.logs.Update(msg)
+	case StateRecover:
+		m.recover, cmd = m.recover.Update(msg)
+	case StateStash:
+		m.stash, cmd = m.stash.Update(msg)
+	case StateAmend:
+		m.amend, cmd = m.amend.Update(msg)
+	case StateCompare:
+		m.compare, cmd = m.compare.Update(msg)
+	case StatePurge:
+		m.purge, cmd = m.purge.Update(msg)
+	case StateStorage:
+		m.storage, cmd = m.storage.Update(msg)
+	case StateDetached:
+		m.detached, cmd = m.detached.Update(msg)
+	case StatePlugin:
+		m.plugin, cmd = m.plugin.Update(msg)
+	case StateTasks:
+		m.tasks, cmd = m.tasks.Update(msg)
+	case StateRemotes:
+		m.remotes, cmd = m.remotes.Update(msg)
+	case StateTeam:
+		m.team, cmd = m.team.Update(msg)
+	case StateExport:
+		m.export, cmd = m.export.Update(msg)
+	case StateTidy:
+		m.tidy, cmd = m.tidy.Update(msg)
+	case StateStats:
+		m.stats, cmd = m.stats.Update(msg)
+	case StateBlame:
+		m.blame, cmd = m.blame.Update(msg)
+		// Hand the save that changed the line over to the revert flow
+		if m.blame.WantsRestore() {
+			m.state = StateRestore
+			m.restore = ui.NewRestoreModelForCommit(m.blame.SelectedCommit())
+			return m, m.restore.Init()
+		}
+	case StateSnapshots:
+		m.snapshots, cmd = m.snapshots.Update(msg)
+	case StateRewind:
+		m.rewind, cmd = m.rewind.Update(msg)
+		// Hand the save the chosen time resolved to over to the revert flow
+		if m.rewind.WantsRestore() {
+			m.state = StateRestore
+			m.restore = ui.NewRestoreModelForCommit(m.rewind.SelectedCommit())
+			return m, m.restore.Init()
+		}
+	case StateCleanup:
+		m.cleanup, cmd = m.cleanup.Update(msg)
+	case StateOptimize:
+		m.optimize, cmd = m.optimize.Update(msg)
+		if m.optimize.WantsBack() {
+			m.state = StateMenu
+			return m, m.menu.RefreshStatus()
+		}
+	case StateQuit:
+		m.quit, cmd = m.quit.Update(msg)
+		if m.quit.WantsQuit() {
+			return m, tea.Quit
+		}
+		if m.quit.WantsBack() {
+			m.state = StateMenu
+			return m, m.menu.RefreshStatus()
+		}
+	case StateTimeline:
+		m.timeline, cmd = m.timeline.Update(msg)
+		// Jump into the restore flow for the chosen commit
+		if m.timeline.WantsRestore() {
+			m.state = StateRestore
+			m.restore = ui.NewRestoreModelForCommit(m.timeline.SelectedCommit())
+			return m, m.restore.Init()
+		}
+		if m.timeline.WantsDetail() {
+			return m.openCommitDetail(m.timeline.SelectedCommit())
+		}
 	case StateExperiments:
 		// Check if user wants to go back
 		if m.experiments.WantsBack() {
@@ -212,8 +887,60 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// openCommitDetail shows a save's details, going back to the current
+// screen afterwards
+func (m Model) openCommitDetail(commit git.CommitInfo) (tea.Model, tea.Cmd) {
+	m.detailFrom = m.state
+	m.state = StateCommitDetail
+	m.detail = ui.NewCommitDetailModel(commit)
+	return m, m.detail.Init()
+}
+
+// demoSession is the running demo when started with --demo
+var demoSession *demo.Session
+
 // View renders the application
 func (m Model) View() string {
+	view := m.transition.Apply(m.checkmark.Apply(m.screenView()))
+	if toast := m.toast.View(); toast != "" {
+		view += "\n" + toast
+	}
+	if status, ok := m.menu.StatusBar(); ok && m.state != StateMenu {
+		view = m.withStatusBar(view, status)
+	}
+	if ui.AccessibleMode() {
+		view = ui.Plain(view)
+	}
+	return demoSession.Mask(ui.StripEmoji(view))
+}
+
+// withStatusBar puts the status bar on the bottom line, under a screen
+func (m Model) withStatusBar(view string, status ui.StatusBar) string {
+	bar := " " + status.View()
+	if m.height == 0 || ui.AccessibleMode() {
+		return view + "\n" + bar
+	}
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Left, lipgloss.Top, view) + "\n" + bar
+}
+
+// programOptions returns how a full-screen program runs. Accessible mode
+// stays in the normal screen, so announcements stay in the scrollback for
+// screen readers, and leaves the mouse alone.
+func programOptions(mouse bool) []tea.ProgramOption {
+	if ui.AccessibleMode() {
+		return nil
+	}
+	if mouse {
+		return []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	}
+	return []tea.ProgramOption{tea.WithAltScreen()}
+}
+
+// screenView renders the current screen
+func (m Model) screenView() string {
+	if m.showKeys {
+		return ui.HelpOverlay(m.screenKeys())
+	}
 	switch m.state {
 	case StateSave:
 		return m.save.View()
@@ -227,17 +954,524 @@ func (m Model) View() string {
 		return m.experiments.View()
 	case StateSettings:
 		return m.settings.View()
+	case StateTimeline:
+		return m.timeline.View()
+	case StateIgnore:
+		return m.ignore.View()
+	case StateEOL:
+		return m.eol.View()
+	case StateOptimize:
+		return m.optimize.View()
+	case StateTrophies:
+		return m.trophies.View()
+	case StateActivity:
+		return m.activity.View()
+	case StateLogs:
+		return m.logs.View()
+	case StateRecover:
+		return m.recover.View()
+	case StateStash:
+		return m.stash.View()
+	case StateAmend:
+		return m.amend.View()
+	case StateCompare:
+		return m.compare.View()
+	case StatePurge:
+		return m.purge.View()
+	case StateStorage:
+		return m.storage.View()
+	case StateDetached:
+		return m.detached.View()
+	case StatePlugin:
+		return m.plugin.View()
+	case StateTasks:
+		return m.tasks.View()
+	case StateRemotes:
+		return m.remotes.View()
+	case StateTeam:
+		return m.team.View()
+	case StateExport:
+		return m.export.View()
+	case StateTidy:
+		return m.tidy.View()
+	case StateStats:
+		return m.stats.View()
+	case StateBlame:
+		return m.blame.View()
+	case StateSnapshots:
+		return m.snapshots.View()
+	case StateRewind:
+		return m.rewind.View()
+	case StateCleanup:
+		return m.cleanup.View()
+	case StateCommitDetail:
+		return m.detail.View()
+	case StateQuit:
+		return m.quit.View()
 	default:
 		return m.menu.View()
 	}
 }
 
+// screenKeys returns the keys the current screen takes, for ? to list
+func (m Model) screenKeys() ui.Keys {
+	switch m.state {
+	case StateSave:
+		return m.save.Keys()
+	case StateSync:
+		return m.sync.Keys()
+	case StateRestore:
+		return m.restore.Keys()
+	case StateBackups:
+		return m.backups.Keys()
+	case StateExperiments:
+		return m.experiments.Keys()
+	case StateSettings:
+		return m.settings.Keys()
+	case StateTimeline:
+		return m.timeline.Keys()
+	case StateIgnore:
+		return m.ignore.Keys()
+	case StateEOL:
+		return m.eol.Keys()
+	case StateOptimize:
+		return m.optimize.Keys()
+	case StateTrophies:
+		return m.trophies.Keys()
+	case StateActivity:
+		return m.activity.Keys()
+	case StateLogs:
+		return m.logs.Keys()
+	case StateRecover:
+		return m.recover.Keys()
+	case StateStash:
+		return m.stash.Keys()
+	case StateAmend:
+		return m.amend.Keys()
+	case StateCompare:
+		return m.compare.Keys()
+	case StatePurge:
+		return m.purge.Keys()
+	case StateStorage:
+		return m.storage.Keys()
+	case StateDetached:
+		return m.detached.Keys()
+	case StatePlugin:
+		return m.plugin.Keys()
+	case StateTasks:
+		return m.tasks.Keys()
+	case StateRemotes:
+		return m.remotes.Keys()
+	case StateTeam:
+		return m.team.Keys()
+	case StateExport:
+		return m.export.Keys()
+	case StateTidy:
+		return m.tidy.Keys()
+	case StateStats:
+		return m.stats.Keys()
+	case StateBlame:
+		return m.blame.Keys()
+	case StateSnapshots:
+		return m.snapshots.Keys()
+	case StateRewind:
+		return m.rewind.Keys()
+	case StateCleanup:
+		return m.cleanup.Keys()
+	case StateCommitDetail:
+		return m.detail.Keys()
+	case StateQuit:
+		return m.quit.Keys()
+	default:
+		return m.menu.Keys()
+	}
+}
+
 var quitKey = key.NewBinding(
 	key.WithKeys("q", "ctrl+c"),
 	key.WithHelp("q", "quit"),
 )
 
-// generateTestData creates hundreds of garbage files for stress testing the UI
+var quicksaveKey = key.NewBinding(
+	key.WithKeys("ctrl+s"),
+	key.WithHelp("ctrl+s", "quick save"),
+)
+
+// takeProfileFlag removes --profile name (or --profile=name) from args,
+// wherever it is, and returns the remaining args and the name
+func takeProfileFlag(args []string) ([]string, string, bool) {
+	var rest []string
+	var name string
+	var found bool
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--profile" && i+1 < len(args):
+			name, found = args[i+1], true
+			i++
+		case strings.HasPrefix(args[i], "--profile="):
+			name, found = strings.TrimPrefix(args[i], "--profile="), true
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest, name, found
+}
+
+// jsonOutput is set by --json: commands print the same JSON the web API
+// returns instead of text, for scripts and AI tools
+var jsonOutput bool
+
+// takeFlag removes a flag like --json from args, wherever it is
+func takeFlag(args []string, flag string) ([]string, bool) {
+	var rest []string
+	var found bool
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// exitWithError reports err and exits. With --json it's an object like the
+// web API's errors, {"error": "..."}.
+func exitWithError(err error) {
+	if jsonOutput {
+		printJSON(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Printf("Error: %v\n", err)
+	}
+	os.Exit(1)
+}
+
+// errNotInProject is reported by commands run outside a project
+var errNotInProject = errors.New("not in a project. Run smooth in your project folder first.")
+
+// runExport handles `smooth export [save] [--dir folder] [--format zip|tar.gz]`
+func runExport(args []string) {
+	cfg, _ := config.Load()
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := flags.String("dir", "", "folder to write the archive to (default from settings)")
+	format := flags.String("format", cfg.ExportFormat, "archive format: zip or tar.gz")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: smooth export [save] [--dir folder] [--format zip|tar.gz]")
+		fmt.Fprintln(flags.Output(), "Without a save, exports your files as they are now, unsaved changes included.")
+		flags.PrintDefaults()
+	}
+
+	// Let the save come before or after the flags
+	var ref string
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		ref = flags.Arg(0)
+		flags.Parse(flags.Args()[1:])
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != config.ExportZip && *format != config.ExportTarGz {
+		fmt.Printf("Unknown format %q: use zip or tar.gz\n", *format)
+		os.Exit(2)
+	}
+
+	if !git.IsRepo() {
+		exitWithError(errNotInProject)
+	}
+	if *dir == "" {
+		var err error
+		if *dir, err = cfg.ExportDirectory(); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	path, err := git.ExportArchive(ref, *dir, *format)
+	if err != nil {
+		exitWithError(fmt.Errorf("export failed: %w", err))
+	}
+	if jsonOutput {
+		printJSON(map[string]string{"path": path})
+		return
+	}
+	fmt.Println("Exported to " + path)
+}
+
+// runWeb handles `smooth web [--port n] [--https] [--open]`, serving until
+// Ctrl+C
+func runWeb(args []string) {
+	flags := flag.NewFlagSet("web", flag.ExitOnError)
+	port := flags.Int("port", 0, fmt.Sprintf("port to serve on (default %d, or the next free one)", config.WebPort))
+	https := flags.Bool("https", false, "serve over HTTPS with a self-signed certificate, so other devices on the network can use it")
+	open := flags.Bool("open", false, "open the web interface in the browser")
+	flags.Parse(args)
+
+	if !git.IsRepo() {
+		exitWithError(errNotInProject)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts := web.Options{Port: *port, HTTPS: *https, Open: *open}
+	err := web.StartServer(ctx, opts)
+	notify.Flush()
+	if err != nil {
+		fmt.Printf("Error starting web server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runStatus handles `smooth status [--porcelain]`. The porcelain output is
+// one key=value per line, and stays the same between versions.
+func runStatus(args []string) {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	porcelain := flags.Bool("porcelain", false, "print key=value lines for scripts")
+	flags.Parse(args)
+
+	if !git.IsRepo() {
+		if jsonOutput {
+			exitWithError(errNotInProject)
+		}
+		if !*porcelain {
+			fmt.Println("Not in a project.")
+		}
+		os.Exit(1)
+	}
+	status, err := git.GetPromptStatus()
+	if err != nil {
+		if jsonOutput {
+			exitWithError(err)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		// The fields of /api/status, and the sync state from the porcelain
+		// output
+		var lastSave *time.Time
+		if !status.LastSave.IsZero() {
+			lastSave = &status.LastSave
+		}
+		printJSON(map[string]interface{}{
+			"branch":       status.Branch,
+			"hasChanges":   status.Unsaved > 0,
+			"isOnMain":     git.IsOnMain(),
+			"detached":     git.IsDetached(),
+			"savesWaiting": git.SavesWaiting(),
+			"busy":         git.BusyWith(),
+			"unsaved":      status.Unsaved,
+			"synced":       status.HasUpstream,
+			"ahead":        status.Ahead,
+			"behind":       status.Behind,
+			"lastSave":     lastSave,
+		})
+		return
+	}
+
+	if *porcelain {
+		var lastSave int64
+		if !status.LastSave.IsZero() {
+			lastSave = status.LastSave.Unix()
+		}
+		fmt.Printf("branch=%s\n", status.Branch)
+		fmt.Printf("unsaved=%d\n", status.Unsaved)
+		fmt.Printf("synced=%t\n", status.HasUpstream)
+		fmt.Printf("ahead=%d\n", status.Ahead)
+		fmt.Printf("behind=%d\n", status.Behind)
+		fmt.Printf("last_save=%d\n", lastSave)
+		return
+	}
+
+	fmt.Println("Branch:     " + status.Branch)
+	fmt.Printf("Unsaved:    %d file(s)\n", status.Unsaved)
+	if status.HasUpstream {
+		fmt.Printf("GitHub:     %d to upload, %d to download\n", status.Ahead, status.Behind)
+	} else {
+		fmt.Println("GitHub:     not synced yet")
+	}
+	if !status.LastSave.IsZero() {
+		age := "just now"
+		if time.Since(status.LastSave) >= time.Minute {
+			age = shortAge(status.LastSave) + " ago"
+		}
+		fmt.Println("Last save:  " + age)
+	}
+}
+
+// runLog handles `smooth log [-n count]`, the latest saves on this branch
+func runLog(args []string) {
+	flags := flag.NewFlagSet("log", flag.ExitOnError)
+	count := flags.Int("n", 20, "how many saves to show")
+	flags.Parse(args)
+
+	if !git.IsRepo() {
+		exitWithError(errNotInProject)
+	}
+	commits, err := git.Log(0, *count)
+	if err != nil {
+		exitWithError(err)
+	}
+	if jsonOutput {
+		// An empty list rather than null, which is easier on scripts
+		if commits == nil {
+			commits = []git.CommitInfo{}
+		}
+		printJSON(commits)
+		return
+	}
+	for _, c := range commits {
+		fmt.Printf("%s  %s  (%s)\n", c.Hash, c.Message, c.Timestamp)
+	}
+}
+
+// runBackups handles `smooth backups`, the backups of this branch, newest
+// first
+func runBackups() {
+	if !git.IsRepo() {
+		exitWithError(errNotInProject)
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		exitWithError(err)
+	}
+	backups, err := git.ListBackups(branch)
+	if err != nil {
+		exitWithError(err)
+	}
+	if jsonOutput {
+		if backups == nil {
+			backups = []git.BackupInfo{}
+		}
+		printJSON(backups)
+		return
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups of " + branch + " yet.")
+		return
+	}
+	for _, b := range backups {
+		fmt.Printf("%s  %s\n", b.Name, b.Reason.Describe())
+	}
+}
+
+// runExperiments handles `smooth experiments`, the experiments and how far
+// each has come from main
+func runExperiments() {
+	if !git.IsRepo() {
+		exitWithError(errNotInProject)
+	}
+	experiments, err := git.ListExperiments()
+	if err != nil {
+		exitWithError(err)
+	}
+	if jsonOutput {
+		if experiments == nil {
+			experiments = []git.BranchInfo{}
+		}
+		printJSON(experiments)
+		return
+	}
+	if len(experiments) == 0 {
+		fmt.Println("No experiments.")
+		return
+	}
+	for _, e := range experiments {
+		marker := " "
+		if e.IsCurrent {
+			marker = "*"
+		}
+		fmt.Printf("%s %s  %d save(s), %s (%s)\n", marker, e.Name, e.Ahead, e.DiffSummary(), e.LastActivity)
+	}
+}
+
+// defaultPromptFormat shows the branch, then only what needs attention
+const defaultPromptFormat = "{branch}{unsaved? ●}{ahead? ↑}{behind? ↓}"
+
+// runPrompt handles `smooth prompt [--format template]`. It prints nothing
+// outside a project, so it can go straight into PS1 or tmux's status-right.
+func runPrompt(args []string) {
+	flags := flag.NewFlagSet("prompt", flag.ExitOnError)
+	format := flags.String("format", defaultPromptFormat,
+		"template with {branch}, {unsaved}, {ahead}, {behind} and {age}; {unsaved? text} shows the text and count only when it isn't 0")
+	flags.Parse(args)
+
+	if !git.IsRepo() {
+		return
+	}
+	status, err := git.GetPromptStatus()
+	if err != nil {
+		return
+	}
+
+	age := ""
+	if !status.LastSave.IsZero() {
+		age = shortAge(status.LastSave)
+	}
+	fmt.Println(expandPrompt(*format, map[string]string{
+		"branch":  status.Branch,
+		"unsaved": strconv.Itoa(status.Unsaved),
+		"ahead":   strconv.Itoa(status.Ahead),
+		"behind":  strconv.Itoa(status.Behind),
+		"age":     age,
+	}))
+}
+
+// expandPrompt fills in a prompt template. {name} is replaced with the
+// value; {name?text} becomes text followed by the value, or nothing if the
+// value is empty or 0.
+func expandPrompt(format string, values map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(format, "{")
+		end := -1
+		if start >= 0 {
+			end = strings.Index(format[start:], "}")
+		}
+		if end < 0 {
+			b.WriteString(format)
+			return b.String()
+		}
+		end += start
+		b.WriteString(format[:start])
+		name, text, conditional := strings.Cut(format[start+1:end], "?")
+		value, known := values[name]
+		switch {
+		case !known:
+			b.WriteString(format[start : end+1])
+		case !conditional:
+			b.WriteString(value)
+		case value != "" && value != "0":
+			b.WriteString(text + value)
+		}
+		format = format[end+1:]
+	}
+}
+
+// shortAge formats how long ago t was in a few characters, like 5m or 2d
+func shortAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dw", int(d.Hours()/24/7))
+}
+
 func generateTestData() {
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
@@ -351,6 +1585,24 @@ func generateTestData() {
 }
 
 func main() {
+	// --profile works with any command, so take it out before looking at them
+	if args, name, ok := takeProfileFlag(os.Args); ok {
+		if err := config.UseProfile(name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		os.Args = args
+		// The ui package set itself up from the default profile
+		cfg, _ := config.Load()
+		ui.ApplySettings(cfg)
+	}
+	os.Args, jsonOutput = takeFlag(os.Args, "--json")
+	var debug bool
+	os.Args, debug = takeFlag(os.Args, "--debug")
+	if cfg, _ := config.Load(); debug || cfg.DebugLogging {
+		git.SetDebugLogging(true)
+	}
+
 	// Check for standalone commands first (these don't require git)
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -360,13 +1612,28 @@ func main() {
 			fmt.Println("Usage:")
 			fmt.Println("  smooth              Start the TUI interface")
 			fmt.Println("  smooth update       Update smooth to the latest version")
-			fmt.Println("  smooth web          Start the web interface (http://localhost:3000)")
+			fmt.Println("  smooth web          Start the web interface (--port, --open, --https for other devices)")
+			fmt.Println("  smooth export       Export your project to a zip (add a save's hash for an older one)")
+			fmt.Println("  smooth status       Show the branch, unsaved changes and sync state (--porcelain for scripts)")
+			fmt.Println("  smooth prompt       Print a short status for shell prompts and tmux (--format to customize)")
+			fmt.Println("  smooth log          List the latest saves (-n to show more)")
+			fmt.Println("  smooth backups      List the backups of this branch")
+			fmt.Println("  smooth experiments  List your experiments")
+			fmt.Println("  smooth serve --mcp  Let editors and AI assistants use smooth (MCP over stdio)")
+			fmt.Println("  smooth --demo       Try smooth on a sample project (safe for recordings)")
+			fmt.Println("  smooth --profile p  Use the settings in profile p, like work or personal")
+			fmt.Println("  smooth --json       Print status, log, backups, experiments and export as JSON")
+			fmt.Println("  smooth --debug      Log every git command to ~/.smooth/logs, for bug reports")
 			fmt.Println("  smooth help         Show this help message")
 			return
 		case "update":
 			fmt.Println("Updating smooth to the latest version...")
 			fmt.Println()
-			cmd := exec.Command("sh", "-c", "curl -fsSL https://raw.githubusercontent.com/richardartoul/smooth-vibes/main/scripts/install.sh | sh")
+			cmd, err := platform.ShellCommand("curl -fsSL https://raw.githubusercontent.com/richardartoul/smooth-vibes/main/scripts/install.sh | sh")
+			if err != nil {
+				fmt.Printf("Update failed: %v\n", err)
+				os.Exit(1)
+			}
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
 			if err := cmd.Run(); err != nil {
@@ -374,6 +1641,60 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "status":
+			runStatus(os.Args[2:])
+			return
+		case "prompt":
+			runPrompt(os.Args[2:])
+			return
+		case "log":
+			runLog(os.Args[2:])
+			return
+		case "backups":
+			runBackups()
+			return
+		case "experiments":
+			runExperiments()
+			return
+		// The web and MCP servers have no terminal to prompt in, so they
+		// start before the setup and branch screens
+		case "web":
+			runWeb(os.Args[2:])
+			return
+		case "serve":
+			if len(os.Args) < 3 || os.Args[2] != "--mcp" {
+				fmt.Println("Usage: smooth serve --mcp")
+				os.Exit(1)
+			}
+			err := mcp.Serve()
+			notify.Flush()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "--demo":
+			session, err := demo.Start()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			demoSession = session
+			// The demo has its own copy of the look and feel settings
+			cfg, _ := config.Load()
+			ui.ApplySettings(cfg)
+			p := tea.NewProgram(NewModel(), programOptions(true)...)
+			_, err = p.Run()
+			notify.Flush()
+			session.Stop()
+			if err != nil {
+				fmt.Printf("Error: %v", err)
+				os.Exit(1)
+			}
+			return
 		}
 	}
 
@@ -381,7 +1702,7 @@ func main() {
 	if !git.IsRepo() {
 		// Run the init prompt UI
 		initModel := ui.NewInitModel()
-		p := tea.NewProgram(initModel, tea.WithAltScreen())
+		p := tea.NewProgram(initModel, programOptions(false)...)
 		finalModel, err := p.Run()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -398,12 +1719,13 @@ func main() {
 		}
 	}
 
-	// Check if we're on main/master branch
+	// Check if we're on main/master branch. A detached HEAD isn't on any
+	// branch; the app opens with a way back instead.
 	currentBranch, _ := git.CurrentBranch()
-	if !git.IsOnMain() {
+	if !git.IsOnMain() && !git.IsDetached() {
 		// Run the branch prompt UI
 		branchModel := ui.NewBranchModel(currentBranch)
-		p := tea.NewProgram(branchModel, tea.WithAltScreen())
+		p := tea.NewProgram(branchModel, programOptions(false)...)
 		finalModel, err := p.Run()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -423,22 +1745,18 @@ func main() {
 	// Check for subcommands that require git
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
-		case "web":
-			port := 3000
-			if err := web.StartServer(port); err != nil {
-				fmt.Printf("Error starting web server: %v\n", err)
-				os.Exit(1)
-			}
-			return
 		case "gen-test-data":
 			generateTestData()
 			return
 		}
 	}
 
-	// Default: run TUI
-	p := tea.NewProgram(NewModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	// Default: run TUI. Webhooks for the last saves, like one made on the
+	// way out, get to finish sending before exiting.
+	p := tea.NewProgram(NewModel(), programOptions(true)...)
+	_, err := p.Run()
+	notify.Flush()
+	if err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,159 @@
+// Package mcp exposes smooth's operations to editors and AI coding
+// assistants as a Model Context Protocol server: JSON-RPC 2.0 messages, one
+// per line, over stdin and stdout.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// protocolVersion is the MCP revision this server speaks
+const protocolVersion = "2025-06-18"
+
+// serverVersion is reported to clients; bump it when the tools change
+const serverVersion = "1.0.0"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request is an incoming JSON-RPC request or notification. Notifications
+// have no ID and get no response.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve answers MCP requests on stdin and stdout until stdin is closed
+func Serve() error {
+	return serve(os.Stdin, os.Stdout)
+}
+
+// serve answers requests read from in, writing responses to out
+func serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			encoder.Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rpcErr := handle(req)
+		if len(req.ID) == 0 {
+			continue // notification
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle dispatches a request to its method
+func handle(req request) (interface{}, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "jsonrpc must be \"2.0\""}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "smooth", "version": serverVersion},
+			"instructions": "smooth is version control for people who don't know git. " +
+				"Saves are commits, reverting resets to a save after backing up, and experiments are branches.",
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		list := make([]map[string]interface{}, 0, len(tools))
+		for _, t := range tools {
+			list = append(list, map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"inputSchema": t.schema(),
+			})
+		}
+		return map[string]interface{}{"tools": list}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		t, ok := findTool(params.Name)
+		if !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		args := arguments{}
+		if len(params.Arguments) > 0 && string(params.Arguments) != "null" {
+			if err := json.Unmarshal(params.Arguments, &args); err != nil {
+				return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+			}
+		}
+		return callResult(t.Run(args)), nil
+	}
+
+	if len(req.ID) == 0 {
+		return nil, nil // unknown notifications, like notifications/initialized, need no answer
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+}
+
+// callResult wraps a tool's outcome in an MCP tool result. Failures are
+// reported in the result, not as JSON-RPC errors, so the assistant can read
+// and act on them.
+func callResult(result interface{}, err error) map[string]interface{} {
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+	data, _ := json.MarshalIndent(result, "", "  ")
+	return map[string]interface{}{
+		"content":           []map[string]string{{"type": "text", "text": string(data)}},
+		"structuredContent": result,
+	}
+}
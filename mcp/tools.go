@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"smooth/achievements"
+	"smooth/config"
+	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+)
+
+// arguments are a tool call's arguments by name
+type arguments map[string]json.RawMessage
+
+// string returns a string argument, or "" if it's missing
+func (a arguments) string(name string) string {
+	var s string
+	json.Unmarshal(a[name], &s)
+	return s
+}
+
+// int returns an integer argument, or def if it's missing
+func (a arguments) int(name string, def int) int {
+	n := def
+	if raw, ok := a[name]; ok {
+		json.Unmarshal(raw, &n)
+	}
+	return n
+}
+
+// bool returns a boolean argument, or false if it's missing
+func (a arguments) bool(name string) bool {
+	var b bool
+	json.Unmarshal(a[name], &b)
+	return b
+}
+
+// param describes one argument of a tool
+type param struct {
+	Name        string
+	Type        string // JSON Schema type
+	Description string
+	Required    bool
+}
+
+// tool is an operation smooth offers over MCP
+type tool struct {
+	Name        string
+	Description string
+	Params      []param
+	Run         func(args arguments) (interface{}, error)
+}
+
+// schema returns the tool's input as a JSON Schema
+func (t tool) schema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, p := range t.Params {
+		prop := map[string]interface{}{"type": p.Type, "description": p.Description}
+		if p.Type == "object" {
+			prop["additionalProperties"] = map[string]interface{}{
+				"type": "string",
+				"enum": []string{"save", "revert", "skip", "ignore"},
+			}
+		}
+		if p.Type == "array" {
+			prop["items"] = map[string]string{"type": "string"}
+		}
+		properties[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// findTool looks a tool up by name
+func findTool(name string) (tool, bool) {
+	for _, t := range tools {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+// tools are the operations offered, in the order they're listed
+var tools = []tool{
+	{
+		Name:        "status",
+		Description: "Show the current branch (or whether HEAD is detached on a save), whether there are unsaved changes, and how many saves are waiting to upload.",
+		Run: func(args arguments) (interface{}, error) {
+			branch, err := git.CurrentBranch()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"branch":       branch,
+				"hasChanges":   git.HasChanges(),
+				"isOnMain":     git.IsOnMain(),
+				"detached":     git.IsDetached(),
+				"savesWaiting": git.SavesWaiting(),
+			}, nil
+		},
+	},
+	{
+		Name:        "list_changes",
+		Description: "List the files with unsaved changes.",
+		Run: func(args arguments) (interface{}, error) {
+			changes, err := git.GetChangeSummary()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"changes": changes}, nil
+		},
+	},
+	{
+		Name: "save",
+		Description: "Save (commit) unsaved changes. By default every changed file is saved; " +
+			"actions can instead revert, skip or permanently ignore individual files. " +
+			"Saves are blocked if the files look like they contain secrets, unless listed in allow_secrets.",
+		Params: []param{
+			{Name: "message", Type: "string", Description: "What changed, in a sentence", Required: true},
+			{Name: "actions", Type: "object", Description: "Action per file path: save, revert, skip or ignore. Unlisted files are saved, unless skipped in an earlier save."},
+			{Name: "allow_secrets", Type: "array", Description: "Paths to save even though they look like they contain secrets"},
+			{Name: "skip_checks", Type: "boolean", Description: "Save even if the project's pre-commit hook or check command fails"},
+		},
+		Run: runSave,
+	},
+	{
+		Name:        "log",
+		Description: "List recent saves, newest first.",
+		Params: []param{
+			{Name: "count", Type: "integer", Description: "How many saves to list (default 20)"},
+			{Name: "offset", Type: "integer", Description: "How many of the newest saves to skip, to page through older ones (default 0)"},
+		},
+		Run: func(args arguments) (interface{}, error) {
+			commits, err := git.Log(args.int("offset", 0), args.int("count", 20))
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"saves": commits}, nil
+		},
+	},
+	{
+		Name: "restore",
+		Description: "Revert the project to an earlier save. Unsaved changes and later saves are " +
+			"discarded, but a backup is made first so this can be undone with restore_backup. " +
+			"With as_experiment, the save is opened as a new experiment instead and nothing is discarded.",
+		Params: []param{
+			{Name: "commit", Type: "string", Description: "Hash of the save to go back to", Required: true},
+			{Name: "as_experiment", Type: "boolean", Description: "Open the save as a new experiment, leaving the current branch as it is"},
+		},
+		Run: func(args arguments) (interface{}, error) {
+			commit := args.string("commit")
+			if commit == "" {
+				return nil, errors.New("commit is required")
+			}
+			if _, err := git.Run("rev-parse", "--verify", "--quiet", commit+"^{commit}"); err != nil {
+				return nil, fmt.Errorf("%s is not a save", commit)
+			}
+			if args.bool("as_experiment") {
+				experiment, err := git.OpenAsExperiment(commit)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"experiment": experiment}, nil
+			}
+			branch, _ := git.CurrentBranch()
+			backup, err := git.CreateBackup(branch, git.BackupBeforeRestore)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create backup: %w", err)
+			}
+			cfg, _ := config.Load()
+			git.TrimBackups(branch, cfg.MaxBackups)
+			if err := git.ResetHard(commit); err != nil {
+				return nil, err
+			}
+			notify.Restored("save " + commit)
+			return map[string]interface{}{"backup": backup}, nil
+		},
+	},
+	{
+		Name:        "list_backups",
+		Description: "List the automatic backups made before reverting on the current branch.",
+		Run: func(args arguments) (interface{}, error) {
+			branch, _ := git.CurrentBranch()
+			backups, err := git.ListBackups(branch)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"backups": backups}, nil
+		},
+	},
+	{
+		Name:        "restore_backup",
+		Description: "Put the project back to a backup from list_backups.",
+		Params: []param{
+			{Name: "backup", Type: "string", Description: "Name of the backup", Required: true},
+		},
+		Run: func(args arguments) (interface{}, error) {
+			name := args.string("backup")
+			if !strings.HasPrefix(name, "backup/") {
+				return nil, fmt.Errorf("%q is not a backup", name)
+			}
+			if err := git.RestoreBackup(name); err != nil {
+				return nil, err
+			}
+			notify.Restored("backup " + name)
+			return map[string]interface{}{"restored": name}, nil
+		},
+	},
+	{
+		Name:        "list_snapshots",
+		Description: "List automatic snapshots of unsaved work, taken before risky operations.",
+		Run: func(args arguments) (interface{}, error) {
+			snapshots, err := git.ListSnapshots()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"snapshots": snapshots}, nil
+		},
+	},
+	{
+		Name:        "list_experiments",
+		Description: "List experiments: branches for trying ideas without touching the main work.",
+		Run: func(args arguments) (interface{}, error) {
+			experiments, err := git.ListExperiments()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"experiments": experiments}, nil
+		},
+	},
+	{
+		Name:        "create_experiment",
+		Description: "Start an experiment from the current work and switch to it. Unsaved changes come along.",
+		Params: []param{
+			{Name: "name", Type: "string", Description: "Short name for the experiment", Required: true},
+		},
+		Run: func(args arguments) (interface{}, error) {
+			name := strings.TrimSpace(args.string("name"))
+			if name == "" {
+				return nil, errors.New("name is required")
+			}
+			branch, err := git.CreateExperiment(name)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"branch": branch}, nil
+		},
+	},
+	{
+		Name:        "keep_experiment",
+		Description: "Merge the current experiment into the main work and switch back to it.",
+		Run: func(args arguments) (interface{}, error) {
+			if err := experimentReady(); err != nil {
+				return nil, err
+			}
+			if err := git.KeepExperiment(); err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"branch": git.GetMainBranch()}, nil
+		},
+	},
+	{
+		Name:        "abandon_experiment",
+		Description: "Throw away the current experiment and switch back to the main work.",
+		Run: func(args arguments) (interface{}, error) {
+			if err := experimentReady(); err != nil {
+				return nil, err
+			}
+			if err := git.AbandonExperiment(); err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"branch": git.GetMainBranch()}, nil
+		},
+	},
+	{
+		Name:        "sync",
+		Description: "Upload saves on the current branch to GitHub.",
+		Run: func(args arguments) (interface{}, error) {
+			if err := push(); err != nil {
+				notify.SyncFailedHook(err)
+				return nil, err
+			}
+			branch, _ := git.CurrentBranch()
+			return map[string]interface{}{"branch": branch}, nil
+		},
+	},
+}
+
+// experimentReady checks that an experiment can be kept or abandoned
+func experimentReady() error {
+	if git.IsOnMain() {
+		return errors.New("not on an experiment")
+	}
+	if git.HasChanges() {
+		return errors.New("there are unsaved changes; save them first")
+	}
+	return nil
+}
+
+// runSave saves with the requested action for each file, the same way the
+// save screen does
+func runSave(args arguments) (interface{}, error) {
+	message := strings.TrimSpace(args.string("message"))
+	var actions map[string]string
+	if raw, ok := args["actions"]; ok {
+		if err := json.Unmarshal(raw, &actions); err != nil {
+			return nil, fmt.Errorf("actions: %w", err)
+		}
+	}
+	var allow []string
+	if raw, ok := args["allow_secrets"]; ok {
+		if err := json.Unmarshal(raw, &allow); err != nil {
+			return nil, fmt.Errorf("allow_secrets: %w", err)
+		}
+	}
+
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("no changes to save")
+	}
+
+	files := ops.NewFiles(changes)
+	for path, name := range actions {
+		action, ok := ops.Actions[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q for %s", name, path)
+		}
+		found := false
+		for i := range files {
+			if files[i].Change.Path == path {
+				files[i].Action = action
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no changes", path)
+		}
+	}
+	for _, f := range files {
+		if f.Action == ops.ActionSave && message == "" {
+			return nil, errors.New("message is required to save files")
+		}
+	}
+
+	plan := ops.NewPlan(message, files)
+	plan.AllowSecrets = allow
+	plan.SkipChecks = args.bool("skip_checks")
+	result, err := plan.Execute(nil)
+	if err != nil {
+		var secrets *ops.SecretsFoundError
+		if errors.As(err, &secrets) {
+			var where []string
+			for _, f := range secrets.Findings {
+				where = append(where, fmt.Sprintf("%s (%s)", f.Location(), f.Rule))
+			}
+			return nil, fmt.Errorf("%v: %s. Nothing was saved. Remove them, revert or ignore those files, "+
+				"or pass their paths in allow_secrets if they're not real secrets",
+				err, strings.Join(where, ", "))
+		}
+		var checks *ops.ChecksFailedError
+		if errors.As(err, &checks) {
+			return nil, fmt.Errorf("%v, so nothing was saved. Fix the problems and save again, "+
+				"or pass skip_checks to save anyway. Output:\n%s", err, checks.Output)
+		}
+		return nil, err
+	}
+
+	if result.Hash != "" {
+		achievements.RecordSave(result.LinesAdded, result.LinesDeleted)
+	}
+
+	out := map[string]interface{}{
+		"hash":     result.Hash,
+		"message":  result.Message,
+		"saved":    result.SavedCount,
+		"reverted": result.RevertedCount,
+		"ignored":  result.IgnoredCount,
+		"skipped":  result.SkippedCount,
+	}
+
+	// Auto-sync if enabled, as the save screen does
+	cfg, _ := config.Load()
+	if result.Hash != "" && cfg.AutoSyncEnabled && git.HasRemote() {
+		out["autoSynced"] = true
+		if err := push(); err != nil {
+			out["syncError"] = err.Error()
+			git.QueueSync(err)
+			notify.SyncFailed(err)
+		}
+	}
+	return out, nil
+}
+
+// push uploads the current branch to GitHub, giving up after the network
+// timeout in Settings
+func push() error {
+	cfg, _ := config.Load()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.NetworkTimeout())
+	defer cancel()
+	return git.Push(ctx)
+}
@@ -0,0 +1,101 @@
+// Package notify shows desktop notifications for things that happen while
+// smooth isn't in front of you, like a failed upload.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"smooth/config"
+)
+
+// appName is shown as the sender of each notification
+const appName = "smooth"
+
+// Send shows a desktop notification, if they're turned on in Settings.
+// Failures are ignored: a missing notifier shouldn't get in the way.
+func Send(title, message string) {
+	if cfg, _ := config.Load(); !cfg.NotificationsEnabled {
+		return
+	}
+	go Show(title, message)
+}
+
+// Show shows a desktop notification using whatever the platform provides:
+// osascript on macOS, notify-send on Linux and PowerShell on Windows
+func Show(title, message string) error {
+	cmd, err := command(title, message)
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// command builds the platform's notification command
+func command(title, message string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			appleScriptString(message), appleScriptString(appName+": "+title))
+		return exec.Command("osascript", "-e", script), nil
+
+	case "windows":
+		script := fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode(%s)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode(%s)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(%s).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`,
+			powerShellString(title), powerShellString(message), powerShellString(appName))
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil, fmt.Errorf("notify-send is not installed")
+		}
+		return exec.Command("notify-send", "--app-name="+appName, title, message), nil
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// SyncFailed reports an auto-sync that couldn't upload a save
+func SyncFailed(err error) {
+	SyncFailedHook(err)
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	Send("Auto-sync failed", "Your save is safe on this computer and will upload when it can. "+msg)
+}
+
+// SavesUploaded reports saves from a failed auto-sync that a background
+// retry has now uploaded
+func SavesUploaded(count int) {
+	Send("Saves uploaded", fmt.Sprintf("%d save(s) waiting from an earlier auto-sync are now on GitHub.", count))
+}
+
+// IncomingSaves reports saves someone else synced to the remote
+func IncomingSaves(count int, branch string) {
+	Send("New saves on GitHub", fmt.Sprintf("Someone synced %d new save(s) to %s.", count, branch))
+}
+
+// DayWrappedUp reports the end-of-day summary
+func DayWrappedUp(saves, files int, wip bool) {
+	msg := fmt.Sprintf("Today: %d save(s) touching %d file(s).", saves, files)
+	if wip {
+		msg += " Unsaved work was saved as work in progress."
+	}
+	Send("End of day", msg)
+}
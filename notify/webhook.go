@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// webhookTimeout is how long a webhook gets to answer
+const webhookTimeout = 15 * time.Second
+
+// flushTimeout is how long Flush waits for webhooks still being sent
+const flushTimeout = 3 * time.Second
+
+// sending tracks the webhooks Fire is still sending
+var sending sync.WaitGroup
+
+// Event is something that happened in a project, sent to the webhooks in
+// config that want it
+type Event struct {
+	Event   string    `json:"event"` // config.EventSave, EventSyncFailed or EventRestore
+	Project string    `json:"project"`
+	Branch  string    `json:"branch"`
+	Message string    `json:"message,omitempty"` // the save message, or what was restored
+	Hash    string    `json:"hash,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// defaultTemplates are the messages sent when a webhook has no template
+var defaultTemplates = map[string]string{
+	config.EventSave:       "💾 Saved {project} on {branch}: {message} [{hash}]",
+	config.EventSyncFailed: "⚠️ Sync failed for {project} on {branch}: {error}",
+	config.EventRestore:    "⏪ Restored {project} on {branch} to {message}",
+}
+
+// Saved tells webhooks about a save
+func Saved(message, hash string) {
+	Fire(Event{Event: config.EventSave, Message: message, Hash: hash})
+}
+
+// Restored tells webhooks the project was put back to an earlier save or
+// a backup
+func Restored(what string) {
+	Fire(Event{Event: config.EventRestore, Message: what})
+}
+
+// SyncFailedHook tells webhooks an upload failed. SyncFailed does this
+// too, along with a desktop notification.
+func SyncFailedHook(err error) {
+	Fire(Event{Event: config.EventSyncFailed, Error: err.Error()})
+}
+
+// Fire sends event to every webhook in config that wants it, in the
+// background. Failures are ignored, like desktop notifications. Flush
+// waits for them before exiting.
+func Fire(event Event) {
+	cfg, _ := config.Load()
+	var hooks []config.Webhook
+	for _, hook := range cfg.Webhooks {
+		if hook.URL != "" && hook.Wants(event.Event) {
+			hooks = append(hooks, hook)
+		}
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	if event.Branch == "" {
+		event.Branch, _ = git.CurrentBranch()
+	}
+	if root, err := git.Run("rev-parse", "--show-toplevel"); err == nil {
+		event.Project = filepath.Base(root)
+	}
+	event.Time = time.Now()
+
+	for _, hook := range hooks {
+		sending.Go(func() { callWebhook(hook, event) })
+	}
+}
+
+// Flush waits a little for webhooks that are still being sent, so the
+// ones for the last thing done, like a save on the way out, aren't cut
+// off when smooth exits
+func Flush() {
+	done := make(chan struct{})
+	go func() {
+		sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+	}
+}
+
+// callWebhook posts event to hook in the shape its kind expects
+func callWebhook(hook config.Webhook, event Event) error {
+	template := hook.Template
+	if template == "" && hook.GuessKind() != config.WebhookGeneric {
+		template = defaultTemplates[event.Event]
+	}
+
+	switch hook.GuessKind() {
+	case config.WebhookSlack:
+		return Webhook(hook.URL, map[string]string{"text": expandEvent(template, event, false)})
+	case config.WebhookDiscord:
+		return Webhook(hook.URL, map[string]string{"content": expandEvent(template, event, false)})
+	}
+	if template == "" {
+		return Webhook(hook.URL, event)
+	}
+	return post(hook.URL, []byte(expandEvent(template, event, true)))
+}
+
+// expandEvent fills in a webhook template's placeholders. With escape, the
+// values are escaped to go inside a JSON string.
+func expandEvent(template string, event Event, escape bool) string {
+	value := func(s string) string {
+		if !escape {
+			return s
+		}
+		quoted, _ := json.Marshal(s)
+		return string(quoted[1 : len(quoted)-1])
+	}
+	firstLine, _, _ := strings.Cut(event.Error, "\n")
+	return strings.NewReplacer(
+		"{event}", value(event.Event),
+		"{project}", value(event.Project),
+		"{branch}", value(event.Branch),
+		"{message}", value(event.Message),
+		"{hash}", value(event.Hash),
+		"{error}", value(firstLine),
+		"{time}", value(event.Time.Format(time.RFC3339)),
+	).Replace(template)
+}
+
+// Webhook posts payload as JSON to url, for services like Slack, Zapier or
+// an email relay to pick up
+func Webhook(url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return post(url, data)
+}
+
+// post sends a JSON body to url
+func post(url string, body []byte) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
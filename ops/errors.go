@@ -0,0 +1,92 @@
+package ops
+
+import (
+	"fmt"
+	"strings"
+
+	"smooth/scan"
+)
+
+// StagingMismatchError is returned when the files git staged for a save
+// differ from the files the user chose to save
+type StagingMismatchError struct {
+	Unexpected []string // staged, but not part of the save
+	Missing    []string // part of the save, but git didn't stage them
+}
+
+func (e *StagingMismatchError) Error() string {
+	var parts []string
+	if len(e.Unexpected) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) were already staged outside of smooth: %s",
+			len(e.Unexpected), strings.Join(e.Unexpected, ", ")))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("%d file(s) could not be staged (they may be ignored by .gitignore): %s",
+			len(e.Missing), strings.Join(e.Missing, ", ")))
+	}
+	return "the save didn't match what you reviewed; " + strings.Join(parts, "; ")
+}
+
+// ChecksFailedError is returned when the project's pre-commit hook or check
+// command fails, which stops the save
+type ChecksFailedError struct {
+	Check  string // the command, or "pre-commit hook"
+	Output string
+}
+
+func (e *ChecksFailedError) Error() string {
+	return fmt.Sprintf("%s failed", e.Check)
+}
+
+// SecretsFoundError is returned when the files about to be committed look
+// like they contain secrets
+type SecretsFoundError struct {
+	Findings []scan.Finding
+}
+
+func (e *SecretsFoundError) Error() string {
+	return fmt.Sprintf("found %d possible secret(s) in the files being saved", len(e.Findings))
+}
+
+// checkStaged compares the planned paths against what git actually staged.
+// Planned directories (untracked folders show up as "dir/") match any
+// staged file inside them. Returns nil if they match.
+func checkStaged(planned, staged []string) *StagingMismatchError {
+	matches := func(plan, path string) bool {
+		if plan == path {
+			return true
+		}
+		return strings.HasSuffix(plan, "/") && strings.HasPrefix(path, plan)
+	}
+
+	var mismatch StagingMismatchError
+	for _, path := range staged {
+		found := false
+		for _, plan := range planned {
+			if matches(plan, path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mismatch.Unexpected = append(mismatch.Unexpected, path)
+		}
+	}
+	for _, plan := range planned {
+		found := false
+		for _, path := range staged {
+			if matches(plan, path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mismatch.Missing = append(mismatch.Missing, plan)
+		}
+	}
+
+	if len(mismatch.Unexpected) == 0 && len(mismatch.Missing) == 0 {
+		return nil
+	}
+	return &mismatch
+}
@@ -0,0 +1,72 @@
+package ops
+
+import (
+	"smooth/config"
+	"smooth/git"
+)
+
+// Action is what a save does with one changed file
+type Action int
+
+const (
+	ActionSave   Action = iota // stage and commit the file
+	ActionRevert               // discard the changes, back to the last save
+	ActionSkip                 // leave it out, keeping the changes, in later saves too
+	ActionIgnore               // add it to .gitignore
+)
+
+// Actions names each action, for the web UI and MCP server to ask for
+var Actions = map[string]Action{
+	"save":   ActionSave,
+	"revert": ActionRevert,
+	"skip":   ActionSkip,
+	"ignore": ActionIgnore,
+}
+
+// File is a changed file and what saving does with it
+type File struct {
+	Change git.FileChange
+	Action Action
+}
+
+// NewFiles starts every changed file off as saved, except files still on
+// the skip list from an earlier save
+func NewFiles(changes []git.FileChange) []File {
+	skipped := git.SkippedFiles()
+	files := make([]File, len(changes))
+	for i, c := range changes {
+		files[i] = File{Change: c, Action: ActionSave}
+		if _, ok := skipped[c.Path]; ok {
+			files[i].Action = ActionSkip
+		}
+	}
+	return files
+}
+
+// NewPlan sorts the files into their planned actions, with message run
+// through the save message template. Every front end saves with a plan
+// made here.
+func NewPlan(message string, files []File) SavePlan {
+	cfg, _ := config.Load()
+	plan := SavePlan{Message: ApplyMessageTemplate(message, files), Sign: cfg.SignCommits}
+	for _, f := range files {
+		switch f.Action {
+		case ActionSave:
+			plan.Save = append(plan.Save, f.Change.Path)
+			if f.Change.OldPath != "" {
+				plan.RenamedFrom = append(plan.RenamedFrom, f.Change.OldPath)
+			}
+		case ActionRevert:
+			// Reverting a rename puts the file back where it was
+			plan.Revert = append(plan.Revert, f.Change.Path)
+			if f.Change.OldPath != "" {
+				plan.Revert = append(plan.Revert, f.Change.OldPath)
+			}
+		case ActionIgnore:
+			plan.Ignore = append(plan.Ignore, f.Change.Path)
+		case ActionSkip:
+			plan.Skip = append(plan.Skip, f.Change.Path)
+		}
+	}
+	return plan
+}
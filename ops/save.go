@@ -0,0 +1,336 @@
+// Package ops carries out smooth's multi-step operations, like saving, the
+// same way for every front end: the TUI, the web UI and the MCP server
+// build a plan and execute it here, so they can't drift apart.
+package ops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"smooth/git"
+	"smooth/notify"
+	"smooth/scan"
+)
+
+// SavePlan is everything a save will do, worked out up front from the
+// per-file choices. It's also what the journal keeps, so a save cut short
+// by a crash can run again.
+type SavePlan struct {
+	Message string   `json:"message"`
+	Save    []string `json:"save,omitempty"`
+	Revert  []string `json:"revert,omitempty"`
+	Ignore  []string `json:"ignore,omitempty"`
+	Skip    []string `json:"skip,omitempty"`
+
+	RenamedFrom []string `json:"renamedFrom,omitempty"` // old paths of renamed files being saved
+
+	AllowSecrets []string `json:"allowSecrets,omitempty"` // files the user chose to save despite suspected secrets
+	SkipChecks   bool     `json:"skipChecks,omitempty"`   // save even if the project's checks fail
+	Sign         bool     `json:"sign,omitempty"`         // sign the save with the user's GPG or SSH key
+}
+
+// Step is a stage of a save, reported to the progress callback as it starts
+type Step string
+
+const (
+	StepRevert  Step = "Reverting files"
+	StepIgnore  Step = "Updating .gitignore"
+	StepStage   Step = "Staging files"
+	StepSecrets Step = "Checking for secrets"
+	StepChecks  Step = "Running your checks"
+	StepCommit  Step = "Saving"
+)
+
+// SaveResult is what a save did
+type SaveResult struct {
+	Hash          string // short hash of the new save, empty if nothing was saved
+	Message       string // the message it was saved with, template filled in
+	SavedCount    int
+	RevertedCount int
+	IgnoredCount  int
+	SkippedCount  int
+	LinesAdded    int
+	LinesDeleted  int
+}
+
+// journalOperation names saves in the journal
+const journalOperation = "save"
+
+// Execute runs the plan, calling progress, if it isn't nil, as each step
+// starts. If any step fails, the files it touched and the staging area are
+// put back the way they were before returning the error.
+func (p SavePlan) Execute(progress func(Step)) (SaveResult, error) {
+	result := SaveResult{
+		SavedCount:    len(p.Save),
+		RevertedCount: len(p.Revert),
+		IgnoredCount:  len(p.Ignore),
+		SkippedCount:  len(p.Skip),
+	}
+	if progress == nil {
+		progress = func(Step) {}
+	}
+
+	if err := p.run(progress); err != nil {
+		return result, err
+	}
+
+	// Skipped files stay skipped in later saves; anything else was dealt
+	// with, so it's no longer skipped
+	git.SkipFiles(p.Skip)
+	var handled []string
+	handled = append(handled, p.Save...)
+	handled = append(handled, p.Revert...)
+	handled = append(handled, p.Ignore...)
+	git.UnskipFiles(handled)
+
+	if len(p.Save) > 0 {
+		result.Message = p.Message
+		result.Hash, _ = git.Run("rev-parse", "--short", "HEAD")
+		if stats, err := git.GetDiffStatBetweenCommits("HEAD^", "HEAD"); err == nil {
+			result.LinesAdded = stats.TotalAdded
+			result.LinesDeleted = stats.TotalDeleted
+		}
+		notify.Saved(p.Message, result.Hash)
+	}
+	return result, nil
+}
+
+// run carries out the plan's steps, rolling back if one fails
+func (p SavePlan) run(progress func(Step)) error {
+	// Snapshot everything the plan could modify
+	touched := append([]string{}, p.Revert...)
+	if len(p.Ignore) > 0 {
+		touched = append(touched, ".gitignore")
+	}
+	files, err := git.CaptureFileState(touched)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot files before saving: %w", err)
+	}
+	// write-tree fails in some states (e.g. unresolved conflicts); in that
+	// case we can still restore files, just not the staging area
+	index, _ := git.CaptureIndex()
+
+	// Record the save before changing anything, so one cut short by a
+	// crash can be finished or undone the next time smooth starts
+	if err := git.BeginJournal(journalOperation, touched, p); err != nil {
+		return fmt.Errorf("failed to record the save before starting: %w", err)
+	}
+
+	rollback := func(stepErr error) error {
+		restoreErr := files.Restore()
+		if indexErr := git.RestoreIndex(index); restoreErr == nil {
+			restoreErr = indexErr
+		}
+		if restoreErr != nil {
+			// The journal stays, so the next start offers to undo it again
+			return fmt.Errorf("%w (undoing the partial save also failed: %v)", stepErr, restoreErr)
+		}
+		git.EndJournal()
+		return fmt.Errorf("%w (nothing was changed)", stepErr)
+	}
+
+	// 1. Revert files first
+	if len(p.Revert) > 0 {
+		progress(StepRevert)
+		if err := git.RevertFiles(p.Revert); err != nil {
+			return rollback(fmt.Errorf("failed to revert files: %w", err))
+		}
+	}
+
+	// 2. Add files to gitignore
+	gitignoreChanged := false
+	if len(p.Ignore) > 0 {
+		progress(StepIgnore)
+	}
+	for _, path := range p.Ignore {
+		if git.HasGitignorePattern(path) {
+			continue
+		}
+		if err := git.AddToGitignore(path); err != nil {
+			return rollback(fmt.Errorf("failed to add %s to .gitignore: %w", path, err))
+		}
+		gitignoreChanged = true
+	}
+
+	// 3. Stage and commit if there are files to save
+	if len(p.Save) > 0 {
+		// Include .gitignore if we modified it
+		stage := append([]string{}, p.Save...)
+		if gitignoreChanged {
+			stage = append(stage, ".gitignore")
+		}
+
+		progress(StepStage)
+		if err := git.AddFiles(stage); err != nil {
+			return rollback(fmt.Errorf("failed to stage files: %w", err))
+		}
+		// A file moved without git mv is still staged where it was
+		if err := git.StageRemovals(p.RenamedFrom); err != nil {
+			return rollback(fmt.Errorf("failed to stage files: %w", err))
+		}
+
+		// Make sure we're about to commit exactly what the user reviewed
+		staged, err := git.StagedFiles()
+		if err != nil {
+			return rollback(fmt.Errorf("failed to check staged files: %w", err))
+		}
+		if mismatch := checkStaged(append(stage, p.RenamedFrom...), staged); mismatch != nil {
+			return rollback(mismatch)
+		}
+
+		// Stop before anything that looks like a secret gets committed
+		progress(StepSecrets)
+		findings, err := scan.Staged()
+		if err != nil {
+			return rollback(fmt.Errorf("failed to check for secrets: %w", err))
+		}
+		allowed := make(map[string]bool)
+		for _, path := range p.AllowSecrets {
+			allowed[path] = true
+		}
+		var blocked []scan.Finding
+		for _, f := range findings {
+			if !allowed[f.Path] {
+				blocked = append(blocked, f)
+			}
+		}
+		if len(blocked) > 0 {
+			return rollback(&SecretsFoundError{Findings: blocked})
+		}
+
+		// Run the project's own checks, unless the user chose to save anyway
+		if command := git.CheckCommand(); command != "" && !p.SkipChecks {
+			progress(StepChecks)
+			if output, err := git.RunCheckCommand(command); err != nil {
+				if output == "" {
+					output = err.Error()
+				}
+				return rollback(&ChecksFailedError{Check: command, Output: output})
+			}
+		}
+
+		progress(StepCommit)
+		switch {
+		case p.Sign:
+			err = git.CommitSigned(p.Message, p.SkipChecks)
+		case p.SkipChecks:
+			err = git.CommitSkippingHooks(p.Message)
+		default:
+			err = git.Commit(p.Message)
+		}
+		if err != nil {
+			// With a pre-commit hook in place, a refused commit is almost
+			// always the hook saying no
+			var commitErr *git.CommitError
+			if errors.As(err, &commitErr) && !p.SkipChecks && git.HasPreCommitHook() {
+				return rollback(&ChecksFailedError{Check: "pre-commit hook", Output: commitErr.Output})
+			}
+			return rollback(fmt.Errorf("failed to commit: %w", err))
+		}
+	}
+
+	git.EndJournal()
+	return nil
+}
+
+// ResumeSave runs a save cut short by a crash again from the start: the
+// files go back to how they were first, then the same plan runs
+func ResumeSave(journal git.Journal) (SaveResult, error) {
+	var p SavePlan
+	if journal.Operation != journalOperation || json.Unmarshal(journal.Plan, &p) != nil {
+		return SaveResult{}, fmt.Errorf("couldn't read what the save was doing")
+	}
+	if err := journal.Rollback(); err != nil {
+		return SaveResult{}, err
+	}
+	return p.Execute(nil)
+}
+
+// CanResume reports if the operation in journal can run again, rather than
+// only be undone
+func CanResume(journal git.Journal) bool {
+	return journal.Operation == journalOperation && len(journal.Plan) > 0
+}
+
+// PlanStep is one step of a save plan as shown in the dry-run preview
+type PlanStep struct {
+	Summary  string
+	Commands []string // git commands the step will run
+	Lines    []string // lines the step will append to .gitignore
+}
+
+// Preview describes the steps Execute would take, without changing
+// anything
+func (p SavePlan) Preview() []PlanStep {
+	var steps []PlanStep
+
+	if len(p.Revert) > 0 {
+		steps = append(steps, PlanStep{
+			Summary:  fmt.Sprintf("Revert %d file(s) to the last save", len(p.Revert)),
+			Commands: []string{"git " + shellJoin(append([]string{"restore", "--source=HEAD", "--staged", "--worktree", "--"}, p.Revert...))},
+		})
+	}
+
+	var newPatterns []string
+	for _, path := range p.Ignore {
+		if !git.HasGitignorePattern(path) {
+			newPatterns = append(newPatterns, path)
+		}
+	}
+	if len(newPatterns) > 0 {
+		steps = append(steps, PlanStep{
+			Summary: fmt.Sprintf("Append %d line(s) to .gitignore", len(newPatterns)),
+			Lines:   newPatterns,
+		})
+	}
+
+	if len(p.Save) > 0 {
+		stage := append([]string{}, p.Save...)
+		if len(newPatterns) > 0 {
+			stage = append(stage, ".gitignore")
+		}
+		if command := git.CheckCommand(); command != "" && !p.SkipChecks {
+			steps = append(steps, PlanStep{
+				Summary:  "Run your project's checks",
+				Commands: []string{command},
+			})
+		}
+		commit := []string{"commit"}
+		if p.Sign {
+			commit = append(commit, "-S")
+		}
+		if p.SkipChecks {
+			commit = append(commit, "--no-verify")
+		}
+		commit = append(commit, "-m", p.Message)
+		steps = append(steps, PlanStep{
+			Summary: fmt.Sprintf("Save %d file(s)", len(stage)),
+			Commands: []string{
+				"git " + shellJoin(append([]string{"add", "--"}, stage...)),
+				"git " + shellJoin(commit),
+			},
+		})
+	}
+
+	if len(p.Skip) > 0 {
+		steps = append(steps, PlanStep{
+			Summary: fmt.Sprintf("Leave %d file(s) as they are, and skip them next time too", len(p.Skip)),
+		})
+	}
+
+	return steps
+}
+
+// shellJoin joins arguments into a command line, quoting any that need it
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"$`\\*?[]#~=%&;|<>()") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
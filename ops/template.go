@@ -0,0 +1,76 @@
+package ops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// TemplatePlaceholders are the placeholders a save message template can
+// use, with what each one means
+var TemplatePlaceholders = [][]string{
+	{"{message}", "what you typed"},
+	{"{date}", "today, like 2024-03-15"},
+	{"{branch}", "the branch or experiment"},
+	{"{ticket}", "a ticket number like ABC-123 from the branch name"},
+	{"{files}", "the files being saved"},
+}
+
+// ticketPattern finds ticket numbers like ABC-123 in branch names
+var ticketPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9]+-[0-9]+`)
+
+// maxTemplateFiles is how many file names {files} lists before summarizing
+const maxTemplateFiles = 3
+
+// ApplyMessageTemplate builds the save message from the configured template.
+// A template without {message} is used as a prefix. Without a template, the
+// message is used as typed.
+func ApplyMessageTemplate(message string, files []File) string {
+	cfg, _ := config.Load()
+	branch, _ := git.CurrentBranch()
+	return ExpandMessageTemplate(cfg.MessageTemplate, message, branch, SavedPaths(files), time.Now())
+}
+
+// SavedPaths returns the paths of the files marked to be saved
+func SavedPaths(files []File) []string {
+	var paths []string
+	for _, f := range files {
+		if f.Action == ActionSave {
+			paths = append(paths, f.Change.Path)
+		}
+	}
+	return paths
+}
+
+// ExpandMessageTemplate fills in the template's placeholders
+func ExpandMessageTemplate(template, message, branch string, files []string, now time.Time) string {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return message
+	}
+	if !strings.Contains(template, "{message}") {
+		template += " {message}"
+	}
+
+	names := files
+	if len(files) > maxTemplateFiles {
+		names = append(append([]string{}, files[:maxTemplateFiles]...),
+			fmt.Sprintf("%d more", len(files)-maxTemplateFiles))
+	}
+
+	expanded := strings.NewReplacer(
+		"{message}", message,
+		"{date}", now.Format("2006-01-02"),
+		"{branch}", branch,
+		"{ticket}", strings.ToUpper(ticketPattern.FindString(branch)),
+		"{files}", strings.Join(names, ", "),
+	).Replace(template)
+
+	// Placeholders with nothing to fill in can leave stray brackets and spaces
+	expanded = strings.NewReplacer("[]", "", "()", "").Replace(expanded)
+	return strings.Join(strings.Fields(expanded), " ")
+}
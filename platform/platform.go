@@ -0,0 +1,150 @@
+// Package platform hides the differences between operating systems, so the
+// rest of smooth can treat Windows the same as macOS and Linux.
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// IsWindows checks if smooth is running on Windows
+func IsWindows() bool {
+	return runtime.GOOS == "windows"
+}
+
+// HomeDir returns the user's home directory. On Windows, USERPROFILE can be
+// missing in some service and SSH sessions, so HOMEDRIVE and HOMEPATH are
+// tried as well.
+func HomeDir() (string, error) {
+	return homeDir(runtime.GOOS, os.Getenv)
+}
+
+func homeDir(goos string, getenv func(string) string) (string, error) {
+	if goos != "windows" {
+		if home := getenv("HOME"); home != "" {
+			return home, nil
+		}
+		return "", errors.New("$HOME is not defined")
+	}
+	if home := getenv("USERPROFILE"); home != "" {
+		return home, nil
+	}
+	if drive, path := getenv("HOMEDRIVE"), getenv("HOMEPATH"); drive != "" && path != "" {
+		return drive + path, nil
+	}
+	return "", errors.New("%USERPROFILE% is not defined")
+}
+
+// SmoothPath returns a path inside ~/.smooth, where smooth keeps its own
+// settings and stats
+func SmoothPath(elem ...string) (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home, ".smooth"}, elem...)...), nil
+}
+
+// GitPath converts a path typed by the user or built with filepath into the
+// form git expects in .gitignore and pathspecs: forward slashes, relative to
+// the project, without a leading "./".
+func GitPath(path string) string {
+	return gitPath(path, filepath.Separator)
+}
+
+func gitPath(path string, sep byte) string {
+	if sep != '/' {
+		path = strings.ReplaceAll(path, string(sep), "/")
+	}
+	for strings.HasPrefix(path, "./") {
+		path = path[2:]
+	}
+	return path
+}
+
+// GitArgs returns config git needs on this OS, to go before the command.
+// On Windows, paths over 260 characters fail unless core.longpaths is on.
+func GitArgs() []string {
+	return gitArgs(runtime.GOOS)
+}
+
+func gitArgs(goos string) []string {
+	if goos == "windows" {
+		return []string{"-c", "core.longpaths=true"}
+	}
+	return nil
+}
+
+// ShellCommand returns a command that runs a POSIX shell script. Windows has
+// no sh of its own, so the one bundled with Git for Windows is used.
+func ShellCommand(script string) (*exec.Cmd, error) {
+	sh, err := findShell()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(sh, "-c", script), nil
+}
+
+// findShell finds sh on the PATH, or next to git.exe on Windows
+func findShell() (string, error) {
+	if sh, err := exec.LookPath("sh"); err == nil {
+		return sh, nil
+	}
+	if IsWindows() {
+		if git, err := exec.LookPath("git"); err == nil {
+			// git.exe lives in <root>\cmd or <root>\bin; sh.exe in <root>\bin
+			// or <root>\usr\bin
+			root := filepath.Dir(filepath.Dir(git))
+			for _, sh := range []string{
+				filepath.Join(root, "bin", "sh.exe"),
+				filepath.Join(root, "usr", "bin", "sh.exe"),
+			} {
+				if _, err := os.Stat(sh); err == nil {
+					return sh, nil
+				}
+			}
+		}
+		return "", errors.New("couldn't find sh; install Git for Windows from https://git-scm.com")
+	}
+	return "", errors.New("couldn't find sh")
+}
+
+// OpenBrowser opens url in the default browser, without waiting for it
+func OpenBrowser(url string) error {
+	name, args := browserCommand(runtime.GOOS, url)
+	return exec.Command(name, args...).Start()
+}
+
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		// start would need cmd, which treats & in the URL as a new command
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	}
+	return "xdg-open", []string{url}
+}
+
+// LineEnding returns the line ending a file already uses, so lines added to
+// it match: "\r\n" if it has any CRLF lines, otherwise "\n"
+func LineEnding(data []byte) string {
+	if strings.Contains(string(data), "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// TrimCR removes a carriage return from the end of a line of text, which
+// would otherwise send the terminal's cursor back to the start of the line.
+// It reports whether there was one.
+func TrimCR(line string) (string, bool) {
+	if strings.HasSuffix(line, "\r") {
+		return line[:len(line)-1], true
+	}
+	return line, false
+}
@@ -0,0 +1,119 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHomeDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"unix", "linux", map[string]string{"HOME": "/home/sam"}, "/home/sam", false},
+		{"unix ignores USERPROFILE", "darwin", map[string]string{"USERPROFILE": `C:\Users\sam`}, "", true},
+		{"windows", "windows", map[string]string{"USERPROFILE": `C:\Users\sam`, "HOME": "/c/Users/other"}, `C:\Users\sam`, false},
+		{"windows drive and path", "windows", map[string]string{"HOMEDRIVE": "D:", "HOMEPATH": `\Users\sam`}, `D:\Users\sam`, false},
+		{"windows drive only", "windows", map[string]string{"HOMEDRIVE": "D:"}, "", true},
+		{"windows nothing set", "windows", map[string]string{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := homeDir(tt.goos, func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		sep  byte
+		want string
+	}{
+		{"build/", '/', "build/"},
+		{"./build/out.log", '/', "build/out.log"},
+		{`build\out.log`, '/', `build\out.log`}, // a literal backslash on unix
+		{`build\out.log`, '\\', "build/out.log"},
+		{`.\src\gen\`, '\\', "src/gen/"},
+		{`node_modules`, '\\', "node_modules"},
+	}
+	for _, tt := range tests {
+		if got := gitPath(tt.path, tt.sep); got != tt.want {
+			t.Errorf("gitPath(%q, %q) = %q, want %q", tt.path, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestGitArgs(t *testing.T) {
+	if got := gitArgs("linux"); got != nil {
+		t.Errorf("linux: got %v, want none", got)
+	}
+	want := []string{"-c", "core.longpaths=true"}
+	if got := gitArgs("windows"); !reflect.DeepEqual(got, want) {
+		t.Errorf("windows: got %v, want %v", got, want)
+	}
+}
+
+func TestBrowserCommand(t *testing.T) {
+	const url = "http://localhost:3000/?a=1&b=2"
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"darwin", "open", []string{url}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", url}},
+		{"linux", "xdg-open", []string{url}},
+	}
+	for _, tt := range tests {
+		name, args := browserCommand(tt.goos, url)
+		if name != tt.name || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.goos, name, args, tt.name, tt.args)
+		}
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"", "\n"},
+		{"node_modules\n", "\n"},
+		{"node_modules\r\n*.log\r\n", "\r\n"},
+		{"node_modules\n*.log\r\n", "\r\n"},
+	}
+	for _, tt := range tests {
+		if got := LineEnding([]byte(tt.data)); got != tt.want {
+			t.Errorf("LineEnding(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestTrimCR(t *testing.T) {
+	tests := []struct {
+		line     string
+		want     string
+		wantCRLF bool
+	}{
+		{"+hello", "+hello", false},
+		{"+hello\r", "+hello", true},
+		{"-a\rb", "-a\rb", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, crlf := TrimCR(tt.line)
+		if got != tt.want || crlf != tt.wantCRLF {
+			t.Errorf("TrimCR(%q) = %q, %v; want %q, %v", tt.line, got, crlf, tt.want, tt.wantCRLF)
+		}
+	}
+}
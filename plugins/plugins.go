@@ -0,0 +1,195 @@
+// Package plugins runs custom actions kept in ~/.smooth/plugins. Each
+// plugin is an executable, optionally next to a manifest with the same name
+// plus .json that gives the menu its title and description:
+//
+//	~/.smooth/plugins/changelog
+//	~/.smooth/plugins/changelog.json  {"name": "Changelog", "description": "Write CHANGELOG.md from recent saves"}
+//
+// A plugin runs in the project's folder. It gets the project's details as
+// SMOOTH_* environment variables and as JSON on stdin. Whatever it prints is
+// shown when it finishes; printing a JSON Result instead lets it pick the
+// title and whether it worked.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"smooth/git"
+	"smooth/platform"
+)
+
+// runTimeout is how long a plugin gets before it's stopped
+const runTimeout = 2 * time.Minute
+
+// Plugin is an executable in ~/.smooth/plugins
+type Plugin struct {
+	Path        string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Context is what a plugin is told about the project, on stdin
+type Context struct {
+	Root       string   `json:"root"`
+	Branch     string   `json:"branch"`
+	MainBranch string   `json:"mainBranch"`
+	Head       string   `json:"head,omitempty"`
+	Remote     string   `json:"remote,omitempty"`
+	Changes    []Change `json:"changes"`
+}
+
+// Change is a file with unsaved changes
+type Change struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // "added", "modified", "deleted" or "renamed"
+}
+
+// Result is what a plugin reports back. Plugins that print plain text get
+// it as Message, and OK is true unless the plugin exits with an error.
+type Result struct {
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+	OK      bool   `json:"ok"`
+}
+
+// Dir returns where plugins are kept
+func Dir() (string, error) {
+	return platform.SmoothPath("plugins")
+}
+
+// List returns the installed plugins, sorted by name. Files that can't be
+// run and manifests that can't be read are skipped.
+func List() []Plugin {
+	dir, err := Dir()
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var plugins []Plugin
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".json") || !isExecutable(entry) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		plugin := Plugin{Path: path}
+		base := strings.TrimSuffix(path, filepath.Ext(path))
+		if data, err := os.ReadFile(base + ".json"); err == nil {
+			if json.Unmarshal(data, &plugin) != nil {
+				continue
+			}
+		}
+		if plugin.Name == "" {
+			plugin.Name = filepath.Base(base)
+		}
+		if plugin.Description == "" {
+			plugin.Description = "Run the " + filepath.Base(path) + " plugin"
+		}
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return strings.ToLower(plugins[i].Name) < strings.ToLower(plugins[j].Name)
+	})
+	return plugins
+}
+
+// isExecutable checks if a file in the plugins folder can be run. Windows
+// has no execute bit, so the extension decides there.
+func isExecutable(entry os.DirEntry) bool {
+	if platform.IsWindows() {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	info, err := entry.Info()
+	return err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
+
+// CurrentContext collects the project details passed to plugins
+func CurrentContext() (Context, error) {
+	root, err := git.Run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return Context{}, err
+	}
+	ctx := Context{Root: root, MainBranch: git.GetMainBranch(), Remote: git.GetRemoteURL()}
+	ctx.Branch, _ = git.CurrentBranch()
+	ctx.Head, _ = git.Run("rev-parse", "--short", "HEAD")
+	ctx.Changes = []Change{}
+	changes, _ := git.GetChangeSummary()
+	for _, change := range changes {
+		ctx.Changes = append(ctx.Changes, Change{Path: change.Path, Status: change.Status})
+	}
+	return ctx, nil
+}
+
+// Run runs a plugin in the project and returns what it reported. A plugin
+// that exits with an error gives a Result that isn't OK, with what it
+// printed as the message.
+func Run(plugin Plugin) (Result, error) {
+	projectCtx, err := CurrentContext()
+	if err != nil {
+		return Result{}, err
+	}
+	input, err := json.Marshal(projectCtx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, plugin.Path)
+	cmd.Dir = projectCtx.Root
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"SMOOTH_ROOT="+projectCtx.Root,
+		"SMOOTH_BRANCH="+projectCtx.Branch,
+		"SMOOTH_MAIN_BRANCH="+projectCtx.MainBranch,
+		"SMOOTH_HEAD="+projectCtx.Head,
+		"SMOOTH_REMOTE="+projectCtx.Remote,
+		"SMOOTH_CHANGES="+strconv.Itoa(len(projectCtx.Changes)),
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Result{}, fmt.Errorf("%s didn't finish within %s", plugin.Name, runTimeout)
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return Result{}, fmt.Errorf("couldn't run %s: %w", plugin.Name, runErr)
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	result := Result{OK: true}
+	if !strings.HasPrefix(output, "{") || json.Unmarshal([]byte(output), &result) != nil {
+		result = Result{Message: output, OK: true}
+	}
+	if runErr != nil {
+		result.OK = false
+		if result.Message == "" {
+			result.Message = strings.TrimSpace(stderr.String())
+		}
+		if result.Message == "" {
+			result.Message = runErr.Error()
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package scan
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"smooth/git"
+)
+
+// Rule is a pattern that looks like a secret
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Rules are checked in order; a line is reported for the first rule it
+// matches. Specific token formats come before the generic ones.
+var Rules = []Rule{
+	{"Private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Secret in .env-style setting", regexp.MustCompile(`^\s*(?:export\s+)?[A-Z0-9_]*(?:SECRET|TOKEN|API_?KEY|PASSWORD|PASSWD|PRIVATE_KEY)[A-Z0-9_]*\s*=\s*["']?([^\s"'#]{8,})`)},
+	{"Hard-coded secret", regexp.MustCompile(`(?i)(?:secret|token|api_?key|password|passwd)["']?\s*[:=]\s*["']([^"'\s]{12,})["']`)},
+}
+
+// Logged git commands have what looks like a secret masked, the same way
+// findings are
+func init() {
+	git.SetDebugRedact(Redact)
+}
+
+// placeholders are values that show up in examples rather than real secrets
+var placeholders = regexp.MustCompile(`(?i)^(?:\$|<|\{\{|%)|your|example|changeme|placeholder|xxxx|\*\*\*\*|dummy|redacted`)
+
+// Finding is a suspected secret in the staged changes
+type Finding struct {
+	Path  string
+	Line  int    // 0 when the whole file is the problem
+	Rule  string // name of the rule that matched
+	Match string // the matched text, mostly masked
+}
+
+// Location returns the finding's file and line, e.g. "config.js:12"
+func (f Finding) Location() string {
+	if f.Line == 0 {
+		return f.Path
+	}
+	return fmt.Sprintf("%s:%d", f.Path, f.Line)
+}
+
+// IsEnvFile checks if the file name looks like a .env file holding real
+// values, as opposed to a checked-in example
+func IsEnvFile(file string) bool {
+	name := path.Base(file)
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	for _, suffix := range []string{".example", ".sample", ".template", ".dist"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Line checks one line of a file against the rules
+func Line(file string, lineNo int, text string) (Finding, bool) {
+	for _, rule := range Rules {
+		m := rule.Pattern.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		secret := m[0]
+		if len(m) > 1 {
+			secret = m[1]
+			if placeholders.MatchString(secret) {
+				continue
+			}
+		}
+		return Finding{Path: file, Line: lineNo, Rule: rule.Name, Match: mask(secret)}, true
+	}
+	return Finding{}, false
+}
+
+// Redact masks everything in text that the rules take for a secret, line
+// by line
+func Redact(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for _, rule := range Rules {
+			line = rule.Pattern.ReplaceAllStringFunc(line, func(match string) string {
+				m := rule.Pattern.FindStringSubmatch(match)
+				secret := m[0]
+				if len(m) > 1 && m[1] != "" {
+					secret = m[1]
+					if placeholders.MatchString(secret) {
+						return match
+					}
+				}
+				return strings.Replace(match, secret, mask(secret), 1)
+			})
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mask hides all but the start of a secret
+func mask(secret string) string {
+	if len(secret) <= 6 {
+		return strings.Repeat("•", len(secret))
+	}
+	return secret[:4] + strings.Repeat("•", min(len(secret)-4, 12))
+}
+
+// Staged scans the lines added by the staged changes, plus any .env files
+// being saved
+func Staged() ([]Finding, error) {
+	output, err := git.RunRaw("-c", "core.quotePath=false", "diff", "--cached", "-U0",
+		"--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/")
+	if err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(output))
+	}
+
+	var findings []Finding
+	file := ""
+	lineNo := 0
+	inHeader := false // between "diff --git" and the first hunk
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHeader = true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			file = ""
+			if strings.HasPrefix(line, "+++ b/") {
+				// git adds a tab after names containing spaces
+				file = strings.TrimSuffix(strings.TrimPrefix(line, "+++ b/"), "\t")
+				if IsEnvFile(file) {
+					findings = append(findings, Finding{Path: file, Rule: "Environment file"})
+				}
+			}
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			lineNo = hunkStart(line)
+		case !inHeader && strings.HasPrefix(line, "+") && file != "":
+			if f, ok := Line(file, lineNo, line[1:]); ok {
+				findings = append(findings, f)
+			}
+			lineNo++
+		}
+	}
+	return findings, nil
+}
+
+// hunkStart returns the first new line number from a hunk header like
+// "@@ -10,2 +12,3 @@"
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"smooth/config"
+)
+
+// accessibleMode is on when the user wants screen-reader-friendly output:
+// no color, borders or emoji, one panel after another, and a single line
+// announcing each change of screen or status
+var accessibleMode bool
+
+// colorProfile is the terminal's own color profile, restored when
+// accessible mode is turned off
+var colorProfile = lipgloss.ColorProfile()
+
+func init() {
+	cfg, _ := config.Load()
+	SetAccessibleMode(cfg.AccessibleMode)
+}
+
+// AccessibleMode checks if accessible mode is on
+func AccessibleMode() bool {
+	return accessibleMode
+}
+
+// SetAccessibleMode turns accessible mode on or off and restyles the
+// interface to match
+func SetAccessibleMode(on bool) {
+	accessibleMode = on
+	if on {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else {
+		lipgloss.SetColorProfile(colorProfile)
+	}
+	ReloadTheme()
+}
+
+// PanelStyle is the bordered box views draw panels with. Accessible mode
+// drops the border, leaving the panel's text.
+func PanelStyle(border lipgloss.Color) lipgloss.Style {
+	if accessibleMode {
+		return lipgloss.NewStyle()
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Transform(StripEmoji)
+}
+
+// SideBySide lays panels out left to right, gap apart. Accessible mode puts
+// them one after another so they're read in order.
+func SideBySide(gap string, panels ...string) string {
+	if accessibleMode {
+		return strings.Join(panels, "\n\n")
+	}
+	var parts []string
+	for i, panel := range panels {
+		if i > 0 && gap != "" {
+			parts = append(parts, gap)
+		}
+		parts = append(parts, panel)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+// spokenSymbols replaces the symbols that carry meaning with words a
+// screen reader can say
+var spokenSymbols = strings.NewReplacer(
+	"✗ ", "Error: ",
+	"⚠ ", "Warning: ",
+	"✓ ", "",
+	"[✓]", "[x]",
+	"↑↓", "up/down",
+	"←→", "left/right",
+	"↑", "up",
+	"↓", "down",
+	"←", "left",
+	"→", "right",
+	"⏎", "enter",
+	"•", "-",
+	"▸", ">",
+	"▾", "v",
+	"↺", "revert",
+)
+
+// decoration matches emoji and the characters used to draw boxes, bars and
+// the banner, which mean nothing read aloud
+var decoration = regexp.MustCompile(`[\x{2500}-\x{259F}\x{25A0}-\x{25FF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}\x{1F000}-\x{1FAFF}\x{FE0F}\x{200D}]`)
+
+// Plain turns a rendered screen into plain linear text for accessible
+// mode: symbols become words, decoration goes, the padding and centering
+// around each line is trimmed and runs of blank lines become one
+func Plain(view string) string {
+	view = decoration.ReplaceAllString(spokenSymbols.Replace(view), "")
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(view, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		lines = append(lines, line)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// Announcement sums up a rendered screen in one line for accessible mode:
+// its title, and its success, error or warning message if it shows one
+func Announcement(view string) string {
+	var title string
+	for _, line := range strings.Split(view, "\n") {
+		raw := strings.TrimSpace(line)
+		status := strings.HasPrefix(raw, "✓ ") || strings.HasPrefix(raw, "✗ ") || strings.HasPrefix(raw, "⚠ ")
+		text := strings.TrimSpace(Plain(raw))
+		if text == "" {
+			continue
+		}
+		if title == "" {
+			title = text
+			continue
+		}
+		if status {
+			return title + ": " + text
+		}
+	}
+	return title
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// ActivityModel is the model for the activity log screen
+type ActivityModel struct {
+	entries []git.ActivityEntry
+	cursor  int
+	err     error
+	width   int
+	height  int
+}
+
+// NewActivityModel creates a new activity log model
+func NewActivityModel() ActivityModel {
+	entries, err := git.ReadActivity(500)
+	return ActivityModel{entries: entries, err: err}
+}
+
+// Init initializes the model
+func (m ActivityModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m ActivityModel) Update(msg tea.Msg) (ActivityModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the activity log
+func (m ActivityModel) View() string {
+	var s string
+
+	s += RenderTitle("Activity") + "\n\n"
+
+	if m.err != nil {
+		s += RenderError("✗ Couldn't read the activity log") + "\n\n"
+		s += renderErrorDetails(m.err)
+		s += HelpText("Press any key to go back")
+		return BoxStyle.Render(s)
+	}
+
+	if len(m.entries) == 0 {
+		s += RenderMuted("Nothing recorded yet.") + "\n\n"
+		s += RenderMuted("Every git command smooth runs that changes your project shows up here.") + "\n\n"
+		s += HelpText("Press any key to go back")
+		return BoxStyle.Render(s)
+	}
+
+	s += RenderSubtitle("What smooth did, newest first:") + "\n\n"
+
+	maxVisible := 15
+	if m.height > 0 {
+		maxVisible = m.height - 12
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+	}
+	start := 0
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+
+	width := 70
+	if m.width > 0 {
+		width = m.width - 30
+		if width < 30 {
+			width = 30
+		}
+	}
+
+	for i := start; i < len(m.entries) && i < start+maxVisible; i++ {
+		e := m.entries[i]
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.cursor {
+			cursor = MenuCursorStyle.Render("> ")
+			style = ListItemSelectedStyle
+		}
+
+		status := SuccessStyle.Render("✓")
+		if e.Error != "" {
+			status = ErrorStyle.Render("✗")
+		}
+		when := MutedStyle.Render(e.Time.Local().Format("Jan 02 15:04:05"))
+		s += fmt.Sprintf("%s%s %s %s\n", cursor, status, when, style.Render(truncateLine(e.Command, width)))
+	}
+
+	if len(m.entries) > maxVisible {
+		s += MutedStyle.Render(fmt.Sprintf("  ... %d total entries", len(m.entries))) + "\n"
+	}
+
+	selected := m.entries[m.cursor]
+	s += "\n"
+	if selected.Error != "" {
+		s += RenderError("Failed: ") + RenderMuted(selected.Error) + "\n\n"
+	}
+
+	s += HelpBar(m.Keys())
+
+	return BoxStyle.Render(s)
+}
+
+// Keys returns the keys the activity log takes
+func (m ActivityModel) Keys() Keys {
+	if m.IsDone() {
+		return Keys{}
+	}
+	return Keys{
+		Bar:  []key.Binding{hint("↑↓", "navigate"), hint("esc", "back")},
+		More: []key.Binding{vimKeys},
+	}
+}
+
+// IsDone returns true if there is nothing to browse
+func (m ActivityModel) IsDone() bool {
+	return m.err != nil || len(m.entries) == 0
+}
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+	"smooth/git"
+	"smooth/ops"
+	"smooth/scan"
+)
+
+// AmendState represents the state of the edit last save flow
+type AmendState int
+
+const (
+	AmendStateEdit AmendState = iota
+	AmendStateConfirm
+	AmendStateWorking
+	AmendStateSuccess
+	AmendStateError
+	AmendStateEmpty
+)
+
+// AmendModel changes the last save's message and can add the files changed
+// since to it, for fixing a save made a moment too soon
+type AmendModel struct {
+	spinner   spinner.Model
+	state     AmendState
+	textInput textinput.Model
+	last      git.CommitInfo
+	uploaded  bool     // the last save is already on GitHub
+	showForce bool     // force uploading is allowed, which an uploaded save needs after editing
+	changed   []string // paths of changed files that would be added
+	addFiles  bool     // add the changed files to the save
+	confirm   confirmation
+	backup    string // made first if the save was uploaded
+	notice    string
+	err       error
+}
+
+// AmendMsg is sent when the last save has been edited
+type AmendMsg struct {
+	Backup string
+	Err    error
+}
+
+// NewAmendModel creates a model for editing the last save
+func NewAmendModel() AmendModel {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	cfg, _ := config.Load()
+	m := AmendModel{spinner: newSpinner(SpinnerDots), textInput: ti, confirm: newConfirmation(), showForce: cfg.ShowForce()}
+	var err error
+	m.last, m.uploaded, err = git.LastSave()
+	if err != nil {
+		m.state = AmendStateEmpty
+		return m
+	}
+	m.textInput.SetValue(m.last.Message)
+	m.textInput.Focus()
+
+	// The same files a quick save would take: skipped ones stay out
+	if changes, err := git.GetChangeSummary(); err == nil {
+		for _, f := range ops.NewFiles(changes) {
+			if f.Action != FileActionSave {
+				continue
+			}
+			m.changed = append(m.changed, f.Change.Path)
+			if f.Change.OldPath != "" {
+				m.changed = append(m.changed, f.Change.OldPath)
+			}
+		}
+	}
+	return m
+}
+
+// Init initializes the model
+func (m AmendModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// doAmend stages paths, checks them for secrets and replaces the last save
+func doAmend(message string, paths []string) tea.Cmd {
+	return func() tea.Msg {
+		cfg, _ := config.Load()
+		index, _ := git.CaptureIndex()
+		if len(paths) > 0 {
+			if large := git.FindLargeFiles(paths, int64(cfg.LargeFileMB)<<20); len(large) > 0 {
+				return AmendMsg{Err: fmt.Errorf("%s is over %d MB, so save it from Save instead", large[0].Path, cfg.LargeFileMB)}
+			}
+			if err := git.AddFiles(paths); err != nil {
+				git.RestoreIndex(index)
+				return AmendMsg{Err: fmt.Errorf("failed to stage files: %w", err)}
+			}
+			findings, err := scan.Staged()
+			if err == nil && len(findings) > 0 {
+				err = &ops.SecretsFoundError{Findings: findings}
+			}
+			if err != nil {
+				git.RestoreIndex(index)
+				return AmendMsg{Err: err}
+			}
+		}
+		backup, err := git.AmendLastSave(message, cfg.SignCommits)
+		if err != nil {
+			git.RestoreIndex(index)
+		}
+		if branch, branchErr := git.CurrentBranch(); backup != "" && branchErr == nil {
+			git.TrimBackups(branch, cfg.MaxBackups)
+		}
+		return AmendMsg{Backup: backup, Err: err}
+	}
+}
+
+// amend starts editing the save with what was chosen
+func (m AmendModel) amend() (AmendModel, tea.Cmd) {
+	message := strings.TrimSpace(m.textInput.Value())
+	if message == m.last.Message {
+		message = ""
+	}
+	var paths []string
+	if m.addFiles {
+		paths = m.changed
+	}
+	m.state = AmendStateWorking
+	return m, tea.Batch(spinnerTick(m.spinner), doAmend(message, paths))
+}
+
+// Update handles messages
+func (m AmendModel) Update(msg tea.Msg) (AmendModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case AmendMsg:
+		m.backup = msg.Backup
+		if msg.Err != nil {
+			m.state = AmendStateError
+			m.err = msg.Err
+			return m, nil
+		}
+		m.state = AmendStateSuccess
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == AmendStateWorking {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case AmendStateEdit:
+			switch msg.String() {
+			case "tab":
+				if len(m.changed) > 0 {
+					m.addFiles = !m.addFiles
+				}
+				return m, nil
+			case "enter":
+				message := strings.TrimSpace(m.textInput.Value())
+				if message == "" {
+					m.notice = "The save needs a message"
+					return m, nil
+				}
+				if message == m.last.Message && !m.addFiles {
+					m.notice = "Change the message or add your changed files first"
+					return m, nil
+				}
+				if m.uploaded && !m.showForce {
+					m.notice = "Editing it would need a force upload, which your safety level in Settings doesn't allow"
+					return m, nil
+				}
+				m.notice = ""
+				m.confirm = newConfirmation()
+				if !m.uploaded || m.confirm.done() {
+					return m.amend()
+				}
+				m.textInput.Blur()
+				m.state = AmendStateConfirm
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+
+		case AmendStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					return m.amend()
+				}
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = AmendStateEdit
+				m.textInput.Focus()
+				return m, textinput.Blink
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the edit last save flow
+func (m AmendModel) View() string {
+	var s string
+
+	s += RenderTitle("Edit last save") + "\n\n"
+
+	switch m.state {
+	case AmendStateEmpty:
+		s += RenderMuted("No saves yet, so there's nothing to edit.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case AmendStateEdit:
+		s += RenderMuted("Last save: ") + HighlightStyle.Render(m.last.Hash) + " " + MutedStyle.Render(m.last.Timestamp) + "\n\n"
+		s += RenderSubtitle("Message:") + "\n"
+		s += m.textInput.View() + "\n\n"
+		if len(m.changed) > 0 {
+			check := "[ ]"
+			if m.addFiles {
+				check = "[x]"
+			}
+			s += check + " Add my changed files to it " + MutedStyle.Render("("+plural(len(m.changed), "file")+")") + "\n\n"
+		} else {
+			s += RenderMuted("No changed files to add.") + "\n\n"
+		}
+		if m.uploaded {
+			s += RenderError("⚠ This save is already on GitHub.") + "\n"
+			s += RenderMuted("Editing it means the next sync has to replace it there.") + "\n\n"
+		}
+		if m.notice != "" {
+			s += RenderError("✗ "+m.notice) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case AmendStateConfirm:
+		if m.confirm.warn {
+			s += RenderError("⚠ This save is already on GitHub!") + "\n\n"
+		}
+		s += RenderMuted("The edited save replaces it, so your next sync will have to") + "\n"
+		s += RenderMuted("overwrite it on GitHub. If anyone already has it, they'll have the") + "\n"
+		s += RenderMuted("old version. A backup is made first.") + "\n\n"
+		s += m.confirm.prompt("Edit it anyway?") + "\n"
+
+	case AmendStateWorking:
+		s += m.spinner.View() + " " + RenderHighlight("Editing your last save...") + "\n"
+
+	case AmendStateSuccess:
+		s += RenderSuccess("✓ Last save edited") + "\n\n"
+		if m.addFiles {
+			s += RenderMuted("Your changed files are part of it now.") + "\n"
+		}
+		if m.backup != "" {
+			s += RenderMuted("Backup created: ") + MutedStyle.Render(m.backup) + "\n"
+			s += RenderMuted("Sync, then press f to replace the old save on GitHub.") + "\n"
+		}
+		s += "\n" + HelpText("Press any key to continue")
+
+	case AmendStateError:
+		s += RenderError("✗ Couldn't edit the last save") + "\n\n"
+		var secrets *ops.SecretsFoundError
+		if errors.As(m.err, &secrets) {
+			s += RenderMuted("These look like they contain secrets:") + "\n"
+			for _, f := range secrets.Findings {
+				s += "  " + f.Location() + " " + MutedStyle.Render(f.Rule) + "\n"
+			}
+			s += "\n" + RenderMuted("Review them from Save instead. Nothing was changed.") + "\n\n"
+		} else if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		if m.backup != "" {
+			s += RenderMuted("Backup created: ") + MutedStyle.Render(m.backup) + "\n\n"
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// Keys returns the keys the edit last save flow takes in its current state
+func (m AmendModel) Keys() Keys {
+	switch m.state {
+	case AmendStateEdit:
+		k := Keys{Bar: []key.Binding{hint("enter", "edit save")}, Typing: true}
+		if len(m.changed) > 0 {
+			k.Bar = append(k.Bar, hint("tab", "add files"))
+		}
+		k.Bar = append(k.Bar, hint("esc", "cancel"))
+		return k
+	case AmendStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "edit it anyway"), hint("n", "go back to the message")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true once the save is edited or couldn't be
+func (m AmendModel) IsDone() bool {
+	return m.state == AmendStateSuccess || m.state == AmendStateError || m.state == AmendStateEmpty
+}
+
+// HandlesEsc returns true while confirming, where esc goes back to editing
+func (m AmendModel) HandlesEsc() bool {
+	return m.state == AmendStateConfirm
+}
@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+)
+
+// animFrameTime is how often frame-based animations advance
+const animFrameTime = 80 * time.Millisecond
+
+// animFrameMsg advances a screen's frame-based animations
+type animFrameMsg struct{}
+
+// animationsOff and motionReduced hold the animation settings, so frames
+// don't read the config every time they're drawn
+var animationsOff, motionReduced bool
+
+func init() {
+	cfg, _ := config.Load()
+	SetAnimations(!cfg.DisableAnimations)
+	SetReducedMotion(cfg.ReducedMotion)
+}
+
+// SetAnimations turns animations on or off
+func SetAnimations(on bool) {
+	animationsOff = !on
+}
+
+// SetReducedMotion turns reduced motion on or off
+func SetReducedMotion(on bool) {
+	motionReduced = on
+}
+
+// animationsEnabled checks if the user has animations turned on. Reduced
+// motion turns them off, and so does accessible mode, since every frame
+// would be read out.
+func animationsEnabled() bool {
+	return !animationsOff && !motionReduced && !accessibleMode
+}
+
+// reducedMotion checks if the user wants nothing on screen to move
+func reducedMotion() bool {
+	return motionReduced
+}
+
+// stillSpinner stands in for spinners with reduced motion
+var stillSpinner = spinner.Spinner{Frames: []string{"•"}, FPS: time.Second}
+
+// animTick schedules the next animation frame, or nothing if animations
+// are turned off
+func animTick() tea.Cmd {
+	if !animationsEnabled() {
+		return nil
+	}
+	return tea.Tick(animFrameTime, func(time.Time) tea.Msg {
+		return animFrameMsg{}
+	})
+}
+
+// SpinnerVariant selects the look of a spinner
+type SpinnerVariant int
+
+const (
+	SpinnerDots SpinnerVariant = iota
+	SpinnerLine
+	SpinnerPulse
+	SpinnerMeter
+)
+
+// newSpinner creates a themed spinner in the given variant
+func newSpinner(variant SpinnerVariant) spinner.Model {
+	s := spinner.New()
+	switch variant {
+	case SpinnerLine:
+		s.Spinner = spinner.Line
+	case SpinnerPulse:
+		s.Spinner = spinner.Pulse
+	case SpinnerMeter:
+		s.Spinner = spinner.Meter
+	default:
+		s.Spinner = spinner.Dot
+	}
+	if reducedMotion() {
+		s.Spinner = stillSpinner
+	}
+	s.Style = lipgloss.NewStyle().Foreground(ColorAccent)
+	return s
+}
+
+// spinnerTick starts a spinner, or nothing if animations are turned off
+func spinnerTick(s spinner.Model) tea.Cmd {
+	if !animationsEnabled() {
+		return nil
+	}
+	return s.Tick
+}
+
+// RenderProgressBar renders a bar filled to fraction (0 to 1)
+func RenderProgressBar(fraction float64, width int) string {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction * float64(width))
+	return lipgloss.NewStyle().Foreground(ColorSuccess).Render(strings.Repeat("█", filled)) +
+		MutedStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// RenderShimmerBar renders an indeterminate progress bar whose highlight
+// sweeps across as frame advances
+func RenderShimmerBar(frame, width int) string {
+	const glow = 6
+	pos := frame % (width + glow)
+
+	var b strings.Builder
+	for i := 0; i < width; i++ {
+		if i >= pos-glow && i < pos {
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorAccent).Render("█"))
+		} else {
+			b.WriteString(MutedStyle.Render("░"))
+		}
+	}
+	return b.String()
+}
+
+// RenderSkeleton renders placeholder rows standing in for content that's
+// still loading, with a highlight sweeping down them
+func RenderSkeleton(frame, rows, width int) string {
+	widths := []int{width, width * 3 / 4, width * 5 / 6, width / 2}
+	lit := frame / 3 % (rows + 2)
+
+	var lines []string
+	for i := 0; i < rows; i++ {
+		style := MutedStyle
+		if i == lit {
+			style = lipgloss.NewStyle().Foreground(ColorSecondary)
+		}
+		lines = append(lines, "  "+style.Render(strings.Repeat("▬", widths[i%len(widths)])))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TransitionFrameMsg advances a screen transition
+type TransitionFrameMsg struct {
+	sent time.Time
+}
+
+// transitionFrames is how many frames a screen takes to be revealed
+const transitionFrames = 5
+
+// Transition reveals a newly opened screen from the top down, or with
+// fancy animations types it out. It keeps the screen's width and height,
+// so full-width screens like the menu don't wrap. The zero value is a
+// finished transition.
+type Transition struct {
+	remaining  int  // frames left before the screen is fully revealed
+	typewriter bool // typing the screen out instead
+}
+
+// StartTransition begins a transition, or does nothing if animations are
+// turned off
+func StartTransition() (Transition, tea.Cmd) {
+	if FancyAnimations() {
+		return Transition{remaining: typewriterFrames, typewriter: true}, transitionTick()
+	}
+	if !animationsEnabled() {
+		return Transition{}, nil
+	}
+	return Transition{remaining: transitionFrames}, transitionTick()
+}
+
+// transitionTick schedules the next transition frame
+func transitionTick() tea.Cmd {
+	return tea.Tick(animFrameTime/2, func(t time.Time) tea.Msg {
+		return TransitionFrameMsg{sent: t}
+	})
+}
+
+// Update advances the transition. Typing out stops short, showing the
+// whole screen, if the terminal turns out to be too slow for it.
+func (t Transition) Update(msg tea.Msg) (Transition, tea.Cmd) {
+	if msg, ok := msg.(TransitionFrameMsg); ok && t.remaining > 0 {
+		if t.typewriter {
+			noteFrame(msg.sent)
+			if slowTerminal {
+				t.remaining = 0
+				return t, nil
+			}
+		}
+		t.remaining--
+		if t.remaining > 0 {
+			return t, transitionTick()
+		}
+	}
+	return t, nil
+}
+
+// Apply blanks out the part of the view not yet revealed, easing out so
+// most of the screen appears in the first frames
+func (t Transition) Apply(view string) string {
+	if t.remaining <= 0 {
+		return view
+	}
+	if t.typewriter {
+		return typewrite(view, t.remaining)
+	}
+
+	lines := strings.Split(view, "\n")
+	hidden := len(lines) * t.remaining * t.remaining / (transitionFrames * transitionFrames)
+	for i := len(lines) - hidden; i < len(lines); i++ {
+		lines[i] = ""
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,28 +2,46 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"smooth/git"
+	"smooth/notify"
 )
 
 // BackupsState represents the state of the backups flow
 type BackupsState int
 
 const (
-	BackupsStateList BackupsState = iota
+	BackupsStateLoading BackupsState = iota
+	BackupsStateList
 	BackupsStateConfirm
 	BackupsStateRestoring
 	BackupsStateSuccess
 	BackupsStateError
 	BackupsStateEmpty
+	BackupsStateNote
+	BackupsStatePrune
+	BackupsStateConfirmDelete
+	BackupsStateDeleting
+)
+
+// Ways to prune backups
+const (
+	pruneKeepNewest = iota
+	pruneOlderThan
 )
 
 // BackupsModel is the model for the backups flow
 type BackupsModel struct {
+	spinner  spinner.Model
+	loaded   bool // whether the branch and its backups have loaded
 	backups  []git.BackupInfo
 	cursor   int
 	state    BackupsState
@@ -32,29 +50,106 @@ type BackupsModel struct {
 	branch   string
 	width    int
 	height   int
+	confirm  confirmation
+
+	noteInput textinput.Model
+	message   string // the backup just made, shown above the list
+
+	// What restoring the selected backup would change, shown before
+	// confirming
+	previewing  bool                  // whether the preview is still loading
+	preview     git.CommitDiffSummary // files as they are now against the backup
+	savesUndone int                   // saves made since the backup
+	uncommitted git.CommitDiffSummary // unsaved changes that would be lost
+
+	// Deleting and pruning
+	pruneMode int              // pruneKeepNewest or pruneOlderThan
+	pruneKeep int              // how many of the newest backups to keep
+	pruneDays int              // how many days old a backup has to be to go
+	toDelete  []git.BackupInfo // the backups about to be deleted
+	freed     int64            // estimated space deleting them frees
 }
 
-// NewBackupsModel creates a new backups model
+// NewBackupsModel creates a new backups model. The backups load in Init.
 func NewBackupsModel() BackupsModel {
-	branch, _ := git.CurrentBranch()
-	backups, _ := git.ListBackups(branch)
-
-	state := BackupsStateList
-	if len(backups) == 0 {
-		state = BackupsStateEmpty
-	}
+	ti := textinput.New()
+	ti.Placeholder = "before the big refactor"
+	ti.CharLimit = 100
+	ti.Width = 40
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
 
 	return BackupsModel{
-		backups: backups,
-		cursor:  0,
-		state:   state,
-		branch:  branch,
+		spinner:   newSpinner(SpinnerDots),
+		cursor:    0,
+		state:     BackupsStateLoading,
+		confirm:   newConfirmation(),
+		noteInput: ti,
+		pruneKeep: 3,
+		pruneDays: 30,
 	}
 }
 
-// Init initializes the backups model
+// NewBackUpNowModel creates a backups model that starts by asking for a
+// note for a new backup, while the backups load behind it
+func NewBackUpNowModel() BackupsModel {
+	m := NewBackupsModel()
+	m.state = BackupsStateNote
+	m.noteInput.Focus()
+	return m
+}
+
+// Init initializes the backups model, loading the branch's backups
 func (m BackupsModel) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{spinnerTick(m.spinner), doLoadBackups("")}
+	if m.state == BackupsStateNote {
+		cmds = append(cmds, textinput.Blink)
+	}
+	return tea.Batch(cmds...)
+}
+
+// backupsLoadedMsg is sent when the branch's backups have loaded
+type backupsLoadedMsg struct {
+	branch  string
+	backups []git.BackupInfo
+}
+
+// doLoadBackups lists the backups of a branch, looking up the current
+// branch if it's not known yet
+func doLoadBackups(branch string) tea.Cmd {
+	return func() tea.Msg {
+		return loadBackups(branch)
+	}
+}
+
+// loadBackups lists the backups of a branch, or the current branch if
+// branch is empty
+func loadBackups(branch string) backupsLoadedMsg {
+	if branch == "" {
+		branch, _ = git.CurrentBranch()
+	}
+	backups, _ := git.ListBackups(branch)
+	return backupsLoadedMsg{branch: branch, backups: backups}
+}
+
+// backupPreviewMsg is sent when what restoring a backup would change has
+// loaded
+type backupPreviewMsg struct {
+	name        string
+	preview     git.CommitDiffSummary
+	savesUndone int
+	uncommitted git.CommitDiffSummary
+}
+
+// doLoadBackupPreview works out what restoring a backup would change
+func doLoadBackupPreview(name string) tea.Cmd {
+	return func() tea.Msg {
+		msg := backupPreviewMsg{name: name}
+		msg.preview, _ = git.GetDiffStatBetweenCommits("HEAD", name)
+		msg.savesUndone = git.SavesAfter(name)
+		msg.uncommitted, _ = git.GetUncommittedDiffStat()
+		return msg
+	}
 }
 
 // BackupsMsg is sent when a backup operation completes
@@ -62,10 +157,56 @@ type BackupsMsg struct {
 	Err error
 }
 
+// BackupCreatedMsg is sent when a backup the user asked for is made
+type BackupCreatedMsg struct {
+	Name    string
+	Branch  string
+	Backups []git.BackupInfo // the branch's backups, the new one included
+	Err     error
+}
+
+// doCreateBackup backs up the branch as it is now, unsaved changes
+// included, with the user's note. branch is looked up if it hasn't loaded
+// yet.
+func doCreateBackup(branch, note string) tea.Cmd {
+	return func() tea.Msg {
+		loaded := loadBackups(branch)
+		name, err := git.BackUpNow(loaded.branch, note)
+		if err != nil {
+			return BackupCreatedMsg{Err: err}
+		}
+		backups, _ := git.ListBackups(loaded.branch)
+		return BackupCreatedMsg{Name: name, Branch: loaded.branch, Backups: backups}
+	}
+}
+
+// BackupsDeletedMsg is sent when backups have been deleted
+type BackupsDeletedMsg struct {
+	Count   int
+	Freed   int64
+	Backups []git.BackupInfo // the backups left
+	Err     error
+}
+
+// doDeleteBackups deletes backups of a branch; freed is the space they
+// were estimated to take
+func doDeleteBackups(branch string, backups []git.BackupInfo, freed int64) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.DeleteBackups(backups); err != nil {
+			return BackupsDeletedMsg{Err: err}
+		}
+		left, _ := git.ListBackups(branch)
+		return BackupsDeletedMsg{Count: len(backups), Freed: freed, Backups: left}
+	}
+}
+
 // doRestoreBackup performs the backup restoration
 func doRestoreBackup(backupBranch string) tea.Cmd {
 	return func() tea.Msg {
 		err := git.RestoreBackup(backupBranch)
+		if err == nil {
+			notify.Restored("backup " + backupBranch)
+		}
 		return BackupsMsg{Err: err}
 	}
 }
@@ -87,10 +228,74 @@ func (m BackupsModel) Update(msg tea.Msg) (BackupsModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case backupsLoadedMsg:
+		m.branch = msg.branch
+		m.backups = msg.backups
+		m.loaded = true
+		// Asked to back up now, the note is already being written
+		if m.state == BackupsStateLoading {
+			m.state = m.listState()
+		}
+		return m, nil
+
+	case backupPreviewMsg:
+		if msg.name != m.selected.Name {
+			return m, nil
+		}
+		m.previewing = false
+		m.preview = msg.preview
+		m.savesUndone = msg.savesUndone
+		m.uncommitted = msg.uncommitted
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loaded || m.previewing {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case BackupsDeletedMsg:
+		if msg.Err != nil {
+			m.state = BackupsStateError
+			m.err = fmt.Errorf("couldn't delete backups: %w", msg.Err)
+			return m, nil
+		}
+		m.backups = msg.Backups
+		m.cursor = min(m.cursor, max(0, len(m.backups)-1))
+		m.state = m.listState()
+		m.message = fmt.Sprintf("Deleted %s", plural(msg.Count, "backup"))
+		if msg.Freed > 0 {
+			m.message += fmt.Sprintf(", about %s freed once git tidies up", formatSize(msg.Freed))
+		}
+		return m, nil
+
+	case BackupCreatedMsg:
+		if msg.Err != nil {
+			m.state = BackupsStateError
+			m.err = fmt.Errorf("couldn't make the backup: %w", msg.Err)
+			return m, nil
+		}
+		m.branch = msg.Branch
+		m.backups = msg.Backups
+		m.loaded = true
+		m.cursor = 0
+		m.state = BackupsStateList
+		m.message = "Backed up " + m.branch
+		return m, nil
+
 	case tea.KeyMsg:
 		switch m.state {
-		case BackupsStateList:
+		case BackupsStateList, BackupsStateEmpty:
+			m.message = ""
 			switch {
+			case msg.String() == "n":
+				m.state = BackupsStateNote
+				m.noteInput.SetValue("")
+				m.noteInput.Focus()
+				return m, textinput.Blink
+			case m.state == BackupsStateEmpty:
 			case key.Matches(msg, keys.Up):
 				if m.cursor > 0 {
 					m.cursor--
@@ -101,17 +306,98 @@ func (m BackupsModel) Update(msg tea.Msg) (BackupsModel, tea.Cmd) {
 				}
 			case key.Matches(msg, keys.Enter):
 				m.selected = m.backups[m.cursor]
+				if m.confirm.done() {
+					m.state = BackupsStateRestoring
+					return m, doRestoreBackup(m.selected.Name)
+				}
+				m.previewing = true
 				m.state = BackupsStateConfirm
+				return m, tea.Batch(spinnerTick(m.spinner), doLoadBackupPreview(m.selected.Name))
+			case msg.String() == "d":
+				return m.confirmDelete([]git.BackupInfo{m.backups[m.cursor]})
+			case msg.String() == "p":
+				m.state = BackupsStatePrune
 			}
 
 		case BackupsStateConfirm:
 			switch msg.String() {
 			case "y", "Y":
-				m.state = BackupsStateRestoring
-				return m, doRestoreBackup(m.selected.Name)
+				if m.confirm.confirm() {
+					m.state = BackupsStateRestoring
+					return m, doRestoreBackup(m.selected.Name)
+				}
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = BackupsStateList
+			}
+
+		case BackupsStatePrune:
+			switch {
+			case key.Matches(msg, keys.Up):
+				m.pruneMode = pruneKeepNewest
+			case key.Matches(msg, keys.Down):
+				m.pruneMode = pruneOlderThan
+			case msg.String() == "left", msg.String() == "h":
+				if m.pruneMode == pruneKeepNewest && m.pruneKeep > 0 {
+					m.pruneKeep--
+				} else if m.pruneMode == pruneOlderThan && m.pruneDays > 1 {
+					m.pruneDays--
+				}
+			case msg.String() == "right", msg.String() == "l":
+				if m.pruneMode == pruneKeepNewest {
+					m.pruneKeep++
+				} else {
+					m.pruneDays++
+				}
+			case key.Matches(msg, keys.Enter):
+				if pruned := m.pruned(); len(pruned) > 0 {
+					return m.confirmDelete(pruned)
+				}
+			case msg.String() == "esc":
+				m.state = BackupsStateList
+			}
+
+		case BackupsStateConfirmDelete:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					m.state = BackupsStateDeleting
+					return m, doDeleteBackups(m.branch, m.toDelete, m.freed)
+				}
 			case "n", "N", "esc":
+				m.confirm.reset()
 				m.state = BackupsStateList
 			}
+
+		case BackupsStateNote:
+			switch msg.String() {
+			case "enter":
+				m.noteInput.Blur()
+				return m, doCreateBackup(m.branch, m.noteInput.Value())
+			case "esc":
+				m.noteInput.Blur()
+				m.state = m.listState()
+			default:
+				var cmd tea.Cmd
+				m.noteInput, cmd = m.noteInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+	case tea.MouseMsg:
+		if m.state != BackupsStateList {
+			break
+		}
+		if delta := wheelDelta(msg); delta != 0 {
+			if cursor := m.cursor + delta; cursor >= 0 && cursor < len(m.backups) {
+				m.cursor = cursor
+			}
+		} else if i, ok := m.backupAt(msg.Y); ok && isLeftClick(msg) {
+			// Clicking the selected backup restores it, like Enter
+			if i == m.cursor {
+				return m, pressEnter
+			}
+			m.cursor = i
 		}
 	}
 
@@ -122,36 +408,25 @@ func (m BackupsModel) Update(msg tea.Msg) (BackupsModel, tea.Cmd) {
 func (m BackupsModel) View() string {
 	var s string
 
-	s += RenderTitle("Backups") + "\n\n"
-	s += RenderMuted(fmt.Sprintf("Showing backups for: %s", m.branch)) + "\n\n"
+	s += m.renderHeader()
 
 	switch m.state {
+	case BackupsStateLoading:
+		s += m.spinner.View() + " " + RenderHighlight("Loading your backups...") + "\n"
+
 	case BackupsStateEmpty:
+		if m.message != "" {
+			s += RenderSuccess("✓ "+m.message) + "\n\n"
+		}
 		s += RenderMuted("No backups found for this branch!") + "\n\n"
 		s += RenderMuted("Backups are created automatically when you restore") + "\n"
 		s += RenderMuted("to a previous state.") + "\n\n"
-		s += HelpText("Press any key to go back")
+		s += HelpBar(m.Keys())
 
 	case BackupsStateList:
-		s += RenderSubtitle("Select a backup to restore:") + "\n\n"
-
-		// Calculate maxVisible based on terminal height
-		maxVisible := 8
-		if m.height > 0 {
-			available := m.height - 12 // Reserve space for chrome
-			maxVisible = available / 3 // Each item is ~3 lines
-			if maxVisible < 2 {
-				maxVisible = 2
-			}
-			if maxVisible > 12 {
-				maxVisible = 12
-			}
-		}
+		s += m.renderListPrompt()
 
-		start := 0
-		if m.cursor >= maxVisible {
-			start = m.cursor - maxVisible + 1
-		}
+		start, maxVisible := m.listWindow()
 
 		for i := start; i < len(m.backups) && i < start+maxVisible; i++ {
 			backup := m.backups[i]
@@ -164,28 +439,85 @@ func (m BackupsModel) View() string {
 			}
 
 			// Format: hash + message (like revert view)
-			line := fmt.Sprintf("%s %s", backup.CommitHash, backup.Message)
-			if len(line) > 45 {
-				line = line[:42] + "..."
-			}
+			line := truncateLine(fmt.Sprintf("%s %s", backup.CommitHash, backup.Message), 45)
 
 			s += cursor + style.Render(line) + "\n"
-			// Show human-friendly relative timestamp below
-			s += "    " + MutedStyle.Render(formatBackupTimestampRelative(backup.Timestamp)) + "\n\n"
+			// Show human-friendly relative timestamp and why it was made below
+			s += "    " + MutedStyle.Render(truncateLine(backupDetails(backup), 60)) + "\n\n"
 		}
 
 		if len(m.backups) > maxVisible {
 			s += MutedStyle.Render(fmt.Sprintf("  ... %d total backups\n", len(m.backups)))
 		}
 
-		s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "restore"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
+
+	case BackupsStateNote:
+		s += RenderSubtitle("Note for this backup (optional):") + "\n\n"
+		s += m.noteInput.View() + "\n\n"
+		s += RenderMuted("Backs up "+m.branchName()+" as it is now, unsaved changes included,") + "\n"
+		s += RenderMuted("without changing anything.") + "\n\n"
+		s += HelpBar(m.Keys())
 
 	case BackupsStateConfirm:
-		s += RenderError("⚠ Warning: This will discard current changes!") + "\n\n"
+		if m.confirm.warn {
+			s += RenderError("⚠ Warning: This will discard current changes!") + "\n\n"
+		}
 		s += "Restore backup: " + HighlightStyle.Render(m.selected.CommitHash) + "\n"
-		s += RenderMuted(m.selected.Message) + "\n"
-		s += RenderMuted(formatBackupTimestampRelative(m.selected.Timestamp)) + "\n\n"
-		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+		s += RenderMuted(strings.Join(wrapText(m.selected.Message, 60), "\n")) + "\n"
+		s += RenderMuted(backupDetails(m.selected)) + "\n\n"
+		if m.previewing {
+			s += m.spinner.View() + " " + RenderMuted("Working out what would change...") + "\n\n"
+		} else {
+			s += m.renderRestorePreview()
+		}
+		s += m.confirm.prompt("Are you sure?") + "\n"
+
+	case BackupsStatePrune:
+		s += RenderSubtitle("Prune backups") + "\n\n"
+		options := []string{
+			fmt.Sprintf("Keep the newest %d", m.pruneKeep),
+			fmt.Sprintf("Delete backups older than %s", plural(m.pruneDays, "day")),
+		}
+		for i, option := range options {
+			cursor := "  "
+			style := ListItemStyle
+			if m.pruneMode == i {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			s += cursor + style.Render(option) + "\n"
+		}
+		s += "\n"
+		if n := len(m.pruned()); n > 0 {
+			s += RenderMuted(fmt.Sprintf("%s of %d will be deleted", plural(n, "backup"), len(m.backups))) + "\n\n"
+		} else {
+			s += RenderMuted("Nothing to delete") + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case BackupsStateConfirmDelete:
+		if m.confirm.warn {
+			s += RenderError("⚠ Warning: Deleted backups can't be restored!") + "\n\n"
+		}
+		s += fmt.Sprintf("Delete %s:", plural(len(m.toDelete), "backup")) + "\n"
+		for i, b := range m.toDelete {
+			if i == 5 {
+				s += RenderMuted(fmt.Sprintf("  ... and %d more", len(m.toDelete)-5)) + "\n"
+				break
+			}
+			s += "  " + HighlightStyle.Render(b.CommitHash) + " " + truncateLine(b.Message, 30) + " " + RenderMuted(truncateLine(backupDetails(b), 30)) + "\n"
+		}
+		s += "\n"
+		if m.freed > 0 {
+			s += RenderMuted(fmt.Sprintf("Frees about %s once git tidies up.", formatSize(m.freed))) + "\n\n"
+		} else {
+			s += RenderMuted("Their saves are kept elsewhere, so this frees no space.") + "\n\n"
+		}
+		s += m.confirm.prompt("Are you sure?") + "\n"
+
+	case BackupsStateDeleting:
+		s += RenderHighlight("Deleting backups...") + "\n"
 
 	case BackupsStateRestoring:
 		s += RenderHighlight("Restoring from backup...") + "\n"
@@ -198,7 +530,7 @@ func (m BackupsModel) View() string {
 	case BackupsStateError:
 		s += RenderError("✗ Restore failed") + "\n\n"
 		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 	}
@@ -206,9 +538,191 @@ func (m BackupsModel) View() string {
 	return BoxStyle.Render(s)
 }
 
+// renderHeader renders the title and which branch's backups are shown
+func (m BackupsModel) renderHeader() string {
+	s := RenderTitle("Backups") + "\n\n"
+	s += RenderMuted(fmt.Sprintf("Showing backups for: %s", m.branchName())) + "\n\n"
+	return s
+}
+
+// branchName returns the branch whose backups are shown, or a stand-in
+// while it loads
+func (m BackupsModel) branchName() string {
+	if m.branch == "" {
+		return "this branch"
+	}
+	return m.branch
+}
+
+// listState is the state showing the list of backups: still loading, the
+// list, or empty if there are none
+func (m BackupsModel) listState() BackupsState {
+	switch {
+	case !m.loaded:
+		return BackupsStateLoading
+	case len(m.backups) == 0:
+		return BackupsStateEmpty
+	}
+	return BackupsStateList
+}
+
+// renderRestorePreview shows what restoring the selected backup would
+// change: files and lines, saves undone and unsaved changes lost
+func (m BackupsModel) renderRestorePreview() string {
+	var s string
+
+	maxFiles := 6
+	if m.height > 0 {
+		maxFiles = max(2, (m.height-24)/2)
+	}
+
+	if len(m.preview.Files) > 0 {
+		s += RenderSubtitle("Files that will change:") + "\n"
+		s += strings.Join(renderFileStats(m.preview, maxFiles), "\n") + "\n"
+		s += MutedStyle.Render("Total: ") + fmt.Sprintf("%s / %s",
+			SuccessStyle.Render(fmt.Sprintf("+%d", m.preview.TotalAdded)),
+			ErrorStyle.Render(fmt.Sprintf("-%d", m.preview.TotalDeleted))) + "\n\n"
+	} else {
+		s += RenderMuted("Your saved files already match this backup.") + "\n\n"
+	}
+
+	if m.savesUndone > 0 {
+		s += ErrorStyle.Render(fmt.Sprintf("%s since the backup will be undone", plural(m.savesUndone, "save"))) + "\n\n"
+	}
+
+	if len(m.uncommitted.Files) > 0 {
+		s += ErrorStyle.Render("Unsaved changes that will be lost:") + "\n"
+		s += strings.Join(renderFileStats(m.uncommitted, maxFiles), "\n") + "\n"
+		s += RenderMuted("(a snapshot is kept under Snapshots)") + "\n\n"
+	}
+	return s
+}
+
+// backupsListPrompt heads the list of backups
+const backupsListPrompt = "Select a backup to restore:"
+
+// renderListPrompt renders what's above the list: the backup just made,
+// if any, and the prompt
+func (m BackupsModel) renderListPrompt() string {
+	var s string
+	if m.message != "" {
+		s += RenderSuccess("✓ "+m.message) + "\n\n"
+	}
+	return s + RenderSubtitle(backupsListPrompt) + "\n\n"
+}
+
+// listWindow returns the first backup shown and how many fit, scrolled so
+// the cursor stays visible
+func (m BackupsModel) listWindow() (start, maxVisible int) {
+	// Calculate maxVisible based on terminal height
+	maxVisible = 8
+	if m.height > 0 {
+		available := m.height - 12 // Reserve space for chrome
+		maxVisible = available / 3 // Each item is ~3 lines
+		if maxVisible < 2 {
+			maxVisible = 2
+		}
+		if maxVisible > 12 {
+			maxVisible = 12
+		}
+	}
+
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+	return start, maxVisible
+}
+
+// backupAt returns which backup in the list is drawn on row y
+func (m BackupsModel) backupAt(y int) (int, bool) {
+	top := boxContentTop + linesAbove(m.renderHeader()+m.renderListPrompt())
+	if y < top {
+		return 0, false
+	}
+	start, maxVisible := m.listWindow()
+	// Each backup takes two lines and a blank one
+	row := y - top
+	if row%3 == 2 || row/3 >= maxVisible {
+		return 0, false
+	}
+	i := start + row/3
+	return i, i < len(m.backups)
+}
+
+// Keys returns the keys the backups flow takes in its current state
+func (m BackupsModel) Keys() Keys {
+	switch m.state {
+	case BackupsStateEmpty:
+		return Keys{Bar: []key.Binding{hint("n", "back up now"), hint("esc", "back")}}
+	case BackupsStateList:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "restore"), hint("n", "back up now"), hint("d", "delete"), hint("p", "prune"), hint("esc", "cancel")},
+			More: []key.Binding{vimKeys, hint("click", "select, again to restore"), hint("wheel", "scroll")},
+		}
+	case BackupsStateNote:
+		return Keys{Bar: []key.Binding{hint("enter", "back up"), hint("esc", "cancel")}, Typing: true}
+	case BackupsStatePrune:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "choose"), hint("←→", "change"), hint("enter", "prune"), hint("esc", "cancel")},
+			More: []key.Binding{vimKeys, hint("h/l", "change")},
+		}
+	case BackupsStateConfirm, BackupsStateConfirmDelete:
+		return Keys{More: []key.Binding{hint("y", "yes"), hint("n", "no, back to the list")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the backups flow is complete
 func (m BackupsModel) IsDone() bool {
-	return m.state == BackupsStateSuccess || m.state == BackupsStateError || m.state == BackupsStateEmpty
+	return m.state == BackupsStateSuccess || m.state == BackupsStateError
+}
+
+// HandlesEsc returns true while writing a note, pruning or confirming,
+// where esc goes back to the list
+func (m BackupsModel) HandlesEsc() bool {
+	switch m.state {
+	case BackupsStateNote, BackupsStateConfirm, BackupsStatePrune, BackupsStateConfirmDelete:
+		return true
+	}
+	return false
+}
+
+// pruned returns the backups the chosen prune would delete
+func (m BackupsModel) pruned() []git.BackupInfo {
+	if m.pruneMode == pruneOlderThan {
+		return git.BackupsOlderThan(m.backups, time.Duration(m.pruneDays)*24*time.Hour)
+	}
+	return git.BackupsBeyond(m.backups, m.pruneKeep)
+}
+
+// confirmDelete asks before deleting backups, with how much space that
+// frees, unless the safety level skips confirmations
+func (m BackupsModel) confirmDelete(backups []git.BackupInfo) (BackupsModel, tea.Cmd) {
+	m.toDelete = backups
+	m.freed, _ = git.BackupsSize(backups)
+	m.confirm = newConfirmation()
+	if m.confirm.done() {
+		m.state = BackupsStateDeleting
+		return m, doDeleteBackups(m.branch, m.toDelete, m.freed)
+	}
+	m.state = BackupsStateConfirmDelete
+	return m, nil
+}
+
+// backupDetails describes when and why a backup was made, like
+// "2 hours ago · Before a restore"
+func backupDetails(backup git.BackupInfo) string {
+	details := formatBackupTimestampRelative(backup.Timestamp)
+	if why := backup.Reason.Describe(); why != "" {
+		details += " · " + why
+	}
+	if backup.Note != "" {
+		details += ": " + backup.Note
+	}
+	if backup.Unsaved {
+		details += " (with unsaved changes)"
+	}
+	return details
 }
 
 // formatBackupTimestamp formats the timestamp for display
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// maxPreviewBytes is the biggest image decoded for a preview
+const maxPreviewBytes = 20 << 20
+
+// previewRows is how many rows an image preview takes at most
+const previewRows = 12
+
+// renderBinaryPreview describes a changed binary file in place of its
+// diff, which would be garbage, with a small picture of it if it's an image
+func renderBinaryPreview(path string, width int) string {
+	info := git.GetBinaryInfo(path)
+	var lines []string
+
+	lines = append(lines, MutedStyle.Render("Binary file, not shown as text"))
+	switch {
+	case info.Size < 0:
+		lines = append(lines, "Deleted "+MutedStyle.Render("(was "+formatSize(info.OldSize)+")"))
+	case info.OldSize < 0:
+		lines = append(lines, "Size: "+formatSize(info.Size)+" "+MutedStyle.Render("(new)"))
+	default:
+		lines = append(lines, "Size: "+formatSize(info.OldSize)+" → "+formatSize(info.Size))
+	}
+
+	if info.Format == "" {
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, fmt.Sprintf("Image: %d × %d %s", info.Width, info.Height, info.Format))
+
+	if info.Size <= maxPreviewBytes {
+		if f, err := os.Open(path); err == nil {
+			img, _, err := image.Decode(f)
+			f.Close()
+			if err == nil {
+				lines = append(lines, "")
+				lines = append(lines, renderImageBlocks(img, width, previewRows)...)
+			}
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, MutedStyle.Render("Full size, with smooth web running:"))
+	lines = append(lines, HighlightStyle.Render(fmt.Sprintf("http://localhost:%d/api/file?path=%s", config.WebPort, url.QueryEscape(path))))
+	return strings.Join(lines, "\n")
+}
+
+// renderImageBlocks draws an image with half-block characters, each one
+// showing a pixel on top and another below, in at most width columns and
+// rows rows. Small images aren't scaled up.
+func renderImageBlocks(img image.Image, width, rows int) []string {
+	bounds := img.Bounds()
+	scale := float64(bounds.Dx()) / float64(width)
+	if tall := float64(bounds.Dy()) / float64(rows*2); tall > scale {
+		scale = tall
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	cols := int(float64(bounds.Dx()) / scale)
+	height := int(float64(bounds.Dy()) / scale)
+
+	pixel := func(x, y int) lipgloss.Color {
+		if y >= height {
+			return lipgloss.Color("")
+		}
+		return terminalColor(img.At(bounds.Min.X+int(float64(x)*scale), bounds.Min.Y+int(float64(y)*scale)))
+	}
+
+	var lines []string
+	for y := 0; y < height; y += 2 {
+		var line strings.Builder
+		for x := 0; x < cols; x++ {
+			style := lipgloss.NewStyle().Foreground(pixel(x, y)).Background(pixel(x, y+1))
+			line.WriteString(style.Render("▀"))
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// terminalColor converts an image color to a terminal color
+func terminalColor(c color.Color) lipgloss.Color {
+	r, g, b, _ := c.RGBA()
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8))
+}
+
+// formatSize formats a number of bytes for people, like 12.3 KB
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
@@ -0,0 +1,439 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+	"smooth/platform"
+)
+
+// BlameState represents the state of the "who changed this" flow
+type BlameState int
+
+const (
+	BlameStatePickFile BlameState = iota
+	BlameStateLines
+	BlameStateSave
+	BlameStateConfirm
+	BlameStateSuccess
+	BlameStateError
+)
+
+// BlameModel shows which save last changed each line of a file
+type BlameModel struct {
+	files       []string // every tracked file
+	matches     []string // files matching the filter
+	fileCursor  int
+	filter      textinput.Model
+	path        string
+	lines       []git.BlameLine
+	cursor      int // line under the cursor
+	offset      int // first line shown
+	saveFiles   git.CommitDiffSummary
+	wantRestore bool
+	state       BlameState
+	err         error
+	width       int
+	height      int
+}
+
+// NewBlameModel creates a new blame model
+func NewBlameModel() BlameModel {
+	ti := textinput.New()
+	ti.Placeholder = "type to filter files"
+	ti.CharLimit = 200
+	ti.Width = 40
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+	ti.Focus()
+
+	m := BlameModel{filter: ti, state: BlameStatePickFile}
+	m.files, m.err = git.ListFiles()
+	if m.err != nil {
+		m.state = BlameStateError
+	}
+	m.matches = m.files
+	return m
+}
+
+// Init initializes the model
+func (m BlameModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// BlameFileRestoreMsg is sent when restoring a file from a save completes
+type BlameFileRestoreMsg struct {
+	Err error
+}
+
+// doRestoreFile puts a file back the way it was in a save
+func doRestoreFile(hash, path string) tea.Cmd {
+	return func() tea.Msg {
+		return BlameFileRestoreMsg{Err: git.RestoreFileFrom(hash, path)}
+	}
+}
+
+// applyFilter narrows the file list to paths containing the filter text
+func (m *BlameModel) applyFilter() {
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if query == "" {
+		m.matches = m.files
+	} else {
+		m.matches = nil
+		for _, f := range m.files {
+			if strings.Contains(strings.ToLower(f), query) {
+				m.matches = append(m.matches, f)
+			}
+		}
+	}
+	m.fileCursor = 0
+}
+
+// openFile loads the blame for a file
+func (m *BlameModel) openFile(path string) {
+	lines, err := git.Blame(path)
+	if err != nil {
+		m.state = BlameStateError
+		m.err = err
+		return
+	}
+	m.path = path
+	m.lines = lines
+	m.cursor = 0
+	m.offset = 0
+	m.state = BlameStateLines
+}
+
+// visibleLines is how many lines of the file fit on screen
+func (m BlameModel) visibleLines() int {
+	if m.height > 0 {
+		return max(5, m.height-14)
+	}
+	return 20
+}
+
+// moveCursor moves the line cursor by delta, scrolling to keep it visible
+func (m *BlameModel) moveCursor(delta int) {
+	m.cursor = max(0, min(len(m.lines)-1, m.cursor+delta))
+	visible := m.visibleLines()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// current returns the line under the cursor
+func (m BlameModel) current() git.BlameLine {
+	return m.lines[m.cursor]
+}
+
+// Update handles messages
+func (m BlameModel) Update(msg tea.Msg) (BlameModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case BlameFileRestoreMsg:
+		if msg.Err != nil {
+			m.state = BlameStateError
+			m.err = msg.Err
+		} else {
+			m.state = BlameStateSuccess
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case BlameStatePickFile:
+			switch msg.String() {
+			case "up":
+				if m.fileCursor > 0 {
+					m.fileCursor--
+				}
+			case "down":
+				if m.fileCursor < len(m.matches)-1 {
+					m.fileCursor++
+				}
+			case "enter":
+				if len(m.matches) > 0 {
+					m.openFile(m.matches[m.fileCursor])
+				}
+			default:
+				var cmd tea.Cmd
+				m.filter, cmd = m.filter.Update(msg)
+				m.applyFilter()
+				return m, cmd
+			}
+
+		case BlameStateLines:
+			switch {
+			case key.Matches(msg, keys.Up):
+				m.moveCursor(-1)
+			case key.Matches(msg, keys.Down):
+				m.moveCursor(1)
+			case msg.String() == "pgup":
+				m.moveCursor(-m.visibleLines())
+			case msg.String() == "pgdown":
+				m.moveCursor(m.visibleLines())
+			case msg.String() == "n" && len(m.lines) > 0:
+				// Jump to the next block of lines from a different save
+				hash := m.current().Hash
+				for i := m.cursor + 1; i < len(m.lines); i++ {
+					if m.lines[i].Hash != hash {
+						m.moveCursor(i - m.cursor)
+						break
+					}
+				}
+			case key.Matches(msg, keys.Enter):
+				if len(m.lines) > 0 && !m.current().Unsaved() {
+					line := m.current()
+					m.saveFiles, _ = git.GetDiffStatBetweenCommits(line.Hash+"^", line.Hash)
+					m.state = BlameStateSave
+				}
+			case msg.String() == "r":
+				if len(m.lines) > 0 && !m.current().Unsaved() {
+					m.state = BlameStateConfirm
+				}
+			case msg.String() == "esc":
+				m.state = BlameStatePickFile
+				m.filter.Focus()
+				return m, textinput.Blink
+			}
+
+		case BlameStateSave:
+			switch msg.String() {
+			case "r":
+				m.state = BlameStateConfirm
+			case "t":
+				m.wantRestore = true
+			case "esc":
+				m.state = BlameStateLines
+			}
+
+		case BlameStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				return m, doRestoreFile(m.current().Hash, m.path)
+			case "n", "N", "esc":
+				m.state = BlameStateLines
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the blame screen
+func (m BlameModel) View() string {
+	var s string
+
+	s += RenderTitle("Who Changed This?") + "\n\n"
+
+	switch m.state {
+	case BlameStatePickFile:
+		s += RenderSubtitle("Pick a file:") + "\n\n"
+		s += m.filter.View() + "\n\n"
+
+		if len(m.matches) == 0 {
+			s += RenderMuted("No matching files.") + "\n\n"
+		}
+		maxVisible := 10
+		if m.height > 0 {
+			maxVisible = max(3, m.height-16)
+		}
+		start := 0
+		if m.fileCursor >= maxVisible {
+			start = m.fileCursor - maxVisible + 1
+		}
+		for i := start; i < len(m.matches) && i < start+maxVisible; i++ {
+			cursor := "  "
+			style := ListItemStyle
+			if i == m.fileCursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			s += cursor + style.Render(m.matches[i]) + "\n"
+		}
+		if len(m.matches) > maxVisible {
+			s += MutedStyle.Render(fmt.Sprintf("  ... %d files", len(m.matches))) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+
+	case BlameStateLines:
+		s += HighlightStyle.Render(m.path) + "\n\n"
+		s += m.renderLines() + "\n"
+		if len(m.lines) > 0 {
+			line := m.current()
+			if line.Unsaved() {
+				s += RenderMuted("Line "+fmt.Sprint(line.Number)+" has changed since your last save.") + "\n\n"
+			} else {
+				s += RenderMuted(fmt.Sprintf("Line %d last changed by ", line.Number)) +
+					HighlightStyle.Render(line.ShortHash()) + " " + line.Summary + "\n\n"
+			}
+		}
+		s += HelpBar(m.Keys())
+
+	case BlameStateSave:
+		line := m.current()
+		s += HighlightStyle.Render(line.ShortHash()) + " " + line.Summary + "\n"
+		s += RenderMuted(fmt.Sprintf("by %s, %s (%s)", line.Author, timeAgo(line.Time), line.Time.Format("Mon Jan 2 2006 15:04"))) + "\n\n"
+		if len(m.saveFiles.Files) > 0 {
+			s += RenderSubtitle("Files changed in this save:") + "\n"
+			for i, f := range m.saveFiles.Files {
+				if i == 10 {
+					s += MutedStyle.Render(fmt.Sprintf("  ... and %d more", len(m.saveFiles.Files)-10)) + "\n"
+					break
+				}
+				s += fmt.Sprintf("  %s %s %s\n", f.DisplayPath(),
+					SuccessStyle.Render(fmt.Sprintf("+%d", f.Additions)), ErrorStyle.Render(fmt.Sprintf("-%d", f.Deletions)))
+			}
+			s += "\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case BlameStateConfirm:
+		line := m.current()
+		s += RenderHighlight(fmt.Sprintf("Put %s back the way it was in \"%s\"?", m.path, line.Summary)) + "\n\n"
+		s += RenderMuted("Only this file changes. Your current version is snapshotted first,") + "\n"
+		s += RenderMuted("and the change isn't saved until you save it.") + "\n\n"
+		s += RenderSubtitle("Restore? (y/n)") + "\n"
+
+	case BlameStateSuccess:
+		s += RenderSuccess("✓ Restored "+m.path) + "\n\n"
+		s += RenderMuted("Save when you're happy with it, or find it in Snapshots to undo.") + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case BlameStateError:
+		s += RenderError("✗ Couldn't show who changed this") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderLines renders the visible lines, with the save and age shown at the
+// start of each block of lines from the same save
+func (m BlameModel) renderLines() string {
+	if len(m.lines) == 0 {
+		return RenderMuted("(empty file)") + "\n"
+	}
+
+	textWidth := 60
+	if m.width > 0 {
+		textWidth = max(20, m.width-40)
+	}
+
+	var s string
+	end := min(len(m.lines), m.offset+m.visibleLines())
+	for i := m.offset; i < end; i++ {
+		line := m.lines[i]
+
+		who := ""
+		if i == m.offset || m.lines[i-1].Hash != line.Hash {
+			if line.Unsaved() {
+				who = "unsaved"
+			} else {
+				who = line.ShortHash() + " " + timeAgo(line.Time)
+			}
+		}
+		who = padRight(truncateLine(who, 22), 22)
+
+		text, _ := platform.TrimCR(line.Text)
+		text = strings.ReplaceAll(text, "\t", "    ")
+		number := fmt.Sprintf("%4d │ ", line.Number)
+		if i == m.cursor {
+			s += MenuCursorStyle.Render("> ") + HighlightStyle.Render(who) + MutedStyle.Render(number) +
+				ListItemSelectedStyle.Render(truncateLine(text, textWidth)) + "\n"
+		} else {
+			s += "  " + MutedStyle.Render(who+number) + truncateLine(text, textWidth) + "\n"
+		}
+	}
+	return s
+}
+
+// timeAgo describes how long ago t was, like git's relative dates
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute") + " ago"
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour") + " ago"
+	case d < 30*24*time.Hour:
+		return plural(int(d.Hours()/24), "day") + " ago"
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/24/30), "month") + " ago"
+	}
+	return plural(int(d.Hours()/24/365), "year") + " ago"
+}
+
+// plural formats a count with its unit, adding an s when needed
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// WantsRestore returns true if the user chose to revert everything to the
+// save that changed the line
+func (m BlameModel) WantsRestore() bool {
+	return m.wantRestore
+}
+
+// SelectedCommit returns the save that changed the line under the cursor
+func (m BlameModel) SelectedCommit() git.CommitInfo {
+	line := m.current()
+	return git.CommitInfo{
+		Hash:      line.ShortHash(),
+		Message:   line.Summary,
+		Timestamp: timeAgo(line.Time),
+		FullHash:  line.Hash,
+	}
+}
+
+// Keys returns the keys the blame flow takes in its current state
+func (m BlameModel) Keys() Keys {
+	switch m.state {
+	case BlameStatePickFile:
+		return Keys{
+			Bar:    []key.Binding{hint("↑↓", "navigate"), hint("enter", "open"), hint("esc", "back")},
+			More:   []key.Binding{hint("type", "filter the files")},
+			Typing: true,
+		}
+	case BlameStateLines:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("n", "next change"), hint("enter", "show save"), hint("r", "restore file from it"), hint("esc", "files")},
+			More: []key.Binding{vimKeys, hint("pgup/pgdown", "page up/down")},
+		}
+	case BlameStateSave:
+		return Keys{Bar: []key.Binding{hint("r", "restore "+m.path+" from this save"), hint("t", "revert everything to it"), hint("esc", "back")}}
+	case BlameStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "restore the file"), hint("n", "no, back to the lines")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true while esc should step back within the screen
+// rather than leave it
+func (m BlameModel) HandlesEsc() bool {
+	return m.state == BlameStateLines || m.state == BlameStateSave || m.state == BlameStateConfirm
+}
+
+// IsDone returns true once a file has been restored or something failed
+func (m BlameModel) IsDone() bool {
+	return m.state == BlameStateSuccess || m.state == BlameStateError
+}
@@ -29,6 +29,7 @@ type BranchModel struct {
 	done          bool
 	choice        BranchChoice
 	switchError   string
+	showKeys      bool
 }
 
 // NewBranchModel creates a new branch model
@@ -58,8 +59,14 @@ func (m BranchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.done {
 			return m, tea.Quit
 		}
+		if m.showKeys {
+			m.showKeys = false
+			return m, nil
+		}
 
 		switch {
+		case msg.String() == "?":
+			m.showKeys = true
 		case key.Matches(msg, keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
@@ -96,8 +103,16 @@ func (m BranchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// View renders the prompt
+// View renders the prompt, as plain text in accessible mode
 func (m BranchModel) View() string {
+	if accessibleMode {
+		return Plain(m.render())
+	}
+	return StripEmoji(m.render())
+}
+
+// render draws the prompt
+func (m BranchModel) render() string {
 	var content string
 
 	// Show a smaller banner for this screen
@@ -130,9 +145,7 @@ func (m BranchModel) View() string {
 	}
 
 	// Main prompt
-	warningBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ColorDanger).
+	warningBox := PanelStyle(ColorDanger).
 		Padding(1, 2).
 		Render(ErrorStyle.Render("⚠ Wrong branch: ") + HighlightStyle.Render(m.currentBranch))
 
@@ -178,12 +191,12 @@ to stay on this branch.`, m.mainBranch, m.mainBranch)
 		}
 	}
 
+	if m.showKeys {
+		return lipgloss.NewStyle().Padding(2, 4).Render(HelpOverlay(m.Keys()))
+	}
+
 	// Help bar
-	helpBar := HelpBar([][]string{
-		{"↑↓", "navigate"},
-		{"enter", "select"},
-		{"q", "quit"},
-	})
+	helpBar := HelpBar(m.Keys())
 
 	// Center help bar
 	centeredHelp := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, helpBar)
@@ -204,6 +217,14 @@ to stay on this branch.`, m.mainBranch, m.mainBranch)
 	return lipgloss.JoinVertical(lipgloss.Left, placedContent, centeredHelp)
 }
 
+// Keys returns the keys the prompt takes
+func (m BranchModel) Keys() Keys {
+	return Keys{
+		Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("q", "quit")},
+		More: []key.Binding{vimKeys, hint("esc", "quit")},
+	}
+}
+
 // IsDone returns true if the user has made a choice
 func (m BranchModel) IsDone() bool {
 	return m.done
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/achievements"
+	"smooth/config"
+)
+
+// Celebrations: a mascot cheers on the save screen after every save, and
+// on any success screen when a milestone is reached. Which mascot it is,
+// if any, is a setting.
+
+// Cat ASCII art variations
+var catArts = []string{
+	`
+    /\_/\  
+   ( o.o ) 
+    > ^ <
+   /|   |\
+  (_|   |_)`,
+
+	`
+   /\_____/\
+  /  o   o  \
+ ( ==  ^  == )
+  )         (
+ (           )
+( (  )   (  ) )
+(__(__)___(__)__)`,
+
+	`
+      /\___/\
+     (  o o  )
+     (  =^=  ) 
+      (---)
+     /|   |\
+    (_|   |_)`,
+
+	`
+  ╱|、
+(˚ˎ 。7  
+ |、˜〵          
+ じしˍ,)ノ`,
+
+	`
+   ∧,,,∧
+ ( ̳• · • ̳)
+ /    づ♡`,
+
+	`
+    /\     /\
+   {  '---'  }
+   {  O   O  }
+   ~~>  V  <~~
+      \  \|
+       '---'\
+       /     \   
+      /       '--'
+     {        }
+      \      /
+       '.__.'`,
+}
+
+// Encouraging messages the cat can say after a save
+var catMessages = []string{
+	"Great job! Your code is saved! ✨",
+	"Purrfect commit! You're doing amazing! 🌟",
+	"Meow! Another save in the bag! 🎉",
+	"You're on a roll! Keep vibing! 💫",
+	"Nice work, hooman! *purrs* 😸",
+	"Commit complete! You're crushing it! 🚀",
+	"Saved! Time for a treat break? 🍪",
+	"Your code is safe with me! *nuzzles* 💕",
+	"Another one! You're unstoppable! ⚡",
+	"Meowvelous work! Keep it up! 🌈",
+	"*happy cat noises* Great save! 😻",
+	"You did it! I believe in you! 💪",
+	"Pawsitively amazing commit! 🐾",
+	"Your code sparks joy! ✨",
+	"Fantastic! You're a coding wizard! 🧙",
+}
+
+// Dog ASCII art variations
+var dogArts = []string{
+	`
+   / \__
+  (    @\___
+  /         O
+ /   (_____/
+/_____/   U`,
+
+	`
+    __      _
+  o'')}____//
+   '_/      )
+   (_(_/-(_/`,
+
+	`
+   ^..^      /
+   /_/\_____/
+      /\   /\
+     /  \ /  \`,
+}
+
+// Encouraging messages the dog can say after a save
+var dogMessages = []string{
+	"Woof! Your code is saved! 🐶",
+	"Good save! Who's a good coder? You are! 🦴",
+	"*wags tail* Another save fetched! 🎾",
+	"Pawsome work! Keep it up! 🐾",
+	"Saved! Time for a walk? 🌳",
+	"I'll guard this save with my life! 🐕",
+	"You're the best human ever! 💕",
+	"Bark bark! That means great job! 🎉",
+}
+
+// Robot ASCII art variations
+var robotArts = []string{
+	`
+   [o_o]
+  /|___|\
+   |   |
+   d   b`,
+
+	`
+    _____
+   | o o |
+   |  ‿  |
+   |_____|
+  __|_|__
+ |_______|`,
+
+	`
+  \_[=_=]_/
+     | |
+    /   \`,
+}
+
+// Encouraging messages the robot can say after a save
+var robotMessages = []string{
+	"BEEP BOOP. SAVE COMPLETE. EXCELLENT WORK, HUMAN. 🤖",
+	"Code stored successfully. Happiness levels: rising. 📈",
+	"Save acknowledged. You are operating at peak efficiency. ⚡",
+	"Processing... conclusion: you are doing great. ✅",
+	"Backup routines nominal. Your work is safe. 🔒",
+	"Another save logged. Productivity: maximum. 🚀",
+	"*whirrs happily* Nice commit! 💾",
+}
+
+// mascot is one of the mascots that can cheer, with what it says
+type mascot struct {
+	arts       []string
+	messages   []string
+	milestones map[string]string // said instead when the achievement with this ID unlocks
+}
+
+// mascots are the mascots by their config name
+var mascots = map[string]mascot{
+	config.MascotCat: {
+		arts:     catArts,
+		messages: catMessages,
+		milestones: map[string]string{
+			"ten-saves":             "Ten saves! You're a natural, hooman! 😻",
+			"first-sync":            "Your code is up in the cloud now! *purrs from above* ☁️",
+			"first-experiment-kept": "Your first experiment worked out! Meowvelous science! 🧪",
+		},
+	},
+	config.MascotDog: {
+		arts:     dogArts,
+		messages: dogMessages,
+		milestones: map[string]string{
+			"ten-saves":             "Ten saves! That's a lot of sticks fetched! 🦴",
+			"first-sync":            "Your code's on GitHub! I'd chase it up there if I could! ☁️",
+			"first-experiment-kept": "Your first experiment is a keeper! Good human! 🧪",
+		},
+	},
+	config.MascotRobot: {
+		arts:     robotArts,
+		messages: robotMessages,
+		milestones: map[string]string{
+			"ten-saves":             "MILESTONE DETECTED: 10 SAVES. YOU ARE LEARNING FAST. 🤖",
+			"first-sync":            "First upload to GitHub complete. Redundancy achieved. ☁️",
+			"first-experiment-kept": "Experiment merged. Hypothesis confirmed: you are brilliant. 🧪",
+		},
+	},
+}
+
+// cheer is what the mascot says on a success screen. It's picked once, so
+// it doesn't change every time the screen redraws.
+type cheer struct {
+	art     string
+	message string
+}
+
+// newCheer picks the configured mascot's art and message. If a milestone
+// is among the unlocked achievements the mascot says so; otherwise it only
+// cheers when always is set, which it is after a save.
+func newCheer(unlocked []achievements.Achievement, always bool) cheer {
+	cfg, _ := config.Load()
+	m, ok := mascots[cfg.Mascot]
+	if !ok {
+		return cheer{}
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	c := cheer{art: m.arts[rng.Intn(len(m.arts))]}
+	for _, a := range unlocked {
+		if message, ok := m.milestones[a.ID]; ok {
+			c.message = message
+			return c
+		}
+	}
+	if !always {
+		return cheer{}
+	}
+	c.message = m.messages[rng.Intn(len(m.messages))]
+	return c
+}
+
+// View renders the mascot with its message, or "" if it has nothing to say
+func (c cheer) View() string {
+	if c.message == "" {
+		return ""
+	}
+
+	// Style the mascot with the accent color
+	artStyle := lipgloss.NewStyle().
+		Foreground(ColorAccent).
+		Bold(true)
+
+	// Style the speech bubble
+	bubbleStyle := lipgloss.NewStyle().
+		Foreground(ColorSuccess).
+		Italic(true).
+		PaddingLeft(2)
+
+	// A screen reader would spell out the art, and with reduced motion it
+	// doesn't pop up
+	if accessibleMode || reducedMotion() {
+		return bubbleStyle.Render(c.message)
+	}
+
+	return artStyle.Render(c.art) + "\n\n" + bubbleStyle.Render("💬 "+c.message)
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// The changes panel can list thousands of files, like after
+// gen-test-data, so it only ever renders the files in view and loads a
+// file's diff in the background when it's opened, keeping the most recent
+// few in memory.
+
+// maxCachedDiffs is how many opened files' diffs are kept
+const maxCachedDiffs = 100
+
+// fileDiffMsg is sent when a changed file's diff has loaded
+type fileDiffMsg struct {
+	path   string
+	diff   string
+	binary bool
+}
+
+// loadFileDiff loads a changed file's diff, or a description of it if it's
+// binary, with a picture of images
+func loadFileDiff(path string, binary bool, width int) tea.Cmd {
+	return func() tea.Msg {
+		if binary || git.IsBinaryFile(path) {
+			return fileDiffMsg{path: path, diff: renderBinaryPreview(path, width), binary: true}
+		}
+		return fileDiffMsg{path: path, diff: git.GetFileDiff(path)}
+	}
+}
+
+// cacheFileDiff keeps a diff that loaded, if its file is still open, and
+// forgets the oldest closed ones past maxCachedDiffs
+func (m *MenuModel) cacheFileDiff(msg fileDiffMsg) {
+	if !m.loadingDiffs[msg.path] {
+		// The changes were refreshed while it loaded
+		return
+	}
+	delete(m.loadingDiffs, msg.path)
+	m.fileDiffs[msg.path] = msg.diff
+	m.binaryFiles[msg.path] = msg.binary
+	m.diffOrder = append(m.diffOrder, msg.path)
+
+	for i := 0; len(m.fileDiffs) > maxCachedDiffs && i < len(m.diffOrder); {
+		path := m.diffOrder[i]
+		if m.expandedFiles[path] {
+			i++
+			continue
+		}
+		delete(m.fileDiffs, path)
+		delete(m.binaryFiles, path)
+		delete(m.diffScrollOffset, path)
+		m.diffOrder = append(m.diffOrder[:i], m.diffOrder[i+1:]...)
+	}
+}
+
+// maxVisibleFiles is how many changed files fit in the changes panel
+func (m MenuModel) maxVisibleFiles() int {
+	_, _, panelHeight := m.panelSizes()
+	return max(3, panelHeight-14)
+}
+
+// keepFileVisible scrolls the changes panel only as far as it takes to
+// show the selected file, so moving within the panel doesn't shift it
+func (m *MenuModel) keepFileVisible() {
+	visible := m.maxVisibleFiles()
+	if m.fileCursor < m.fileOffset {
+		m.fileOffset = m.fileCursor
+	}
+	if m.fileCursor >= m.fileOffset+visible {
+		m.fileOffset = m.fileCursor - visible + 1
+	}
+	m.fileOffset = max(0, min(m.fileOffset, len(m.changedFiles)-visible))
+}
+
+// diffExcerpt returns the part of an open file's diff that's in view
+func (m MenuModel) diffExcerpt(path string) string {
+	lines := strings.Split(strings.TrimLeft(m.fileDiffs[path], "\n"), "\n")
+	start := min(m.diffScrollOffset[path], len(lines))
+	end := min(start+m.getMaxDiffLines(), len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/platform"
+)
+
+// checksPreviewLines is how much of the checks' output shows before the
+// panel is expanded. The end is shown, since that's where errors usually are.
+const checksPreviewLines = 6
+
+// updateChecks handles keys on the failed checks screen
+func (m SaveModel) updateChecks(msg tea.KeyMsg) (SaveModel, tea.Cmd) {
+	switch msg.String() {
+	case "o", "tab":
+		m.checksOpen = !m.checksOpen
+	case "r", "enter":
+		// Fix and retry: the user fixed things (or a formatter did), so
+		// run everything again
+		m.checks = nil
+		return m.executeSave()
+	case "a":
+		m.checks = nil
+		m.skipChecks = true
+		return m.executeSave()
+	case "c", "esc":
+		m.checks = nil
+		m.state = SaveStateReview
+	}
+	return m, nil
+}
+
+// renderChecks renders the output of the failed checks and the choices
+func (m SaveModel) renderChecks() string {
+	var s string
+	s += RenderTitle("Checks Failed") + "\n\n"
+	s += RenderError("✗ "+m.checks.Error()) + "\n\n"
+	s += RenderMuted("Your project checks every save, and this one didn't pass.") + "\n"
+	s += RenderMuted("Nothing has been saved yet.") + "\n\n"
+
+	lines := strings.Split(strings.TrimRight(m.checks.Output, "\n"), "\n")
+	for i := range lines {
+		lines[i], _ = platform.TrimCR(lines[i])
+	}
+	maxLines := len(lines)
+	if !m.checksOpen {
+		maxLines = checksPreviewLines
+	} else if m.height > 0 {
+		maxLines = max(checksPreviewLines, m.height-18)
+	}
+	width := 80
+	if m.width > 0 {
+		width = max(30, m.width-12)
+	}
+
+	toggle := "▸ Output"
+	if m.checksOpen {
+		toggle = "▾ Output"
+	}
+	s += RenderSubtitle(toggle) + "\n"
+	if hidden := len(lines) - maxLines; hidden > 0 {
+		s += MutedStyle.Render(fmt.Sprintf("  ... %d earlier lines", hidden)) + "\n"
+		lines = lines[hidden:]
+	}
+	for _, line := range lines {
+		s += "  " + MutedStyle.Render(truncateLine(strings.ReplaceAll(line, "\t", "    "), width)) + "\n"
+	}
+	s += "\n"
+
+	s += HelpBar(m.checksKeys())
+	return s
+}
+
+// checksKeys returns the keys for the failed checks
+func (m SaveModel) checksKeys() Keys {
+	toggleHelp := "show all output"
+	if m.checksOpen {
+		toggleHelp = "show less"
+	}
+	return Keys{
+		Bar:  []key.Binding{hint("r", "fix and retry"), hint("a", "save anyway"), hint("c", "cancel"), hint("o", toggleHelp)},
+		More: []key.Binding{hint("enter", "retry"), hint("tab", toggleHelp), hint("esc", "cancel")},
+	}
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// Checks take a moment to start after an upload, and are then asked about
+// until they finish or have been watched for ciWatchLimit
+const (
+	ciFirstCheck   = 5 * time.Second
+	ciPollInterval = 15 * time.Second
+	ciGrace        = 45 * time.Second // how long checks get to show up before they're taken to be none
+	ciWatchLimit   = 20 * time.Minute
+)
+
+// CIStatusMsg is sent when GitHub says how the checks, like GitHub
+// Actions, on an uploaded save are going
+type CIStatusMsg struct {
+	Status  git.CIStatus
+	Err     error
+	Commit  string
+	Branch  string
+	Started time.Time // when the upload finished, to tell uploads apart
+}
+
+// watchCI starts watching the checks on the save just uploaded. Projects
+// that aren't on GitHub get an error back, and aren't asked about again.
+func watchCI() tea.Cmd {
+	started := time.Now()
+	return tea.Tick(ciFirstCheck, func(time.Time) tea.Msg {
+		commit, err := git.Run("rev-parse", "HEAD")
+		if err != nil {
+			return CIStatusMsg{Err: err, Started: started}
+		}
+		branch, _ := git.CurrentBranch()
+		return checkCI(commit, branch, started)
+	})
+}
+
+// checkCI asks GitHub about the checks on commit
+func checkCI(commit, branch string, started time.Time) CIStatusMsg {
+	status, err := git.CheckCI(commit)
+	return CIStatusMsg{Status: status, Err: err, Commit: commit, Branch: branch, Started: started}
+}
+
+// next returns the command to ask again, or nil once the checks are done,
+// none showed up or they've been watched long enough
+func (msg CIStatusMsg) next() tea.Cmd {
+	if msg.Err != nil || time.Since(msg.Started) > ciWatchLimit {
+		return nil
+	}
+	switch msg.Status.State {
+	case git.CIPending:
+	case git.CINone:
+		if time.Since(msg.Started) > ciGrace {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return tea.Tick(ciPollInterval, func(time.Time) tea.Msg {
+		return checkCI(msg.Commit, msg.Branch, msg.Started)
+	})
+}
+
+// shown returns true if there's something to say about the checks
+func (msg CIStatusMsg) shown() bool {
+	return msg.Err == nil && msg.Status.Total > 0
+}
+
+// ciBadge sums up the checks in a few words for the status bar, like
+// "✓ checks passed"
+func ciBadge(status git.CIStatus) string {
+	switch status.State {
+	case git.CIPassed:
+		return SuccessStyle.Render("✓ checks passed")
+	case git.CIFailed:
+		return ErrorStyle.Render("✗ checks failed")
+	case git.CIPending:
+		return MutedStyle.Render("⏳ checks running")
+	}
+	return ""
+}
+
+// renderCI describes how the checks on the uploaded save went, for the
+// sync screen
+func renderCI(status git.CIStatus) string {
+	var s string
+	switch status.State {
+	case git.CIPassed:
+		s += RenderSuccess(fmt.Sprintf("✓ GitHub's checks passed (%d)", status.Total)) + "\n"
+	case git.CIFailed:
+		s += RenderError(fmt.Sprintf("✗ %s of %d failed: %s", plural(len(status.Failed), "check"), status.Total,
+			strings.Join(status.Failed, ", "))) + "\n"
+	case git.CIPending:
+		s += RenderMuted(fmt.Sprintf("⏳ GitHub is running %s on this upload...", plural(status.Total, "check"))) + "\n"
+	default:
+		return ""
+	}
+	return s + MutedStyle.Render(status.URL) + "\n"
+}
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// CleanupState represents the state of the clean up flow
+type CleanupState int
+
+const (
+	CleanupStateList CleanupState = iota
+	CleanupStateConfirm
+	CleanupStateDeleting
+	CleanupStateSuccess
+	CleanupStateError
+)
+
+// cleanupAges are the "older than" choices, in days
+var cleanupAges = []int{7, 14, 30, 60, 90}
+
+// CleanupModel is the model for the branch clean up screen
+type CleanupModel struct {
+	candidates []git.CleanupCandidate
+	selected   map[string]bool
+	ageIndex   int // index into cleanupAges
+	cursor     int
+	state      CleanupState
+	unique     int // saves only the selected branches have
+	deleted    int
+	err        error
+	width      int
+	height     int
+}
+
+// NewCleanupModel creates a new clean up model
+func NewCleanupModel() CleanupModel {
+	m := CleanupModel{ageIndex: 2, state: CleanupStateList}
+	m.load()
+	return m
+}
+
+// load finds the branches to offer for the current age and selects them all
+func (m *CleanupModel) load() {
+	days := cleanupAges[m.ageIndex]
+	m.candidates, m.err = git.FindCleanupCandidates(time.Duration(days) * 24 * time.Hour)
+	if m.err != nil {
+		m.state = CleanupStateError
+	}
+	m.selected = make(map[string]bool)
+	for _, c := range m.candidates {
+		m.selected[c.Name] = true
+	}
+	m.cursor = 0
+	m.countUnique()
+}
+
+// countUnique updates how many saves deleting the selection would lose
+func (m *CleanupModel) countUnique() {
+	m.unique = git.UniqueCommits(git.GetMainBranch(), m.selectedNames()...)
+}
+
+// Init initializes the model
+func (m CleanupModel) Init() tea.Cmd {
+	return nil
+}
+
+// CleanupMsg is sent when deleting branches completes
+type CleanupMsg struct {
+	Err error
+}
+
+// doCleanup deletes the branches
+func doCleanup(names []string) tea.Cmd {
+	return func() tea.Msg {
+		return CleanupMsg{Err: git.DeleteBranches(names)}
+	}
+}
+
+// selectedNames returns the selected branches in list order
+func (m CleanupModel) selectedNames() []string {
+	var names []string
+	for _, c := range m.candidates {
+		if m.selected[c.Name] {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// Update handles messages
+func (m CleanupModel) Update(msg tea.Msg) (CleanupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case CleanupMsg:
+		if msg.Err != nil {
+			m.state = CleanupStateError
+			m.err = msg.Err
+		} else {
+			m.state = CleanupStateSuccess
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case CleanupStateList:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(m.candidates)-1 {
+					m.cursor++
+				}
+			case msg.String() == "left":
+				if m.ageIndex > 0 {
+					m.ageIndex--
+					m.load()
+				}
+			case msg.String() == "right":
+				if m.ageIndex < len(cleanupAges)-1 {
+					m.ageIndex++
+					m.load()
+				}
+			case msg.String() == " ":
+				if len(m.candidates) > 0 {
+					name := m.candidates[m.cursor].Name
+					m.selected[name] = !m.selected[name]
+					m.countUnique()
+				}
+			case msg.String() == "a":
+				// Select all, or none if everything is already selected
+				all := len(m.selectedNames()) == len(m.candidates)
+				for _, c := range m.candidates {
+					m.selected[c.Name] = !all
+				}
+				m.countUnique()
+			case key.Matches(msg, keys.Enter):
+				if len(m.selectedNames()) > 0 {
+					m.state = CleanupStateConfirm
+				}
+			}
+
+		case CleanupStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				names := m.selectedNames()
+				m.deleted = len(names)
+				m.state = CleanupStateDeleting
+				return m, doCleanup(names)
+			case "n", "N", "esc":
+				m.state = CleanupStateList
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the clean up screen
+func (m CleanupModel) View() string {
+	var s string
+
+	s += RenderTitle("Clean Up") + "\n\n"
+
+	switch m.state {
+	case CleanupStateList:
+		days := cleanupAges[m.ageIndex]
+		s += RenderMuted("Old experiments and backups, and ones already in your main work.") + "\n"
+		s += fmt.Sprintf("%s %s\n\n", MutedStyle.Render("Older than:"), HighlightStyle.Render(fmt.Sprintf("← %d days →", days)))
+
+		if len(m.candidates) == 0 {
+			s += RenderSuccess("✓ Nothing to clean up!") + "\n\n"
+			s += HelpBar(m.Keys())
+			return BoxStyle.Render(s)
+		}
+
+		maxVisible := 10
+		if m.height > 0 {
+			maxVisible = max(3, (m.height-18)/2)
+		}
+		start := 0
+		if m.cursor >= maxVisible {
+			start = m.cursor - maxVisible + 1
+		}
+
+		for i := start; i < len(m.candidates) && i < start+maxVisible; i++ {
+			c := m.candidates[i]
+			cursor := "  "
+			style := ListItemStyle
+			if i == m.cursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			check := MutedStyle.Render("[ ]")
+			if m.selected[c.Name] {
+				check = SuccessStyle.Render("[✓]")
+			}
+			s += fmt.Sprintf("%s%s %s\n", cursor, check, style.Render(c.Name))
+
+			detail := fmt.Sprintf("%s · last saved %s", c.Kind, c.LastCommit.Format("Jan 2, 2006"))
+			if c.Merged {
+				detail += " · already in main"
+			} else {
+				detail += fmt.Sprintf(" · %d save(s) not in main", c.Unique)
+			}
+			s += "      " + MutedStyle.Render(detail) + "\n"
+		}
+		if len(m.candidates) > maxVisible {
+			s += MutedStyle.Render(fmt.Sprintf("  ... %d total", len(m.candidates))) + "\n"
+		}
+
+		s += "\n" + m.renderSummary() + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case CleanupStateConfirm:
+		s += RenderHighlight(m.renderSummary()) + "\n\n"
+		if m.unique > 0 {
+			s += RenderError(fmt.Sprintf("⚠ %d save(s) aren't anywhere else and will be gone for good.", m.unique)) + "\n\n"
+		} else {
+			s += RenderMuted("Everything on them is already in your main work.") + "\n\n"
+		}
+		s += RenderSubtitle("Delete them? (y/n)") + "\n"
+
+	case CleanupStateDeleting:
+		s += RenderHighlight("Deleting...") + "\n"
+
+	case CleanupStateSuccess:
+		s += RenderSuccess(fmt.Sprintf("✓ Deleted %d branch(es)", m.deleted)) + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case CleanupStateError:
+		s += RenderError("✗ Clean up failed") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderSummary describes what deleting the selection would remove
+func (m CleanupModel) renderSummary() string {
+	var experiments, backups int
+	for _, c := range m.candidates {
+		if !m.selected[c.Name] {
+			continue
+		}
+		if c.Kind == "backup" {
+			backups++
+		} else {
+			experiments++
+		}
+	}
+	return fmt.Sprintf("Will delete %d experiment(s) and %d backup(s), removing %d save(s) not in main",
+		experiments, backups, m.unique)
+}
+
+// Keys returns the keys the clean up screen takes in its current state
+func (m CleanupModel) Keys() Keys {
+	switch m.state {
+	case CleanupStateList:
+		if len(m.candidates) == 0 {
+			return Keys{Bar: []key.Binding{hint("←→", "change age"), hint("esc", "back")}}
+		}
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("space", "select"), hint("a", "all"), hint("←→", "change age"), hint("enter", "delete"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	case CleanupStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "delete them"), hint("n", "no, back to the list")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true if esc should cancel the confirmation rather than
+// leave the screen
+func (m CleanupModel) HandlesEsc() bool {
+	return m.state == CleanupStateConfirm
+}
+
+// IsDone returns true if the clean up has finished
+func (m CleanupModel) IsDone() bool {
+	return m.state == CleanupStateSuccess || m.state == CleanupStateError
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// CopiedMsg is sent when something has been copied to the clipboard, for
+// the toast saying so
+type CopiedMsg struct {
+	What string // what was copied, like "the hash"
+}
+
+// copyToClipboard copies text to the clipboard. Without a clipboard tool,
+// as over SSH, the terminal is asked to copy it instead.
+func copyToClipboard(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			termenv.Copy(text)
+		}
+		return CopiedMsg{What: what}
+	}
+}
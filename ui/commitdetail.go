@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// CommitDetailState represents the state of the save details screen
+type CommitDetailState int
+
+const (
+	CommitDetailStateLoading CommitDetailState = iota
+	CommitDetailStateView
+	CommitDetailStateName
+	CommitDetailStateError
+)
+
+// CommitDetailModel shows everything about one save, opened from the
+// Restore list or the Timeline, with ways to revert to it, start a branch
+// there or copy its hash
+type CommitDetailModel struct {
+	spinner     spinner.Model
+	commit      git.CommitInfo
+	details     git.CommitDetails
+	state       CommitDetailState
+	err         error
+	textInput   textinput.Model
+	notice      string // how the last action went
+	failed      bool   // whether the last action failed
+	wantRestore bool
+	width       int
+	height      int
+}
+
+// NewCommitDetailModel creates a details screen for commit. The details
+// load in Init.
+func NewCommitDetailModel(commit git.CommitInfo) CommitDetailModel {
+	ti := textinput.New()
+	ti.Placeholder = "my-branch"
+	ti.CharLimit = 50
+	ti.Width = 30
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	return CommitDetailModel{
+		spinner:   newSpinner(SpinnerDots),
+		commit:    commit,
+		textInput: ti,
+	}
+}
+
+// commitDetailLoadedMsg is sent when a save's details have loaded
+type commitDetailLoadedMsg struct {
+	details git.CommitDetails
+	err     error
+}
+
+// commitDetailBranchMsg is sent when a branch has been made at the save
+type commitDetailBranchMsg struct {
+	name string
+	err  error
+}
+
+// doLoadCommitDetails looks up everything about a save
+func doLoadCommitDetails(hash string) tea.Cmd {
+	return func() tea.Msg {
+		details, err := git.GetCommitDetails(hash)
+		return commitDetailLoadedMsg{details: details, err: err}
+	}
+}
+
+// doCreateBranchAt makes a branch at a save, staying where we are
+func doCreateBranchAt(name, hash string) tea.Cmd {
+	return func() tea.Msg {
+		return commitDetailBranchMsg{name: name, err: git.CreateBranchAt(name, hash)}
+	}
+}
+
+// Init starts loading the details
+func (m CommitDetailModel) Init() tea.Cmd {
+	return tea.Batch(spinnerTick(m.spinner), doLoadCommitDetails(m.commit.FullHash))
+}
+
+// Update handles messages for the details screen
+func (m CommitDetailModel) Update(msg tea.Msg) (CommitDetailModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case commitDetailLoadedMsg:
+		if msg.err != nil {
+			m.state = CommitDetailStateError
+			m.err = msg.err
+			return m, nil
+		}
+		m.details = msg.details
+		m.state = CommitDetailStateView
+		return m, nil
+
+	case commitDetailBranchMsg:
+		m.failed = msg.err != nil
+		if m.failed {
+			m.notice = "Couldn't create the branch: " + msg.err.Error()
+		} else {
+			m.notice = fmt.Sprintf("Created %s at this save", msg.name)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == CommitDetailStateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case CommitDetailStateView:
+			switch msg.String() {
+			case "r":
+				m.wantRestore = true
+			case "b":
+				m.notice = ""
+				m.state = CommitDetailStateName
+				m.textInput.Focus()
+				return m, textinput.Blink
+			case "c":
+				return m, copyToClipboard(m.commit.FullHash, "the hash")
+			}
+
+		case CommitDetailStateName:
+			switch msg.String() {
+			case "enter":
+				name := strings.TrimSpace(m.textInput.Value())
+				if name == "" {
+					return m, nil
+				}
+				m.textInput.Blur()
+				m.textInput.SetValue("")
+				m.state = CommitDetailStateView
+				return m, doCreateBranchAt(name, m.commit.FullHash)
+			case "esc":
+				m.textInput.Blur()
+				m.textInput.SetValue("")
+				m.state = CommitDetailStateView
+			default:
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the details screen
+func (m CommitDetailModel) View() string {
+	var s string
+
+	s += RenderTitle("Save details") + "\n\n"
+
+	switch m.state {
+	case CommitDetailStateLoading:
+		s += m.spinner.View() + " " + RenderHighlight("Loading "+m.commit.Hash+"...") + "\n"
+
+	case CommitDetailStateError:
+		s += RenderError("✗ Couldn't load this save") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpBar(m.Keys())
+
+	case CommitDetailStateView, CommitDetailStateName:
+		s += m.renderDetails()
+		if m.state == CommitDetailStateName {
+			s += RenderSubtitle("Name for the new branch:") + "\n\n"
+			s += m.textInput.View() + "\n\n"
+			s += HelpBar(m.Keys())
+			break
+		}
+		if m.notice != "" {
+			if m.failed {
+				s += RenderError("✗ "+m.notice) + "\n\n"
+			} else {
+				s += RenderSuccess("✓ "+m.notice) + "\n\n"
+			}
+		}
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderDetails renders the save's metadata, message and changed files
+func (m CommitDetailModel) renderDetails() string {
+	d := m.details
+	label := func(name string) string {
+		return MutedStyle.Render(fmt.Sprintf("%-9s", name))
+	}
+
+	var s string
+	s += HighlightStyle.Render(d.Hash) + " " + MutedStyle.Render(d.FullHash)
+	if d.Signed() {
+		s += " " + SignedBadge()
+	}
+	s += "\n\n"
+
+	s += label("Author") + d.Author
+	if d.AuthorEmail != "" {
+		s += " " + MutedStyle.Render("<"+d.AuthorEmail+">")
+	}
+	s += "\n"
+	s += label("Date") + d.Date.Format("Mon Jan 2 2006 15:04") + " " + MutedStyle.Render("("+d.Timestamp+")") + "\n"
+	s += label("Parents")
+	switch len(d.Parents) {
+	case 0:
+		s += MutedStyle.Render("none, this is the first save")
+	case 1:
+		s += d.Parents[0]
+	default:
+		s += strings.Join(d.Parents, ", ") + " " + MutedStyle.Render("(a merge)")
+	}
+	s += "\n\n"
+
+	// Keep room for the files on short terminals
+	maxLines, maxFiles := 10, 15
+	if m.height > 0 {
+		maxFiles = max(3, m.height-26)
+		maxLines = max(3, min(10, m.height-24))
+	}
+	// Long messages wrap inside the box rather than running off the side
+	wrapWidth := 72
+	if m.width > 0 {
+		wrapWidth = min(wrapWidth, m.width-10)
+	}
+	var lines []string
+	for _, line := range strings.Split(d.Body, "\n") {
+		lines = append(lines, wrapText(line, wrapWidth)...)
+	}
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], "...")
+	}
+	for _, line := range lines {
+		s += "  " + line + "\n"
+	}
+	s += "\n"
+
+	addStyle := lipgloss.NewStyle().Foreground(ColorSuccess)
+	delStyle := lipgloss.NewStyle().Foreground(ColorDanger)
+	s += RenderSubtitle(fmt.Sprintf("Files changed: %d ", len(d.Summary.Files))) +
+		addStyle.Render(fmt.Sprintf("+%d", d.Summary.TotalAdded)) + " " +
+		delStyle.Render(fmt.Sprintf("-%d", d.Summary.TotalDeleted)) + "\n"
+	for _, line := range renderFileStats(d.Summary, maxFiles) {
+		s += line + "\n"
+	}
+	return s + "\n"
+}
+
+// WantsRestore returns true if the user wants to revert to this save
+func (m CommitDetailModel) WantsRestore() bool {
+	return m.wantRestore
+}
+
+// Commit returns the save the details are for
+func (m CommitDetailModel) Commit() git.CommitInfo {
+	return m.commit
+}
+
+// Keys returns the keys the details screen takes in its current state
+func (m CommitDetailModel) Keys() Keys {
+	switch m.state {
+	case CommitDetailStateError:
+		return Keys{Bar: []key.Binding{hint("esc", "back")}}
+	case CommitDetailStateName:
+		return Keys{Bar: []key.Binding{hint("enter", "create"), hint("esc", "cancel")}, Typing: true}
+	case CommitDetailStateView:
+		return Keys{Bar: []key.Binding{hint("r", "revert to this"), hint("b", "create branch here"), hint("c", "copy hash"), hint("esc", "back")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true while typing a branch name, where esc cancels
+// the name instead of leaving the screen
+func (m CommitDetailModel) HandlesEsc() bool {
+	return m.state == CommitDetailStateName
+}
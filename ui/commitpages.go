@@ -0,0 +1,39 @@
+package ui
+
+import "smooth/git"
+
+// Save lists load logPageSize saves at a time, and the next page once the
+// cursor is within logPrefetch of the last one loaded, so the whole
+// history can be scrolled without loading it up front
+const (
+	logPageSize = 20
+	logPrefetch = 3
+)
+
+// firstCommitPage loads the newest saves, and whether there may be more
+func firstCommitPage() ([]git.CommitInfo, bool, error) {
+	commits, err := git.Log(0, logPageSize)
+	return commits, len(commits) == logPageSize, err
+}
+
+// loadMoreCommits adds the next page of saves once the cursor gets close to
+// the last one loaded, and reports whether there may be more still
+func loadMoreCommits(commits []git.CommitInfo, cursor int, more bool) ([]git.CommitInfo, bool) {
+	if !more || cursor < len(commits)-logPrefetch {
+		return commits, more
+	}
+	page, err := git.Log(len(commits), logPageSize)
+	if err != nil {
+		return commits, false
+	}
+	return append(commits, page...), len(page) == logPageSize
+}
+
+// savesLoadedNote says how many saves a list has, and if older ones load
+// on scrolling
+func savesLoadedNote(count int, more bool) string {
+	if more {
+		return plural(count, "save") + " so far, scroll for older ones"
+	}
+	return plural(count, "save")
+}
@@ -0,0 +1,368 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// CompareState represents the state of the compare screen
+type CompareState int
+
+const (
+	CompareStatePickFrom CompareState = iota
+	CompareStatePickTo
+	CompareStateLoading
+	CompareStateDiff
+	CompareStateEmpty
+	CompareStateError
+)
+
+// compareMaxSaves is how many recent saves can be picked from
+const compareMaxSaves = 50
+
+// CompareModel shows everything that differs between two saves, or between
+// a save and the files as they are now
+type CompareModel struct {
+	spinner    spinner.Model
+	state      CompareState
+	commits    []git.CommitInfo
+	fromCursor int // save picked first, in commits
+	toCursor   int // 0 is the files now, then commits from 1
+	comparison git.Comparison
+	lines      []string // the diff, split into lines
+	offset     int      // first diff line shown
+	err        error
+	width      int
+	height     int
+}
+
+// compareLoadedMsg is sent when the diff between the two picks is ready
+type compareLoadedMsg struct {
+	comparison git.Comparison
+	err        error
+}
+
+// NewCompareModel creates a model for the compare screen
+func NewCompareModel() CompareModel {
+	m := CompareModel{spinner: newSpinner(SpinnerDots)}
+	m.commits, m.err = git.Log(0, compareMaxSaves)
+	if m.err != nil {
+		m.state = CompareStateError
+	} else if len(m.commits) == 0 {
+		m.state = CompareStateEmpty
+	}
+	return m
+}
+
+// Init initializes the model
+func (m CompareModel) Init() tea.Cmd {
+	return nil
+}
+
+// doCompare diffs two saves, or a save and the files now if to is empty
+func doCompare(from, to string) tea.Cmd {
+	return func() tea.Msg {
+		comparison, err := git.Compare(from, to)
+		return compareLoadedMsg{comparison: comparison, err: err}
+	}
+}
+
+// compare starts diffing the two picks
+func (m CompareModel) compare() (CompareModel, tea.Cmd) {
+	from := m.commits[m.fromCursor].FullHash
+	to := ""
+	if m.toCursor > 0 {
+		to = m.commits[m.toCursor-1].FullHash
+	}
+	m.state = CompareStateLoading
+	return m, tea.Batch(spinnerTick(m.spinner), doCompare(from, to))
+}
+
+// Update handles messages
+func (m CompareModel) Update(msg tea.Msg) (CompareModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.scroll(0)
+
+	case compareLoadedMsg:
+		if msg.err != nil {
+			m.state = CompareStateError
+			m.err = msg.err
+			return m, nil
+		}
+		m.comparison = msg.comparison
+		m.lines = strings.Split(strings.TrimRight(msg.comparison.Diff, "\n"), "\n")
+		m.offset = 0
+		m.state = CompareStateDiff
+
+	case spinner.TickMsg:
+		if m.state == CompareStateLoading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.MouseMsg:
+		if m.state == CompareStateDiff {
+			m.scroll(wheelDelta(msg) * 3)
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case CompareStatePickFrom:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.fromCursor > 0 {
+					m.fromCursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.fromCursor < len(m.commits)-1 {
+					m.fromCursor++
+				}
+			case key.Matches(msg, keys.Enter):
+				m.toCursor = 0
+				m.state = CompareStatePickTo
+			}
+
+		case CompareStatePickTo:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.toCursor > 0 {
+					m.toCursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.toCursor < len(m.commits) {
+					m.toCursor++
+				}
+			case key.Matches(msg, keys.Enter):
+				if m.toCursor != m.fromCursor+1 {
+					return m.compare()
+				}
+			case msg.String() == "esc":
+				m.state = CompareStatePickFrom
+			}
+
+		case CompareStateDiff:
+			switch {
+			case key.Matches(msg, keys.Up):
+				m.scroll(-1)
+			case key.Matches(msg, keys.Down):
+				m.scroll(1)
+			case msg.String() == "pgup":
+				m.scroll(-m.visibleLines())
+			case msg.String() == "pgdown" || msg.String() == " ":
+				m.scroll(m.visibleLines())
+			case msg.String() == "n":
+				// Jump to the next file
+				for i := m.offset + 1; i < len(m.lines); i++ {
+					if strings.HasPrefix(m.lines[i], "diff --git ") {
+						m.scroll(i - m.offset)
+						break
+					}
+				}
+			case msg.String() == "s" && m.toCursor > 0:
+				// Swap which side is older
+				m.fromCursor, m.toCursor = m.toCursor-1, m.fromCursor+1
+				return m.compare()
+			case msg.String() == "esc":
+				m.state = CompareStatePickTo
+			}
+		}
+	}
+	return m, nil
+}
+
+// visibleLines returns how many diff lines fit on screen
+func (m CompareModel) visibleLines() int {
+	if m.height > 0 {
+		return max(5, m.height-24)
+	}
+	return 15
+}
+
+// scroll moves the diff by delta lines, keeping the last page full
+func (m *CompareModel) scroll(delta int) {
+	m.offset = min(m.offset+delta, len(m.lines)-m.visibleLines())
+	m.offset = max(m.offset, 0)
+}
+
+// View renders the compare screen
+func (m CompareModel) View() string {
+	var s string
+
+	s += RenderTitle("Compare saves") + "\n\n"
+
+	switch m.state {
+	case CompareStateEmpty:
+		s += RenderMuted("No saves yet, so there's nothing to compare.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case CompareStatePickFrom:
+		s += RenderSubtitle("Compare from which save?") + "\n\n"
+		s += m.renderPicker(m.fromCursor, false) + "\n"
+		s += HelpBar(m.Keys())
+
+	case CompareStatePickTo:
+		from := m.commits[m.fromCursor]
+		s += RenderMuted("From ") + HighlightStyle.Render(from.Hash) + " " + from.Message + "\n\n"
+		s += RenderSubtitle("Compare with?") + "\n\n"
+		s += m.renderPicker(m.toCursor, true) + "\n"
+		s += HelpBar(m.Keys())
+
+	case CompareStateLoading:
+		s += m.spinner.View() + " " + RenderHighlight("Comparing...") + "\n"
+
+	case CompareStateDiff:
+		s += m.renderDiff()
+
+	case CompareStateError:
+		s += RenderError("✗ Couldn't compare") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderPicker renders the list of saves to pick from. The second pick can
+// also be the files now, and can't be the save picked first.
+func (m CompareModel) renderPicker(cursor int, withFilesNow bool) string {
+	type entry struct {
+		label, details string
+		disabled       bool
+	}
+	var entries []entry
+	if withFilesNow {
+		entries = append(entries, entry{label: "Your files now", details: "unsaved changes included"})
+	}
+	for i, c := range m.commits {
+		e := entry{label: c.Hash + " " + c.Message, details: c.Timestamp}
+		if withFilesNow && i == m.fromCursor {
+			e.details = "comparing from this"
+			e.disabled = true
+		}
+		entries = append(entries, e)
+	}
+
+	maxVisible := 10
+	if m.height > 0 {
+		maxVisible = max(3, m.height-18)
+	}
+	start := 0
+	if cursor >= maxVisible {
+		start = cursor - maxVisible + 1
+	}
+	var s string
+	for i := start; i < len(entries) && i < start+maxVisible; i++ {
+		e := entries[i]
+		prefix, style := "  ", ListItemStyle
+		if i == cursor {
+			prefix, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		if e.disabled {
+			style = MutedStyle
+		}
+		s += prefix + style.Render(truncateLine(e.label, 50)) + " " + MutedStyle.Render(e.details) + "\n"
+	}
+	if len(entries) > maxVisible {
+		s += MutedStyle.Render(fmt.Sprintf("  ... %d saves", len(m.commits))) + "\n"
+	}
+	return s
+}
+
+// renderDiff renders the two sides, the files changed and the part of the
+// diff scrolled to
+func (m CompareModel) renderDiff() string {
+	var s string
+	c := m.comparison
+
+	s += RenderMuted("From ") + HighlightStyle.Render(c.From.Hash) + " " + c.From.Message + " " + MutedStyle.Render(c.From.Timestamp) + "\n"
+	if c.WorkingTree() {
+		s += RenderMuted("To   ") + HighlightStyle.Render("your files now") + "\n\n"
+	} else {
+		s += RenderMuted("To   ") + HighlightStyle.Render(c.To.Hash) + " " + c.To.Message + " " + MutedStyle.Render(c.To.Timestamp) + "\n\n"
+	}
+
+	if len(c.Summary.Files) == 0 {
+		s += RenderMuted("No differences.") + "\n\n"
+		return s + HelpBar(m.Keys())
+	}
+
+	s += RenderSubtitle(plural(len(c.Summary.Files), "file")+" changed ") +
+		SuccessStyle.Render(fmt.Sprintf("+%d", c.Summary.TotalAdded)) + " " +
+		ErrorStyle.Render(fmt.Sprintf("-%d", c.Summary.TotalDeleted)) + "\n"
+	s += strings.Join(renderFileStats(c.Summary, 5), "\n") + "\n\n"
+
+	width := 100
+	if m.width > 0 {
+		width = max(20, m.width-8)
+	}
+	highlight := syntaxHighlightEnabled()
+	var lang chroma.Lexer
+	end := min(len(m.lines), m.offset+m.visibleLines())
+	for i := 0; i < end; i++ {
+		line := m.lines[i]
+		// Follow which file each line is in, for its highlighting
+		if file, ok := strings.CutPrefix(line, "+++ "); ok {
+			lang = nil
+			if highlight {
+				lang = syntaxFor(strings.TrimPrefix(file, "b/"))
+			}
+		}
+		if i < m.offset {
+			continue
+		}
+		if strings.HasPrefix(line, "diff --git ") {
+			s += HighlightStyle.Render(truncateLine(line, width)) + "\n"
+			continue
+		}
+		s += renderDiffLine(line, lang, width) + "\n"
+	}
+	s += MutedStyle.Render(fmt.Sprintf("lines %d-%d of %d", m.offset+1, end, len(m.lines))) + "\n\n"
+
+	return s + HelpBar(m.Keys())
+}
+
+// Keys returns the keys the compare screen takes in its current state
+func (m CompareModel) Keys() Keys {
+	switch m.state {
+	case CompareStatePickFrom:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case CompareStatePickTo:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "compare"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case CompareStateDiff:
+		if len(m.comparison.Summary.Files) == 0 {
+			return Keys{Bar: []key.Binding{hint("esc", "back")}}
+		}
+		bar := []key.Binding{hint("↑↓", "scroll"), hint("pgup/pgdn", "page"), hint("n", "next file")}
+		if !m.comparison.WorkingTree() {
+			bar = append(bar, hint("s", "swap"))
+		}
+		return Keys{
+			Bar:  append(bar, hint("esc", "back")),
+			More: []key.Binding{vimKeys, hint("space", "page down"), hint("wheel", "scroll")},
+		}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if there's nothing to compare, so any key goes back
+func (m CompareModel) IsDone() bool {
+	return m.state == CompareStateEmpty || m.state == CompareStateError
+}
+
+// HandlesEsc returns true after the first pick, where esc steps back
+func (m CompareModel) HandlesEsc() bool {
+	return m.state == CompareStatePickTo || m.state == CompareStateDiff
+}
@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// DetachedState represents the state of the detached HEAD recovery flow
+type DetachedState int
+
+const (
+	DetachedStateMenu DetachedState = iota
+	DetachedStateName
+	DetachedStateConfirmLeave
+	DetachedStateWorking
+	DetachedStateDone
+	DetachedStateError
+)
+
+// DetachedModel helps the user get back onto a branch after something
+// outside smooth left HEAD detached: keep working here on a new branch, or
+// go back to main
+type DetachedModel struct {
+	info       git.DetachedInfo
+	mainBranch string
+	cursor     int
+	state      DetachedState
+	textInput  textinput.Model
+	confirm    confirmation // before leaving saves no branch has
+	branch     string       // the branch the user ended up on
+	err        error
+}
+
+// DetachedMsg is sent when the user is back on a branch, or that failed
+type DetachedMsg struct {
+	Branch string
+	Err    error
+}
+
+// NewDetachedModel creates a model for the detached HEAD recovery flow
+func NewDetachedModel() DetachedModel {
+	ti := textinput.New()
+	ti.Placeholder = "my-branch"
+	ti.CharLimit = 50
+	ti.Width = 30
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m := DetachedModel{
+		mainBranch: git.GetMainBranch(),
+		textInput:  ti,
+		confirm:    newConfirmation(),
+	}
+	info, err := git.GetDetachedInfo()
+	if err != nil {
+		m.state = DetachedStateError
+		m.err = err
+	}
+	m.info = info
+	return m
+}
+
+// Init initializes the model
+func (m DetachedModel) Init() tea.Cmd {
+	return nil
+}
+
+// doCreateBranchHere starts a branch at the detached HEAD
+func doCreateBranchHere(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.CreateBranchHere(name); err != nil {
+			return DetachedMsg{Err: err}
+		}
+		return DetachedMsg{Branch: name}
+	}
+}
+
+// doLeaveDetached switches back to the main branch
+func doLeaveDetached(mainBranch string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.SwitchBranch(mainBranch); err != nil {
+			return DetachedMsg{Err: fmt.Errorf("couldn't switch to %s. Unsaved changes that clash with it may be in the way: %w", mainBranch, err)}
+		}
+		return DetachedMsg{Branch: mainBranch}
+	}
+}
+
+// Update handles messages
+func (m DetachedModel) Update(msg tea.Msg) (DetachedModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DetachedMsg:
+		if msg.Err != nil {
+			m.state = DetachedStateError
+			m.err = msg.Err
+			return m, nil
+		}
+		m.state = DetachedStateDone
+		m.branch = msg.Branch
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case DetachedStateMenu:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < 1 {
+					m.cursor++
+				}
+			case key.Matches(msg, keys.Enter):
+				if m.cursor == 0 {
+					m.state = DetachedStateName
+					m.textInput.Focus()
+					return m, textinput.Blink
+				}
+				if m.info.Loose > 0 {
+					m.confirm = newConfirmation()
+					if !m.confirm.done() {
+						m.state = DetachedStateConfirmLeave
+						return m, nil
+					}
+				}
+				m.state = DetachedStateWorking
+				return m, doLeaveDetached(m.mainBranch)
+			}
+
+		case DetachedStateName:
+			switch msg.String() {
+			case "enter":
+				name := strings.TrimSpace(m.textInput.Value())
+				if name == "" {
+					return m, nil
+				}
+				m.textInput.Blur()
+				m.state = DetachedStateWorking
+				return m, doCreateBranchHere(name)
+			case "esc":
+				m.textInput.Blur()
+				m.textInput.SetValue("")
+				m.state = DetachedStateMenu
+			default:
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
+		case DetachedStateConfirmLeave:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					m.state = DetachedStateWorking
+					return m, doLeaveDetached(m.mainBranch)
+				}
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = DetachedStateMenu
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the recovery flow
+func (m DetachedModel) View() string {
+	var s string
+
+	s += RenderTitle("Not on a branch") + "\n\n"
+
+	switch m.state {
+	case DetachedStateMenu:
+		s += RenderError("⚠ You're looking at an old save, not a branch") + "\n\n"
+		s += "At: " + HighlightStyle.Render(m.info.Commit.Hash) + " " + truncateLine(m.info.Commit.Message, 50) + "\n"
+		s += RenderMuted("Something outside smooth checked out this save directly. New saves") + "\n"
+		s += RenderMuted("made here don't belong to any branch and are easy to lose.") + "\n\n"
+		if m.info.Loose > 0 {
+			s += RenderHighlight("Not on any branch yet: "+plural(m.info.Loose, "save")+" made here.") + "\n\n"
+		}
+
+		options := []struct {
+			title string
+			desc  string
+		}{
+			{"Create a branch here", "Keep working from this save, with your saves and changes on a new branch"},
+			{fmt.Sprintf("Go back to %s", m.mainBranch), fmt.Sprintf("Switch to %s as it is now", m.mainBranch)},
+		}
+		for i, opt := range options {
+			cursor := "  "
+			style := MenuItemStyle
+			if m.cursor == i {
+				cursor = MenuCursorStyle.Render("> ")
+				style = MenuItemSelectedStyle
+			}
+			s += cursor + style.Render(opt.title) + "\n"
+			s += "    " + MutedStyle.Render(opt.desc) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case DetachedStateName:
+		s += RenderSubtitle("Name for the new branch:") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case DetachedStateConfirmLeave:
+		if m.confirm.warn {
+			s += RenderError("⚠ "+plural(m.info.Loose, "save")+" made here will be left behind!") + "\n\n"
+		}
+		s += RenderMuted("Create a branch here first to keep them.") + "\n\n"
+		s += m.confirm.prompt("Go back to "+m.mainBranch+" anyway?") + "\n"
+
+	case DetachedStateWorking:
+		s += RenderHighlight("Switching...") + "\n"
+
+	case DetachedStateDone:
+		s += RenderSuccess("✓ You're on "+m.branch) + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case DetachedStateError:
+		s += RenderError("✗ Couldn't get back onto a branch") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// IsDone returns true once the user is back on a branch or it failed, so
+// any key goes back to the menu
+func (m DetachedModel) IsDone() bool {
+	return m.state == DetachedStateDone || m.state == DetachedStateError
+}
+
+// Keys returns the keys the screen takes in its current state
+func (m DetachedModel) Keys() Keys {
+	switch m.state {
+	case DetachedStateMenu:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case DetachedStateName:
+		return Keys{Bar: []key.Binding{hint("enter", "create"), hint("esc", "cancel")}, Typing: true}
+	case DetachedStateConfirmLeave:
+		return Keys{More: []key.Binding{hint("y", "go back to "+m.mainBranch), hint("n", "no, stay here")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true while typing a branch name or confirming, where
+// esc goes back within the screen
+func (m DetachedModel) HandlesEsc() bool {
+	return m.state == DetachedStateName || m.state == DetachedStateConfirmLeave
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"regexp"
+
+	"smooth/config"
+)
+
+// noEmoji is on when the user's terminal or font draws emoji badly, and
+// they're left out of everything smooth shows
+var noEmoji bool
+
+func init() {
+	cfg, _ := config.Load()
+	SetNoEmoji(cfg.NoEmoji)
+}
+
+// SetNoEmoji turns leaving emoji out on or off and restyles the interface
+// to match
+func SetNoEmoji(on bool) {
+	noEmoji = on
+	ReloadTheme()
+}
+
+// emoji matches pictographs and the symbols terminals draw as emoji, with
+// the joiners and modifiers that build them up and the space after them.
+// Symbols like ✓, ✗ and ⚠ are plain text and stay, losing only the
+// selector that asks for them to be drawn as emoji.
+var emoji = regexp.MustCompile(`(?:[\x{1F000}-\x{1FAFF}\x{231A}\x{231B}\x{23E9}-\x{23EC}\x{23F0}\x{23F3}\x{25FD}\x{25FE}` +
+	`\x{2614}\x{2615}\x{2648}-\x{2653}\x{267F}\x{2693}\x{26A1}\x{26AA}\x{26AB}\x{26BD}\x{26BE}\x{26C4}\x{26C5}\x{26CE}` +
+	`\x{26D4}\x{26EA}\x{26F2}\x{26F3}\x{26F5}\x{26FA}\x{26FD}\x{2705}\x{270A}\x{270B}\x{2728}\x{274C}\x{274E}` +
+	`\x{2753}-\x{2755}\x{2757}\x{2795}-\x{2797}\x{27B0}\x{27BF}\x{2B1B}\x{2B1C}\x{2B50}\x{2B55}]` +
+	`[\x{FE0F}\x{200D}\x{E0020}-\x{E007F}]*)+ ?|\x{FE0F}`)
+
+// StripEmoji leaves the emoji out of s if the user turned them off
+func StripEmoji(s string) string {
+	if !noEmoji {
+		return s
+	}
+	return emoji.ReplaceAllString(s, "")
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/config"
+	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+)
+
+// wipMessage is the message of the save made of unsaved work at the end
+// of the day
+const wipMessage = "WIP: end of day"
+
+// EndOfDayMsg is sent when the day has been wrapped up
+type EndOfDayMsg struct {
+	Summary git.DaySummary
+	Err     error
+}
+
+// endOfDayDue checks if the configured end of day has passed and today
+// hasn't been wrapped up yet
+func endOfDayDue(cfg config.Config, now time.Time) bool {
+	at, ok := cfg.EndOfDayAt(now)
+	return ok && !now.Before(at) && !git.HasDaySummary(now)
+}
+
+// doEndOfDay wraps up the day in the background
+func doEndOfDay() tea.Cmd {
+	return func() tea.Msg {
+		summary, err := WrapUpDay(time.Now())
+		return EndOfDayMsg{Summary: summary, Err: err}
+	}
+}
+
+// WrapUpDay saves any unsaved work as a work-in-progress save, uploads it
+// if auto-sync is on, and records a summary of the day in .smooth/daily.
+// The summary is posted to the end-of-day webhook if one is set. Unsaved
+// work goes through the same checks as a quick save: files on the skip list
+// stay out, and a file over the size limit, anything that looks like a
+// secret or a failing pre-save check stops the WIP save, which the summary
+// records.
+func WrapUpDay(now time.Time) (git.DaySummary, error) {
+	cfg, _ := config.Load()
+
+	var wipHash, wipErr string
+	if git.HasChanges() {
+		changes, _ := git.GetChangeSummary()
+		files := ops.NewFiles(changes)
+		if hasAction(files, FileActionSave) {
+			if reason := tooLarge(files, cfg.LargeFileMB); reason != "" {
+				wipErr = reason
+			} else if result := Save(wipMessage, files, nil, false, nil); result.Err != nil {
+				wipErr = result.Err.Error()
+			} else {
+				wipHash = result.Hash
+			}
+		}
+	}
+
+	var synced bool
+	var syncErr string
+	if cfg.AutoSyncEnabled && git.HasRemote() {
+		ctx, cancel := networkContext()
+		err := git.Push(ctx)
+		cancel()
+		if err != nil {
+			syncErr = err.Error()
+			git.QueueSync(err)
+			notify.SyncFailedHook(err)
+		} else {
+			synced = true
+		}
+	}
+
+	summary, err := git.SummarizeDay(now)
+	if err != nil {
+		return summary, err
+	}
+	summary.WIPHash = wipHash
+	summary.WIPError = wipErr
+	summary.Synced = synced
+	summary.SyncError = syncErr
+	if err := git.SaveDaySummary(summary); err != nil {
+		return summary, err
+	}
+
+	notify.DayWrappedUp(len(summary.Saves), len(summary.Files), wipHash != "")
+	if cfg.EndOfDayWebhook != "" {
+		notify.Webhook(cfg.EndOfDayWebhook, summary)
+	}
+	return summary, nil
+}
@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// EOLState represents the state of the line-ending assistant
+type EOLState int
+
+const (
+	EOLStateExplain EOLState = iota
+	EOLStateWorking
+	EOLStateSuccess
+	EOLStateError
+	EOLStateNothingToFix
+	EOLStateDismissed
+)
+
+// eolChoice is an option offered by the line-ending assistant
+type eolChoice int
+
+const (
+	eolChoiceNormalize eolChoice = iota
+	eolChoiceAutoCRLF
+	eolChoiceNotNow
+)
+
+// EOLModel is the model for the line-ending assistant
+type EOLModel struct {
+	files   []string
+	cursor  int
+	state   EOLState
+	err     error
+	message string
+	width   int
+	height  int
+}
+
+// NewEOLModel creates a new line-ending assistant model
+func NewEOLModel() EOLModel {
+	files, _ := git.LineEndingChurn()
+
+	state := EOLStateExplain
+	if len(files) == 0 {
+		state = EOLStateNothingToFix
+	}
+
+	return EOLModel{
+		files: files,
+		state: state,
+	}
+}
+
+// Init initializes the model
+func (m EOLModel) Init() tea.Cmd {
+	return nil
+}
+
+// EOLMsg is sent when a line-ending fix completes
+type EOLMsg struct {
+	Err     error
+	Message string
+}
+
+// doNormalizeLineEndings adds .gitattributes and re-saves the files with LF endings
+func doNormalizeLineEndings(files []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.NormalizeLineEndings(files); err != nil {
+			return EOLMsg{Err: err}
+		}
+		return EOLMsg{Message: "Line endings normalized and saved"}
+	}
+}
+
+// doSetAutoCRLF configures core.autocrlf for this repository
+func doSetAutoCRLF() tea.Cmd {
+	return func() tea.Msg {
+		value := git.RecommendedAutoCRLF()
+		if err := git.SetAutoCRLF(value); err != nil {
+			return EOLMsg{Err: err}
+		}
+		return EOLMsg{Message: fmt.Sprintf("Set core.autocrlf to %q for this project", value)}
+	}
+}
+
+// Update handles messages
+func (m EOLModel) Update(msg tea.Msg) (EOLModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case EOLMsg:
+		if msg.Err != nil {
+			m.state = EOLStateError
+			m.err = msg.Err
+		} else {
+			m.state = EOLStateSuccess
+			m.message = msg.Message
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.state != EOLStateExplain {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < int(eolChoiceNotNow) {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Enter):
+			switch eolChoice(m.cursor) {
+			case eolChoiceNormalize:
+				m.state = EOLStateWorking
+				return m, doNormalizeLineEndings(m.files)
+			case eolChoiceAutoCRLF:
+				m.state = EOLStateWorking
+				return m, doSetAutoCRLF()
+			case eolChoiceNotNow:
+				m.state = EOLStateDismissed
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the line-ending assistant
+func (m EOLModel) View() string {
+	var s string
+
+	s += RenderTitle("Line Endings") + "\n\n"
+
+	switch m.state {
+	case EOLStateNothingToFix:
+		s += RenderMuted("No line-ending-only changes right now.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case EOLStateExplain:
+		s += RenderHighlight(fmt.Sprintf("%d file(s) look completely changed, but only their line endings differ.", len(m.files))) + "\n\n"
+		s += RenderMuted("Windows ends lines with two invisible characters (CRLF), while Mac") + "\n"
+		s += RenderMuted("and Linux use one (LF). When an editor or tool switches between them,") + "\n"
+		s += RenderMuted("git sees every line as changed even though the text is the same.") + "\n\n"
+
+		for i, path := range m.files {
+			if i >= 5 {
+				s += MutedStyle.Render(fmt.Sprintf("  ... and %d more", len(m.files)-5)) + "\n"
+				break
+			}
+			s += "  " + MutedStyle.Render("• "+path) + "\n"
+		}
+		s += "\n"
+
+		options := []struct {
+			title string
+			desc  string
+		}{
+			{"Normalize line endings (recommended)", "Add a .gitattributes rule and save these files with consistent endings"},
+			{"Just configure git for this computer", fmt.Sprintf("Set core.autocrlf=%s so new changes are converted automatically", git.RecommendedAutoCRLF())},
+			{"Not now", "Leave everything as it is"},
+		}
+		for i, opt := range options {
+			cursor := "  "
+			style := MenuItemStyle
+			if m.cursor == i {
+				cursor = MenuCursorStyle.Render("> ")
+				style = MenuItemSelectedStyle
+			}
+			s += cursor + style.Render(opt.title) + "\n"
+			s += "    " + MutedStyle.Render(opt.desc) + "\n\n"
+		}
+
+		s += HelpBar(m.Keys())
+
+	case EOLStateWorking:
+		s += RenderHighlight("Fixing line endings...") + "\n"
+
+	case EOLStateSuccess:
+		s += RenderSuccess("✓ "+m.message) + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case EOLStateError:
+		s += RenderError("✗ Couldn't fix line endings") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// Keys returns the keys the line endings screen takes in its current state
+func (m EOLModel) Keys() Keys {
+	if m.state == EOLStateExplain {
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	}
+	return Keys{}
+}
+
+// WantsBack returns true if the user chose "Not now"
+func (m EOLModel) WantsBack() bool {
+	return m.state == EOLStateDismissed
+}
+
+// IsDone returns true if the flow is complete
+func (m EOLModel) IsDone() bool {
+	return m.state == EOLStateSuccess || m.state == EOLStateError || m.state == EOLStateNothingToFix
+}
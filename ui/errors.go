@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"smooth/git"
+)
+
+// errorHelp is a plain-language explanation of an error and what to do
+// about it
+type errorHelp struct {
+	explanation string
+	hints       []string
+}
+
+// explainError describes the errors smooth knows how to explain, and
+// returns false for anything else
+func explainError(err error) (errorHelp, bool) {
+	var (
+		noRemote git.NoRemoteError
+		auth     *git.AuthError
+		merge    *git.MergeConflictError
+		dirty    *git.DirtyTreeError
+		lock     *git.LockError
+		timeout  *git.TimeoutError
+	)
+	switch {
+	case errors.As(err, &noRemote):
+		return errorHelp{
+			explanation: "This project isn't connected to GitHub yet, so there's nowhere to send your saves.",
+			hints: []string{
+				"Choose Sync to GitHub and enter the address of a repository to connect it",
+				"Create an empty repository on github.com first if you don't have one",
+			},
+		}, true
+
+	case errors.As(err, &auth):
+		return errorHelp{
+			explanation: "GitHub didn't accept your login. Your token may have expired, or GitHub may not know your SSH key.",
+			hints: []string{
+				"Choose Sync to GitHub to set up a new access token or SSH key",
+				"Check you can open the repository on github.com with this account",
+			},
+		}, true
+
+	case errors.As(err, &merge):
+		return errorHelp{
+			explanation: fmt.Sprintf("%s and this branch both changed the same lines in %s, so smooth can't combine them by itself. Nothing was changed.",
+				merge.Branch, plural(len(merge.Files), "file")),
+			hints: []string{
+				"Open " + listFiles(merge.Files) + " and make the same changes by hand",
+				"Or keep working on " + merge.Branch + " and combine them later",
+			},
+		}, true
+
+	case errors.As(err, &dirty):
+		return errorHelp{
+			explanation: "You have unsaved changes in " + listFiles(dirty.Files) + " that this would overwrite, so nothing was changed.",
+			hints: []string{
+				"Save your changes first, then try again",
+				"Or undo them from Undo changes if you don't need them",
+			},
+		}, true
+
+	case errors.As(err, &lock):
+		return errorHelp{
+			explanation: "Another git operation is still running, so this one had to stop.",
+			hints: []string{
+				"Wait a moment and try again",
+				"If nothing else is using git, delete .git/index.lock; a crashed operation may have left it behind",
+			},
+		}, true
+
+	case errors.As(err, &timeout):
+		return errorHelp{
+			explanation: "GitHub didn't answer in time, so smooth stopped waiting.",
+			hints: []string{
+				"Check your internet connection and try again",
+				"On a slow connection, give it longer with Network timeout in Settings",
+			},
+		}, true
+	}
+	return errorHelp{}, false
+}
+
+// renderErrorDetails explains err in plain language with what to do next,
+// or shows its message if it isn't one smooth can explain
+func renderErrorDetails(err error) string {
+	help, ok := explainError(err)
+	if !ok {
+		return RenderMuted(err.Error()) + "\n\n"
+	}
+	s := RenderMuted(help.explanation) + "\n\n"
+	s += RenderSubtitle("What you can do:") + "\n"
+	for _, hint := range help.hints {
+		s += "  • " + hint + "\n"
+	}
+	return s + "\n"
+}
+
+// listFiles names a few files, and how many more there are
+func listFiles(files []string) string {
+	const shown = 3
+	switch {
+	case len(files) == 0:
+		return "some files"
+	case len(files) <= shown:
+		return strings.Join(files, ", ")
+	}
+	return strings.Join(files[:shown], ", ") + fmt.Sprintf(" and %d more", len(files)-shown)
+}
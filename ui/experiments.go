@@ -1,13 +1,17 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"smooth/achievements"
 	"smooth/git"
 )
 
@@ -25,6 +29,15 @@ const (
 	ExperimentsStateSuccess
 	ExperimentsStateError
 	ExperimentsStateUnsavedWarning
+	ExperimentsStateConfirmAbandon
+	ExperimentsStateRenameInput
+	ExperimentsStateRenaming
+	ExperimentsStateKeepChoice
+	ExperimentsStatePRTitle
+	ExperimentsStateOpeningPR
+	ExperimentsStateArchiving
+	ExperimentsStateArchivedList
+	ExperimentsStateConfirmDeleteArchived
 )
 
 // ExperimentsAction represents the selected action
@@ -34,6 +47,9 @@ const (
 	ExpActionStart ExperimentsAction = iota
 	ExpActionKeep
 	ExpActionAbandon
+	ExpActionArchive
+	ExpActionArchived
+	ExpActionRename
 	ExpActionSwitch
 	ExpActionBack
 )
@@ -45,12 +61,21 @@ type ExperimentsModel struct {
 	textInput     textinput.Model
 	experiments   []git.BranchInfo
 	expCursor     int
+	archived      []git.BranchInfo // experiments put away with Archive
+	archCursor    int
 	currentBranch string
 	isOnMain      bool
 	hasChanges    bool
 	err           error
 	message       string
 	blockedAction ExperimentsAction // action that was blocked by unsaved changes
+	celebration   celebration       // achievements unlocked by keeping an experiment
+	cheer         cheer             // the mascot's cheer if this was the first experiment kept
+	confirm       confirmation      // before abandoning, at the configured safety level
+	renaming      string            // the experiment being renamed
+	keepCursor    int               // merge here or open a pull request
+	draft         git.PullRequestDraft
+	prURL         string // the pull request opened instead of merging
 	width         int
 	height        int
 }
@@ -78,8 +103,7 @@ func NewKeepExperimentModel() (ExperimentsModel, tea.Cmd) {
 		return m, nil
 	}
 	
-	m.state = ExperimentsStateKeeping
-	return m, doKeepExperiment()
+	return m.startKeep()
 }
 
 // NewAbandonExperimentModel creates an experiments model that starts the abandon flow
@@ -93,6 +117,48 @@ func NewAbandonExperimentModel() (ExperimentsModel, tea.Cmd) {
 		return m, nil
 	}
 	
+	return m.startAbandon()
+}
+
+// startKeep offers to open a pull request instead of merging locally when
+// the project is on GitHub, and otherwise merges straight away
+func (m ExperimentsModel) startKeep() (ExperimentsModel, tea.Cmd) {
+	if _, _, err := git.GitHubRepo(); err != nil {
+		m.state = ExperimentsStateKeeping
+		return m, doKeepExperiment()
+	}
+	m.keepCursor = 0
+	m.state = ExperimentsStateKeepChoice
+	return m, nil
+}
+
+// startPullRequest prefills a pull request from the experiment's saves and
+// lets the title be edited before it's opened
+func (m ExperimentsModel) startPullRequest() (ExperimentsModel, tea.Cmd) {
+	draft, err := git.DraftPullRequest()
+	if err != nil {
+		m.state = ExperimentsStateError
+		m.err = err
+		return m, nil
+	}
+	m.draft = draft
+	m.textInput.CharLimit = 100
+	m.textInput.Width = 50
+	m.textInput.SetValue(draft.Title)
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	m.state = ExperimentsStatePRTitle
+	return m, textinput.Blink
+}
+
+// startAbandon asks for confirmation before abandoning the experiment,
+// unless the safety level says not to
+func (m ExperimentsModel) startAbandon() (ExperimentsModel, tea.Cmd) {
+	m.confirm = newConfirmation()
+	if !m.confirm.done() {
+		m.state = ExperimentsStateConfirmAbandon
+		return m, nil
+	}
 	m.state = ExperimentsStateAbandoning
 	return m, doAbandonExperiment()
 }
@@ -108,6 +174,7 @@ func newExperimentsModelWithAction(state ExperimentsState, action ExperimentsAct
 	branch, _ := git.CurrentBranch()
 	isOnMain := git.IsOnMain()
 	experiments, _ := git.ListExperiments()
+	archived, _ := git.ListArchivedExperiments()
 	hasChanges := git.HasChanges()
 
 	return ExperimentsModel{
@@ -115,6 +182,7 @@ func newExperimentsModelWithAction(state ExperimentsState, action ExperimentsAct
 		cursor:        0,
 		textInput:     ti,
 		experiments:   experiments,
+		archived:      archived,
 		currentBranch: branch,
 		isOnMain:      isOnMain,
 		hasChanges:    hasChanges,
@@ -141,12 +209,30 @@ func (m ExperimentsModel) getMenuItems() []experimentsMenuItem {
 			Action:      ExpActionAbandon,
 			Disabled:    m.isOnMain,
 		},
+		{
+			Title:       "Archive this experiment",
+			Description: "Put it away without deleting it, to bring back later",
+			Action:      ExpActionArchive,
+			Disabled:    m.isOnMain,
+		},
+		{
+			Title:       "Rename this experiment",
+			Description: "Give this experiment a better name",
+			Action:      ExpActionRename,
+			Disabled:    m.isOnMain,
+		},
 		{
 			Title:       "Switch experiment",
 			Description: "Switch to a different experiment",
 			Action:      ExpActionSwitch,
 			Disabled:    len(m.experiments) == 0,
 		},
+		{
+			Title:       "Archived experiments",
+			Description: "Bring back or delete experiments you put away",
+			Action:      ExpActionArchived,
+			Disabled:    len(m.archived) == 0,
+		},
 		{
 			Title:       "Back to main menu",
 			Description: "",
@@ -164,6 +250,9 @@ func (m ExperimentsModel) Init() tea.Cmd {
 type ExperimentsMsg struct {
 	Err     error
 	Message string
+	Kept    bool // an experiment was merged into main
+
+	PullRequestURL string // a pull request was opened instead
 }
 
 // doCreateExperiment creates a new experiment branch
@@ -184,7 +273,18 @@ func doKeepExperiment() tea.Cmd {
 		if err != nil {
 			return ExperimentsMsg{Err: err}
 		}
-		return ExperimentsMsg{Message: "Experiment merged into main!"}
+		return ExperimentsMsg{Message: "Experiment merged into main!", Kept: true}
+	}
+}
+
+// doOpenPullRequest uploads the experiment and opens a pull request for it
+func doOpenPullRequest(draft git.PullRequestDraft) tea.Cmd {
+	return func() tea.Msg {
+		url, err := git.CreatePullRequest(draft)
+		if err != nil {
+			return ExperimentsMsg{Err: err}
+		}
+		return ExperimentsMsg{Message: "Pull request opened!", PullRequestURL: url}
 	}
 }
 
@@ -199,22 +299,94 @@ func doAbandonExperiment() tea.Cmd {
 	}
 }
 
-// doSwitchExperiment switches to a different experiment
-func doSwitchExperiment(branchName string) tea.Cmd {
+// doArchiveExperiment puts the current experiment away and goes back to main
+func doArchiveExperiment() tea.Cmd {
 	return func() tea.Msg {
-		// Stash any current changes
-		if git.HasChanges() {
-			if err := git.Stash(); err != nil {
-				return ExperimentsMsg{Err: err}
-			}
+		if _, err := git.ArchiveExperiment(); err != nil {
+			return ExperimentsMsg{Err: err}
 		}
-		
-		if err := git.SwitchBranch(branchName); err != nil {
+		return ExperimentsMsg{Message: "Experiment archived. Back on main."}
+	}
+}
+
+// doUnarchiveExperiment brings an archived experiment back
+func doUnarchiveExperiment(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.UnarchiveExperiment(name); err != nil {
+			return ExperimentsMsg{Err: err}
+		}
+		return ExperimentsMsg{Message: fmt.Sprintf("%s is back in your experiments", name)}
+	}
+}
+
+// doDeleteArchivedExperiment deletes an archived experiment for good
+func doDeleteArchivedExperiment(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.DeleteArchivedExperiment(name); err != nil {
+			return ExperimentsMsg{Err: err}
+		}
+		return ExperimentsMsg{Message: fmt.Sprintf("Deleted %s", name)}
+	}
+}
+
+// startArchive puts the current experiment away, unless there are unsaved
+// changes that would be left behind
+func (m ExperimentsModel) startArchive() (ExperimentsModel, tea.Cmd) {
+	m.blockedAction = ExpActionArchive
+	if m.hasChanges {
+		m.state = ExperimentsStateUnsavedWarning
+		return m, nil
+	}
+	m.state = ExperimentsStateArchiving
+	return m, doArchiveExperiment()
+}
+
+// doRenameExperiment renames an experiment, on GitHub too if it was synced
+func doRenameExperiment(oldName, name string) tea.Cmd {
+	return func() tea.Msg {
+		branchName, err := git.RenameExperiment(oldName, name)
+		var remoteErr *git.RenameRemoteError
+		if errors.As(err, &remoteErr) {
+			return ExperimentsMsg{Message: fmt.Sprintf("Renamed to %s (%s)", branchName, remoteErr.Error())}
+		}
+		if err != nil {
 			return ExperimentsMsg{Err: err}
 		}
+		return ExperimentsMsg{Message: fmt.Sprintf("Renamed to %s", branchName)}
+	}
+}
+
+// startRename asks for a new name for an experiment, starting from the
+// name it has now without the timestamp
+func (m ExperimentsModel) startRename(branch string) (ExperimentsModel, tea.Cmd) {
+	m.renaming = branch
+	m.textInput.SetValue(experimentLabel(branch))
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	m.state = ExperimentsStateRenameInput
+	return m, textinput.Blink
+}
 
-		// Try to pop stash, but don't fail if there's nothing
-		git.StashPop()
+// experimentTimestamp matches the timestamp CreateExperiment adds
+var experimentTimestamp = regexp.MustCompile(`-\d{8}-\d{6}$`)
+
+// experimentLabel returns the part of an experiment's branch name the user
+// chose
+func experimentLabel(branch string) string {
+	return experimentTimestamp.ReplaceAllString(strings.TrimPrefix(branch, "experiment-"), "")
+}
+
+// doSwitchExperiment switches to a different experiment
+func doSwitchExperiment(branchName string) tea.Cmd {
+	return func() tea.Msg {
+		// Unsaved changes come along; if they clash with the experiment
+		// they stay stashed for the Stashed changes screen
+		err := git.WithAutoStash("switching to "+branchName, func() error {
+			return git.SwitchBranch(branchName)
+		})
+		if err != nil {
+			return ExperimentsMsg{Err: err}
+		}
 
 		return ExperimentsMsg{Message: fmt.Sprintf("Switched to: %s", branchName)}
 	}
@@ -237,13 +409,27 @@ func (m ExperimentsModel) Update(msg tea.Msg) (ExperimentsModel, tea.Cmd) {
 		} else {
 			m.state = ExperimentsStateSuccess
 			m.message = msg.Message
+			m.prURL = msg.PullRequestURL
 		}
 		// Refresh state
 		m.currentBranch, _ = git.CurrentBranch()
 		m.isOnMain = git.IsOnMain()
 		m.experiments, _ = git.ListExperiments()
+		m.archived, _ = git.ListArchivedExperiments()
+		if msg.Kept {
+			unlocked := achievements.RecordExperimentKept()
+			var celebrate tea.Cmd
+			m.celebration, celebrate = newCelebration(unlocked)
+			m.cheer = newCheer(unlocked, false)
+			return m, celebrate
+		}
 		return m, nil
 
+	case celebrationTickMsg:
+		var cmd tea.Cmd
+		m.celebration, cmd = m.celebration.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
 		switch m.state {
 		case ExperimentsStateMenu:
@@ -287,8 +473,7 @@ func (m ExperimentsModel) Update(msg tea.Msg) (ExperimentsModel, tea.Cmd) {
 						m.state = ExperimentsStateUnsavedWarning
 						return m, nil
 					}
-					m.state = ExperimentsStateKeeping
-					return m, doKeepExperiment()
+					return m.startKeep()
 				case ExpActionAbandon:
 					m.blockedAction = ExpActionAbandon
 					// Check for unsaved changes first
@@ -296,8 +481,14 @@ func (m ExperimentsModel) Update(msg tea.Msg) (ExperimentsModel, tea.Cmd) {
 						m.state = ExperimentsStateUnsavedWarning
 						return m, nil
 					}
-					m.state = ExperimentsStateAbandoning
-					return m, doAbandonExperiment()
+					return m.startAbandon()
+				case ExpActionArchive:
+					return m.startArchive()
+				case ExpActionArchived:
+					m.state = ExperimentsStateArchivedList
+					m.archCursor = 0
+				case ExpActionRename:
+					return m.startRename(m.currentBranch)
 				case ExpActionSwitch:
 					m.state = ExperimentsStateSwitchList
 					m.expCursor = 0
@@ -341,13 +532,118 @@ func (m ExperimentsModel) Update(msg tea.Msg) (ExperimentsModel, tea.Cmd) {
 					m.state = ExperimentsStateSwitching
 					return m, doSwitchExperiment(allOptions[m.expCursor].Name)
 				}
+			case msg.String() == "r":
+				// Main isn't an experiment, so it can't be renamed here
+				if m.expCursor > 0 {
+					return m.startRename(m.experiments[m.expCursor-1].Name)
+				}
+			case msg.String() == "esc":
+				m.state = ExperimentsStateMenu
+			}
+
+		case ExperimentsStateRenameInput:
+			switch msg.String() {
+			case "enter":
+				if strings.TrimSpace(m.textInput.Value()) != "" {
+					m.textInput.Blur()
+					m.state = ExperimentsStateRenaming
+					return m, doRenameExperiment(m.renaming, m.textInput.Value())
+				}
+			case "esc":
+				m.textInput.Blur()
+				m.textInput.SetValue("")
+				m.state = ExperimentsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
+		case ExperimentsStateKeepChoice:
+			switch {
+			case key.Matches(msg, keys.Up):
+				m.keepCursor = 0
+			case key.Matches(msg, keys.Down):
+				m.keepCursor = 1
+			case key.Matches(msg, keys.Enter):
+				if m.keepCursor == 1 {
+					return m.startPullRequest()
+				}
+				m.state = ExperimentsStateKeeping
+				return m, doKeepExperiment()
 			case msg.String() == "esc":
 				m.state = ExperimentsStateMenu
 			}
 
+		case ExperimentsStatePRTitle:
+			switch msg.String() {
+			case "enter":
+				if title := strings.TrimSpace(m.textInput.Value()); title != "" {
+					m.draft.Title = title
+					m.textInput.Blur()
+					m.state = ExperimentsStateOpeningPR
+					return m, doOpenPullRequest(m.draft)
+				}
+			case "esc":
+				m.textInput.Blur()
+				m.textInput.SetValue("")
+				m.state = ExperimentsStateKeepChoice
+			default:
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
 		case ExperimentsStateUnsavedWarning:
 			// Any key goes back to menu
 			m.state = ExperimentsStateMenu
+
+		case ExperimentsStateConfirmAbandon:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					m.state = ExperimentsStateAbandoning
+					return m, doAbandonExperiment()
+				}
+			case "a", "A":
+				return m.startArchive()
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = ExperimentsStateMenu
+			}
+
+		case ExperimentsStateArchivedList:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.archCursor > 0 {
+					m.archCursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.archCursor < len(m.archived)-1 {
+					m.archCursor++
+				}
+			case key.Matches(msg, keys.Enter) && len(m.archived) > 0:
+				return m, doUnarchiveExperiment(m.archived[m.archCursor].Name)
+			case msg.String() == "d" && len(m.archived) > 0:
+				m.confirm = newConfirmation()
+				if m.confirm.done() {
+					return m, doDeleteArchivedExperiment(m.archived[m.archCursor].Name)
+				}
+				m.state = ExperimentsStateConfirmDeleteArchived
+			case msg.String() == "esc":
+				m.state = ExperimentsStateMenu
+			}
+
+		case ExperimentsStateConfirmDeleteArchived:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					return m, doDeleteArchivedExperiment(m.archived[m.archCursor].Name)
+				}
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = ExperimentsStateArchivedList
+			}
 		}
 	}
 
@@ -393,13 +689,13 @@ func (m ExperimentsModel) View() string {
 			s += "\n"
 		}
 
-		s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "select"}, {"esc", "back"}})
+		s += HelpBar(m.Keys())
 
 	case ExperimentsStateNameInput:
 		s += RenderSubtitle("Name your experiment:") + "\n\n"
 		s += m.textInput.View() + "\n\n"
 		s += RenderMuted("Use a short, descriptive name (no spaces)") + "\n\n"
-		s += HelpBar([][]string{{"enter", "create"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
 
 	case ExperimentsStateCreating:
 		s += RenderHighlight("Creating experiment...") + "\n"
@@ -407,9 +703,63 @@ func (m ExperimentsModel) View() string {
 	case ExperimentsStateKeeping:
 		s += RenderHighlight("Merging experiment into main...") + "\n"
 
+	case ExperimentsStateKeepChoice:
+		s += RenderSubtitle("How do you want to keep it?") + "\n\n"
+		options := []experimentsMenuItem{
+			{Title: "Merge into " + git.GetMainBranch() + " here", Description: "Your experiment becomes part of your main work right away"},
+			{Title: "Open a pull request instead", Description: "Upload it to GitHub so it can be reviewed before it's merged"},
+		}
+		for i, option := range options {
+			cursor := "  "
+			style := ListItemStyle
+			if m.keepCursor == i {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			s += cursor + style.Render(option.Title) + "\n"
+			s += "    " + ListItemDescStyle.Render(option.Description) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case ExperimentsStatePRTitle:
+		s += RenderSubtitle("Pull request title:") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		s += RenderMuted(fmt.Sprintf("%s → %s, with these saves:", m.draft.Branch, m.draft.Base)) + "\n"
+		for _, line := range strings.Split(strings.TrimSpace(m.draft.Body), "\n") {
+			s += RenderMuted("  "+truncateLine(line, 60)) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+
+	case ExperimentsStateOpeningPR:
+		s += RenderHighlight("Uploading and opening a pull request...") + "\n"
+
+	case ExperimentsStateConfirmAbandon:
+		if m.confirm.warn {
+			s += RenderError("⚠ Warning: Saves made in this experiment will be deleted!") + "\n\n"
+		}
+		s += "Abandon: " + HighlightStyle.Render(m.currentBranch) + "\n\n"
+		s += RenderMuted("You'll go back to main, as it was before the experiment.") + "\n"
+		s += RenderMuted("Press a to archive it instead, so it can be brought back.") + "\n\n"
+		s += m.confirm.prompt("Are you sure?") + "\n"
+
 	case ExperimentsStateAbandoning:
 		s += RenderHighlight("Abandoning experiment...") + "\n"
 
+	case ExperimentsStateArchiving:
+		s += RenderHighlight("Archiving experiment...") + "\n"
+
+	case ExperimentsStateArchivedList:
+		s += m.renderArchived()
+
+	case ExperimentsStateConfirmDeleteArchived:
+		exp := m.archived[m.archCursor]
+		if m.confirm.warn {
+			s += RenderError("⚠ Warning: Its saves will be gone for good!") + "\n\n"
+		}
+		s += "Delete: " + HighlightStyle.Render(exp.Name) + "\n"
+		s += RenderMuted(experimentDetails(exp)) + "\n\n"
+		s += m.confirm.prompt("Are you sure?") + "\n"
+
 	case ExperimentsStateSwitchList:
 		s += RenderSubtitle("Select an experiment to switch to:") + "\n\n"
 
@@ -449,26 +799,57 @@ func (m ExperimentsModel) View() string {
 				label += " (current)"
 			}
 
-			s += cursor + style.Render(label) + "\n\n"
+			s += cursor + style.Render(label) + "\n"
+			if i > 0 {
+				s += "    " + MutedStyle.Render(experimentDetails(exp)) + "\n"
+			} else {
+				s += "\n"
+			}
 		}
 
 		if len(allOptions) > maxVisible {
 			s += MutedStyle.Render(fmt.Sprintf("  ... %d total branches\n", len(allOptions)))
 		}
 
-		s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "switch"}, {"esc", "back"}})
+		s += HelpBar(m.Keys())
+
+	case ExperimentsStateRenameInput:
+		s += RenderSubtitle("New name for ") + HighlightStyle.Render(m.renaming) + RenderSubtitle(":") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		s += RenderMuted("It will be called experiment-"+strings.TrimPrefix(strings.Join(strings.Fields(m.textInput.Value()), "-"), "experiment-")) + "\n"
+		s += RenderMuted("If it's synced to GitHub, it's renamed there too.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case ExperimentsStateRenaming:
+		s += RenderHighlight("Renaming experiment...") + "\n"
 
 	case ExperimentsStateSwitching:
 		s += RenderHighlight("Switching...") + "\n"
 
 	case ExperimentsStateSuccess:
 		s += RenderSuccess("✓ " + m.message) + "\n\n"
+		if m.prURL != "" {
+			s += HighlightStyle.Render(m.prURL) + "\n\n"
+			s += RenderMuted("Once it's merged on GitHub, switch to main and use Team sync to bring it in.") + "\n\n"
+		}
+		if cheer := m.cheer.View(); cheer != "" {
+			s += cheer + "\n\n"
+		}
+		if celebration := m.celebration.View(); celebration != "" {
+			s += celebration + "\n\n"
+		}
 		s += HelpText("Press any key to continue")
 
 	case ExperimentsStateError:
+		if conflict := m.StashConflict(); conflict != nil {
+			s += RenderError("⚠ Your unsaved changes didn't fit back in") + "\n\n"
+			s += RenderMuted(conflict.Error()+". They're safe in the stash.") + "\n\n"
+			s += HelpText("Press any key to sort them out")
+			break
+		}
 		s += RenderError("✗ Operation failed") + "\n\n"
 		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 
@@ -478,6 +859,8 @@ func (m ExperimentsModel) View() string {
 		actionName := "keep"
 		if m.blockedAction == ExpActionAbandon {
 			actionName = "abandon"
+		} else if m.blockedAction == ExpActionArchive {
+			actionName = "archive"
 		}
 		s += RenderMuted(fmt.Sprintf("You need to save your progress before you can %s", actionName)) + "\n"
 		s += RenderMuted("this experiment.") + "\n\n"
@@ -488,6 +871,29 @@ func (m ExperimentsModel) View() string {
 	return BoxStyle.Render(s)
 }
 
+// Keys returns the keys the experiments flow takes in its current state
+func (m ExperimentsModel) Keys() Keys {
+	switch m.state {
+	case ExperimentsStateMenu, ExperimentsStateKeepChoice:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case ExperimentsStateNameInput:
+		return Keys{Bar: []key.Binding{hint("enter", "create"), hint("esc", "cancel")}, Typing: true}
+	case ExperimentsStatePRTitle:
+		return Keys{Bar: []key.Binding{hint("enter", "upload and open"), hint("esc", "back")}, Typing: true}
+	case ExperimentsStateSwitchList:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "switch"), hint("r", "rename"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case ExperimentsStateRenameInput:
+		return Keys{Bar: []key.Binding{hint("enter", "rename"), hint("esc", "cancel")}, Typing: true}
+	case ExperimentsStateArchivedList:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "bring back"), hint("d", "delete"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case ExperimentsStateConfirmAbandon:
+		return Keys{More: []key.Binding{hint("y", "abandon it"), hint("a", "archive it instead"), hint("n", "no, keep it")}}
+	case ExperimentsStateConfirmDeleteArchived:
+		return Keys{More: []key.Binding{hint("y", "delete it"), hint("n", "no, keep it")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the current flow is complete
 func (m ExperimentsModel) IsDone() bool {
 	return m.state == ExperimentsStateSuccess || m.state == ExperimentsStateError
@@ -499,7 +905,17 @@ func (m ExperimentsModel) ShouldReturnToMainMenu() bool {
 	if m.state != ExperimentsStateSuccess {
 		return false
 	}
-	return m.blockedAction == ExpActionKeep || m.blockedAction == ExpActionAbandon
+	return m.blockedAction == ExpActionKeep || m.blockedAction == ExpActionAbandon || m.blockedAction == ExpActionArchive
+}
+
+// StashConflict returns the unsaved changes that clashed when they were put
+// back after switching, for the Stashed changes screen to sort out
+func (m ExperimentsModel) StashConflict() *git.StashConflictError {
+	var conflict *git.StashConflictError
+	if m.state == ExperimentsStateError && errors.As(m.err, &conflict) {
+		return conflict
+	}
+	return nil
 }
 
 // WantsBack returns true if the user selected "Back to main menu"
@@ -507,3 +923,42 @@ func (m ExperimentsModel) WantsBack() bool {
 	return m.state == ExperimentsStateMenu && m.getMenuItems()[m.cursor].Action == ExpActionBack
 }
 
+// experimentDetails describes an experiment in the switch list: how many
+// saves it's ahead of main, when it was last saved to and what it changes
+func experimentDetails(exp git.BranchInfo) string {
+	details := []string{plural(exp.Ahead, "save") + " ahead", exp.DiffSummary()}
+	if exp.LastActivity != "" {
+		details = append(details, "saved "+exp.LastActivity)
+	}
+	return strings.Join(details, " · ")
+}
+
+// renderArchived renders the archived experiments, most recently saved to
+// first
+func (m ExperimentsModel) renderArchived() string {
+	var s string
+	s += RenderSubtitle("Archived experiments:") + "\n\n"
+
+	maxVisible := 8
+	if m.height > 0 {
+		maxVisible = max(3, (m.height-14)/2)
+	}
+	start := 0
+	if m.archCursor >= maxVisible {
+		start = m.archCursor - maxVisible + 1
+	}
+	for i := start; i < len(m.archived) && i < start+maxVisible; i++ {
+		exp := m.archived[i]
+		cursor, style := "  ", ListItemStyle
+		if m.archCursor == i {
+			cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		s += cursor + style.Render(exp.Name) + "\n"
+		s += "    " + MutedStyle.Render(experimentDetails(exp)) + "\n"
+	}
+	if len(m.archived) > maxVisible {
+		s += MutedStyle.Render(fmt.Sprintf("  ... %d archived", len(m.archived))) + "\n"
+	}
+	s += "\n" + HelpBar(m.Keys())
+	return s
+}
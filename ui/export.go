@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// ExportState represents the state of the export flow
+type ExportState int
+
+const (
+	ExportStatePick ExportState = iota
+	ExportStateExporting
+	ExportStateSuccess
+	ExportStateError
+)
+
+// exportLookback is how many recent saves can be exported
+const exportLookback = 20
+
+// ExportModel writes the project, as it is now or at an earlier save, to a
+// zip or tar.gz on disk. It's a backup that doesn't need GitHub.
+type ExportModel struct {
+	saves  []git.CommitInfo
+	cursor int // 0 is the current files, then saves newest first
+	format string
+	dir    string
+	path   string
+	state  ExportState
+	err    error
+	width  int
+	height int
+}
+
+// NewExportModel creates a new export model
+func NewExportModel() ExportModel {
+	cfg, _ := config.Load()
+	m := ExportModel{format: cfg.ExportFormat, state: ExportStatePick}
+	m.dir, m.err = cfg.ExportDirectory()
+	if m.err != nil {
+		m.state = ExportStateError
+	}
+	m.saves, _ = git.Log(0, exportLookback)
+	return m
+}
+
+// Init initializes the model
+func (m ExportModel) Init() tea.Cmd {
+	return nil
+}
+
+// ExportMsg is sent when an export completes
+type ExportMsg struct {
+	Path string
+	Err  error
+}
+
+// doExport writes the archive. An empty ref exports the current files.
+func doExport(ref, dir, format string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := git.ExportArchive(ref, dir, format)
+		return ExportMsg{Path: path, Err: err}
+	}
+}
+
+// Update handles messages
+func (m ExportModel) Update(msg tea.Msg) (ExportModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case ExportMsg:
+		if msg.Err != nil {
+			m.state = ExportStateError
+			m.err = msg.Err
+		} else {
+			m.state = ExportStateSuccess
+			m.path = msg.Path
+		}
+
+	case tea.KeyMsg:
+		if m.state != ExportStatePick {
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < len(m.saves) {
+				m.cursor++
+			}
+		case msg.String() == "f":
+			// Just for this export; the default is in settings
+			if m.format == config.ExportZip {
+				m.format = config.ExportTarGz
+			} else {
+				m.format = config.ExportZip
+			}
+		case key.Matches(msg, keys.Enter):
+			ref := ""
+			if m.cursor > 0 {
+				ref = m.saves[m.cursor-1].FullHash
+			}
+			m.state = ExportStateExporting
+			return m, doExport(ref, m.dir, m.format)
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the export screen
+func (m ExportModel) View() string {
+	var s string
+
+	s += RenderTitle("Export Backup") + "\n\n"
+
+	switch m.state {
+	case ExportStatePick:
+		s += RenderSubtitle("What do you want to export?") + "\n\n"
+		maxVisible := m.visibleRows()
+		start := max(0, m.cursor-maxVisible+1)
+		for i := start; i <= len(m.saves) && i < start+maxVisible; i++ {
+			cursor := "  "
+			style := ListItemStyle
+			if i == m.cursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			if i == 0 {
+				s += cursor + style.Render("My files right now") + " " + MutedStyle.Render("including unsaved changes") + "\n"
+				continue
+			}
+			save := m.saves[i-1]
+			s += cursor + style.Render(truncateLine(save.Message, 50)) + " " + MutedStyle.Render(save.Timestamp) + "\n"
+		}
+		s += "\n"
+		s += RenderMuted("Saved as a ") + HighlightStyle.Render(m.format) + RenderMuted(" in ") + HighlightStyle.Render(m.dir) + "\n"
+		s += RenderMuted("Ignored files aren't included.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case ExportStateExporting:
+		s += RenderMuted("Exporting...") + "\n"
+
+	case ExportStateSuccess:
+		s += RenderSuccess("✓ Backup exported") + "\n\n"
+		s += RenderMuted("Saved to:") + "\n"
+		s += HighlightStyle.Render(m.path) + "\n\n"
+		s += RenderMuted("Copy it to a USB stick or cloud folder to keep it safe.") + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case ExportStateError:
+		s += RenderError("✗ Couldn't export a backup") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// visibleRows is how many rows fit in the list
+func (m ExportModel) visibleRows() int {
+	if m.height > 0 {
+		return max(5, m.height-16)
+	}
+	return 12
+}
+
+// Keys returns the keys the export screen takes in its current state
+func (m ExportModel) Keys() Keys {
+	if m.state == ExportStatePick {
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "export"), hint("f", "zip/tar.gz"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	}
+	return Keys{}
+}
+
+// IsDone returns true once the export finished or failed
+func (m ExportModel) IsDone() bool {
+	return m.state == ExportStateSuccess || m.state == ExportStateError
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"smooth/config"
+)
+
+// fancyAnimations is on when the user wants screens typed out as they open
+// and success checkmarks popped in, on top of the usual animations
+var fancyAnimations bool
+
+func init() {
+	cfg, _ := config.Load()
+	SetFancyAnimations(cfg.FancyAnimations)
+}
+
+// SetFancyAnimations turns fancy animations on or off
+func SetFancyAnimations(on bool) {
+	fancyAnimations = on
+}
+
+// slowTerminal is set when the terminal can't keep up with fancy
+// animations: over SSH, on a dumb terminal, or once frames start arriving
+// late. Fancy animations stay off for the rest of the session.
+var slowTerminal = os.Getenv("SSH_CONNECTION") != "" || os.Getenv("TERM") == "dumb"
+
+// lateFrames counts fancy animation frames that arrived well after they
+// were due, and maxLateFrames is how many it takes to call the terminal slow
+var lateFrames int
+
+const maxLateFrames = 3
+
+// noteFrame checks how late a frame sent at sent arrived. Drawing a frame
+// holds up the next one, so frames that keep arriving late mean the
+// terminal is drawing slower than the animation.
+func noteFrame(sent time.Time) {
+	if time.Since(sent) <= animFrameTime {
+		return
+	}
+	lateFrames++
+	if lateFrames >= maxLateFrames {
+		slowTerminal = true
+	}
+}
+
+// FancyAnimations checks if fancy animations should play. They need the
+// usual animations to be on too, and a terminal fast enough to draw them.
+func FancyAnimations() bool {
+	return fancyAnimations && !slowTerminal && animationsEnabled()
+}
+
+// typewriterFrames is how many frames a screen takes to be typed out
+const typewriterFrames = 8
+
+// typewrite shows only the start of the view, as if it were being typed
+// out: the first lines whole, then one cut short, and the rest blank so
+// the screen keeps its height. Typing eases out, so most of the screen is
+// there in the first frames.
+func typewrite(view string, remaining int) string {
+	lines := strings.Split(view, "\n")
+	total := 0
+	for _, line := range lines {
+		total += displayWidth(line)
+	}
+	shown := total - total*remaining*remaining/(typewriterFrames*typewriterFrames)
+
+	for i, line := range lines {
+		width := displayWidth(line)
+		switch {
+		case shown >= width:
+			shown -= width
+		case shown > 0:
+			lines[i] = ansi.Truncate(line, shown, "")
+			shown = 0
+		default:
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckmarkFrameMsg advances a success checkmark popping in
+type CheckmarkFrameMsg struct {
+	sent time.Time
+}
+
+// checkmarkFrames grow into the ✓ of a newly shown success message
+var checkmarkFrames = []string{"·", "•", "●", "✔"}
+
+// Checkmark pops in the checkmark of a success message as it's shown. The
+// zero value shows checkmarks as they are.
+type Checkmark struct {
+	line      string // the success message last seen on screen
+	remaining int    // frames left before the checkmark settles
+}
+
+// checkmarkTick schedules the next checkmark frame
+func checkmarkTick() tea.Cmd {
+	return tea.Tick(animFrameTime, func(t time.Time) tea.Msg {
+		return CheckmarkFrameMsg{sent: t}
+	})
+}
+
+// Watch starts popping in the checkmark when view shows a success message
+// it didn't before
+func (c Checkmark) Watch(view string) (Checkmark, tea.Cmd) {
+	_, line := successLine(strings.Split(view, "\n"))
+	if line == c.line {
+		return c, nil
+	}
+	c.line = line
+	c.remaining = 0
+	if line == "" || !FancyAnimations() {
+		return c, nil
+	}
+	c.remaining = len(checkmarkFrames)
+	return c, checkmarkTick()
+}
+
+// Update advances the checkmark
+func (c Checkmark) Update(msg tea.Msg) (Checkmark, tea.Cmd) {
+	frame, ok := msg.(CheckmarkFrameMsg)
+	if !ok || c.remaining <= 0 {
+		return c, nil
+	}
+	noteFrame(frame.sent)
+	c.remaining--
+	if c.remaining > 0 && !slowTerminal {
+		return c, checkmarkTick()
+	}
+	c.remaining = 0
+	return c, nil
+}
+
+// Apply draws the success message's checkmark as far as it has grown
+func (c Checkmark) Apply(view string) string {
+	if c.remaining <= 0 {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	i, _ := successLine(lines)
+	if i < 0 {
+		return view
+	}
+	frame := checkmarkFrames[len(checkmarkFrames)-c.remaining]
+	lines[i] = strings.Replace(lines[i], "✓", frame, 1)
+	return strings.Join(lines, "\n")
+}
+
+// successLine finds the first line that starts with a ✓, returning where
+// it is and its text, or -1 and "" if there isn't one
+func successLine(lines []string) (int, string) {
+	for i, line := range lines {
+		text := strings.TrimSpace(ansi.Strip(line))
+		// Inside a box the line is between the borders
+		text = strings.TrimSpace(strings.Trim(text, "│┃║"))
+		if strings.HasPrefix(text, "✓ ") {
+			return i, text
+		}
+	}
+	return -1, ""
+}
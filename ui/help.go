@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Keys are the keys a screen takes in its current state. The help bar at
+// the bottom of the screen hints at the main ones, and ? lists them all.
+type Keys struct {
+	Bar    []key.Binding // hinted in the help bar
+	More   []key.Binding // only listed by ?
+	Typing bool          // a text box has the keys, so ? is typed rather than showing help
+}
+
+// ShortHelp returns the keys hinted in the help bar
+func (k Keys) ShortHelp() []key.Binding {
+	return k.Bar
+}
+
+// FullHelp returns every key, the help bar's first
+func (k Keys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{append(append([]key.Binding{}, k.Bar...), k.More...)}
+}
+
+// hint is a key for the help: the key as it's shown, and what it does
+func hint(k, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(k), key.WithHelp(k, desc))
+}
+
+// vimKeys are the letter keys that move through lists as well as the arrows
+var vimKeys = hint("k/j", "up/down")
+
+// newHelp returns a help view in the theme's colors
+func newHelp() help.Model {
+	h := help.New()
+	h.ShortSeparator = "  ·  "
+	h.FullSeparator = "    "
+	keyStyle := lipgloss.NewStyle().Foreground(ColorSecondary)
+	h.Styles.ShortKey = keyStyle
+	h.Styles.FullKey = keyStyle
+	h.Styles.ShortDesc = MutedStyle
+	h.Styles.FullDesc = MutedStyle
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(ColorMuted)
+	h.Styles.FullSeparator = lipgloss.NewStyle().Foreground(ColorMuted)
+	return h
+}
+
+// HelpBar renders the hints for a screen's main keys
+func HelpBar(keys Keys) string {
+	return lipgloss.NewStyle().
+		MarginTop(1).
+		Render(newHelp().ShortHelpView(keys.ShortHelp()))
+}
+
+// globalKeys are the keys that work from every screen
+var globalKeys = []key.Binding{
+	hint("?", "show or hide these keys"),
+	hint("esc", "go back"),
+	hint("ctrl+s", "quick save, from the menu"),
+	hint("q", "quit, from the menu"),
+}
+
+// HelpOverlay renders every key the current screen takes, and the ones that
+// work everywhere
+func HelpOverlay(keys Keys) string {
+	h := newHelp()
+	var s string
+	s += RenderTitle("Keys") + "\n\n"
+	if len(keys.Bar)+len(keys.More) > 0 {
+		s += RenderSubtitle("On this screen:") + "\n\n"
+		s += h.FullHelpView(keys.FullHelp()) + "\n\n"
+	} else {
+		s += RenderMuted("Nothing to pick here: any key carries on.") + "\n\n"
+	}
+	s += RenderSubtitle("Anywhere:") + "\n\n"
+	s += h.FullHelpView([][]key.Binding{globalKeys}) + "\n"
+	s += HelpText("Press any key to close")
+	return BoxStyle.Render(s)
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"smooth/config"
+	"smooth/platform"
+)
+
+// Code in diffs is colored by chroma. Diffs only show fragments of a file,
+// so each line is highlighted on its own.
+
+// codeStyles caches a chroma style for each color diff lines are drawn in,
+// made from the theme the first time it's needed. ApplyTheme clears it.
+var codeStyles = map[lipgloss.TerminalColor]*chroma.Style{}
+
+// syntaxFor picks the lexer for a file from its name, or nil if chroma
+// doesn't know the language
+func syntaxFor(path string) chroma.Lexer {
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		return nil
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// syntaxHighlightEnabled checks if the user wants code in diffs colored.
+// Terminals with few colors can turn it off in Settings.
+func syntaxHighlightEnabled() bool {
+	cfg, _ := config.Load()
+	return !cfg.DisableSyntaxHighlight
+}
+
+// codeFormatter picks a formatter for as many colors as the terminal
+// shows, or nil if it shows none
+func codeFormatter() chroma.Formatter {
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor, termenv.ANSI256:
+		return formatters.TTY256
+	case termenv.ANSI:
+		return formatters.TTY16
+	}
+	return nil
+}
+
+// codeStyle returns the chroma style for code shown in plain: keywords,
+// strings, numbers and comments in the theme's colors, and everything else
+// in plain's
+func codeStyle(plain lipgloss.TerminalColor) *chroma.Style {
+	if style, ok := codeStyles[plain]; ok {
+		return style
+	}
+	base, _ := plain.(lipgloss.Color)
+	style, err := chroma.NewStyle("smooth", chroma.StyleEntries{
+		chroma.Background:    string(base),
+		chroma.Keyword:       "bold " + string(ColorSecondary),
+		chroma.NameBuiltin:   string(ColorSecondary),
+		chroma.LiteralString: string(ColorHighlight),
+		chroma.LiteralNumber: string(ColorAccent),
+		chroma.Comment:       "italic " + string(ColorMuted),
+	})
+	if err != nil {
+		style = nil
+	}
+	codeStyles[plain] = style
+	return style
+}
+
+// renderDiffLine colors one line of a diff, truncated to width. Added and
+// removed lines keep their green and red, with the code in them
+// highlighted if lang is set.
+func renderDiffLine(line string, lang chroma.Lexer, width int) string {
+	// Lines with Windows line endings end in \r, which would garble the
+	// terminal; show a marker instead so changes to just the line endings
+	// are still visible
+	line, crlf := platform.TrimCR(line)
+	display := truncateLine(line, width)
+
+	var out string
+	switch {
+	case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+		out = renderDiffCode(display, lang, SuccessStyle)
+	case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+		out = renderDiffCode(display, lang, ErrorStyle)
+	case strings.HasPrefix(line, "@@"):
+		out = HighlightStyle.Render(display)
+	case strings.HasPrefix(line, " "):
+		out = renderDiffCode(display, lang, MutedStyle)
+	default:
+		out = MutedStyle.Render(display)
+	}
+	if crlf {
+		out += MutedStyle.Render("␍")
+	}
+	return out
+}
+
+// renderDiffCode renders a diff line's +, - or space in style and the
+// code after it highlighted
+func renderDiffCode(line string, lang chroma.Lexer, style lipgloss.Style) string {
+	if lang == nil || line == "" {
+		return style.Render(line)
+	}
+	return style.Render(line[:1]) + highlightCode(line[1:], lang, style)
+}
+
+// highlightCode colors a line of code for the terminal, with anything
+// chroma has no color for rendered in plain
+func highlightCode(code string, lang chroma.Lexer, plain lipgloss.Style) string {
+	formatter := codeFormatter()
+	style := codeStyle(plain.GetForeground())
+	if formatter == nil || style == nil {
+		return plain.Render(code)
+	}
+	tokens, err := lang.Tokenise(nil, code)
+	if err != nil {
+		return plain.Render(code)
+	}
+	var b strings.Builder
+	if err := formatter.Format(&b, style, tokens); err != nil {
+		return plain.Render(code)
+	}
+	// Lexers end a line they're given with a newline
+	return strings.TrimSuffix(b.String(), "\n")
+}
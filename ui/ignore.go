@@ -0,0 +1,301 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// IgnoreState represents the state of the .gitignore manager
+type IgnoreState int
+
+const (
+	IgnoreStateList IgnoreState = iota
+	IgnoreStateConfirmRemove
+	IgnoreStateConfirmCleanup
+	IgnoreStateEmpty
+	IgnoreStateError
+)
+
+// IgnoreModel is the model for the .gitignore manager screen
+type IgnoreModel struct {
+	patterns []git.GitignorePattern
+	matches  map[int][]string // .gitignore line -> changed and ignored files it matches
+	cursor   int
+	state    IgnoreState
+	err      error
+	message  string
+	width    int
+	height   int
+}
+
+// NewIgnoreModel creates a new .gitignore manager model
+func NewIgnoreModel() IgnoreModel {
+	m := IgnoreModel{}
+	m.reload()
+	return m
+}
+
+// reload re-reads .gitignore and recomputes which files each pattern
+// matches: the changed ones, and the ones it's keeping out of Save, which
+// Save never lists
+func (m *IgnoreModel) reload() {
+	patterns, err := git.ReadGitignore()
+	if err != nil {
+		m.state = IgnoreStateError
+		m.err = err
+		return
+	}
+	m.patterns = patterns
+
+	var paths []string
+	if changes, err := git.GetChangeSummary(); err == nil {
+		for _, c := range changes {
+			paths = append(paths, c.Path)
+		}
+	}
+	if ignored, err := git.IgnoredFiles(); err == nil {
+		paths = append(paths, ignored...)
+	}
+	m.matches = git.GitignoreMatches(paths)
+
+	m.state = IgnoreStateList
+	if len(m.patterns) == 0 {
+		m.state = IgnoreStateEmpty
+	}
+	if m.cursor >= len(m.patterns) {
+		m.cursor = max(0, len(m.patterns)-1)
+	}
+}
+
+// Init initializes the model
+func (m IgnoreModel) Init() tea.Cmd {
+	return nil
+}
+
+// duplicateLines returns the line numbers of every duplicate pattern
+func (m IgnoreModel) duplicateLines() []int {
+	var lines []int
+	for _, p := range m.patterns {
+		if p.DuplicateOf != 0 {
+			lines = append(lines, p.Line)
+		}
+	}
+	return lines
+}
+
+// Update handles messages
+func (m IgnoreModel) Update(msg tea.Msg) (IgnoreModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case IgnoreStateList:
+			m.message = ""
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(m.patterns)-1 {
+					m.cursor++
+				}
+			case msg.String() == "d" || msg.String() == "delete" || msg.String() == "backspace":
+				m.state = IgnoreStateConfirmRemove
+			case msg.String() == "c":
+				if len(m.duplicateLines()) > 0 {
+					m.state = IgnoreStateConfirmCleanup
+				}
+			}
+
+		case IgnoreStateConfirmRemove:
+			switch msg.String() {
+			case "y", "Y":
+				pattern := m.patterns[m.cursor]
+				if err := git.RemoveGitignoreLines([]int{pattern.Line}); err != nil {
+					m.state = IgnoreStateError
+					m.err = err
+					return m, nil
+				}
+				m.reload()
+				m.message = fmt.Sprintf("Removed %q", pattern.Pattern)
+			case "n", "N", "esc":
+				m.state = IgnoreStateList
+			}
+
+		case IgnoreStateConfirmCleanup:
+			switch msg.String() {
+			case "y", "Y":
+				dups := m.duplicateLines()
+				if err := git.RemoveGitignoreLines(dups); err != nil {
+					m.state = IgnoreStateError
+					m.err = err
+					return m, nil
+				}
+				m.reload()
+				m.message = fmt.Sprintf("Removed %d duplicate pattern(s)", len(dups))
+			case "n", "N", "esc":
+				m.state = IgnoreStateList
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the .gitignore manager
+func (m IgnoreModel) View() string {
+	var s string
+
+	s += RenderTitle("Ignored Files") + "\n\n"
+
+	switch m.state {
+	case IgnoreStateEmpty:
+		s += RenderMuted("Your .gitignore has no patterns yet.") + "\n\n"
+		s += RenderMuted("Mark a file as IGNR on the Save screen to add one.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case IgnoreStateError:
+		s += RenderError("✗ Couldn't update .gitignore") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+
+	case IgnoreStateList:
+		s += RenderSubtitle("Patterns in .gitignore:") + "\n\n"
+		s += SideBySide("  ", m.renderPatternList(), m.renderMatchesPanel()) + "\n\n"
+
+		if m.message != "" {
+			s += RenderSuccess("✓ "+m.message) + "\n\n"
+		}
+
+		s += HelpBar(m.Keys())
+
+	case IgnoreStateConfirmRemove:
+		pattern := m.patterns[m.cursor]
+		s += "Remove pattern: " + HighlightStyle.Render(pattern.Pattern) + "\n\n"
+		if matched := m.matches[pattern.Line]; len(matched) > 0 {
+			s += RenderMuted(plural(len(matched), "file")+" it matches will show up in Save again.") + "\n\n"
+		}
+		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+
+	case IgnoreStateConfirmCleanup:
+		s += fmt.Sprintf("Remove %d duplicate pattern(s)?", len(m.duplicateLines())) + "\n\n"
+		s += RenderMuted("The first copy of each pattern is kept.") + "\n\n"
+		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderPatternList renders the left panel with every pattern
+func (m IgnoreModel) renderPatternList() string {
+	maxVisible := 15
+	if m.height > 0 {
+		maxVisible = m.height - 14
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+	}
+
+	start := 0
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+
+	var lines []string
+	for i := start; i < len(m.patterns) && i < start+maxVisible; i++ {
+		p := m.patterns[i]
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.cursor {
+			cursor = MenuCursorStyle.Render("> ")
+			style = ListItemSelectedStyle
+		}
+
+		line := cursor + style.Render(truncateLine(p.Pattern, 30))
+		if p.DuplicateOf != 0 {
+			line += " " + ErrorStyle.Render(fmt.Sprintf("duplicate of line %d", p.DuplicateOf))
+		} else if n := len(m.matches[p.Line]); n > 0 {
+			line += " " + MutedStyle.Render("("+plural(n, "file")+")")
+		}
+		lines = append(lines, line)
+	}
+
+	if len(m.patterns) > maxVisible {
+		lines = append(lines, MutedStyle.Render(fmt.Sprintf("  ... %d total patterns", len(m.patterns))))
+	}
+
+	return lipgloss.NewStyle().Width(55).Render(strings.Join(lines, "\n"))
+}
+
+// renderMatchesPanel renders the files matched by the selected pattern
+func (m IgnoreModel) renderMatchesPanel() string {
+	panelStyle := PanelStyle(ColorSecondary).
+		Padding(0, 1).
+		Width(40)
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent)
+
+	var lines []string
+	lines = append(lines, titleStyle.Render("Matches"), "")
+
+	if len(m.patterns) == 0 {
+		return panelStyle.Render(strings.Join(lines, "\n"))
+	}
+
+	p := m.patterns[m.cursor]
+	lines = append(lines, MutedStyle.Render(fmt.Sprintf("Line %d of .gitignore", p.Line)), "")
+
+	matched := m.matches[p.Line]
+	if len(matched) == 0 {
+		lines = append(lines, MutedStyle.Render("No files here match this pattern."))
+	} else {
+		lines = append(lines, MutedStyle.Render("Files it matches:"), "")
+		for i, path := range matched {
+			if i >= 8 {
+				lines = append(lines, MutedStyle.Render(fmt.Sprintf("  ... and %d more", len(matched)-8)))
+				break
+			}
+			lines = append(lines, "  "+truncateLine(path, 34))
+		}
+	}
+
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// Keys returns the keys the ignore screen takes in its current state
+func (m IgnoreModel) Keys() Keys {
+	switch m.state {
+	case IgnoreStateList:
+		bar := []key.Binding{hint("↑↓", "navigate"), hint("d", "remove")}
+		if dups := len(m.duplicateLines()); dups > 0 {
+			bar = append(bar, hint("c", fmt.Sprintf("remove %d duplicate(s)", dups)))
+		}
+		return Keys{
+			Bar:  append(bar, hint("esc", "back")),
+			More: []key.Binding{vimKeys, hint("delete", "remove")},
+		}
+	case IgnoreStateConfirmRemove:
+		return Keys{More: []key.Binding{hint("y", "remove the pattern"), hint("n", "no, keep it")}}
+	case IgnoreStateConfirmCleanup:
+		return Keys{More: []key.Binding{hint("y", "remove the duplicates"), hint("n", "no, keep them")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if there is nothing to interact with
+func (m IgnoreModel) IsDone() bool {
+	return m.state == IgnoreStateEmpty || m.state == IgnoreStateError
+}
@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -17,9 +20,27 @@ type InitChoice int
 const (
 	InitChoiceNone InitChoice = iota
 	InitChoiceInit
+	InitChoiceClone
 	InitChoiceExit
 )
 
+// initStep is where the user is in the init or clone flow
+type initStep int
+
+const (
+	initStepChoose initStep = iota
+	initStepSetup
+	initStepIdentity
+	initStepCloneURL
+	initStepCloning
+)
+
+// Setup options offered after choosing to initialize git
+const (
+	initOptionFirstSave = iota
+	initOptionGitignore
+)
+
 // InitModel is the model for the "not a git repository" prompt
 type InitModel struct {
 	cursor    int
@@ -29,16 +50,213 @@ type InitModel struct {
 	done      bool
 	choice    InitChoice
 	initError string
+	step      initStep
+	urlInput  textinput.Model
+	cloneDir  string
+	cloneErr  string
+	progress  git.Progress
+	updates   chan tea.Msg // progress and the result of a running clone
+	showKeys  bool
+
+	setupCursor   int
+	firstSave     bool
+	gitignore     bool
+	hasGitignore  bool // the folder already has one, which is kept
+	needName      bool // git doesn't know who's saving yet
+	needEmail     bool
+	nameInput     textinput.Model
+	emailInput    textinput.Model
+	identityFocus int // 0 is the name, 1 the email
 }
 
 // NewInitModel creates a new init model
 func NewInitModel() InitModel {
 	cwd, _ := os.Getwd()
+
+	ti := textinput.New()
+	ti.Placeholder = "https://github.com/you/project or you/project"
+	ti.CharLimit = 300
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	newInput := func(placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.CharLimit = 100
+		input.Width = 40
+		input.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+		input.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+		return input
+	}
+
+	name, email := git.Identity()
+	hasGitignore := git.HasGitignore()
+
 	return InitModel{
-		cursor: 0,
-		cwd:    cwd,
-		width:  80,
-		height: 24,
+		cursor:       0,
+		cwd:          cwd,
+		width:        80,
+		height:       24,
+		urlInput:     ti,
+		firstSave:    true,
+		gitignore:    !hasGitignore,
+		hasGitignore: hasGitignore,
+		needName:     name == "",
+		needEmail:    email == "",
+		nameInput:    newInput("Your Name"),
+		emailInput:   newInput("you@example.com"),
+	}
+}
+
+// initRepo initializes git with the chosen setup, finishing the prompt
+func (m InitModel) initRepo() InitModel {
+	opts := git.NewRepoOptions{
+		FirstSave: m.firstSave,
+		Gitignore: m.gitignore && !m.hasGitignore,
+	}
+	if m.needName {
+		opts.Name = m.nameInput.Value()
+	}
+	if m.needEmail {
+		opts.Email = m.emailInput.Value()
+	}
+
+	m.done = true
+	if err := git.InitRepo(opts); err != nil {
+		m.initError = err.Error()
+		m.choice = InitChoiceExit
+	} else {
+		m.choice = InitChoiceInit
+	}
+	return m
+}
+
+// focusIdentity focuses the name or email input
+func (m InitModel) focusIdentity(field int) (InitModel, tea.Cmd) {
+	m.identityFocus = field
+	if field == 0 {
+		m.emailInput.Blur()
+		return m, m.nameInput.Focus()
+	}
+	m.nameInput.Blur()
+	return m, m.emailInput.Focus()
+}
+
+// updateSetup handles keys while choosing how to set up the new project
+func (m InitModel) updateSetup(msg tea.KeyMsg) (InitModel, tea.Cmd) {
+	switch {
+	case msg.String() == "?":
+		m.showKeys = true
+	case key.Matches(msg, keys.Up):
+		if m.setupCursor > 0 {
+			m.setupCursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.setupCursor < initOptionGitignore && !m.hasGitignore {
+			m.setupCursor++
+		}
+	case msg.String() == " ":
+		if m.setupCursor == initOptionFirstSave {
+			m.firstSave = !m.firstSave
+		} else {
+			m.gitignore = !m.gitignore
+		}
+	case key.Matches(msg, keys.Enter):
+		// Saving fails until git knows who's saving, so ask first
+		if m.needName || m.needEmail {
+			m.step = initStepIdentity
+			if m.needName {
+				return m.focusIdentity(0)
+			}
+			return m.focusIdentity(1)
+		}
+		return m.initRepo(), nil
+	case msg.String() == "esc":
+		m.step = initStepChoose
+	case msg.String() == "q" || msg.String() == "ctrl+c":
+		m.done = true
+		m.choice = InitChoiceExit
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// updateIdentity handles keys while asking for the name and email saves
+// are made under
+func (m InitModel) updateIdentity(msg tea.KeyMsg) (InitModel, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "shift+tab", "up", "down":
+		if m.needName && m.needEmail {
+			return m.focusIdentity(1 - m.identityFocus)
+		}
+		return m, nil
+	case "enter":
+		if m.identityFocus == 0 && strings.TrimSpace(m.nameInput.Value()) == "" {
+			return m, nil
+		}
+		if m.identityFocus == 0 && m.needEmail {
+			return m.focusIdentity(1)
+		}
+		if m.needEmail && strings.TrimSpace(m.emailInput.Value()) == "" {
+			return m, nil
+		}
+		if m.needName && strings.TrimSpace(m.nameInput.Value()) == "" {
+			return m.focusIdentity(0)
+		}
+		m.nameInput.Blur()
+		m.emailInput.Blur()
+		return m.initRepo(), nil
+	case "esc":
+		m.nameInput.Blur()
+		m.emailInput.Blur()
+		m.step = initStepSetup
+		return m, nil
+	case "ctrl+c":
+		m.done = true
+		m.choice = InitChoiceExit
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	if m.identityFocus == 0 {
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	} else {
+		m.emailInput, cmd = m.emailInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// cloneProgressMsg is sent as git reports how far a clone has got
+type cloneProgressMsg git.Progress
+
+// cloneDoneMsg is sent when a clone finishes
+type cloneDoneMsg struct {
+	err error
+}
+
+// startClone runs the clone in the background, sending progress and the
+// result to updates
+func startClone(url, dir string, updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		go func() {
+			err := git.Clone(url, dir, func(p git.Progress) {
+				// Drop updates the screen hasn't caught up with
+				select {
+				case updates <- cloneProgressMsg(p):
+				default:
+				}
+			})
+			updates <- cloneDoneMsg{err: err}
+		}()
+		return waitForClone(updates)()
+	}
+}
+
+// waitForClone waits for the next update from a running clone
+func waitForClone(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
 	}
 }
 
@@ -54,33 +272,97 @@ func (m InitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
+	case cloneProgressMsg:
+		m.progress = git.Progress(msg)
+		return m, waitForClone(m.updates)
+
+	case cloneDoneMsg:
+		if msg.err == nil {
+			// Carry on in the new project, as if smooth had started there
+			msg.err = os.Chdir(m.cloneDir)
+		}
+		if msg.err != nil {
+			// Back to the address, which is most likely what's wrong
+			m.cloneErr = msg.err.Error()
+			m.progress = git.Progress{}
+			m.step = initStepCloneURL
+			m.urlInput.Focus()
+			return m, textinput.Blink
+		}
+		m.done = true
+		m.choice = InitChoiceClone
+		return m, nil
+
 	case tea.KeyMsg:
 		if m.done {
 			return m, tea.Quit
 		}
+		if m.showKeys {
+			m.showKeys = false
+			return m, nil
+		}
+
+		switch m.step {
+		case initStepSetup:
+			return m.updateSetup(msg)
+		case initStepIdentity:
+			return m.updateIdentity(msg)
+		case initStepCloning:
+			return m, nil
+		case initStepCloneURL:
+			switch msg.String() {
+			case "enter":
+				url := strings.TrimSpace(m.urlInput.Value())
+				if url == "" {
+					return m, nil
+				}
+				dir, err := git.CloneDir(url)
+				if err != nil {
+					m.cloneErr = err.Error()
+					return m, nil
+				}
+				m.cloneDir = filepath.Join(m.cwd, dir)
+				m.cloneErr = ""
+				m.updates = make(chan tea.Msg, 1)
+				m.urlInput.Blur()
+				m.step = initStepCloning
+				return m, startClone(url, m.cloneDir, m.updates)
+			case "esc":
+				m.urlInput.Blur()
+				m.step = initStepChoose
+				return m, nil
+			case "ctrl+c":
+				m.done = true
+				m.choice = InitChoiceExit
+				return m, tea.Quit
+			}
+			var cmd tea.Cmd
+			m.urlInput, cmd = m.urlInput.Update(msg)
+			return m, cmd
+		}
 
 		switch {
+		case msg.String() == "?":
+			m.showKeys = true
 		case key.Matches(msg, keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
 			}
 		case key.Matches(msg, keys.Down):
-			if m.cursor < 1 {
+			if m.cursor < 2 {
 				m.cursor++
 			}
 		case key.Matches(msg, keys.Enter):
 			if m.cursor == 0 {
-				// Initialize git
-				_, err := git.Run("init")
-				if err != nil {
-					m.initError = err.Error()
-					m.done = true
-					m.choice = InitChoiceExit
-				} else {
-					m.done = true
-					m.choice = InitChoiceInit
-				}
+				// Choose how to set up the project before initializing git
+				m.step = initStepSetup
+				m.setupCursor = initOptionFirstSave
 				return m, nil
+			} else if m.cursor == 1 {
+				// Clone a project into a new folder here
+				m.step = initStepCloneURL
+				m.urlInput.Focus()
+				return m, textinput.Blink
 			} else {
 				// Exit immediately
 				m.done = true
@@ -96,8 +378,16 @@ func (m InitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// View renders the prompt
+// View renders the prompt, as plain text in accessible mode
 func (m InitModel) View() string {
+	if accessibleMode {
+		return Plain(m.render())
+	}
+	return StripEmoji(m.render())
+}
+
+// render draws the prompt
+func (m InitModel) render() string {
 	var content string
 
 	// Show a smaller banner for this screen
@@ -118,7 +408,29 @@ func (m InitModel) View() string {
 
 	// Success state
 	if m.done && m.choice == InitChoiceInit {
-		content += SuccessStyle.Render("✓ Git initialized successfully!") + "\n\n"
+		content += SuccessStyle.Render("✓ Git initialized on "+git.DefaultBranch) + "\n"
+		if m.gitignore && !m.hasGitignore {
+			content += SuccessStyle.Render("✓ Added a starter .gitignore") + "\n"
+		}
+		if m.firstSave {
+			content += SuccessStyle.Render("✓ Made the first save") + "\n"
+		}
+		if m.needName || m.needEmail {
+			name, email := git.Identity()
+			content += SuccessStyle.Render("✓ Saving as ") + HighlightStyle.Render(name+" <"+email+">") + "\n"
+		}
+		content += "\n" + MutedStyle.Render("Press any key to continue...") + "\n"
+
+		return lipgloss.NewStyle().
+			Padding(2, 4).
+			Width(m.width).
+			Height(m.height).
+			Render(content)
+	}
+
+	if m.done && m.choice == InitChoiceClone {
+		content += SuccessStyle.Render("✓ Project cloned!") + "\n\n"
+		content += MutedStyle.Render("It's in ") + HighlightStyle.Render(m.cloneDir) + "\n\n"
 		content += MutedStyle.Render("Press any key to continue...") + "\n"
 
 		return lipgloss.NewStyle().
@@ -128,10 +440,20 @@ func (m InitModel) View() string {
 			Render(content)
 	}
 
+	if m.showKeys {
+		return lipgloss.NewStyle().Padding(2, 4).Render(HelpOverlay(m.Keys()))
+	}
+
+	if m.step == initStepSetup || m.step == initStepIdentity {
+		return m.renderSetup(content)
+	}
+
+	if m.step != initStepChoose {
+		return m.renderClone(content)
+	}
+
 	// Main prompt
-	warningBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ColorDanger).
+	warningBox := PanelStyle(ColorDanger).
 		Padding(1, 2).
 		Render(ErrorStyle.Render("⚠ Not a git repository"))
 
@@ -162,7 +484,8 @@ If you're in the wrong place, exit and navigate to your project.`
 		title string
 		desc  string
 	}{
-		{"Initialize git here", "Run 'git init' to start tracking this folder"},
+		{"Initialize git here", "Start tracking this folder on the main branch"},
+		{"Clone a project", "Download a project from GitHub into a new folder here"},
 		{"Exit", "I'm in the wrong folder"},
 	}
 
@@ -181,11 +504,7 @@ If you're in the wrong place, exit and navigate to your project.`
 	}
 
 	// Help bar
-	helpBar := HelpBar([][]string{
-		{"↑↓", "navigate"},
-		{"enter", "select"},
-		{"q", "quit"},
-	})
+	helpBar := HelpBar(m.Keys())
 
 	// Center help bar
 	centeredHelp := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, helpBar)
@@ -206,6 +525,128 @@ If you're in the wrong place, exit and navigate to your project.`
 	return lipgloss.JoinVertical(lipgloss.Left, placedContent, centeredHelp)
 }
 
+// renderSetup renders the choices for setting up the new project, then
+// asks who's saving if git doesn't know yet
+func (m InitModel) renderSetup(content string) string {
+	content += RenderTitle("Initialize git here") + "\n\n"
+
+	if m.step == initStepSetup {
+		content += RenderSubtitle("Set up the project") + "\n\n"
+		options := []struct {
+			title string
+			desc  string
+			on    bool
+		}{
+			{"Make the first save", "Save what's in the folder now, so there's history to build on", m.firstSave},
+			{"Add a starter .gitignore", "Keep dependencies, secrets and editor files out of saves", m.gitignore},
+		}
+		if m.hasGitignore {
+			options = options[:1]
+		}
+		for i, opt := range options {
+			cursor := "  "
+			style := MenuItemStyle
+			if m.setupCursor == i {
+				cursor = MenuCursorStyle.Render("> ")
+				style = MenuItemSelectedStyle
+			}
+			check := "[ ]"
+			if opt.on {
+				check = "[✓]"
+			}
+			content += cursor + style.Render(check+" "+opt.title) + "\n"
+			content += "      " + MutedStyle.Render(opt.desc) + "\n\n"
+		}
+		content += MutedStyle.Render("Saves go on the ") + HighlightStyle.Render(git.DefaultBranch) + MutedStyle.Render(" branch.") + "\n"
+	} else {
+		content += RenderSubtitle("Who's saving?") + "\n\n"
+		content += MutedStyle.Render("Git puts a name and email on every save. They're kept in") + "\n"
+		content += MutedStyle.Render("git's settings, for your other projects too.") + "\n\n"
+		if m.needName {
+			content += "Name   " + m.nameInput.View() + "\n"
+		}
+		if m.needEmail {
+			content += "Email  " + m.emailInput.View() + "\n"
+		}
+		content += "\n" + MutedStyle.Render("Use the email you signed up to GitHub with, so saves link to you.") + "\n"
+	}
+
+	mainContent := lipgloss.NewStyle().
+		Padding(2, 4).
+		Render(content)
+	contentHeight := max(1, m.height-3)
+	placedContent := lipgloss.Place(m.width, contentHeight, lipgloss.Left, lipgloss.Top, mainContent)
+	return lipgloss.JoinVertical(lipgloss.Left, placedContent, lipgloss.PlaceHorizontal(m.width, lipgloss.Center, HelpBar(m.Keys())))
+}
+
+// renderClone renders the clone flow: asking for the project's address,
+// then how far the download has got
+func (m InitModel) renderClone(content string) string {
+	content += RenderTitle("Clone a project") + "\n\n"
+
+	var help string
+	if m.step == initStepCloneURL {
+		content += RenderSubtitle("Where's the project?") + "\n\n"
+		content += m.urlInput.View() + "\n\n"
+		if m.cloneErr != "" {
+			content += ErrorStyle.Render("✗ Couldn't clone the project") + "\n"
+			content += MutedStyle.Render(m.cloneErr) + "\n\n"
+		}
+		if dir, err := git.CloneDir(strings.TrimSpace(m.urlInput.Value())); err == nil {
+			content += MutedStyle.Render("It will go in ") + HighlightStyle.Render(filepath.Join(m.cwd, dir)) + "\n\n"
+		}
+		content += MutedStyle.Render("Copy the address from the green Code button on GitHub.") + "\n"
+		help = HelpBar(m.Keys())
+	} else {
+		content += MutedStyle.Render("Cloning into ") + HighlightStyle.Render(m.cloneDir) + "\n\n"
+		phase := "Connecting"
+		if m.progress.Phase != "" {
+			phase = m.progress.Phase
+		}
+		content += RenderProgressBar(float64(m.progress.Percent)/100, 40) + " " +
+			MutedStyle.Render(fmt.Sprintf("%s %d%%", phase, m.progress.Percent)) + "\n"
+		if details := m.progress.Details(); details != "" {
+			content += MutedStyle.Render(details) + "\n"
+		}
+	}
+
+	mainContent := lipgloss.NewStyle().
+		Padding(2, 4).
+		Render(content)
+	contentHeight := max(1, m.height-3)
+	placedContent := lipgloss.Place(m.width, contentHeight, lipgloss.Left, lipgloss.Top, mainContent)
+	return lipgloss.JoinVertical(lipgloss.Left, placedContent, lipgloss.PlaceHorizontal(m.width, lipgloss.Center, help))
+}
+
+// Keys returns the keys the prompt takes in its current step
+func (m InitModel) Keys() Keys {
+	switch m.step {
+	case initStepChoose:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("q", "quit")},
+			More: []key.Binding{vimKeys, hint("esc", "quit")},
+		}
+	case initStepSetup:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("space", "on/off"), hint("enter", "initialize"), hint("esc", "back")},
+			More: []key.Binding{vimKeys, hint("q", "quit")},
+		}
+	case initStepIdentity:
+		next := hint("enter", "initialize")
+		if m.identityFocus == 0 && m.needEmail {
+			next = hint("enter", "next")
+		}
+		bar := []key.Binding{next, hint("esc", "back")}
+		if m.needName && m.needEmail {
+			bar = []key.Binding{hint("tab", "switch"), next, hint("esc", "back")}
+		}
+		return Keys{Bar: bar, Typing: true}
+	case initStepCloneURL:
+		return Keys{Bar: []key.Binding{hint("enter", "clone"), hint("esc", "back")}, Typing: true}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the user has made a choice
 func (m InitModel) IsDone() bool {
 	return m.done
@@ -216,8 +657,9 @@ func (m InitModel) Choice() InitChoice {
 	return m.choice
 }
 
-// ShouldContinue returns true if git was initialized and the app should continue
+// ShouldContinue returns true if git was initialized, or a project was
+// cloned and is now the working directory, and the app should continue
 func (m InitModel) ShouldContinue() bool {
-	return m.done && m.choice == InitChoiceInit && m.initError == ""
+	return m.done && (m.choice == InitChoiceInit || m.choice == InitChoiceClone) && m.initError == ""
 }
 
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// Choices on the large files interstitial
+const (
+	largeChoiceLFS = iota
+	largeChoiceIgnore
+	largeChoiceSaveAnyway
+	largeChoiceBack
+)
+
+// LFSTrackMsg is sent when tracking large files with Git LFS completes
+type LFSTrackMsg struct {
+	Err error
+}
+
+// doTrackLFS tracks the files with Git LFS
+func doTrackLFS(files []git.LargeFile) tea.Cmd {
+	return func() tea.Msg {
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = f.Path
+		}
+		return LFSTrackMsg{Err: git.TrackWithLFS(paths)}
+	}
+}
+
+// checkLargeFiles stops the save at the large files interstitial if any file
+// being saved is over the configured size, otherwise carries on with it
+func (m SaveModel) checkLargeFiles(preview bool) (SaveModel, tea.Cmd) {
+	m.wantPreview = preview
+
+	var paths []string
+	for _, f := range m.files {
+		if f.Action == FileActionSave && f.Change.Status != "deleted" {
+			paths = append(paths, f.Change.Path)
+		}
+	}
+
+	cfg, _ := config.Load()
+	m.largeFiles = git.FindLargeFiles(paths, int64(cfg.LargeFileMB)<<20)
+	if len(m.largeFiles) == 0 {
+		return m.proceed()
+	}
+
+	m.state = SaveStateLargeFiles
+	m.largeChoice = largeChoiceLFS
+	m.lfsInstalled = git.LFSInstalled()
+	m.lfsErr = nil
+	if !m.lfsInstalled {
+		m.largeChoice = largeChoiceIgnore
+	}
+	return m, nil
+}
+
+// proceed continues the save to the preview or straight to saving
+func (m SaveModel) proceed() (SaveModel, tea.Cmd) {
+	if m.wantPreview {
+		m.state = SaveStatePreview
+		return m, nil
+	}
+	return m.executeSave()
+}
+
+// ignoreLargeFiles switches the large files to be added to .gitignore
+func (m SaveModel) ignoreLargeFiles() SaveModel {
+	for _, large := range m.largeFiles {
+		m = m.withFileAction(large.Path, FileActionIgnore)
+	}
+	return m
+}
+
+// updateLargeFiles handles keys on the large files interstitial
+func (m SaveModel) updateLargeFiles(msg tea.KeyMsg) (SaveModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.largeChoice > largeChoiceLFS {
+			m.largeChoice--
+		}
+		if m.largeChoice == largeChoiceLFS && !m.lfsInstalled {
+			m.largeChoice = largeChoiceIgnore
+		}
+	case key.Matches(msg, keys.Down):
+		if m.largeChoice < largeChoiceBack {
+			m.largeChoice++
+		}
+	case msg.String() == "esc":
+		m.state = SaveStateReview
+	case key.Matches(msg, keys.Enter):
+		switch m.largeChoice {
+		case largeChoiceLFS:
+			m.state = SaveStateTrackingLFS
+			return m, tea.Batch(spinnerTick(m.spinner), doTrackLFS(m.largeFiles))
+		case largeChoiceIgnore:
+			m = m.ignoreLargeFiles()
+			return m.proceed()
+		case largeChoiceSaveAnyway:
+			return m.proceed()
+		case largeChoiceBack:
+			m.state = SaveStateReview
+		}
+	}
+	return m, nil
+}
+
+// renderLargeFiles renders the large files interstitial
+func (m SaveModel) renderLargeFiles() string {
+	cfg, _ := config.Load()
+
+	var s string
+	s += RenderTitle("Large Files") + "\n\n"
+	s += RenderHighlight(fmt.Sprintf("⚠ %d file(s) are %d MB or bigger:", len(m.largeFiles), cfg.LargeFileMB)) + "\n\n"
+
+	maxVisible := 8
+	for i, f := range m.largeFiles {
+		if i == maxVisible {
+			s += MutedStyle.Render(fmt.Sprintf("    ... and %d more", len(m.largeFiles)-maxVisible)) + "\n"
+			break
+		}
+		s += fmt.Sprintf("    %s %s\n", truncateLine(f.Path, 50), MutedStyle.Render(fmt.Sprintf("%.1f MB", float64(f.Size)/(1<<20))))
+	}
+	s += "\n"
+	s += RenderMuted("Big files make every copy of your project slow to download,") + "\n"
+	s += RenderMuted("and GitHub rejects files over 100 MB.") + "\n\n"
+
+	choices := []struct {
+		label       string
+		description string
+	}{
+		{"Track with Git LFS", "Upload them to GitHub's large file storage (recommended)"},
+		{"Add to .gitignore", "Keep them on this computer only"},
+		{"Save anyway", "Save them like any other file"},
+		{"Go back", "Change what gets saved"},
+	}
+	if !m.lfsInstalled {
+		choices[largeChoiceLFS].description = "Needs Git LFS, get it from https://git-lfs.com"
+	}
+
+	for i, choice := range choices {
+		cursor := "  "
+		style := MenuItemStyle
+		if i == m.largeChoice {
+			cursor = MenuCursorStyle.Render("> ")
+			style = MenuItemSelectedStyle
+		}
+		if i == largeChoiceLFS && !m.lfsInstalled {
+			style = MutedStyle
+		}
+		s += cursor + style.Render(choice.label) + "\n"
+		s += "    " + MutedStyle.Render(choice.description) + "\n"
+	}
+	s += "\n"
+
+	if m.lfsErr != nil {
+		s += RenderError("✗ Couldn't set up Git LFS: ") + RenderMuted(m.lfsErr.Error()) + "\n\n"
+	}
+
+	s += HelpBar(largeFilesKeys)
+	return s
+}
+
+// largeFilesKeys are the keys for the large files interstitial
+var largeFilesKeys = Keys{
+	Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "back")},
+	More: []key.Binding{vimKeys},
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+)
+
+// menuConcept describes the git idea behind a menu action
+type menuConcept struct {
+	minLevel string // lowest experience level that sees the action
+	gitTerm  string // git name for the action, shown to advanced users
+	learn    string // plain-language explanation shown under "learn more"
+}
+
+// menuConcepts maps actions to the git concepts they are built on. Actions
+// without an entry are shown at every level.
+var menuConcepts = map[MenuAction]menuConcept{
+	ActionQuicksave: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git commit",
+		learn:    "Each save is a git commit: a snapshot of every file with a message. You can always go back to any commit.",
+	},
+	ActionRestore: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git reset --hard",
+		learn:    "Reverting moves your project back to an earlier commit. A backup branch is made first, so later saves aren't lost.",
+	},
+	ActionRewind: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git log --before, git reset --hard",
+		learn:    "Every commit records when it was made. Rewinding finds the last commit before the time you pick and reverts to it, with a backup first.",
+	},
+	ActionAmend: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git commit --amend",
+		learn:    "Amending swaps the last commit for a new one with your fixes. It's a new commit with a new ID, so if the old one was pushed, pushing again has to overwrite it.",
+	},
+	ActionTimeline: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git log --graph",
+		learn:    "The timeline is git's commit graph. Each dot is a commit, and the lines show which commit came before it and where branches split and merge.",
+	},
+	ActionCompare: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git diff <commit> <commit>",
+		learn:    "git diff can compare any two commits, or a commit with your working tree, whether or not one came after the other.",
+	},
+	ActionKeepExperiment: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git merge",
+		learn:    "Keeping an experiment merges its branch into main, adding its commits to your main history.",
+	},
+	ActionAbandonExperiment: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git branch -D",
+		learn:    "Abandoning deletes the experiment's branch and switches back to main. Main never saw those commits. Archiving renames the branch to archived/ instead, so it can come back.",
+	},
+	ActionBackups: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "backup branches",
+		learn:    "Backups are ordinary git branches under backup/…, created before anything that could lose work.",
+	},
+	ActionBackUpNow: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git branch, commit-tree",
+		learn:    "A backup is a branch at your latest commit. Unsaved changes go in one more commit on top, made without touching your files or staging area.",
+	},
+	ActionExport: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git archive",
+		learn:    "Exporting packs the files from a commit, or your working folder, into a zip or tarball. It's a plain copy without git history, so anyone can open it.",
+	},
+	ActionIgnore: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  ".gitignore",
+		learn:    "Patterns in .gitignore tell git which files never to save, like build output, logs, or secrets.",
+	},
+	ActionFixLineEndings: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  ".gitattributes, core.autocrlf",
+		learn:    "Git can convert between Windows (CRLF) and Mac/Linux (LF) line endings. A .gitattributes rule makes it consistent for everyone.",
+	},
+	ActionStash: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git stash",
+		learn:    "The stash holds unsaved changes set aside so you can switch branches with a clean folder. Applying a stash merges it back, which can conflict like any merge.",
+	},
+	ActionOptimize: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git maintenance, commit-graph",
+		learn:    "Git can keep an index of your history (the commit-graph) and tidy its storage in the background, which keeps big projects fast.",
+	},
+	ActionStorage: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git count-objects, rev-list --objects",
+		learn:    "Git keeps every version of every file ever saved, compressed in .git. Ignoring or removing a file stops new versions, but old ones stay until history is rewritten.",
+	},
+	ActionPurgeFile: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git filter-branch, push --force-with-lease",
+		learn:    "Rewriting history makes new saves without the file and moves every branch onto them. Anyone with the old saves still has the file, so a leaked secret should always be changed too.",
+	},
+	ActionFixDetached: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "detached HEAD, git switch -c",
+		learn:    "Checking out a commit directly detaches HEAD from any branch. Commits made there belong to no branch until you create one, and switching away leaves them behind.",
+	},
+	ActionExperiments: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git branch",
+		learn:    "Experiments are git branches: a separate line of commits that starts from your current work and can be merged back or thrown away.",
+	},
+	ActionActivity: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  ".smooth/activity.log",
+		learn:    "Each git command smooth runs that changes your project, like commit, reset, merge or push, is appended to .smooth/activity.log.",
+	},
+	ActionLogs: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "~/.smooth/logs",
+		learn:    "With debug logging on, every git command smooth runs, even ones that only look, is written to a log for the day with how long it took and what git printed back.",
+	},
+	ActionSnapshots: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "refs/smooth/snapshots",
+		learn:    "Before anything that could lose unsaved work, smooth commits your files to a hidden ref. It's not on any branch, so it never shows up in your history.",
+	},
+	ActionTidy: {
+		minLevel: config.LevelAdvanced,
+		gitTerm:  "git rebase -i (fixup, reword)",
+		learn:    "An interactive rebase replays recent commits in a new shape: folding several into one or changing their messages. The files end up the same; only the history changes.",
+	},
+	ActionBlame: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git blame",
+		learn:    "Blame shows, for each line of a file, the commit that last changed it, so you can find when something broke.",
+	},
+	ActionCleanup: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git branch -D",
+		learn:    "Experiments and backups are branches. Deleting one whose commits are all in main loses nothing; otherwise its unique commits go with it.",
+	},
+	ActionSync: {
+		minLevel: config.LevelBeginner,
+		gitTerm:  "git push",
+		learn:    "Syncing pushes your commits to a remote repository such as GitHub, so they're backed up and shareable.",
+	},
+	ActionSyncAll: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git push origin <every branch and tag>",
+		learn:    "Pushing only uploads the branch you name. Pushing each branch and tag in turn puts experiments, backups and tags on the remote too, so they're backed up as well.",
+	},
+	ActionRemotes: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git remote add, git push <remote>",
+		learn:    "A remote is another copy of the repository that you push to. origin is the usual one; adding more, like a private backup, means pushing to each.",
+	},
+	ActionTeamSync: {
+		minLevel: config.LevelIntermediate,
+		gitTerm:  "git fetch, git pull --rebase",
+		learn:    "Fetching downloads a teammate's commits without touching your files. Pulling then replays your commits on top of theirs (rebase) or joins the two with a merge commit.",
+	},
+}
+
+// applyExperienceLevel drops actions above the configured level and, for
+// advanced users, adds the git term to each description
+func applyExperienceLevel(items []MenuItem, cfg config.Config) []MenuItem {
+	var visible []MenuItem
+	for _, item := range items {
+		concept, ok := menuConcepts[item.Action]
+		if ok && !cfg.AtLeast(concept.minLevel) {
+			continue
+		}
+		if ok && cfg.AtLeast(config.LevelAdvanced) {
+			item.Description += " · " + concept.gitTerm
+		}
+		visible = append(visible, item)
+	}
+	return visible
+}
+
+// renderLearnMore renders the explanation of the git concept behind an
+// action, or "" if it has none
+func renderLearnMore(action MenuAction, width int) string {
+	concept, ok := menuConcepts[action]
+	if !ok {
+		return ""
+	}
+
+	style := PanelStyle(ColorSecondary).
+		Padding(0, 1).
+		Width(width)
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent).Render("Learn more: " + concept.gitTerm)
+	return style.Render(title + "\n" + MutedStyle.Render(concept.learn))
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// LogsState represents the state of the debug logs screen
+type LogsState int
+
+const (
+	LogsStateList LogsState = iota
+	LogsStateView
+)
+
+// LogsModel lists the debug logs, one per day, and shows what's in them so
+// they can be attached to bug reports
+type LogsModel struct {
+	state  LogsState
+	logs   []git.DebugLog
+	dir    string
+	cursor int
+	lines  []string // of the log being viewed
+	offset int      // first line shown
+	err    error
+	width  int
+	height int
+}
+
+// NewLogsModel creates a model for the debug logs screen
+func NewLogsModel() LogsModel {
+	m := LogsModel{}
+	m.dir, m.err = git.DebugLogDir()
+	if m.err == nil {
+		m.logs, m.err = git.DebugLogs()
+	}
+	return m
+}
+
+// Init initializes the model
+func (m LogsModel) Init() tea.Cmd {
+	return nil
+}
+
+// visibleLines is how many lines of a log fit on screen
+func (m LogsModel) visibleLines() int {
+	if m.height == 0 {
+		return 20
+	}
+	return max(5, m.height-12)
+}
+
+// Update handles messages
+func (m LogsModel) Update(msg tea.Msg) (LogsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.state == LogsStateView {
+			return m.updateView(msg), nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < len(m.logs)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Enter):
+			lines, err := git.ReadDebugLog(m.logs[m.cursor].Path)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.lines = lines
+			// Start at the end, where whatever just went wrong is
+			m.offset = max(0, len(lines)-m.visibleLines())
+			m.state = LogsStateView
+		}
+	}
+	return m, nil
+}
+
+// updateView scrolls through the log being viewed
+func (m LogsModel) updateView(msg tea.KeyMsg) LogsModel {
+	last := max(0, len(m.lines)-m.visibleLines())
+	switch msg.String() {
+	case "up", "k":
+		m.offset = max(0, m.offset-1)
+	case "down", "j":
+		m.offset = min(last, m.offset+1)
+	case "pgup":
+		m.offset = max(0, m.offset-m.visibleLines())
+	case "pgdown", " ":
+		m.offset = min(last, m.offset+m.visibleLines())
+	case "home", "g":
+		m.offset = 0
+	case "end", "G":
+		m.offset = last
+	case "esc":
+		m.state = LogsStateList
+	}
+	return m
+}
+
+// View renders the debug logs screen
+func (m LogsModel) View() string {
+	var s string
+
+	s += RenderTitle("Logs") + "\n\n"
+
+	if m.err != nil {
+		s += RenderError("✗ Couldn't read the logs") + "\n\n"
+		s += renderErrorDetails(m.err)
+		s += HelpText("Press any key to go back")
+		return BoxStyle.Render(s)
+	}
+
+	if len(m.logs) == 0 {
+		s += RenderMuted("Nothing logged yet.") + "\n\n"
+		s += RenderMuted("Turn on Debug logging in Settings, or start smooth with --debug,") + "\n"
+		s += RenderMuted("and every git command smooth runs is written to "+m.dir+".") + "\n\n"
+		s += HelpText("Press any key to go back")
+		return BoxStyle.Render(s)
+	}
+
+	if m.state == LogsStateView {
+		return BoxStyle.Render(s + m.renderLog())
+	}
+
+	if !git.DebugLogging() {
+		s += RenderMuted("Debug logging is off, so these are from before.") + "\n\n"
+	}
+	for i, log := range m.logs {
+		cursor, style := "  ", ListItemStyle
+		if i == m.cursor {
+			cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		s += fmt.Sprintf("%s%s %s\n", cursor, style.Render(logDayName(log.Day)), MutedStyle.Render(formatSize(log.Size)))
+	}
+	s += "\n" + RenderMuted("To report a bug, attach the file from:") + "\n"
+	s += "  " + HighlightStyle.Render(m.dir) + "\n\n"
+	s += HelpBar(m.Keys())
+
+	return BoxStyle.Render(s)
+}
+
+// renderLog shows a page of the log being viewed
+func (m LogsModel) renderLog() string {
+	log := m.logs[m.cursor]
+	s := RenderSubtitle(logDayName(log.Day)) + " " + MutedStyle.Render(log.Path) + "\n\n"
+
+	width := 80
+	if m.width > 0 {
+		width = max(30, m.width-10)
+	}
+	end := min(len(m.lines), m.offset+m.visibleLines())
+	for _, line := range m.lines[m.offset:end] {
+		s += truncateLine(line, width) + "\n"
+	}
+	s += "\n" + MutedStyle.Render(fmt.Sprintf("Lines %d-%d of %d", m.offset+1, end, len(m.lines))) + "\n\n"
+	s += HelpBar(m.Keys())
+	return s
+}
+
+// logDayName names the day a log is from
+func logDayName(day time.Time) string {
+	today := time.Now()
+	switch {
+	case day.Format(time.DateOnly) == today.Format(time.DateOnly):
+		return "Today"
+	case day.Format(time.DateOnly) == today.AddDate(0, 0, -1).Format(time.DateOnly):
+		return "Yesterday"
+	}
+	return day.Format("Monday, Jan 2")
+}
+
+// Keys returns the keys the logs screen takes in its current state
+func (m LogsModel) Keys() Keys {
+	if m.state == LogsStateView {
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "scroll"), hint("pgup/pgdn", "page"), hint("g/G", "start/end"), hint("esc", "back")},
+			More: []key.Binding{vimKeys, hint("space", "page down"), hint("home/end", "start/end")},
+		}
+	}
+	return Keys{
+		Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "view"), hint("esc", "back")},
+		More: []key.Binding{vimKeys},
+	}
+}
+
+// IsDone returns true if there is nothing to browse
+func (m LogsModel) IsDone() bool {
+	return m.err != nil || len(m.logs) == 0
+}
+
+// HandlesEsc returns true while viewing a log, so esc goes back to the list
+func (m LogsModel) HandlesEsc() bool {
+	return m.state == LogsStateView
+}
@@ -5,12 +5,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alecthomas/chroma/v2"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"smooth/config"
 	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+	"smooth/plugins"
 )
 
 // tickMsg is sent periodically to refresh the menu
@@ -19,11 +24,15 @@ type tickMsg time.Time
 // refreshInterval is how often the menu refreshes
 const refreshInterval = 2 * time.Second
 
+// menuMaxVisible is how many menu items fit before the list scrolls
+const menuMaxVisible = 12
+
 // MenuItem represents a menu option
 type MenuItem struct {
 	Title       string
 	Description string
 	Action      MenuAction
+	Plugin      plugins.Plugin // Set for ActionPlugin
 }
 
 // MenuAction represents the action to take when a menu item is selected
@@ -32,30 +41,60 @@ type MenuAction int
 const (
 	ActionQuicksave MenuAction = iota
 	ActionSync
+	ActionSyncAll
+	ActionRemotes
+	ActionTeamSync
+	ActionTasks
 	ActionRestore
+	ActionRewind
+	ActionAmend
+	ActionTimeline
+	ActionCompare
+	ActionTidy
+	ActionBlame
 	ActionBackups
+	ActionBackUpNow
+	ActionExport
+	ActionSnapshots
+	ActionIgnore
+	ActionFixLineEndings
+	ActionStash
+	ActionOptimize
+	ActionTrophies
+	ActionStats
+	ActionActivity
+	ActionLogs
+	ActionCleanup
+	ActionStorage
+	ActionPurgeFile
 	ActionExperiments
 	ActionKeepExperiment
 	ActionAbandonExperiment
+	ActionFixDetached
+	ActionPlugin
 	ActionSettings
 	ActionQuit
 )
 
 // FileAction represents what to do with a changed file
-type FileAction int
+type FileAction = ops.Action
 
 const (
-	FileActionSave       FileAction = iota // Stage and commit the file
-	FileActionRevert                       // Discard changes (restore to HEAD)
-	FileActionIgnoreOnce                   // Skip this time, keep local changes
-	FileActionIgnore                       // Add to .gitignore
+	FileActionSave       = ops.ActionSave   // Stage and commit the file
+	FileActionRevert     = ops.ActionRevert // Discard changes (restore to HEAD)
+	FileActionIgnoreOnce = ops.ActionSkip   // Skip this time, keep local changes
+	FileActionIgnore     = ops.ActionIgnore // Add to .gitignore
 )
 
 // MenuModel is the model for the main menu
 type MenuModel struct {
+	spinner          spinner.Model
+	loading          bool // Whether the project's status is still loading for the first time
+	refreshing       bool // Whether a status load is running, see refresh.go
 	items            []MenuItem
 	cursor           int
 	branch           string
+	detached         bool // HEAD is on a save rather than a branch
 	hasChanges       bool
 	isOnMain         bool
 	diff             string
@@ -64,45 +103,56 @@ type MenuModel struct {
 	changedFiles     []git.FileChange
 	focusRight       bool
 	fileCursor       int
+	fileOffset       int // First changed file shown, see changes.go
 	expandedFiles    map[string]bool
 	fileDiffs        map[string]string
+	loadingDiffs     map[string]bool         // Opened files whose diffs are still loading
+	diffOrder        []string                // Files with cached diffs, oldest first
+	binaryFiles      map[string]bool         // Expanded files showing a binary preview instead of a diff
 	diffScrollOffset map[string]int          // Scroll offset per file
 	diffStats        map[string]git.DiffStat // Line additions/deletions per file
+	eolChurn         []string                // Files whose only change is line endings
+	stashes          int                     // Sets of unsaved changes put aside in git's stash
+	debugLogs        bool                    // Whether there are debug logs to view
+	largeRepo        bool                    // Whether git status is slow enough to refresh less, see refresh.go
+	skipped          map[string]time.Time    // Files left out of saves, until when
+	needsOptimize    bool                    // Big project without background maintenance
+	showLearn        bool                    // Whether the "learn more" box is expanded
+	savesWaiting     int                     // Saves a failed auto-sync hasn't uploaded yet
+	ahead            int                     // Saves on this branch not uploaded to GitHub
+	behind           int                     // Saves on GitHub not downloaded, as of the last check
+	lastSave         time.Time               // When the newest save on this branch was made
+	retryingSync     bool                    // Whether a queued sync is being retried
+	fetching         bool                    // Whether we're checking GitHub for new saves
+	lastFetch        time.Time               // When we last checked GitHub for new saves
+	wrapUpTried      string                  // The day the end of day last ran, so a failure isn't retried every tick
+	quicksaving      bool                    // Whether a quick save is running
+	quicksaveNote    string                  // How the last quick save went, until the next key
+	quicksaveFailed  bool                    // Whether quicksaveNote is a problem
+	ci               CIStatusMsg             // How the checks on the last upload went, see ci.go
+	backups          int                     // Backups of this branch, as of the last full refresh
 }
 
-// NewMenuModel creates a new menu model
+// NewMenuModel creates a new menu model. The project's status loads in
+// Init; until then the menu shows what it would on main with no changes.
 func NewMenuModel() MenuModel {
-	branch, _ := git.CurrentBranch()
-	hasChanges := git.HasChanges()
-	isOnMain := git.IsOnMain()
-	diff := git.GetDiff()
-	changedFiles, _ := git.GetChangeSummary()
-
-	// Load diff stats for line counts
-	diffStats := make(map[string]git.DiffStat)
-	if stats, err := git.GetUncommittedDiffStat(); err == nil {
-		for _, stat := range stats.Files {
-			// Clean up path (remove " (new)" suffix if present)
-			path := strings.TrimSuffix(stat.Path, " (new)")
-			diffStats[path] = stat
-		}
-	}
-
 	m := MenuModel{
+		spinner:          newSpinner(SpinnerDots),
+		loading:          true,
+		refreshing:       true,
 		cursor:           0,
-		branch:           branch,
-		hasChanges:       hasChanges,
-		isOnMain:         isOnMain,
-		diff:             diff,
+		isOnMain:         true,
 		width:            120, // Default to wide, will be updated by WindowSizeMsg
 		height:           30,
-		changedFiles:     changedFiles,
 		focusRight:       false,
 		fileCursor:       0,
 		expandedFiles:    make(map[string]bool),
 		fileDiffs:        make(map[string]string),
+		loadingDiffs:     make(map[string]bool),
+		binaryFiles:      make(map[string]bool),
 		diffScrollOffset: make(map[string]int),
-		diffStats:        diffStats,
+		diffStats:        make(map[string]git.DiffStat),
+		skipped:          make(map[string]time.Time),
 	}
 	m.items = m.buildMenuItems()
 	return m
@@ -110,29 +160,105 @@ func NewMenuModel() MenuModel {
 
 // buildMenuItems creates the menu items based on current state
 func (m MenuModel) buildMenuItems() []MenuItem {
+	cfg, _ := config.Load()
+
+	// Descriptions count what's waiting, like "(12 files changed)"
+	saveDesc := "Save your work (use → to configure per-file actions)"
+	if n := len(m.changedFiles); n > 0 {
+		saveDesc = fmt.Sprintf("Save your work (%s changed, use → to configure per-file actions)", plural(n, "file"))
+	}
+	var syncCount string
+	if n := max(m.savesWaiting, m.ahead); n > 0 && !m.detached {
+		syncCount = " (" + plural(n, "save") + " not uploaded)"
+	}
+	backupsDesc := "Restore from automatic backups created during reverts"
+	if m.backups > 0 {
+		backupsDesc += " (" + plural(m.backups, "backup") + ")"
+	}
+
 	// Titles and descriptions change based on whether we're on an experiment
 	revertTitle := "Revert"
 	revertDesc := "Restore your project to an earlier save point"
-	if !m.isOnMain {
+	if !m.isOnMain && !m.detached {
 		revertTitle = "Revert (experiment)"
 		revertDesc = "Restore your experiment to an earlier save point"
 	}
 
-	items := []MenuItem{
+	var items []MenuItem
+
+	// Getting back onto a branch comes first, before saves end up on none
+	if m.detached {
+		items = append(items, MenuItem{
+			Title:       "Get back on a branch",
+			Description: "You're on an old save, not a branch: start a branch here or go back to main",
+			Action:      ActionFixDetached,
+		})
+	}
+
+	items = append(items, []MenuItem{
 		{
 			Title:       "Save",
-			Description: "Save your work (use → to configure per-file actions)",
+			Description: saveDesc,
 			Action:      ActionQuicksave,
 		},
+		{
+			Title:       "Edit last save",
+			Description: "Fix the last save's message or add files you forgot",
+			Action:      ActionAmend,
+		},
 		{
 			Title:       revertTitle,
 			Description: revertDesc,
 			Action:      ActionRestore,
 		},
+		{
+			Title:       "Rewind to a time",
+			Description: "Go back to how your project looked 30 minutes, 2 hours or a day ago",
+			Action:      ActionRewind,
+		},
+		{
+			Title:       "Timeline",
+			Description: "See how your saves, experiments, and backups connect",
+			Action:      ActionTimeline,
+		},
+		{
+			Title:       "Compare saves",
+			Description: "See everything that differs between two saves, or a save and now",
+			Action:      ActionCompare,
+		},
+		{
+			Title:       "Tidy up saves",
+			Description: "Combine and rename recent saves before syncing",
+			Action:      ActionTidy,
+		},
+		{
+			Title:       "Who changed this?",
+			Description: "Find the save that last changed each line of a file",
+			Action:      ActionBlame,
+		},
+	}...)
+
+	// Offer the line-ending fix only while some files are pure CRLF/LF churn
+	if len(m.eolChurn) > 0 {
+		items = append(items, MenuItem{
+			Title:       "Fix line endings",
+			Description: fmt.Sprintf("%d file(s) only changed line endings", len(m.eolChurn)),
+			Action:      ActionFixLineEndings,
+		})
+	}
+
+	// Offer stashed changes back only while there are some, like ones that
+	// clashed when switching experiments
+	if m.stashes > 0 {
+		items = append(items, MenuItem{
+			Title:       "Stashed changes",
+			Description: plural(m.stashes, "set") + " of unsaved changes put aside, to bring back or delete",
+			Action:      ActionStash,
+		})
 	}
 
 	// Add experiment-specific actions when on an experiment branch
-	if !m.isOnMain {
+	if !m.isOnMain && !m.detached {
 		items = append(items,
 			MenuItem{
 				Title:       "Keep this experiment",
@@ -147,17 +273,59 @@ func (m MenuModel) buildMenuItems() []MenuItem {
 		)
 	}
 
+	if m.needsOptimize {
+		items = append(items, MenuItem{
+			Title:       "Optimize for big project",
+			Description: "Speed up history and diffs, and keep git tidy in the background",
+			Action:      ActionOptimize,
+		})
+	}
+
 	// This is a comment to show troy that it works.
 	items = append(items,
 		MenuItem{
 			Title:       "Restore backup",
-			Description: "Restore from automatic backups created during reverts",
+			Description: backupsDesc,
 			Action:      ActionBackups,
 		},
+		MenuItem{
+			Title:       "Back up now",
+			Description: "Keep a copy of your project as it is, unsaved changes included",
+			Action:      ActionBackUpNow,
+		},
+		MenuItem{
+			Title:       "Export backup",
+			Description: "Save a copy of your project as a zip, no GitHub needed",
+			Action:      ActionExport,
+		},
+		MenuItem{
+			Title:       "Snapshots",
+			Description: "Get back unsaved work from automatic snapshots",
+			Action:      ActionSnapshots,
+		},
+		MenuItem{
+			Title:       "Ignored files",
+			Description: "Review and clean up the files smooth never saves",
+			Action:      ActionIgnore,
+		},
+		MenuItem{
+			Title:       "Clean up",
+			Description: "Delete old experiments and backups you no longer need",
+			Action:      ActionCleanup,
+		},
+		MenuItem{
+			Title:       "Storage",
+			Description: "See what takes up space and stop big files from growing it",
+			Action:      ActionStorage,
+		},
+		MenuItem{
+			Title:       "Remove a file from history",
+			Description: "Take a .env or key saved by mistake out of every save",
+			Action:      ActionPurgeFile,
+		},
 	)
 
 	// Only show experiments if enabled in config
-	cfg, _ := config.Load()
 	if cfg.ExperimentsEnabled {
 		items = append(items,
 			MenuItem{
@@ -168,12 +336,84 @@ func (m MenuModel) buildMenuItems() []MenuItem {
 		)
 	}
 
-	items = append(items,
-		MenuItem{
+	// Sync uploads everything already when that's the default
+	if cfg.SyncEverything {
+		items = append(items, MenuItem{
 			Title:       "Sync to GitHub",
-			Description: "Upload your saves to the cloud",
+			Description: "Upload every branch, experiment, backup and tag to the cloud" + syncCount,
 			Action:      ActionSync,
+		})
+	} else {
+		items = append(items,
+			MenuItem{
+				Title:       "Sync to GitHub",
+				Description: "Upload your saves to the cloud" + syncCount,
+				Action:      ActionSync,
+			},
+			MenuItem{
+				Title:       "Sync everything",
+				Description: "Upload every branch, experiment, backup and tag, not just this one",
+				Action:      ActionSyncAll,
+			},
+		)
+	}
+
+	items = append(items,
+		MenuItem{
+			Title:       "Remotes",
+			Description: "Pick where Sync uploads to, like a private backup as well as GitHub",
+			Action:      ActionRemotes,
+		},
+		MenuItem{
+			Title:       "Team sync",
+			Description: "Bring in saves your teammates uploaded",
+			Action:      ActionTeamSync,
+		},
+		MenuItem{
+			Title:       "Tasks",
+			Description: "Keep a to-do list and link your saves to it",
+			Action:      ActionTasks,
+		},
+		MenuItem{
+			Title:       "Activity",
+			Description: "See every change smooth has made to your project",
+			Action:      ActionActivity,
 		},
+	)
+
+	// Debug logs are only offered once there are some to look at
+	if m.debugLogs {
+		items = append(items, MenuItem{
+			Title:       "View logs",
+			Description: "See the git commands smooth ran, to attach to bug reports",
+			Action:      ActionLogs,
+		})
+	}
+
+	items = append(items,
+		MenuItem{
+			Title:       "Trophies",
+			Description: "See the milestones you've unlocked",
+			Action:      ActionTrophies,
+		},
+		MenuItem{
+			Title:       "Stats",
+			Description: "See your saving streaks and activity over time",
+			Action:      ActionStats,
+		},
+	)
+
+	// Plugins from ~/.smooth/plugins go just above Settings
+	for _, plugin := range plugins.List() {
+		items = append(items, MenuItem{
+			Title:       plugin.Name,
+			Description: plugin.Description,
+			Action:      ActionPlugin,
+			Plugin:      plugin,
+		})
+	}
+
+	items = append(items,
 		MenuItem{
 			Title:       "Settings",
 			Description: "Configure auto-sync and backup options",
@@ -186,50 +426,117 @@ func (m MenuModel) buildMenuItems() []MenuItem {
 		},
 	)
 
-	return items
+	return applyExperienceLevel(items, cfg)
 }
 
-// Init initializes the menu model
+// Init initializes the menu model, loading the project's status
 func (m MenuModel) Init() tea.Cmd {
-	return tickCmd()
+	return tea.Batch(m.tickCmd(), spinnerTick(m.spinner), doLoadMenuStatus(m.largeRepo, true))
 }
 
-// tickCmd returns a command that sends a tick after the refresh interval
-func tickCmd() tea.Cmd {
-	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
-		return tickMsg(t)
-	})
+// SyncRetryMsg is sent when retrying a queued sync completes
+type SyncRetryMsg struct {
+	Err error
+}
+
+// doRetrySync retries uploading saves from a failed auto-sync
+func doRetrySync() tea.Cmd {
+	return func() tea.Msg {
+		return SyncRetryMsg{Err: git.RetryPendingSync()}
+	}
+}
+
+// incomingInterval is how often GitHub is checked for new saves
+const incomingInterval = 5 * time.Minute
+
+// IncomingMsg is sent when checking GitHub for new saves completes
+type IncomingMsg struct {
+	Count int
+}
+
+// doFetchIncoming checks GitHub for saves synced from somewhere else
+func doFetchIncoming() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := networkContext()
+		defer cancel()
+		count, _ := git.FetchIncoming(ctx)
+		return IncomingMsg{Count: count}
+	}
 }
 
 // Update handles messages for the menu model
 func (m MenuModel) Update(msg tea.Msg) (MenuModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tickMsg:
-		// Refresh data from git
-		m.branch, _ = git.CurrentBranch()
-		m.hasChanges = git.HasChanges()
-		m.isOnMain = git.IsOnMain()
-		m.diff = git.GetDiff()
-		m.changedFiles, _ = git.GetChangeSummary()
-		m.items = m.buildMenuItems()
-		// Reset file cursor if out of bounds
-		if m.fileCursor >= len(m.changedFiles) {
-			m.fileCursor = max(0, len(m.changedFiles)-1)
+		// Refresh data from git in the background and schedule the next tick
+		return m, tea.Batch(m.tickCmd(), m.refresh(false))
+	case MenuStatusMsg:
+		first := m.loading
+		m.loading = false
+		m.refreshing = false
+		m.applyStatus(msg)
+		var cmds []tea.Cmd
+		cfg, _ := config.Load()
+		// Saves left over from a failed auto-sync get another try on launch,
+		// and after that once a retry is due, unless one is already running
+		if !m.retryingSync && cfg.AutoSyncEnabled && (msg.retryDue || first && msg.pendingSync) {
+			m.retryingSync = true
+			cmds = append(cmds, doRetrySync())
 		}
-		// Refresh diff stats
-		if stats, err := git.GetUncommittedDiffStat(); err == nil {
-			m.diffStats = make(map[string]git.DiffStat)
-			for _, stat := range stats.Files {
-				path := strings.TrimSuffix(stat.Path, " (new)")
-				m.diffStats[path] = stat
-			}
+		// Check for saves synced from elsewhere, only to notify about them
+		if !m.fetching && cfg.NotificationsEnabled && time.Since(m.lastFetch) >= incomingInterval && msg.hasRemote {
+			m.fetching = true
+			m.lastFetch = time.Now()
+			cmds = append(cmds, doFetchIncoming())
+		}
+		// Wrap up the day once the configured end of day has passed
+		if today := time.Now().Format("2006-01-02"); m.wrapUpTried != today && endOfDayDue(cfg, time.Now()) {
+			m.wrapUpTried = today
+			cmds = append(cmds, doEndOfDay())
+		}
+		return m, tea.Batch(cmds...)
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	case SyncRetryMsg:
+		if msg.Err == nil && m.savesWaiting > 0 {
+			notify.SavesUploaded(m.savesWaiting)
+		}
+		m.retryingSync = false
+		return m, m.refresh(false)
+	case EndOfDayMsg:
+		// The day's work-in-progress save may be waiting to upload
+		return m, m.refresh(false)
+	case QuicksaveMsg:
+		m.quicksaving = false
+		m.quicksaveNote, m.quicksaveFailed = quicksaveNote(msg)
+		m.clearDiffs()
+		return m, m.refresh(true) // the tick is already running
+	case CIStatusMsg:
+		// Only the newest upload's checks count
+		if msg.Started.Before(m.ci.Started) {
+			return m, nil
 		}
-		// Schedule next tick
-		return m, tickCmd()
+		m.ci = msg
+		return m, msg.next()
+	case IncomingMsg:
+		m.fetching = false
+		if msg.Count > 0 {
+			notify.IncomingSaves(msg.Count, m.branch)
+		}
+		return m, m.refresh(false)
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.keepFileVisible()
+	case fileDiffMsg:
+		m.cacheFileDiff(msg)
 	case tea.KeyMsg:
+		m.quicksaveNote = ""
+
 		// Check if we should show the diff panel (determines if right navigation is available)
 		showDiffPanel := m.width >= 90 && len(m.changedFiles) > 0
 
@@ -244,119 +551,171 @@ func (m MenuModel) Update(msg tea.Msg) (MenuModel, tea.Cmd) {
 			}
 		case key.Matches(msg, keys.Up):
 			if m.focusRight {
-				// Check if current file is expanded - if so, scroll the diff
-				if len(m.changedFiles) > 0 {
-					filePath := m.changedFiles[m.fileCursor].Path
-					if m.expandedFiles[filePath] {
-						// Scroll up in diff
-						if m.diffScrollOffset[filePath] > 0 {
-							m.diffScrollOffset[filePath]--
-						}
-					} else {
-						// Move to previous file
-						if m.fileCursor > 0 {
-							m.fileCursor--
-						}
-					}
-				}
+				m.scrollChanges(-1)
 			} else {
-				if m.cursor > 0 {
-					m.cursor--
-				}
+				m.moveCursor(-1)
 			}
 		case key.Matches(msg, keys.Down):
 			if m.focusRight {
-				// Check if current file is expanded - if so, scroll the diff
-				if len(m.changedFiles) > 0 {
-					filePath := m.changedFiles[m.fileCursor].Path
-					if m.expandedFiles[filePath] {
-						// Scroll down in diff
-						diff := m.fileDiffs[filePath]
-						diffLines := strings.Split(diff, "\n")
-						maxScroll := len(diffLines) - m.getMaxDiffLines()
-						if maxScroll < 0 {
-							maxScroll = 0
-						}
-						if m.diffScrollOffset[filePath] < maxScroll {
-							m.diffScrollOffset[filePath]++
-						}
-					} else {
-						// Move to next file
-						if m.fileCursor < len(m.changedFiles)-1 {
-							m.fileCursor++
-						}
-					}
-				}
+				m.scrollChanges(1)
 			} else {
-				if m.cursor < len(m.items)-1 {
-					m.cursor++
-				}
+				m.moveCursor(1)
 			}
-		case key.Matches(msg, keys.Enter):
+		case msg.String() == "i":
+			if !m.focusRight {
+				m.showLearn = !m.showLearn
+			}
+		case msg.String() == "s":
+			// Skip the selected file in saves, or let it be saved again
 			if m.focusRight && len(m.changedFiles) > 0 {
-				// Toggle diff for the selected file
-				filePath := m.changedFiles[m.fileCursor].Path
-				if m.expandedFiles[filePath] {
-					m.expandedFiles[filePath] = false
+				path := m.changedFiles[m.fileCursor].Path
+				if _, ok := m.skipped[path]; ok {
+					git.UnskipFiles([]string{path})
 				} else {
-					// Load diff if not cached
-					if _, ok := m.fileDiffs[filePath]; !ok {
-						m.fileDiffs[filePath] = git.GetFileDiff(filePath)
-					}
-					m.expandedFiles[filePath] = true
+					git.SkipFiles([]string{path})
 				}
+				m.skipped = git.SkippedFiles()
+			}
+		case msg.String() == "c":
+			// Copy the part of the diff in view if it's open, or the path
+			if m.focusRight && len(m.changedFiles) > 0 {
+				path := m.changedFiles[m.fileCursor].Path
+				if _, loaded := m.fileDiffs[path]; loaded && m.expandedFiles[path] && !m.binaryFiles[path] {
+					return m, copyToClipboard(m.diffExcerpt(path), "the diff")
+				}
+				return m, copyToClipboard(path, "the path")
+			}
+		case key.Matches(msg, keys.Enter):
+			if m.focusRight && len(m.changedFiles) > 0 {
+				return m, m.toggleFileDiff()
 			}
 		}
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	}
 	return m, nil
 }
 
-// View renders the menu
-func (m MenuModel) View() string {
-	// Determine if we should show split view (need at least 90 chars wide)
+// updateMouse lets menu items and changed files be clicked, and the menu
+// and diffs be scrolled with the wheel
+func (m MenuModel) updateMouse(msg tea.MouseMsg) (MenuModel, tea.Cmd) {
 	showDiffPanel := m.width >= 90
+	leftWidth, rightWidth, panelHeight := m.panelSizes()
+
+	if showDiffPanel && len(m.changedFiles) > 0 && msg.X >= leftWidth {
+		_, rows := m.renderChanges(rightWidth, panelHeight)
+		// The panel's border and padding come before its first line
+		row := msg.Y - 2
+		file := -1
+		if row >= 0 && row < len(rows) {
+			file = rows[row]
+		}
 
-	// === LEFT PANEL: Menu ===
-	var leftContent string
+		var cmd tea.Cmd
+		if delta := wheelDelta(msg); delta != 0 {
+			m.focusRight = true
+			if file >= 0 {
+				m.fileCursor = file
+			}
+			m.scrollChanges(delta)
+		} else if isLeftClick(msg) && file >= 0 {
+			// Clicking the selected file opens or closes its diff
+			if m.focusRight && file == m.fileCursor {
+				cmd = m.toggleFileDiff()
+			}
+			m.focusRight = true
+			m.fileCursor = file
+		}
+		return m, cmd
+	}
 
-	// Banner (skip if narrow or short terminal)
-	if m.width >= 60 && m.height >= 30 {
-		leftContent += Banner() + "\n\n"
-	} else if m.height >= 20 {
-		leftContent += TitleStyle.Render("SMOOTH") + "\n\n"
+	if delta := wheelDelta(msg); delta != 0 {
+		m.focusRight = false
+		m.moveCursor(delta)
+		return m, nil
 	}
-	// Skip title entirely if very short
+	if isLeftClick(msg) {
+		// The panel's padding comes before the header
+		row := msg.Y - 1 - linesAbove(m.renderMenuHeader(showDiffPanel))
+		i := m.menuScrollStart() + row
+		if row >= 0 && row < menuMaxVisible && i < len(m.items) {
+			m.focusRight = false
+			m.cursor = i
+			m.showLearn = false
+			return m, pressEnter
+		}
+	}
+	return m, nil
+}
 
-	// Status bar
-	branchDisplay := m.branch
-	if !m.isOnMain {
-		branchDisplay = HighlightStyle.Render(m.branch) + " " + MutedStyle.Render("(experiment)")
+// moveCursor moves the menu cursor up (-1) or down (1)
+func (m *MenuModel) moveCursor(delta int) {
+	cursor := m.cursor + delta
+	if cursor >= 0 && cursor < len(m.items) {
+		m.cursor = cursor
+		m.showLearn = false
 	}
-	statusText := fmt.Sprintf("Branch: %s", branchDisplay)
-	if m.hasChanges {
-		statusText += " " + SuccessStyle.Render("(unsaved changes)")
+}
+
+// scrollChanges scrolls the selected file's diff if it's open, or moves to
+// the previous (-1) or next (1) file if not
+func (m *MenuModel) scrollChanges(delta int) {
+	if len(m.changedFiles) == 0 {
+		return
+	}
+	filePath := m.changedFiles[m.fileCursor].Path
+	if !m.expandedFiles[filePath] {
+		fileCursor := m.fileCursor + delta
+		if fileCursor >= 0 && fileCursor < len(m.changedFiles) {
+			m.fileCursor = fileCursor
+			m.keepFileVisible()
+		}
+		return
 	}
-	leftContent += HeaderBoxStyle.Render(statusText) + "\n\n"
 
-	// Title - show focus indicator
-	menuTitle := "What would you like to do?"
-	if showDiffPanel && !m.focusRight {
-		menuTitle = "▸ " + menuTitle
+	diffLines := strings.Split(m.fileDiffs[filePath], "\n")
+	maxScroll := len(diffLines) - m.getMaxDiffLines()
+	if maxScroll < 0 {
+		maxScroll = 0
 	}
-	leftContent += RenderTitle(menuTitle) + "\n\n"
+	offset := m.diffScrollOffset[filePath] + delta
+	if offset >= 0 && offset <= maxScroll {
+		m.diffScrollOffset[filePath] = offset
+	}
+}
+
+// toggleFileDiff opens or closes the diff of the selected file, loading
+// it in the background if it isn't cached
+func (m *MenuModel) toggleFileDiff() tea.Cmd {
+	filePath := m.changedFiles[m.fileCursor].Path
+	if m.expandedFiles[filePath] {
+		m.expandedFiles[filePath] = false
+		return nil
+	}
+	m.expandedFiles[filePath] = true
+	if _, ok := m.fileDiffs[filePath]; ok || m.loadingDiffs[filePath] {
+		return nil
+	}
+	m.loadingDiffs[filePath] = true
+	_, rightWidth, _ := m.panelSizes()
+	return loadFileDiff(filePath, m.diffStats[filePath].IsBinary, rightWidth-10)
+}
+
+// View renders the menu
+func (m MenuModel) View() string {
+	// Determine if we should show split view (need at least 90 chars wide)
+	showDiffPanel := m.width >= 90
+
+	// === LEFT PANEL: Menu ===
+	leftContent := m.renderMenuHeader(showDiffPanel)
 
 	// Menu items
 	// Hide descriptions if narrow OR short terminal
 	showDescriptions := m.width >= 60 && m.height >= 25
 
-	// Calculate visible range for scrolling
-	maxVisible := 12
-	start := 0
-	if m.cursor >= maxVisible {
-		start = m.cursor - maxVisible + 1
-	}
+	start := m.menuScrollStart()
 
-	for i := start; i < len(m.items) && i < start+maxVisible; i++ {
+	for i := start; i < len(m.items) && i < start+menuMaxVisible; i++ {
 		item := m.items[i]
 		cursor := "  "
 		style := MenuItemStyle
@@ -373,7 +732,7 @@ func (m MenuModel) View() string {
 		leftContent += cursor + title + "\n"
 	}
 
-	if len(m.items) > maxVisible {
+	if len(m.items) > menuMaxVisible {
 		leftContent += MutedStyle.Render(fmt.Sprintf("  ... %d total items\n", len(m.items)))
 	}
 
@@ -382,42 +741,13 @@ func (m MenuModel) View() string {
 		selectedDesc := m.items[m.cursor].Description
 		leftContent += "\n" + MutedStyle.Render("  "+selectedDesc) + "\n"
 	}
-
-	// Help bar - changes based on focus
-	var helpBar string
-	// Check if we're viewing an expanded diff
-	viewingExpandedDiff := false
-	if m.focusRight && len(m.changedFiles) > 0 {
-		filePath := m.changedFiles[m.fileCursor].Path
-		viewingExpandedDiff = m.expandedFiles[filePath]
+	if m.showLearn && !m.focusRight {
+		if learn := renderLearnMore(m.items[m.cursor].Action, 50); learn != "" {
+			leftContent += "\n" + learn + "\n"
+		}
 	}
 
-	if m.focusRight && viewingExpandedDiff {
-		helpBar = HelpBar([][]string{
-			{"↑↓", "scroll"},
-			{"⏎", "collapse"},
-			{"←", "menu"},
-		})
-	} else if m.focusRight {
-		helpBar = HelpBar([][]string{
-			{"↑↓", "navigate"},
-			{"⏎", "expand diff"},
-			{"←", "menu"},
-		})
-	} else if showDiffPanel && len(m.changedFiles) > 0 {
-		helpBar = HelpBar([][]string{
-			{"↑↓", "navigate"},
-			{"enter", "select"},
-			{"→", "changes"},
-			{"q", "quit"},
-		})
-	} else {
-		helpBar = HelpBar([][]string{
-			{"↑↓", "navigate"},
-			{"enter", "select"},
-			{"q", "quit"},
-		})
-	}
+	helpBar := HelpBar(m.Keys())
 
 	// If no split view, just return the menu
 	if !showDiffPanel {
@@ -428,27 +758,113 @@ func (m MenuModel) View() string {
 		return placeWithBottomHelp(content, helpBar, m.width, m.height)
 	}
 
-	// Calculate widths for split layout
-	leftWidth := m.width / 2
+	leftWidth, rightWidth, panelHeight := m.panelSizes()
+
+	leftPanel := lipgloss.NewStyle().
+		Width(leftWidth).
+		Height(panelHeight).
+		Padding(1, 2).
+		Render(leftContent)
+
+	// === RIGHT PANEL: Changed Files ===
+	rightContent, _ := m.renderChanges(rightWidth, panelHeight)
+
+	// Border color changes based on focus
+	borderColor := ColorSecondary
+	if m.focusRight {
+		borderColor = ColorPrimary
+	}
+
+	rightPanel := PanelStyle(borderColor).
+		Width(rightWidth).
+		Height(panelHeight-6). // Account for border and bottom help bar
+		Padding(1, 2).
+		Render(rightContent)
+
+	// Join panels horizontally
+	combined := SideBySide("", leftPanel, rightPanel)
+
+	// Place content at top, help bar at bottom center
+	return placeWithBottomHelp(combined, helpBar, m.width, m.height)
+}
+
+// renderMenuHeader renders everything above the menu items: the banner,
+// status bar and title
+func (m MenuModel) renderMenuHeader(showDiffPanel bool) string {
+	var leftContent string
+
+	// Banner (skip if narrow or short terminal, or read aloud)
+	if m.width >= 60 && m.height >= 30 && !accessibleMode {
+		leftContent += Banner() + "\n\n"
+	} else if m.height >= 20 {
+		leftContent += TitleStyle.Render("SMOOTH") + "\n\n"
+	}
+	// Skip title entirely if very short
+
+	// Status bar
+	if m.loading {
+		leftContent += HeaderBoxStyle.Render(m.spinner.View()+" "+MutedStyle.Render("Looking at your project...")) + "\n\n"
+		leftContent += RenderTitle(menuTitle(showDiffPanel, m.focusRight)) + "\n\n"
+		return leftContent
+	}
+	status, _ := m.StatusBar()
+	statusText := status.View()
+	leftContent += HeaderBoxStyle.Render(statusText) + "\n\n"
+	switch {
+	case m.quicksaving:
+		leftContent += RenderHighlight("Quick saving...") + "\n\n"
+	case m.quicksaveNote != "" && m.quicksaveFailed:
+		leftContent += RenderError("✗ "+m.quicksaveNote) + "\n\n"
+	case m.quicksaveNote != "":
+		leftContent += RenderSuccess("✓ "+m.quicksaveNote) + "\n\n"
+	}
+
+	leftContent += RenderTitle(menuTitle(showDiffPanel, m.focusRight)) + "\n\n"
+
+	return leftContent
+}
+
+// menuTitle is the title above the menu items, marked while the menu has
+// focus
+func menuTitle(showDiffPanel, focusRight bool) string {
+	title := "What would you like to do?"
+	if showDiffPanel && !focusRight {
+		title = "▸ " + title
+	}
+	return title
+}
+
+// menuScrollStart returns the first menu item shown, scrolled so the
+// cursor stays visible
+func (m MenuModel) menuScrollStart() int {
+	if m.cursor >= menuMaxVisible {
+		return m.cursor - menuMaxVisible + 1
+	}
+	return 0
+}
+
+// panelSizes returns the widths of the menu and changes panels and their
+// height in the split layout
+func (m MenuModel) panelSizes() (leftWidth, rightWidth, panelHeight int) {
+	leftWidth = m.width / 2
 	if leftWidth < 50 {
 		leftWidth = 50
 	}
-	rightWidth := m.width - leftWidth - 4
+	rightWidth = m.width - leftWidth - 4
 
 	// Use available height minus some margin
-	panelHeight := m.height - 2
+	panelHeight = m.height - 2
 	if panelHeight < 10 {
 		panelHeight = 10
 	}
+	return leftWidth, rightWidth, panelHeight
+}
 
-	leftPanel := lipgloss.NewStyle().
-		Width(leftWidth).
-		Height(panelHeight).
-		Padding(1, 2).
-		Render(leftContent)
-
-	// === RIGHT PANEL: Changed Files ===
+// renderChanges renders the changed files panel's content. rows says
+// which file each line belongs to, or -1 for lines that aren't a file's.
+func (m MenuModel) renderChanges(rightWidth, panelHeight int) (string, []int) {
 	var rightContent string
+	var rows []int
 
 	// Title with focus indicator
 	changesTitle := "Current Changes"
@@ -457,34 +873,16 @@ func (m MenuModel) View() string {
 	}
 	rightContent += RenderSubtitle(changesTitle) + "\n\n"
 
-	if len(m.changedFiles) == 0 {
+	if m.loading {
+		rightContent += m.spinner.View() + " " + MutedStyle.Render("Loading changes...") + "\n"
+	} else if len(m.changedFiles) == 0 {
 		rightContent += MutedStyle.Render("No uncommitted changes") + "\n"
 	} else {
-		// Calculate available lines for files (reserve space for scroll indicators)
-		maxVisibleFiles := panelHeight - 14
-		if maxVisibleFiles < 3 {
-			maxVisibleFiles = 3
-		}
-
+		// Only the files in view are rendered, from the offset kept by
+		// keepFileVisible
 		totalFiles := len(m.changedFiles)
-
-		// Calculate visible window around cursor
-		startFileIdx := 0
-		if m.fileCursor >= maxVisibleFiles {
-			startFileIdx = m.fileCursor - maxVisibleFiles + 1
-		}
-		// Adjust if we're near the end
-		if startFileIdx+maxVisibleFiles > totalFiles {
-			startFileIdx = totalFiles - maxVisibleFiles
-			if startFileIdx < 0 {
-				startFileIdx = 0
-			}
-		}
-
-		endFileIdx := startFileIdx + maxVisibleFiles
-		if endFileIdx > totalFiles {
-			endFileIdx = totalFiles
-		}
+		startFileIdx := min(m.fileOffset, totalFiles-1)
+		endFileIdx := min(startFileIdx+m.maxVisibleFiles(), totalFiles)
 
 		// Show scroll indicator if there are files above
 		if startFileIdx > 0 {
@@ -496,6 +894,7 @@ func (m MenuModel) View() string {
 		if maxFileLines < 5 {
 			maxFileLines = 5
 		}
+		highlight := syntaxHighlightEnabled()
 
 		for i := startFileIdx; i < endFileIdx; i++ {
 			file := m.changedFiles[i]
@@ -503,6 +902,9 @@ func (m MenuModel) View() string {
 			if lineCount >= maxFileLines {
 				break
 			}
+			for len(rows) < strings.Count(rightContent, "\n") {
+				rows = append(rows, -1)
+			}
 
 			// Status icon
 			var statusIcon string
@@ -560,12 +962,23 @@ func (m MenuModel) View() string {
 			}
 
 			// Truncate filename if needed (account for diff stats)
-			displayPath := truncateLine(file.Path, rightWidth-25)
+			displayPath := truncateLine(file.DisplayPath(), rightWidth-25)
+			// Permission and symlink changes have no line counts, so label them
+			if file.Detail != "" {
+				diffStatStr = " " + MutedStyle.Render(truncateLine(file.Detail, 40))
+			}
+			if until, ok := m.skipped[file.Path]; ok {
+				diffStatStr += " " + MutedStyle.Render("⏸ skipped "+skipRemaining(until))
+			}
+
 			rightContent += cursor + MutedStyle.Render(expandIcon) + " " + statusIcon + " " + fileStyle.Render(displayPath) + diffStatStr + "\n"
 			lineCount++
 
 			// Show diff if expanded
-			if m.expandedFiles[file.Path] {
+			if m.expandedFiles[file.Path] && m.loadingDiffs[file.Path] {
+				rightContent += MutedStyle.Render("    Loading diff...") + "\n"
+				lineCount++
+			} else if m.expandedFiles[file.Path] {
 				diff := m.fileDiffs[file.Path]
 				diffLines := strings.Split(diff, "\n")
 
@@ -592,24 +1005,19 @@ func (m MenuModel) View() string {
 					endIdx = totalLines
 				}
 
+				var lang chroma.Lexer
+				if highlight {
+					lang = syntaxFor(file.Path)
+				}
 				visibleLines := diffLines[scrollOffset:endIdx]
 				for _, line := range visibleLines {
 					if lineCount >= maxFileLines {
 						break
 					}
-					// Color-code diff lines
-					displayLine := truncateLine(line, rightWidth-10)
-					prefix := "    "
-					if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-						rightContent += prefix + SuccessStyle.Render(displayLine) + "\n"
-					} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-						rightContent += prefix + ErrorStyle.Render(displayLine) + "\n"
-					} else if strings.HasPrefix(line, "@@") {
-						rightContent += prefix + HighlightStyle.Render(displayLine) + "\n"
-					} else if strings.HasPrefix(line, "new file:") || strings.HasPrefix(line, "---") {
-						rightContent += prefix + MutedStyle.Render(displayLine) + "\n"
+					if m.binaryFiles[file.Path] {
+						rightContent += "    " + line + "\n"
 					} else {
-						rightContent += prefix + MutedStyle.Render(displayLine) + "\n"
+						rightContent += "    " + renderDiffLine(line, lang, rightWidth-10) + "\n"
 					}
 					lineCount++
 				}
@@ -621,6 +1029,9 @@ func (m MenuModel) View() string {
 					lineCount++
 				}
 			}
+			for len(rows) < strings.Count(rightContent, "\n") {
+				rows = append(rows, i)
+			}
 		}
 
 		// Show scroll indicator if there are files below
@@ -629,26 +1040,7 @@ func (m MenuModel) View() string {
 			rightContent += MutedStyle.Render(fmt.Sprintf("  ▼ %d more files below", remaining)) + "\n"
 		}
 	}
-
-	// Border color changes based on focus
-	borderColor := ColorSecondary
-	if m.focusRight {
-		borderColor = ColorPrimary
-	}
-
-	rightPanel := lipgloss.NewStyle().
-		Width(rightWidth).
-		Height(panelHeight-6). // Account for border and bottom help bar
-		Padding(1, 2).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(borderColor).
-		Render(rightContent)
-
-	// Join panels horizontally
-	combined := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, rightPanel)
-
-	// Place content at top, help bar at bottom center
-	return placeWithBottomHelp(combined, helpBar, m.width, m.height)
+	return rightContent, rows
 }
 
 // placeWithBottomHelp renders content with a help bar fixed at the bottom center
@@ -669,17 +1061,6 @@ func placeWithBottomHelp(content, helpBar string, width, height int) string {
 	return lipgloss.JoinVertical(lipgloss.Left, placedContent, centeredHelp)
 }
 
-// truncateLine truncates a line to fit within maxWidth
-func truncateLine(line string, maxWidth int) string {
-	if maxWidth < 10 {
-		maxWidth = 10
-	}
-	if len(line) > maxWidth {
-		return line[:maxWidth-3] + "..."
-	}
-	return line
-}
-
 // getMaxDiffLines returns the max number of diff lines that can be displayed
 func (m MenuModel) getMaxDiffLines() int {
 	panelHeight := m.height - 2
@@ -703,47 +1084,124 @@ func (m MenuModel) SelectedAction() MenuAction {
 	return m.items[m.cursor].Action
 }
 
+// SelectedPlugin returns the plugin behind the selected menu item, for
+// ActionPlugin
+func (m MenuModel) SelectedPlugin() plugins.Plugin {
+	return m.items[m.cursor].Plugin
+}
+
+// StartQuicksave saves the changes straight from the menu, or does nothing
+// if there's nothing to save or a quick save is already running
+func (m *MenuModel) StartQuicksave() tea.Cmd {
+	if m.quicksaving || !m.hasChanges {
+		return nil
+	}
+	m.quicksaving = true
+	m.quicksaveNote = ""
+	return doQuicksave()
+}
+
 // IsFocusedOnChanges returns true if the right panel (changes) is focused
 func (m MenuModel) IsFocusedOnChanges() bool {
 	return m.focusRight
 }
 
-// RefreshStatus updates the branch and changes status and returns a tick command
+// RefreshStatus reloads the branch and changes status in the background
+// and returns a command that does so along with restarting the tick
 func (m *MenuModel) RefreshStatus() tea.Cmd {
-	m.branch, _ = git.CurrentBranch()
-	m.hasChanges = git.HasChanges()
-	m.isOnMain = git.IsOnMain()
-	m.diff = git.GetDiff()
-	m.changedFiles, _ = git.GetChangeSummary()
-	m.items = m.buildMenuItems()
-	// Reset cursor if it's out of bounds
-	if m.cursor >= len(m.items) {
-		m.cursor = len(m.items) - 1
-	}
-	// Reset file cursor if out of bounds
-	if m.fileCursor >= len(m.changedFiles) {
-		m.fileCursor = max(0, len(m.changedFiles)-1)
+	m.clearDiffs()
+	// Return tick command to restart periodic refresh
+	return tea.Batch(m.tickCmd(), m.refresh(true))
+}
+
+// refresh loads the menu's status in the background. A regular refresh is
+// skipped while one is already running, so a slow git doesn't pile them
+// up; a full one, after a screen may have changed things, always runs.
+func (m *MenuModel) refresh(full bool) tea.Cmd {
+	if m.refreshing && !full {
+		return nil
 	}
-	// Clear cached diffs and expanded state on refresh
+	m.refreshing = true
+	return doLoadMenuStatus(m.largeRepo, full)
+}
+
+// clearDiffs forgets opened and cached diffs, which may be out of date
+func (m *MenuModel) clearDiffs() {
 	m.expandedFiles = make(map[string]bool)
 	m.fileDiffs = make(map[string]string)
+	m.loadingDiffs = make(map[string]bool)
+	m.diffOrder = nil
+	m.binaryFiles = make(map[string]bool)
 	m.diffScrollOffset = make(map[string]int)
-	// Refresh diff stats
-	m.diffStats = make(map[string]git.DiffStat)
-	if stats, err := git.GetUncommittedDiffStat(); err == nil {
-		for _, stat := range stats.Files {
-			path := strings.TrimSuffix(stat.Path, " (new)")
-			m.diffStats[path] = stat
-		}
+}
+
+// busyLabels describes git operations in progress in plain words
+var busyLabels = map[string]string{
+	"git push":     "Uploading to GitHub",
+	"git commit":   "Saving",
+	"git merge":    "Merging",
+	"git rebase":   "Tidying up saves",
+	"git reset":    "Restoring",
+	"git checkout": "Switching",
+	"git stash":    "Putting changes aside",
+}
+
+// busyLabel describes a git operation in progress, like "Saving"
+func busyLabel(op string) string {
+	if label, ok := busyLabels[op]; ok {
+		return label
 	}
-	// Return tick command to restart periodic refresh
-	return tickCmd()
+	return "Running " + op
+}
+
+// skipRemaining describes how long a file stays skipped, like "for 3 days"
+func skipRemaining(until time.Time) string {
+	left := time.Until(until)
+	if days := int(left.Hours() / 24); days >= 1 {
+		return "for " + plural(days, "day")
+	}
+	if hours := int(left.Hours()); hours >= 1 {
+		return "for " + plural(hours, "hour")
+	}
+	return "for under an hour"
 }
 
 // SetSize updates the terminal dimensions
 func (m *MenuModel) SetSize(width, height int) {
 	m.width = width
 	m.height = height
+	m.keepFileVisible()
+}
+
+// Keys returns the keys the menu takes: the menu's own, or the changes
+// panel's while it has focus
+func (m MenuModel) Keys() Keys {
+	if m.focusRight && len(m.changedFiles) > 0 {
+		if m.expandedFiles[m.changedFiles[m.fileCursor].Path] {
+			return Keys{
+				Bar:  []key.Binding{hint("↑↓", "scroll"), hint("⏎", "collapse"), hint("c", "copy diff"), hint("←", "menu")},
+				More: []key.Binding{vimKeys, hint("s", "skip/unskip"), hint("wheel", "scroll"), hint("click", "collapse")},
+			}
+		}
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("⏎", "expand diff"), hint("s", "skip/unskip"), hint("c", "copy path"), hint("←", "menu")},
+			More: []key.Binding{vimKeys, hint("wheel", "scroll"), hint("click", "pick a file, again to expand it")},
+		}
+	}
+	bar := []key.Binding{hint("↑↓", "navigate"), hint("enter", "select")}
+	if m.width >= 90 && len(m.changedFiles) > 0 {
+		bar = append(bar, hint("→", "changes"))
+	}
+	if _, ok := menuConcepts[m.items[m.cursor].Action]; ok {
+		bar = append(bar, hint("i", "learn more"))
+	}
+	if m.hasChanges {
+		bar = append(bar, hint("ctrl+s", "quick save"))
+	}
+	return Keys{
+		Bar:  append(bar, hint("q", "quit")),
+		More: []key.Binding{vimKeys, hint("wheel", "scroll"), hint("click", "select an item")},
+	}
 }
 
 // Key bindings
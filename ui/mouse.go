@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Screens work out what was clicked from the same layout they draw with:
+// the rows above a list are counted from the rendered text above it.
+
+// boxContentTop is the row a BoxStyle screen's content starts on, below
+// its border and padding
+const boxContentTop = 2
+
+// isLeftClick checks if msg is a press of the left mouse button
+func isLeftClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// wheelDelta returns -1 when the wheel scrolls up, 1 when it scrolls down
+// and 0 for anything else
+func wheelDelta(msg tea.MouseMsg) int {
+	if msg.Action != tea.MouseActionPress {
+		return 0
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return -1
+	case tea.MouseButtonWheelDown:
+		return 1
+	}
+	return 0
+}
+
+// linesAbove counts the rows text takes up when more is drawn after it
+func linesAbove(text string) int {
+	return strings.Count(text, "\n")
+}
+
+// pressEnter makes a click open what it selected, the same way pressing
+// Enter does
+func pressEnter() tea.Msg {
+	return tea.KeyMsg{Type: tea.KeyEnter}
+}
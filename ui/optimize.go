@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// OptimizeState represents the state of the optimize flow
+type OptimizeState int
+
+const (
+	OptimizeStateConfirm OptimizeState = iota
+	OptimizeStateWorking
+	OptimizeStateSuccess
+	OptimizeStateError
+	OptimizeStateCancelled
+)
+
+// OptimizeModel is the model for the "Optimize for big project" flow
+type OptimizeModel struct {
+	commits int
+	state   OptimizeState
+	result  git.OptimizeResult
+	err     error
+	frame   int // animates the progress bar while optimizing
+	width   int
+	height  int
+}
+
+// NewOptimizeModel creates a new optimize model
+func NewOptimizeModel() OptimizeModel {
+	return OptimizeModel{
+		commits: git.CommitCount(),
+		state:   OptimizeStateConfirm,
+	}
+}
+
+// Init initializes the model
+func (m OptimizeModel) Init() tea.Cmd {
+	return nil
+}
+
+// OptimizeMsg is sent when optimizing completes
+type OptimizeMsg struct {
+	Result git.OptimizeResult
+	Err    error
+}
+
+// doOptimize runs the one-time optimization and schedules maintenance
+func doOptimize() tea.Cmd {
+	return func() tea.Msg {
+		result, err := git.OptimizeRepo()
+		return OptimizeMsg{Result: result, Err: err}
+	}
+}
+
+// Update handles messages
+func (m OptimizeModel) Update(msg tea.Msg) (OptimizeModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case animFrameMsg:
+		if m.state == OptimizeStateWorking {
+			m.frame++
+			return m, animTick()
+		}
+		return m, nil
+
+	case OptimizeMsg:
+		m.result = msg.Result
+		if msg.Err != nil {
+			m.state = OptimizeStateError
+			m.err = msg.Err
+		} else {
+			m.state = OptimizeStateSuccess
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.state != OptimizeStateConfirm {
+			return m, nil
+		}
+		switch msg.String() {
+		case "y", "Y", "enter":
+			m.state = OptimizeStateWorking
+			return m, tea.Batch(animTick(), doOptimize())
+		case "n", "N":
+			m.state = OptimizeStateCancelled
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the optimize flow
+func (m OptimizeModel) View() string {
+	var s string
+
+	s += RenderTitle("Optimize for Big Project") + "\n\n"
+
+	switch m.state {
+	case OptimizeStateConfirm:
+		s += RenderHighlight(fmt.Sprintf("This project has %d saves.", m.commits)) + "\n\n"
+		s += RenderMuted("Git can keep an index of your history so the timeline, revert list,") + "\n"
+		s += RenderMuted("and change summaries load faster. This will:") + "\n\n"
+		s += "  " + MutedStyle.Render("• Build a commit-graph of your history now") + "\n"
+		s += "  " + MutedStyle.Render("• Keep it up to date whenever you save or sync") + "\n"
+		s += "  " + MutedStyle.Render("• Schedule git to tidy up in the background (hourly and daily)") + "\n\n"
+		s += RenderMuted("Your files and saves are not changed.") + "\n\n"
+		s += RenderSubtitle("Optimize now? (y/n)") + "\n"
+
+	case OptimizeStateWorking:
+		s += RenderHighlight("Optimizing... this can take a minute on very big projects") + "\n\n"
+		s += RenderShimmerBar(m.frame, 40) + "\n"
+
+	case OptimizeStateSuccess:
+		s += RenderSuccess("✓ Project optimized") + "\n\n"
+		s += RenderMuted(fmt.Sprintf("Reading history: %s → %s", formatDuration(m.result.Before), formatDuration(m.result.After))) + "\n"
+		if m.result.Scheduled {
+			s += RenderMuted("Background maintenance is scheduled.") + "\n\n"
+		} else {
+			s += RenderMuted("Couldn't schedule background maintenance on this computer,") + "\n"
+			s += RenderMuted("so git will tidy up after saves and syncs instead.") + "\n\n"
+		}
+		s += HelpText("Press any key to continue")
+
+	case OptimizeStateError:
+		s += RenderError("✗ Couldn't optimize this project") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// formatDuration rounds a duration for display
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// Keys returns the keys the optimize flow takes in its current state
+func (m OptimizeModel) Keys() Keys {
+	if m.state == OptimizeStateConfirm {
+		return Keys{More: []key.Binding{hint("y", "optimize"), hint("n", "no, go back")}}
+	}
+	return Keys{}
+}
+
+// WantsBack returns true if the user declined to optimize
+func (m OptimizeModel) WantsBack() bool {
+	return m.state == OptimizeStateCancelled
+}
+
+// IsDone returns true if optimizing has finished
+func (m OptimizeModel) IsDone() bool {
+	return m.state == OptimizeStateSuccess || m.state == OptimizeStateError
+}
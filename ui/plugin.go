@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/plugins"
+)
+
+// PluginState represents the state of a running plugin
+type PluginState int
+
+const (
+	PluginStateRunning PluginState = iota
+	PluginStateDone
+	PluginStateError
+)
+
+// PluginModel runs a plugin from ~/.smooth/plugins and shows what it
+// reported
+type PluginModel struct {
+	plugin  plugins.Plugin
+	spinner spinner.Model
+	state   PluginState
+	result  plugins.Result
+	err     error
+	height  int
+}
+
+// PluginMsg is sent when a plugin finishes
+type PluginMsg struct {
+	Result plugins.Result
+	Err    error
+}
+
+// NewPluginModel creates a model that runs plugin
+func NewPluginModel(plugin plugins.Plugin) PluginModel {
+	return PluginModel{plugin: plugin, spinner: newSpinner(SpinnerDots)}
+}
+
+// Init starts the plugin
+func (m PluginModel) Init() tea.Cmd {
+	return tea.Batch(spinnerTick(m.spinner), doRunPlugin(m.plugin))
+}
+
+// doRunPlugin runs a plugin in the background
+func doRunPlugin(plugin plugins.Plugin) tea.Cmd {
+	return func() tea.Msg {
+		result, err := plugins.Run(plugin)
+		return PluginMsg{Result: result, Err: err}
+	}
+}
+
+// Update handles messages
+func (m PluginModel) Update(msg tea.Msg) (PluginModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+
+	case PluginMsg:
+		if msg.Err != nil {
+			m.state = PluginStateError
+			m.err = msg.Err
+		} else {
+			m.state = PluginStateDone
+			m.result = msg.Result
+		}
+
+	case spinner.TickMsg:
+		if m.state == PluginStateRunning {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// View renders the plugin screen
+func (m PluginModel) View() string {
+	var s string
+
+	title := m.plugin.Name
+	if m.state == PluginStateDone && m.result.Title != "" {
+		title = m.result.Title
+	}
+	s += RenderTitle(title) + "\n\n"
+
+	switch m.state {
+	case PluginStateRunning:
+		s += m.spinner.View() + " " + RenderHighlight("Running "+m.plugin.Name+"...") + "\n"
+
+	case PluginStateError:
+		s += RenderError("✗ Couldn't run the plugin") + "\n\n"
+		s += renderErrorDetails(m.err)
+		s += HelpText("Press any key to go back")
+
+	case PluginStateDone:
+		if m.result.OK {
+			s += RenderSuccess("✓ Done") + "\n\n"
+		} else {
+			s += RenderError("✗ The plugin reported a problem") + "\n\n"
+		}
+		if m.result.Message != "" {
+			s += m.renderOutput() + "\n"
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderOutput shows as much of the plugin's message as fits
+func (m PluginModel) renderOutput() string {
+	maxLines := 20
+	if m.height > 0 {
+		maxLines = max(3, m.height-12)
+	}
+	lines := strings.Split(m.result.Message, "\n")
+	var s string
+	for i, line := range lines {
+		if i == maxLines {
+			s += RenderMuted(fmt.Sprintf("... %d more line(s)", len(lines)-maxLines)) + "\n"
+			break
+		}
+		s += truncateLine(line, 76) + "\n"
+	}
+	return s
+}
+
+// Keys returns the keys the plugin screen takes: none, any key goes back
+// once it's finished
+func (m PluginModel) Keys() Keys {
+	return Keys{}
+}
+
+// IsDone returns true once the plugin has finished, so any key goes back
+func (m PluginModel) IsDone() bool {
+	return m.state != PluginStateRunning
+}
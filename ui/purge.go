@@ -0,0 +1,285 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// PurgeState represents the state of the remove-from-history flow
+type PurgeState int
+
+const (
+	PurgeStatePath PurgeState = iota
+	PurgeStateConfirm
+	PurgeStateWorking
+	PurgeStateDone
+	PurgeStatePushing
+	PurgeStatePushed
+	PurgeStateError
+)
+
+// PurgeModel walks through removing a file from every save, for a .env or
+// key that was saved by mistake: back up, rewrite, check it's gone, then
+// replace the history on GitHub
+type PurgeModel struct {
+	spinner   spinner.Model
+	state     PurgeState
+	textInput textinput.Model
+	path      string
+	saves     int // saves that added or changed the file
+	result    git.PurgeResult
+	hasRemote bool
+	notice    string // why the path can't be removed
+	err       error
+}
+
+// PurgeMsg is sent when history has been rewritten
+type PurgeMsg struct {
+	Result git.PurgeResult
+	Err    error
+}
+
+// PurgePushedMsg is sent when the rewritten history has been uploaded
+type PurgePushedMsg struct {
+	Err error
+}
+
+// NewPurgeModel creates a model for removing a file from history
+func NewPurgeModel() PurgeModel {
+	ti := textinput.New()
+	ti.Placeholder = ".env"
+	ti.CharLimit = 200
+	ti.Width = 40
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+	ti.Focus()
+
+	return PurgeModel{
+		spinner:   newSpinner(SpinnerDots),
+		textInput: ti,
+		hasRemote: git.HasRemote(),
+	}
+}
+
+// Init initializes the model
+func (m PurgeModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// doPurge removes path from every save
+func doPurge(path string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := git.PurgeFile(path)
+		return PurgeMsg{Result: result, Err: err}
+	}
+}
+
+// doPushRewritten uploads the rewritten branches and tags
+func doPushRewritten(result git.PurgeResult) tea.Cmd {
+	return func() tea.Msg {
+		return PurgePushedMsg{Err: git.PushRewritten(result)}
+	}
+}
+
+// Update handles messages
+func (m PurgeModel) Update(msg tea.Msg) (PurgeModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PurgeMsg:
+		m.result = msg.Result
+		if msg.Err != nil {
+			m.state = PurgeStateError
+			m.err = msg.Err
+			return m, nil
+		}
+		m.state = PurgeStateDone
+		return m, nil
+
+	case PurgePushedMsg:
+		if msg.Err != nil {
+			m.state = PurgeStateError
+			m.err = msg.Err
+			return m, nil
+		}
+		m.state = PurgeStatePushed
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == PurgeStateWorking || m.state == PurgeStatePushing {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case PurgeStatePath:
+			if msg.String() != "enter" {
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+			path := strings.TrimSpace(m.textInput.Value())
+			if path == "" {
+				return m, nil
+			}
+			saves, err := git.SavesWithFile(path)
+			if err != nil || saves == 0 {
+				m.notice = "No saves have " + path + ". Check the path is from the project's top folder."
+				return m, nil
+			}
+			m.path, m.saves, m.notice = path, saves, ""
+			m.textInput.Blur()
+			m.state = PurgeStateConfirm
+
+		case PurgeStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				// Rewriting history always asks, however experienced the user
+				m.state = PurgeStateWorking
+				return m, tea.Batch(spinnerTick(m.spinner), doPurge(m.path))
+			case "n", "N", "esc":
+				m.state = PurgeStatePath
+				m.textInput.Focus()
+				return m, textinput.Blink
+			}
+
+		case PurgeStateDone:
+			if msg.String() == "f" && m.hasRemote {
+				m.state = PurgeStatePushing
+				return m, tea.Batch(spinnerTick(m.spinner), doPushRewritten(m.result))
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the remove-from-history flow
+func (m PurgeModel) View() string {
+	var s string
+
+	s += RenderTitle("Remove a file from history") + "\n\n"
+
+	switch m.state {
+	case PurgeStatePath:
+		s += RenderMuted("Saved a .env, key or password file by mistake? Deleting it only") + "\n"
+		s += RenderMuted("stops new saves having it. This takes it out of every save.") + "\n\n"
+		s += RenderSubtitle("Which file?") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		if m.notice != "" {
+			s += RenderError("✗ "+m.notice) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case PurgeStateConfirm:
+		s += RenderError("⚠ Remove "+m.path+" from all history?") + "\n\n"
+		s += RenderMuted(plural(m.saves, "save")+" added or changed it. Every save since it was first") + "\n"
+		s += RenderMuted("added is rewritten and gets a new ID.") + "\n\n"
+		s += RenderMuted("• Each branch is backed up first, so this can be undone") + "\n"
+		s += RenderMuted("• Your copy of "+m.path+" stays, added to .gitignore") + "\n"
+		if m.hasRemote {
+			s += RenderMuted("• Anyone else with the project will need to download it again") + "\n"
+		}
+		s += "\n" + RenderSubtitle("Rewrite history? (y/n)") + "\n"
+
+	case PurgeStateWorking:
+		s += m.spinner.View() + " " + RenderHighlight("Rewriting history... this can take a while") + "\n"
+
+	case PurgeStateDone:
+		s += m.renderResult()
+
+	case PurgeStatePushing:
+		s += m.spinner.View() + " " + RenderHighlight("Replacing the history on GitHub...") + "\n"
+
+	case PurgeStatePushed:
+		s += RenderSuccess("✓ GitHub has the rewritten history") + "\n\n"
+		s += RenderMuted("If the project is public, GitHub may still show cached copies of the") + "\n"
+		s += RenderMuted("old saves. GitHub Support can remove them.") + "\n\n"
+		s += RenderHighlight("If it was a password or key, change it now: someone may have copied it.") + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case PurgeStateError:
+		s += RenderError("✗ Couldn't finish removing "+m.path) + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		if len(m.result.Backups) > 0 {
+			s += RenderMuted("Your branches were backed up first; Restore backup puts them back.") + "\n\n"
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderResult shows whether the file is gone, and what's left to do
+func (m PurgeModel) renderResult() string {
+	var s string
+	r := m.result
+	if r.Remaining == 0 {
+		s += RenderSuccess("✓ "+m.path+" is gone from every branch") + "\n\n"
+	} else {
+		s += RenderError(fmt.Sprintf("✗ %s is still in history (%s)", m.path, plural(r.Remaining, "save"))) + "\n\n"
+	}
+	s += RenderMuted("Rewritten: "+strings.Join(slices.Concat(r.Branches, r.Tags), ", ")) + "\n"
+	if r.Snapshots > 0 {
+		s += RenderMuted("Snapshots had it too, and were rewritten without it.") + "\n"
+	}
+	if r.KeptCopy {
+		s += RenderMuted("Your copy is still here, and .gitignore keeps it out of saves.") + "\n"
+	}
+	s += "\n"
+	s += RenderMuted("The backups still have it on this computer, and syncing leaves them") + "\n"
+	s += RenderMuted("out. Once you've checked everything, delete them from Restore backup:") + "\n"
+	for _, backup := range r.Backups {
+		s += "  " + HighlightStyle.Render(backup) + "\n"
+	}
+	s += "\n"
+	s += RenderHighlight("If it was a password or key, change it now: someone may have copied it.") + "\n\n"
+
+	if m.hasRemote {
+		s += RenderMuted("GitHub still has the old saves. Uploading replaces them, and stops") + "\n"
+		s += RenderMuted("if someone synced in the meantime.") + "\n\n"
+		s += HelpBar(m.Keys())
+	} else {
+		s += HelpText("Press esc to continue")
+	}
+	return s
+}
+
+// Keys returns the keys the remove-from-history flow takes in its current
+// state
+func (m PurgeModel) Keys() Keys {
+	switch m.state {
+	case PurgeStatePath:
+		return Keys{Bar: []key.Binding{hint("enter", "continue"), hint("esc", "back")}, Typing: true}
+	case PurgeStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "take it out of every save"), hint("n", "no, pick another file")}}
+	case PurgeStateDone:
+		if m.hasRemote {
+			return Keys{Bar: []key.Binding{hint("f", "upload rewritten history"), hint("esc", "done")}}
+		}
+		return Keys{Bar: []key.Binding{hint("esc", "done")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true once the history on GitHub is replaced or something
+// failed, so any key goes back
+func (m PurgeModel) IsDone() bool {
+	return m.state == PurgeStatePushed || m.state == PurgeStateError
+}
+
+// HandlesEsc returns true while confirming, where esc goes back to picking
+// the file
+func (m PurgeModel) HandlesEsc() bool {
+	return m.state == PurgeStateConfirm
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/achievements"
+	"smooth/config"
+	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+)
+
+// QuicksaveMsg is sent when a quick save from the menu finishes
+type QuicksaveMsg struct {
+	Result  SaveMsg // its Message is what was saved, template filled in
+	Stopped string  // why the quick save needs the Save screen instead, if it does
+	SyncErr error   // set when auto-sync couldn't upload the save
+}
+
+// doQuicksave saves every changed file the menu isn't skipping, with a
+// message made up from the files, without opening the Save screen.
+// Anything that needs a decision, like a large file, a possible secret or
+// a failing check, stops the quick save so it can be done from Save.
+func doQuicksave() tea.Cmd {
+	return func() tea.Msg {
+		return quicksave(quicksaveMessage, false)
+	}
+}
+
+// doSaveOnQuit quick saves with a timestamped message on the way out,
+// uploading the save with sync even when auto-sync is off
+func doSaveOnQuit(sync bool) tea.Cmd {
+	return func() tea.Msg {
+		message := quitMessage(time.Now())
+		return quicksave(func([]SaveFileItem) string { return message }, sync)
+	}
+}
+
+// quitMessage is the message for changes saved on quit, like
+// "WIP: quit at 2024-03-08 17:45"
+func quitMessage(now time.Time) string {
+	return "WIP: quit at " + now.Format("2006-01-02 15:04")
+}
+
+// tooLarge says which file to be saved is over the large file limit, since
+// a save nobody is watching can't ask what to do with it, or returns ""
+func tooLarge(files []SaveFileItem, limitMB int) string {
+	var paths []string
+	for _, f := range files {
+		if f.Action == FileActionSave && f.Change.Status != "deleted" {
+			paths = append(paths, f.Change.Path)
+		}
+	}
+	if large := git.FindLargeFiles(paths, int64(limitMB)<<20); len(large) > 0 {
+		return fmt.Sprintf("%s is over %d MB", large[0].Path, limitMB)
+	}
+	return ""
+}
+
+// quicksave does a quick save with the message describe makes up from the
+// files, then uploads it if auto-sync is on or sync is set
+func quicksave(describe func([]SaveFileItem) string, sync bool) QuicksaveMsg {
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		return QuicksaveMsg{Result: SaveMsg{Err: err}}
+	}
+	files := ops.NewFiles(changes)
+	if !hasAction(files, FileActionSave) {
+		return QuicksaveMsg{Stopped: "nothing to save, every changed file is skipped"}
+	}
+	cfg, _ := config.Load()
+	if reason := tooLarge(files, cfg.LargeFileMB); reason != "" {
+		return QuicksaveMsg{Stopped: reason}
+	}
+
+	result := Save(describe(files), files, nil, false, nil)
+	var secrets *ops.SecretsFoundError
+	var checks *ops.ChecksFailedError
+	switch {
+	case errors.As(result.Err, &secrets):
+		return QuicksaveMsg{Stopped: secrets.Error()}
+	case errors.As(result.Err, &checks):
+		return QuicksaveMsg{Stopped: checks.Error()}
+	case result.Err != nil:
+		return QuicksaveMsg{Result: result}
+	}
+
+	msg := QuicksaveMsg{Result: result}
+	if result.SavedCount > 0 {
+		achievements.RecordSave(result.LinesAdded, result.LinesDeleted)
+		if (cfg.AutoSyncEnabled || sync) && git.HasRemote() {
+			ctx, cancel := networkContext()
+			err := git.Push(ctx)
+			cancel()
+			if err != nil {
+				git.QueueSync(err)
+				notify.SyncFailed(err)
+				msg.SyncErr = err
+			} else {
+				achievements.RecordSync()
+			}
+		}
+	}
+	return msg
+}
+
+// quicksaveNote sums up how a quick save went for the menu, and whether
+// it's a problem
+func quicksaveNote(msg QuicksaveMsg) (string, bool) {
+	switch {
+	case msg.Stopped != "":
+		return "Quick save stopped: " + msg.Stopped + ". Use Save to review.", true
+	case msg.Result.Err != nil:
+		reason, _, _ := strings.Cut(msg.Result.Err.Error(), "\n")
+		return "Quick save failed: " + reason, true
+	case msg.SyncErr != nil:
+		return fmt.Sprintf("Saved %q, upload will retry", msg.Result.Message), false
+	}
+	return fmt.Sprintf("Saved %q (%s)", msg.Result.Message, msg.Result.Hash), false
+}
+
+// hasAction checks if any file is set to action
+func hasAction(files []SaveFileItem, action FileAction) bool {
+	for _, f := range files {
+		if f.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// quicksaveMessage describes the files being saved, like "Update login.html
+// and style.css" or "Add a.go, b.go and 3 other files"
+func quicksaveMessage(files []SaveFileItem) string {
+	var names []string
+	counts := make(map[string]int)
+	for _, f := range files {
+		if f.Action != FileActionSave {
+			continue
+		}
+		names = append(names, path.Base(f.Change.Path))
+		counts[f.Change.Status]++
+	}
+
+	verb := "Update"
+	switch len(names) {
+	case counts["added"]:
+		verb = "Add"
+	case counts["deleted"]:
+		verb = "Remove"
+	}
+
+	switch len(names) {
+	case 1:
+		return verb + " " + names[0]
+	case 2, 3:
+		return verb + " " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+	return fmt.Sprintf("%s %s, %s and %d other files", verb, names[0], names[1], len(names)-2)
+}
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// QuitState represents the state of the quit confirmation
+type QuitState int
+
+const (
+	QuitStateConfirm QuitState = iota
+	QuitStateSaving
+	QuitStateFailed
+)
+
+// QuitModel asks before quitting with unsaved changes, and can quick save
+// them on the way out
+type QuitModel struct {
+	spinner  spinner.Model
+	unsaved  int
+	state    QuitState
+	note     string // why the quick save didn't go through
+	sync     bool   // saving on quit uploads the save too
+	wantQuit bool
+	wantBack bool
+	width    int
+	height   int
+}
+
+// NewQuitModel creates a quit confirmation for unsaved changed files
+func NewQuitModel(unsaved int) QuitModel {
+	return QuitModel{
+		spinner: newSpinner(SpinnerDots),
+		unsaved: unsaved,
+	}
+}
+
+// NewSaveOnQuitModel creates a quit screen that saves unsaved changed
+// files straight away with a timestamped message, and uploads them too
+// with sync
+func NewSaveOnQuitModel(unsaved int, sync bool) QuitModel {
+	m := NewQuitModel(unsaved)
+	m.state = QuitStateSaving
+	m.sync = sync
+	return m
+}
+
+// Init initializes the model
+func (m QuitModel) Init() tea.Cmd {
+	if m.state == QuitStateSaving {
+		return tea.Batch(spinnerTick(m.spinner), doSaveOnQuit(m.sync))
+	}
+	return nil
+}
+
+// Update handles messages
+func (m QuitModel) Update(msg tea.Msg) (QuitModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == QuitStateSaving {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case QuicksaveMsg:
+		if m.state != QuitStateSaving {
+			return m, nil
+		}
+		// A save that couldn't upload is still saved, and the upload
+		// retries next time
+		if note, failed := quicksaveNote(msg); failed {
+			m.state = QuitStateFailed
+			m.note = note
+			return m, nil
+		}
+		m.wantQuit = true
+		return m, nil
+
+	case tea.KeyMsg:
+		// ctrl+c always gets out, even while saving
+		if msg.String() == "ctrl+c" {
+			m.wantQuit = true
+			return m, nil
+		}
+		switch m.state {
+		case QuitStateConfirm:
+			switch msg.String() {
+			case "s", "S", "y", "Y", "enter":
+				m.state = QuitStateSaving
+				return m, tea.Batch(spinnerTick(m.spinner), doQuicksave())
+			case "q", "Q":
+				m.wantQuit = true
+			case "n", "N":
+				m.wantBack = true
+			}
+		case QuitStateFailed:
+			if msg.String() == "q" || msg.String() == "Q" {
+				m.wantQuit = true
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the quit confirmation
+func (m QuitModel) View() string {
+	var s string
+
+	s += RenderTitle("Quit") + "\n\n"
+
+	switch m.state {
+	case QuitStateConfirm:
+		s += RenderHighlight(fmt.Sprintf("You have %s — save before quitting?", plural(m.unsaved, "unsaved file"))) + "\n\n"
+		s += RenderMuted("A quick save saves every changed file you aren't skipping,") + "\n"
+		s += RenderMuted("with a message made up from them.") + "\n"
+		s += HelpBar(m.Keys())
+
+	case QuitStateSaving:
+		if m.sync {
+			s += m.spinner.View() + " " + RenderHighlight("Saving and uploading before quitting...") + "\n"
+		} else {
+			s += m.spinner.View() + " " + RenderHighlight("Saving before quitting...") + "\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case QuitStateFailed:
+		s += RenderError("✗ "+m.note) + "\n\n"
+		s += RenderMuted("Your changes are still there, unsaved.") + "\n"
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// Keys returns the keys the quit confirmation takes in its current state
+func (m QuitModel) Keys() Keys {
+	switch m.state {
+	case QuitStateConfirm:
+		return Keys{
+			Bar:  []key.Binding{hint("s", "save and quit"), hint("q", "quit without saving"), hint("esc", "stay")},
+			More: []key.Binding{hint("y/enter", "save and quit"), hint("n", "stay")},
+		}
+	case QuitStateSaving:
+		return Keys{Bar: []key.Binding{hint("ctrl+c", "quit without waiting")}}
+	case QuitStateFailed:
+		return Keys{Bar: []key.Binding{hint("q", "quit anyway"), hint("esc", "back to the menu")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true while saving, so esc doesn't leave the save
+// running behind the menu
+func (m QuitModel) HandlesEsc() bool {
+	return m.state == QuitStateSaving
+}
+
+// WantsQuit returns true once the changes are saved, or the user chose to
+// quit without saving them
+func (m QuitModel) WantsQuit() bool {
+	return m.wantQuit
+}
+
+// WantsBack returns true if the user chose to stay
+func (m QuitModel) WantsBack() bool {
+	return m.wantBack
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+	"smooth/ops"
+)
+
+// RecoverState represents the state of the interrupted operation flow
+type RecoverState int
+
+const (
+	RecoverStateChoose RecoverState = iota
+	RecoverStateWorking
+	RecoverStateDone
+	RecoverStateError
+)
+
+// RecoverModel offers to finish or undo an operation that was cut short,
+// like a save interrupted after reverting files but before saving
+type RecoverModel struct {
+	spinner spinner.Model
+	state   RecoverState
+	journal git.Journal
+	cursor  int // 0 finishes, 1 undoes; only undoing is offered if it can't be finished
+	message string
+	err     error
+}
+
+// recoverMsg is sent when the operation was finished or undone
+type recoverMsg struct {
+	message string
+	err     error
+}
+
+// NewRecoverModel creates a model for the operation journal found
+func NewRecoverModel(journal git.Journal) RecoverModel {
+	m := RecoverModel{spinner: newSpinner(SpinnerDots), journal: journal}
+	if !m.canFinish() {
+		m.cursor = 1
+	}
+	return m
+}
+
+// Init initializes the model
+func (m RecoverModel) Init() tea.Cmd {
+	return nil
+}
+
+// canFinish reports if the operation can run again, rather than only be
+// undone
+func (m RecoverModel) canFinish() bool {
+	return ops.CanResume(m.journal)
+}
+
+// doRecover finishes the operation, or undoes it
+func doRecover(journal git.Journal, finish bool) tea.Cmd {
+	return func() tea.Msg {
+		if finish {
+			if _, err := ops.ResumeSave(journal); err != nil {
+				return recoverMsg{err: err}
+			}
+			return recoverMsg{message: "The save is finished"}
+		}
+		if err := journal.Rollback(); err != nil {
+			return recoverMsg{err: err}
+		}
+		return recoverMsg{message: "Everything is back the way it was before"}
+	}
+}
+
+// Update handles messages
+func (m RecoverModel) Update(msg tea.Msg) (RecoverModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case recoverMsg:
+		if msg.err != nil {
+			m.state = RecoverStateError
+			m.err = msg.err
+			return m, nil
+		}
+		m.state = RecoverStateDone
+		m.message = msg.message
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == RecoverStateWorking {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.KeyMsg:
+		if m.state != RecoverStateChoose {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 && m.canFinish() {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < 1 {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Enter):
+			m.state = RecoverStateWorking
+			return m, tea.Batch(spinnerTick(m.spinner), doRecover(m.journal, m.cursor == 0))
+		}
+	}
+	return m, nil
+}
+
+// View renders the interrupted operation flow
+func (m RecoverModel) View() string {
+	var s string
+
+	s += RenderTitle("Finish what was interrupted") + "\n\n"
+
+	switch m.state {
+	case RecoverStateChoose:
+		what := m.journal.Operation
+		if what == "save" {
+			what = "saving"
+		}
+		s += RenderError("⚠ smooth stopped in the middle of "+what+" "+timeAgo(m.journal.Started)) + "\n\n"
+		s += RenderMuted("Some files may be changed and others not yet.") + "\n"
+		if len(m.journal.Files) > 0 {
+			s += RenderMuted("It had a copy of "+plural(len(m.journal.Files), "file")+" from before it started.") + "\n"
+		}
+		s += "\n"
+
+		options := []struct{ title, description string }{
+			{"Finish it", "Run the " + m.journal.Operation + " again from the start"},
+			{"Undo it", "Put the files back the way they were before"},
+		}
+		for i, option := range options {
+			cursor, style := "  ", ListItemStyle
+			if i == m.cursor {
+				cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+			}
+			if i == 0 && !m.canFinish() {
+				style = MutedStyle
+			}
+			s += cursor + style.Render(option.title) + "\n"
+			s += "    " + MutedStyle.Render(option.description) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+
+	case RecoverStateWorking:
+		s += m.spinner.View() + " " + RenderHighlight("Sorting it out...") + "\n"
+
+	case RecoverStateDone:
+		s += RenderSuccess("✓ "+m.message) + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case RecoverStateError:
+		s += RenderError("✗ Couldn't sort it out") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to continue")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// Keys returns the keys the screen takes in its current state
+func (m RecoverModel) Keys() Keys {
+	if m.state == RecoverStateChoose {
+		return Keys{Bar: []key.Binding{hint("↑↓", "choose"), hint("enter", "select")}, More: []key.Binding{vimKeys}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true once the operation is finished or undone, or that
+// failed
+func (m RecoverModel) IsDone() bool {
+	return m.state == RecoverStateDone || m.state == RecoverStateError
+}
+
+// HandlesEsc returns true until a choice is made, so the half-done
+// operation isn't left behind
+func (m RecoverModel) HandlesEsc() bool {
+	return m.state == RecoverStateChoose || m.state == RecoverStateWorking
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// Refreshing the menu runs git status and diffs over the whole project.
+// In projects with tens of thousands of files that takes long enough to
+// make the menu stutter, so while git status is over budget the menu
+// switches to large repo mode: it refreshes less often, skips the
+// line-ending check, and only counts lines for the files it can show.
+const (
+	statusBudget       = 250 * time.Millisecond
+	largeRepoInterval  = 10 * time.Second
+	largeRepoDiffFiles = 50
+)
+
+// RefreshTimings is how long the menu's last refresh took
+type RefreshTimings struct {
+	Status    time.Duration // git status, which decides large repo mode
+	Diff      time.Duration // line counts and the line-ending check
+	LargeRepo bool
+	At        time.Time
+}
+
+// lastRefresh is the menu's last refresh, shown in Settings
+var lastRefresh RefreshTimings
+
+// largeRepoMode decides if the menu should refresh like in a huge project:
+// always or never if set in Settings, or otherwise while git status is
+// over budget. Once on it stays on until status is well under budget, so
+// it doesn't flip back and forth around the limit.
+func largeRepoMode(mode string, was bool, status time.Duration) bool {
+	switch mode {
+	case config.LargeRepoOn:
+		return true
+	case config.LargeRepoOff:
+		return false
+	}
+	if was {
+		return status > statusBudget/2
+	}
+	return status > statusBudget
+}
+
+// menuChanges is the unsaved changes and their line counts, loaded for the
+// menu's changes panel
+type menuChanges struct {
+	files     []git.FileChange
+	diff      string
+	eolChurn  []string
+	diffStats map[string]git.DiffStat
+	largeRepo bool
+	timings   RefreshTimings
+}
+
+// loadChanges loads the unsaved changes and their line counts, timing how
+// long git takes to decide on large repo mode. largeRepo is whether the
+// menu is in large repo mode now.
+func loadChanges(largeRepo bool) menuChanges {
+	cfg, _ := config.Load()
+	var c menuChanges
+
+	start := time.Now()
+	c.files, _ = git.GetChangeSummary()
+	status := time.Since(start)
+	c.largeRepo = largeRepoMode(cfg.LargeRepoMode, largeRepo, status)
+
+	start = time.Now()
+	var stats git.CommitDiffSummary
+	var err error
+	if c.largeRepo {
+		paths := make([]string, 0, largeRepoDiffFiles)
+		for _, f := range c.files[:min(len(c.files), largeRepoDiffFiles)] {
+			paths = append(paths, f.Path)
+		}
+		stats, err = git.GetUncommittedDiffStatFor(paths)
+	} else {
+		c.diff = git.GetDiff()
+		c.eolChurn, _ = git.LineEndingChurn()
+		stats, err = git.GetUncommittedDiffStat()
+	}
+	c.diffStats = make(map[string]git.DiffStat)
+	if err == nil {
+		for _, stat := range stats.Files {
+			// Clean up path (remove " (new)" suffix if present)
+			path := strings.TrimSuffix(stat.Path, " (new)")
+			c.diffStats[path] = stat
+		}
+	}
+
+	c.timings = RefreshTimings{Status: status, Diff: time.Since(start), LargeRepo: c.largeRepo, At: time.Now()}
+	git.DebugLogf("menu refresh: status %s, diffs %s, %d changed files, large repo mode %t",
+		c.timings.Status.Round(time.Millisecond), c.timings.Diff.Round(time.Millisecond), len(c.files), c.largeRepo)
+	return c
+}
+
+// MenuStatusMsg is sent when what the menu shows about the project has
+// loaded in the background
+type MenuStatusMsg struct {
+	full bool // whether the rarely changing parts were loaded too
+
+	branch       string
+	detached     bool
+	isOnMain     bool
+	changes      menuChanges
+	skipped      map[string]time.Time
+	stashes      int
+	savesWaiting int
+	ahead        int
+	behind       int
+	retryDue     bool // a queued sync is due another try
+	hasRemote    bool
+	lastSave     time.Time
+
+	// Only loaded when full
+	needsOptimize bool
+	debugLogs     bool
+	pendingSync   bool
+	backups       int // of this branch
+}
+
+// doLoadMenuStatus loads what the menu shows off the UI, so slow git
+// commands never freeze it. full loads what only changes between screens,
+// like whether the project needs optimizing, as well.
+func doLoadMenuStatus(largeRepo, full bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := MenuStatusMsg{full: full}
+		msg.branch, _ = git.CurrentBranch()
+		msg.detached = git.IsDetached()
+		msg.isOnMain = git.IsOnMain()
+		msg.changes = loadChanges(largeRepo)
+		msg.skipped = git.SkippedFiles()
+		msg.stashes = git.StashCount()
+		msg.savesWaiting = git.SavesWaiting()
+		msg.ahead, msg.behind = git.AheadBehind()
+		msg.retryDue = git.SyncRetryDue()
+		msg.hasRemote = git.HasRemote()
+		msg.lastSave = git.LastSaveTime()
+		if full {
+			msg.needsOptimize = git.NeedsOptimizing()
+			msg.debugLogs = git.DebugLogging() || git.HasDebugLogs()
+			_, msg.pendingSync = git.LoadPendingSync()
+			msg.backups = git.CountBackups(msg.branch)
+		}
+		return msg
+	}
+}
+
+// applyStatus shows a status loaded by doLoadMenuStatus
+func (m *MenuModel) applyStatus(msg MenuStatusMsg) {
+	m.branch = msg.branch
+	m.detached = msg.detached
+	m.isOnMain = msg.isOnMain
+	m.changedFiles = msg.changes.files
+	m.hasChanges = len(m.changedFiles) > 0
+	m.diff = msg.changes.diff
+	m.eolChurn = msg.changes.eolChurn
+	m.diffStats = msg.changes.diffStats
+	m.largeRepo = msg.changes.largeRepo
+	lastRefresh = msg.changes.timings
+	m.skipped = msg.skipped
+	m.stashes = msg.stashes
+	m.savesWaiting = msg.savesWaiting
+	m.ahead, m.behind = msg.ahead, msg.behind
+	m.lastSave = msg.lastSave
+	if msg.full {
+		m.needsOptimize = msg.needsOptimize
+		m.debugLogs = msg.debugLogs
+		m.backups = msg.backups
+	}
+	m.items = m.buildMenuItems()
+	// Reset cursors if they're out of bounds
+	if m.cursor >= len(m.items) {
+		m.cursor = len(m.items) - 1
+	}
+	if m.fileCursor >= len(m.changedFiles) {
+		m.fileCursor = max(0, len(m.changedFiles)-1)
+	}
+	m.keepFileVisible()
+}
+
+// tickCmd returns a command that sends a tick after the refresh interval,
+// or less often in large repo mode
+func (m MenuModel) tickCmd() tea.Cmd {
+	interval := refreshInterval
+	if m.largeRepo {
+		interval = largeRepoInterval
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
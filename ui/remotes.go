@@ -0,0 +1,312 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// RemotesState represents the state of the remotes screen
+type RemotesState int
+
+const (
+	RemotesStateList RemotesState = iota
+	RemotesStateAddName
+	RemotesStateAddURL
+	RemotesStateConfirmRemove
+	RemotesStateError
+)
+
+// RemotesModel is the model for choosing where Sync uploads to
+type RemotesModel struct {
+	remotes   []git.Remote // origin first
+	cursor    int
+	state     RemotesState
+	textInput textinput.Model
+	name      string // name of the remote being added
+	err       error
+	message   string
+	notice    string // why the last name or address wasn't taken
+}
+
+// NewRemotesModel creates a new remotes model
+func NewRemotesModel() RemotesModel {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m := RemotesModel{textInput: ti}
+	m.reload()
+	return m
+}
+
+// reload re-reads the project's remotes, keeping the cursor in range
+func (m *RemotesModel) reload() {
+	remotes, err := git.ListRemotes()
+	if err != nil {
+		m.state = RemotesStateError
+		m.err = err
+		return
+	}
+	m.remotes = remotes
+	if m.cursor >= len(m.remotes) {
+		m.cursor = max(0, len(m.remotes)-1)
+	}
+}
+
+// Init initializes the model
+func (m RemotesModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m RemotesModel) Update(msg tea.Msg) (RemotesModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch m.state {
+	case RemotesStateList:
+		m.message = ""
+		switch {
+		case key.Matches(keyMsg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(keyMsg, keys.Down):
+			if m.cursor < len(m.remotes)-1 {
+				m.cursor++
+			}
+		case keyMsg.String() == " " || key.Matches(keyMsg, keys.Enter):
+			if len(m.remotes) == 0 {
+				return m, nil
+			}
+			remote := m.remotes[m.cursor]
+			if remote.Name == "origin" {
+				m.message = "Sync always uploads to origin"
+				return m, nil
+			}
+			if err := git.SetRemoteSync(remote.Name, !remote.Sync); err != nil {
+				m.state = RemotesStateError
+				m.err = err
+				return m, nil
+			}
+			m.reload()
+			if remote.Sync {
+				m.message = fmt.Sprintf("Sync won't upload to %s anymore", remote.Name)
+			} else {
+				m.message = fmt.Sprintf("Sync uploads to %s too", remote.Name)
+			}
+		case keyMsg.String() == "a" || keyMsg.String() == "n":
+			m.state = RemotesStateAddName
+			m.name = ""
+			m.notice = ""
+			m.textInput.Placeholder = "backup"
+			m.textInput.SetValue("")
+			m.textInput.Focus()
+			return m, textinput.Blink
+		case keyMsg.String() == "d" || keyMsg.String() == "delete" || keyMsg.String() == "backspace":
+			if len(m.remotes) == 0 {
+				return m, nil
+			}
+			if m.remotes[m.cursor].Name == "origin" {
+				m.message = "origin is where Sync uploads, so it stays"
+				return m, nil
+			}
+			m.state = RemotesStateConfirmRemove
+		}
+
+	case RemotesStateAddName:
+		switch keyMsg.String() {
+		case "enter":
+			name := strings.TrimSpace(m.textInput.Value())
+			if name == "" {
+				return m, nil
+			}
+			for _, r := range m.remotes {
+				if r.Name == name {
+					m.notice = "There's already a remote called " + name
+					return m, nil
+				}
+			}
+			m.name = name
+			m.notice = ""
+			m.state = RemotesStateAddURL
+			m.textInput.Placeholder = "git@github.com:username/repo-backup.git"
+			m.textInput.SetValue("")
+			return m, nil
+		case "esc":
+			m.textInput.Blur()
+			m.state = RemotesStateList
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(keyMsg)
+		return m, cmd
+
+	case RemotesStateAddURL:
+		switch keyMsg.String() {
+		case "enter":
+			url := strings.TrimSpace(m.textInput.Value())
+			if url == "" {
+				return m, nil
+			}
+			if err := git.AddSyncRemote(m.name, url); err != nil {
+				reason, _, _ := strings.Cut(err.Error(), "\n")
+				m.notice = reason
+				return m, nil
+			}
+			m.textInput.Blur()
+			m.state = RemotesStateList
+			m.reload()
+			m.message = fmt.Sprintf("Added %s; Sync uploads to it too", m.name)
+			return m, nil
+		case "esc":
+			m.notice = ""
+			m.state = RemotesStateAddName
+			m.textInput.Placeholder = "backup"
+			m.textInput.SetValue(m.name)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(keyMsg)
+		return m, cmd
+
+	case RemotesStateConfirmRemove:
+		switch keyMsg.String() {
+		case "y", "Y":
+			remote := m.remotes[m.cursor]
+			if err := git.RemoveRemote(remote.Name); err != nil {
+				m.state = RemotesStateError
+				m.err = err
+				return m, nil
+			}
+			m.state = RemotesStateList
+			m.reload()
+			m.message = fmt.Sprintf("Removed %s", remote.Name)
+		case "n", "N", "esc":
+			m.state = RemotesStateList
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the remotes screen
+func (m RemotesModel) View() string {
+	var s string
+
+	s += RenderTitle("Remotes") + "\n\n"
+
+	switch m.state {
+	case RemotesStateError:
+		s += RenderError("✗ Couldn't update the remotes") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+
+	case RemotesStateAddName:
+		s += RenderSubtitle("Name for the new remote:") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		if m.notice != "" {
+			s += RenderError("✗ "+m.notice) + "\n\n"
+		}
+		s += RenderMuted("A short name like backup or gitlab.") + "\n"
+		s += HelpBar(m.Keys())
+
+	case RemotesStateAddURL:
+		s += RenderSubtitle("Address of "+m.name+":") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		if m.notice != "" {
+			s += RenderError("✗ "+m.notice) + "\n\n"
+		}
+		s += RenderMuted("Copy it from the repository's page, like you did for GitHub.") + "\n"
+		s += HelpBar(m.Keys())
+
+	case RemotesStateConfirmRemove:
+		remote := m.remotes[m.cursor]
+		s += "Remove remote: " + HighlightStyle.Render(remote.Name) + "\n\n"
+		s += RenderMuted("Nothing on it is deleted; Sync just stops uploading there.") + "\n\n"
+		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+
+	case RemotesStateList:
+		if len(m.remotes) == 0 {
+			s += RenderMuted("No remotes yet. Sync to GitHub sets up origin, or add") + "\n"
+			s += RenderMuted("one here.") + "\n\n"
+		} else {
+			s += RenderMuted("Sync uploads to origin, then to every other remote that's on.") + "\n\n"
+			s += m.renderRemoteList() + "\n"
+		}
+
+		if m.message != "" {
+			s += RenderSuccess("✓ "+m.message) + "\n\n"
+		}
+
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderRemoteList renders each remote with whether Sync uploads to it
+func (m RemotesModel) renderRemoteList() string {
+	var s string
+	for i, remote := range m.remotes {
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.cursor {
+			cursor = MenuCursorStyle.Render("> ")
+			style = ListItemSelectedStyle
+		}
+
+		check := "[ ]"
+		if remote.Sync {
+			check = "[✓]"
+		} else if i != m.cursor {
+			style = ListItemStyle.Foreground(ColorMuted)
+		}
+		line := cursor + style.Render(check+" "+padRight(truncateLine(remote.Name, 12), 12))
+		line += " " + MutedStyle.Render(truncateLine(remote.URL, 60))
+		s += line + "\n"
+	}
+	return s
+}
+
+// Keys returns the keys the remotes screen takes in its current state
+func (m RemotesModel) Keys() Keys {
+	switch m.state {
+	case RemotesStateList:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("space", "sync on/off"), hint("a", "add"), hint("d", "remove"), hint("esc", "back")},
+			More: []key.Binding{vimKeys, hint("enter", "sync on/off"), hint("n", "add"), hint("delete", "remove")},
+		}
+	case RemotesStateAddName:
+		return Keys{Bar: []key.Binding{hint("enter", "next"), hint("esc", "cancel")}, Typing: true}
+	case RemotesStateAddURL:
+		return Keys{Bar: []key.Binding{hint("enter", "add"), hint("esc", "back")}, Typing: true}
+	case RemotesStateConfirmRemove:
+		return Keys{More: []key.Binding{hint("y", "remove the remote"), hint("n", "no, keep it")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if the screen should close on the next key
+func (m RemotesModel) IsDone() bool {
+	return m.state == RemotesStateError
+}
+
+// HandlesEsc returns true while esc should cancel adding or removing a
+// remote rather than leave the screen
+func (m RemotesModel) HandlesEsc() bool {
+	return m.state == RemotesStateAddName || m.state == RemotesStateAddURL || m.state == RemotesStateConfirmRemove
+}
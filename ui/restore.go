@@ -4,19 +4,23 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/alecthomas/chroma/v2"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"smooth/config"
 	"smooth/git"
+	"smooth/notify"
 )
 
 // RestoreState represents the state of the restore flow
 type RestoreState int
 
 const (
-	RestoreStateList RestoreState = iota
+	RestoreStateLoading RestoreState = iota
+	RestoreStateList
 	RestoreStateConfirm
 	RestoreStateRestoring
 	RestoreStateSuccess
@@ -24,71 +28,133 @@ const (
 	RestoreStateEmpty
 )
 
+// Headings of the commit list, which clicks are measured from
+const (
+	restoreTitle      = "Revert to Previous State"
+	restoreListPrompt = "Select a save point to revert back to:"
+)
+
 // RestoreModel is the model for the restore flow
 type RestoreModel struct {
-	commits       []git.CommitInfo
-	cursor        int
-	state         RestoreState
-	err           error
-	selected      git.CommitInfo
-	branch        string
-	backupName    string
-	width         int
-	height        int
-	diffPreview   git.CommitDiffSummary // Preview of file changes
-	uncommitted   git.CommitDiffSummary // Current uncommitted changes
-	hasUncommit   bool                  // Whether there are uncommitted changes
-	prevCursor    int                   // Track cursor changes for preview updates
+	spinner      spinner.Model
+	commits      []git.CommitInfo
+	cursor       int
+	state        RestoreState
+	err          error
+	selected     git.CommitInfo
+	branch       string
+	backupName   string
+	width        int
+	height       int
+	diffPreview  git.CommitDiffSummary // Preview of file changes
+	diffText     string                // Diff being undone, for the excerpt in the preview
+	uncommitted  git.CommitDiffSummary // Current uncommitted changes
+	hasUncommit  bool                  // Whether there are uncommitted changes
+	prevCursor   int                   // Track cursor changes for preview updates
+	confirm      confirmation
+	keepFiles    bool   // move the save point but leave the files as they are
+	asExperiment bool   // open the save as a new experiment instead of reverting
+	experiment   string // the experiment the save was opened as
+	moreCommits  bool   // whether older saves load on scrolling down
+	previewing   bool   // whether the selected save's preview is loading
+	loaded       bool   // whether the saves have loaded
+	wantDetail   bool   // whether the user asked for the selected save's details
 }
 
-// NewRestoreModel creates a new restore model
+// NewRestoreModel creates a new restore model. The saves load in Init.
 func NewRestoreModel() RestoreModel {
-	commits, err := git.Log(20)
-	branch, _ := git.CurrentBranch()
+	return RestoreModel{
+		spinner:    newSpinner(SpinnerDots),
+		cursor:     0,
+		state:      RestoreStateLoading,
+		prevCursor: -1, // Force initial update
+		confirm:    newConfirmation(),
+	}
+}
+
+// restoreLoadedMsg is sent when the saves and unsaved changes have loaded
+type restoreLoadedMsg struct {
+	commits     []git.CommitInfo
+	more        bool
+	branch      string
+	uncommitted git.CommitDiffSummary
+}
 
-	state := RestoreStateList
-	if err != nil || len(commits) == 0 {
-		state = RestoreStateEmpty
+// doLoadRestore loads the newest saves, the branch and the unsaved changes
+// reverting would lose
+func doLoadRestore() tea.Cmd {
+	return func() tea.Msg {
+		var msg restoreLoadedMsg
+		msg.commits, msg.more, _ = firstCommitPage()
+		msg.branch, _ = git.CurrentBranch()
+		msg.uncommitted, _ = git.GetUncommittedDiffStat()
+		return msg
 	}
+}
 
-	// Get uncommitted changes
-	uncommitted, _ := git.GetUncommittedDiffStat()
-	hasUncommit := len(uncommitted.Files) > 0
+// restorePreviewMsg is sent when the preview of reverting to a save has
+// loaded
+type restorePreviewMsg struct {
+	hash  string
+	stats git.CommitDiffSummary
+	diff  string
+}
 
-	// Get initial diff preview (comparing HEAD to first commit if any)
-	var diffPreview git.CommitDiffSummary
-	if len(commits) > 0 {
-		diffPreview, _ = git.GetDiffStatBetweenCommits(commits[0].FullHash, "HEAD")
+// doLoadRestorePreview works out what reverting to a save would undo. The
+// newest save undoes nothing but unsaved changes, so it has no diff.
+func doLoadRestorePreview(hash string, newest bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := restorePreviewMsg{hash: hash}
+		msg.stats, _ = git.GetDiffStatBetweenCommits(hash, "HEAD")
+		if !newest {
+			msg.diff, _ = git.GetDiffBetweenCommits(hash, "HEAD")
+		}
+		return msg
 	}
+}
 
-	return RestoreModel{
-		commits:     commits,
-		cursor:      0,
-		state:       state,
-		branch:      branch,
-		diffPreview: diffPreview,
-		uncommitted: uncommitted,
-		hasUncommit: hasUncommit,
-		prevCursor:  -1, // Force initial update
+// NewRestoreModelForCommit creates a restore model that skips the list and
+// asks straight away whether to revert to the given commit. The list still
+// loads, for if the user says no.
+func NewRestoreModelForCommit(commit git.CommitInfo) RestoreModel {
+	m := NewRestoreModel()
+	if commit.FullHash == "" {
+		return m
+	}
+	m.selected = commit
+	m.state = RestoreStateConfirm
+	if m.confirm.done() {
+		m.state = RestoreStateRestoring
 	}
+	return m
 }
 
-// Init initializes the restore model
+// Init initializes the restore model, starting the restore straight away
+// if it was handed a commit and no confirmation is needed
 func (m RestoreModel) Init() tea.Cmd {
-	return nil
+	if m.state == RestoreStateRestoring {
+		return doRestore(m.selected.FullHash, m.branch, false)
+	}
+	return tea.Batch(spinnerTick(m.spinner), doLoadRestore())
 }
 
 // RestoreMsg is sent when a restore operation completes
 type RestoreMsg struct {
 	Err        error
 	BackupName string
+	Experiment string // set when the save was opened as an experiment
 }
 
-// doRestore creates a backup then performs the git reset
-func doRestore(commitHash string, branch string) tea.Cmd {
+// doRestore creates a backup then performs the git reset. With keepFiles
+// only the save point moves, and the files stay as they are. branch is
+// looked up if it hasn't loaded yet.
+func doRestore(commitHash string, branch string, keepFiles bool) tea.Cmd {
 	return func() tea.Msg {
+		if branch == "" {
+			branch, _ = git.CurrentBranch()
+		}
 		// Create a backup first
-		backupName, err := git.CreateBackup(branch)
+		backupName, err := git.CreateBackup(branch, git.BackupBeforeRestore)
 		if err != nil {
 			return RestoreMsg{Err: fmt.Errorf("failed to create backup: %w", err)}
 		}
@@ -98,17 +164,35 @@ func doRestore(commitHash string, branch string) tea.Cmd {
 		git.TrimBackups(branch, cfg.MaxBackups)
 
 		// Now do the reset
-		err = git.ResetHard(commitHash)
+		if keepFiles {
+			err = git.ResetKeepFiles(commitHash)
+		} else {
+			err = git.ResetHard(commitHash)
+		}
 		if err != nil {
 			return RestoreMsg{Err: err, BackupName: backupName}
 		}
+		notify.Restored("save " + commitHash)
 
 		return RestoreMsg{Err: nil, BackupName: backupName}
 	}
 }
 
+// doOpenAsExperiment opens a save as a new experiment, leaving the branch
+// and its saves as they are, so nothing needs backing up
+func doOpenAsExperiment(commitHash string) tea.Cmd {
+	return func() tea.Msg {
+		experiment, err := git.OpenAsExperiment(commitHash)
+		return RestoreMsg{Err: err, Experiment: experiment}
+	}
+}
+
 // Update handles messages for the restore model
 func (m RestoreModel) Update(msg tea.Msg) (RestoreModel, tea.Cmd) {
+	// Asking for details lasts one message, so coming back to the list
+	// doesn't open them again
+	m.wantDetail = false
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -117,6 +201,7 @@ func (m RestoreModel) Update(msg tea.Msg) (RestoreModel, tea.Cmd) {
 
 	case RestoreMsg:
 		m.backupName = msg.BackupName
+		m.experiment = msg.Experiment
 		if msg.Err != nil {
 			m.state = RestoreStateError
 			m.err = msg.Err
@@ -125,6 +210,36 @@ func (m RestoreModel) Update(msg tea.Msg) (RestoreModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case restoreLoadedMsg:
+		m.commits = msg.commits
+		m.moreCommits = msg.more
+		m.branch = msg.branch
+		m.uncommitted = msg.uncommitted
+		m.hasUncommit = len(msg.uncommitted.Files) > 0
+		m.loaded = true
+		// Handed a commit, the confirmation is already showing
+		if m.state == RestoreStateLoading {
+			m.state = m.listState()
+		}
+
+	case restorePreviewMsg:
+		// Only the selected save's preview is wanted, not one moved past
+		if len(m.commits) == 0 || msg.hash != m.commits[m.cursor].FullHash {
+			return m, nil
+		}
+		m.previewing = false
+		m.diffPreview = msg.stats
+		m.diffText = msg.diff
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loaded {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		switch m.state {
 		case RestoreStateList:
@@ -139,44 +254,126 @@ func (m RestoreModel) Update(msg tea.Msg) (RestoreModel, tea.Cmd) {
 				}
 			case key.Matches(msg, keys.Enter):
 				m.selected = m.commits[m.cursor]
+				if m.confirm.done() {
+					m.state = RestoreStateRestoring
+					return m, doRestore(m.selected.FullHash, m.branch, false)
+				}
+				m.state = RestoreStateConfirm
+			case msg.String() == "m" && m.cursor > 0:
+				// The later saves are taken off the branch, so this asks
+				// like a revert does
+				m.selected = m.commits[m.cursor]
+				m.keepFiles = true
+				if m.confirm.done() {
+					m.state = RestoreStateRestoring
+					return m, doRestore(m.selected.FullHash, m.branch, true)
+				}
 				m.state = RestoreStateConfirm
+			case msg.String() == "e":
+				// The branch keeps its saves, so there's nothing to confirm
+				return m.openAsExperiment(m.commits[m.cursor])
+			case msg.String() == "i":
+				m.wantDetail = true
+			case msg.String() == "c":
+				return m, copyToClipboard(m.commits[m.cursor].FullHash, "the hash")
 			}
 
 		case RestoreStateConfirm:
 			switch msg.String() {
 			case "y", "Y":
-				m.state = RestoreStateRestoring
-				return m, doRestore(m.selected.FullHash, m.branch)
+				if m.confirm.confirm() {
+					m.state = RestoreStateRestoring
+					return m, doRestore(m.selected.FullHash, m.branch, m.keepFiles)
+				}
+			case "m", "M":
+				// Switching to keeping the files asks again, about that
+				if !m.keepFiles {
+					m.keepFiles = true
+					m.confirm.reset()
+				}
+			case "e", "E":
+				return m.openAsExperiment(m.selected)
 			case "n", "N", "esc":
-				m.state = RestoreStateList
+				m.confirm.reset()
+				m.keepFiles = false
+				m.state = m.listState()
 			}
 		}
+
+	case tea.MouseMsg:
+		if m.state != RestoreStateList {
+			break
+		}
+		if delta := wheelDelta(msg); delta != 0 {
+			if cursor := m.cursor + delta; cursor >= 0 && cursor < len(m.commits) {
+				m.cursor = cursor
+			}
+		} else if i, ok := m.commitAt(msg.X, msg.Y); ok && isLeftClick(msg) {
+			// Clicking the selected save reverts to it, like Enter
+			if i == m.cursor {
+				return m, pressEnter
+			}
+			m.cursor = i
+		}
 	}
 
-	// Update diff preview when cursor changes
+	// Load the diff preview in the background when the cursor changes
 	if m.state == RestoreStateList && m.cursor != m.prevCursor && len(m.commits) > 0 {
 		m.prevCursor = m.cursor
-		// Get diff between selected commit and HEAD
-		m.diffPreview, _ = git.GetDiffStatBetweenCommits(m.commits[m.cursor].FullHash, "HEAD")
+		m.commits, m.moreCommits = loadMoreCommits(m.commits, m.cursor, m.moreCommits)
+		m.previewing = true
+		return m, doLoadRestorePreview(m.commits[m.cursor].FullHash, m.cursor == 0)
 	}
 
 	return m, nil
 }
 
+// openAsExperiment starts opening a save as a new experiment
+func (m RestoreModel) openAsExperiment(commit git.CommitInfo) (RestoreModel, tea.Cmd) {
+	m.selected = commit
+	m.asExperiment = true
+	m.state = RestoreStateRestoring
+	return m, doOpenAsExperiment(commit.FullHash)
+}
+
+// branchName returns the branch the saves are on, or a stand-in while it
+// loads
+func (m RestoreModel) branchName() string {
+	if m.branch == "" {
+		return "Your branch"
+	}
+	return m.branch
+}
+
+// listState is the state showing the list of saves: still loading, the
+// list, or empty if there are none
+func (m RestoreModel) listState() RestoreState {
+	switch {
+	case !m.loaded:
+		return RestoreStateLoading
+	case len(m.commits) == 0:
+		return RestoreStateEmpty
+	}
+	return RestoreStateList
+}
+
 // View renders the restore flow
 func (m RestoreModel) View() string {
 	var s string
 
-	s += RenderTitle("Revert to Previous State") + "\n\n"
+	s += RenderTitle(restoreTitle) + "\n\n"
 
 	switch m.state {
+	case RestoreStateLoading:
+		s += m.spinner.View() + " " + RenderHighlight("Loading your saves...") + "\n"
+
 	case RestoreStateEmpty:
 		s += RenderMuted("No save points found!") + "\n\n"
 		s += RenderMuted("Save your progress first before you can restore.") + "\n\n"
 		s += HelpText("Press any key to go back")
 
 	case RestoreStateList:
-		s += RenderSubtitle("Select a save point to revert back to:") + "\n\n"
+		s += RenderSubtitle(restoreListPrompt) + "\n\n"
 
 		// Build left panel (commit list)
 		leftPanel := m.renderCommitList()
@@ -185,31 +382,62 @@ func (m RestoreModel) View() string {
 		rightPanel := m.renderPreviewPanel()
 
 		// Join panels side by side
-		content := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, "  ", rightPanel)
+		content := SideBySide("  ", leftPanel, rightPanel)
 		s += content + "\n\n"
 
-		s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "select"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
 
 	case RestoreStateConfirm:
-		s += RenderError("⚠ Warning: This will discard current changes!") + "\n\n"
+		if m.keepFiles {
+			s += "Move the save point to: " + HighlightStyle.Render(m.selected.Hash) + "\n"
+			s += RenderMuted(strings.Join(wrapText(m.selected.Message, 60), "\n")) + "\n\n"
+			s += RenderMuted("Your files stay as they are, and what the later saves changed") + "\n"
+			s += RenderMuted("becomes unsaved changes. A backup will be created first.") + "\n\n"
+			s += m.confirm.prompt("Are you sure?") + "\n"
+			break
+		}
+		if m.confirm.warn {
+			s += RenderError("⚠ Warning: This will discard current changes!") + "\n\n"
+		}
 		s += "Restore to: " + HighlightStyle.Render(m.selected.Hash) + "\n"
-		s += RenderMuted(m.selected.Message) + "\n\n"
+		s += RenderMuted(strings.Join(wrapText(m.selected.Message, 60), "\n")) + "\n\n"
 		s += RenderMuted("A backup will be created before restoring.") + "\n\n"
-		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+		s += RenderMuted("Or press m to keep your files as they are and just move the save") + "\n"
+		s += RenderMuted("point: what the later saves changed becomes unsaved changes.") + "\n"
+		s += RenderMuted("Or press e to open it as a new experiment and leave your saves as they are.") + "\n\n"
+		s += m.confirm.prompt("Are you sure?") + "\n"
 
 	case RestoreStateRestoring:
-		s += RenderHighlight("Creating backup and restoring...") + "\n"
+		if m.asExperiment {
+			s += RenderHighlight("Opening as an experiment...") + "\n"
+		} else {
+			s += RenderHighlight("Creating backup and restoring...") + "\n"
+		}
 
 	case RestoreStateSuccess:
-		s += RenderSuccess("✓ Restored!") + "\n\n"
-		s += RenderMuted("Your project has been restored to the selected state.") + "\n"
+		if m.asExperiment {
+			s += RenderSuccess("✓ Opened as an experiment!") + "\n\n"
+			s += RenderMuted("You're on "+m.experiment+", starting from "+m.selected.Hash+".") + "\n"
+			s += RenderMuted(m.branchName()+" still has all its saves. Keep or abandon the experiment") + "\n"
+			s += RenderMuted("from the menu when you're done with it.") + "\n\n"
+			s += HelpText("Press any key to continue")
+			break
+		}
+		if m.keepFiles {
+			s += RenderSuccess("✓ Save point moved!") + "\n\n"
+			s += RenderMuted("Your files haven't changed. What the later saves did is now") + "\n"
+			s += RenderMuted("unsaved changes, ready to save again however you like.") + "\n"
+		} else {
+			s += RenderSuccess("✓ Restored!") + "\n\n"
+			s += RenderMuted("Your project has been restored to the selected state.") + "\n"
+		}
 		s += RenderMuted("Backup created: ") + MutedStyle.Render(m.backupName) + "\n\n"
 		s += HelpText("Press any key to continue")
 
 	case RestoreStateError:
 		s += RenderError("✗ Restore failed") + "\n\n"
 		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 	}
@@ -217,12 +445,11 @@ func (m RestoreModel) View() string {
 	return BoxStyle.Render(s)
 }
 
-// renderCommitList renders the left panel with the commit list
-func (m RestoreModel) renderCommitList() string {
-	var lines []string
-
+// commitListWindow returns the first commit shown and how many fit,
+// scrolled so the cursor stays visible
+func (m RestoreModel) commitListWindow() (start, maxVisible int) {
 	// Calculate maxVisible based on terminal height
-	maxVisible := 8
+	maxVisible = 8
 	if m.height > 0 {
 		available := m.height - 14 // Reserve space for chrome
 		maxVisible = available / 3 // Each item is ~3 lines
@@ -234,10 +461,34 @@ func (m RestoreModel) renderCommitList() string {
 		}
 	}
 
-	start := 0
 	if m.cursor >= maxVisible {
 		start = m.cursor - maxVisible + 1
 	}
+	return start, maxVisible
+}
+
+// commitAt returns which commit in the list is drawn at x, y
+func (m RestoreModel) commitAt(x, y int) (int, bool) {
+	top := boxContentTop + linesAbove(RenderTitle(restoreTitle)+"\n\n"+RenderSubtitle(restoreListPrompt)+"\n\n")
+	// The list is 50 wide, inside the box's border and padding
+	if x < 3 || x >= 53 || y < top {
+		return 0, false
+	}
+	start, maxVisible := m.commitListWindow()
+	// Each commit takes two lines and a blank one
+	row := y - top
+	if row%3 == 2 || row/3 >= maxVisible {
+		return 0, false
+	}
+	i := start + row/3
+	return i, i < len(m.commits)
+}
+
+// renderCommitList renders the left panel with the commit list
+func (m RestoreModel) renderCommitList() string {
+	var lines []string
+
+	start, maxVisible := m.commitListWindow()
 
 	for i := start; i < len(m.commits) && i < start+maxVisible; i++ {
 		commit := m.commits[i]
@@ -250,18 +501,19 @@ func (m RestoreModel) renderCommitList() string {
 		}
 
 		// Format: hash - message (time ago)
-		line := fmt.Sprintf("%s %s", commit.Hash, commit.Message)
-		if len(line) > 45 {
-			line = line[:42] + "..."
-		}
+		line := truncateLine(fmt.Sprintf("%s %s", commit.Hash, commit.Message), 45)
 
 		lines = append(lines, cursor+style.Render(line))
-		lines = append(lines, "    "+MutedStyle.Render(commit.Timestamp))
+		details := MutedStyle.Render(commit.Timestamp)
+		if commit.Signed() {
+			details += " " + SignedBadge()
+		}
+		lines = append(lines, "    "+details)
 		lines = append(lines, "")
 	}
 
 	if len(m.commits) > maxVisible {
-		lines = append(lines, MutedStyle.Render(fmt.Sprintf("  ... %d total saves", len(m.commits))))
+		lines = append(lines, MutedStyle.Render("  ... "+savesLoadedNote(len(m.commits), m.moreCommits)))
 	}
 
 	// Set a fixed width for the left panel
@@ -274,9 +526,7 @@ func (m RestoreModel) renderPreviewPanel() string {
 	var lines []string
 
 	// Panel styling
-	panelStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ColorSecondary).
+	panelStyle := PanelStyle(ColorSecondary).
 		Padding(0, 1).
 		Width(40)
 
@@ -292,7 +542,7 @@ func (m RestoreModel) renderPreviewPanel() string {
 		if m.hasUncommit {
 			lines = append(lines, MutedStyle.Render("Uncommitted changes will be lost:"))
 			lines = append(lines, "")
-			lines = append(lines, m.renderFileStats(m.uncommitted, 6)...)
+			lines = append(lines, renderFileStats(m.uncommitted, 6)...)
 		} else {
 			lines = append(lines, MutedStyle.Render("No uncommitted changes."))
 			lines = append(lines, "")
@@ -307,11 +557,7 @@ func (m RestoreModel) renderPreviewPanel() string {
 		maxCommitsToShow := 4
 		for i := 0; i < m.cursor && i < maxCommitsToShow; i++ {
 			c := m.commits[i]
-			msg := c.Message
-			if len(msg) > 30 {
-				msg = msg[:27] + "..."
-			}
-			lines = append(lines, MutedStyle.Render(fmt.Sprintf("  • %s", msg)))
+			lines = append(lines, MutedStyle.Render("  • "+truncateLine(c.Message, 30)))
 		}
 		if m.cursor > maxCommitsToShow {
 			lines = append(lines, MutedStyle.Render(fmt.Sprintf("  ... and %d more", m.cursor-maxCommitsToShow)))
@@ -319,10 +565,12 @@ func (m RestoreModel) renderPreviewPanel() string {
 		lines = append(lines, "")
 
 		// Show file changes
-		if len(m.diffPreview.Files) > 0 {
+		if m.previewing {
+			lines = append(lines, MutedStyle.Render("Working out file changes..."))
+		} else if len(m.diffPreview.Files) > 0 {
 			lines = append(lines, MutedStyle.Render("File changes:"))
 			lines = append(lines, "")
-			lines = append(lines, m.renderFileStats(m.diffPreview, 5)...)
+			lines = append(lines, renderFileStats(m.diffPreview, 5)...)
 
 			// Summary
 			lines = append(lines, "")
@@ -330,6 +578,12 @@ func (m RestoreModel) renderPreviewPanel() string {
 				addStyle.Render(fmt.Sprintf("+%d", m.diffPreview.TotalAdded)),
 				delStyle.Render(fmt.Sprintf("-%d", m.diffPreview.TotalDeleted)))
 			lines = append(lines, MutedStyle.Render("Total: ")+summary)
+
+			if excerpt := m.renderDiffExcerpt(8); len(excerpt) > 0 {
+				lines = append(lines, "")
+				lines = append(lines, MutedStyle.Render("Changes being undone:"))
+				lines = append(lines, excerpt...)
+			}
 		} else {
 			lines = append(lines, MutedStyle.Render("No file changes."))
 		}
@@ -344,8 +598,42 @@ func (m RestoreModel) renderPreviewPanel() string {
 	return panelStyle.Render(strings.Join(lines, "\n"))
 }
 
+// renderDiffExcerpt renders the first changed lines of the diff being
+// undone, under the name of the file they're in
+func (m RestoreModel) renderDiffExcerpt(maxLines int) []string {
+	var lines []string
+	var lang chroma.Lexer
+	highlight := syntaxHighlightEnabled()
+	file := ""
+	shown := 0
+
+	for _, line := range strings.Split(m.diffText, "\n") {
+		if shown >= maxLines {
+			break
+		}
+		if strings.HasPrefix(line, "+++ ") {
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			lang = nil
+			if highlight {
+				lang = syntaxFor(file)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "---") || !(strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) {
+			continue
+		}
+		if file != "" {
+			lines = append(lines, HighlightStyle.Render(truncateLine(file, 34)))
+			file = ""
+		}
+		lines = append(lines, renderDiffLine(line, lang, 34))
+		shown++
+	}
+	return lines
+}
+
 // renderFileStats renders file statistics with +/- numbers
-func (m RestoreModel) renderFileStats(summary git.CommitDiffSummary, maxFiles int) []string {
+func renderFileStats(summary git.CommitDiffSummary, maxFiles int) []string {
 	var lines []string
 
 	addStyle := lipgloss.NewStyle().Foreground(ColorSuccess)
@@ -357,9 +645,11 @@ func (m RestoreModel) renderFileStats(summary git.CommitDiffSummary, maxFiles in
 			break
 		}
 
-		path := f.Path
-		if len(path) > 25 {
-			path = "..." + path[len(path)-22:]
+		path := truncateLeft(f.Path, 25)
+		if f.OldPath != "" {
+			// Renames say where the file came from, on a line of their own
+			lines = append(lines, "  "+truncateLeft(f.DisplayPath(), 36))
+			path = ""
 		}
 
 		var stat string
@@ -371,12 +661,45 @@ func (m RestoreModel) renderFileStats(summary git.CommitDiffSummary, maxFiles in
 				delStyle.Render(fmt.Sprintf("-%d", f.Deletions)))
 		}
 
-		lines = append(lines, fmt.Sprintf("  %-25s %s", path, stat))
+		lines = append(lines, "  "+padRight(path, 25)+" "+stat)
 	}
 
 	return lines
 }
 
+// WantsDetail returns true if the user asked for the selected save's
+// details
+func (m RestoreModel) WantsDetail() bool {
+	return m.wantDetail
+}
+
+// SelectedCommit returns the save under the cursor
+func (m RestoreModel) SelectedCommit() git.CommitInfo {
+	if m.cursor < len(m.commits) {
+		return m.commits[m.cursor]
+	}
+	return git.CommitInfo{}
+}
+
+// Keys returns the keys the restore flow takes in its current state
+func (m RestoreModel) Keys() Keys {
+	switch m.state {
+	case RestoreStateList:
+		return Keys{
+			Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("m", "keep my files"), hint("e", "experiment"),
+				hint("i", "details"), hint("c", "copy hash"), hint("esc", "cancel")},
+			More: []key.Binding{vimKeys, hint("wheel", "move through the saves"), hint("click", "pick a save, again to revert")},
+		}
+	case RestoreStateConfirm:
+		if m.keepFiles {
+			return Keys{More: []key.Binding{hint("y", "move the save point"), hint("n", "no, back to the list")}}
+		}
+		return Keys{More: []key.Binding{hint("y", "revert"), hint("m", "keep my files, move the save point"),
+			hint("e", "open it as an experiment"), hint("n", "no, back to the list")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the restore flow is complete
 func (m RestoreModel) IsDone() bool {
 	return m.state == RestoreStateSuccess || m.state == RestoreStateError || m.state == RestoreStateEmpty
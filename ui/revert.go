@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -25,6 +26,7 @@ const (
 // RevertModel is the model for the revert flow
 type RevertModel struct {
 	commits    []git.CommitInfo
+	more       bool // whether older saves load on scrolling down
 	cursor     int
 	state      RevertState
 	err        error
@@ -37,7 +39,7 @@ type RevertModel struct {
 
 // NewRevertModel creates a new revert model
 func NewRevertModel() RevertModel {
-	commits, err := git.Log(20)
+	commits, more, err := firstCommitPage()
 	branch, _ := git.CurrentBranch()
 
 	state := RevertStateList
@@ -47,6 +49,7 @@ func NewRevertModel() RevertModel {
 
 	return RevertModel{
 		commits: commits,
+		more:    more,
 		cursor:  0,
 		state:   state,
 		branch:  branch,
@@ -68,7 +71,7 @@ type RevertMsg struct {
 func doRevert(commitHash string, branch string) tea.Cmd {
 	return func() tea.Msg {
 		// Create a backup first
-		backupName, err := git.CreateBackup(branch)
+		backupName, err := git.CreateBackup(branch, git.BackupBeforeRestore)
 		if err != nil {
 			return RevertMsg{Err: fmt.Errorf("failed to create backup: %w", err)}
 		}
@@ -117,6 +120,7 @@ func (m RevertModel) Update(msg tea.Msg) (RevertModel, tea.Cmd) {
 				if m.cursor < len(m.commits)-1 {
 					m.cursor++
 				}
+				m.commits, m.more = loadMoreCommits(m.commits, m.cursor, m.more)
 			case key.Matches(msg, keys.Enter):
 				m.selected = m.commits[m.cursor]
 				m.state = RevertStateConfirm
@@ -181,25 +185,22 @@ func (m RevertModel) View() string {
 			}
 
 			// Format: hash - message (time ago)
-			line := fmt.Sprintf("%s %s", commit.Hash, commit.Message)
-			if len(line) > 60 {
-				line = line[:57] + "..."
-			}
+			line := truncateLine(fmt.Sprintf("%s %s", commit.Hash, commit.Message), 60)
 
 			s += cursor + style.Render(line) + "\n"
 			s += "    " + MutedStyle.Render(commit.Timestamp) + "\n\n"
 		}
 
 		if len(m.commits) > maxVisible {
-			s += MutedStyle.Render(fmt.Sprintf("  ... %d total saves\n", len(m.commits)))
+			s += MutedStyle.Render("  ... "+savesLoadedNote(len(m.commits), m.more)) + "\n"
 		}
 
-		s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "select"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
 
 	case RevertStateConfirm:
 		s += RenderError("⚠ Warning: This will discard current changes!") + "\n\n"
 		s += "Revert to: " + HighlightStyle.Render(m.selected.Hash) + "\n"
-		s += RenderMuted(m.selected.Message) + "\n\n"
+		s += RenderMuted(strings.Join(wrapText(m.selected.Message, 60), "\n")) + "\n\n"
 		s += RenderMuted("A backup will be created before reverting.") + "\n\n"
 		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
 
@@ -215,7 +216,7 @@ func (m RevertModel) View() string {
 	case RevertStateError:
 		s += RenderError("✗ Revert failed") + "\n\n"
 		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 	}
@@ -223,6 +224,17 @@ func (m RevertModel) View() string {
 	return BoxStyle.Render(s)
 }
 
+// Keys returns the keys the revert flow takes in its current state
+func (m RevertModel) Keys() Keys {
+	switch m.state {
+	case RevertStateList:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "cancel")}, More: []key.Binding{vimKeys}}
+	case RevertStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "revert"), hint("n", "no, back to the list")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the revert flow is complete
 func (m RevertModel) IsDone() bool {
 	return m.state == RevertStateSuccess || m.state == RevertStateError || m.state == RevertStateEmpty
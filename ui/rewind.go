@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// RewindState represents the state of the rewind flow
+type RewindState int
+
+const (
+	RewindStatePick RewindState = iota
+	RewindStatePickDate
+	RewindStateChosen
+	RewindStateEmpty
+)
+
+// rewindOption is a point in time to rewind to
+type rewindOption struct {
+	label string
+	when  func(now time.Time) time.Time
+}
+
+// rewindOptions are the quick choices; "Pick a date" follows them
+var rewindOptions = []rewindOption{
+	{"30 minutes ago", func(now time.Time) time.Time { return now.Add(-30 * time.Minute) }},
+	{"2 hours ago", func(now time.Time) time.Time { return now.Add(-2 * time.Hour) }},
+	{"This morning", func(now time.Time) time.Time { return startOfDay(now) }},
+	{"Yesterday", func(now time.Time) time.Time { return startOfDay(now).Add(-time.Second) }},
+	{"A week ago", func(now time.Time) time.Time { return now.AddDate(0, 0, -7) }},
+}
+
+// startOfDay returns midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+// rewindTarget is the save a point in time resolves to
+type rewindTarget struct {
+	commit git.CommitInfo
+	saved  time.Time
+	err    error
+}
+
+// RewindModel is the model for the "Rewind to a time" flow
+type RewindModel struct {
+	cursor    int // rewindOptions, then "Pick a date"
+	state     RewindState
+	target    rewindTarget
+	dateInput textinput.Model
+	dateErr   error
+	width     int
+	height    int
+}
+
+// NewRewindModel creates a new rewind model
+func NewRewindModel() RewindModel {
+	ti := textinput.New()
+	ti.Placeholder = "2006-01-02 15:04"
+	ti.CharLimit = 16
+	ti.Width = 20
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m := RewindModel{state: RewindStatePick, dateInput: ti}
+	if commits, err := git.Log(0, 1); err != nil || len(commits) == 0 {
+		m.state = RewindStateEmpty
+		return m
+	}
+	m.resolve()
+	return m
+}
+
+// Init initializes the model
+func (m RewindModel) Init() tea.Cmd {
+	return nil
+}
+
+// resolve finds the save for the highlighted option
+func (m *RewindModel) resolve() {
+	if m.cursor >= len(rewindOptions) {
+		m.target = rewindTarget{}
+		return
+	}
+	m.target = resolveRewind(rewindOptions[m.cursor].when(time.Now()))
+}
+
+// resolveRewind finds the save that was current at t
+func resolveRewind(t time.Time) rewindTarget {
+	commit, saved, err := git.CommitBefore(t)
+	return rewindTarget{commit: commit, saved: saved, err: err}
+}
+
+// parseRewindDate reads a date, with an optional time. A date alone means
+// the end of that day.
+func parseRewindDate(input string) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if t, err := time.ParseInLocation("2006-01-02 15:04", input, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", input, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("use a date like 2024-03-15, optionally with a time like 2024-03-15 14:30")
+}
+
+// Update handles messages
+func (m RewindModel) Update(msg tea.Msg) (RewindModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch m.state {
+		case RewindStatePick:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+					m.resolve()
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(rewindOptions) {
+					m.cursor++
+					m.resolve()
+				}
+			case key.Matches(msg, keys.Enter):
+				if m.cursor == len(rewindOptions) {
+					m.state = RewindStatePickDate
+					m.dateErr = nil
+					m.dateInput.SetValue(time.Now().AddDate(0, 0, -1).Format("2006-01-02"))
+					m.dateInput.Focus()
+					return m, textinput.Blink
+				}
+				if m.target.err == nil {
+					m.state = RewindStateChosen
+				}
+			}
+
+		case RewindStatePickDate:
+			switch msg.String() {
+			case "enter":
+				t, err := parseRewindDate(m.dateInput.Value())
+				if err != nil {
+					m.dateErr = err
+					return m, nil
+				}
+				m.target = resolveRewind(t)
+				if m.target.err != nil {
+					m.dateErr = m.target.err
+					return m, nil
+				}
+				m.state = RewindStateChosen
+			case "esc":
+				m.state = RewindStatePick
+				m.resolve()
+			default:
+				var cmd tea.Cmd
+				m.dateInput, cmd = m.dateInput.Update(msg)
+				return m, cmd
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the rewind flow
+func (m RewindModel) View() string {
+	var s string
+
+	s += RenderTitle("Rewind to a Time") + "\n\n"
+
+	switch m.state {
+	case RewindStateEmpty:
+		s += RenderMuted("No save points found!") + "\n\n"
+		s += RenderMuted("Save your progress first before you can rewind.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case RewindStatePick:
+		s += RenderSubtitle("How far back do you want to go?") + "\n\n"
+
+		for i := 0; i <= len(rewindOptions); i++ {
+			label := "Pick a date..."
+			if i < len(rewindOptions) {
+				label = rewindOptions[i].label
+			}
+			cursor := "  "
+			style := MenuItemStyle
+			if i == m.cursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = MenuItemSelectedStyle
+			}
+			s += cursor + style.Render(label) + "\n"
+		}
+		s += "\n"
+
+		if m.cursor < len(rewindOptions) {
+			s += m.renderTarget() + "\n\n"
+		}
+
+		s += HelpBar(m.Keys())
+
+	case RewindStatePickDate:
+		s += RenderSubtitle("Rewind to how things were on:") + "\n\n"
+		s += m.dateInput.View() + "\n\n"
+		if m.dateErr != nil {
+			s += RenderError(m.dateErr.Error()) + "\n\n"
+		} else {
+			s += RenderMuted("A date on its own means the end of that day.") + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderTarget describes the save the highlighted time resolves to
+func (m RewindModel) renderTarget() string {
+	if m.target.err != nil {
+		return RenderMuted("No saves that old.")
+	}
+	return RenderMuted("Back to: ") + HighlightStyle.Render(m.target.commit.Hash) + " " +
+		m.target.commit.Message + "\n" +
+		RenderMuted(fmt.Sprintf("saved %s (%s)", m.target.saved.Format("Mon Jan 2 15:04"), m.target.commit.Timestamp))
+}
+
+// WantsRestore returns true once a save has been chosen, to hand over to
+// the revert flow
+func (m RewindModel) WantsRestore() bool {
+	return m.state == RewindStateChosen
+}
+
+// SelectedCommit returns the save the chosen time resolved to
+func (m RewindModel) SelectedCommit() git.CommitInfo {
+	return m.target.commit
+}
+
+// Keys returns the keys the rewind screen takes in its current state
+func (m RewindModel) Keys() Keys {
+	switch m.state {
+	case RewindStatePick:
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "select"), hint("esc", "cancel")}, More: []key.Binding{vimKeys}}
+	case RewindStatePickDate:
+		return Keys{Bar: []key.Binding{hint("enter", "find save"), hint("esc", "back")}, Typing: true}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true if esc should go back to the list of times
+// rather than leave the flow
+func (m RewindModel) HandlesEsc() bool {
+	return m.state == RewindStatePickDate
+}
+
+// IsDone returns true if there is nothing to rewind to
+func (m RewindModel) IsDone() bool {
+	return m.state == RewindStateEmpty
+}
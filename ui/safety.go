@@ -0,0 +1,42 @@
+package ui
+
+import "smooth/config"
+
+// confirmation tracks how many times a destructive operation still has to
+// ask before going ahead, which depends on the safety level in settings
+type confirmation struct {
+	needed int
+	given  int
+	warn   bool // whether to explain what could be lost
+}
+
+// newConfirmation starts counting confirmations at the configured level
+func newConfirmation() confirmation {
+	cfg, _ := config.Load()
+	return confirmation{needed: cfg.Confirmations(), warn: cfg.ShowWarnings()}
+}
+
+// done checks if the operation has been confirmed enough times
+func (c confirmation) done() bool {
+	return c.given >= c.needed
+}
+
+// confirm records a yes and reports whether the operation can go ahead
+func (c *confirmation) confirm() bool {
+	c.given++
+	return c.done()
+}
+
+// reset starts over, for when the user backs out
+func (c *confirmation) reset() {
+	c.given = 0
+}
+
+// prompt renders the question for the next confirmation. After the first
+// yes, beginners are asked once more.
+func (c confirmation) prompt(question string) string {
+	if c.given > 0 {
+		return RenderError("Just to be sure: really go ahead? (y/n)")
+	}
+	return RenderSubtitle(question + " (y/n)")
+}
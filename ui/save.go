@@ -1,15 +1,23 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"smooth/achievements"
 	"smooth/config"
 	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+	"smooth/scan"
 )
 
 // SaveState represents the state of the save flow
@@ -22,13 +30,16 @@ const (
 	SaveStateSuccess
 	SaveStateError
 	SaveStateNoChanges
+	SaveStatePreview
+	SaveStateLargeFiles
+	SaveStateTrackingLFS
+	SaveStateSecrets
+	SaveStateChecks
+	SaveStateConfirmRevert
 )
 
 // SaveFileItem represents a file with its action
-type SaveFileItem struct {
-	Change git.FileChange
-	Action FileAction
-}
+type SaveFileItem = ops.File
 
 // SaveModel is the model for the save flow
 type SaveModel struct {
@@ -40,11 +51,36 @@ type SaveModel struct {
 	focusOnFiles  bool // true = file list focused, false = text input focused
 	synced        bool
 	syncErr       error
+	cancelSync    context.CancelFunc // stops the auto-sync; nil when it isn't running
+	stoppingSync  bool               // esc was pressed and the auto-sync is being stopped
 	commitHash    string
 	savedCount    int
 	revertedCount int
 	ignoredCount  int
 	skippedCount  int
+	celebration   celebration // achievements unlocked by this save
+	cheer         cheer       // the mascot's cheer for this save
+	spinner       spinner.Model
+	frame         int // advances with the spinner to animate loading placeholders
+	wantPreview   bool
+	largeFiles    []git.LargeFile // files over the size limit, awaiting a choice
+	largeChoice   int
+	lfsInstalled  bool
+	lfsErr        error
+	secrets       []scan.Finding
+	secretCursor  int
+	secretChoices map[string]secretChoice // by file, since the choice applies to the whole file
+	allowSecrets  map[string]bool
+	checks        *ops.ChecksFailedError // the failed checks that stopped the save
+	checksOpen    bool                   // whether the checks' full output is shown
+	skipChecks    bool
+	revertConfirm confirmation // marking a file to revert counts as the first
+	msgTemplate   string       // save message template from config
+	branch        string       // for the template's {branch} and {ticket}
+	openTasks     []git.Task   // tasks a save can be linked to
+	taskIndex     int          // the linked task in openTasks, or -1
+	step          ops.Step     // the step the save is on
+	updates       chan tea.Msg // steps and the result from the running save
 	width         int
 	height        int
 }
@@ -66,21 +102,26 @@ func NewSaveModel() SaveModel {
 		state = SaveStateNoChanges
 	}
 
-	// Convert to SaveFileItem with Save as default
-	files := make([]SaveFileItem, len(changes))
-	for i, c := range changes {
-		files[i] = SaveFileItem{
-			Change: c,
-			Action: FileActionSave,
-		}
-	}
+	files := ops.NewFiles(changes)
+
+	cfg, _ := config.Load()
+	branch, _ := git.CurrentBranch()
+
+	revertConfirm := newConfirmation()
+	revertConfirm.needed--
 
 	return SaveModel{
-		textInput:    ti,
-		state:        state,
-		files:        files,
-		cursor:       0,
-		focusOnFiles: false, // Start with text input focused
+		textInput:     ti,
+		state:         state,
+		files:         files,
+		cursor:        0,
+		focusOnFiles:  false, // Start with text input focused
+		spinner:       newSpinner(SpinnerLine),
+		msgTemplate:   cfg.MessageTemplate,
+		branch:        branch,
+		revertConfirm: revertConfirm,
+		openTasks:     git.OpenTasks(),
+		taskIndex:     -1,
 	}
 }
 
@@ -91,92 +132,61 @@ func (m SaveModel) Init() tea.Cmd {
 
 // SaveMsg is sent when save completes
 type SaveMsg struct {
-	Err           error
-	Hash          string
-	SavedCount    int
-	RevertedCount int
-	IgnoredCount  int
-	SkippedCount  int
+	ops.SaveResult
+	Err error
 }
 
+// saveProgressMsg is sent as each step of a save starts
+type saveProgressMsg ops.Step
+
 // SaveSyncMsg is sent when sync completes
 type SaveSyncMsg struct {
 	Err error
 }
 
-// doSave performs the save operation
-func doSave(message string, files []SaveFileItem) tea.Cmd {
+// doSave performs the save operation in the background, sending each step
+// and then the result to updates, and links the save to task if there is
+// one
+func doSave(message string, files []SaveFileItem, allowSecrets map[string]bool, skipChecks bool, task *git.Task, updates chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
-		var toSave []string
-		var toRevert []string
-		var toIgnore []string
-		skipped := 0
-
-		for _, f := range files {
-			switch f.Action {
-			case FileActionSave:
-				toSave = append(toSave, f.Change.Path)
-			case FileActionRevert:
-				toRevert = append(toRevert, f.Change.Path)
-			case FileActionIgnore:
-				toIgnore = append(toIgnore, f.Change.Path)
-			case FileActionIgnoreOnce:
-				skipped++
-			}
-		}
-
-		result := SaveMsg{
-			SavedCount:    len(toSave),
-			RevertedCount: len(toRevert),
-			IgnoredCount:  len(toIgnore),
-			SkippedCount:  skipped,
-		}
-
-		// 1. Revert files first
-		if len(toRevert) > 0 {
-			if err := git.RevertFiles(toRevert); err != nil {
-				result.Err = fmt.Errorf("failed to revert files: %w", err)
-				return result
-			}
-		}
-
-		// 2. Add files to gitignore
-		for _, path := range toIgnore {
-			if err := git.AddToGitignore(path); err != nil {
-				result.Err = fmt.Errorf("failed to add %s to .gitignore: %w", path, err)
-				return result
-			}
-		}
-
-		// 3. Stage and commit if there are files to save
-		if len(toSave) > 0 {
-			// Include .gitignore if we modified it
-			if len(toIgnore) > 0 {
-				toSave = append(toSave, ".gitignore")
-			}
-
-			if err := git.AddFiles(toSave); err != nil {
-				result.Err = fmt.Errorf("failed to stage files: %w", err)
-				return result
-			}
-
-			if err := git.Commit(message); err != nil {
-				result.Err = fmt.Errorf("failed to commit: %w", err)
-				return result
+		go func() {
+			result := Save(message, files, allowSecrets, skipChecks, func(step ops.Step) {
+				// Drop steps the screen hasn't caught up with
+				select {
+				case updates <- saveProgressMsg(step):
+				default:
+				}
+			})
+			if task != nil && result.Err == nil && result.Hash != "" {
+				git.LinkTaskSave(task.ID, result.Hash)
 			}
+			updates <- result
+		}()
+		return <-updates
+	}
+}
 
-			// Get the commit hash for display
-			result.Hash, _ = git.Run("rev-parse", "--short", "HEAD")
+// Save carries out each file's action: reverting, ignoring, skipping or
+// saving it. Files in allowSecrets are saved even if they look like they
+// contain secrets, and skipChecks saves even if the project's pre-commit
+// hook or check command fails. progress, if it isn't nil, hears about each
+// step as it starts.
+func Save(message string, files []SaveFileItem, allowSecrets map[string]bool, skipChecks bool, progress func(ops.Step)) SaveMsg {
+	plan := ops.NewPlan(message, files)
+	plan.SkipChecks = skipChecks
+	for path, allowed := range allowSecrets {
+		if allowed {
+			plan.AllowSecrets = append(plan.AllowSecrets, path)
 		}
-
-		return result
 	}
+	result, err := plan.Execute(progress)
+	return SaveMsg{SaveResult: result, Err: err}
 }
 
-// doSaveSync performs the sync operation
-func doSaveSync() tea.Cmd {
+// doSaveSync performs the sync operation, which cancelling ctx stops
+func doSaveSync(ctx context.Context) tea.Cmd {
 	return func() tea.Msg {
-		err := git.Push()
+		err := git.Push(ctx)
 		return SaveSyncMsg{Err: err}
 	}
 }
@@ -232,7 +242,22 @@ func (m SaveModel) Update(msg tea.Msg) (SaveModel, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case saveProgressMsg:
+		m.step = ops.Step(msg)
+		return m, func() tea.Msg { return <-m.updates }
+
 	case SaveMsg:
+		var secrets *ops.SecretsFoundError
+		if errors.As(msg.Err, &secrets) {
+			return m.openSecrets(secrets.Findings), nil
+		}
+		var checks *ops.ChecksFailedError
+		if errors.As(msg.Err, &checks) {
+			m.state = SaveStateChecks
+			m.checks = checks
+			m.checksOpen = false
+			return m, nil
+		}
 		if msg.Err != nil {
 			m.state = SaveStateError
 			m.err = msg.Err
@@ -245,24 +270,111 @@ func (m SaveModel) Update(msg tea.Msg) (SaveModel, tea.Cmd) {
 		m.skippedCount = msg.SkippedCount
 		m.commitHash = msg.Hash
 
+		var unlocked []achievements.Achievement
+		if m.savedCount > 0 {
+			unlocked = achievements.RecordSave(msg.LinesAdded, msg.LinesDeleted)
+		}
+		var celebrate tea.Cmd
+		m.celebration, celebrate = newCelebration(unlocked)
+		m.cheer = newCheer(unlocked, true)
+
 		// Check if auto-sync is enabled and we saved files
 		cfg, _ := config.Load()
 		if cfg.AutoSyncEnabled && git.HasRemote() && m.savedCount > 0 {
 			m.state = SaveStateAutoSyncing
 			m.synced = true
-			return m, doSaveSync()
+			var ctx context.Context
+			ctx, m.cancelSync = networkContext()
+			return m, tea.Batch(celebrate, doSaveSync(ctx))
 		}
 
 		m.state = SaveStateSuccess
-		return m, nil
+		return m, celebrate
 
 	case SaveSyncMsg:
+		if m.cancelSync != nil {
+			m.cancelSync()
+			m.cancelSync = nil
+		}
 		m.syncErr = msg.Err
 		m.state = SaveStateSuccess
+		if msg.Err != nil {
+			// Keep the save queued so it's uploaded once we're back online,
+			// or later on if the sync was stopped
+			git.QueueSync(msg.Err)
+			if !errors.Is(msg.Err, context.Canceled) {
+				notify.SyncFailed(msg.Err)
+			}
+		} else {
+			if unlocked := achievements.RecordSync(); len(unlocked) > 0 {
+				unlocked = append(m.celebration.unlocked, unlocked...)
+				var celebrate tea.Cmd
+				m.celebration, celebrate = newCelebration(unlocked)
+				m.cheer = newCheer(unlocked, true)
+				return m, celebrate
+			}
+		}
+		return m, nil
+
+	case LFSTrackMsg:
+		if msg.Err != nil {
+			m.state = SaveStateLargeFiles
+			m.lfsErr = msg.Err
+			return m, nil
+		}
+		return m.proceed()
+
+	case celebrationTickMsg:
+		var cmd tea.Cmd
+		m.celebration, cmd = m.celebration.Update(msg)
+		return m, cmd
+
+	case spinner.TickMsg:
+		if m.state == SaveStateExecuting || m.state == SaveStateAutoSyncing || m.state == SaveStateTrackingLFS {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			m.frame++
+			return m, cmd
+		}
 		return m, nil
 
 	case tea.KeyMsg:
 		switch m.state {
+		case SaveStateAutoSyncing:
+			if msg.String() == "esc" && m.cancelSync != nil {
+				m.cancelSync()
+				m.stoppingSync = true
+			}
+			return m, nil
+
+		case SaveStateLargeFiles:
+			return m.updateLargeFiles(msg)
+
+		case SaveStateSecrets:
+			return m.updateSecrets(msg)
+
+		case SaveStateChecks:
+			return m.updateChecks(msg)
+
+		case SaveStateConfirmRevert:
+			switch msg.String() {
+			case "y", "Y":
+				if m.revertConfirm.confirm() {
+					return m.executeSave()
+				}
+			case "n", "N", "esc":
+				m.revertConfirm.reset()
+				m.state = SaveStateReview
+			}
+
+		case SaveStatePreview:
+			switch msg.String() {
+			case "y", "Y", "enter":
+				return m.executeSave()
+			case "n", "N", "esc":
+				m.state = SaveStateReview
+			}
+
 		case SaveStateReview:
 			// Only arrow keys switch focus (not h/l which conflict with typing)
 			if msg.String() == "right" && !m.focusOnFiles {
@@ -276,15 +388,23 @@ func (m SaveModel) Update(msg tea.Msg) (SaveModel, tea.Cmd) {
 				return m, textinput.Blink
 			}
 
-			// Enter executes save from either focus
-			if key.Matches(msg, keys.Enter) {
-				if m.textInput.Value() != "" {
-					m.state = SaveStateExecuting
-					return m, doSave(m.textInput.Value(), m.files)
-				}
+			if msg.String() == "ctrl+t" {
+				m.taskIndex = m.nextTaskIndex()
 				return m, nil
 			}
 
+			// Enter executes save from either focus, after a preview if the
+			// user always wants one; ctrl+p (or p in the file list) previews
+			previewKey := msg.String() == "ctrl+p" || (m.focusOnFiles && msg.String() == "p")
+			if key.Matches(msg, keys.Enter) || previewKey {
+				if m.textInput.Value() == "" {
+					return m, nil
+				}
+				cfg, _ := config.Load()
+				m.revertConfirm.reset()
+				return m.checkLargeFiles(previewKey || cfg.AlwaysPreviewSave)
+			}
+
 			if m.focusOnFiles {
 				// File list is focused - handle file navigation and actions
 				switch {
@@ -315,11 +435,207 @@ func (m SaveModel) Update(msg tea.Msg) (SaveModel, tea.Cmd) {
 				return m, cmd
 			}
 		}
+
+	case tea.MouseMsg:
+		if m.state == SaveStateReview {
+			return m.updateMouse(msg)
+		}
 	}
 
 	return m, nil
 }
 
+// updateMouse lets the message box and files be clicked, the file list be
+// scrolled, and a file's badge be clicked to cycle its action
+func (m SaveModel) updateMouse(msg tea.MouseMsg) (SaveModel, tea.Cmd) {
+	leftWidth, _ := m.panelWidths()
+	// The right panel starts after the left one and its two border columns
+	rightX := leftWidth + 2
+	if msg.X < rightX {
+		if isLeftClick(msg) && m.focusOnFiles {
+			m.focusOnFiles = false
+			m.textInput.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	}
+
+	if delta := wheelDelta(msg); delta != 0 {
+		if cursor := m.cursor + delta; cursor >= 0 && cursor < len(m.files) {
+			m.cursor = cursor
+		}
+		return m, nil
+	}
+	if !isLeftClick(msg) {
+		return m, nil
+	}
+
+	// Below the title, the panel's border and padding, and its heading
+	top := linesAbove(RenderTitle("Save")+"\n\n") + 2 + linesAbove(HighlightStyle.Render("Files")+"\n\n")
+	row := msg.Y - top
+	start, maxVisible := m.fileListWindow()
+	i := start + row
+	if row < 0 || row >= maxVisible || i >= len(m.files) {
+		return m, nil
+	}
+
+	m.focusOnFiles = true
+	m.textInput.Blur()
+	m.cursor = i
+
+	// The badge comes after the border, padding and cursor
+	badgeX := rightX + 1 + 2 + 2
+	if msg.X >= badgeX && msg.X < badgeX+lipgloss.Width(m.renderActionBadge(m.files[i].Action)) {
+		m.files[i].Action = m.cycleAction(m.files[i].Action)
+	}
+	return m, nil
+}
+
+// withFileAction sets the action for path. Files inside a new folder get
+// their own entry so the rest of the folder keeps its action.
+func (m SaveModel) withFileAction(path string, action FileAction) SaveModel {
+	for i, f := range m.files {
+		if f.Change.Path == path {
+			m.files[i].Action = action
+			return m
+		}
+	}
+	m.files = append(m.files, SaveFileItem{
+		Change: git.FileChange{Status: "added", Path: path},
+		Action: action,
+	})
+	return m
+}
+
+// executeSave starts running the save, asking first about reverted files
+// once reverting has been confirmed enough times
+func (m SaveModel) executeSave() (SaveModel, tea.Cmd) {
+	if _, revert, _, _ := m.countByAction(); revert > 0 && !m.revertConfirm.done() {
+		m.state = SaveStateConfirmRevert
+		return m, nil
+	}
+	m.state = SaveStateExecuting
+	m.step = ""
+	m.updates = make(chan tea.Msg, 1)
+	return m, tea.Batch(spinnerTick(m.spinner), doSave(m.message(), m.files, m.allowSecrets, m.skipChecks, m.linkedTask(), m.updates))
+}
+
+// linkedTask returns the task this save will be linked to, if any
+func (m SaveModel) linkedTask() *git.Task {
+	if m.taskIndex < 0 || m.taskIndex >= len(m.openTasks) {
+		return nil
+	}
+	return &m.openTasks[m.taskIndex]
+}
+
+// nextTaskIndex cycles the linked task through the open ones and back to
+// none
+func (m SaveModel) nextTaskIndex() int {
+	if m.taskIndex+1 >= len(m.openTasks) {
+		return -1
+	}
+	return m.taskIndex + 1
+}
+
+// message returns the save message as typed, mentioning the linked task
+func (m SaveModel) message() string {
+	msg := m.textInput.Value()
+	if task := m.linkedTask(); task != nil && msg != "" {
+		msg += " " + task.Reference()
+	}
+	return msg
+}
+
+// renderPreview renders the dry run of what the save will do
+func (m SaveModel) renderPreview() string {
+	var s string
+	s += RenderTitle("Save Preview") + "\n\n"
+	s += RenderMuted("Nothing has changed yet. Saving will:") + "\n\n"
+
+	plan := ops.NewPlan(m.message(), m.files)
+	plan.SkipChecks = m.skipChecks
+	steps := plan.Preview()
+	if len(steps) == 0 {
+		s += "  " + MutedStyle.Render("Do nothing (every file is skipped)") + "\n\n"
+	}
+	for i, step := range steps {
+		s += fmt.Sprintf("  %s %s\n", HighlightStyle.Render(fmt.Sprintf("%d.", i+1)), step.Summary)
+		for _, cmd := range step.Commands {
+			s += "     " + MutedStyle.Render("$ "+truncateLine(cmd, 90)) + "\n"
+		}
+		for _, line := range step.Lines {
+			s += "     " + SuccessStyle.Render("+ "+line) + "\n"
+		}
+		s += "\n"
+	}
+
+	s += RenderSubtitle("Go ahead? (y/n)") + "\n"
+	return s
+}
+
+// renderConfirmRevert asks before throwing away changes to reverted files
+func (m SaveModel) renderConfirmRevert() string {
+	var s string
+	s += RenderTitle("Revert Files") + "\n\n"
+	if m.revertConfirm.warn {
+		s += RenderError("⚠ Warning: Changes to these files will be lost!") + "\n\n"
+	}
+	for _, f := range m.files {
+		if f.Action == FileActionRevert {
+			s += "  " + ErrorStyle.Render("↺") + " " + truncateLine(f.Change.DisplayPath(), 60) + "\n"
+		}
+	}
+	s += "\n"
+	s += m.revertConfirm.prompt("Revert these files?") + "\n"
+	return s
+}
+
+// Keys returns the keys the save flow takes in its current state
+func (m SaveModel) Keys() Keys {
+	switch m.state {
+	case SaveStateReview:
+		if m.focusOnFiles {
+			return Keys{
+				Bar: []key.Binding{hint("←", "message"), hint("↑↓", "navigate"), hint("space", "cycle"),
+					hint("1-4", "set action"), hint("p", "preview"), hint("enter", "save"), hint("esc", "cancel")},
+				More: []key.Binding{vimKeys, hint("1", "save the file"), hint("2", "revert it"),
+					hint("3", "leave it out this time"), hint("4", "add it to .gitignore"), hint("click", "cycle a file's action")},
+			}
+		}
+		bar := []key.Binding{hint("→", "files"), hint("ctrl+p", "preview")}
+		if len(m.openTasks) > 0 {
+			bar = append(bar, hint("ctrl+t", "task"))
+		}
+		return Keys{
+			Bar:    append(bar, hint("enter", "save"), hint("esc", "cancel")),
+			More:   []key.Binding{hint("click", "focus the message or a file")},
+			Typing: true,
+		}
+	case SaveStatePreview:
+		return Keys{More: []key.Binding{hint("y", "save"), hint("n", "back to the files")}}
+	case SaveStateLargeFiles:
+		return largeFilesKeys
+	case SaveStateSecrets:
+		return m.secretsKeys()
+	case SaveStateChecks:
+		return m.checksKeys()
+	case SaveStateConfirmRevert:
+		return Keys{More: []key.Binding{hint("y", "revert the files"), hint("n", "back to the files")}}
+	case SaveStateAutoSyncing:
+		if !m.stoppingSync {
+			return Keys{Bar: []key.Binding{hint("esc", "stop syncing")}}
+		}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true if esc should go back within the save flow
+// rather than leave it
+func (m SaveModel) HandlesEsc() bool {
+	return m.state == SaveStatePreview || m.state == SaveStateLargeFiles || m.state == SaveStateSecrets ||
+		m.state == SaveStateChecks || m.state == SaveStateConfirmRevert || m.state == SaveStateAutoSyncing
+}
+
 // View renders the save flow
 func (m SaveModel) View() string {
 	switch m.state {
@@ -333,25 +649,62 @@ func (m SaveModel) View() string {
 	case SaveStateReview:
 		return m.renderTwoPanelView()
 
+	case SaveStatePreview:
+		return BoxStyle.Render(m.renderPreview())
+
+	case SaveStateLargeFiles:
+		return BoxStyle.Render(m.renderLargeFiles())
+
+	case SaveStateSecrets:
+		return BoxStyle.Render(m.renderSecrets())
+
+	case SaveStateChecks:
+		return BoxStyle.Render(m.renderChecks())
+
+	case SaveStateConfirmRevert:
+		return BoxStyle.Render(m.renderConfirmRevert())
+
+	case SaveStateTrackingLFS:
+		s := RenderTitle("Large Files") + "\n\n"
+		s += m.spinner.View() + " " + RenderHighlight("Setting up Git LFS...") + "\n"
+		return BoxStyle.Render(s)
+
 	case SaveStateExecuting:
 		s := RenderTitle("Save") + "\n\n"
-		s += RenderHighlight("⟳ Processing changes...") + "\n"
+		step := "Processing changes"
+		if m.step != "" {
+			step = string(m.step)
+		}
+		s += m.spinner.View() + " " + RenderHighlight(step+"...") + "\n\n"
+		s += RenderSkeleton(m.frame, min(len(m.files), 5), 30) + "\n"
 		return BoxStyle.Render(s)
 
 	case SaveStateAutoSyncing:
 		s := RenderTitle("Save") + "\n\n"
 		s += RenderSuccess("✓ Done!") + "\n\n"
-		s += RenderHighlight("⟳ Syncing to GitHub...") + "\n"
+		s += m.spinner.View() + " " + RenderHighlight("Syncing to GitHub...") + "\n\n"
+		s += RenderShimmerBar(m.frame, 40) + "\n"
+		if m.stoppingSync {
+			s += "\n" + RenderMuted("Stopping...") + "\n"
+		} else {
+			s += "\n" + HelpBar(m.Keys())
+		}
 		return BoxStyle.Render(s)
 
 	case SaveStateSuccess:
 		s := RenderTitle("Save") + "\n\n"
 
-		// Show the cute celebration cat!
-		s += RenderCelebrationCat() + "\n\n"
+		// Show the mascot cheering!
+		if cheer := m.cheer.View(); cheer != "" {
+			s += cheer + "\n\n"
+		}
 
 		s += RenderSuccess("✓ Complete!") + "\n\n"
 
+		if celebration := m.celebration.View(); celebration != "" {
+			s += celebration + "\n\n"
+		}
+
 		if m.savedCount > 0 {
 			s += fmt.Sprintf("  %s Saved %d file(s)",
 				SuccessStyle.Render("✓"), m.savedCount)
@@ -372,8 +725,12 @@ func (m SaveModel) View() string {
 
 		if m.synced {
 			s += "\n"
-			if m.syncErr != nil {
+			if errors.Is(m.syncErr, context.Canceled) {
+				s += RenderMuted("○ Sync stopped") + "\n"
+				s += RenderMuted("Your save is safe here; smooth will upload it later.") + "\n"
+			} else if m.syncErr != nil {
 				s += RenderError("✗ Sync failed: ") + RenderMuted(m.syncErr.Error()) + "\n"
+				s += RenderMuted("Your save is safe here; smooth will keep trying to upload it.") + "\n"
 			} else {
 				s += RenderSuccess("✓ Synced to GitHub!") + "\n"
 			}
@@ -384,8 +741,15 @@ func (m SaveModel) View() string {
 	case SaveStateError:
 		s := RenderTitle("Save") + "\n\n"
 		s += RenderError("✗ Save failed") + "\n\n"
-		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+		var mismatch *ops.StagingMismatchError
+		var signing *git.SigningError
+		if errors.As(m.err, &mismatch) {
+			s += m.renderMismatch(mismatch) + "\n"
+		} else if errors.As(m.err, &signing) {
+			s += RenderMuted("Your changes weren't saved because git couldn't sign them.") + "\n"
+			s += RenderMuted(signing.Guidance()) + "\n\n"
+		} else if m.err != nil {
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 		return BoxStyle.Render(s)
@@ -394,16 +758,32 @@ func (m SaveModel) View() string {
 	return ""
 }
 
-// renderTwoPanelView renders the two-panel save review layout
-func (m SaveModel) renderTwoPanelView() string {
-	width := m.width
-	if width < 80 {
-		width = 100
+// renderMismatch explains why the staged files didn't match the review
+func (m SaveModel) renderMismatch(mismatch *ops.StagingMismatchError) string {
+	var s string
+	s += RenderMuted("Nothing was saved, because git was about to commit something") + "\n"
+	s += RenderMuted("different from what you reviewed. Your files are unchanged.") + "\n\n"
+
+	if len(mismatch.Unexpected) > 0 {
+		s += HighlightStyle.Render("Already staged outside of smooth:") + "\n"
+		for _, path := range mismatch.Unexpected {
+			s += "  " + MutedStyle.Render("• "+path) + "\n"
+		}
+		s += RenderMuted("Unstage them with: git restore --staged <file>") + "\n\n"
 	}
+	if len(mismatch.Missing) > 0 {
+		s += HighlightStyle.Render("Couldn't be staged:") + "\n"
+		for _, path := range mismatch.Missing {
+			s += "  " + MutedStyle.Render("• "+path) + "\n"
+		}
+		s += RenderMuted("These may be matched by a .gitignore rule.") + "\n\n"
+	}
+	return s
+}
 
-	// Calculate panel widths (35% left for message, 65% right for files)
-	leftWidth := width*35/100 - 2
-	rightWidth := width*65/100 - 2
+// renderTwoPanelView renders the two-panel save review layout
+func (m SaveModel) renderTwoPanelView() string {
+	leftWidth, rightWidth := m.panelWidths()
 
 	// Build panel contents
 	leftContent := m.renderLeftPanel(leftWidth)
@@ -418,22 +798,18 @@ func (m SaveModel) renderTwoPanelView() string {
 		rightBorderColor = ColorAccent
 	}
 
-	leftPanel := lipgloss.NewStyle().
+	leftPanel := PanelStyle(leftBorderColor).
 		Width(leftWidth).
 		Padding(1, 2).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(leftBorderColor).
 		Render(leftContent)
 
-	rightPanel := lipgloss.NewStyle().
+	rightPanel := PanelStyle(rightBorderColor).
 		Width(rightWidth).
 		Padding(1, 2).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(rightBorderColor).
 		Render(rightContent)
 
 	// Join panels horizontally
-	panels := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, rightPanel)
+	panels := SideBySide("", leftPanel, rightPanel)
 
 	// Build full view
 	var s string
@@ -441,26 +817,34 @@ func (m SaveModel) renderTwoPanelView() string {
 	s += panels + "\n\n"
 
 	// Help bar at bottom
-	if m.focusOnFiles {
-		s += HelpBar([][]string{
-			{"←", "message"},
-			{"↑↓", "navigate"},
-			{"space", "cycle"},
-			{"1-4", "set action"},
-			{"enter", "save"},
-			{"esc", "cancel"},
-		})
-	} else {
-		s += HelpBar([][]string{
-			{"→", "files"},
-			{"enter", "save"},
-			{"esc", "cancel"},
-		})
-	}
+	s += HelpBar(m.Keys())
 
 	return s
 }
 
+// panelWidths returns the widths of the message and file panels
+func (m SaveModel) panelWidths() (leftWidth, rightWidth int) {
+	width := m.width
+	if width < 80 {
+		width = 100
+	}
+
+	// Calculate panel widths (35% left for message, 65% right for files)
+	leftWidth = width*35/100 - 2
+	rightWidth = width*65/100 - 2
+	return leftWidth, rightWidth
+}
+
+// fileListWindow returns the first file shown and how many fit, scrolled
+// so the cursor stays visible
+func (m SaveModel) fileListWindow() (start, maxVisible int) {
+	maxVisible = 10
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+	return start, maxVisible
+}
+
 // renderLeftPanel renders the instructions and save message input
 func (m SaveModel) renderLeftPanel(width int) string {
 	var s string
@@ -475,6 +859,20 @@ func (m SaveModel) renderLeftPanel(width int) string {
 	// Text input
 	s += m.textInput.View() + "\n\n"
 
+	// What the message becomes once the template is applied
+	if msg := m.textInput.Value(); msg != "" {
+		if full := ops.ExpandMessageTemplate(m.msgTemplate, m.message(), m.branch, ops.SavedPaths(m.files), time.Now()); full != msg {
+			s += MutedStyle.Render("Saved as: "+full) + "\n\n"
+		}
+	}
+
+	// The task this save is linked to
+	if task := m.linkedTask(); task != nil {
+		s += "Task: " + HighlightStyle.Render(fmt.Sprintf("#%d %s", task.ID, truncateLine(task.Title, width-14))) + "\n\n"
+	} else if len(m.openTasks) > 0 {
+		s += MutedStyle.Render("ctrl+t links this save to a task") + "\n\n"
+	}
+
 	// Summary of actions
 	s += m.renderSummary()
 
@@ -493,11 +891,7 @@ func (m SaveModel) renderRightPanel(width int) string {
 	s += titleStyle.Render("Files") + "\n\n"
 
 	// File list
-	maxVisible := 10
-	start := 0
-	if m.cursor >= maxVisible {
-		start = m.cursor - maxVisible + 1
-	}
+	start, maxVisible := m.fileListWindow()
 
 	for i := start; i < len(m.files) && i < start+maxVisible; i++ {
 		f := m.files[i]
@@ -512,14 +906,12 @@ func (m SaveModel) renderRightPanel(width int) string {
 		badge := m.renderActionBadge(f.Action)
 
 		// Filename (truncate if needed)
-		name := f.Change.Path
+		name := f.Change.DisplayPath()
 		maxNameLen := width - 15
 		if maxNameLen < 10 {
 			maxNameLen = 10
 		}
-		if len(name) > maxNameLen {
-			name = "..." + name[len(name)-maxNameLen+3:]
-		}
+		name = truncateLeft(name, maxNameLen)
 
 		// Status indicator
 		status := ""
@@ -538,7 +930,12 @@ func (m SaveModel) renderRightPanel(width int) string {
 			nameStyle = MutedStyle
 		}
 
-		s += fmt.Sprintf("%s%s %s %s\n", cursor, badge, status, nameStyle.Render(name))
+		detail := ""
+		if f.Change.Detail != "" {
+			detail = " " + MutedStyle.Render(f.Change.Detail)
+		}
+
+		s += fmt.Sprintf("%s%s %s %s%s\n", cursor, badge, status, nameStyle.Render(name), detail)
 	}
 
 	if len(m.files) > maxVisible {
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/scan"
+)
+
+// secretChoice is what to do with a file that looks like it has a secret
+type secretChoice int
+
+const (
+	secretUndecided secretChoice = iota
+	secretCommitAnyway
+	secretRevert
+	secretIgnore
+)
+
+// openSecrets shows the suspected secrets that stopped the save
+func (m SaveModel) openSecrets(findings []scan.Finding) SaveModel {
+	m.state = SaveStateSecrets
+	m.secrets = findings
+	m.secretCursor = 0
+	m.secretChoices = make(map[string]secretChoice)
+	return m
+}
+
+// canRevert checks if path can be reverted to its last save. New files
+// (including those inside new folders) have nothing to go back to.
+func (m SaveModel) canRevert(path string) bool {
+	for _, f := range m.files {
+		if f.Change.Path == path {
+			return f.Change.Status != "added"
+		}
+	}
+	return false
+}
+
+// secretsDecided checks if every file with a finding has a choice
+func (m SaveModel) secretsDecided() bool {
+	for _, f := range m.secrets {
+		if m.secretChoices[f.Path] == secretUndecided {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveSecrets applies the choices to the files and saves again
+func (m SaveModel) resolveSecrets() (SaveModel, tea.Cmd) {
+	if m.allowSecrets == nil {
+		m.allowSecrets = make(map[string]bool)
+	}
+	for path, choice := range m.secretChoices {
+		switch choice {
+		case secretCommitAnyway:
+			m.allowSecrets[path] = true
+		case secretRevert:
+			m = m.withFileAction(path, FileActionRevert)
+		case secretIgnore:
+			m = m.withFileAction(path, FileActionIgnore)
+		}
+	}
+	m.secrets = nil
+	return m.executeSave()
+}
+
+// updateSecrets handles keys on the suspected secrets screen
+func (m SaveModel) updateSecrets(msg tea.KeyMsg) (SaveModel, tea.Cmd) {
+	path := m.secrets[m.secretCursor].Path
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.secretCursor > 0 {
+			m.secretCursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.secretCursor < len(m.secrets)-1 {
+			m.secretCursor++
+		}
+	case msg.String() == "c":
+		m.secretChoices[path] = secretCommitAnyway
+	case msg.String() == "r":
+		if m.canRevert(path) {
+			m.secretChoices[path] = secretRevert
+		}
+	case msg.String() == "i":
+		m.secretChoices[path] = secretIgnore
+	case msg.String() == "esc":
+		m.state = SaveStateReview
+	case key.Matches(msg, keys.Enter):
+		if m.secretsDecided() {
+			return m.resolveSecrets()
+		}
+	}
+	return m, nil
+}
+
+// renderSecrets renders the suspected secrets and the choice for each
+func (m SaveModel) renderSecrets() string {
+	var s string
+	s += RenderTitle("Possible Secrets") + "\n\n"
+	s += RenderError(fmt.Sprintf("⚠ Found %d thing(s) that look like passwords or keys", len(m.secrets))) + "\n\n"
+	s += RenderMuted("Anything you save can end up on GitHub, where it's hard to take back.") + "\n"
+	s += RenderMuted("Nothing has been saved yet. Choose what to do with each file:") + "\n\n"
+
+	for i, f := range m.secrets {
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.secretCursor {
+			cursor = MenuCursorStyle.Render("> ")
+			style = ListItemSelectedStyle
+		}
+
+		choice := MutedStyle.Render("[choose]")
+		switch m.secretChoices[f.Path] {
+		case secretCommitAnyway:
+			choice = ErrorStyle.Render("[commit anyway]")
+		case secretRevert:
+			choice = HighlightStyle.Render("[revert]")
+		case secretIgnore:
+			choice = SuccessStyle.Render("[ignore]")
+		}
+
+		s += fmt.Sprintf("%s%s %s\n", cursor, style.Render(truncateLine(f.Location(), 50)), choice)
+		detail := f.Rule
+		if f.Match != "" {
+			detail += ": " + f.Match
+		}
+		s += "    " + MutedStyle.Render(detail) + "\n"
+	}
+	s += "\n"
+
+	if path := m.secrets[m.secretCursor].Path; !m.canRevert(path) {
+		s += RenderMuted("This file is new, so there's no earlier version to revert to.") + "\n\n"
+	}
+
+	s += HelpBar(m.secretsKeys())
+	return s
+}
+
+// secretsKeys returns the keys for the suspected secrets, with enter once
+// every file has a choice
+func (m SaveModel) secretsKeys() Keys {
+	bar := []key.Binding{hint("↑↓", "navigate"), hint("c", "commit anyway"), hint("r", "revert"), hint("i", "ignore")}
+	if m.secretsDecided() {
+		bar = append(bar, hint("enter", "save"))
+	}
+	return Keys{
+		Bar:  append(bar, hint("esc", "back")),
+		More: []key.Binding{vimKeys},
+	}
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -10,6 +12,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"smooth/config"
+	"smooth/git"
+	"smooth/ops"
 )
 
 // SettingsState represents the state of the settings screen
@@ -22,17 +26,45 @@ const (
 	SettingsStateSaved
 	SettingsStateError
 	SettingsStateConfirmExit
+	SettingsStateThemeEditor
+	SettingsStateEditThemeField
+	SettingsStateImportTheme
+	SettingsStateExportTheme
+	SettingsStateEditTemplate
+	SettingsStateEditCheckCommand
+	SettingsStateEditExportDir
+	SettingsStateEditEndOfDay
+	SettingsStateEditWebhook
+	SettingsStateNewProfile
+	SettingsStateEditRemoteDefault
 )
 
 // SettingsModel is the model for the settings screen
 type SettingsModel struct {
 	cfg       config.Config
+	profile   string // active profile, "" for the default settings
 	cursor    int
 	state     SettingsState
 	textInput textinput.Model
 	err       error
 	dirty     bool // whether config has been modified
 	wantsExit bool // whether user confirmed exit
+
+	// The check command lives in the project's git config, not cfg
+	checkCommand string
+	inRepo       bool
+	hasHook      bool // whether the project has a pre-commit hook
+
+	signingKey git.SigningKey // the key git would sign saves with
+
+	debugLogging bool // whether debug logging was on in the saved settings
+
+	// Theme editor
+	editTheme   config.Theme // theme being edited
+	fieldCursor int          // 0 is the name, then each color in config.ThemeColors order
+	fieldInput  textinput.Model
+	message     string // result of the last theme edit, import or export
+	fieldErr    error  // why the last field value or theme save was rejected
 }
 
 // NewSettingsModel creates a new settings model
@@ -46,11 +78,31 @@ func NewSettingsModel() SettingsModel {
 	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
 	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
 
+	fi := textinput.New()
+	fi.CharLimit = 256
+	fi.Width = 40
+	fi.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	fi.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	inRepo := git.IsRepo()
+	checkCommand, hasHook := "", false
+	if inRepo {
+		checkCommand = git.CheckCommand()
+		hasHook = git.HasPreCommitHook()
+	}
+
 	return SettingsModel{
-		cfg:       cfg,
-		cursor:    0,
-		state:     SettingsStateMenu,
-		textInput: ti,
+		cfg:          cfg,
+		profile:      config.ActiveProfile(),
+		cursor:       0,
+		state:        SettingsStateMenu,
+		textInput:    ti,
+		fieldInput:   fi,
+		checkCommand: checkCommand,
+		inRepo:       inRepo,
+		hasHook:      hasHook,
+		signingKey:   git.DetectSigningKey(),
+		debugLogging: cfg.DebugLogging,
 	}
 }
 
@@ -64,10 +116,14 @@ type SettingsSaveMsg struct {
 	Err error
 }
 
-// doSaveSettings saves the config
-func doSaveSettings(cfg config.Config) tea.Cmd {
+// doSaveSettings saves the config, and the check command to the project
+func (m SettingsModel) doSaveSettings() tea.Cmd {
+	cfg, checkCommand, inRepo := m.cfg, m.checkCommand, m.inRepo
 	return func() tea.Msg {
 		err := config.Save(cfg)
+		if err == nil && inRepo {
+			err = git.SetCheckCommand(checkCommand)
+		}
 		return SettingsSaveMsg{Err: err}
 	}
 }
@@ -82,8 +138,13 @@ func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
 		} else {
 			m.state = SettingsStateSaved
 			m.dirty = false
-			// Apply theme now that it's saved
-			ApplyTheme(config.GetTheme(m.cfg.Theme))
+			// Apply theme, accessible mode, emoji and fancy animations now that they're saved
+			ApplySettings(m.cfg)
+			// Only follow debug logging if it changed, so --debug stays on
+			if m.cfg.DebugLogging != m.debugLogging {
+				git.SetDebugLogging(m.cfg.DebugLogging)
+				m.debugLogging = m.cfg.DebugLogging
+			}
 			// If we were saving before exit, mark exit now
 			if m.wantsExit {
 				return m, nil
@@ -94,13 +155,14 @@ func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
 	case tea.KeyMsg:
 		switch m.state {
 		case SettingsStateMenu:
+			m.message = ""
 			switch {
 			case key.Matches(msg, keys.Up):
 				if m.cursor > 0 {
 					m.cursor--
 				}
 			case key.Matches(msg, keys.Down):
-				if m.cursor < 3 { // 4 settings
+				if m.cursor < 30 { // 31 settings
 					m.cursor++
 				}
 			case key.Matches(msg, keys.Enter), msg.String() == " ":
@@ -116,25 +178,177 @@ func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
 				case 2: // Experiments toggle
 					m.cfg.ExperimentsEnabled = !m.cfg.ExperimentsEnabled
 					m.dirty = true
+				case 5: // Animations toggle
+					m.cfg.DisableAnimations = !m.cfg.DisableAnimations
+					m.dirty = true
+				case 6: // Save preview toggle
+					m.cfg.AlwaysPreviewSave = !m.cfg.AlwaysPreviewSave
+					m.dirty = true
 					// case 3 (Theme) - do nothing on enter/space, use arrows only
+				case 8: // Notifications toggle
+					m.cfg.NotificationsEnabled = !m.cfg.NotificationsEnabled
+					m.dirty = true
+				case 9: // Message template - switch to edit mode
+					m.state = SettingsStateEditTemplate
+					m.fieldInput.Placeholder = "[{ticket}] {message}"
+					m.fieldInput.SetValue(m.cfg.MessageTemplate)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
+				case 10: // Check command - switch to edit mode
+					if !m.inRepo {
+						break
+					}
+					m.state = SettingsStateEditCheckCommand
+					m.fieldInput.Placeholder = "npm test"
+					m.fieldInput.SetValue(m.checkCommand)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
+				case 12: // Export folder - switch to edit mode
+					m.state = SettingsStateEditExportDir
+					m.fieldInput.Placeholder = "~/smooth-exports"
+					m.fieldInput.SetValue(m.cfg.ExportDir)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
+				case 14: // Syntax highlighting toggle
+					m.cfg.DisableSyntaxHighlight = !m.cfg.DisableSyntaxHighlight
+					m.dirty = true
+				case 15: // End of day - switch to edit mode
+					m.state = SettingsStateEditEndOfDay
+					m.fieldInput.Placeholder = "17:30"
+					m.fieldInput.SetValue(m.cfg.EndOfDay)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
+				case 16: // End-of-day webhook - switch to edit mode
+					m.state = SettingsStateEditWebhook
+					m.fieldInput.Placeholder = "https://hooks.example.com/..."
+					m.fieldInput.SetValue(m.cfg.EndOfDayWebhook)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
+				case 19: // Accessibility mode toggle
+					m.cfg.AccessibleMode = !m.cfg.AccessibleMode
+					m.dirty = true
+				case 20: // Signed saves toggle
+					m.cfg.SignCommits = !m.cfg.SignCommits
+					m.dirty = true
+				case 22: // Debug logging toggle
+					m.cfg.DebugLogging = !m.cfg.DebugLogging
+					m.dirty = true
+				case 24: // Quit confirmation toggle
+					m.cfg.DisableQuitConfirm = !m.cfg.DisableQuitConfirm
+					m.dirty = true
+				case 26: // Sync everything toggle
+					m.cfg.SyncEverything = !m.cfg.SyncEverything
+					m.dirty = true
+				case 27: // Reduced motion toggle
+					m.cfg.ReducedMotion = !m.cfg.ReducedMotion
+					m.dirty = true
+				case 28: // Emoji toggle
+					m.cfg.NoEmoji = !m.cfg.NoEmoji
+					m.dirty = true
+				case 30: // Fancy animations toggle
+					m.cfg.FancyAnimations = !m.cfg.FancyAnimations
+					m.dirty = true
+				case 18: // Remote default - switch to edit mode
+					m.state = SettingsStateEditRemoteDefault
+					m.fieldInput.Placeholder = "git@github.com:my-org/"
+					m.fieldInput.SetValue(m.cfg.RemoteDefault)
+					m.fieldInput.Focus()
+					return m, textinput.Blink
 				}
 			case msg.String() == "right":
-				// Right arrow cycles theme forward
-				if m.cursor == 3 {
+				// Right arrow cycles theme, experience level, large file size, safety level, export format, network timeout, large repo mode, save on quit or mascot forward
+				switch m.cursor {
+				case 3:
 					m.cfg.Theme = nextTheme(m.cfg.Theme)
 					m.dirty = true
+				case 4:
+					m.cfg.ExperienceLevel = cycleLevel(m.cfg.ExperienceLevel, 1)
+					m.dirty = true
+				case 7:
+					m.cfg.LargeFileMB = cycleLargeFileMB(m.cfg.LargeFileMB, 1)
+					m.dirty = true
+				case 11:
+					m.cfg.SafetyLevel = cycleSafetyLevel(m.cfg.SafetyLevel, 1)
+					m.dirty = true
+				case 13:
+					m.cfg.ExportFormat = cycleExportFormat(m.cfg.ExportFormat)
+					m.dirty = true
+				case 17:
+					m.switchProfile(1)
+				case 21:
+					m.cfg.NetworkTimeoutSeconds = cycleNetworkTimeout(m.cfg.NetworkTimeoutSeconds, 1)
+					m.dirty = true
+				case 23:
+					m.cfg.LargeRepoMode = cycleLargeRepoMode(m.cfg.LargeRepoMode, 1)
+					m.dirty = true
+				case 25:
+					m.cfg.SaveOnQuit = cycleSaveOnQuit(m.cfg.SaveOnQuit, 1)
+					m.dirty = true
+				case 29:
+					m.cfg.Mascot = cycleMascot(m.cfg.Mascot, 1)
+					m.dirty = true
 				}
 			case msg.String() == "left":
-				// Left arrow cycles theme backward
-				if m.cursor == 3 {
+				// Left arrow cycles theme, experience level, large file size, safety level, export format, network timeout, large repo mode, save on quit or mascot backward
+				switch m.cursor {
+				case 3:
 					m.cfg.Theme = prevTheme(m.cfg.Theme)
 					m.dirty = true
+				case 4:
+					m.cfg.ExperienceLevel = cycleLevel(m.cfg.ExperienceLevel, -1)
+					m.dirty = true
+				case 7:
+					m.cfg.LargeFileMB = cycleLargeFileMB(m.cfg.LargeFileMB, -1)
+					m.dirty = true
+				case 11:
+					m.cfg.SafetyLevel = cycleSafetyLevel(m.cfg.SafetyLevel, -1)
+					m.dirty = true
+				case 13:
+					m.cfg.ExportFormat = cycleExportFormat(m.cfg.ExportFormat)
+					m.dirty = true
+				case 17:
+					m.switchProfile(-1)
+				case 21:
+					m.cfg.NetworkTimeoutSeconds = cycleNetworkTimeout(m.cfg.NetworkTimeoutSeconds, -1)
+					m.dirty = true
+				case 23:
+					m.cfg.LargeRepoMode = cycleLargeRepoMode(m.cfg.LargeRepoMode, -1)
+					m.dirty = true
+				case 25:
+					m.cfg.SaveOnQuit = cycleSaveOnQuit(m.cfg.SaveOnQuit, -1)
+					m.dirty = true
+				case 29:
+					m.cfg.Mascot = cycleMascot(m.cfg.Mascot, -1)
+					m.dirty = true
 				}
+			case msg.String() == "n" && m.cursor == 17:
+				m.state = SettingsStateNewProfile
+				m.fieldErr = nil
+				m.fieldInput.Placeholder = "work"
+				m.fieldInput.SetValue("")
+				m.fieldInput.Focus()
+				return m, textinput.Blink
+			case msg.String() == "e" && m.cursor == 3:
+				m.openThemeEditor()
+			case msg.String() == "i" && m.cursor == 3:
+				m.state = SettingsStateImportTheme
+				m.fieldErr = nil
+				m.fieldInput.Placeholder = "path/to/theme.json"
+				m.fieldInput.SetValue("")
+				m.fieldInput.Focus()
+				return m, textinput.Blink
+			case msg.String() == "x" && m.cursor == 3:
+				m.state = SettingsStateExportTheme
+				m.fieldErr = nil
+				m.fieldInput.Placeholder = ""
+				m.fieldInput.SetValue(m.cfg.Theme + ".json")
+				m.fieldInput.Focus()
+				return m, textinput.Blink
 			case msg.String() == "s":
 				// Save settings
 				if m.dirty {
 					m.state = SettingsStateSaving
-					return m, doSaveSettings(m.cfg)
+					return m, m.doSaveSettings()
 				}
 			}
 
@@ -161,6 +375,182 @@ func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
 				return m, cmd
 			}
 
+		case SettingsStateEditTemplate:
+			switch msg.String() {
+			case "enter":
+				m.cfg.MessageTemplate = strings.TrimSpace(m.fieldInput.Value())
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateEditCheckCommand:
+			switch msg.String() {
+			case "enter":
+				m.checkCommand = strings.TrimSpace(m.fieldInput.Value())
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateEditExportDir:
+			switch msg.String() {
+			case "enter":
+				m.cfg.ExportDir = strings.TrimSpace(m.fieldInput.Value())
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateEditEndOfDay:
+			switch msg.String() {
+			case "enter":
+				value := strings.TrimSpace(m.fieldInput.Value())
+				if value != "" {
+					t, err := config.ParseClock(value)
+					if err != nil {
+						m.fieldErr = err
+						return m, nil
+					}
+					value = t.Format("15:04")
+				}
+				m.fieldErr = nil
+				m.cfg.EndOfDay = value
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.fieldErr = nil
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateEditWebhook:
+			switch msg.String() {
+			case "enter":
+				value := strings.TrimSpace(m.fieldInput.Value())
+				if value != "" && !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+					m.fieldErr = fmt.Errorf("the webhook should be a web address starting with https://")
+					return m, nil
+				}
+				m.fieldErr = nil
+				m.cfg.EndOfDayWebhook = value
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.fieldErr = nil
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateNewProfile:
+			switch msg.String() {
+			case "enter":
+				name := strings.TrimSpace(m.fieldInput.Value())
+				if err := config.CreateProfile(name, m.cfg); err != nil {
+					m.fieldErr = err
+					return m, nil
+				}
+				m.fieldErr = nil
+				m.profile = name
+				m.dirty = false
+				m.message = "Created and switched to profile " + name
+				m.state = SettingsStateMenu
+			case "esc":
+				m.fieldErr = nil
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateEditRemoteDefault:
+			switch msg.String() {
+			case "enter":
+				m.cfg.RemoteDefault = strings.TrimSpace(m.fieldInput.Value())
+				m.dirty = true
+				m.state = SettingsStateMenu
+			case "esc":
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateThemeEditor:
+			return m.updateThemeEditor(msg)
+
+		case SettingsStateEditThemeField:
+			switch msg.String() {
+			case "enter":
+				if err := m.setThemeField(m.fieldInput.Value()); err != nil {
+					m.fieldErr = err
+					return m, nil
+				}
+				m.fieldErr = nil
+				m.state = SettingsStateThemeEditor
+			case "esc":
+				m.fieldErr = nil
+				m.state = SettingsStateThemeEditor
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
+		case SettingsStateImportTheme, SettingsStateExportTheme:
+			switch msg.String() {
+			case "enter":
+				path := strings.TrimSpace(m.fieldInput.Value())
+				if m.state == SettingsStateImportTheme {
+					id, err := config.ImportTheme(path)
+					if err != nil {
+						m.fieldErr = err
+						return m, nil
+					}
+					m.cfg.Theme = id
+					m.dirty = true
+					m.message = fmt.Sprintf("Imported %q", config.GetTheme(id).Name)
+				} else {
+					if err := config.ExportTheme(themeID(m.cfg.Theme), path); err != nil {
+						m.fieldErr = err
+						return m, nil
+					}
+					m.message = "Exported to " + path
+				}
+				m.fieldErr = nil
+				m.state = SettingsStateMenu
+			case "esc":
+				m.fieldErr = nil
+				m.state = SettingsStateMenu
+			default:
+				var cmd tea.Cmd
+				m.fieldInput, cmd = m.fieldInput.Update(msg)
+				return m, cmd
+			}
+
 		case SettingsStateSaved:
 			// Any key goes back to main menu
 			m.wantsExit = true
@@ -183,7 +573,7 @@ func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
 				// Save and exit
 				m.state = SettingsStateSaving
 				m.wantsExit = true
-				return m, doSaveSettings(m.cfg)
+				return m, m.doSaveSettings()
 			}
 		}
 	}
@@ -203,29 +593,110 @@ func (m SettingsModel) View() string {
 
 		// Show theme preview when hovering over theme option
 		if m.cursor == 3 {
-			s += m.renderThemePreview() + "\n"
+			s += renderThemePreview(themeFor(m.cfg.Theme)) + "\n"
+		}
+
+		if m.message != "" {
+			s += RenderSuccess("✓ "+m.message) + "\n\n"
 		}
 
 		if m.dirty {
 			s += HighlightStyle.Render("• Unsaved changes") + "\n\n"
-			if m.cursor == 3 {
-				s += HelpBar([][]string{{"↑↓", "navigate"}, {"←→", "cycle theme"}, {"s", "save"}, {"esc", "back"}})
-			} else {
-				s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "toggle"}, {"s", "save"}, {"esc", "back"}})
-			}
-		} else {
-			if m.cursor == 3 {
-				s += HelpBar([][]string{{"↑↓", "navigate"}, {"←→", "cycle theme"}, {"esc", "back"}})
-			} else {
-				s += HelpBar([][]string{{"↑↓", "navigate"}, {"enter", "toggle"}, {"esc", "back"}})
-			}
 		}
+		s += HelpBar(m.Keys())
+
+	case SettingsStateThemeEditor, SettingsStateEditThemeField:
+		s += m.renderThemeEditor()
+
+	case SettingsStateImportTheme:
+		s += RenderSubtitle("Import a theme from a JSON file:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		if m.fieldErr != nil {
+			s += RenderError(m.fieldErr.Error()) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case SettingsStateExportTheme:
+		s += RenderSubtitle(fmt.Sprintf("Export %q to:", themeFor(m.cfg.Theme).Name)) + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		if m.fieldErr != nil {
+			s += RenderError(m.fieldErr.Error()) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
 
 	case SettingsStateEditMaxBackups:
 		s += RenderSubtitle("Maximum backups to keep:") + "\n\n"
 		s += m.textInput.View() + "\n\n"
 		s += RenderMuted("Enter a number between 1 and 1000") + "\n\n"
-		s += HelpBar([][]string{{"enter", "confirm"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
+
+	case SettingsStateEditTemplate:
+		s += RenderSubtitle("Save message template:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		for _, p := range ops.TemplatePlaceholders {
+			s += fmt.Sprintf("  %s %s\n", HighlightStyle.Render(fmt.Sprintf("%-10s", p[0])), MutedStyle.Render(p[1]))
+		}
+		s += "\n" + RenderMuted("Without {message}, the template goes in front of what you type.") + "\n"
+		if tmpl := strings.TrimSpace(m.fieldInput.Value()); tmpl != "" {
+			example := ops.ExpandMessageTemplate(tmpl, "Fix the login button", "experiment-ABC-123-login",
+				[]string{"login.html", "style.css"}, time.Now())
+			s += RenderMuted("Example: ") + example + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+
+	case SettingsStateEditCheckCommand:
+		s += RenderSubtitle("Check command for this project:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		s += RenderMuted("Runs before every save, like a formatter, linter or the tests.") + "\n"
+		s += RenderMuted("If it fails, you see its output and choose whether to save anyway.") + "\n"
+		s += RenderMuted("Leave it empty to turn it off. A pre-commit hook runs either way.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SettingsStateEditExportDir:
+		s += RenderSubtitle("Folder for exported backups:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		s += RenderMuted("Point it at a USB stick or a synced folder like Dropbox to keep copies safe.") + "\n"
+		s += RenderMuted("Leave it empty to use ~/smooth-exports.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SettingsStateEditEndOfDay:
+		s += RenderSubtitle("Wrap up the day at:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		if m.fieldErr != nil {
+			s += RenderError(m.fieldErr.Error()) + "\n\n"
+		}
+		s += RenderMuted("At this time, while smooth is open, unsaved work is saved as") + "\n"
+		s += RenderMuted("\""+wipMessage+"\", uploaded if auto-sync is on, and a summary") + "\n"
+		s += RenderMuted("of the day's saves shows up in Stats.") + "\n"
+		s += RenderMuted("Use 24-hour time. Leave it empty to turn it off.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SettingsStateEditWebhook:
+		s += RenderSubtitle("Send the end-of-day summary to:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		if m.fieldErr != nil {
+			s += RenderError(m.fieldErr.Error()) + "\n\n"
+		}
+		s += RenderMuted("The summary is posted as JSON, so a Slack or Zapier webhook, or") + "\n"
+		s += RenderMuted("an email relay, can pass it on. Leave it empty to turn it off.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SettingsStateNewProfile:
+		s += RenderSubtitle("Name for the new profile:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		if m.fieldErr != nil {
+			s += RenderError(m.fieldErr.Error()) + "\n\n"
+		}
+		s += RenderMuted("It starts with the settings shown here. Use it for one run with") + "\n"
+		s += RenderMuted("smooth --profile <name>, or switch to it here.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SettingsStateEditRemoteDefault:
+		s += RenderSubtitle("Start of new projects' GitHub address:") + "\n\n"
+		s += m.fieldInput.View() + "\n\n"
+		s += RenderMuted("Sync to GitHub fills in this plus the project's folder name,") + "\n"
+		s += RenderMuted("like git@github.com:my-org/my-project.git. Leave it empty to type it each time.") + "\n\n"
+		s += HelpBar(m.Keys())
 
 	case SettingsStateSaving:
 		s += RenderHighlight("Saving settings...") + "\n"
@@ -237,14 +708,14 @@ func (m SettingsModel) View() string {
 	case SettingsStateError:
 		s += RenderError("✗ Failed to save settings") + "\n\n"
 		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
+			s += renderErrorDetails(m.err)
 		}
 		s += HelpText("Press any key to go back")
 
 	case SettingsStateConfirmExit:
 		s += RenderError("⚠ You have unsaved changes!") + "\n\n"
 		s += RenderMuted("Do you want to save before leaving?") + "\n\n"
-		s += HelpBar([][]string{{"s", "save and exit"}, {"y", "exit without saving"}, {"n", "cancel"}})
+		s += HelpBar(m.Keys())
 	}
 
 	return BoxStyle.Render(s)
@@ -276,8 +747,143 @@ func (m SettingsModel) renderSettingsList() string {
 		},
 		{
 			name:        "Theme",
-			description: "Color scheme for the interface",
-			value:       config.GetTheme(m.cfg.Theme).Name,
+			description: themeDescription(m.cfg.Theme),
+			value:       themeName(m.cfg.Theme),
+		},
+		{
+			name:        "Experience level",
+			description: levelDescription(m.cfg.ExperienceLevel),
+			value:       levelName(m.cfg.ExperienceLevel),
+		},
+		{
+			name:        "Animations",
+			description: "Spinners, progress bars and screen transitions",
+			value:       formatBool(!m.cfg.DisableAnimations),
+		},
+		{
+			name:        "Always preview saves",
+			description: "Show the git commands a save will run and ask before running them",
+			value:       formatBool(m.cfg.AlwaysPreviewSave),
+		},
+		{
+			name:        "Large file warning",
+			description: "Offer Git LFS or .gitignore for files at least this big when saving",
+			value:       fmt.Sprintf("%d MB", m.cfg.LargeFileMB),
+		},
+		{
+			name:        "Desktop notifications",
+			description: "Tell me when auto-sync fails, waiting saves upload, or someone syncs new saves",
+			value:       formatBool(m.cfg.NotificationsEnabled),
+		},
+		{
+			name:        "Save message template",
+			description: "Add a ticket number, date or emoji to every save message",
+			value:       templateValue(m.cfg.MessageTemplate),
+		},
+		{
+			name:        "Check before saving",
+			description: "A command to run before each save in this project, like npm test",
+			value:       m.checkCommandValue(),
+		},
+		{
+			name:        "Safety level",
+			description: safetyDescription(m.cfg.SafetyLevel),
+			value:       safetyName(m.cfg.SafetyLevel),
+		},
+		{
+			name:        "Export folder",
+			description: "Where Export backup saves zips of your project",
+			value:       exportDirValue(m.cfg.ExportDir),
+		},
+		{
+			name:        "Export format",
+			description: "zip opens anywhere; tar.gz is smaller on Mac and Linux",
+			value:       m.cfg.ExportFormat,
+		},
+		{
+			name:        "Syntax highlighting",
+			description: "Color the code in diffs by language; turn off for terminals with few colors",
+			value:       formatBool(!m.cfg.DisableSyntaxHighlight),
+		},
+		{
+			name:        "End of day",
+			description: "Save unsaved work and sum up the day at this time",
+			value:       offIfEmpty(m.cfg.EndOfDay),
+		},
+		{
+			name:        "End-of-day webhook",
+			description: "Post each day's summary to this address, for Slack, Zapier or email",
+			value:       offIfEmpty(m.cfg.EndOfDayWebhook),
+		},
+		{
+			name:        "Profile",
+			description: m.profileDescription(),
+			value:       profileName(m.profile),
+		},
+		{
+			name:        "Remote default",
+			description: "Where new projects go on GitHub, like git@github.com:my-org/",
+			value:       offIfEmpty(m.cfg.RemoteDefault),
+		},
+		{
+			name:        "Accessibility mode",
+			description: "Plain text for screen readers: no color, borders or emoji. Restart to apply fully",
+			value:       formatBool(m.cfg.AccessibleMode),
+		},
+		{
+			name:        "Sign saves",
+			description: m.signingDescription(),
+			value:       formatBool(m.cfg.SignCommits),
+		},
+		{
+			name:        "Network timeout",
+			description: "How long syncing waits for GitHub before giving up. Press esc to stop sooner",
+			value:       timeoutName(m.cfg.NetworkTimeoutSeconds),
+		},
+		{
+			name:        "Debug logging",
+			description: "Log every git command to ~/.smooth/logs to attach to bug reports, under View logs",
+			value:       formatBool(m.cfg.DebugLogging),
+		},
+		{
+			name:        "Large repo mode",
+			description: largeRepoDescription(m.cfg.LargeRepoMode),
+			value:       largeRepoName(m.cfg.LargeRepoMode),
+		},
+		{
+			name:        "Ask before quitting",
+			description: "Offer to save unsaved changes when quitting with q",
+			value:       formatBool(!m.cfg.DisableQuitConfirm),
+		},
+		{
+			name:        "Save on quit",
+			description: saveOnQuitDescription(m.cfg.SaveOnQuit),
+			value:       saveOnQuitName(m.cfg.SaveOnQuit),
+		},
+		{
+			name:        "Sync everything",
+			description: "Sync uploads every experiment, backup and tag too, not just the current branch",
+			value:       formatBool(m.cfg.SyncEverything),
+		},
+		{
+			name:        "Reduced motion",
+			description: "Still indicators instead of spinners, no screen transitions and no mascot art",
+			value:       formatBool(m.cfg.ReducedMotion),
+		},
+		{
+			name:        "Emoji",
+			description: "Turn off if your terminal or font draws emoji as boxes or out of line",
+			value:       formatBool(!m.cfg.NoEmoji),
+		},
+		{
+			name:        "Mascot",
+			description: mascotDescription(m.cfg.Mascot),
+			value:       mascotName(m.cfg.Mascot),
+		},
+		{
+			name:        "Fancy animations",
+			description: "Screens type themselves out as they open and checkmarks pop in. Paused on slow terminals",
+			value:       fancyAnimationsValue(m.cfg.FancyAnimations),
 		},
 	}
 
@@ -294,8 +900,8 @@ func (m SettingsModel) renderSettingsList() string {
 		nameStr := style.Render(setting.name)
 		valueStr := HighlightStyle.Render(setting.value)
 
-		// Theme setting gets arrow indicators
-		if i == 3 { // Theme
+		// Theme, experience level, large file, safety, export format, profile, network timeout, large repo, save on quit and mascot settings get arrow indicators
+		if i == 3 || i == 4 || i == 7 || i == 11 || i == 13 || i == 17 || i == 21 || i == 23 || i == 25 || i == 29 {
 			if m.cursor == i {
 				// Show arrows when selected
 				s += fmt.Sprintf("%s%s: ← %s →\n", cursor, nameStr, valueStr)
@@ -313,6 +919,110 @@ func (m SettingsModel) renderSettingsList() string {
 	return s
 }
 
+// templateValue formats the save message template for display
+func templateValue(template string) string {
+	if template == "" {
+		return "None"
+	}
+	return template
+}
+
+// checkCommandValue formats the project's check command for display
+func (m SettingsModel) checkCommandValue() string {
+	switch {
+	case !m.inRepo:
+		return "Not in a project"
+	case m.checkCommand != "":
+		return m.checkCommand
+	case m.hasHook:
+		return "None (pre-commit hook runs)"
+	}
+	return "None"
+}
+
+// signingDescription says which key saves would be signed with, or how to
+// set one up
+func (m SettingsModel) signingDescription() string {
+	if !m.signingKey.Found() {
+		return "No signing key found. Set one with git config --global user.signingkey first"
+	}
+	return "Sign each save with your " + m.signingKey.String() + " so others can verify it's yours"
+}
+
+// switchProfile moves to the next or previous profile and loads its
+// settings. Unsaved changes would be lost, so they have to be saved first.
+func (m *SettingsModel) switchProfile(delta int) {
+	if m.dirty {
+		return
+	}
+	names := append([]string{""}, config.Profiles()...)
+	current := -1
+	for i, name := range names {
+		if name == m.profile {
+			current = i
+		}
+	}
+	if current < 0 {
+		// A --profile that hasn't been saved yet
+		names = append(names, m.profile)
+		current = len(names) - 1
+	}
+	next := names[(current+delta+len(names))%len(names)]
+	if next == m.profile {
+		return
+	}
+	if err := config.SwitchProfile(next); err != nil {
+		m.state = SettingsStateError
+		m.err = err
+		return
+	}
+	m.profile = next
+	m.cfg, _ = config.Load()
+	ApplySettings(m.cfg)
+	m.message = "Switched to " + profileName(next)
+}
+
+// profileDescription explains the profile setting, or why it can't be
+// changed right now
+func (m SettingsModel) profileDescription() string {
+	if m.dirty {
+		return "Save your changes first to switch profiles"
+	}
+	return "Separate settings for work, personal projects or demos"
+}
+
+// profileName formats a profile for display
+func profileName(name string) string {
+	if name == "" {
+		return "Default"
+	}
+	return name
+}
+
+// offIfEmpty formats an optional setting for display
+func offIfEmpty(value string) string {
+	if value == "" {
+		return "Off"
+	}
+	return value
+}
+
+// exportDirValue formats the export folder for display
+func exportDirValue(dir string) string {
+	if dir == "" {
+		return "~/smooth-exports"
+	}
+	return dir
+}
+
+// cycleExportFormat switches between the export formats
+func cycleExportFormat(current string) string {
+	if current == config.ExportZip {
+		return config.ExportTarGz
+	}
+	return config.ExportZip
+}
+
 // formatBool formats a boolean for display
 func formatBool(b bool) string {
 	if b {
@@ -321,35 +1031,287 @@ func formatBool(b bool) string {
 	return "Off"
 }
 
+// largeFileSizes are the large file thresholds to choose from, in MB
+var largeFileSizes = []int{10, 25, 50, 100, 200}
+
+// cycleLargeFileMB returns the large file threshold step places away from
+// current, wrapping around
+func cycleLargeFileMB(current, step int) int {
+	n := len(largeFileSizes)
+	i := 0
+	for j, size := range largeFileSizes {
+		if size <= current {
+			i = j
+		}
+	}
+	return largeFileSizes[((i+step)%n+n)%n]
+}
+
+// networkTimeouts are the network timeouts to choose from, in seconds
+var networkTimeouts = []int{30, 60, 120, 300, 600, 1800}
+
+// cycleNetworkTimeout returns the network timeout step places away from
+// current, wrapping around
+func cycleNetworkTimeout(current, step int) int {
+	n := len(networkTimeouts)
+	i := 0
+	for j, seconds := range networkTimeouts {
+		if seconds <= current {
+			i = j
+		}
+	}
+	return networkTimeouts[((i+step)%n+n)%n]
+}
+
+// timeoutName describes a network timeout, like "30 seconds" or "5 minutes"
+func timeoutName(seconds int) string {
+	if seconds >= 60 && seconds%60 == 0 {
+		return plural(seconds/60, "minute")
+	}
+	return plural(seconds, "second")
+}
+
+// cycleLevel returns the experience level step places away, wrapping around
+func cycleLevel(current string, step int) string {
+	n := len(config.ExperienceLevels)
+	i := config.LevelRank(current)
+	if i < 0 {
+		i = 0
+	}
+	return config.ExperienceLevels[((i+step)%n+n)%n]
+}
+
+// levelName returns the display name of an experience level
+func levelName(level string) string {
+	switch level {
+	case config.LevelBeginner:
+		return "Beginner"
+	case config.LevelAdvanced:
+		return "Advanced"
+	}
+	return "Intermediate"
+}
+
+// levelDescription explains what an experience level shows
+func levelDescription(level string) string {
+	switch level {
+	case config.LevelBeginner:
+		return "Just the essentials: save, revert, backups and sync"
+	case config.LevelAdvanced:
+		return "Everything, with the git command behind each action"
+	}
+	return "Adds the timeline, ignored files and experiments"
+}
+
+// cycleSafetyLevel returns the safety level step places away, wrapping around
+func cycleSafetyLevel(current string, step int) string {
+	n := len(config.SafetyLevels)
+	i := 0
+	for j, level := range config.SafetyLevels {
+		if level == current {
+			i = j
+		}
+	}
+	return config.SafetyLevels[((i+step)%n+n)%n]
+}
+
+// cycleLargeRepoMode returns the large repo mode step places away from
+// current
+func cycleLargeRepoMode(current string, step int) string {
+	n := len(config.LargeRepoModes)
+	i := 0
+	for j, mode := range config.LargeRepoModes {
+		if mode == current {
+			i = j
+		}
+	}
+	return config.LargeRepoModes[((i+step)%n+n)%n]
+}
+
+// cycleSaveOnQuit returns the save on quit mode step places away from
+// current
+func cycleSaveOnQuit(current string, step int) string {
+	n := len(config.SaveOnQuitModes)
+	i := 0
+	for j, mode := range config.SaveOnQuitModes {
+		if mode == current {
+			i = j
+		}
+	}
+	return config.SaveOnQuitModes[((i+step)%n+n)%n]
+}
+
+// saveOnQuitName returns the display name of a save on quit mode
+func saveOnQuitName(mode string) string {
+	switch mode {
+	case config.SaveOnQuitSave:
+		return "Save"
+	case config.SaveOnQuitSync:
+		return "Save and sync"
+	}
+	return "Off"
+}
+
+// saveOnQuitDescription explains a save on quit mode
+func saveOnQuitDescription(mode string) string {
+	switch mode {
+	case config.SaveOnQuitSave:
+		return "Quick save unsaved changes with a timestamped message when quitting with q"
+	case config.SaveOnQuitSync:
+		return "Quick save unsaved changes when quitting with q, then upload them to GitHub"
+	}
+	return "Leave unsaved changes for next time when quitting"
+}
+
+// fancyAnimationsValue shows if fancy animations are on, and if they're
+// paused because the terminal is too slow for them
+func fancyAnimationsValue(on bool) string {
+	if on && slowTerminal {
+		return "On (paused, slow terminal)"
+	}
+	return formatBool(on)
+}
+
+// cycleMascot returns the mascot step places away from current
+func cycleMascot(current string, step int) string {
+	n := len(config.Mascots)
+	i := 0
+	for j, mascot := range config.Mascots {
+		if mascot == current {
+			i = j
+		}
+	}
+	return config.Mascots[((i+step)%n+n)%n]
+}
+
+// mascotName returns the display name of a mascot
+func mascotName(mascot string) string {
+	switch mascot {
+	case config.MascotDog:
+		return "Dog"
+	case config.MascotRobot:
+		return "Robot"
+	case config.MascotOff:
+		return "Off"
+	}
+	return "Cat"
+}
+
+// mascotDescription explains what the mascot setting does
+func mascotDescription(mascot string) string {
+	if mascot == config.MascotOff {
+		return "No mascot cheering after saves and milestones"
+	}
+	return "Cheers after every save, and when you reach milestones like your tenth save or first sync"
+}
+
+// largeRepoName returns the display name of a large repo mode
+func largeRepoName(mode string) string {
+	switch mode {
+	case config.LargeRepoOn:
+		return "On"
+	case config.LargeRepoOff:
+		return "Off"
+	}
+	if lastRefresh.LargeRepo {
+		return "Auto (on)"
+	}
+	return "Auto"
+}
+
+// largeRepoDescription explains a large repo mode, with how long checking
+// for changes took last time so it's clear why auto picked what it did
+func largeRepoDescription(mode string) string {
+	var s string
+	switch mode {
+	case config.LargeRepoOn:
+		s = "Refresh every 10 seconds and count lines only for the files shown"
+	case config.LargeRepoOff:
+		s = "Always refresh every 2 seconds, however long it takes"
+	default:
+		s = "Refresh less often when checking for changes gets slow"
+	}
+	if !lastRefresh.At.IsZero() {
+		s += fmt.Sprintf(". Last check took %s", lastRefresh.Status.Round(time.Millisecond))
+	}
+	return s
+}
+
+// safetyName returns the display name of a safety level
+func safetyName(level string) string {
+	switch level {
+	case config.SafetyBeginner:
+		return "Beginner"
+	case config.SafetyExpert:
+		return "Expert"
+	}
+	return "Normal"
+}
+
+// safetyDescription explains what a safety level asks before doing
+func safetyDescription(level string) string {
+	switch level {
+	case config.SafetyBeginner:
+		return "Ask twice before restoring, reverting or abandoning, with warnings"
+	case config.SafetyExpert:
+		return "No confirmations or warnings, and force upload when sync is rejected"
+	}
+	return "Ask once before restoring, reverting or abandoning, with warnings"
+}
+
+// themeCycle returns the themes to cycle through, auto first
+func themeCycle() []string {
+	return append([]string{config.ThemeAuto}, config.ThemeIDs()...)
+}
+
 // nextTheme returns the next theme in the cycle
 func nextTheme(current string) string {
-	for i, name := range config.ThemeNames {
+	themes := themeCycle()
+	for i, name := range themes {
 		if name == current {
-			nextIdx := (i + 1) % len(config.ThemeNames)
-			return config.ThemeNames[nextIdx]
+			nextIdx := (i + 1) % len(themes)
+			return themes[nextIdx]
 		}
 	}
-	return config.ThemeNames[0]
+	return themes[0]
 }
 
 // prevTheme returns the previous theme in the cycle
 func prevTheme(current string) string {
-	for i, name := range config.ThemeNames {
+	themes := themeCycle()
+	for i, name := range themes {
 		if name == current {
 			prevIdx := i - 1
 			if prevIdx < 0 {
-				prevIdx = len(config.ThemeNames) - 1
+				prevIdx = len(themes) - 1
 			}
-			return config.ThemeNames[prevIdx]
+			return themes[prevIdx]
 		}
 	}
-	return config.ThemeNames[0]
+	return themes[0]
 }
 
-// renderThemePreview renders a preview of the selected theme's colors
-func (m SettingsModel) renderThemePreview() string {
-	theme := config.GetTheme(m.cfg.Theme)
+// themeName returns the theme's name, with the one auto picked
+func themeName(name string) string {
+	if name == config.ThemeAuto {
+		return "Auto (" + themeFor(name).Name + ")"
+	}
+	return config.GetTheme(name).Name
+}
 
+// themeDescription explains the theme setting, and what auto does
+func themeDescription(name string) string {
+	if name != config.ThemeAuto {
+		return "Color scheme for the interface"
+	}
+	if darkBackground() {
+		return "Matches your terminal, which has a dark background"
+	}
+	return "Matches your terminal, which has a light background"
+}
+
+// renderThemePreview renders a preview of a theme's colors
+func renderThemePreview(theme config.Theme) string {
 	// Create styles using the theme colors directly
 	primaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
 	secondaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Secondary))
@@ -360,9 +1322,7 @@ func (m SettingsModel) renderThemePreview() string {
 	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Text))
 	highlightStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Highlight)).Bold(true)
 
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(theme.Secondary)).
+	boxStyle := PanelStyle(lipgloss.Color(theme.Secondary)).
 		Padding(0, 1)
 
 	var preview string
@@ -384,6 +1344,53 @@ func (m SettingsModel) renderThemePreview() string {
 	return boxStyle.Render(preview) + "\n"
 }
 
+// Keys returns the keys the settings screen takes in its current state
+func (m SettingsModel) Keys() Keys {
+	switch m.state {
+	case SettingsStateMenu:
+		bar := []key.Binding{hint("↑↓", "navigate")}
+		switch m.cursor {
+		case 3:
+			bar = append(bar, hint("←→", "cycle theme"), hint("e", "edit"), hint("i", "import"), hint("x", "export"))
+		case 4:
+			bar = append(bar, hint("←→", "change level"))
+		case 25:
+			bar = append(bar, hint("←→", "change mode"))
+		case 29:
+			bar = append(bar, hint("←→", "change mascot"))
+		case 17:
+			if !m.dirty {
+				bar = append(bar, hint("←→", "switch"))
+			}
+			bar = append(bar, hint("n", "new profile"))
+		default:
+			bar = append(bar, hint("enter", "toggle"))
+		}
+		if m.dirty {
+			bar = append(bar, hint("s", "save"))
+		}
+		return Keys{
+			Bar:  append(bar, hint("esc", "back")),
+			More: []key.Binding{vimKeys, hint("space", "toggle"), hint("←→", "change a choice")},
+		}
+	case SettingsStateThemeEditor, SettingsStateEditThemeField:
+		return m.themeEditorKeys()
+	case SettingsStateImportTheme:
+		return Keys{Bar: []key.Binding{hint("enter", "import"), hint("esc", "cancel")}, Typing: true}
+	case SettingsStateExportTheme:
+		return Keys{Bar: []key.Binding{hint("enter", "export"), hint("esc", "cancel")}, Typing: true}
+	case SettingsStateNewProfile:
+		return Keys{Bar: []key.Binding{hint("enter", "create"), hint("esc", "cancel")}, Typing: true}
+	case SettingsStateEditMaxBackups, SettingsStateEditTemplate, SettingsStateEditCheckCommand,
+		SettingsStateEditExportDir, SettingsStateEditEndOfDay, SettingsStateEditWebhook,
+		SettingsStateEditRemoteDefault:
+		return Keys{Bar: []key.Binding{hint("enter", "confirm"), hint("esc", "cancel")}, Typing: true}
+	case SettingsStateConfirmExit:
+		return Keys{Bar: []key.Binding{hint("s", "save and exit"), hint("y", "exit without saving"), hint("n", "cancel")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the settings screen should close
 func (m SettingsModel) IsDone() bool {
 	return false // Settings screen doesn't auto-close
@@ -394,6 +1401,19 @@ func (m SettingsModel) WantsBack() bool {
 	return m.wantsExit
 }
 
+// HandlesEsc returns true if esc should go to the settings screen itself
+// (to cancel an edit) rather than leave it
+func (m SettingsModel) HandlesEsc() bool {
+	switch m.state {
+	case SettingsStateEditMaxBackups, SettingsStateThemeEditor, SettingsStateEditThemeField,
+		SettingsStateImportTheme, SettingsStateExportTheme, SettingsStateEditTemplate,
+		SettingsStateEditCheckCommand, SettingsStateEditExportDir, SettingsStateEditEndOfDay,
+		SettingsStateEditWebhook, SettingsStateNewProfile, SettingsStateEditRemoteDefault:
+		return true
+	}
+	return false
+}
+
 // HasUnsavedChanges returns true if there are unsaved changes
 func (m SettingsModel) HasUnsavedChanges() bool {
 	return m.dirty
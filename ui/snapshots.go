@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// SnapshotsState represents the state of the snapshots flow
+type SnapshotsState int
+
+const (
+	SnapshotsStateList SnapshotsState = iota
+	SnapshotsStateConfirm
+	SnapshotsStateRestoring
+	SnapshotsStateSuccess
+	SnapshotsStateError
+)
+
+// SnapshotsModel is the model for browsing and restoring snapshots
+type SnapshotsModel struct {
+	snapshots []git.Snapshot
+	files     []string // files changed in the highlighted snapshot
+	cursor    int
+	state     SnapshotsState
+	notice    string
+	err       error
+	width     int
+	height    int
+}
+
+// NewSnapshotsModel creates a new snapshots model
+func NewSnapshotsModel() SnapshotsModel {
+	m := SnapshotsModel{state: SnapshotsStateList}
+	m.load()
+	return m
+}
+
+// load reads the snapshots and the files of the highlighted one
+func (m *SnapshotsModel) load() {
+	m.snapshots, m.err = git.ListSnapshots()
+	if m.err != nil {
+		m.state = SnapshotsStateError
+		return
+	}
+	if m.cursor >= len(m.snapshots) {
+		m.cursor = max(0, len(m.snapshots)-1)
+	}
+	m.loadFiles()
+}
+
+// loadFiles reads the files of the highlighted snapshot
+func (m *SnapshotsModel) loadFiles() {
+	m.files = nil
+	if len(m.snapshots) > 0 {
+		m.files, _ = git.SnapshotFiles(m.snapshots[m.cursor])
+	}
+}
+
+// Init initializes the model
+func (m SnapshotsModel) Init() tea.Cmd {
+	return nil
+}
+
+// SnapshotRestoreMsg is sent when restoring a snapshot completes
+type SnapshotRestoreMsg struct {
+	Err error
+}
+
+// doRestoreSnapshot puts the snapshot's files back
+func doRestoreSnapshot(s git.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		return SnapshotRestoreMsg{Err: git.RestoreSnapshot(s)}
+	}
+}
+
+// Update handles messages
+func (m SnapshotsModel) Update(msg tea.Msg) (SnapshotsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case SnapshotRestoreMsg:
+		if msg.Err != nil {
+			m.state = SnapshotsStateError
+			m.err = msg.Err
+		} else {
+			m.state = SnapshotsStateSuccess
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case SnapshotsStateList:
+			m.notice = ""
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+					m.loadFiles()
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(m.snapshots)-1 {
+					m.cursor++
+					m.loadFiles()
+				}
+			case msg.String() == "s":
+				if !git.HasChanges() {
+					m.notice = "No unsaved changes to snapshot."
+					break
+				}
+				if err := git.TakeSnapshot("Manual snapshot"); err != nil {
+					m.notice = err.Error()
+					break
+				}
+				m.cursor = 0
+				m.load()
+				m.notice = "✓ Snapshot taken"
+			case msg.String() == "d":
+				if len(m.snapshots) > 0 {
+					if err := git.DeleteSnapshot(m.snapshots[m.cursor]); err != nil {
+						m.notice = err.Error()
+						break
+					}
+					m.load()
+				}
+			case key.Matches(msg, keys.Enter):
+				if len(m.snapshots) > 0 {
+					m.state = SnapshotsStateConfirm
+				}
+			}
+
+		case SnapshotsStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				m.state = SnapshotsStateRestoring
+				return m, doRestoreSnapshot(m.snapshots[m.cursor])
+			case "n", "N", "esc":
+				m.state = SnapshotsStateList
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the snapshots screen
+func (m SnapshotsModel) View() string {
+	var s string
+
+	s += RenderTitle("Snapshots") + "\n\n"
+
+	switch m.state {
+	case SnapshotsStateList:
+		s += RenderMuted("Copies of your unsaved work, taken before anything that could lose it.") + "\n\n"
+
+		if len(m.snapshots) == 0 {
+			s += RenderMuted("No snapshots yet.") + "\n\n"
+			if m.notice != "" {
+				s += RenderHighlight(m.notice) + "\n\n"
+			}
+			s += HelpBar(m.Keys())
+			return BoxStyle.Render(s)
+		}
+
+		maxVisible := 8
+		if m.height > 0 {
+			maxVisible = max(3, m.height-22)
+		}
+		start := 0
+		if m.cursor >= maxVisible {
+			start = m.cursor - maxVisible + 1
+		}
+
+		for i := start; i < len(m.snapshots) && i < start+maxVisible; i++ {
+			snap := m.snapshots[i]
+			cursor := "  "
+			style := ListItemStyle
+			if i == m.cursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			when := MutedStyle.Render(snap.Time.Format("Mon Jan 2 15:04"))
+			s += fmt.Sprintf("%s%s %s\n", cursor, when, style.Render(snap.Reason))
+		}
+		if len(m.snapshots) > maxVisible {
+			s += MutedStyle.Render(fmt.Sprintf("  ... %d total", len(m.snapshots))) + "\n"
+		}
+
+		s += "\n" + RenderSubtitle("Unsaved files in this snapshot:") + "\n"
+		s += m.renderFiles() + "\n"
+
+		if m.notice != "" {
+			s += RenderHighlight(m.notice) + "\n\n"
+		}
+		s += HelpBar(m.Keys())
+
+	case SnapshotsStateConfirm:
+		snap := m.snapshots[m.cursor]
+		s += RenderHighlight(fmt.Sprintf("Restore \"%s\" from %s?", snap.Reason, snap.Time.Format("Mon Jan 2 15:04"))) + "\n\n"
+		s += RenderMuted("Your files go back to how they were then. Your current files are") + "\n"
+		s += RenderMuted("snapshotted first, so you can undo this.") + "\n\n"
+		s += RenderSubtitle("Restore? (y/n)") + "\n"
+
+	case SnapshotsStateRestoring:
+		s += RenderHighlight("Restoring...") + "\n"
+
+	case SnapshotsStateSuccess:
+		s += RenderSuccess("✓ Snapshot restored") + "\n\n"
+		s += RenderMuted("The files aren't saved yet. Save when you're happy with them.") + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case SnapshotsStateError:
+		s += RenderError("✗ Snapshot failed") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderFiles lists the highlighted snapshot's files, capped to a few lines
+func (m SnapshotsModel) renderFiles() string {
+	if len(m.files) == 0 {
+		return RenderMuted("  (none)") + "\n"
+	}
+	const maxFiles = 6
+	var s string
+	for i, f := range m.files {
+		if i == maxFiles {
+			s += MutedStyle.Render(fmt.Sprintf("  ... and %d more", len(m.files)-maxFiles)) + "\n"
+			break
+		}
+		s += "  " + f + "\n"
+	}
+	return s
+}
+
+// Keys returns the keys the snapshots screen takes in its current state
+func (m SnapshotsModel) Keys() Keys {
+	switch m.state {
+	case SnapshotsStateList:
+		if len(m.snapshots) == 0 {
+			return Keys{Bar: []key.Binding{hint("s", "snapshot now"), hint("esc", "back")}}
+		}
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "restore"), hint("s", "snapshot now"), hint("d", "delete"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	case SnapshotsStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "restore the snapshot"), hint("n", "no, back to the list")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true if esc should cancel the confirmation rather than
+// leave the screen
+func (m SnapshotsModel) HandlesEsc() bool {
+	return m.state == SnapshotsStateConfirm
+}
+
+// IsDone returns true if a restore has finished
+func (m SnapshotsModel) IsDone() bool {
+	return m.state == SnapshotsStateSuccess || m.state == SnapshotsStateError
+}
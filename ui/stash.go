@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// StashState represents the state of the stashed changes screen
+type StashState int
+
+const (
+	StashStateList StashState = iota
+	StashStateConflicts
+	StashStateConfirmDrop
+	StashStateSuccess
+	StashStateError
+	StashStateEmpty
+)
+
+// StashModel lists unsaved changes put aside in git's stash, puts them back
+// or deletes them, and walks through any files that clash on the way back
+type StashModel struct {
+	state     StashState
+	stashes   []git.StashEntry
+	cursor    int
+	stash     git.StashEntry // being put back or deleted
+	conflicts []string       // files still needing a decision
+	resolved  []string
+	confirm   confirmation
+	message   string
+	notice    string
+	err       error
+}
+
+// NewStashModel creates a model for the stashed changes screen
+func NewStashModel() StashModel {
+	m := StashModel{confirm: newConfirmation()}
+	m.load()
+	return m
+}
+
+// NewStashConflictModel opens the stashed changes screen on changes that
+// clashed when smooth put them back
+func NewStashConflictModel(conflict *git.StashConflictError) StashModel {
+	m := NewStashModel()
+	m.startConflicts(conflict)
+	return m
+}
+
+// load reads the stash, and shows the empty state if there's nothing in it
+func (m *StashModel) load() {
+	m.stashes, m.err = git.ListStashes()
+	switch {
+	case m.err != nil:
+		m.state = StashStateError
+	case len(m.stashes) == 0:
+		m.state = StashStateEmpty
+	default:
+		m.state = StashStateList
+		m.cursor = min(m.cursor, len(m.stashes)-1)
+	}
+}
+
+// startConflicts walks through the files that clashed, or explains why the
+// changes couldn't go back if none did
+func (m *StashModel) startConflicts(conflict *git.StashConflictError) {
+	m.stash = conflict.Stash
+	if len(conflict.Files) == 0 {
+		m.notice = conflict.Error()
+		return
+	}
+	m.conflicts = conflict.Files
+	m.resolved = nil
+	m.cursor = 0
+	m.state = StashStateConflicts
+}
+
+// Init initializes the model
+func (m StashModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m StashModel) Update(msg tea.Msg) (StashModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch m.state {
+	case StashStateList:
+		m.notice = ""
+		switch {
+		case key.Matches(keyMsg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(keyMsg, keys.Down):
+			if m.cursor < len(m.stashes)-1 {
+				m.cursor++
+			}
+		case key.Matches(keyMsg, keys.Enter):
+			m.stash = m.stashes[m.cursor]
+			err := git.ApplyStash(m.stash.Hash)
+			var conflict *git.StashConflictError
+			switch {
+			case errors.As(err, &conflict):
+				m.startConflicts(conflict)
+			case err != nil:
+				m.notice = err.Error()
+			default:
+				m.message = "Your changes are back"
+				m.state = StashStateSuccess
+			}
+		case keyMsg.String() == "d":
+			m.stash = m.stashes[m.cursor]
+			m.confirm = newConfirmation()
+			if m.confirm.done() {
+				return m.drop()
+			}
+			m.state = StashStateConfirmDrop
+		}
+
+	case StashStateConflicts:
+		return m.updateConflicts(keyMsg)
+
+	case StashStateConfirmDrop:
+		switch keyMsg.String() {
+		case "y", "Y":
+			if m.confirm.confirm() {
+				return m.drop()
+			}
+		case "n", "N", "esc":
+			m.confirm.reset()
+			m.state = StashStateList
+		}
+	}
+	return m, nil
+}
+
+// drop deletes the stashed changes picked and shows what's left
+func (m StashModel) drop() (StashModel, tea.Cmd) {
+	if err := git.DropStash(m.stash.Hash); err != nil {
+		m.state = StashStateError
+		m.err = err
+		return m, nil
+	}
+	m.load()
+	return m, nil
+}
+
+// updateConflicts handles a decision for each file that clashed, then
+// finishes once every file has one
+func (m StashModel) updateConflicts(msg tea.KeyMsg) (StashModel, tea.Cmd) {
+	m.notice = ""
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.conflicts)-1 {
+			m.cursor++
+		}
+	case "m", "s", "e":
+		if len(m.conflicts) == 0 {
+			return m, nil
+		}
+		path := m.conflicts[m.cursor]
+		var err error
+		switch msg.String() {
+		case "m":
+			err = git.ResolveStashConflict(path, true)
+		case "s":
+			err = git.ResolveStashConflict(path, false)
+		case "e":
+			err = git.MarkStashResolved(path)
+		}
+		if err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.resolved = append(m.resolved, path)
+		m.conflicts = append(m.conflicts[:m.cursor:m.cursor], m.conflicts[m.cursor+1:]...)
+		if m.cursor >= len(m.conflicts) && m.cursor > 0 {
+			m.cursor--
+		}
+	case "c", "enter":
+		if len(m.conflicts) > 0 {
+			m.notice = fmt.Sprintf("%d file(s) still need a decision", len(m.conflicts))
+			return m, nil
+		}
+		if err := git.FinishStashConflict(m.stash.Hash); err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.message = "Your changes are back"
+		m.state = StashStateSuccess
+	case "l", "esc":
+		// Leave them stashed, with the files as they were before
+		if err := git.UndoStashApply(); err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.message = "Your changes are kept in the stash for later"
+		m.state = StashStateSuccess
+	}
+	return m, nil
+}
+
+// View renders the stashed changes screen
+func (m StashModel) View() string {
+	var s string
+
+	s += RenderTitle("Stashed changes") + "\n\n"
+
+	switch m.state {
+	case StashStateEmpty:
+		s += RenderMuted("Nothing is stashed.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case StashStateList:
+		s += RenderMuted("Unsaved changes put aside, like while switching experiments.") + "\n\n"
+		for i, stash := range m.stashes {
+			cursor, style := "  ", ListItemStyle
+			if i == m.cursor {
+				cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+			}
+			s += cursor + style.Render(truncateLine(stashLabel(stash), 50)) + " " + MutedStyle.Render(stash.Age) + "\n"
+		}
+		if m.notice != "" {
+			s += "\n" + RenderError("✗ "+m.notice) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+
+	case StashStateConflicts:
+		s += m.renderConflicts()
+
+	case StashStateConfirmDrop:
+		if m.confirm.warn {
+			s += RenderError("⚠ These changes were never saved!") + "\n\n"
+		}
+		s += RenderMuted("Deleting them can't be undone:") + "\n"
+		s += "  " + HighlightStyle.Render(stashLabel(m.stash)) + " " + MutedStyle.Render(m.stash.Age) + "\n\n"
+		s += m.confirm.prompt("Delete them?") + "\n"
+
+	case StashStateSuccess:
+		s += RenderSuccess("✓ "+m.message) + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case StashStateError:
+		s += RenderError("✗ Couldn't read the stash") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderConflicts walks through the files that clashed, one decision per
+// file
+func (m StashModel) renderConflicts() string {
+	var s string
+	if len(m.conflicts) == 0 {
+		s += RenderSuccess("✓ Every file is sorted out") + "\n\n"
+		for _, path := range m.resolved {
+			s += RenderMuted("  ✓ "+path) + "\n"
+		}
+		if m.notice != "" {
+			s += "\n" + RenderError(m.notice) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+		return s
+	}
+
+	s += RenderError("⚠ Your unsaved changes clashed with the saved files") + "\n\n"
+	s += RenderMuted("For each file, keep your unsaved version or the saved one, or open") + "\n"
+	s += RenderMuted("it in your editor, fix the parts between <<<<<<< and >>>>>>>, and mark it fixed.") + "\n\n"
+	for i, path := range m.conflicts {
+		cursor, style := "  ", ListItemStyle
+		if i == m.cursor {
+			cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		s += cursor + style.Render(path) + "\n"
+	}
+	for _, path := range m.resolved {
+		s += RenderMuted("  ✓ "+path) + "\n"
+	}
+	if m.notice != "" {
+		s += "\n" + RenderError(m.notice) + "\n"
+	}
+	s += "\n" + HelpBar(m.Keys())
+	return s
+}
+
+// stashLabel describes stashed changes: what smooth was doing when it
+// stashed them, or the message git gave them
+func stashLabel(stash git.StashEntry) string {
+	if i := strings.Index(stash.Message, "smooth: "); i >= 0 {
+		return "Before " + stash.Message[i+len("smooth: "):]
+	}
+	return stash.Message
+}
+
+// Keys returns the keys the stash screen takes in its current state
+func (m StashModel) Keys() Keys {
+	switch m.state {
+	case StashStateList:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "put back"), hint("d", "delete"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	case StashStateConflicts:
+		if len(m.conflicts) == 0 {
+			return Keys{Bar: []key.Binding{hint("c", "continue")}, More: []key.Binding{hint("enter", "continue")}}
+		}
+		return Keys{
+			Bar:  []key.Binding{hint("m", "keep mine"), hint("s", "keep saved"), hint("e", "I fixed it"), hint("l", "leave stashed")},
+			More: []key.Binding{hint("↑↓", "navigate"), vimKeys, hint("esc", "leave stashed")},
+		}
+	case StashStateConfirmDrop:
+		return Keys{More: []key.Binding{hint("y", "delete them"), hint("n", "no, keep them")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true once the changes are back or there's nothing to do
+func (m StashModel) IsDone() bool {
+	return m.state == StashStateSuccess || m.state == StashStateError || m.state == StashStateEmpty
+}
+
+// HandlesEsc returns true while esc should leave changes stashed rather
+// than leave the screen with conflict markers in files, and while confirming
+func (m StashModel) HandlesEsc() bool {
+	return m.state == StashStateConflicts || m.state == StashStateConfirmDrop
+}
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/achievements"
+	"smooth/git"
+)
+
+// statsChartDays is how many days the saves chart covers
+const statsChartDays = 14
+
+// statsBarWidth is the width of the longest bar in the charts
+const statsBarWidth = 30
+
+// statsEndOfDays is how many end-of-day summaries are listed
+const statsEndOfDays = 5
+
+// StatsModel is the model for the stats screen
+type StatsModel struct {
+	state  achievements.State
+	days   []git.DaySummary // end-of-day summaries, newest first
+	now    time.Time
+	width  int
+	height int
+}
+
+// NewStatsModel creates a new stats model
+func NewStatsModel() StatsModel {
+	state, _ := achievements.Load()
+	return StatsModel{state: state, days: git.RecentDaySummaries(statsEndOfDays), now: time.Now()}
+}
+
+// Init initializes the model
+func (m StatsModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m StatsModel) Update(msg tea.Msg) (StatsModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the stats screen
+func (m StatsModel) View() string {
+	var s string
+
+	s += RenderTitle("Stats") + "\n\n"
+
+	streak := m.state.Streak(m.now)
+	longest := max(m.state.LongestStreak, streak)
+	total := m.state.Totals()
+
+	flame := "🔥"
+	if streak == 0 {
+		flame = "·"
+	}
+	s += fmt.Sprintf("%s %s   %s %s\n",
+		flame, HighlightStyle.Render(plural(streak, "day")+" streak"),
+		MutedStyle.Render("best:"), plural(longest, "day"))
+	s += fmt.Sprintf("%s %s   %s %s   %s\n\n",
+		MutedStyle.Render("saves:"), HighlightStyle.Render(fmt.Sprint(m.state.Saves)),
+		MutedStyle.Render("lines:"), SuccessStyle.Render(fmt.Sprintf("+%d", total.Added)),
+		ErrorStyle.Render(fmt.Sprintf("-%d", total.Deleted)))
+
+	if m.state.Saves == 0 {
+		s += RenderMuted("No saves yet. Save your work to start a streak!") + "\n\n"
+		s += HelpBar(m.Keys())
+		return BoxStyle.Render(s)
+	}
+
+	s += RenderSubtitle(fmt.Sprintf("Saves, last %d days", statsChartDays)) + "\n"
+	s += m.renderSavesChart() + "\n"
+
+	s += RenderSubtitle("Lines changed, last 4 weeks") + "\n"
+	s += m.renderLinesChart()
+
+	if len(m.days) > 0 {
+		s += "\n" + RenderSubtitle("End of day") + "\n"
+		s += m.renderEndOfDays()
+	}
+
+	s += HelpBar(m.Keys())
+	return BoxStyle.Render(s)
+}
+
+// renderSavesChart draws a bar per day for the saves made that day
+func (m StatsModel) renderSavesChart() string {
+	days := make([]achievements.DayStats, statsChartDays)
+	most := 0
+	for i := range days {
+		days[i] = m.state.Day(m.now.AddDate(0, 0, i-statsChartDays+1))
+		most = max(most, days[i].Saves)
+	}
+
+	barStyle := lipgloss.NewStyle().Foreground(ColorSecondary)
+	todayStyle := lipgloss.NewStyle().Foreground(ColorAccent)
+
+	var s string
+	for i, d := range days {
+		day := m.now.AddDate(0, 0, i-statsChartDays+1)
+		style := barStyle
+		if i == len(days)-1 {
+			style = todayStyle
+		}
+		s += fmt.Sprintf("  %s %s %s\n",
+			MutedStyle.Render(day.Format("Mon Jan 02")),
+			style.Render(bar(d.Saves, most, statsBarWidth)),
+			MutedStyle.Render(countLabel(d.Saves)))
+	}
+	return s
+}
+
+// renderLinesChart draws added and deleted lines per week, oldest first
+func (m StatsModel) renderLinesChart() string {
+	weeks := make([]achievements.DayStats, 4)
+	for i := 0; i < 28; i++ {
+		d := m.state.Day(m.now.AddDate(0, 0, -i))
+		w := &weeks[3-i/7]
+		w.Added += d.Added
+		w.Deleted += d.Deleted
+	}
+	most := 0
+	for _, w := range weeks {
+		most = max(most, w.Added, w.Deleted)
+	}
+
+	half := statsBarWidth / 2
+	var s string
+	for i, w := range weeks {
+		label := fmt.Sprintf("%d weeks ago", 3-i)
+		switch 3 - i {
+		case 0:
+			label = "this week"
+		case 1:
+			label = "last week"
+		}
+		s += fmt.Sprintf("  %s %s %s %s\n",
+			MutedStyle.Render(fmt.Sprintf("%-11s", label)),
+			SuccessStyle.Render(bar(w.Added, most, half)),
+			ErrorStyle.Render(bar(w.Deleted, most, half)),
+			MutedStyle.Render(fmt.Sprintf("+%d -%d", w.Added, w.Deleted)))
+	}
+	return s
+}
+
+// renderEndOfDays lists the recent end-of-day summaries, a line per day
+// plus anything that went wrong
+func (m StatsModel) renderEndOfDays() string {
+	var s string
+	for _, day := range m.days {
+		label := day.Date
+		if t, err := time.Parse("2006-01-02", day.Date); err == nil {
+			label = t.Format("Mon Jan 02")
+		}
+		s += fmt.Sprintf("  %s %s · %s · %s %s",
+			MutedStyle.Render(label), plural(len(day.Saves), "save"), plural(len(day.Files), "file"),
+			SuccessStyle.Render(fmt.Sprintf("+%d", day.Added)), ErrorStyle.Render(fmt.Sprintf("-%d", day.Deleted)))
+		if day.WIPHash != "" {
+			s += MutedStyle.Render(" · work in progress saved [" + day.WIPHash + "]")
+		}
+		s += "\n"
+		if day.WIPError != "" {
+			msg, _, _ := strings.Cut(day.WIPError, "\n")
+			s += "    " + ErrorStyle.Render("Unsaved work wasn't saved: "+truncateLine(msg, 60)) + "\n"
+		}
+		if day.SyncError != "" {
+			msg, _, _ := strings.Cut(day.SyncError, "\n")
+			s += "    " + ErrorStyle.Render("Upload failed: "+truncateLine(msg, 60)) + "\n"
+		}
+	}
+	return s
+}
+
+// bar renders value as a bar scaled so that most fills width. Any nonzero
+// value gets at least a sliver so it doesn't look like nothing happened.
+func bar(value, most, width int) string {
+	if most == 0 || value == 0 {
+		return strings.Repeat(" ", width)
+	}
+	n := max(1, value*width/most)
+	return strings.Repeat("█", n) + strings.Repeat(" ", width-n)
+}
+
+// countLabel formats a day's save count, leaving zero days blank
+func countLabel(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
+
+// Keys returns the keys the stats screen takes
+func (m StatsModel) Keys() Keys {
+	return Keys{Bar: []key.Binding{hint("esc", "back")}}
+}
+
+// IsDone returns false; the stats screen is left with esc
+func (m StatsModel) IsDone() bool {
+	return false
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"smooth/git"
+)
+
+// StatusBar is where the project stands: the branch, unsaved files, what's
+// waiting to sync and how long ago the last save was. The menu keeps it up
+// to date in the background, so every screen shows the same thing.
+type StatusBar struct {
+	Branch     string
+	Detached   bool
+	Experiment bool // on a branch other than main
+	Unsaved    int  // changed and new files
+	Waiting    int  // saves queued to upload after a failed sync
+	Retrying   bool // the queued saves are being uploaded now
+	Ahead      int
+	Behind     int
+	LastSave   time.Time
+	CI         git.CIStatus // the checks on the last upload from this branch
+}
+
+// StatusBar returns the status as last loaded, and false while it's still
+// loading
+func (m MenuModel) StatusBar() (StatusBar, bool) {
+	return StatusBar{
+		Branch:     m.branch,
+		Detached:   m.detached,
+		Experiment: !m.isOnMain,
+		Unsaved:    len(m.changedFiles),
+		Waiting:    m.savesWaiting,
+		Retrying:   m.retryingSync,
+		Ahead:      m.ahead,
+		Behind:     m.behind,
+		LastSave:   m.lastSave,
+		CI:         m.lastCI(),
+	}, !m.loading
+}
+
+// lastCI returns the checks on the last upload, if it was from this branch
+// and there are any
+func (m MenuModel) lastCI() git.CIStatus {
+	if !m.ci.shown() || m.ci.Branch != m.branch {
+		return git.CIStatus{}
+	}
+	return m.ci.Status
+}
+
+// View renders the status as one line
+func (b StatusBar) View() string {
+	branch := b.Branch
+	if b.Detached {
+		branch = ErrorStyle.Render("none") + " " + MutedStyle.Render("(on an old save)")
+	} else if b.Experiment {
+		branch = HighlightStyle.Render(b.Branch) + " " + MutedStyle.Render("(experiment)")
+	}
+	parts := []string{fmt.Sprintf("Branch: %s", branch)}
+
+	if b.Unsaved > 0 {
+		parts = append(parts, SuccessStyle.Render(plural(b.Unsaved, "unsaved file")))
+	}
+	if b.Waiting > 0 {
+		waiting := plural(b.Waiting, "save") + " waiting to upload"
+		if b.Retrying {
+			waiting += "..."
+		}
+		parts = append(parts, HighlightStyle.Render("⬆ "+waiting))
+	} else if b.Ahead > 0 && !b.Detached {
+		parts = append(parts, HighlightStyle.Render("⬆ "+plural(b.Ahead, "save")+" not uploaded"))
+	}
+	if b.Behind > 0 && !b.Detached {
+		parts = append(parts, MutedStyle.Render("⬇ "+plural(b.Behind, "new save")+" on GitHub"))
+	}
+	if badge := ciBadge(b.CI); badge != "" {
+		parts = append(parts, badge)
+	}
+	if !b.LastSave.IsZero() {
+		parts = append(parts, MutedStyle.Render("saved "+timeAgo(b.LastSave)))
+	}
+	if op := git.BusyWith(); op != "" {
+		parts = append(parts, MutedStyle.Render("⏳ "+busyLabel(op)+"..."))
+	}
+	return strings.Join(parts, MutedStyle.Render(" · "))
+}
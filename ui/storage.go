@@ -0,0 +1,309 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// StorageState represents the state of the storage screen
+type StorageState int
+
+const (
+	StorageStateLoading StorageState = iota
+	StorageStateList
+	StorageStateConfirmRemove
+	StorageStateWorking
+	StorageStateError
+)
+
+// storageMaxFiles is how many of the biggest files and history versions are
+// listed
+const storageMaxFiles = 8
+
+// storageAction is what happens to a large file chosen on the storage screen
+type storageAction int
+
+const (
+	storageIgnore storageAction = iota // stop tracking it, keep it on disk
+	storageRemove                      // delete it
+)
+
+// StorageModel shows what takes up space in the project: the history in
+// .git, the biggest files, the biggest versions kept in history and the
+// backups, with ways to stop big files growing it further
+type StorageModel struct {
+	spinner spinner.Model
+	state   StorageState
+	report  storageReport
+	cursor  int          // selected file among report.files
+	confirm confirmation // before deleting a file
+	message string       // what the last action did
+	err     error
+}
+
+// storageReport is everything the storage screen measures
+type storageReport struct {
+	size        git.RepoSize
+	files       []git.TrackedFile
+	objects     []git.HistoryObject
+	backups     int
+	backupBytes int64
+}
+
+// storageLoadedMsg is sent when the project has been measured
+type storageLoadedMsg struct {
+	report storageReport
+	err    error
+}
+
+// storageChangedMsg is sent when a large file has been ignored or removed
+type storageChangedMsg struct {
+	path   string
+	action storageAction
+	err    error
+}
+
+// NewStorageModel creates a model for the storage screen
+func NewStorageModel() StorageModel {
+	return StorageModel{
+		spinner: newSpinner(SpinnerDots),
+		confirm: newConfirmation(),
+	}
+}
+
+// Init measures the project, which can take a while with a long history
+func (m StorageModel) Init() tea.Cmd {
+	return tea.Batch(spinnerTick(m.spinner), doMeasureStorage())
+}
+
+// doMeasureStorage measures the project's history, files and backups
+func doMeasureStorage() tea.Cmd {
+	return func() tea.Msg {
+		var report storageReport
+		var err error
+		if report.size, err = git.GetRepoSize(); err != nil {
+			return storageLoadedMsg{err: err}
+		}
+		// A project without saves has no files in history yet
+		report.files, _ = git.LargestTrackedFiles(storageMaxFiles)
+		if report.objects, err = git.LargestObjects(storageMaxFiles); err != nil {
+			return storageLoadedMsg{err: err}
+		}
+		report.backups, report.backupBytes, _ = git.BackupStorage()
+		return storageLoadedMsg{report: report}
+	}
+}
+
+// doChangeLargeFile ignores or removes a file and saves that
+func doChangeLargeFile(path string, action storageAction) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if action == storageRemove {
+			err = git.RemoveFile(path)
+		} else {
+			err = git.StopTracking(path)
+		}
+		return storageChangedMsg{path: path, action: action, err: err}
+	}
+}
+
+// Update handles messages
+func (m StorageModel) Update(msg tea.Msg) (StorageModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case storageLoadedMsg:
+		if msg.err != nil {
+			m.state = StorageStateError
+			m.err = msg.err
+			return m, nil
+		}
+		m.state = StorageStateList
+		m.report = msg.report
+		if m.cursor >= len(m.report.files) {
+			m.cursor = max(0, len(m.report.files)-1)
+		}
+		return m, nil
+
+	case storageChangedMsg:
+		if msg.err != nil {
+			m.state = StorageStateList
+			m.message = RenderError("✗ " + msg.err.Error())
+			return m, nil
+		}
+		if msg.action == storageRemove {
+			m.message = RenderSuccess("✓ Removed " + msg.path)
+		} else {
+			m.message = RenderSuccess("✓ " + msg.path + " stays on this computer but won't be saved again")
+		}
+		// Measure again to show what changed
+		m.state = StorageStateLoading
+		return m, tea.Batch(spinnerTick(m.spinner), doMeasureStorage())
+
+	case spinner.TickMsg:
+		if m.state == StorageStateLoading || m.state == StorageStateWorking {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case StorageStateList:
+			return m.updateList(msg)
+		case StorageStateConfirmRemove:
+			switch msg.String() {
+			case "y", "Y":
+				if m.confirm.confirm() {
+					m.state = StorageStateWorking
+					path := m.report.files[m.cursor].Path
+					return m, tea.Batch(spinnerTick(m.spinner), doChangeLargeFile(path, storageRemove))
+				}
+			case "n", "N", "esc":
+				m.confirm.reset()
+				m.state = StorageStateList
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateList handles keys on the storage report
+func (m StorageModel) updateList(msg tea.KeyMsg) (StorageModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.cursor < len(m.report.files)-1 {
+			m.cursor++
+		}
+	case msg.String() == "i" && len(m.report.files) > 0:
+		m.message = ""
+		m.state = StorageStateWorking
+		return m, tea.Batch(spinnerTick(m.spinner), doChangeLargeFile(m.report.files[m.cursor].Path, storageIgnore))
+	case msg.String() == "d" && len(m.report.files) > 0:
+		m.message = ""
+		m.confirm = newConfirmation()
+		if m.confirm.done() {
+			m.state = StorageStateWorking
+			return m, tea.Batch(spinnerTick(m.spinner), doChangeLargeFile(m.report.files[m.cursor].Path, storageRemove))
+		}
+		m.state = StorageStateConfirmRemove
+	}
+	return m, nil
+}
+
+// View renders the storage screen
+func (m StorageModel) View() string {
+	var s string
+
+	s += RenderTitle("Storage") + "\n\n"
+
+	switch m.state {
+	case StorageStateLoading:
+		s += m.spinner.View() + " " + RenderHighlight("Measuring your project...") + "\n"
+
+	case StorageStateList:
+		s += m.renderReport()
+
+	case StorageStateConfirmRemove:
+		path := m.report.files[m.cursor].Path
+		if m.confirm.warn {
+			s += RenderError("⚠ "+path+" will be deleted from this computer too!") + "\n\n"
+		}
+		s += RenderMuted("Earlier saves still have it, so you can get it back from them.") + "\n\n"
+		s += m.confirm.prompt("Delete "+path+" and save that?") + "\n"
+
+	case StorageStateWorking:
+		s += m.spinner.View() + " " + RenderHighlight("Saving the change...") + "\n"
+
+	case StorageStateError:
+		s += RenderError("✗ Couldn't measure this project") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderReport renders the sizes, biggest files and biggest history
+func (m StorageModel) renderReport() string {
+	var s string
+	r := m.report
+
+	s += RenderSubtitle("History on this computer: ") + RenderHighlight(formatSize(r.size.Total())) + "\n"
+	if r.size.Loose > 0 || r.size.Garbage > 0 {
+		s += RenderMuted(fmt.Sprintf("  %s packed, %s not packed yet", formatSize(r.size.Packed), formatSize(r.size.Loose+r.size.Garbage))) + "\n"
+	}
+	if r.backups == 1 {
+		s += RenderMuted(fmt.Sprintf("  1 backup keeps about %s that nothing else needs", formatSize(r.backupBytes))) + "\n"
+	} else if r.backups > 1 {
+		s += RenderMuted(fmt.Sprintf("  %d backups keep about %s that nothing else needs", r.backups, formatSize(r.backupBytes))) + "\n"
+	}
+	s += "\n"
+
+	s += RenderSubtitle("Biggest files in your project") + "\n"
+	if len(r.files) == 0 {
+		s += RenderMuted("  No saves yet") + "\n"
+	}
+	for i, f := range r.files {
+		cursor, style := "  ", ListItemStyle
+		if i == m.cursor {
+			cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		s += cursor + style.Render(truncateLine(f.Path, 50)) + " " + MutedStyle.Render(formatSize(f.Size)) + "\n"
+	}
+	s += "\n"
+
+	s += RenderSubtitle("Biggest versions kept in history") + "\n"
+	if len(r.objects) == 0 {
+		s += RenderMuted("  Nothing yet") + "\n"
+	}
+	for _, o := range r.objects {
+		where := "old version"
+		if o.Current {
+			where = "in your project"
+		}
+		s += "  " + truncateLine(o.Path, 50) + " " + MutedStyle.Render(formatSize(o.OnDisk)+" · "+where) + "\n"
+	}
+	s += "\n"
+	s += RenderMuted("Old versions stay in history after a file is ignored or removed,") + "\n"
+	s += RenderMuted("so every copy of the project still downloads them.") + "\n\n"
+
+	if m.message != "" {
+		s += m.message + "\n\n"
+	}
+	s += HelpBar(m.Keys())
+	return s
+}
+
+// Keys returns the keys the storage screen takes in its current state
+func (m StorageModel) Keys() Keys {
+	switch m.state {
+	case StorageStateList:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "choose"), hint("i", "stop saving it"), hint("d", "delete it"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	case StorageStateConfirmRemove:
+		return Keys{More: []key.Binding{hint("y", "delete it"), hint("n", "no, keep it")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if measuring failed, so any key goes back
+func (m StorageModel) IsDone() bool {
+	return m.state == StorageStateError
+}
+
+// HandlesEsc returns true while confirming a delete, where esc cancels it
+func (m StorageModel) HandlesEsc() bool {
+	return m.state == StorageStateConfirmRemove
+}
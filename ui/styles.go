@@ -1,6 +1,9 @@
 package ui
 
 import (
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
 	"github.com/charmbracelet/lipgloss"
 
 	"smooth/config"
@@ -58,7 +61,25 @@ var (
 
 func init() {
 	// Apply default theme on startup
-	ApplyTheme(config.CurrentTheme())
+	ReloadTheme()
+}
+
+// darkBackground reports whether the terminal's background is dark. The
+// terminal is only asked once, and only if the auto theme needs to know.
+var darkBackground = sync.OnceValue(lipgloss.HasDarkBackground)
+
+// themeID returns the ID of the theme to show for the configured one,
+// picking a light or dark theme for auto
+func themeID(name string) string {
+	if name != config.ThemeAuto {
+		return name
+	}
+	return config.ResolveTheme(name, darkBackground())
+}
+
+// themeFor returns the theme to show for the configured one
+func themeFor(name string) config.Theme {
+	return config.GetTheme(themeID(name))
 }
 
 // ApplyTheme updates all styles based on the given theme
@@ -73,6 +94,7 @@ func ApplyTheme(theme config.Theme) {
 	ColorBackground = lipgloss.Color(theme.Background)
 	ColorText = lipgloss.Color(theme.Text)
 	ColorHighlight = lipgloss.Color(theme.Highlight)
+	codeStyles = map[lipgloss.TerminalColor]*chroma.Style{}
 
 	// Update text styles
 	TitleStyle = lipgloss.NewStyle().
@@ -151,11 +173,40 @@ func ApplyTheme(theme config.Theme) {
 	ListItemDescStyle = lipgloss.NewStyle().
 		Foreground(ColorMuted).
 		PaddingLeft(4)
+
+	// Without emoji, boxes leave them out before measuring what's in them,
+	// so the borders still line up
+	if noEmoji {
+		BoxStyle = BoxStyle.Transform(StripEmoji)
+		HeaderBoxStyle = HeaderBoxStyle.Transform(StripEmoji)
+	}
+
+	// Accessible mode has no boxes to draw or backgrounds to read past
+	if accessibleMode {
+		BoxStyle = lipgloss.NewStyle()
+		HeaderBoxStyle = lipgloss.NewStyle().MarginBottom(1)
+		InputStyle = lipgloss.NewStyle()
+		InputFocusedStyle = lipgloss.NewStyle()
+		ListItemSelectedStyle = lipgloss.NewStyle().PaddingLeft(2)
+	}
 }
 
 // ReloadTheme reloads the theme from config
 func ReloadTheme() {
-	ApplyTheme(config.CurrentTheme())
+	cfg, _ := config.Load()
+	ApplyTheme(themeFor(cfg.Theme))
+}
+
+// ApplySettings puts the look and feel in cfg into effect: accessible
+// mode, emoji, animations and the theme. The package starts out with the
+// default profile's, so switching profiles needs this.
+func ApplySettings(cfg config.Config) {
+	SetAccessibleMode(cfg.AccessibleMode)
+	SetAnimations(!cfg.DisableAnimations)
+	SetReducedMotion(cfg.ReducedMotion)
+	SetNoEmoji(cfg.NoEmoji)
+	SetFancyAnimations(cfg.FancyAnimations)
+	ApplyTheme(themeFor(cfg.Theme))
 }
 
 // Helper functions
@@ -183,6 +234,11 @@ func RenderHighlight(text string) string {
 	return HighlightStyle.Render(text)
 }
 
+// SignedBadge marks a save with a good signature in lists of saves
+func SignedBadge() string {
+	return SuccessStyle.Render("✓ signed")
+}
+
 // Banner renders the app banner
 func Banner() string {
 	banner := `
@@ -206,38 +262,11 @@ func HelpKey(key string) string {
 }
 
 // HelpText renders the bottom help text with styled keys
-// Format: "key1: action1 • key2: action2" or use HelpBar for more control
+// Format: "key1: action1 • key2: action2", or see Keys for hints generated
+// from a screen's keys
 func HelpText(text string) string {
 	return lipgloss.NewStyle().
 		Foreground(ColorMuted).
 		MarginTop(1).
 		Render(text)
 }
-
-// HelpBar renders a cleaner help bar with styled key hints
-func HelpBar(hints [][]string) string {
-	var parts []string
-	for _, hint := range hints {
-		if len(hint) >= 2 {
-			key := HelpKey(hint[0])
-			action := MutedStyle.Render(hint[1])
-			parts = append(parts, key+" "+action)
-		}
-	}
-
-	separator := lipgloss.NewStyle().
-		Foreground(ColorMuted).
-		Render("  ·  ")
-
-	result := ""
-	for i, part := range parts {
-		if i > 0 {
-			result += separator
-		}
-		result += part
-	}
-
-	return lipgloss.NewStyle().
-		MarginTop(1).
-		Render(result)
-}
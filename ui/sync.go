@@ -1,14 +1,23 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"smooth/achievements"
+	"smooth/config"
+	"smooth/cred"
 	"smooth/git"
+	"smooth/notify"
 )
 
 // SyncState represents the state of the sync flow
@@ -20,22 +29,72 @@ const (
 	SyncStateSyncing
 	SyncStateSuccess
 	SyncStateError
+	SyncStateRejected
+	SyncStateConfirmForce
+	SyncStateAuth
+	SyncStateToken
+	SyncStateStoringToken
 )
 
 // SyncModel is the model for the sync flow
 type SyncModel struct {
-	spinner   spinner.Model
-	textInput textinput.Model
-	state     SyncState
-	err       error
-	branch    string
+	spinner     spinner.Model
+	textInput   textinput.Model
+	state       SyncState
+	err         error
+	branch      string
+	celebration celebration // achievements unlocked by this sync
+	cheer       cheer       // the mascot's cheer if this was the first sync
+	frame       int         // advances with the spinner to animate the progress bar
+	showForce   bool        // whether force uploading is offered, from the safety level
+	progress    git.Progress
+	updates     chan tea.Msg // progress and the result of a running push
+	ctx         context.Context
+	cancel      context.CancelFunc // stops the running push, nil when there isn't one
+	stopping    bool               // esc was pressed and the push is being stopped
+	tokenInput  textinput.Model
+	remote      cred.Remote // the HTTPS remote that turned down the login
+	https       bool        // false when the remote is SSH, which needs a key instead
+	helper      cred.Helper // where a pasted token is kept
+	notice      string      // why the last token didn't work
+	remoteURL   string      // where the saves went, shown once synced
+	everything  bool        // uploading every branch and tag, not just this branch
+	refs        []git.SyncRef
+	refDone     int            // how many of refs have finished uploading
+	failed      []refFailure   // refs that didn't upload
+	remotes     []remoteResult // remotes besides origin to upload to once origin has the saves
+	remoteDone  int            // how many of remotes have finished uploading
+	toRemotes   bool           // origin is done and the other remotes are uploading
+	ci          CIStatusMsg    // how GitHub's checks on the upload are going
+	wantBack    bool
 }
 
-// NewSyncModel creates a new sync model
+// refFailure is a branch or tag that didn't upload when syncing everything
+type refFailure struct {
+	ref git.SyncRef
+	err error
+}
+
+// remoteResult is how uploading to a remote besides origin went
+type remoteResult struct {
+	remote git.Remote
+	err    error
+}
+
+// NewSyncModel creates a new sync model, which syncs everything if that's
+// the default in Settings
 func NewSyncModel() SyncModel {
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(ColorAccent)
+	return newSyncModel(false)
+}
+
+// NewSyncAllModel creates a sync model that uploads every branch,
+// experiment, backup and tag
+func NewSyncAllModel() SyncModel {
+	return newSyncModel(true)
+}
+
+func newSyncModel(everything bool) SyncModel {
+	s := newSpinner(SpinnerDots)
 
 	ti := textinput.New()
 	ti.Placeholder = "git@github.com:username/repo.git"
@@ -44,29 +103,104 @@ func NewSyncModel() SyncModel {
 	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
 	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
 
+	token := textinput.New()
+	token.Placeholder = "ghp_..."
+	token.CharLimit = 255
+	token.Width = 50
+	token.EchoMode = textinput.EchoPassword
+	token.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	token.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
 	branch, _ := git.CurrentBranch()
+	cfg, _ := config.Load()
 
 	// Check if remote exists
 	state := SyncStateChecking
 	if !git.HasRemote() {
 		state = SyncStateNoRemote
+		if root, err := git.Run("rev-parse", "--show-toplevel"); err == nil {
+			ti.SetValue(cfg.RemoteFor(filepath.Base(root)))
+		}
 		ti.Focus()
 	}
 
-	return SyncModel{
-		spinner:   s,
-		textInput: ti,
-		state:     state,
-		branch:    branch,
+	everything = everything || cfg.SyncEverything
+	var refs []git.SyncRef
+	var err error
+	if everything {
+		if refs, err = git.ListSyncRefs(); err != nil {
+			state = SyncStateError
+		}
+	}
+
+	m := SyncModel{
+		spinner:    s,
+		textInput:  ti,
+		state:      state,
+		branch:     branch,
+		showForce:  cfg.ShowForce(),
+		updates:    make(chan tea.Msg, 1),
+		tokenInput: token,
+		everything: everything,
+		refs:       refs,
+		err:        err,
 	}
+	for _, r := range git.SyncRemotes() {
+		m.remotes = append(m.remotes, remoteResult{remote: r})
+	}
+	if state == SyncStateChecking {
+		m.beginSync()
+	}
+	return m
+}
+
+// networkContext returns a context for talking to GitHub that gives up
+// after the network timeout in Settings
+func networkContext() (context.Context, context.CancelFunc) {
+	cfg, _ := config.Load()
+	return context.WithTimeout(context.Background(), cfg.NetworkTimeout())
+}
+
+// beginSync sets up the context for a push, which esc can cancel
+func (m *SyncModel) beginSync() {
+	m.ctx, m.cancel = networkContext()
+	m.stopping = false
+}
+
+// endSync releases the context of a push that finished
+func (m *SyncModel) endSync() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.stopping = false
 }
 
 // Init initializes the sync model
 func (m SyncModel) Init() tea.Cmd {
-	if m.state == SyncStateNoRemote {
+	switch m.state {
+	case SyncStateNoRemote:
 		return textinput.Blink
+	case SyncStateError:
+		return nil
 	}
-	return tea.Batch(m.spinner.Tick, doSync())
+	return tea.Batch(spinnerTick(m.spinner), m.push())
+}
+
+// push starts uploading this branch, or everything, to origin. The other
+// remotes follow once origin has the saves.
+func (m *SyncModel) push() tea.Cmd {
+	m.toRemotes = false
+	m.remoteDone = 0
+	for i := range m.remotes {
+		m.remotes[i].err = nil
+	}
+	if m.everything {
+		m.refDone = 0
+		m.failed = nil
+		return startPushAll(m.ctx, m.refs, m.updates)
+	}
+	return doSync(m.ctx, m.updates)
 }
 
 // SyncMsg is sent when a sync operation completes
@@ -79,11 +213,150 @@ type AddRemoteMsg struct {
 	Err error
 }
 
-// doSync performs the actual git push
-func doSync() tea.Cmd {
+// syncProgressMsg is sent as git reports how far a push has got
+type syncProgressMsg git.Progress
+
+// syncRefMsg is sent as each branch or tag finishes uploading when syncing
+// everything
+type syncRefMsg struct {
+	Err error
+}
+
+// syncRemoteMsg is sent as each remote besides origin finishes uploading
+type syncRemoteMsg struct {
+	Err error
+}
+
+// doSync performs the actual git push, sending progress and the result to
+// updates
+func doSync(ctx context.Context, updates chan tea.Msg) tea.Cmd {
+	return startPush(ctx, git.PushWithProgress, updates)
+}
+
+// doForceSync replaces the saves on GitHub with the ones here
+func doForceSync(ctx context.Context, updates chan tea.Msg) tea.Cmd {
+	return startPush(ctx, git.ForcePushWithProgress, updates)
+}
+
+// startPush runs push in the background, sending progress and the result
+// to updates
+func startPush(ctx context.Context, push func(context.Context, func(git.Progress)) error, updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		go func() {
+			err := push(ctx, func(p git.Progress) {
+				// Drop updates the screen hasn't caught up with
+				select {
+				case updates <- syncProgressMsg(p):
+				default:
+				}
+			})
+			// Stopping it on purpose isn't worth a notification
+			if err != nil && !errors.Is(err, context.Canceled) {
+				notify.SyncFailedHook(err)
+			}
+			updates <- SyncMsg{Err: err}
+		}()
+		return waitForPush(updates)()
+	}
+}
+
+// startPushAll uploads each of refs in turn in the background, sending
+// progress, how each went and then the result to updates. Refs that fail
+// are sent on and the rest still upload, unless the push is stopped or the
+// login is turned down, which would stop every one of them.
+func startPushAll(ctx context.Context, refs []git.SyncRef, updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		go func() {
+			var err error
+			notified := false
+			for _, ref := range refs {
+				refErr := git.PushRefWithProgress(ctx, "origin", ref, func(p git.Progress) {
+					select {
+					case updates <- syncProgressMsg(p):
+					default:
+					}
+				})
+				var auth *git.AuthError
+				if ctx.Err() != nil || errors.As(refErr, &auth) {
+					err = refErr
+					break
+				}
+				// One notification is enough however many fail
+				if refErr != nil && !notified {
+					notify.SyncFailedHook(refErr)
+					notified = true
+				}
+				updates <- syncRefMsg{Err: refErr}
+			}
+			if err != nil && !errors.Is(err, context.Canceled) && !notified {
+				notify.SyncFailedHook(err)
+			}
+			updates <- SyncMsg{Err: err}
+		}()
+		return waitForPush(updates)()
+	}
+}
+
+// startPushRemotes uploads to each of remotes in turn in the background,
+// every one of refs or just the current branch if there are none, sending
+// progress, how each remote went and then the result to updates. A remote
+// that fails doesn't stop the rest; stopping the push does.
+func startPushRemotes(ctx context.Context, remotes []remoteResult, refs []git.SyncRef, updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		go func() {
+			progress := func(p git.Progress) {
+				select {
+				case updates <- syncProgressMsg(p):
+				default:
+				}
+			}
+			var stopped error
+			notified := false
+			for _, r := range remotes {
+				var err error
+				if len(refs) == 0 {
+					err = git.PushToWithProgress(ctx, r.remote.Name, progress)
+				}
+				for _, ref := range refs {
+					if refErr := git.PushRefWithProgress(ctx, r.remote.Name, ref, progress); refErr != nil && err == nil {
+						err = refErr
+					}
+					if ctx.Err() != nil {
+						break
+					}
+				}
+				if ctx.Err() != nil {
+					stopped = err
+					break
+				}
+				if err != nil && !notified {
+					notify.SyncFailedHook(err)
+					notified = true
+				}
+				updates <- syncRemoteMsg{Err: err}
+			}
+			updates <- SyncMsg{Err: stopped}
+		}()
+		return waitForPush(updates)()
+	}
+}
+
+// waitForPush waits for the next update from a running push
+func waitForPush(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// TokenStoredMsg is sent when a pasted token has been checked and stored
+type TokenStoredMsg struct {
+	Err error
+}
+
+// doStoreToken stores a token for the remote where git will find it
+func doStoreToken(remote cred.Remote, token string, helper cred.Helper) tea.Cmd {
 	return func() tea.Msg {
-		err := git.Push()
-		return SyncMsg{Err: err}
+		return TokenStoredMsg{Err: cred.StoreToken(remote, token, helper)}
 	}
 }
 
@@ -105,34 +378,175 @@ func (m SyncModel) Update(msg tea.Msg) (SyncModel, tea.Cmd) {
 		} else {
 			// Remote added, now sync
 			m.state = SyncStateSyncing
-			return m, tea.Batch(m.spinner.Tick, doSync())
+			m.beginSync()
+			return m, tea.Batch(spinnerTick(m.spinner), m.push())
 		}
 		return m, nil
 
-	case SyncMsg:
+	case syncProgressMsg:
+		m.progress = git.Progress(msg)
+		return m, waitForPush(m.updates)
+
+	case syncRemoteMsg:
+		m.remotes[m.remoteDone].err = msg.Err
+		m.remoteDone++
+		m.progress = git.Progress{}
+		return m, waitForPush(m.updates)
+
+	case syncRefMsg:
 		if msg.Err != nil {
+			m.failed = append(m.failed, refFailure{ref: m.refs[m.refDone], err: msg.Err})
+		}
+		m.refDone++
+		m.progress = git.Progress{}
+		return m, waitForPush(m.updates)
+
+	case SyncMsg:
+		m.progress = git.Progress{}
+		m.endSync()
+		if m.toRemotes {
+			// Stopping leaves the remotes that hadn't finished without the saves
+			for i := m.remoteDone; i < len(m.remotes) && msg.Err != nil; i++ {
+				m.remotes[i].err = msg.Err
+			}
+			m.remoteDone = len(m.remotes)
+			return m.synced()
+		}
+		var push *git.PushError
+		var auth *git.AuthError
+		if errors.As(msg.Err, &push) && push.Rejected() {
+			m.state = SyncStateRejected
+			m.err = msg.Err
+		} else if errors.As(msg.Err, &auth) {
+			m.state = SyncStateAuth
+			m.err = msg.Err
+			m.remote, m.https = cred.ParseRemote(git.GetRemoteURL())
+			var keychain bool
+			if m.helper, keychain = cred.KeychainHelper(); !keychain {
+				m.helper = cred.PlainTextHelper
+			}
+		} else if msg.Err != nil {
 			m.state = SyncStateError
 			m.err = msg.Err
+		} else if len(m.failed) > 0 && len(m.failed) == len(m.refs) {
+			m.state = SyncStateError
+			m.err = m.failed[0].err
+		} else if len(m.remotes) > 0 {
+			// origin has the saves, so on to the other remotes
+			m.toRemotes = true
+			m.beginSync()
+			var refs []git.SyncRef
+			if m.everything {
+				refs = m.refs
+			}
+			return m, startPushRemotes(m.ctx, m.remotes, refs, m.updates)
 		} else {
-			m.state = SyncStateSuccess
+			return m.synced()
+		}
+		return m, nil
+
+	case TokenStoredMsg:
+		if msg.Err != nil {
+			m.state = SyncStateToken
+			m.notice = msg.Err.Error()
+			m.tokenInput.Focus()
+			return m, textinput.Blink
+		}
+		// Try again with the token
+		m.tokenInput.SetValue("")
+		m.state = SyncStateSyncing
+		m.beginSync()
+		return m, tea.Batch(spinnerTick(m.spinner), m.push())
+
+	case CIStatusMsg:
+		// The menu asks again while the checks run; this shows them
+		if m.state == SyncStateSuccess {
+			m.ci = msg
 		}
 		return m, nil
 
+	case celebrationTickMsg:
+		var cmd tea.Cmd
+		m.celebration, cmd = m.celebration.Update(msg)
+		return m, cmd
+
 	case spinner.TickMsg:
-		if m.state == SyncStateSyncing || m.state == SyncStateChecking {
+		if m.state == SyncStateSyncing || m.state == SyncStateChecking || m.state == SyncStateStoringToken {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
+			m.frame++
 			return m, cmd
 		}
 
 	case tea.KeyMsg:
+		switch m.state {
+		case SyncStateSuccess:
+			// Any key but c, which copies the address, goes back
+			if msg.String() == "c" && m.remoteURL != "" {
+				return m, copyToClipboard(m.remoteURL, "the address")
+			}
+			m.wantBack = true
+			return m, nil
+		case SyncStateChecking, SyncStateSyncing:
+			if msg.String() == "esc" && m.cancel != nil {
+				m.cancel()
+				m.stopping = true
+			}
+			return m, nil
+		case SyncStateRejected:
+			if msg.String() == "f" && m.showForce {
+				m.state = SyncStateConfirmForce
+			}
+			return m, nil
+		case SyncStateAuth:
+			// Without a keychain, p keeps the token in plain text, so it's
+			// never stored that way without asking
+			if m.https && (msg.String() == "t" && m.helper.Secure || msg.String() == "p" && !m.helper.Secure) {
+				m.state = SyncStateToken
+				m.notice = ""
+				m.tokenInput.Focus()
+				return m, textinput.Blink
+			}
+			return m, nil
+		case SyncStateToken:
+			switch msg.String() {
+			case "enter":
+				token := strings.TrimSpace(m.tokenInput.Value())
+				if token == "" {
+					return m, nil
+				}
+				m.tokenInput.Blur()
+				m.state = SyncStateStoringToken
+				return m, tea.Batch(spinnerTick(m.spinner), doStoreToken(m.remote, token, m.helper))
+			case "esc":
+				m.tokenInput.Blur()
+				m.tokenInput.SetValue("")
+				m.state = SyncStateAuth
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.tokenInput, cmd = m.tokenInput.Update(msg)
+			return m, cmd
+		case SyncStateConfirmForce:
+			// Overwriting GitHub can't be undone from here, so even experts
+			// are asked once
+			switch msg.String() {
+			case "y", "Y":
+				m.state = SyncStateSyncing
+				m.beginSync()
+				return m, tea.Batch(spinnerTick(m.spinner), doForceSync(m.ctx, m.updates))
+			case "n", "N", "esc":
+				m.state = SyncStateRejected
+			}
+			return m, nil
+		}
 		if m.state == SyncStateNoRemote {
 			switch msg.String() {
 			case "enter":
 				url := strings.TrimSpace(m.textInput.Value())
 				if url != "" {
 					m.state = SyncStateSyncing
-					return m, tea.Batch(m.spinner.Tick, doAddRemote(url))
+					return m, tea.Batch(spinnerTick(m.spinner), doAddRemote(url))
 				}
 			default:
 				var cmd tea.Cmd
@@ -154,6 +568,7 @@ func (m SyncModel) View() string {
 	switch m.state {
 	case SyncStateChecking:
 		s += m.spinner.View() + " " + RenderHighlight("Checking...") + "\n"
+		s += "\n" + m.renderStopHelp()
 
 	case SyncStateNoRemote:
 		s += RenderSubtitle("No GitHub remote configured") + "\n\n"
@@ -164,32 +579,362 @@ func (m SyncModel) View() string {
 		s += RenderMuted("  2. Click the green 'Code' button") + "\n"
 		s += RenderMuted("  3. Select 'SSH' and copy the URL") + "\n"
 		s += RenderMuted("     (looks like git@github.com:user/repo.git)") + "\n\n"
-		s += HelpBar([][]string{{"enter", "save and sync"}, {"esc", "cancel"}})
+		s += HelpBar(m.Keys())
 
 	case SyncStateSyncing:
+		if m.toRemotes {
+			s += m.spinner.View() + " " + RenderHighlight("Syncing to your other remotes...") + "\n\n"
+			s += m.renderRemoteList() + "\n" + m.renderStopHelp()
+			break
+		}
+		if m.everything {
+			s += m.renderSyncingEverything()
+			break
+		}
 		s += m.spinner.View() + " " + RenderHighlight("Syncing...") + "\n\n"
-		s += RenderMuted("Uploading your saves to GitHub...") + "\n"
+		if m.progress.Phase == "" {
+			// git hasn't said how far it's got yet
+			s += RenderShimmerBar(m.frame, 40) + "\n\n"
+			s += RenderMuted("Uploading your saves to GitHub...") + "\n"
+			s += m.renderRemoteList()
+			s += "\n" + m.renderStopHelp()
+			break
+		}
+		s += RenderProgressBar(float64(m.progress.Percent)/100, 40) + " " +
+			MutedStyle.Render(fmt.Sprintf("%s %d%%", m.progress.Phase, m.progress.Percent)) + "\n"
+		if details := m.progress.Details(); details != "" {
+			s += RenderMuted(details) + "\n"
+		}
+		s += m.renderRemoteList()
+		s += "\n" + m.renderStopHelp()
 
 	case SyncStateSuccess:
-		s += RenderSuccess("✓ Synced!") + "\n\n"
-		s += RenderMuted("Your work is now on GitHub.") + "\n\n"
-		s += HelpText("Press any key to continue")
+		switch {
+		case len(m.failed) > 0:
+			s += RenderError(fmt.Sprintf("⚠ Synced %d of %d branches and tags", len(m.refs)-len(m.failed), len(m.refs))) + "\n\n"
+			s += renderRefFailures(m.failed)
+			s += RenderMuted("The rest of your work is now on GitHub.") + "\n"
+		case m.everything:
+			s += RenderSuccess("✓ Synced everything!") + "\n\n"
+			s += RenderMuted(fmt.Sprintf("Your work is now on GitHub: %s.", describeSyncRefs(m.refs))) + "\n"
+		default:
+			s += RenderSuccess("✓ Synced!") + "\n\n"
+			s += RenderMuted("Your work is now on GitHub.") + "\n"
+		}
+		if m.remoteURL != "" {
+			s += MutedStyle.Render(m.remoteURL) + "\n"
+		}
+		if len(m.remotes) > 0 {
+			s += "\n" + m.renderRemoteList()
+		}
+		if m.ci.shown() {
+			s += "\n" + renderCI(m.ci.Status)
+		}
+		s += "\n"
+		if cheer := m.cheer.View(); cheer != "" {
+			s += cheer + "\n\n"
+		}
+		if celebration := m.celebration.View(); celebration != "" {
+			s += celebration + "\n\n"
+		}
+		if m.remoteURL != "" {
+			s += HelpText("Press c to copy the address, or any other key to continue")
+		} else {
+			s += HelpText("Press any key to continue")
+		}
 
 	case SyncStateError:
-		s += RenderError("✗ Sync failed") + "\n\n"
-		if m.err != nil {
-			s += RenderMuted(m.err.Error()) + "\n\n"
-		}
-		if git.HasRemote() {
-			s += RenderMuted("Make sure you have an internet connection.") + "\n\n"
+		var timeout *git.TimeoutError
+		switch {
+		case errors.Is(m.err, context.Canceled):
+			s += RenderError("✗ Sync stopped") + "\n\n"
+			s += RenderMuted("GitHub only takes the saves once they've all arrived, so") + "\n"
+			s += RenderMuted("nothing changed there. Sync again when you're ready.") + "\n\n"
+		case errors.As(m.err, &timeout):
+			s += RenderError("✗ Sync timed out") + "\n\n"
+			s += renderErrorDetails(m.err)
+		default:
+			s += RenderError("✗ Sync failed") + "\n\n"
+			if m.err != nil {
+				s += renderErrorDetails(m.err)
+			}
+			if git.HasRemote() {
+				s += RenderMuted("Make sure you have an internet connection.") + "\n\n"
+			}
 		}
 		s += HelpText("Press any key to go back")
+
+	case SyncStateRejected:
+		s += RenderError("✗ GitHub has saves that aren't on this computer") + "\n\n"
+		s += RenderMuted("Someone (maybe you, somewhere else) synced since you last did,") + "\n"
+		s += RenderMuted("so uploading now would replace their saves. Nothing was changed.") + "\n\n"
+		if m.showForce {
+			s += HelpBar(m.Keys())
+		} else {
+			s += HelpText("Press any key to go back")
+		}
+
+	case SyncStateAuth:
+		s += m.renderAuthHelp()
+
+	case SyncStateToken:
+		s += RenderSubtitle("Paste your access token:") + "\n\n"
+		s += m.tokenInput.View() + "\n\n"
+		if m.notice != "" {
+			s += RenderError("✗ "+m.notice) + "\n\n"
+		}
+		s += RenderMuted("It's kept in "+m.helper.Where+", not by smooth.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case SyncStateStoringToken:
+		s += m.spinner.View() + " " + RenderHighlight("Checking the token and storing it...") + "\n"
+
+	case SyncStateConfirmForce:
+		s += RenderError("⚠ Replace the saves on GitHub with yours?") + "\n\n"
+		s += RenderMuted("Saves on GitHub that aren't here will be gone from "+m.branch+".") + "\n"
+		s += RenderMuted("This stops if GitHub changed again since smooth last checked.") + "\n\n"
+		s += RenderSubtitle("Force upload? (y/n)") + "\n"
 	}
 
 	return BoxStyle.Render(s)
 }
 
+// synced shows that the saves are on origin, and celebrates
+func (m SyncModel) synced() (SyncModel, tea.Cmd) {
+	m.state = SyncStateSuccess
+	m.remoteURL = git.GetRemoteURL()
+	unlocked := achievements.RecordSync()
+	var celebrate tea.Cmd
+	m.celebration, celebrate = newCelebration(unlocked)
+	m.cheer = newCheer(unlocked, false)
+	return m, tea.Batch(celebrate, watchCI())
+}
+
+// renderSyncingEverything shows which branch or tag is uploading, how far
+// through them it is and any that failed so far
+func (m SyncModel) renderSyncingEverything() string {
+	var s string
+	s += m.spinner.View() + " " + RenderHighlight("Syncing everything...") + " " +
+		MutedStyle.Render(fmt.Sprintf("%d of %d", min(m.refDone+1, len(m.refs)), len(m.refs))) + "\n\n"
+	if m.refDone < len(m.refs) {
+		ref := m.refs[m.refDone]
+		if ref.Tag {
+			s += RenderMuted("Uploading tag "+ref.Name) + "\n"
+		} else {
+			s += RenderMuted("Uploading "+ref.Name) + "\n"
+		}
+	}
+	if m.progress.Phase == "" {
+		s += RenderShimmerBar(m.frame, 40) + "\n"
+	} else {
+		s += RenderProgressBar(float64(m.progress.Percent)/100, 40) + " " +
+			MutedStyle.Render(fmt.Sprintf("%s %d%%", m.progress.Phase, m.progress.Percent)) + "\n"
+	}
+	s += "\n"
+	if len(m.failed) > 0 {
+		s += renderRefFailures(m.failed)
+	}
+	if list := m.renderRemoteList(); list != "" {
+		s += list + "\n"
+	}
+	return s + m.renderStopHelp()
+}
+
+// renderRemoteList shows how uploading to origin and each other remote is
+// going, or went. It's empty when origin is the only remote synced.
+func (m SyncModel) renderRemoteList() string {
+	if len(m.remotes) == 0 {
+		return ""
+	}
+	line := func(icon, name, note string) string {
+		return "  " + lipgloss.NewStyle().Width(2).Render(icon) + lipgloss.NewStyle().Width(14).Render(truncateLine(name, 14)) + " " + MutedStyle.Render(truncateLine(note, 60)) + "\n"
+	}
+	uploading := "uploading"
+	if m.progress.Phase != "" {
+		uploading = fmt.Sprintf("%s %d%%", m.progress.Phase, m.progress.Percent)
+	}
+	syncing := m.state == SyncStateSyncing
+
+	var s string
+	if syncing && !m.toRemotes {
+		s += line(m.spinner.View(), "origin", uploading)
+	} else {
+		s += line(SuccessStyle.Render("✓"), "origin", "")
+	}
+	for i, r := range m.remotes {
+		switch {
+		case i < m.remoteDone && r.err != nil:
+			s += line(ErrorStyle.Render("✗"), r.remote.Name, pushFailureReason(r.err))
+		case i < m.remoteDone:
+			s += line(SuccessStyle.Render("✓"), r.remote.Name, "")
+		case syncing && m.toRemotes && i == m.remoteDone:
+			s += line(m.spinner.View(), r.remote.Name, uploading)
+		default:
+			s += line(MutedStyle.Render("·"), r.remote.Name, "waiting")
+		}
+	}
+	return s
+}
+
+// renderRefFailures lists the branches and tags that didn't upload, and
+// why
+func renderRefFailures(failed []refFailure) string {
+	const shown = 8
+	var s string
+	for i, f := range failed {
+		if i == shown {
+			s += RenderMuted(fmt.Sprintf("  …and %d more", len(failed)-shown)) + "\n"
+			break
+		}
+		name := f.ref.Name
+		if f.ref.Tag {
+			name = "tag " + name
+		}
+		s += RenderError("  ✗ "+name) + " " + MutedStyle.Render(pushFailureReason(f.err)) + "\n"
+	}
+	return s + "\n"
+}
+
+// pushFailureReason picks the line of a failed push that says what went
+// wrong
+func pushFailureReason(err error) string {
+	var push *git.PushError
+	if errors.As(err, &push) && push.Rejected() {
+		return "GitHub has saves that aren't here"
+	}
+	lines := strings.Split(err.Error(), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"! ", "error: ", "fatal: "} {
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimPrefix(line, prefix)
+			}
+		}
+	}
+	return lines[0]
+}
+
+// describeSyncRefs counts the branches and tags, like "3 branches and 1 tag"
+func describeSyncRefs(refs []git.SyncRef) string {
+	var branches, tags int
+	for _, ref := range refs {
+		if ref.Tag {
+			tags++
+		} else {
+			branches++
+		}
+	}
+	s := fmt.Sprintf("%d branches", branches)
+	if branches == 1 {
+		s = "1 branch"
+	}
+	if tags > 0 {
+		s += " and " + plural(tags, "tag")
+	}
+	return s
+}
+
+// renderAuthHelp explains why the login was turned down and how to fix it:
+// a token for HTTPS remotes, a key for SSH ones
+func (m SyncModel) renderAuthHelp() string {
+	var s string
+	if !m.https {
+		s += RenderError("✗ GitHub didn't accept your SSH key") + "\n\n"
+		s += RenderMuted("This project syncs over SSH, which logs in with a key on this computer.") + "\n\n"
+		s += RenderSubtitle("To fix it:") + "\n"
+		s += RenderMuted("  1. Check for a key: ls ~/.ssh/id_ed25519.pub") + "\n"
+		s += RenderMuted("     No key? Make one: ssh-keygen -t ed25519") + "\n"
+		s += RenderMuted("  2. Add the .pub file's contents at github.com/settings/keys") + "\n"
+		s += RenderMuted("  3. Test it: ssh -T git@github.com") + "\n\n"
+		s += HelpText("Press any key to go back")
+		return s
+	}
+
+	s += RenderError("✗ "+m.remote.Host+" didn't accept your login") + "\n\n"
+	s += RenderMuted("Syncing over HTTPS needs a personal access token, a password made") + "\n"
+	s += RenderMuted("just for git. Account passwords aren't accepted anymore.") + "\n\n"
+	s += RenderSubtitle("To make one:") + "\n"
+	if m.remote.Host == "github.com" {
+		s += RenderMuted("  1. Open github.com/settings/tokens") + "\n"
+		s += RenderMuted("  2. Generate a new token (classic) with the repo scope") + "\n"
+	} else {
+		s += RenderMuted("  1. Open your account settings on "+m.remote.Host) + "\n"
+		s += RenderMuted("  2. Create an access token that can write to repositories") + "\n"
+	}
+	if !m.helper.Secure {
+		s += RenderMuted("  3. Copy it") + "\n\n"
+		s += RenderError("⚠ No keychain was found to keep the token in.") + "\n"
+		s += RenderMuted("Install one, like libsecret, and sync again. Or press p to keep it in") + "\n"
+		s += RenderMuted(m.helper.Where+" as plain text, where anyone who can read your files") + "\n"
+		s += RenderMuted("can read it.") + "\n\n"
+		s += HelpBar(m.Keys())
+		return s
+	}
+	s += RenderMuted("  3. Copy it, then press t to paste it here") + "\n\n"
+	s += RenderMuted("smooth stores it in "+m.helper.Where+" and sets up git to use it") + "\n"
+	s += RenderMuted("for "+m.remote.Host+", so you won't be asked again.") + "\n\n"
+	s += HelpBar(m.Keys())
+	return s
+}
+
+// renderStopHelp shows how to stop a push that's taking too long
+func (m SyncModel) renderStopHelp() string {
+	if m.stopping {
+		return RenderMuted("Stopping...") + "\n"
+	}
+	if m.cancel == nil {
+		return ""
+	}
+	return HelpBar(m.Keys())
+}
+
+// Keys returns the keys the sync flow takes in its current state
+func (m SyncModel) Keys() Keys {
+	switch m.state {
+	case SyncStateChecking, SyncStateSyncing:
+		if m.cancel != nil && !m.stopping {
+			return Keys{Bar: []key.Binding{hint("esc", "stop")}}
+		}
+	case SyncStateNoRemote:
+		return Keys{Bar: []key.Binding{hint("enter", "save and sync"), hint("esc", "cancel")}, Typing: true}
+	case SyncStateSuccess:
+		if m.remoteURL != "" {
+			return Keys{More: []key.Binding{hint("c", "copy the address")}}
+		}
+	case SyncStateRejected:
+		if m.showForce {
+			return Keys{Bar: []key.Binding{hint("f", "force upload"), hint("esc", "back")}}
+		}
+	case SyncStateAuth:
+		if m.https && !m.helper.Secure {
+			return Keys{Bar: []key.Binding{hint("p", "paste token, kept in plain text"), hint("esc", "back")}}
+		}
+		if m.https {
+			return Keys{Bar: []key.Binding{hint("t", "paste token"), hint("esc", "back")}}
+		}
+	case SyncStateToken:
+		return Keys{Bar: []key.Binding{hint("enter", "store and sync"), hint("esc", "back")}, Typing: true}
+	case SyncStateConfirmForce:
+		return Keys{More: []key.Binding{hint("y", "force upload"), hint("n", "no, back")}}
+	}
+	return Keys{}
+}
+
 // IsDone returns true if the sync flow is complete
 func (m SyncModel) IsDone() bool {
-	return m.state == SyncStateSuccess || m.state == SyncStateError
+	return m.state == SyncStateError ||
+		(m.state == SyncStateRejected && !m.showForce) ||
+		(m.state == SyncStateAuth && !m.https)
+}
+
+// WantsBack returns true once the user is done with a successful sync
+func (m SyncModel) WantsBack() bool {
+	return m.wantBack
+}
+
+// HandlesEsc returns true while esc should cancel a force upload, stop the
+// push or leave the token prompt rather than leave the screen
+func (m SyncModel) HandlesEsc() bool {
+	return m.state == SyncStateConfirmForce || m.state == SyncStateChecking || m.state == SyncStateSyncing ||
+		m.state == SyncStateToken
 }
@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// TasksState represents the state of the tasks screen
+type TasksState int
+
+const (
+	TasksStateList TasksState = iota
+	TasksStateAdding
+	TasksStateConfirmDelete
+	TasksStateError
+)
+
+// TasksModel is the model for the project's to-do list
+type TasksModel struct {
+	tasks     []git.Task // open ones first, then done
+	cursor    int
+	state     TasksState
+	textInput textinput.Model
+	err       error
+	message   string
+	height    int
+}
+
+// NewTasksModel creates a new tasks model
+func NewTasksModel() TasksModel {
+	ti := textinput.New()
+	ti.Placeholder = "What needs doing?"
+	ti.CharLimit = 100
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m := TasksModel{textInput: ti}
+	m.reload()
+	return m
+}
+
+// reload re-reads the task list, keeping the cursor in range
+func (m *TasksModel) reload() {
+	tasks, err := git.LoadTasks()
+	if err != nil {
+		m.state = TasksStateError
+		m.err = err
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return !tasks[i].Done && tasks[j].Done
+	})
+	m.tasks = tasks
+	if m.cursor >= len(m.tasks) {
+		m.cursor = max(0, len(m.tasks)-1)
+	}
+}
+
+// Init initializes the model
+func (m TasksModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m TasksModel) Update(msg tea.Msg) (TasksModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.state {
+		case TasksStateList:
+			m.message = ""
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(m.tasks)-1 {
+					m.cursor++
+				}
+			case msg.String() == "n" || msg.String() == "a":
+				m.state = TasksStateAdding
+				m.textInput.SetValue("")
+				m.textInput.Focus()
+				return m, textinput.Blink
+			case msg.String() == " " || key.Matches(msg, keys.Enter):
+				if len(m.tasks) == 0 {
+					return m, nil
+				}
+				task := m.tasks[m.cursor]
+				if err := git.SetTaskDone(task.ID, !task.Done); err != nil {
+					m.state = TasksStateError
+					m.err = err
+					return m, nil
+				}
+				m.reload()
+				if !task.Done {
+					m.message = fmt.Sprintf("Done: %s", task.Title)
+				}
+			case msg.String() == "d" || msg.String() == "delete" || msg.String() == "backspace":
+				if len(m.tasks) > 0 {
+					m.state = TasksStateConfirmDelete
+				}
+			}
+
+		case TasksStateAdding:
+			switch msg.String() {
+			case "enter":
+				task, err := git.AddTask(m.textInput.Value())
+				if err != nil {
+					return m, nil
+				}
+				m.textInput.Blur()
+				m.state = TasksStateList
+				m.reload()
+				m.message = fmt.Sprintf("Added task #%d", task.ID)
+			case "esc":
+				m.textInput.Blur()
+				m.state = TasksStateList
+			default:
+				var cmd tea.Cmd
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
+		case TasksStateConfirmDelete:
+			switch msg.String() {
+			case "y", "Y":
+				task := m.tasks[m.cursor]
+				if err := git.DeleteTask(task.ID); err != nil {
+					m.state = TasksStateError
+					m.err = err
+					return m, nil
+				}
+				m.state = TasksStateList
+				m.reload()
+				m.message = fmt.Sprintf("Deleted: %s", task.Title)
+			case "n", "N", "esc":
+				m.state = TasksStateList
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the tasks screen
+func (m TasksModel) View() string {
+	var s string
+
+	s += RenderTitle("Tasks") + "\n\n"
+
+	switch m.state {
+	case TasksStateError:
+		s += RenderError("✗ Couldn't update the task list") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+
+	case TasksStateAdding:
+		s += RenderSubtitle("New task:") + "\n\n"
+		s += m.textInput.View() + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case TasksStateConfirmDelete:
+		task := m.tasks[m.cursor]
+		s += "Delete task: " + HighlightStyle.Render(task.Title) + "\n\n"
+		if len(task.Saves) > 0 {
+			s += RenderMuted("The saves linked to it keep mentioning "+task.Reference()+".") + "\n\n"
+		}
+		s += RenderSubtitle("Are you sure? (y/n)") + "\n"
+
+	case TasksStateList:
+		if len(m.tasks) == 0 {
+			s += RenderMuted("No tasks yet. Add one, then link saves to it with") + "\n"
+			s += RenderMuted("ctrl+t on the Save screen.") + "\n\n"
+		} else {
+			s += m.renderTaskList() + "\n"
+		}
+
+		if m.message != "" {
+			s += RenderSuccess("✓ "+m.message) + "\n\n"
+		}
+
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderTaskList renders the tasks, scrolled so the cursor stays visible
+func (m TasksModel) renderTaskList() string {
+	maxVisible := 15
+	if m.height > 0 {
+		maxVisible = max(3, m.height-12)
+	}
+	start := 0
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+
+	var s string
+	for i := start; i < len(m.tasks) && i < start+maxVisible; i++ {
+		task := m.tasks[i]
+		cursor := "  "
+		style := ListItemStyle
+		if i == m.cursor {
+			cursor = MenuCursorStyle.Render("> ")
+			style = ListItemSelectedStyle
+		}
+
+		check := "[ ]"
+		if task.Done {
+			check = "[✓]"
+			if i != m.cursor {
+				style = ListItemStyle.Foreground(ColorMuted)
+			}
+		}
+		line := cursor + style.Render(fmt.Sprintf("%s #%d %s", check, task.ID, truncateLine(task.Title, 50)))
+		if n := len(task.Saves); n > 0 {
+			line += " " + MutedStyle.Render(fmt.Sprintf("(%d save(s))", n))
+		}
+		s += line + "\n"
+	}
+	if len(m.tasks) > maxVisible {
+		s += MutedStyle.Render(fmt.Sprintf("  ... %d total tasks", len(m.tasks))) + "\n"
+	}
+	return s
+}
+
+// Keys returns the keys the tasks screen takes in its current state
+func (m TasksModel) Keys() Keys {
+	switch m.state {
+	case TasksStateList:
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("space", "done/not done"), hint("n", "new"), hint("d", "delete"), hint("esc", "back")},
+			More: []key.Binding{vimKeys, hint("enter", "done/not done"), hint("a", "new"), hint("delete", "delete")},
+		}
+	case TasksStateAdding:
+		return Keys{Bar: []key.Binding{hint("enter", "add"), hint("esc", "cancel")}, Typing: true}
+	case TasksStateConfirmDelete:
+		return Keys{More: []key.Binding{hint("y", "delete the task"), hint("n", "no, keep it")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if the screen should close on the next key
+func (m TasksModel) IsDone() bool {
+	return m.state == TasksStateError
+}
+
+// HandlesEsc returns true while esc should cancel adding or deleting a
+// task rather than leave the screen
+func (m TasksModel) HandlesEsc() bool {
+	return m.state == TasksStateAdding || m.state == TasksStateConfirmDelete
+}
@@ -0,0 +1,427 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"smooth/git"
+)
+
+// TeamState represents the state of the team sync flow
+type TeamState int
+
+const (
+	TeamStateFetching TeamState = iota
+	TeamStateNoRemote
+	TeamStateUpToDate
+	TeamStateReview
+	TeamStateIntegrating
+	TeamStateConflicts
+	TeamStateConfirmAbort
+	TeamStateSuccess
+	TeamStateError
+)
+
+// teamMaxCommits is how many teammate saves are listed before "and N more"
+const teamMaxCommits = 10
+
+// teamOption is a way of combining teammates' saves with yours
+type teamOption struct {
+	title       string
+	description string
+	method      git.IntegrateMethod
+}
+
+var teamOptions = []teamOption{
+	{
+		title:       "Put my saves on top of theirs",
+		description: "History stays in a straight line (git pull --rebase)",
+		method:      git.IntegrateRebase,
+	},
+	{
+		title:       "Combine with a merge save",
+		description: "Keeps both lines of history and joins them (git merge)",
+		method:      git.IntegrateMerge,
+	},
+}
+
+// TeamModel is the model for bringing in saves teammates uploaded
+type TeamModel struct {
+	spinner   spinner.Model
+	state     TeamState
+	status    git.TeamStatus
+	cursor    int      // selected option while reviewing, file while resolving
+	conflicts []string // files still waiting for a decision
+	resolved  []string // files decided during this stop
+	notice    string   // why the last action in the walkthrough didn't work
+	err       error
+	ctx       context.Context
+	cancel    context.CancelFunc // stops the download from GitHub
+	stopping  bool               // esc was pressed while downloading
+}
+
+// NewTeamModel creates a new team sync model. If a team sync stopped
+// partway through earlier, it picks up at the conflict walkthrough.
+func NewTeamModel() TeamModel {
+	m := TeamModel{spinner: newSpinner(SpinnerDots)}
+	if !git.HasRemote() {
+		m.state = TeamStateNoRemote
+		return m
+	}
+	if rebasing, merging := git.TeamSyncInProgress(); rebasing || merging {
+		m.state = TeamStateConflicts
+		m.conflicts, _ = git.ConflictedFiles()
+		return m
+	}
+	m.ctx, m.cancel = networkContext()
+	return m
+}
+
+// Init initializes the team sync model
+func (m TeamModel) Init() tea.Cmd {
+	if m.state != TeamStateFetching {
+		return nil
+	}
+	return tea.Batch(spinnerTick(m.spinner), doFetchTeam(m.ctx))
+}
+
+// TeamFetchedMsg is sent when teammates' saves have been downloaded
+type TeamFetchedMsg struct {
+	Status git.TeamStatus
+	Err    error
+}
+
+// TeamIntegratedMsg is sent when a rebase or merge step finishes or stops
+// for conflicts
+type TeamIntegratedMsg struct {
+	Err error
+}
+
+func doFetchTeam(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		status, err := git.FetchTeam(ctx)
+		return TeamFetchedMsg{Status: status, Err: err}
+	}
+}
+
+func doIntegrateTeam(method git.IntegrateMethod) tea.Cmd {
+	return func() tea.Msg {
+		return TeamIntegratedMsg{Err: git.IntegrateTeam(method)}
+	}
+}
+
+func doContinueTeam() tea.Cmd {
+	return func() tea.Msg {
+		return TeamIntegratedMsg{Err: git.ContinueTeamSync()}
+	}
+}
+
+// Update handles messages for the team sync model
+func (m TeamModel) Update(msg tea.Msg) (TeamModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TeamFetchedMsg:
+		if m.cancel != nil {
+			m.cancel()
+			m.cancel = nil
+		}
+		if errors.Is(msg.Err, context.Canceled) {
+			m.state = TeamStateError
+			m.err = errors.New("Stopped checking GitHub. Nothing was changed.")
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.state = TeamStateError
+			m.err = msg.Err
+			return m, nil
+		}
+		m.status = msg.Status
+		if len(m.status.Incoming) == 0 {
+			m.state = TeamStateUpToDate
+		} else {
+			m.state = TeamStateReview
+		}
+		return m, nil
+
+	case TeamIntegratedMsg:
+		var conflict *git.ConflictError
+		switch {
+		case errors.As(msg.Err, &conflict):
+			m.state = TeamStateConflicts
+			m.conflicts = conflict.Files
+			m.resolved = nil
+			m.cursor = 0
+		case msg.Err != nil:
+			m.state = TeamStateError
+			m.err = msg.Err
+		default:
+			m.state = TeamStateSuccess
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state == TeamStateFetching || m.state == TeamStateIntegrating {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case TeamStateFetching:
+			if msg.String() == "esc" && m.cancel != nil {
+				m.cancel()
+				m.stopping = true
+			}
+		case TeamStateReview:
+			return m.updateReview(msg)
+		case TeamStateConflicts:
+			return m.updateConflicts(msg)
+		case TeamStateConfirmAbort:
+			switch msg.String() {
+			case "y", "Y":
+				if err := git.AbortTeamSync(); err != nil {
+					m.state = TeamStateError
+					m.err = err
+					return m, nil
+				}
+				m.state = TeamStateError
+				m.err = errors.New("Stopped. Your branch is back the way it was before the team sync.")
+			case "n", "N", "esc":
+				m.state = TeamStateConflicts
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// updateReview handles keys while choosing how to bring teammates' saves in
+func (m TeamModel) updateReview(msg tea.KeyMsg) (TeamModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(teamOptions)-1 {
+			m.cursor++
+		}
+	case "enter":
+		m.state = TeamStateIntegrating
+		method := teamOptions[m.cursor].method
+		m.cursor = 0
+		return m, tea.Batch(spinnerTick(m.spinner), doIntegrateTeam(method))
+	}
+	return m, nil
+}
+
+// updateConflicts handles keys in the conflict walkthrough
+func (m TeamModel) updateConflicts(msg tea.KeyMsg) (TeamModel, tea.Cmd) {
+	m.notice = ""
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.conflicts)-1 {
+			m.cursor++
+		}
+	case "m", "t", "e":
+		if len(m.conflicts) == 0 {
+			return m, nil
+		}
+		path := m.conflicts[m.cursor]
+		var err error
+		switch msg.String() {
+		case "m":
+			err = git.ResolveConflict(path, true)
+		case "t":
+			err = git.ResolveConflict(path, false)
+		case "e":
+			err = git.MarkResolved(path)
+		}
+		if err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.resolved = append(m.resolved, path)
+		m.conflicts = append(m.conflicts[:m.cursor:m.cursor], m.conflicts[m.cursor+1:]...)
+		if m.cursor >= len(m.conflicts) && m.cursor > 0 {
+			m.cursor--
+		}
+	case "c", "enter":
+		if len(m.conflicts) > 0 {
+			m.notice = fmt.Sprintf("%d file(s) still need a decision", len(m.conflicts))
+			return m, nil
+		}
+		m.state = TeamStateIntegrating
+		return m, tea.Batch(spinnerTick(m.spinner), doContinueTeam())
+	case "a", "esc":
+		m.state = TeamStateConfirmAbort
+	}
+	return m, nil
+}
+
+// View renders the team sync flow
+func (m TeamModel) View() string {
+	var s string
+
+	s += RenderTitle("Team sync") + "\n\n"
+
+	switch m.state {
+	case TeamStateFetching:
+		s += m.spinner.View() + " " + RenderHighlight("Checking GitHub for your teammates' saves...") + "\n\n"
+		if m.stopping {
+			s += RenderMuted("Stopping...") + "\n"
+		} else {
+			s += HelpBar(m.Keys())
+		}
+
+	case TeamStateNoRemote:
+		s += RenderSubtitle("No GitHub remote configured") + "\n\n"
+		s += RenderMuted("Use Sync to GitHub first to connect this project.") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case TeamStateUpToDate:
+		s += RenderSuccess("✓ You have everything your teammates uploaded") + "\n\n"
+		if m.status.Ahead > 0 {
+			s += RenderMuted(fmt.Sprintf("You have %d save(s) they don't yet. Use Sync to GitHub to share them.", m.status.Ahead)) + "\n\n"
+		}
+		s += HelpText("Press any key to continue")
+
+	case TeamStateReview:
+		s += m.renderIncoming() + "\n"
+		if m.status.Ahead > 0 {
+			s += RenderMuted(fmt.Sprintf("You have %d save(s) they don't, so the two need combining.", m.status.Ahead)) + "\n\n"
+		}
+		s += RenderSubtitle("How should their saves come in?") + "\n\n"
+		for i, option := range teamOptions {
+			cursor, style := "  ", ListItemStyle
+			if i == m.cursor {
+				cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+			}
+			s += cursor + style.Render(option.title) + "\n"
+			s += "    " + MutedStyle.Render(option.description) + "\n"
+		}
+		s += "\n" + RenderMuted("A backup is made first, and unsaved changes are kept.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case TeamStateIntegrating:
+		s += m.spinner.View() + " " + RenderHighlight("Combining your saves with your teammates'...") + "\n"
+
+	case TeamStateConflicts:
+		s += m.renderConflicts()
+
+	case TeamStateConfirmAbort:
+		s += RenderError("⚠ Stop the team sync?") + "\n\n"
+		s += RenderMuted("Your branch goes back to how it was, and the choices made") + "\n"
+		s += RenderMuted("so far are dropped. Your teammates' saves stay on GitHub.") + "\n\n"
+		s += RenderSubtitle("Stop? (y/n)") + "\n"
+
+	case TeamStateSuccess:
+		s += RenderSuccess("✓ Your teammates' saves are in!") + "\n\n"
+		if m.status.Ahead > 0 {
+			s += RenderMuted("Use Sync to GitHub to share your saves with them too.") + "\n\n"
+		}
+		s += HelpText("Press any key to continue")
+
+	case TeamStateError:
+		s += RenderError("✗ Team sync didn't finish") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderIncoming lists the saves teammates uploaded, newest first
+func (m TeamModel) renderIncoming() string {
+	incoming := m.status.Incoming
+	s := RenderSubtitle(fmt.Sprintf("%d new save(s) on %s", len(incoming), m.status.Branch)) + "\n\n"
+	for i, commit := range incoming {
+		if i == teamMaxCommits {
+			s += RenderMuted(fmt.Sprintf("  ...and %d more", len(incoming)-teamMaxCommits)) + "\n"
+			break
+		}
+		s += "  " + HighlightStyle.Render(commit.Author) + " " + truncateLine(commit.Message, 50) +
+			" " + MutedStyle.Render(commit.When) + "\n"
+	}
+	return s
+}
+
+// renderConflicts walks through the files changed on both sides, one
+// decision per file
+func (m TeamModel) renderConflicts() string {
+	var s string
+	if len(m.conflicts) == 0 {
+		s += RenderSuccess("✓ Every conflict is resolved") + "\n\n"
+		for _, path := range m.resolved {
+			s += RenderMuted("  ✓ "+path) + "\n"
+		}
+		s += "\n" + HelpBar(m.Keys())
+		return s
+	}
+
+	s += RenderError("⚠ You and a teammate changed the same lines") + "\n\n"
+	s += RenderMuted("For each file, keep one version whole, or open it in your editor,") + "\n"
+	s += RenderMuted("fix the parts between <<<<<<< and >>>>>>>, and mark it fixed.") + "\n\n"
+	for i, path := range m.conflicts {
+		cursor, style := "  ", ListItemStyle
+		if i == m.cursor {
+			cursor, style = MenuCursorStyle.Render("> "), ListItemSelectedStyle
+		}
+		s += cursor + style.Render(path) + "\n"
+	}
+	for _, path := range m.resolved {
+		s += RenderMuted("  ✓ "+path) + "\n"
+	}
+	if m.notice != "" {
+		s += "\n" + RenderError(m.notice) + "\n"
+	}
+	s += "\n" + HelpBar(m.Keys())
+	return s
+}
+
+// Keys returns the keys the team sync flow takes in its current state
+func (m TeamModel) Keys() Keys {
+	switch m.state {
+	case TeamStateFetching:
+		if !m.stopping {
+			return Keys{Bar: []key.Binding{hint("esc", "stop")}}
+		}
+	case TeamStateReview:
+		return Keys{Bar: []key.Binding{hint("↑↓", "choose"), hint("enter", "bring in"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case TeamStateConflicts:
+		if len(m.conflicts) == 0 {
+			return Keys{Bar: []key.Binding{hint("c", "continue"), hint("a", "stop")}, More: []key.Binding{hint("enter", "continue"), hint("esc", "stop")}}
+		}
+		return Keys{
+			Bar:  []key.Binding{hint("m", "keep mine"), hint("t", "keep theirs"), hint("e", "I fixed it"), hint("a", "stop")},
+			More: []key.Binding{hint("↑↓", "navigate"), vimKeys, hint("esc", "stop")},
+		}
+	case TeamStateConfirmAbort:
+		return Keys{More: []key.Binding{hint("y", "stop the team sync"), hint("n", "no, keep going")}}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if the team sync flow is complete
+func (m TeamModel) IsDone() bool {
+	return m.state == TeamStateNoRemote || m.state == TeamStateUpToDate ||
+		m.state == TeamStateSuccess || m.state == TeamStateError
+}
+
+// HandlesEsc returns true while esc should ask about stopping rather than
+// leave the screen, which would strand a half-finished rebase or merge, and
+// while downloading, where esc stops the download
+func (m TeamModel) HandlesEsc() bool {
+	return m.state == TeamStateConflicts || m.state == TeamStateConfirmAbort || m.state == TeamStateFetching
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Text on screen is measured in terminal cells rather than bytes: CJK
+// characters and most emoji take two cells, and a character made of several
+// code points, like a flag or an accented letter, is only ever cut whole.
+
+// displayWidth returns how many cells s takes up, ignoring colors
+func displayWidth(s string) int {
+	return ansi.StringWidth(s)
+}
+
+// truncateLine truncates a line to fit within maxWidth, ending it with
+// "..." if anything was cut
+func truncateLine(line string, maxWidth int) string {
+	if maxWidth < 10 {
+		maxWidth = 10
+	}
+	return ansi.Truncate(line, maxWidth, "...")
+}
+
+// truncateLeft is truncateLine from the front, keeping the end, which is
+// the part of a path that says which file it is
+func truncateLeft(line string, maxWidth int) string {
+	if maxWidth < 10 {
+		maxWidth = 10
+	}
+	width := displayWidth(line)
+	if width <= maxWidth {
+		return line
+	}
+	return ansi.TruncateLeft(line, width-maxWidth+3, "...")
+}
+
+// padRight pads s with spaces to width cells, so columns after it line up
+func padRight(s string, width int) string {
+	if w := displayWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// wrapText wraps s at spaces to lines of at most width cells, breaking
+// words too long for a line of their own
+func wrapText(s string, width int) []string {
+	if width < 10 {
+		width = 10
+	}
+	return strings.Split(ansi.Wrap(s, width, ""), "\n")
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+)
+
+// hexColorInput matches a complete #RGB or #RRGGBB color being typed
+var hexColorInput = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// openThemeEditor starts editing a copy of the current theme. Built-in
+// themes get a new name so saving creates a custom theme instead.
+func (m *SettingsModel) openThemeEditor() {
+	m.editTheme = themeFor(m.cfg.Theme)
+	if config.IsBuiltinTheme(themeID(m.cfg.Theme)) {
+		m.editTheme.Name += " Custom"
+	}
+	m.fieldCursor = 0
+	m.fieldErr = nil
+	m.state = SettingsStateThemeEditor
+}
+
+// updateThemeEditor handles keys while browsing the theme's fields
+func (m SettingsModel) updateThemeEditor(msg tea.KeyMsg) (SettingsModel, tea.Cmd) {
+	fieldCount := len(config.ThemeColors(&m.editTheme)) + 1
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if m.fieldCursor > 0 {
+			m.fieldCursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.fieldCursor < fieldCount-1 {
+			m.fieldCursor++
+		}
+	case key.Matches(msg, keys.Enter):
+		m.state = SettingsStateEditThemeField
+		m.fieldInput.Placeholder = ""
+		m.fieldInput.SetValue(m.themeField())
+		m.fieldInput.Focus()
+		return m, textinput.Blink
+	case msg.String() == "s":
+		id, err := config.SaveCustomTheme(m.editTheme)
+		if err != nil {
+			m.fieldErr = err
+			return m, nil
+		}
+		m.cfg.Theme = id
+		m.dirty = true
+		m.fieldErr = nil
+		m.message = fmt.Sprintf("Saved theme %q", m.editTheme.Name)
+		m.state = SettingsStateMenu
+	case msg.String() == "esc":
+		m.fieldErr = nil
+		m.state = SettingsStateMenu
+	}
+	return m, nil
+}
+
+// themeField returns the current value of the selected field
+func (m SettingsModel) themeField() string {
+	if m.fieldCursor == 0 {
+		return m.editTheme.Name
+	}
+	return *config.ThemeColors(&m.editTheme)[m.fieldCursor-1].Value
+}
+
+// setThemeField validates and stores a new value for the selected field
+func (m *SettingsModel) setThemeField(value string) error {
+	if m.fieldCursor == 0 {
+		if config.ThemeID(value) == "" {
+			return fmt.Errorf("theme needs a name")
+		}
+		m.editTheme.Name = value
+		return nil
+	}
+	c := config.ThemeColors(&m.editTheme)[m.fieldCursor-1]
+	if !hexColorInput.MatchString(value) {
+		return fmt.Errorf("%s must be a hex color like #FF6B6B", c.Label)
+	}
+	*c.Value = value
+	return nil
+}
+
+// previewTheme returns the theme being edited, including a color that is
+// still being typed once it's a valid hex color
+func (m SettingsModel) previewTheme() config.Theme {
+	theme := m.editTheme
+	if m.state == SettingsStateEditThemeField && m.fieldCursor > 0 {
+		if value := m.fieldInput.Value(); hexColorInput.MatchString(value) {
+			*config.ThemeColors(&theme)[m.fieldCursor-1].Value = value
+		}
+	}
+	return theme
+}
+
+// renderThemeEditor renders the field list next to a live preview
+func (m SettingsModel) renderThemeEditor() string {
+	var s string
+
+	s += RenderSubtitle("Edit theme:") + "\n\n"
+
+	preview := m.previewTheme()
+	var fields string
+	labels := []string{"Name"}
+	values := []string{preview.Name}
+	for _, c := range config.ThemeColors(&preview) {
+		labels = append(labels, c.Label)
+		values = append(values, *c.Value)
+	}
+	for i, label := range labels {
+		cursor := "  "
+		style := MenuItemStyle
+		if m.fieldCursor == i {
+			cursor = MenuCursorStyle.Render("> ")
+			style = MenuItemSelectedStyle
+		}
+
+		value := HighlightStyle.Render(values[i])
+		if i > 0 {
+			value = lipgloss.NewStyle().Foreground(lipgloss.Color(values[i])).Render("■") + " " + value
+		}
+		if m.state == SettingsStateEditThemeField && m.fieldCursor == i {
+			value = m.fieldInput.View()
+		}
+		fields += fmt.Sprintf("%s%s %s\n", cursor, style.Render(fmt.Sprintf("%-11s", label)), value)
+	}
+
+	s += SideBySide("  ", lipgloss.NewStyle().Width(46).Render(fields), renderThemePreview(preview)) + "\n"
+
+	if m.fieldErr != nil {
+		s += RenderError(m.fieldErr.Error()) + "\n\n"
+	}
+
+	if m.state == SettingsStateEditThemeField {
+		s += HelpBar(m.themeEditorKeys())
+	} else {
+		s += RenderMuted(fmt.Sprintf("Saved to ~/.smooth/themes/%s.json", config.ThemeID(m.editTheme.Name))) + "\n\n"
+		s += HelpBar(m.themeEditorKeys())
+	}
+
+	return s
+}
+
+// themeEditorKeys returns the keys for the theme editor
+func (m SettingsModel) themeEditorKeys() Keys {
+	if m.state == SettingsStateEditThemeField {
+		return Keys{Bar: []key.Binding{hint("enter", "confirm"), hint("esc", "cancel")}, Typing: true}
+	}
+	return Keys{
+		Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "edit"), hint("s", "save theme"), hint("esc", "cancel")},
+		More: []key.Binding{vimKeys},
+	}
+}
@@ -0,0 +1,389 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/config"
+	"smooth/git"
+)
+
+// TidyState represents the state of the tidy up saves flow
+type TidyState int
+
+const (
+	TidyStatePick TidyState = iota
+	TidyStatePlan
+	TidyStateMessage
+	TidyStateConfirm
+	TidyStateWorking
+	TidyStateSuccess
+	TidyStateError
+)
+
+// tidyLookback is how many recent saves are offered for tidying
+const tidyLookback = 50
+
+// TidyModel combines and renames recent saves. The user picks how far back
+// to go, then splits the saves into groups; each group becomes one save.
+type TidyModel struct {
+	saves    []git.CommitInfo // saves that can be tidied, newest first
+	cursor   int
+	count    int          // how many of the newest saves are being tidied
+	splits   map[int]bool // saves that don't combine with the one before them
+	messages map[int]string
+	input    textinput.Model
+	backup   string
+	state    TidyState
+	err      error
+	width    int
+	height   int
+}
+
+// NewTidyModel creates a new tidy model
+func NewTidyModel() TidyModel {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(ColorAccent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+
+	m := TidyModel{input: ti, state: TidyStatePick}
+	m.saves, m.err = git.TidyableSaves(tidyLookback)
+	if m.err != nil {
+		m.state = TidyStateError
+	}
+	return m
+}
+
+// Init initializes the model
+func (m TidyModel) Init() tea.Cmd {
+	return nil
+}
+
+// TidyMsg is sent when tidying saves completes
+type TidyMsg struct {
+	Backup string
+	Err    error
+}
+
+// doTidy rewrites the saves into the planned groups
+func doTidy(groups []git.TidyGroup) tea.Cmd {
+	return func() tea.Msg {
+		backup, err := git.TidyHistory(groups)
+		if err == nil {
+			branch, _ := git.CurrentBranch()
+			cfg, _ := config.Load()
+			git.TrimBackups(branch, cfg.MaxBackups)
+		}
+		return TidyMsg{Backup: backup, Err: err}
+	}
+}
+
+// groupStart returns the index of the oldest save in the group containing
+// save i. Indexes are newest first, so the oldest has the highest index.
+func (m TidyModel) groupStart(i int) int {
+	for i < m.count-1 && !m.splits[i] {
+		i++
+	}
+	return i
+}
+
+// groupEnd returns the index of the newest save in the group containing
+// save i
+func (m TidyModel) groupEnd(i int) int {
+	for i > 0 && !m.splits[i-1] {
+		i--
+	}
+	return i
+}
+
+// groupMessage returns the message for the group containing save i, which
+// is the newest save's message until it's renamed
+func (m TidyModel) groupMessage(i int) string {
+	if msg, ok := m.messages[m.groupStart(i)]; ok {
+		return msg
+	}
+	return m.saves[m.groupEnd(i)].Message
+}
+
+// groups returns the plan as groups of saves, oldest first
+func (m TidyModel) groups() []git.TidyGroup {
+	var groups []git.TidyGroup
+	for start := m.count - 1; start >= 0; {
+		end := m.groupEnd(start)
+		g := git.TidyGroup{Message: m.groupMessage(start)}
+		for i := start; i >= end; i-- {
+			g.Hashes = append(g.Hashes, m.saves[i].FullHash)
+		}
+		groups = append(groups, g)
+		start = end - 1
+	}
+	return groups
+}
+
+// changed checks if the plan would change anything
+func (m TidyModel) changed() bool {
+	return len(m.groups()) < m.count || len(m.messages) > 0
+}
+
+// Update handles messages
+func (m TidyModel) Update(msg tea.Msg) (TidyModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case TidyMsg:
+		if msg.Err != nil {
+			m.state = TidyStateError
+			m.err = msg.Err
+		} else {
+			m.state = TidyStateSuccess
+			m.backup = msg.Backup
+		}
+
+	case tea.KeyMsg:
+		switch m.state {
+		case TidyStatePick:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < len(m.saves)-1 {
+					m.cursor++
+				}
+			case key.Matches(msg, keys.Enter):
+				if m.cursor > 0 {
+					m.count = m.cursor + 1
+					m.splits = make(map[int]bool)
+					m.messages = make(map[int]string)
+					m.cursor = 0
+					m.state = TidyStatePlan
+				}
+			}
+
+		case TidyStatePlan:
+			switch {
+			case key.Matches(msg, keys.Up):
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case key.Matches(msg, keys.Down):
+				if m.cursor < m.count-1 {
+					m.cursor++
+				}
+			case msg.String() == " ":
+				// Split from or join with the save before this one; the
+				// oldest save has nothing before it to join
+				if m.cursor < m.count-1 {
+					// The groups change shape, so their renames start over
+					delete(m.messages, m.groupStart(m.cursor))
+					delete(m.messages, m.groupStart(m.cursor+1))
+					m.splits[m.cursor] = !m.splits[m.cursor]
+				}
+			case msg.String() == "a":
+				// Combine everything into one save
+				m.splits = make(map[int]bool)
+				m.messages = make(map[int]string)
+			case msg.String() == "r" || key.Matches(msg, keys.Enter):
+				m.input.SetValue(m.groupMessage(m.cursor))
+				m.input.CursorEnd()
+				m.input.Focus()
+				m.state = TidyStateMessage
+				return m, textinput.Blink
+			case msg.String() == "c":
+				if m.changed() {
+					m.state = TidyStateConfirm
+				}
+			case msg.String() == "esc":
+				m.cursor = m.count - 1
+				m.state = TidyStatePick
+			}
+
+		case TidyStateMessage:
+			switch msg.String() {
+			case "enter":
+				if value := m.input.Value(); value != "" {
+					m.messages[m.groupStart(m.cursor)] = value
+				}
+				m.input.Blur()
+				m.state = TidyStatePlan
+				return m, nil
+			case "esc":
+				m.input.Blur()
+				m.state = TidyStatePlan
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+
+		case TidyStateConfirm:
+			switch msg.String() {
+			case "y", "Y":
+				m.state = TidyStateWorking
+				return m, doTidy(m.groups())
+			case "n", "N", "esc":
+				m.state = TidyStatePlan
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the tidy screen
+func (m TidyModel) View() string {
+	var s string
+
+	s += RenderTitle("Tidy Up Saves") + "\n\n"
+
+	switch m.state {
+	case TidyStatePick:
+		if len(m.saves) < 2 {
+			s += RenderMuted("There's nothing to tidy yet.") + "\n\n"
+			s += RenderMuted("Only saves that haven't been synced to GitHub can be tidied,") + "\n"
+			s += RenderMuted("and it takes at least two of them.") + "\n\n"
+			s += HelpBar(m.Keys())
+			break
+		}
+		s += RenderSubtitle("How far back do you want to tidy?") + "\n\n"
+		maxVisible := m.visibleSaves()
+		start := max(0, m.cursor-maxVisible+1)
+		for i := start; i < len(m.saves) && i < start+maxVisible; i++ {
+			save := m.saves[i]
+			cursor := "  "
+			style := ListItemStyle
+			if i <= m.cursor {
+				cursor = MutedStyle.Render("│ ")
+			}
+			if i == m.cursor {
+				cursor = MenuCursorStyle.Render("> ")
+				style = ListItemSelectedStyle
+			}
+			s += cursor + style.Render(truncateLine(save.Message, 50)) + " " + MutedStyle.Render(save.Timestamp) + "\n"
+		}
+		s += "\n"
+		if m.cursor > 0 {
+			s += RenderMuted(fmt.Sprintf("Tidy the latest %d saves", m.cursor+1)) + "\n\n"
+		} else {
+			s += RenderMuted("Move down to include at least two saves") + "\n\n"
+		}
+		s += RenderMuted("Saves already synced to GitHub can't be tidied.") + "\n\n"
+		s += HelpBar(m.Keys())
+
+	case TidyStatePlan, TidyStateMessage:
+		groups := len(m.groups())
+		s += RenderSubtitle(fmt.Sprintf("%d saves will become %d:", m.count, groups)) + "\n\n"
+		s += m.renderPlan()
+		s += "\n"
+		if m.state == TidyStateMessage {
+			s += RenderSubtitle("Message for this save:") + "\n"
+			s += m.input.View() + "\n\n"
+			s += HelpBar(m.Keys())
+			break
+		}
+		s += HelpBar(m.Keys())
+
+	case TidyStateConfirm:
+		groups := m.groups()
+		s += RenderHighlight(fmt.Sprintf("Turn %d saves into %d?", m.count, len(groups))) + "\n\n"
+		for i := len(groups) - 1; i >= 0; i-- {
+			s += "  " + SuccessStyle.Render("●") + " " + groups[i].Message + "\n"
+		}
+		s += "\n"
+		s += RenderMuted("Your files won't change, only how the saves are grouped.") + "\n"
+		s += RenderMuted("A backup is made first, so you can undo this from Restore backup.") + "\n\n"
+		s += RenderSubtitle("Tidy up? (y/n)") + "\n"
+
+	case TidyStateWorking:
+		s += RenderMuted("Tidying up saves...") + "\n"
+
+	case TidyStateSuccess:
+		s += RenderSuccess("✓ Saves tidied up") + "\n\n"
+		s += RenderMuted("The old saves are kept in "+m.backup+" if you change your mind.") + "\n\n"
+		s += HelpText("Press any key to continue")
+
+	case TidyStateError:
+		s += RenderError("✗ Couldn't tidy up saves") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += RenderMuted("Nothing was changed.") + "\n\n"
+		s += HelpText("Press any key to go back")
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderPlan draws the saves being tidied, newest first, under the message
+// of the save each group will become
+func (m TidyModel) renderPlan() string {
+	var s string
+	for i := 0; i < m.count; i++ {
+		if i == m.groupEnd(i) {
+			size := m.groupStart(i) - i + 1
+			label := ""
+			if size > 1 {
+				label = MutedStyle.Render(fmt.Sprintf(" (%d saves)", size))
+			}
+			s += "  " + SuccessStyle.Render("●") + " " + HighlightStyle.Render(truncateLine(m.groupMessage(i), 50)) + label + "\n"
+		}
+		save := m.saves[i]
+		cursor := "    "
+		style := MutedStyle
+		if i == m.cursor {
+			cursor = "  " + MenuCursorStyle.Render("> ")
+			style = HighlightStyle
+		}
+		s += cursor + MutedStyle.Render("│ ") + style.Render(truncateLine(save.Message, 44)) + " " + MutedStyle.Render(save.Timestamp) + "\n"
+	}
+	return s
+}
+
+// visibleSaves is how many saves fit in the list
+func (m TidyModel) visibleSaves() int {
+	if m.height > 0 {
+		return max(5, m.height-16)
+	}
+	return 12
+}
+
+// Keys returns the keys the tidy screen takes in its current state
+func (m TidyModel) Keys() Keys {
+	switch m.state {
+	case TidyStatePick:
+		if len(m.saves) < 2 {
+			return Keys{Bar: []key.Binding{hint("esc", "back")}}
+		}
+		return Keys{Bar: []key.Binding{hint("↑↓", "navigate"), hint("enter", "tidy back to here"), hint("esc", "back")}, More: []key.Binding{vimKeys}}
+	case TidyStateMessage:
+		return Keys{Bar: []key.Binding{hint("enter", "done"), hint("esc", "cancel")}, Typing: true}
+	case TidyStatePlan:
+		return Keys{
+			Bar: []key.Binding{hint("↑↓", "navigate"), hint("space", "split/join"), hint("a", "combine all"),
+				hint("r", "rename"), hint("c", "continue"), hint("esc", "back")},
+			More: []key.Binding{vimKeys, hint("enter", "rename")},
+		}
+	case TidyStateConfirm:
+		return Keys{More: []key.Binding{hint("y", "tidy the saves"), hint("n", "no, back to the plan")}}
+	}
+	return Keys{}
+}
+
+// HandlesEsc returns true while esc should step back within the screen
+func (m TidyModel) HandlesEsc() bool {
+	return m.state == TidyStatePlan || m.state == TidyStateMessage || m.state == TidyStateConfirm
+}
+
+// IsDone returns true once the saves are tidied or something failed
+func (m TidyModel) IsDone() bool {
+	return m.state == TidyStateSuccess || m.state == TidyStateError
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/git"
+)
+
+// timelineCommitLimit is how many commits the timeline loads
+const timelineCommitLimit = 200
+
+// TimelineState represents the state of the timeline screen
+type TimelineState int
+
+const (
+	TimelineStateGraph TimelineState = iota
+	TimelineStateEmpty
+	TimelineStateError
+)
+
+// TimelineModel is the model for the commit graph screen
+type TimelineModel struct {
+	lines       []git.GraphLine
+	cursor      int // index into lines, always on a commit row
+	state       TimelineState
+	err         error
+	wantRestore bool
+	wantDetail  bool
+	width       int
+	height      int
+}
+
+// NewTimelineModel creates a new timeline model
+func NewTimelineModel() TimelineModel {
+	lines, err := git.Graph(timelineCommitLimit)
+
+	m := TimelineModel{
+		lines: lines,
+		state: TimelineStateGraph,
+	}
+
+	if err != nil {
+		m.state = TimelineStateError
+		m.err = err
+		return m
+	}
+
+	m.cursor = m.nextCommit(-1, 1)
+	if m.cursor < 0 {
+		m.state = TimelineStateEmpty
+		m.cursor = 0
+	}
+	return m
+}
+
+// Init initializes the timeline model
+func (m TimelineModel) Init() tea.Cmd {
+	return nil
+}
+
+// nextCommit returns the index of the next commit row from start in the
+// given direction, or -1 if there is none
+func (m TimelineModel) nextCommit(start, dir int) int {
+	for i := start + dir; i >= 0 && i < len(m.lines); i += dir {
+		if m.lines[i].Commit != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// Update handles messages for the timeline model
+func (m TimelineModel) Update(msg tea.Msg) (TimelineModel, tea.Cmd) {
+	// Asking for details lasts one message, so coming back to the graph
+	// doesn't open them again
+	m.wantDetail = false
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.state != TimelineStateGraph {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Up):
+			if i := m.nextCommit(m.cursor, -1); i >= 0 {
+				m.cursor = i
+			}
+		case key.Matches(msg, keys.Down):
+			if i := m.nextCommit(m.cursor, 1); i >= 0 {
+				m.cursor = i
+			}
+		case key.Matches(msg, keys.Enter):
+			m.wantRestore = true
+		case msg.String() == "i":
+			m.wantDetail = true
+		case msg.String() == "c":
+			return m, copyToClipboard(m.SelectedCommit().FullHash, "the hash")
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the timeline
+func (m TimelineModel) View() string {
+	var s string
+
+	s += RenderTitle("Timeline") + "\n\n"
+
+	switch m.state {
+	case TimelineStateEmpty:
+		s += RenderMuted("No save points found!") + "\n\n"
+		s += HelpText("Press any key to go back")
+
+	case TimelineStateError:
+		s += RenderError("✗ Could not load the timeline") + "\n\n"
+		if m.err != nil {
+			s += renderErrorDetails(m.err)
+		}
+		s += HelpText("Press any key to go back")
+
+	case TimelineStateGraph:
+		s += RenderSubtitle("Every save on every branch, newest first:") + "\n\n"
+		s += m.renderGraph() + "\n\n"
+		s += HelpBar(m.Keys())
+	}
+
+	return BoxStyle.Render(s)
+}
+
+// renderGraph renders the visible window of graph rows around the cursor
+func (m TimelineModel) renderGraph() string {
+	maxVisible := 20
+	if m.height > 0 {
+		maxVisible = m.height - 12
+		if maxVisible < 5 {
+			maxVisible = 5
+		}
+	}
+
+	start := 0
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+	end := start + maxVisible
+	if end > len(m.lines) {
+		end = len(m.lines)
+	}
+
+	width := m.width - 10
+	if width < 60 {
+		width = 60
+	}
+
+	graphStyle := lipgloss.NewStyle().Foreground(ColorSecondary)
+
+	var rows []string
+	for i := start; i < end; i++ {
+		line := m.lines[i]
+		if line.Commit == nil {
+			rows = append(rows, "  "+graphStyle.Render(line.Graph))
+			continue
+		}
+
+		cursor := "  "
+		msgStyle := NormalStyle
+		if i == m.cursor {
+			cursor = MenuCursorStyle.Render("> ")
+			msgStyle = ListItemSelectedStyle.PaddingLeft(0)
+		}
+
+		refs := ""
+		if len(line.Refs) > 0 {
+			refs = " " + HighlightStyle.Render("("+strings.Join(line.Refs, ", ")+")")
+		}
+
+		signed := ""
+		if line.Commit.Signed() {
+			signed = " " + SignedBadge()
+		}
+
+		used := len(line.Graph) + len(line.Commit.Hash) + len(line.Commit.Timestamp) + lipgloss.Width(signed) + 8
+		message := truncateLine(line.Commit.Message, width-used)
+
+		rows = append(rows, fmt.Sprintf("%s%s%s %s%s %s%s",
+			cursor,
+			graphStyle.Render(line.Graph),
+			MutedStyle.Render(line.Commit.Hash),
+			msgStyle.Render(message),
+			refs,
+			MutedStyle.Render(line.Commit.Timestamp),
+			signed))
+	}
+
+	if start > 0 {
+		rows = append([]string{MutedStyle.Render("  ▲ newer saves above")}, rows...)
+	}
+	if end < len(m.lines) {
+		rows = append(rows, MutedStyle.Render("  ▼ older saves below"))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// Keys returns the keys the timeline takes in its current state
+func (m TimelineModel) Keys() Keys {
+	if m.state == TimelineStateGraph {
+		return Keys{
+			Bar:  []key.Binding{hint("↑↓", "navigate"), hint("enter", "revert to this"), hint("i", "details"), hint("c", "copy hash"), hint("esc", "back")},
+			More: []key.Binding{vimKeys},
+		}
+	}
+	return Keys{}
+}
+
+// IsDone returns true if there is nothing to interact with
+func (m TimelineModel) IsDone() bool {
+	return m.state == TimelineStateEmpty || m.state == TimelineStateError
+}
+
+// WantsRestore returns true if the user picked a commit to revert to
+func (m TimelineModel) WantsRestore() bool {
+	return m.wantRestore
+}
+
+// WantsDetail returns true if the user asked for the selected commit's
+// details
+func (m TimelineModel) WantsDetail() bool {
+	return m.wantDetail
+}
+
+// SelectedCommit returns the commit under the cursor
+func (m TimelineModel) SelectedCommit() git.CommitInfo {
+	if m.cursor < len(m.lines) && m.lines[m.cursor].Commit != nil {
+		return *m.lines[m.cursor].Commit
+	}
+	return git.CommitInfo{}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toastDuration is how long a toast stays up
+const toastDuration = 2 * time.Second
+
+// Toast is a brief note under the screen, like "Copied the hash!", that
+// goes away by itself
+type Toast struct {
+	text string
+	id   int // which toast is up, so an older one's expiry doesn't clear it
+}
+
+// ToastExpiredMsg is sent when a toast's time is up
+type ToastExpiredMsg struct {
+	id int
+}
+
+// Show puts up a toast, replacing any that's showing
+func (t Toast) Show(text string) (Toast, tea.Cmd) {
+	t.id++
+	t.text = text
+	id := t.id
+	return t, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{id: id}
+	})
+}
+
+// Update clears the toast once its time is up
+func (t Toast) Update(msg tea.Msg) Toast {
+	if msg, ok := msg.(ToastExpiredMsg); ok && msg.id == t.id {
+		t.text = ""
+	}
+	return t
+}
+
+// View renders the toast, or nothing if there isn't one
+func (t Toast) View() string {
+	if t.text == "" {
+		return ""
+	}
+	return RenderSuccess("✓ " + t.text)
+}
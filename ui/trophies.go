@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"smooth/achievements"
+)
+
+// celebrationTickMsg advances the achievement unlock animation
+type celebrationTickMsg struct{}
+
+// Animation timing for unlocked achievements
+const (
+	celebrationFrames    = 16
+	celebrationFrameTime = 120 * time.Millisecond
+)
+
+// sparkles cycle along the celebration border
+var sparkles = []string{"✦", "✧", "★", "☆", "✨", "·"}
+
+// celebration animates newly unlocked achievements on a success screen
+type celebration struct {
+	unlocked []achievements.Achievement
+	frame    int
+}
+
+// newCelebration starts an animation for the unlocked achievements, if any
+func newCelebration(unlocked []achievements.Achievement) (celebration, tea.Cmd) {
+	c := celebration{unlocked: unlocked}
+	if len(unlocked) == 0 {
+		return c, nil
+	}
+	return c, celebrationTick()
+}
+
+// celebrationTick schedules the next animation frame
+func celebrationTick() tea.Cmd {
+	return tea.Tick(celebrationFrameTime, func(time.Time) tea.Msg {
+		return celebrationTickMsg{}
+	})
+}
+
+// Update advances the animation
+func (c celebration) Update(msg tea.Msg) (celebration, tea.Cmd) {
+	if _, ok := msg.(celebrationTickMsg); ok && c.frame < celebrationFrames {
+		c.frame++
+		if c.frame < celebrationFrames {
+			return c, celebrationTick()
+		}
+	}
+	return c, nil
+}
+
+// View renders the unlocked achievements, or "" if there are none
+func (c celebration) View() string {
+	if len(c.unlocked) == 0 {
+		return ""
+	}
+
+	colors := []lipgloss.Color{ColorAccent, ColorPrimary, ColorSecondary, ColorHighlight, ColorSuccess}
+
+	// Sparkles march along the border while animating, then settle
+	var border string
+	for i := 0; i < 24; i++ {
+		offset := i
+		if c.frame < celebrationFrames {
+			offset += c.frame
+		}
+		style := lipgloss.NewStyle().Foreground(colors[offset%len(colors)])
+		border += style.Render(sparkles[offset%len(sparkles)]) + " "
+	}
+
+	title := "🏆 Achievement unlocked!"
+	if len(c.unlocked) > 1 {
+		title = fmt.Sprintf("🏆 %d achievements unlocked!", len(c.unlocked))
+	}
+
+	var s string
+	s += border + "\n\n"
+	s += lipgloss.NewStyle().Bold(true).Foreground(colors[c.frame%len(colors)]).Render(title) + "\n\n"
+	for _, a := range c.unlocked {
+		s += "  " + a.Icon + " " + HighlightStyle.Render(a.Title) + " " + MutedStyle.Render("— "+a.Description) + "\n"
+	}
+	s += "\n" + border
+	return s
+}
+
+// TrophiesModel is the model for the trophy screen
+type TrophiesModel struct {
+	state  achievements.State
+	err    error
+	width  int
+	height int
+}
+
+// NewTrophiesModel creates a new trophy screen model
+func NewTrophiesModel() TrophiesModel {
+	state, err := achievements.Load()
+	return TrophiesModel{state: state, err: err}
+}
+
+// Init initializes the model
+func (m TrophiesModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m TrophiesModel) Update(msg tea.Msg) (TrophiesModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the trophy screen
+func (m TrophiesModel) View() string {
+	var s string
+
+	s += RenderTitle("Trophies") + "\n\n"
+
+	if m.err != nil {
+		s += RenderError("✗ Couldn't read your trophies") + "\n\n"
+		s += renderErrorDetails(m.err)
+		s += HelpText("Press any key to go back")
+		return BoxStyle.Render(s)
+	}
+
+	unlocked := 0
+	for _, a := range achievements.All {
+		if m.state.IsUnlocked(a.ID) {
+			unlocked++
+		}
+	}
+	s += RenderSubtitle(fmt.Sprintf("%d of %d unlocked", unlocked, len(achievements.All))) + "\n"
+	s += RenderProgressBar(float64(unlocked)/float64(len(achievements.All)), 30) + "\n\n"
+
+	for _, a := range achievements.All {
+		if when, ok := m.state.Unlocked[a.ID]; ok {
+			s += "  " + a.Icon + " " + HighlightStyle.Render(a.Title) + "  " + MutedStyle.Render(when.Format("Jan 2, 2006")) + "\n"
+			s += "     " + MutedStyle.Render(a.Description) + "\n\n"
+		} else {
+			s += "  🔒 " + MutedStyle.Render(a.Title) + "\n"
+			s += "     " + MutedStyle.Render(a.Description+m.progress(a.ID)) + "\n\n"
+		}
+	}
+
+	stats := []string{
+		fmt.Sprintf("%d saves", m.state.Saves),
+		fmt.Sprintf("%d syncs", m.state.Syncs),
+		fmt.Sprintf("%d-day streak", m.state.Streak(time.Now())),
+	}
+	s += RenderMuted(strings.Join(stats, " · ")) + "\n\n"
+	s += HelpBar(m.Keys())
+
+	return BoxStyle.Render(s)
+}
+
+// progress returns a " (n/goal)" hint for counting achievements
+func (m TrophiesModel) progress(id string) string {
+	switch id {
+	case "ten-saves":
+		return fmt.Sprintf(" (%d/10)", m.state.Saves)
+	case "hundred-saves":
+		return fmt.Sprintf(" (%d/100)", m.state.Saves)
+	case "seven-day-streak":
+		return fmt.Sprintf(" (%d/7)", m.state.Streak(time.Now()))
+	}
+	return ""
+}
+
+// Keys returns the keys the trophies screen takes
+func (m TrophiesModel) Keys() Keys {
+	return Keys{Bar: []key.Binding{hint("esc", "back")}}
+}
+
+// IsDone returns true if the trophies couldn't be loaded
+func (m TrophiesModel) IsDone() bool {
+	return m.err != nil
+}
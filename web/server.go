@@ -1,38 +1,85 @@
 package web
 
 import (
+	"context"
+	"crypto/tls"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"mime"
+	"net"
 	"net/http"
-
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"smooth/achievements"
 	"smooth/config"
 	"smooth/git"
+	"smooth/notify"
+	"smooth/ops"
+	"smooth/platform"
 )
 
 //go:embed static/*
 var staticFiles embed.FS
 
-// StartServer starts the web server on the specified port
-func StartServer(port int) error {
+// Options is how the web interface is served
+type Options struct {
+	Port  int  // 0 for config.WebPort, or the next free port if it's taken
+	HTTPS bool // over HTTPS with a self-signed certificate, to other devices on the network too
+	Open  bool // open the web interface in the browser once it's up
+}
+
+// portAttempts is how many ports from config.WebPort on are tried before
+// giving up
+const portAttempts = 10
+
+// shutdownTimeout is how long requests that are still running, like a
+// save, get to finish once the server is stopped
+const shutdownTimeout = 10 * time.Second
+
+// StartServer serves the web interface until ctx is done, then shuts down
+// once the requests that are still running finish. Over plain HTTP it's
+// only reachable from this computer; with HTTPS it's reachable from the
+// network, so the dashboard can be opened on something like a tablet
+// without anything being sent in the clear.
+func StartServer(ctx context.Context, opts Options) error {
 	// API routes
 	http.HandleFunc("/api/status", handleStatus)
 	http.HandleFunc("/api/changes", handleChanges)
 	http.HandleFunc("/api/save", handleSave)
+	http.HandleFunc("/api/save/plan", handleSavePlan)
 	http.HandleFunc("/api/sync", handleSync)
 	http.HandleFunc("/api/commits", handleCommits)
+	http.HandleFunc("/api/graph", handleGraph)
+	http.HandleFunc("/api/diff", handleDiff)
+	http.HandleFunc("/api/compare", handleCompare)
+	http.HandleFunc("/api/file", handleFile)
+	http.HandleFunc("/api/activity", handleActivity)
+	http.HandleFunc("/api/stats", handleStats)
 	http.HandleFunc("/api/restore", handleRestore)
 	http.HandleFunc("/api/backups", handleBackups)
+	http.HandleFunc("/api/backup", handleBackup)
 	http.HandleFunc("/api/restore-backup", handleRestoreBackup)
+	http.HandleFunc("/api/export", handleExport)
 	http.HandleFunc("/api/experiments", handleExperiments)
 	http.HandleFunc("/api/experiment/create", handleCreateExperiment)
 	http.HandleFunc("/api/experiment/keep", handleKeepExperiment)
 	http.HandleFunc("/api/experiment/abandon", handleAbandonExperiment)
 	http.HandleFunc("/api/experiment/switch", handleSwitchExperiment)
 	http.HandleFunc("/api/gitignore", handleGitignore)
+	http.HandleFunc("/api/tasks", handleTasks)
+	http.HandleFunc("/api/task/done", handleTaskDone)
+	http.HandleFunc("/api/task/delete", handleDeleteTask)
 	http.HandleFunc("/api/config", handleConfig)
 	http.HandleFunc("/api/themes", handleThemes)
+	http.HandleFunc("/api/theme", handleTheme)
 
 	// Static files
 	staticFS, err := fs.Sub(staticFiles, "static")
@@ -41,8 +88,110 @@ func StartServer(port int) error {
 	}
 	http.Handle("/", http.FileServer(http.FS(staticFS)))
 
-	fmt.Printf("Starting web server at http://localhost:%d\n", port)
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	server := &http.Server{}
+	host, scheme := "localhost", "http"
+	var cert tls.Certificate
+	var token string
+	if opts.HTTPS {
+		if cert, err = LoadCertificate(); err != nil {
+			return err
+		}
+		// No Strict-Transport-Security header is sent: browsers would
+		// remember it for localhost, and refuse plain HTTP there afterwards,
+		// for this and every other server run on this computer
+		server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		host, scheme = "", "https"
+
+		// Anyone on the network can reach it, so only those given the
+		// address with the token get in
+		if token, err = newAccessToken(); err != nil {
+			return err
+		}
+		server.Handler = requireToken(token, http.DefaultServeMux)
+	}
+
+	ln, err := listen(host, opts.Port)
+	if err != nil {
+		return err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	if opts.Port == 0 && port != config.WebPort {
+		fmt.Printf("Port %d is in use, so using %d instead\n", config.WebPort, port)
+	}
+	address := fmt.Sprintf("%s://localhost:%d", scheme, port)
+	if token != "" {
+		address += "/?" + tokenParam + "=" + token
+	}
+	fmt.Printf("Starting web server at %s\n", address)
+	if opts.HTTPS {
+		printHTTPSAddresses(port, token, cert)
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	served := make(chan error, 1)
+	go func() {
+		if opts.HTTPS {
+			served <- server.ServeTLS(ln, "", "")
+		} else {
+			served <- server.Serve(ln)
+		}
+	}()
+	if opts.Open {
+		if err := platform.OpenBrowser(address); err != nil {
+			fmt.Printf("Couldn't open the browser: %v\n", err)
+		}
+	}
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+	fmt.Println("\nStopping web server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// listen opens the port to serve on. Port 0 is config.WebPort, or the next
+// free port after it if something else is using it; a port that was asked
+// for has to be free.
+func listen(host string, port int) (net.Listener, error) {
+	if port != 0 {
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't use port %d, pick another with --port: %w", port, err)
+		}
+		return ln, nil
+	}
+
+	var err error
+	for port := config.WebPort; port < config.WebPort+portAttempts; port++ {
+		var ln net.Listener
+		if ln, err = net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port))); err == nil {
+			return ln, nil
+		}
+	}
+	return nil, fmt.Errorf("ports %d to %d are all in use, pick another with --port: %w",
+		config.WebPort, config.WebPort+portAttempts-1, err)
+}
+
+// printHTTPSAddresses shows where else the web interface can be opened, and
+// the certificate's fingerprint to check when the browser warns about it
+func printHTTPSAddresses(port int, token string, cert tls.Certificate) {
+	for _, ip := range LocalAddresses() {
+		fmt.Printf("  On other devices: https://%s/?%s=%s\n", net.JoinHostPort(ip.String(), strconv.Itoa(port)), tokenParam, token)
+	}
+	fmt.Println()
+	fmt.Println("The certificate is self-signed, so browsers will ask before trusting it.")
+	if fingerprint, err := Fingerprint(cert); err == nil {
+		fmt.Println("Check that its SHA-256 fingerprint is:")
+		fmt.Println("  " + fingerprint)
+	}
+	fmt.Println("Anyone with the address, token included, can use smooth, so keep it to yourself.")
 }
 
 // Response helpers
@@ -65,9 +214,12 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 	isOnMain := git.IsOnMain()
 
 	jsonResponse(w, map[string]interface{}{
-		"branch":     branch,
-		"hasChanges": hasChanges,
-		"isOnMain":   isOnMain,
+		"branch":       branch,
+		"hasChanges":   hasChanges,
+		"isOnMain":     isOnMain,
+		"detached":     git.IsDetached(),
+		"savesWaiting": git.SavesWaiting(),
+		"busy":         git.BusyWith(),
 	})
 }
 
@@ -89,44 +241,250 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Message string   `json:"message"`
 		Files   []string `json:"files"`
+		TaskID  int      `json:"taskId,omitempty"` // task to link the save to
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, "Invalid request", 400)
 		return
 	}
 
-	// Stage files
-	if len(req.Files) > 0 {
-		if err := git.AddFiles(req.Files); err != nil {
-			errorResponse(w, err.Error(), 500)
+	var task *git.Task
+	if req.TaskID != 0 {
+		for _, t := range git.OpenTasks() {
+			if t.ID == req.TaskID {
+				task = &t
+				req.Message += " " + t.Reference()
+				break
+			}
+		}
+		if task == nil {
+			errorResponse(w, fmt.Sprintf("No open task #%d", req.TaskID), 400)
 			return
 		}
 	}
 
-	// Commit
-	if err := git.Commit(req.Message); err != nil {
+	if len(req.Files) == 0 {
+		errorResponse(w, "No files to save", 400)
+		return
+	}
+
+	changes, err := git.GetChangeSummary()
+	if err != nil {
 		errorResponse(w, err.Error(), 500)
 		return
 	}
+	known := make(map[string]bool)
+	for _, change := range changes {
+		known[change.Path] = true
+	}
+	// The files asked for are saved and the rest skipped, like unticking
+	// them on the save screen
+	files := ops.NewFiles(changes)
+	for i := range files {
+		files[i].Action = ops.ActionSkip
+	}
+	for _, path := range req.Files {
+		if !isChangedPath(path, known) {
+			errorResponse(w, fmt.Sprintf("%s has no changes", path), 400)
+			return
+		}
+		files = withFileAction(files, path, ops.ActionSave)
+	}
 
-	// Auto-sync if enabled
+	// The same save the TUI makes, template, secret scan and checks included
 	cfg, _ := config.Load()
+	plan := ops.NewPlan(req.Message, files)
+	result, err := plan.Execute(nil)
+	if err != nil {
+		saveErrorResponse(w, err)
+		return
+	}
+	achievements.RecordSave(result.LinesAdded, result.LinesDeleted)
+	if task != nil {
+		git.LinkTaskSave(task.ID, result.Hash)
+	}
+
+	// Auto-sync if enabled
 	autoSynced := false
 	var syncErr string
 	if cfg.AutoSyncEnabled && git.HasRemote() {
 		autoSynced = true
-		if err := git.Push(); err != nil {
+		if err := push(r); err != nil {
+			syncErr = err.Error()
+			git.QueueSync(err)
+			notify.SyncFailed(err)
+		}
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"status":     "ok",
+		"hash":       result.Hash,
+		"message":    result.Message,
+		"autoSynced": autoSynced,
+		"syncError":  syncErr,
+	})
+}
+
+// saveErrorResponse reports why a save failed. Suspected secrets and
+// failing checks are a 409 with the details, so the page can ask what to do.
+func saveErrorResponse(w http.ResponseWriter, err error) {
+	var secrets *ops.SecretsFoundError
+	if errors.As(err, &secrets) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    err.Error(),
+			"findings": secrets.Findings,
+		})
+		return
+	}
+	var checks *ops.ChecksFailedError
+	if errors.As(err, &checks) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  err.Error(),
+			"checks": checks.Check,
+			"output": checks.Output,
+		})
+		return
+	}
+	errorResponse(w, err.Error(), 500)
+}
+
+// handleSavePlan saves with a chosen action for each changed file, the same
+// way the TUI save screen does. Changed files that aren't listed are saved,
+// unless they're on the skip list.
+func handleSavePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, "Method not allowed", 405)
+		return
+	}
+
+	var req struct {
+		Message      string            `json:"message"`
+		Actions      map[string]string `json:"actions"`      // path -> save, revert, skip or ignore
+		AllowSecrets []string          `json:"allowSecrets"` // paths to save despite suspected secrets
+		SkipChecks   bool              `json:"skipChecks"`   // save even if the project's checks fail
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, "Invalid request", 400)
+		return
+	}
+
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	files := ops.NewFiles(changes)
+	known := make(map[string]bool)
+	for _, change := range changes {
+		known[change.Path] = true
+	}
+	for path, name := range req.Actions {
+		action, ok := ops.Actions[name]
+		if !ok {
+			errorResponse(w, fmt.Sprintf("Unknown action %q for %s", name, path), 400)
+			return
+		}
+		if !isChangedPath(path, known) {
+			errorResponse(w, fmt.Sprintf("%s has no changes", path), 400)
+			return
+		}
+		files = withFileAction(files, path, action)
+	}
+
+	hasSaves := false
+	for _, f := range files {
+		if f.Action == ops.ActionSave {
+			hasSaves = true
+			break
+		}
+	}
+	if hasSaves && strings.TrimSpace(req.Message) == "" {
+		errorResponse(w, "A description is needed to save files", 400)
+		return
+	}
+
+	plan := ops.NewPlan(req.Message, files)
+	plan.AllowSecrets = req.AllowSecrets
+	plan.SkipChecks = req.SkipChecks
+	result, err := plan.Execute(nil)
+	if err != nil {
+		saveErrorResponse(w, err)
+		return
+	}
+
+	if result.Hash != "" {
+		achievements.RecordSave(result.LinesAdded, result.LinesDeleted)
+	}
+
+	// Auto-sync if something was saved
+	cfg, _ := config.Load()
+	autoSynced := false
+	var syncErr string
+	if result.Hash != "" && cfg.AutoSyncEnabled && git.HasRemote() {
+		autoSynced = true
+		if err := push(r); err != nil {
 			syncErr = err.Error()
+			git.QueueSync(err)
+			notify.SyncFailed(err)
 		}
 	}
 
 	jsonResponse(w, map[string]interface{}{
 		"status":     "ok",
+		"hash":       result.Hash,
+		"message":    result.Message,
+		"saved":      result.SavedCount,
+		"reverted":   result.RevertedCount,
+		"ignored":    result.IgnoredCount,
+		"skipped":    result.SkippedCount,
 		"autoSynced": autoSynced,
 		"syncError":  syncErr,
 	})
 }
 
+// isChangedPath checks if path is a changed file, or a file inside a new
+// folder (which git reports as a single "folder/" change)
+func isChangedPath(path string, changed map[string]bool) bool {
+	if changed[path] {
+		return true
+	}
+	for dir := range changed {
+		if strings.HasSuffix(dir, "/") && strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// withFileAction sets the action for path, adding an entry for files inside
+// a new folder so they can be handled apart from the rest of it
+func withFileAction(files []ops.File, path string, action ops.Action) []ops.File {
+	for i := range files {
+		if files[i].Change.Path == path {
+			files[i].Action = action
+			return files
+		}
+	}
+	return append(files, ops.File{
+		Change: git.FileChange{Status: "added", Path: path},
+		Action: action,
+	})
+}
+
+// push uploads the current branch to GitHub, giving up after the network
+// timeout in Settings or once the browser stops waiting
+func push(r *http.Request) error {
+	cfg, _ := config.Load()
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.NetworkTimeout())
+	defer cancel()
+	return git.Push(ctx)
+}
+
 func handleSync(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		errorResponse(w, "Method not allowed", 405)
@@ -156,7 +514,13 @@ func handleSync(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := git.Push(); err != nil {
+	if err := push(r); err != nil {
+		notify.SyncFailedHook(err)
+		var auth *git.AuthError
+		if errors.As(err, &auth) {
+			errorResponse(w, "GitHub didn't accept your login. Run smooth in a terminal and choose Sync to GitHub to set up an access token or SSH key.", 401)
+			return
+		}
 		errorResponse(w, err.Error(), 500)
 		return
 	}
@@ -164,8 +528,51 @@ func handleSync(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, map[string]string{"status": "ok"})
 }
 
+// commitsPageSize is how many saves /api/commits returns unless asked for
+// up to maxCommitsPageSize
+const (
+	commitsPageSize    = 20
+	maxCommitsPageSize = 100
+)
+
+// handleCommits returns a page of saves, newest first. Pass the nextCursor
+// from one page as ?cursor= to get the next; it's empty on the last page.
 func handleCommits(w http.ResponseWriter, r *http.Request) {
-	commits, err := git.Log(20)
+	limit := commitsPageSize
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxCommitsPageSize)
+	}
+	offset := 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil || offset < 0 {
+			errorResponse(w, "Invalid cursor", 400)
+			return
+		}
+	}
+
+	// One extra tells whether there's another page
+	commits, err := git.Log(offset, limit+1)
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+	next := ""
+	if len(commits) > limit {
+		commits = commits[:limit]
+		next = strconv.Itoa(offset + limit)
+	}
+	if commits == nil {
+		commits = []git.CommitInfo{}
+	}
+	jsonResponse(w, map[string]interface{}{
+		"commits":    commits,
+		"nextCursor": next,
+	})
+}
+
+func handleGraph(w http.ResponseWriter, r *http.Request) {
+	commits, err := git.CommitGraph(200)
 	if err != nil {
 		errorResponse(w, err.Error(), 500)
 		return
@@ -173,6 +580,173 @@ func handleCommits(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, commits)
 }
 
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" {
+		errorResponse(w, "Missing 'from' parameter", 400)
+		return
+	}
+	// Only saves go on to git, never something it could read as an option
+	from, err := git.ResolveCommit(from)
+	if err != nil {
+		errorResponse(w, err.Error(), 400)
+		return
+	}
+	if to != "" {
+		if to, err = git.ResolveCommit(to); err != nil {
+			errorResponse(w, err.Error(), 400)
+			return
+		}
+	}
+
+	summary, err := git.GetDiffStatBetweenCommits(from, to)
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	diff, err := git.GetDiffBetweenCommits(from, to)
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"summary": summary,
+		"diff":    diff,
+	})
+}
+
+// handleCompare diffs any two saves, or a save and the files now if 'to'
+// is left out
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" {
+		errorResponse(w, "Missing 'from' parameter", 400)
+		return
+	}
+
+	comparison, err := git.Compare(from, to)
+	if err != nil {
+		errorResponse(w, err.Error(), 400)
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"from":        comparison.From,
+		"to":          comparison.To,
+		"workingTree": comparison.WorkingTree(),
+		"summary":     comparison.Summary,
+		"diff":        comparison.Diff,
+	})
+}
+
+// handleFile serves a changed file as it is now, so images the terminal
+// can only sketch can be seen full size. Only changed files are served,
+// and only images are shown in the browser: anything else, like HTML or
+// SVG that could run script here, is downloaded instead.
+func handleFile(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	changes, err := git.GetChangeSummary()
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+	changed := make(map[string]bool)
+	for _, c := range changes {
+		changed[c.Path] = true
+	}
+	if !changed[path] {
+		errorResponse(w, "Not a changed file", 404)
+		return
+	}
+
+	// A link could point anywhere on the computer, so only real files in
+	// the project are served
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		errorResponse(w, "File not found", 404)
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err != nil || resolved != filepath.Clean(path) {
+		errorResponse(w, "File not found", 404)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		errorResponse(w, "File not found", 404)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); inlineImages[contentType] {
+		w.Header().Set("Content-Type", contentType)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(path)}))
+	}
+	http.ServeContent(w, r, path, info.ModTime(), f)
+}
+
+// inlineImages are the kinds of file handleFile shows in the browser.
+// SVG isn't one: it can carry script.
+var inlineImages = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/avif": true,
+	"image/bmp":  true,
+}
+
+func handleActivity(w http.ResponseWriter, r *http.Request) {
+	limit := 200
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l >= 0 {
+		limit = l
+	}
+
+	entries, err := git.ReadActivity(limit)
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+	jsonResponse(w, entries)
+}
+
+// handleStats returns save streaks, totals and per-day stats for the last
+// 30 days, oldest first
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	state, err := achievements.Load()
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	type day struct {
+		Date string `json:"date"`
+		achievements.DayStats
+	}
+	now := time.Now()
+	days := make([]day, 0, 30)
+	for i := 29; i >= 0; i-- {
+		t := now.AddDate(0, 0, -i)
+		days = append(days, day{Date: t.Format("2006-01-02"), DayStats: state.Day(t)})
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"saves":         state.Saves,
+		"streak":        state.Streak(now),
+		"longestStreak": max(state.LongestStreak, state.Streak(now)),
+		"totals":        state.Totals(),
+		"days":          days,
+		"endOfDay":      git.RecentDaySummaries(30),
+	})
+}
+
 func handleRestore(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		errorResponse(w, "Method not allowed", 405)
@@ -181,15 +755,32 @@ func handleRestore(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
 		CommitHash string `json:"commitHash"`
+		Experiment bool   `json:"experiment"` // open the save as a new experiment instead
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, "Invalid request", 400)
 		return
 	}
 
+	// The branch keeps its saves, so there's nothing to back up
+	if req.Experiment {
+		experiment, err := git.OpenAsExperiment(req.CommitHash)
+		var conflict *git.StashConflictError
+		if errors.As(err, &conflict) {
+			errorResponse(w, conflict.Error()+". They're kept in the stash: open Stashed changes in smooth to sort them out.", 409)
+			return
+		}
+		if err != nil {
+			errorResponse(w, err.Error(), 500)
+			return
+		}
+		jsonResponse(w, map[string]string{"status": "ok", "experiment": experiment})
+		return
+	}
+
 	// Create backup first
 	branch, _ := git.CurrentBranch()
-	backupName, err := git.CreateBackup(branch)
+	backupName, err := git.CreateBackup(branch, git.BackupBeforeRestore)
 	if err != nil {
 		errorResponse(w, "Failed to create backup: "+err.Error(), 500)
 		return
@@ -204,6 +795,7 @@ func handleRestore(w http.ResponseWriter, r *http.Request) {
 		errorResponse(w, err.Error(), 500)
 		return
 	}
+	notify.Restored("save " + req.CommitHash)
 
 	jsonResponse(w, map[string]string{"status": "ok", "backup": backupName})
 }
@@ -218,6 +810,79 @@ func handleBackups(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, backups)
 }
 
+// handleExport writes the project to a zip or tar.gz in the export folder.
+// Without a commit hash, the current files are exported, unsaved changes
+// included; the format defaults to the one in settings.
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, "Method not allowed", 405)
+		return
+	}
+
+	var req struct {
+		CommitHash string `json:"commitHash"`
+		Format     string `json:"format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, "Invalid request", 400)
+		return
+	}
+
+	cfg, _ := config.Load()
+	format := cfg.ExportFormat
+	if req.Format != "" {
+		if req.Format != config.ExportZip && req.Format != config.ExportTarGz {
+			errorResponse(w, "Format must be zip or tar.gz", 400)
+			return
+		}
+		format = req.Format
+	}
+	dir, err := cfg.ExportDirectory()
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	path, err := git.ExportArchive(req.CommitHash, dir, format)
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+	jsonResponse(w, map[string]string{"status": "ok", "path": path})
+}
+
+// handleBackup backs up the current branch, unsaved changes included, with
+// an optional label
+func handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, "Method not allowed", 405)
+		return
+	}
+
+	var req struct {
+		Label string `json:"label"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errorResponse(w, "Invalid request", 400)
+			return
+		}
+	}
+
+	branch, _ := git.CurrentBranch()
+	backupName, err := git.BackUpNow(branch, req.Label)
+	if err != nil {
+		errorResponse(w, "Failed to create backup: "+err.Error(), 500)
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"status":     "ok",
+		"backupName": backupName,
+		"unsaved":    git.HasChanges(),
+	})
+}
+
 func handleRestoreBackup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		errorResponse(w, "Method not allowed", 405)
@@ -236,6 +901,7 @@ func handleRestoreBackup(w http.ResponseWriter, r *http.Request) {
 		errorResponse(w, err.Error(), 500)
 		return
 	}
+	notify.Restored("backup " + req.BackupName)
 
 	jsonResponse(w, map[string]string{"status": "ok"})
 }
@@ -326,40 +992,120 @@ func handleSwitchExperiment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Stash changes if any
-	if git.HasChanges() {
-		if err := git.Stash(); err != nil {
+	err := git.WithAutoStash("switching to "+req.Branch, func() error {
+		return git.SwitchBranch(req.Branch)
+	})
+	var conflict *git.StashConflictError
+	if errors.As(err, &conflict) {
+		errorResponse(w, conflict.Error()+". They're kept in the stash: open Stashed changes in smooth to sort them out.", 409)
+		return
+	}
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	jsonResponse(w, map[string]string{"status": "ok"})
+}
+
+func handleGitignore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, "Method not allowed", 405)
+		return
+	}
+
+	var req struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, "Invalid request", 400)
+		return
+	}
+
+	if err := git.AddToGitignore(req.Pattern); err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+
+	jsonResponse(w, map[string]string{"status": "ok"})
+}
+
+func handleTasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		tasks, err := git.LoadTasks()
+		if err != nil {
+			errorResponse(w, err.Error(), 500)
+			return
+		}
+		if tasks == nil {
+			tasks = []git.Task{}
+		}
+		jsonResponse(w, tasks)
+
+	case "POST":
+		var req struct {
+			Title string `json:"title"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			errorResponse(w, "Invalid request", 400)
+			return
+		}
+		if strings.TrimSpace(req.Title) == "" {
+			errorResponse(w, "A task needs a title", 400)
+			return
+		}
+
+		task, err := git.AddTask(req.Title)
+		if err != nil {
 			errorResponse(w, err.Error(), 500)
 			return
 		}
+		jsonResponse(w, task)
+
+	default:
+		errorResponse(w, "Method not allowed", 405)
 	}
+}
 
-	if err := git.SwitchBranch(req.Branch); err != nil {
-		errorResponse(w, err.Error(), 500)
+func handleTaskDone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		errorResponse(w, "Method not allowed", 405)
 		return
 	}
 
-	// Try to pop stash
-	git.StashPop()
+	var req struct {
+		ID   int  `json:"id"`
+		Done bool `json:"done"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, "Invalid request", 400)
+		return
+	}
+
+	if err := git.SetTaskDone(req.ID, req.Done); err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
 
 	jsonResponse(w, map[string]string{"status": "ok"})
 }
 
-func handleGitignore(w http.ResponseWriter, r *http.Request) {
+func handleDeleteTask(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		errorResponse(w, "Method not allowed", 405)
 		return
 	}
 
 	var req struct {
-		Pattern string `json:"pattern"`
+		ID int `json:"id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, "Invalid request", 400)
 		return
 	}
 
-	if err := git.AddToGitignore(req.Pattern); err != nil {
+	if err := git.DeleteTask(req.ID); err != nil {
 		errorResponse(w, err.Error(), 500)
 		return
 	}
@@ -373,17 +1119,72 @@ func handleThemes(w http.ResponseWriter, r *http.Request) {
 		Name string `json:"name"`
 	}
 
-	themes := make([]themeInfo, len(config.ThemeNames))
-	for i, id := range config.ThemeNames {
-		themes[i] = themeInfo{
+	// Auto is the terminal's choice, so it's offered here too
+	themes := []themeInfo{{ID: config.ThemeAuto, Name: "Auto (match the terminal)"}}
+	for _, id := range config.ThemeIDs() {
+		theme, _ := config.LookupTheme(id)
+		themes = append(themes, themeInfo{
 			ID:   id,
-			Name: config.Themes[id].Name,
-		}
+			Name: theme.Name,
+		})
 	}
 
 	jsonResponse(w, themes)
 }
 
+// handleTheme returns the colors of the theme in use, for the page to
+// paint itself with. Auto has no terminal to match here, so it follows the
+// browser, which says if it prefers dark with dark=true or false. Passing
+// id returns that theme instead, to preview it before picking it.
+func handleTheme(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load()
+	if err != nil {
+		errorResponse(w, err.Error(), 500)
+		return
+	}
+	id := cfg.Theme
+	if q := r.URL.Query().Get("id"); q != "" {
+		id = q
+	}
+	id = config.ResolveTheme(id, r.URL.Query().Get("dark") != "false")
+	theme, ok := config.LookupTheme(id)
+	if !ok {
+		errorResponse(w, "Unknown theme", 404)
+		return
+	}
+
+	jsonResponse(w, struct {
+		ID string `json:"id"`
+		config.Theme
+	}{id, theme})
+}
+
+// redactConfig hides the webhook addresses in cfg. They work like
+// passwords, since anyone with one can post to the channel behind it.
+func redactConfig(cfg config.Config) config.Config {
+	cfg.EndOfDayWebhook = redactURL(cfg.EndOfDayWebhook)
+	webhooks := make([]config.Webhook, len(cfg.Webhooks))
+	for i, hook := range cfg.Webhooks {
+		hook.URL = redactURL(hook.URL)
+		webhooks[i] = hook
+	}
+	cfg.Webhooks = webhooks
+	return cfg
+}
+
+// redactURL keeps only the scheme and host of a URL, like
+// "https://hooks.slack.com/…", so it can still be told apart
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "…"
+	}
+	return u.Scheme + "://" + u.Host + "/…"
+}
+
 func handleConfig(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
@@ -392,7 +1193,7 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 			errorResponse(w, err.Error(), 500)
 			return
 		}
-		jsonResponse(w, cfg)
+		jsonResponse(w, redactConfig(cfg))
 
 	case "POST":
 		var req struct {
@@ -432,7 +1233,7 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 		}
 		if req.Theme != nil {
 			// Validate theme exists
-			if _, ok := config.Themes[*req.Theme]; ok {
+			if _, ok := config.LookupTheme(*req.Theme); ok || *req.Theme == config.ThemeAuto {
 				cfg.Theme = *req.Theme
 			}
 		}
@@ -443,7 +1244,7 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		jsonResponse(w, cfg)
+		jsonResponse(w, redactConfig(cfg))
 
 	default:
 		errorResponse(w, "Method not allowed", 405)
@@ -1,12 +1,13 @@
 // State
 let currentStatus = null;
-let selectedFiles = new Set();
+let fileActions = {};
 let pendingConfirm = null;
 let settingsDirty = false;
 let originalConfig = null;
 
 // Initialize
 document.addEventListener('DOMContentLoaded', () => {
+    loadTheme();
     refreshStatus();
     loadInitialConfig();
     setInterval(refreshStatus, 5000); // Poll every 5 seconds
@@ -22,6 +23,59 @@ async function loadInitialConfig() {
     }
 }
 
+// Theme
+const prefersDark = window.matchMedia('(prefers-color-scheme: dark)');
+// The auto theme follows the browser
+prefersDark.addEventListener('change', () => loadTheme());
+
+// loadTheme paints the page with the colors of the theme picked in the
+// settings, or of the theme with id to preview it
+async function loadTheme(id) {
+    try {
+        const params = new URLSearchParams({ dark: prefersDark.matches });
+        if (id) params.set('id', id);
+        applyTheme(await api('/theme?' + params));
+    } catch (e) {
+        console.error('Failed to load theme:', e);
+    }
+}
+
+// applyTheme sets the page's color variables from a theme's palette. The
+// shades in between are mixed from the background and text colors, so
+// light themes get light panels.
+function applyTheme(theme) {
+    const mix = (color, percent) => `color-mix(in srgb, ${color} ${percent}%, ${theme.background})`;
+    const colors = {
+        '--bg-primary': theme.background,
+        '--bg-secondary': mix(theme.text, 4),
+        '--bg-tertiary': mix(theme.text, 8),
+        '--bg-hover': mix(theme.text, 14),
+        '--border-color': mix(theme.text, 14),
+        '--text-primary': theme.text,
+        '--text-secondary': mix(theme.text, 70),
+        '--text-muted': theme.muted,
+        '--accent-coral': theme.primary,
+        '--accent-teal': theme.secondary,
+        '--accent-yellow': theme.accent,
+        '--accent-purple': theme.highlight,
+        '--accent-green': theme.success,
+        '--accent-red': theme.danger,
+    };
+    const root = document.documentElement;
+    for (const [name, value] of Object.entries(colors)) {
+        root.style.setProperty(name, value);
+    }
+    // Scrollbars and form controls match too
+    root.style.colorScheme = isLight(theme.background) ? 'light' : 'dark';
+}
+
+// isLight checks if a #rrggbb color is closer to white than black
+function isLight(hex) {
+    const n = parseInt(hex.replace('#', ''), 16);
+    const luminance = 0.299 * (n >> 16 & 255) + 0.587 * (n >> 8 & 255) + 0.114 * (n & 255);
+    return luminance > 128;
+}
+
 // API helpers
 async function api(endpoint, options = {}) {
     const response = await fetch(`/api${endpoint}`, {
@@ -30,7 +84,9 @@ async function api(endpoint, options = {}) {
     });
     const data = await response.json();
     if (!response.ok) {
-        throw new Error(data.error || 'Request failed');
+        const err = new Error(data.error || 'Request failed');
+        err.data = data;
+        throw err;
     }
     return data;
 }
@@ -61,6 +117,7 @@ function showPanel(panelId, skipDirtyCheck = false) {
     // Load data for specific panels
     if (panelId === 'savePanel') loadChanges();
     if (panelId === 'restorePanel') loadCommits();
+    if (panelId === 'timelinePanel') loadGraph();
     if (panelId === 'backupsPanel') loadBackups();
     if (panelId === 'experimentsPanel') loadExperiments();
     if (panelId === 'settingsPanel') loadConfig();
@@ -93,13 +150,21 @@ function updateStatusUI() {
 }
 
 // Save Progress
+const fileActionLabels = {
+    save: 'Save',
+    revert: 'Revert',
+    skip: 'Skip this time',
+    ignore: 'Always ignore'
+};
+
 async function loadChanges() {
     const fileList = document.getElementById('fileList');
     fileList.innerHTML = '<p class="loading">Loading changes...</p>';
     
     try {
         const changes = await api('/changes');
-        selectedFiles = new Set(changes.map(c => c.Path));
+        fileActions = {};
+        changes.forEach(c => { fileActions[c.Path] = 'save'; });
         
         if (changes.length === 0) {
             fileList.innerHTML = '<div class="empty-state"><p>No changes to save!</p><p>Your work is already saved.</p></div>';
@@ -107,13 +172,16 @@ async function loadChanges() {
         }
         
         fileList.innerHTML = changes.map(change => {
-            const statusIcon = change.Status === 'added' ? 'new' : change.Status === 'deleted' ? 'del' : 'mod';
+            const statusIcon = change.Status === 'added' ? 'new' : change.Status === 'deleted' ? 'del' : change.Status === 'renamed' ? 'mv' : 'mod';
             const statusClass = change.Status;
+            const options = Object.entries(fileActionLabels)
+                .map(([action, label]) => `<option value="${action}">${label}</option>`)
+                .join('');
             return `
-                <div class="file-item selected" data-path="${change.Path}" onclick="toggleFile(this)">
-                    <input type="checkbox" checked>
+                <div class="file-item selected" data-path="${change.Path}">
                     <span class="file-status ${statusClass}">${statusIcon}</span>
-                    <span class="file-path">${change.Path}</span>
+                    <span class="file-path">${escapeHtml(displayPath(change))}</span>
+                    <select class="file-action" onchange="setFileAction(this)">${options}</select>
                 </div>
             `;
         }).join('');
@@ -122,62 +190,45 @@ async function loadChanges() {
     }
 }
 
-function toggleFile(element) {
-    const path = element.dataset.path;
-    const checkbox = element.querySelector('input[type="checkbox"]');
-    
-    if (selectedFiles.has(path)) {
-        selectedFiles.delete(path);
-        checkbox.checked = false;
-        element.classList.remove('selected');
-        
-        // Ask about gitignore
-        showConfirm(
-            'Add to .gitignore?',
-            `Would you like to add "${path}" to .gitignore so it's never tracked?`,
-            async () => {
-                try {
-                    await api('/gitignore', {
-                        method: 'POST',
-                        body: JSON.stringify({ pattern: path })
-                    });
-                    showToast('Added to .gitignore', 'success');
-                } catch (e) {
-                    showToast(e.message, 'error');
-                }
-            }
-        );
-    } else {
-        selectedFiles.add(path);
-        checkbox.checked = true;
-        element.classList.add('selected');
-    }
+function setFileAction(select) {
+    const element = select.closest('.file-item');
+    const action = select.value;
+    fileActions[element.dataset.path] = action;
+    element.classList.toggle('selected', action === 'save');
+    element.dataset.action = action;
 }
 
-async function saveProgress() {
+async function saveProgress(allowSecrets = [], skipChecks = false) {
     const message = document.getElementById('commitMessage').value.trim();
-    if (!message) {
+    const actions = Object.values(fileActions);
+    const saving = actions.includes('save');
+    
+    if (actions.length === 0) {
+        showToast('No changes to save', 'error');
+        return;
+    }
+    
+    if (saving && !message) {
         showToast('Please enter a description', 'error');
         return;
     }
     
-    if (selectedFiles.size === 0) {
-        showToast('No files selected', 'error');
+    if (actions.every(a => a === 'skip')) {
+        showToast('Every file is skipped', 'error');
         return;
     }
     
     showLoading(true);
     try {
-        const result = await api('/save', {
+        const result = await api('/save/plan', {
             method: 'POST',
-            body: JSON.stringify({
-                message,
-                files: Array.from(selectedFiles)
-            })
+            body: JSON.stringify({ message, actions: fileActions, allowSecrets, skipChecks })
         });
         
-        // Show appropriate message based on auto-sync result
-        if (result.autoSynced) {
+        // Show appropriate message based on what happened
+        if (!result.hash) {
+            showToast(describeSaveResult(result), 'success');
+        } else if (result.autoSynced) {
             if (result.syncError) {
                 showToast('Saved! (Auto-sync failed: ' + result.syncError + ')', 'success');
             } else {
@@ -191,39 +242,125 @@ async function saveProgress() {
         refreshStatus();
         showPanel('menuPanel');
     } catch (e) {
-        showToast(e.message, 'error');
+        if (e.data && e.data.findings) {
+            confirmSecrets(e.data.findings, allowSecrets);
+        } else if (e.data && e.data.checks) {
+            showChecks(e.data, allowSecrets);
+        } else {
+            showToast(e.message, 'error');
+        }
     }
     showLoading(false);
 }
 
+function describeSaveResult(result) {
+    const parts = [];
+    if (result.reverted) parts.push(`reverted ${result.reverted}`);
+    if (result.ignored) parts.push(`ignored ${result.ignored}`);
+    if (result.skipped) parts.push(`skipped ${result.skipped}`);
+    return parts.length ? 'Done: ' + parts.join(', ') + ' file(s)' : 'Done';
+}
+
+function confirmSecrets(findings, allowSecrets) {
+    const paths = [...new Set(findings.map(f => f.Path))];
+    const list = findings.map(f => `${f.Path}:${f.Line} (${f.Rule})`).join(', ');
+    showConfirm(
+        'Possible secrets found',
+        `These look like passwords or keys: ${list}. Nothing was saved. Save them anyway? Otherwise, change those files to Revert or Always ignore.`,
+        () => saveProgress([...allowSecrets, ...paths])
+    );
+}
+
+function showChecks(failure, allowSecrets) {
+    document.getElementById('checksTitle').textContent = failure.error;
+    document.getElementById('checksOutput').textContent = failure.output || '(no output)';
+    document.getElementById('checksRetryBtn').onclick = () => {
+        closeChecks();
+        saveProgress(allowSecrets);
+    };
+    document.getElementById('checksAnywayBtn').onclick = () => {
+        closeChecks();
+        saveProgress(allowSecrets, true);
+    };
+    document.getElementById('checksModal').classList.remove('hidden');
+}
+
+function closeChecks() {
+    document.getElementById('checksModal').classList.add('hidden');
+}
+
 // Restore
+// Saves load a page at a time; the next page loads when the end of the list
+// scrolls into view
+let commitsCursor = '';
+let commitsObserver = null;
+
+function renderCommitItem(commit) {
+    return `
+            <div class="commit-item" onclick="showDiff('${commit.FullHash}', '${commit.Hash}', '${escapeHtml(commit.Message)}', 'restorePanel')">
+                <div class="commit-info">
+                    <span class="commit-hash">${commit.Hash}</span>
+                    <span class="commit-message">${escapeHtml(commit.Message)}</span>
+                    <span class="commit-time">${commit.Timestamp}</span>
+                </div>
+                <button class="restore-btn">Preview</button>
+            </div>
+        `;
+}
+
 async function loadCommits() {
     const commitList = document.getElementById('commitList');
     commitList.innerHTML = '<p class="loading">Loading save points...</p>';
+    if (commitsObserver) {
+        commitsObserver.disconnect();
+    }
     
     try {
-        const commits = await api('/commits');
+        const page = await api('/commits');
         
-        if (commits.length === 0) {
+        if (page.commits.length === 0) {
             commitList.innerHTML = '<div class="empty-state"><p>No save points found</p><p>Save your progress first!</p></div>';
             return;
         }
         
-        commitList.innerHTML = commits.map(commit => `
-            <div class="commit-item" onclick="restoreCommit('${commit.FullHash}', '${escapeHtml(commit.Message)}')">
-                <div class="commit-info">
-                    <span class="commit-hash">${commit.Hash}</span>
-                    <span class="commit-message">${escapeHtml(commit.Message)}</span>
-                    <span class="commit-time">${commit.Timestamp}</span>
-                </div>
-                <button class="restore-btn">Restore</button>
-            </div>
-        `).join('');
+        commitList.innerHTML = page.commits.map(renderCommitItem).join('');
+        commitsCursor = page.nextCursor;
+        watchCommitsEnd();
     } catch (e) {
         commitList.innerHTML = `<div class="empty-state"><p>Error loading commits</p><p>${e.message}</p></div>`;
     }
 }
 
+// watchCommitsEnd adds a marker after the last save that loads the next
+// page once it's visible
+function watchCommitsEnd() {
+    if (!commitsCursor) {
+        return;
+    }
+    const commitList = document.getElementById('commitList');
+    const marker = document.createElement('p');
+    marker.className = 'loading';
+    marker.textContent = 'Loading older saves...';
+    commitList.appendChild(marker);
+
+    commitsObserver = new IntersectionObserver(async entries => {
+        if (!entries[0].isIntersecting) {
+            return;
+        }
+        commitsObserver.disconnect();
+        try {
+            const page = await api(`/commits?cursor=${encodeURIComponent(commitsCursor)}`);
+            marker.insertAdjacentHTML('beforebegin', page.commits.map(renderCommitItem).join(''));
+            marker.remove();
+            commitsCursor = page.nextCursor;
+            watchCommitsEnd();
+        } catch (e) {
+            marker.textContent = `Couldn't load older saves: ${e.message}`;
+        }
+    });
+    commitsObserver.observe(marker);
+}
+
 function restoreCommit(hash, message) {
     showConfirm(
         'Restore to this state?',
@@ -246,6 +383,142 @@ function restoreCommit(hash, message) {
     );
 }
 
+// Timeline
+// Lane colors follow the theme
+const graphColors = ['var(--accent-teal)', 'var(--accent-coral)', 'var(--accent-yellow)', 'var(--accent-purple)', 'var(--accent-green)'];
+const graphRowHeight = 44;
+const graphLaneWidth = 18;
+
+async function loadGraph() {
+    const graphList = document.getElementById('graphList');
+    graphList.innerHTML = '<p class="loading">Loading timeline...</p>';
+
+    try {
+        const commits = await api('/graph');
+
+        if (commits.length === 0) {
+            graphList.innerHTML = '<div class="empty-state"><p>No save points found</p><p>Save your progress first!</p></div>';
+            return;
+        }
+
+        const layout = layoutGraph(commits);
+        const width = (layout.laneCount + 1) * graphLaneWidth;
+
+        graphList.innerHTML = `
+            <svg class="graph-lanes" width="${width}" height="${commits.length * graphRowHeight}">${layout.svg}</svg>
+            <div class="graph-rows">
+                ${commits.map(commit => `
+                    <div class="graph-row" style="height: ${graphRowHeight}px" onclick="showDiff('${commit.FullHash}', '${commit.Hash}', '${escapeHtml(commit.Message)}', 'timelinePanel')">
+                        <span class="commit-hash">${commit.Hash}</span>
+                        <span class="commit-message">${escapeHtml(commit.Message)}</span>
+                        ${(commit.Refs || []).map(ref => `<span class="graph-ref">${escapeHtml(ref)}</span>`).join('')}
+                        <span class="commit-time">${commit.Timestamp}</span>
+                    </div>
+                `).join('')}
+            </div>
+        `;
+    } catch (e) {
+        graphList.innerHTML = `<div class="empty-state"><p>Error loading timeline</p><p>${e.message}</p></div>`;
+    }
+}
+
+// layoutGraph assigns each commit a lane and returns SVG for the lines and dots
+function layoutGraph(commits) {
+    const lanes = []; // hash each lane is waiting for
+    const positions = {}; // hash -> {lane, row}
+    let laneCount = 0;
+
+    commits.forEach((commit, row) => {
+        let lane = lanes.indexOf(commit.FullHash);
+        if (lane === -1) {
+            lane = lanes.indexOf(null);
+            if (lane === -1) lane = lanes.length;
+        }
+        // Any other lanes waiting on this commit merge into it
+        lanes.forEach((hash, i) => { if (hash === commit.FullHash) lanes[i] = null; });
+
+        positions[commit.FullHash] = { lane, row };
+        const parents = commit.Parents || [];
+        lanes[lane] = parents[0] || null;
+        parents.slice(1).forEach(parent => {
+            if (lanes.indexOf(parent) !== -1) return;
+            const free = lanes.indexOf(null);
+            if (free === -1) lanes.push(parent); else lanes[free] = parent;
+        });
+        laneCount = Math.max(laneCount, lanes.length);
+    });
+
+    const x = lane => (lane + 1) * graphLaneWidth;
+    const y = row => row * graphRowHeight + graphRowHeight / 2;
+
+    let lines = '';
+    let dots = '';
+    commits.forEach(commit => {
+        const from = positions[commit.FullHash];
+        (commit.Parents || []).forEach(parent => {
+            const to = positions[parent];
+            // Parents outside the loaded range run off the bottom
+            const toX = to ? x(to.lane) : x(from.lane);
+            const toY = to ? y(to.row) : commits.length * graphRowHeight;
+            const color = graphColors[(to ? to.lane : from.lane) % graphColors.length];
+            lines += `<path d="M${x(from.lane)} ${y(from.row)} C${x(from.lane)} ${y(from.row) + graphRowHeight / 2}, ${toX} ${toY - graphRowHeight / 2}, ${toX} ${toY}" style="stroke: ${color}" fill="none" stroke-width="2"/>`;
+        });
+        dots += `<circle cx="${x(from.lane)}" cy="${y(from.row)}" r="5" style="fill: ${graphColors[from.lane % graphColors.length]}"/>`;
+    });
+
+    return { svg: lines + dots, laneCount };
+}
+
+// Diff viewer
+async function showDiff(hash, shortHash, message, backPanel) {
+    showPanel('diffPanel');
+    document.getElementById('diffBackBtn').onclick = () => showPanel(backPanel);
+    document.getElementById('diffTitle').textContent = `Since ${shortHash}`;
+    document.getElementById('diffDesc').textContent = `Everything that changed after "${message}", including unsaved work. Restoring undoes all of it.`;
+    document.getElementById('diffRestoreBtn').onclick = () => restoreCommit(hash, message);
+
+    const summaryEl = document.getElementById('diffSummary');
+    const diffEl = document.getElementById('diffView');
+    summaryEl.innerHTML = '<p class="loading">Loading changes...</p>';
+    diffEl.innerHTML = '';
+
+    try {
+        const result = await api(`/diff?from=${encodeURIComponent(hash)}`);
+        const files = result.summary.Files || [];
+
+        if (files.length === 0) {
+            summaryEl.innerHTML = '<div class="empty-state"><p>No changes since this save</p></div>';
+            return;
+        }
+
+        summaryEl.innerHTML = `
+            <div class="diff-total">
+                ${files.length} file(s) ·
+                <span class="diff-add">+${result.summary.TotalAdded}</span>
+                <span class="diff-del">-${result.summary.TotalDeleted}</span>
+            </div>
+            ${files.map(f => `
+                <div class="diff-file">
+                    <span class="file-path">${escapeHtml(displayPath(f))}</span>
+                    ${f.IsBinary ? '<span class="commit-time">binary</span>' :
+                        `<span class="diff-add">+${f.Additions}</span> <span class="diff-del">-${f.Deletions}</span>`}
+                </div>
+            `).join('')}
+        `;
+
+        diffEl.innerHTML = result.diff.split('\n').map(line => {
+            let cls = '';
+            if (line.startsWith('+') && !line.startsWith('+++')) cls = 'diff-add';
+            else if (line.startsWith('-') && !line.startsWith('---')) cls = 'diff-del';
+            else if (line.startsWith('@@')) cls = 'diff-hunk';
+            else if (line.startsWith('diff ')) cls = 'diff-header';
+            return `<span class="${cls}">${escapeHtml(line)}</span>`;
+        }).join('\n');
+    } catch (e) {
+        summaryEl.innerHTML = `<div class="empty-state"><p>Error loading changes</p><p>${e.message}</p></div>`;
+    }
+}
+
 // Backups
 async function loadBackups() {
     const backupList = document.getElementById('backupList');
@@ -264,6 +537,7 @@ async function loadBackups() {
                 <div class="backup-info">
                     <span class="backup-time">${formatTimestamp(backup.Timestamp)}</span>
                     <span class="backup-message">${escapeHtml(backup.Message)}</span>
+                    ${backup.note ? `<span class="backup-note">${escapeHtml(backup.note)}</span>` : ''}
                     <span class="backup-branch">${backup.CommitHash}</span>
                 </div>
                 <button class="restore-btn">Restore</button>
@@ -296,7 +570,50 @@ function restoreBackup(name, timestamp) {
     );
 }
 
+async function backUpNow() {
+    const label = prompt('Label for this backup (optional):', '');
+    if (label === null) return;
+    showLoading(true);
+    try {
+        await api('/backup', {
+            method: 'POST',
+            body: JSON.stringify({ label })
+        });
+        showToast('Backed up!', 'success');
+        loadBackups();
+    } catch (e) {
+        showToast(e.message, 'error');
+    }
+    showLoading(false);
+}
+
+async function exportBackup() {
+    showLoading(true);
+    try {
+        const result = await api('/export', {
+            method: 'POST',
+            body: JSON.stringify({})
+        });
+        showToast(`Exported to ${result.path}`, 'success');
+    } catch (e) {
+        showToast(e.message, 'error');
+    }
+    showLoading(false);
+}
+
 // Experiments
+function experimentDetails(exp) {
+    const saves = exp.Ahead === 1 ? '1 save' : `${exp.Ahead} saves`;
+    let changes = 'no changes';
+    if (exp.FilesChanged > 0) {
+        const files = exp.FilesChanged === 1 ? '1 file' : `${exp.FilesChanged} files`;
+        changes = `${files} +${exp.LinesAdded} -${exp.LinesDeleted}`;
+    }
+    const details = [`${saves} ahead`, changes];
+    if (exp.LastActivity) details.push(`saved ${exp.LastActivity}`);
+    return details.join(' · ');
+}
+
 async function loadExperiments() {
     const experimentList = document.getElementById('experimentList');
     experimentList.innerHTML = '<p class="loading">Loading experiments...</p>';
@@ -313,6 +630,7 @@ async function loadExperiments() {
             <div class="experiment-item ${exp.IsCurrent ? 'current' : ''}" onclick="switchExperiment('${exp.Name}')">
                 <div class="experiment-info">
                     <span class="experiment-name">${exp.Name}</span>
+                    <span class="commit-time">${experimentDetails(exp)}</span>
                 </div>
                 ${exp.IsCurrent ? '<span class="current-badge">current</span>' : '<button class="restore-btn">Switch</button>'}
             </div>
@@ -414,6 +732,8 @@ async function loadConfig() {
         document.getElementById('maxBackupsInput').value = cfg.maxBackups;
         document.getElementById('experimentsToggle').checked = cfg.experimentsEnabled;
         document.getElementById('themeSelect').value = cfg.theme || 'coral';
+        // The theme may have been changed in the terminal since the page loaded
+        loadTheme();
         // Store original values to detect changes
         originalConfig = { ...cfg };
         settingsDirty = false;
@@ -461,6 +781,7 @@ async function updateConfig() {
         settingsDirty = false;
         // Update UI visibility based on experiments setting
         updateExperimentsVisibility(cfg.experimentsEnabled);
+        loadTheme();
         showToast('Settings saved', 'success');
     } catch (e) {
         showToast('Failed to save settings: ' + e.message, 'error');
@@ -575,6 +896,15 @@ function closeModal() {
     document.getElementById('confirmModal').classList.add('hidden');
 }
 
+// Renamed files show where they came from, like the TUI
+function displayPath(file) {
+    if (!file.OldPath) {
+        return file.Path;
+    }
+    const similar = file.Similarity ? ` (${file.Similarity}% similar)` : '';
+    return `${file.OldPath} → ${file.Path}${similar}`;
+}
+
 function escapeHtml(text) {
     const div = document.createElement('div');
     div.textContent = text;
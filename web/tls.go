@@ -0,0 +1,168 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"smooth/platform"
+)
+
+// The self-signed certificate is kept in ~/.smooth/web, so browsers that
+// were told to trust it once keep trusting it
+const (
+	certFile = "cert.pem"
+	keyFile  = "key.pem"
+)
+
+// certLifetime is how long a new certificate is valid, and certRenewal is
+// how close to expiring a certificate is replaced with a new one
+const (
+	certLifetime = 365 * 24 * time.Hour
+	certRenewal  = 30 * 24 * time.Hour
+)
+
+// LocalAddresses returns this computer's addresses on the networks it's
+// connected to, which other devices can reach the web interface at
+func LocalAddresses() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ips = append(ips, ipnet.IP)
+	}
+	return ips
+}
+
+// certHosts returns the names and addresses the certificate is for:
+// localhost, this computer's name and its addresses
+func certHosts() (names []string, ips []net.IP) {
+	names = []string{"localhost"}
+	if host, err := os.Hostname(); err == nil && host != "" && host != "localhost" {
+		names = append(names, host)
+	}
+	ips = append([]net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}, LocalAddresses()...)
+	return names, ips
+}
+
+// LoadCertificate returns the web interface's self-signed certificate,
+// making a new one if there isn't one yet, it's about to expire, or this
+// computer's addresses changed since it was made
+func LoadCertificate() (tls.Certificate, error) {
+	dir, err := platform.SmoothPath("web")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	certPath, keyPath := filepath.Join(dir, certFile), filepath.Join(dir, keyFile)
+	names, ips := certHosts()
+
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil && covers(cert.Leaf, names, ips) {
+		return cert, nil
+	}
+
+	certPEM, keyPEM, err := newCertificate(names, ips, time.Now())
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("couldn't make a certificate: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	// The key is what makes the certificate safe to trust, so only its
+	// owner can read it
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// covers checks if a certificate is good for a while yet and for every
+// name and address
+func covers(leaf *x509.Certificate, names []string, ips []net.IP) bool {
+	if leaf == nil || time.Until(leaf.NotAfter) < certRenewal {
+		return false
+	}
+	for _, name := range names {
+		if !slices.Contains(leaf.DNSNames, name) {
+			return false
+		}
+	}
+	for _, ip := range ips {
+		if !slices.ContainsFunc(leaf.IPAddresses, ip.Equal) {
+			return false
+		}
+	}
+	return true
+}
+
+// newCertificate makes a self-signed certificate for names and ips, valid
+// from now, returning it and its private key PEM encoded
+func newCertificate(names []string, ips []net.IP, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"smooth"}, CommonName: "smooth web"},
+		NotBefore:             now.Add(-time.Hour), // allow for clocks that are a little behind
+		NotAfter:              now.Add(certLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              names,
+		IPAddresses:           ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate, like
+// "AB:CD:...", which browsers show so it can be checked before trusting it
+func Fingerprint(cert tls.Certificate) (string, error) {
+	if len(cert.Certificate) == 0 {
+		return "", errors.New("no certificate")
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	hex := make([]string, len(sum))
+	for i, b := range sum {
+		hex[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hex, ":"), nil
+}
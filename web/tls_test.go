@@ -0,0 +1,103 @@
+package web
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCertificate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cert, err := LoadCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.Leaf.VerifyHostname("localhost"); err != nil {
+		t.Errorf("localhost: %v", err)
+	}
+	if err := cert.Leaf.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("127.0.0.1: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(home, ".smooth", "web", keyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key is readable by others: %v", perm)
+	}
+
+	// The same certificate is used next time, so browsers keep trusting it
+	again, err := LoadCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Certificate[0], cert.Certificate[0]) {
+		t.Error("made a new certificate when the old one was fine")
+	}
+}
+
+func TestLoadCertificateRenews(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// A certificate that expires in a week is replaced
+	names, ips := certHosts()
+	certPEM, keyPEM, err := newCertificate(names, ips, time.Now().Add(-certLifetime+7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(home, ".smooth", "web")
+	os.MkdirAll(dir, 0700)
+	os.WriteFile(filepath.Join(dir, certFile), certPEM, 0644)
+	os.WriteFile(filepath.Join(dir, keyFile), keyPEM, 0600)
+
+	cert, err := LoadCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(cert.Leaf.NotAfter) < certRenewal {
+		t.Errorf("kept a certificate expiring %v", cert.Leaf.NotAfter)
+	}
+	block, _ := pem.Decode(mustRead(t, filepath.Join(dir, certFile)))
+	saved, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || !saved.NotAfter.Equal(cert.Leaf.NotAfter) {
+		t.Errorf("the new certificate wasn't saved: %v", err)
+	}
+}
+
+func TestCoversAddresses(t *testing.T) {
+	names := []string{"localhost"}
+	ips := []net.IP{net.IPv4(127, 0, 0, 1)}
+	certPEM, _, err := newCertificate(names, ips, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !covers(leaf, names, ips) {
+		t.Error("doesn't cover what it was made for")
+	}
+	// Joining a new network means a new address to cover
+	if covers(leaf, names, append(ips, net.IPv4(192, 168, 1, 20))) {
+		t.Error("covers an address it wasn't made for")
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+)
+
+// Over HTTPS the web interface can be reached from the whole network, so
+// every request needs the access token made when the server starts. It's
+// in the address that's printed; opening that sets a cookie, and the page
+// and API work from then on without it.
+const (
+	tokenParam  = "token"
+	tokenCookie = "smooth_token"
+)
+
+// newAccessToken makes a random token for this run of the server
+func newAccessToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// requireToken only lets requests that carry token through to next, in
+// the address or the cookie. Opening the address with it sets the cookie,
+// then goes to the same page without it, so it isn't left in the history.
+func requireToken(token string, next http.Handler) http.Handler {
+	matches := func(s string) bool {
+		return subtle.ConstantTimeCompare([]byte(s), []byte(token)) == 1
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Has(tokenParam) && matches(q.Get(tokenParam)) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			if r.Method == http.MethodGet {
+				q.Del(tokenParam)
+				clean := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+				http.Redirect(w, r, clean.String(), http.StatusSeeOther)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c, err := r.Cookie(tokenCookie); err == nil && matches(c.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		errorResponse(w, "Open smooth with the address it printed when it started, token included", http.StatusUnauthorized)
+	})
+}
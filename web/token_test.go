@@ -0,0 +1,46 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	const token = "secret"
+	handler := requireToken(token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, target := range []string{"/", "/api/status", "/api/status?token=wrong"} {
+		if rec := serve(httptest.NewRequest("GET", target, nil)); rec.Code != 401 {
+			t.Errorf("%s: status %d, want 401", target, rec.Code)
+		}
+	}
+
+	// The printed address sets the cookie and drops the token from the URL
+	rec := serve(httptest.NewRequest("GET", "/?token="+token, nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Fatalf("status %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != token || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("cookies %+v", cookies)
+	}
+
+	req := httptest.NewRequest("POST", "/api/save", nil)
+	req.AddCookie(cookies[0])
+	if rec := serve(req); rec.Code != 200 {
+		t.Errorf("with the cookie: status %d", rec.Code)
+	}
+	req = httptest.NewRequest("POST", "/api/save", nil)
+	req.AddCookie(&http.Cookie{Name: tokenCookie, Value: "wrong"})
+	if rec := serve(req); rec.Code != 401 {
+		t.Errorf("with a wrong cookie: status %d, want 401", rec.Code)
+	}
+}